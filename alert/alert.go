@@ -0,0 +1,170 @@
+// Package alert raises alerts when synced channels and videos change
+// faster than expected, such as a channel posting more than five videos in
+// a day or a video gaining more than 100,000 views since its last stats
+// sample.
+//
+// An Engine evaluates Rules against a store, whose videos are kept up to
+// date by syncs and whose stats history is sampled by youtube.TrackStats,
+// and passes new alerts to Notifiers: a writer such as stdout, a webhook,
+// or a command. Call Check after each sync or stats run, or let Run check
+// on a fixed interval.
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"ytsync/storage"
+)
+
+// DefaultCooldown is how long an alert is not raised again when
+// Engine.Cooldown is zero.
+const DefaultCooldown = 24 * time.Hour
+
+// Alert is a rule's finding about a channel or video.
+type Alert struct {
+	// Rule is the name of the rule that raised the alert.
+	Rule string `json:"rule"`
+	// Key identifies what the alert is about, so it is raised once per
+	// Engine.Cooldown.
+	Key string `json:"key"`
+	// ChannelID is the YouTube ID of the channel.
+	ChannelID string `json:"channel_id"`
+	// ChannelName is the channel's name.
+	ChannelName string `json:"channel_name,omitempty"`
+	// VideoID is the YouTube ID of the video, for video alerts.
+	VideoID string `json:"video_id,omitempty"`
+	// VideoTitle is the video's title, for video alerts.
+	VideoTitle string `json:"video_title,omitempty"`
+	// Value is the measured value, such as a number of uploads or views.
+	Value int64 `json:"value"`
+	// Threshold is the value the rule allows.
+	Threshold int64 `json:"threshold"`
+	// Message describes the alert.
+	Message string `json:"message"`
+	// Time is when the alert was raised.
+	Time time.Time `json:"time"`
+}
+
+// Rule finds alerts in the contents of a store.
+type Rule interface {
+	// Evaluate returns the alerts the rule raises on store at now.
+	Evaluate(ctx context.Context, store storage.Store, now time.Time) ([]Alert, error)
+}
+
+// Engine evaluates rules and notifies new alerts. It is safe for
+// concurrent use.
+type Engine struct {
+	// Store holds the channels, videos, and stats the rules evaluate.
+	Store storage.Store
+	// Rules are evaluated by each Check.
+	Rules []Rule
+	// Notifiers receive each Check's new alerts.
+	Notifiers []Notifier
+	// Cooldown is how long an alert with the same key is not raised again.
+	// Defaults to DefaultCooldown.
+	Cooldown time.Duration
+
+	now func() time.Time
+
+	mu   sync.Mutex
+	sent map[string]time.Time // alert key -> when it was notified
+}
+
+// NewEngine returns an engine evaluating rules on store.
+func NewEngine(store storage.Store, rules []Rule, notifiers ...Notifier) *Engine {
+	return &Engine{Store: store, Rules: rules, Notifiers: notifiers}
+}
+
+// Check evaluates every rule and passes the alerts not raised within the
+// cooldown to every notifier, returning them. A failing rule does not stop
+// the others. Alerts whose notification failed are raised again by the
+// next Check. The error joins the failures of rules and notifiers.
+func (e *Engine) Check(ctx context.Context) ([]Alert, error) {
+	now := time.Now()
+	if e.now != nil {
+		now = e.now()
+	}
+	cooldown := e.Cooldown
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+
+	var errs []error
+	var raised []Alert
+	for _, rule := range e.Rules {
+		alerts, err := rule.Evaluate(ctx, e.Store, now)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		raised = append(raised, alerts...)
+	}
+
+	e.mu.Lock()
+	if e.sent == nil {
+		e.sent = make(map[string]time.Time)
+	}
+	for key, at := range e.sent {
+		if now.Sub(at) >= cooldown {
+			delete(e.sent, key)
+		}
+	}
+	var fresh []Alert
+	seen := make(map[string]bool)
+	for _, a := range raised {
+		if _, ok := e.sent[a.Key]; ok || seen[a.Key] {
+			continue
+		}
+		seen[a.Key] = true
+		if a.Time.IsZero() {
+			a.Time = now
+		}
+		fresh = append(fresh, a)
+	}
+	e.mu.Unlock()
+
+	if len(fresh) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	notified := true
+	for _, n := range e.Notifiers {
+		if err := n.Notify(ctx, fresh); err != nil {
+			errs = append(errs, fmt.Errorf("notify: %w", err))
+			notified = false
+		}
+	}
+	if notified {
+		e.mu.Lock()
+		for _, a := range fresh {
+			e.sent[a.Key] = now
+		}
+		e.mu.Unlock()
+	}
+	return fresh, errors.Join(errs...)
+}
+
+// Run calls Check immediately and then every interval until ctx is done.
+// Failed checks are logged and retried at the next interval. It returns
+// ctx's error.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("alert: interval must be positive")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.Check(ctx); err != nil {
+			log.Printf("alert: check: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"ytsync/storage"
+	"ytsync/ytsynctest"
+)
+
+var testNow = time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+// newTestStore returns a store with a channel that posted six videos in
+// the last day, the first of which has three stats samples.
+func newTestStore(t *testing.T) (*storage.JSONStore, *storage.Video) {
+	t.Helper()
+	ctx := context.Background()
+	store, err := storage.NewJSONStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	ch := &storage.Channel{YouTubeID: "UCbusy", Name: "Busy"}
+	if err := store.CreateChannel(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	var first *storage.Video
+	for i := 0; i < 7; i++ {
+		v := &storage.Video{
+			YouTubeID:   "video" + string(rune('a'+i)),
+			ChannelID:   ch.ID,
+			Title:       "Video " + string(rune('A'+i)),
+			PublishedAt: testNow.Add(-time.Duration(i*4) * time.Hour),
+		}
+		if err := store.CreateVideo(ctx, v); err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = v
+		}
+	}
+	for i, views := range []int64{1000, 50000, 200000} {
+		sample := &storage.VideoStats{VideoID: first.ID, SampledAt: testNow.Add(time.Duration(i-2) * time.Hour), ViewCount: views, LikeCount: views / 100}
+		if err := store.RecordVideoStats(ctx, sample); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store, first
+}
+
+func TestUploadRate(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	alerts, err := (&UploadRate{Max: 5}).Evaluate(ctx, store, testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Value != 6 || alerts[0].ChannelID != "UCbusy" || alerts[0].Rule != "upload-rate" {
+		t.Fatalf("alerts = %+v, want one for 6 videos", alerts)
+	}
+	if want := "Busy posted 6 videos in a day (more than 5)"; alerts[0].Message != want {
+		t.Errorf("message = %q, want %q", alerts[0].Message, want)
+	}
+
+	if alerts, _ := (&UploadRate{Max: 6}).Evaluate(ctx, store, testNow); len(alerts) != 0 {
+		t.Errorf("alerts at the limit = %+v", alerts)
+	}
+	if alerts, _ := (&UploadRate{Max: 2, Window: 8 * time.Hour}).Evaluate(ctx, store, testNow); len(alerts) != 0 {
+		t.Errorf("alerts within 8 hours = %+v, want none for 2 videos", alerts)
+	}
+	if _, err := (&UploadRate{ChannelIDs: []string{"UCmissing"}}).Evaluate(ctx, store, testNow); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("unknown channel error = %v", err)
+	}
+}
+
+func TestStatsGrowth(t *testing.T) {
+	store, first := newTestStore(t)
+	ctx := context.Background()
+
+	alerts, err := (&StatsGrowth{MinIncrease: 100000}).Evaluate(ctx, store, testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].VideoID != first.YouTubeID || alerts[0].Value != 150000 || alerts[0].Rule != "views-growth" {
+		t.Fatalf("alerts = %+v, want 150000 views since the last sample", alerts)
+	}
+	if want := `"Video A" gained 150000 views in an hour (more than 100000)`; alerts[0].Message != want {
+		t.Errorf("message = %q, want %q", alerts[0].Message, want)
+	}
+
+	alerts, _ = (&StatsGrowth{Metric: Likes, MinIncrease: 1000, Window: 2 * time.Hour}).Evaluate(ctx, store, testNow)
+	if len(alerts) != 1 || alerts[0].Value != 1990 {
+		t.Errorf("likes over 2 hours = %+v, want 1990", alerts)
+	}
+	if alerts, _ := (&StatsGrowth{MinIncrease: 100000, MaxAge: time.Minute}).Evaluate(ctx, store, testNow.Add(time.Hour)); len(alerts) != 0 {
+		t.Errorf("alerts for old video = %+v", alerts)
+	}
+	if _, err := (&StatsGrowth{}).Evaluate(ctx, ytsynctest.NewMemoryStore(), testNow); !errors.Is(err, ErrNoStatsStore) {
+		t.Errorf("store without stats error = %v", err)
+	}
+}
+
+func TestEngineCooldown(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	var notified [][]Alert
+	fail := false
+	e := NewEngine(store, []Rule{&UploadRate{Max: 5}, &StatsGrowth{MinIncrease: 100000}},
+		NotifierFunc(func(ctx context.Context, alerts []Alert) error {
+			if fail {
+				return errors.New("unreachable")
+			}
+			notified = append(notified, alerts)
+			return nil
+		}))
+	now := testNow
+	e.now = func() time.Time { return now }
+
+	if alerts, err := e.Check(ctx); err != nil || len(alerts) != 2 || len(notified) != 1 {
+		t.Fatalf("first Check() = %d alerts, %v; notified %d times", len(alerts), err, len(notified))
+	}
+	if alerts, _ := e.Check(ctx); len(alerts) != 0 {
+		t.Errorf("second Check() raised %+v again", alerts)
+	}
+
+	// A day later the uploads have left the window but the views alert
+	// is raised again
+	now = testNow.Add(DefaultCooldown)
+	fail = true
+	alerts, err := e.Check(ctx)
+	if len(alerts) != 1 || alerts[0].Rule != "views-growth" || err == nil {
+		t.Fatalf("Check() after cooldown = %+v, %v; want the views alert and the notifier's error", alerts, err)
+	}
+	fail = false
+	if alerts, _ := e.Check(ctx); len(alerts) != 1 {
+		t.Errorf("Check() after failed notification raised %d alerts, want 1 again", len(alerts))
+	}
+}
+
+func TestNotifiers(t *testing.T) {
+	ctx := context.Background()
+	alerts := []Alert{{Rule: "upload-rate", Key: "k", ChannelID: "UCbusy", Message: "Busy posted 6 videos", Time: testNow}}
+
+	var buf bytes.Buffer
+	if err := (&WriterNotifier{W: &buf}).Notify(ctx, alerts); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "2024-03-10T12:00:00Z [upload-rate] Busy posted 6 videos\n" {
+		t.Errorf("writer output = %q", got)
+	}
+
+	var body struct{ Alerts []Alert }
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer server.Close()
+	if err := (&WebhookNotifier{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}).Notify(ctx, alerts); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Alerts) != 1 || body.Alerts[0].ChannelID != "UCbusy" {
+		t.Errorf("webhook got %+v", body)
+	}
+	if err := (&WebhookNotifier{URL: server.URL}).Notify(ctx, alerts); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("unauthorized webhook error = %v", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	cmd := &CommandNotifier{Path: "sh", Args: []string{"-c", `{ echo "$YTSYNC_ALERT_RULE $YTSYNC_CHANNEL_ID"; cat; } > "` + out + `"`}}
+	if err := cmd.Notify(ctx, alerts); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(out)
+	if !strings.HasPrefix(string(data), "upload-rate UCbusy\n{") || !strings.Contains(string(data), `"message":"Busy posted 6 videos"`) {
+		t.Errorf("command got %q", data)
+	}
+}
@@ -0,0 +1,135 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"ytsync/proc"
+)
+
+// Notifier delivers alerts.
+type Notifier interface {
+	// Notify delivers the alerts of one Check.
+	Notify(ctx context.Context, alerts []Alert) error
+}
+
+// NotifierFunc adapts a function to Notifier.
+type NotifierFunc func(ctx context.Context, alerts []Alert) error
+
+// Notify calls f.
+func (f NotifierFunc) Notify(ctx context.Context, alerts []Alert) error {
+	return f(ctx, alerts)
+}
+
+// WriterNotifier writes each alert as a line to W.
+type WriterNotifier struct {
+	W io.Writer
+}
+
+// NewStdoutNotifier returns a notifier writing to standard output.
+func NewStdoutNotifier() *WriterNotifier {
+	return &WriterNotifier{W: os.Stdout}
+}
+
+// Notify writes a line per alert: the time, the rule, and the message.
+func (n *WriterNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	var buf bytes.Buffer
+	for _, a := range alerts {
+		fmt.Fprintf(&buf, "%s [%s] %s\n", a.Time.UTC().Format(time.RFC3339), a.Rule, a.Message)
+	}
+	_, err := n.W.Write(buf.Bytes())
+	return err
+}
+
+// WebhookNotifier posts the alerts of each Check to a URL as a JSON object
+// {"alerts": [...]}.
+type WebhookNotifier struct {
+	// URL receives the POST requests.
+	URL string
+	// Headers are added to each request, for example for authentication.
+	Headers map[string]string
+	// HTTPClient sends the requests. Defaults to a client with a 30 second
+	// timeout.
+	HTTPClient *http.Client
+}
+
+// Notify posts alerts to the webhook. A response status other than 2xx is
+// an error.
+func (n *WebhookNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	body, err := json.Marshal(struct {
+		Alerts []Alert `json:"alerts"`
+	}{alerts})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.Headers {
+		req.Header.Set(k, v)
+	}
+	client := n.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: %s returned %s", n.URL, resp.Status)
+	}
+	return nil
+}
+
+// CommandNotifier runs a program once per alert, with the alert as JSON
+// on standard input and in its environment:
+//
+//	YTSYNC_ALERT_RULE     the rule name
+//	YTSYNC_ALERT_MESSAGE  the message
+//	YTSYNC_CHANNEL_ID     the channel's YouTube ID
+//	YTSYNC_VIDEO_ID       the video's YouTube ID, for video alerts
+type CommandNotifier struct {
+	// Path is the program to run.
+	Path string
+	// Args are the program's arguments.
+	Args []string
+}
+
+// Notify runs the program for each alert, stopping at the first failure.
+func (n *CommandNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	for _, a := range alerts {
+		data, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		cmd := proc.Command(ctx, n.Path, n.Args...)
+		cmd.Env = append(os.Environ(),
+			"YTSYNC_ALERT_RULE="+a.Rule,
+			"YTSYNC_ALERT_MESSAGE="+a.Message,
+			"YTSYNC_CHANNEL_ID="+a.ChannelID,
+			"YTSYNC_VIDEO_ID="+a.VideoID,
+		)
+		cmd.Stdin = bytes.NewReader(data)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(out.String()); msg != "" {
+				return fmt.Errorf("run %s: %w: %s", n.Path, err, msg)
+			}
+			return fmt.Errorf("run %s: %w", n.Path, err)
+		}
+	}
+	return nil
+}
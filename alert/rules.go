@@ -0,0 +1,243 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"ytsync/errcode"
+	"ytsync/storage"
+)
+
+// ErrNoStatsStore is returned by StatsGrowth when the store does not
+// implement storage.VideoStatsStore.
+var ErrNoStatsStore = errcode.New(errcode.InvalidInput, "alert: store does not record video stats")
+
+// UploadRate alerts when a channel publishes more than Max videos within
+// Window, such as more than five in a day. Videos count by their publish
+// time once a sync has stored them.
+type UploadRate struct {
+	// Name names the rule in alerts. Default: "upload-rate".
+	Name string
+	// Max is the number of videos a channel may publish within Window.
+	Max int
+	// Window is the period uploads are counted over. Default: 24 hours.
+	Window time.Duration
+	// ChannelIDs limits the rule to the stored channels with these YouTube
+	// IDs. Empty checks every stored channel.
+	ChannelIDs []string
+}
+
+// Evaluate raises an alert for each channel over the limit at now.
+func (r *UploadRate) Evaluate(ctx context.Context, store storage.Store, now time.Time) ([]Alert, error) {
+	name := r.Name
+	if name == "" {
+		name = "upload-rate"
+	}
+	window := r.Window
+	if window <= 0 {
+		window = 24 * time.Hour
+	}
+	channels, err := listChannels(ctx, store, r.ChannelIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var alerts []Alert
+	for _, ch := range channels {
+		videos, err := store.ListVideosByChannel(ctx, ch.ID)
+		if err != nil {
+			return alerts, fmt.Errorf("%s: list videos of %s: %w", name, ch.YouTubeID, err)
+		}
+		count := 0
+		for _, v := range videos {
+			if v.PublishedAt.After(now.Add(-window)) && !v.PublishedAt.After(now) {
+				count++
+			}
+		}
+		if count > r.Max {
+			alerts = append(alerts, Alert{
+				Rule:        name,
+				Key:         name + "/" + ch.YouTubeID,
+				ChannelID:   ch.YouTubeID,
+				ChannelName: ch.Name,
+				Value:       int64(count),
+				Threshold:   int64(r.Max),
+				Message:     fmt.Sprintf("%s posted %d videos in %s (more than %d)", channelLabel(ch), count, formatWindow(window), r.Max),
+			})
+		}
+	}
+	return alerts, nil
+}
+
+// Metric is a counter in the stats history.
+type Metric string
+
+const (
+	// Views is the view count.
+	Views Metric = "views"
+	// Likes is the like count.
+	Likes Metric = "likes"
+	// Comments is the comment count.
+	Comments Metric = "comments"
+)
+
+// value returns the metric's counter in s.
+func (m Metric) value(s *storage.VideoStats) (int64, error) {
+	switch m {
+	case Views, "":
+		return s.ViewCount, nil
+	case Likes:
+		return s.LikeCount, nil
+	case Comments:
+		return s.CommentCount, nil
+	}
+	return 0, fmt.Errorf("unknown metric %q", m)
+}
+
+// StatsGrowth alerts when a video's counter grows by more than
+// MinIncrease, such as more than 100,000 views, between stats samples. It
+// needs a store implementing storage.VideoStatsStore, sampled by
+// youtube.CaptureStats or youtube.TrackStats. Each new sample over the
+// limit raises a new alert.
+type StatsGrowth struct {
+	// Name names the rule in alerts. Default: the metric followed by
+	// "-growth", such as "views-growth".
+	Name string
+	// Metric is the counter compared. Default: Views.
+	Metric Metric
+	// MinIncrease is the growth a video may show without an alert.
+	MinIncrease int64
+	// Window, if set, compares the latest sample with the newest one taken
+	// at least Window before it, instead of with the previous sample.
+	Window time.Duration
+	// MaxAge, if set, skips videos published longer ago.
+	MaxAge time.Duration
+	// ChannelIDs limits the rule to the stored channels with these YouTube
+	// IDs. Empty checks every stored channel.
+	ChannelIDs []string
+}
+
+// Evaluate raises an alert for each video whose latest sample grew by
+// more than the limit.
+func (r *StatsGrowth) Evaluate(ctx context.Context, store storage.Store, now time.Time) ([]Alert, error) {
+	metric := r.Metric
+	if metric == "" {
+		metric = Views
+	}
+	name := r.Name
+	if name == "" {
+		name = string(metric) + "-growth"
+	}
+	statsStore, ok := store.(storage.VideoStatsStore)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, ErrNoStatsStore)
+	}
+	if _, err := metric.value(&storage.VideoStats{}); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	channels, err := listChannels(ctx, store, r.ChannelIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var alerts []Alert
+	for _, ch := range channels {
+		videos, err := store.ListVideosByChannel(ctx, ch.ID)
+		if err != nil {
+			return alerts, fmt.Errorf("%s: list videos of %s: %w", name, ch.YouTubeID, err)
+		}
+		for _, v := range videos {
+			if r.MaxAge > 0 && !v.PublishedAt.IsZero() && now.Sub(v.PublishedAt) > r.MaxAge {
+				continue
+			}
+			history, err := statsStore.GetStatsHistory(ctx, v.ID, time.Time{}, now)
+			if err != nil {
+				return alerts, fmt.Errorf("%s: stats of %s: %w", name, v.YouTubeID, err)
+			}
+			latest, base := r.compared(history)
+			if base == nil {
+				continue
+			}
+			to, _ := metric.value(latest)
+			from, _ := metric.value(base)
+			if growth := to - from; growth > r.MinIncrease {
+				alerts = append(alerts, Alert{
+					Rule:        name,
+					Key:         name + "/" + v.YouTubeID + "/" + strconv.FormatInt(latest.SampledAt.UnixNano(), 10),
+					ChannelID:   ch.YouTubeID,
+					ChannelName: ch.Name,
+					VideoID:     v.YouTubeID,
+					VideoTitle:  v.Title,
+					Value:       growth,
+					Threshold:   r.MinIncrease,
+					Message: fmt.Sprintf("%q gained %d %s in %s (more than %d)",
+						v.Title, growth, metric, formatWindow(latest.SampledAt.Sub(base.SampledAt)), r.MinIncrease),
+				})
+			}
+		}
+	}
+	return alerts, nil
+}
+
+// compared returns the latest sample in history, which is oldest first,
+// and the sample it is compared with, or nil if there is none.
+func (r *StatsGrowth) compared(history []*storage.VideoStats) (latest, base *storage.VideoStats) {
+	if len(history) < 2 {
+		return nil, nil
+	}
+	latest = history[len(history)-1]
+	if r.Window <= 0 {
+		return latest, history[len(history)-2]
+	}
+	for i := len(history) - 2; i >= 0; i-- {
+		if latest.SampledAt.Sub(history[i].SampledAt) >= r.Window {
+			return latest, history[i]
+		}
+	}
+	return latest, nil
+}
+
+// listChannels returns the stored channels with the given YouTube IDs, or
+// every stored channel if ids is empty.
+func listChannels(ctx context.Context, store storage.Store, ids []string) ([]*storage.Channel, error) {
+	if len(ids) == 0 {
+		channels, err := store.ListChannels(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list channels: %w", err)
+		}
+		return channels, nil
+	}
+	var channels []*storage.Channel
+	for _, id := range ids {
+		ch, err := store.GetChannelByYouTubeID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("look up channel %s: %w", id, err)
+		}
+		channels = append(channels, ch)
+	}
+	return channels, nil
+}
+
+// channelLabel returns the channel's name, or its ID if it has none.
+func channelLabel(ch *storage.Channel) string {
+	if ch.Name != "" {
+		return ch.Name
+	}
+	return ch.YouTubeID
+}
+
+// formatWindow formats d in whole days or hours where it can.
+func formatWindow(d time.Duration) string {
+	switch {
+	case d == 24*time.Hour:
+		return "a day"
+	case d > 0 && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d days", d/(24*time.Hour))
+	case d == time.Hour:
+		return "an hour"
+	case d > 0 && d%time.Hour == 0:
+		return fmt.Sprintf("%d hours", d/time.Hour)
+	}
+	return d.Round(time.Minute).String()
+}
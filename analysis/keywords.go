@@ -0,0 +1,317 @@
+// Package analysis derives keywords and topics from stored transcripts,
+// for tagging and faceted browsing of an archive without external
+// services.
+//
+// ExtractKeywords ranks the terms of one transcript by TF-IDF against a
+// Corpus, usually the transcripts of the same channel, so words the channel
+// says in every video rank below words particular to the transcript.
+// SummarizeChannel does this for every transcript of a channel, combines
+// the results into channel topics, and saves them to stores implementing
+// storage.KeywordStore, where GetChannelKeywords reads them back.
+package analysis
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"ytsync/storage"
+)
+
+// DefaultMaxKeywords is the number of keywords returned when
+// Options.MaxKeywords is zero.
+const DefaultMaxKeywords = 10
+
+// DefaultMinTermLength is the shortest term considered when
+// Options.MinTermLength is zero.
+const DefaultMinTermLength = 3
+
+// Options configures keyword extraction.
+type Options struct {
+	// Corpus weights terms by how rare they are across its documents. If
+	// nil, terms are ranked by frequency alone.
+	Corpus *Corpus
+	// MaxKeywords is the number of keywords returned (default
+	// DefaultMaxKeywords).
+	MaxKeywords int
+	// MinTermLength is the shortest term considered, in letters (default
+	// DefaultMinTermLength).
+	MinTermLength int
+	// StopWords are ignored in addition to the built-in English stop words
+	// and transcript fillers such as "um" and "gonna".
+	StopWords []string
+}
+
+func (o *Options) maxKeywords() int {
+	if o == nil || o.MaxKeywords <= 0 {
+		return DefaultMaxKeywords
+	}
+	return o.MaxKeywords
+}
+
+func (o *Options) minTermLength() int {
+	if o == nil || o.MinTermLength <= 0 {
+		return DefaultMinTermLength
+	}
+	return o.MinTermLength
+}
+
+func (o *Options) corpus() *Corpus {
+	if o == nil {
+		return nil
+	}
+	return o.Corpus
+}
+
+// stopWords returns the set of ignored terms.
+func (o *Options) stopWords() map[string]bool {
+	if o == nil || len(o.StopWords) == 0 {
+		return defaultStopWords
+	}
+	words := make(map[string]bool, len(defaultStopWords)+len(o.StopWords))
+	for w := range defaultStopWords {
+		words[w] = true
+	}
+	for _, w := range o.StopWords {
+		words[strings.ToLower(w)] = true
+	}
+	return words
+}
+
+// Corpus holds the document frequency of each term in a set of
+// transcripts. It is not safe for concurrent modification.
+type Corpus struct {
+	docs int
+	df   map[string]int
+}
+
+// NewCorpus creates an empty corpus.
+func NewCorpus() *Corpus {
+	return &Corpus{df: make(map[string]int)}
+}
+
+// Add adds a document's text to the corpus.
+func (c *Corpus) Add(text string) {
+	c.docs++
+	seen := make(map[string]bool)
+	for _, term := range Tokenize(text) {
+		if !seen[term] {
+			seen[term] = true
+			c.df[term]++
+		}
+	}
+}
+
+// Docs returns the number of documents added.
+func (c *Corpus) Docs() int {
+	return c.docs
+}
+
+// idf returns the smoothed inverse document frequency of term, which is 1
+// for a term in no document and falls as the term becomes more common.
+func (c *Corpus) idf(term string) float64 {
+	if c == nil || c.docs == 0 {
+		return 1
+	}
+	return math.Log(float64(1+c.docs)/float64(1+c.df[term])) + 1
+}
+
+// ChannelCorpus builds a corpus from every transcript of the channel with
+// internal ID channelID.
+func ChannelCorpus(ctx context.Context, store storage.TranscriptStore, channelID string) (*Corpus, error) {
+	transcripts, err := store.ListTranscriptsByChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	corpus := NewCorpus()
+	for _, t := range transcripts {
+		corpus.Add(t.Content)
+	}
+	return corpus, nil
+}
+
+// Tokenize splits text into lowercased words. Apostrophes inside a word
+// are kept, so "don't" is one token.
+func Tokenize(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, strings.Trim(word.String(), "'"))
+			word.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(unicode.ToLower(r))
+		case (r == '\'' || r == '’') && word.Len() > 0:
+			word.WriteRune('\'')
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// ExtractKeywords returns the most characteristic terms of a transcript,
+// best first. Stop words, numbers, and terms shorter than
+// opts.MinTermLength are skipped. opts may be nil.
+func ExtractKeywords(transcript *storage.Transcript, opts *Options) []storage.Keyword {
+	if transcript == nil {
+		return nil
+	}
+	counts, total := termCounts(transcript.Content, opts)
+	if total == 0 {
+		return nil
+	}
+
+	corpus := opts.corpus()
+	keywords := make([]storage.Keyword, 0, len(counts))
+	for term, n := range counts {
+		tf := float64(n) / float64(total)
+		keywords = append(keywords, storage.Keyword{Term: term, Score: tf * corpus.idf(term), Count: n})
+	}
+	return topKeywords(keywords, opts.maxKeywords())
+}
+
+// termCounts counts the eligible terms of text and returns the counts and
+// their sum.
+func termCounts(text string, opts *Options) (map[string]int, int) {
+	stop := opts.stopWords()
+	minLen := opts.minTermLength()
+
+	counts := make(map[string]int)
+	total := 0
+	for _, term := range Tokenize(text) {
+		if stop[term] || isNumber(term) || len([]rune(term)) < minLen {
+			continue
+		}
+		counts[term]++
+		total++
+	}
+	return counts, total
+}
+
+func isNumber(term string) bool {
+	for _, r := range term {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// topKeywords sorts keywords by score, then term, and returns the first n.
+func topKeywords(keywords []storage.Keyword, n int) []storage.Keyword {
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		return keywords[i].Term < keywords[j].Term
+	})
+	if len(keywords) > n {
+		keywords = keywords[:n]
+	}
+	return keywords
+}
+
+// SummarizeChannel extracts the keywords of every transcript of the
+// channel with internal ID channelID, weighted against the channel's own
+// transcripts, and ranks channel topics by their summed score. If store
+// implements storage.KeywordStore, the summary is saved there. If
+// opts.Corpus is nil, the channel corpus is used.
+func SummarizeChannel(ctx context.Context, store storage.Store, channelID string, opts *Options) (*storage.ChannelKeywords, error) {
+	transcripts, err := store.ListTranscriptsByChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	perVideo := Options{}
+	if opts != nil {
+		perVideo = *opts
+	}
+	if perVideo.Corpus == nil {
+		perVideo.Corpus = NewCorpus()
+		for _, t := range transcripts {
+			perVideo.Corpus.Add(t.Content)
+		}
+	}
+
+	summary := &storage.ChannelKeywords{
+		ChannelID:   channelID,
+		Videos:      make(map[string][]storage.Keyword, len(transcripts)),
+		Transcripts: len(transcripts),
+		ComputedAt:  time.Now(),
+	}
+	topics := make(map[string]*storage.Keyword)
+	for _, t := range transcripts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		keywords := ExtractKeywords(t, &perVideo)
+		if len(keywords) == 0 {
+			continue
+		}
+		summary.Videos[t.VideoID] = keywords
+		for _, k := range keywords {
+			topic, ok := topics[k.Term]
+			if !ok {
+				topic = &storage.Keyword{Term: k.Term}
+				topics[k.Term] = topic
+			}
+			topic.Score += k.Score
+			topic.Count += k.Count
+			topic.Videos++
+		}
+	}
+
+	summary.Topics = make([]storage.Keyword, 0, len(topics))
+	for _, topic := range topics {
+		summary.Topics = append(summary.Topics, *topic)
+	}
+	summary.Topics = topKeywords(summary.Topics, opts.maxKeywords())
+
+	if ks, ok := store.(storage.KeywordStore); ok {
+		if err := ks.SaveChannelKeywords(ctx, summary); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+// defaultStopWords are common English words and spoken fillers that say
+// nothing about a transcript's topic.
+var defaultStopWords = toSet(`
+a about above after again against all also am an and any are aren't as at
+be because been before being below between both but by can can't cannot
+could couldn't did didn't do does doesn't doing don't down during each few
+for from further had hadn't has hasn't have haven't having he he'd he'll
+he's her here here's hers herself him himself his how how's i i'd i'll i'm
+i've if in into is isn't it it's its itself let's me more most mustn't my
+myself no nor not of off on once only or other ought our ours ourselves out
+over own same shan't she she'd she'll she's should shouldn't so some such
+than that that's the their theirs them themselves then there there's these
+they they'd they'll they're they've this those through to too under until
+up very was wasn't we we'd we'll we're we've were weren't what what's when
+when's where where's which while who who's whom why why's will with won't
+would wouldn't you you'd you'll you're you've your yours yourself
+yourselves
+um uh uhm hmm mm oh ah okay ok yeah yes yep like just really actually
+basically literally gonna wanna gotta kinda sorta know mean think thing
+things stuff right well going get got getting go goes say said says see
+look want make made lot lots way one two also even still much many now
+today here there something anything everything nothing someone everyone
+kind sort pretty little bit guys guy let us want music applause laughter
+`)
+
+func toSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
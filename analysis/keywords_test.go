@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"ytsync/storage"
+)
+
+func terms(keywords []storage.Keyword) []string {
+	out := make([]string, len(keywords))
+	for i, k := range keywords {
+		out[i] = k.Term
+	}
+	return out
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Don't PANIC: it's 42 — café’s “soldering” iron!")
+	want := []string{"don't", "panic", "it's", "42", "café's", "soldering", "iron"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize() = %q, want %q", got, want)
+	}
+}
+
+func TestExtractKeywords_TermFrequency(t *testing.T) {
+	transcript := &storage.Transcript{Content: "Um, so today we solder. Soldering irons get hot, so solder carefully. " +
+		"The solder joint should be shiny. I have 2 irons."}
+
+	got := ExtractKeywords(transcript, &Options{MaxKeywords: 2})
+	if want := []string{"solder", "irons"}; !reflect.DeepEqual(terms(got), want) {
+		t.Errorf("ExtractKeywords() = %q, want %q", terms(got), want)
+	}
+	if got[0].Count != 3 {
+		t.Errorf("solder count = %d, want 3", got[0].Count)
+	}
+}
+
+func TestExtractKeywords_CorpusWeighting(t *testing.T) {
+	corpus := NewCorpus()
+	corpus.Add("welcome back to the workshop, today a bench build")
+	corpus.Add("welcome back to the workshop, today a lathe restoration")
+	corpus.Add("welcome back to the workshop, today sharpening chisels")
+
+	// Every term occurs once, but "workshop" is in every document
+	transcript := &storage.Transcript{Content: "welcome back to the workshop, today lathe restoration"}
+	got := ExtractKeywords(transcript, &Options{Corpus: corpus, MaxKeywords: 2})
+	if want := []string{"lathe", "restoration"}; !reflect.DeepEqual(terms(got), want) {
+		t.Errorf("ExtractKeywords() = %q, want %q", terms(got), want)
+	}
+	if all := ExtractKeywords(transcript, &Options{Corpus: corpus}); all[len(all)-1].Score >= got[0].Score {
+		t.Errorf("common term score %v, want below %v", all[len(all)-1].Score, got[0].Score)
+	}
+}
+
+func TestExtractKeywords_StopWordsAndLength(t *testing.T) {
+	transcript := &storage.Transcript{Content: "arduino arduino arduino led led sensor"}
+	got := ExtractKeywords(transcript, &Options{StopWords: []string{"Arduino"}, MinTermLength: 4})
+	if want := []string{"sensor"}; !reflect.DeepEqual(terms(got), want) {
+		t.Errorf("ExtractKeywords() = %q, want %q", terms(got), want)
+	}
+	if got := ExtractKeywords(&storage.Transcript{Content: "um, uh, the"}, nil); len(got) != 0 {
+		t.Errorf("ExtractKeywords(fillers) = %q, want none", terms(got))
+	}
+}
+
+func TestSummarizeChannel(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewJSONStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	channel := &storage.Channel{YouTubeID: "UCanalysis", Name: "Workshop"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatal(err)
+	}
+	contents := map[string]string{
+		"v1": "welcome to the workshop, today the lathe. lathe tooling and lathe speeds",
+		"v2": "welcome to the workshop, today the lathe chuck and a lathe restoration",
+		"v3": "welcome to the workshop, today chisels. sharpening chisels on stones",
+	}
+	for id, content := range contents {
+		if err := store.CreateVideo(ctx, &storage.Video{ID: id, YouTubeID: "yt" + id, ChannelID: channel.ID}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CreateTranscript(ctx, &storage.Transcript{VideoID: id, Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summary, err := SummarizeChannel(ctx, store, channel.ID, &Options{MaxKeywords: 3})
+	if err != nil {
+		t.Fatalf("SummarizeChannel() error = %v", err)
+	}
+	if summary.Transcripts != 3 || len(summary.Videos) != 3 {
+		t.Errorf("summary covers %d transcripts, %d videos; want 3, 3", summary.Transcripts, len(summary.Videos))
+	}
+	if len(summary.Topics) == 0 || summary.Topics[0].Term != "lathe" || summary.Topics[0].Videos != 2 {
+		t.Errorf("Topics = %+v, want lathe first, from 2 videos", summary.Topics)
+	}
+	if got := terms(summary.Videos["v3"]); len(got) == 0 || got[0] != "chisels" {
+		t.Errorf("v3 keywords = %q, want chisels first", got)
+	}
+
+	saved, err := store.GetChannelKeywords(ctx, channel.ID)
+	if err != nil {
+		t.Fatalf("GetChannelKeywords() error = %v", err)
+	}
+	if !reflect.DeepEqual(terms(saved.Topics), terms(summary.Topics)) {
+		t.Errorf("saved topics = %q, want %q", terms(saved.Topics), terms(summary.Topics))
+	}
+
+	if err := store.DeleteChannel(ctx, channel.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetChannelKeywords(ctx, channel.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("GetChannelKeywords() after DeleteChannel error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,304 @@
+// Package cache is a small embedded key-value cache kept in a single file,
+// separate from the main store. It holds values that are expensive to look
+// up and cheap to lose: channel handle resolutions and video metadata.
+//
+// Entries live in named buckets and may expire. Several processes can share
+// one cache file: writers serialize on an advisory file lock and replace the
+// file atomically, and readers pick up other processes' writes when the file
+// changes. Expired entries are skipped on read and removed by Prune.
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+	"ytsync/errcode"
+	"ytsync/storage"
+)
+
+// formatVersion is the version of the cache file layout.
+const formatVersion = 1
+
+// lockTimeout bounds how long a write waits for another process.
+const lockTimeout = 5 * time.Second
+
+// Buckets used by the adapters in this package.
+const (
+	BucketMetadata = "metadata"
+	BucketAliases  = "channel_aliases"
+)
+
+// ErrCorrupt is returned when the cache file cannot be parsed. Deleting the
+// file is always safe.
+var ErrCorrupt = errcode.New(errcode.Corrupt, "cache: file is corrupt")
+
+// DefaultPath returns the cache file used when none is configured:
+// ytsync/cache.json in the user's cache directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ytsync", "cache.json"), nil
+}
+
+// entry is a cached value.
+type entry struct {
+	Value     json.RawMessage `json:"value"`
+	StoredAt  time.Time       `json:"stored_at"`
+	ExpiresAt time.Time       `json:"expires_at,omitempty"`
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// cacheFile is the JSON layout of the cache file.
+type cacheFile struct {
+	Version int                          `json:"version"`
+	Buckets map[string]map[string]*entry `json:"buckets"`
+}
+
+// Cache is a file-backed key-value cache. It is safe for concurrent use by
+// multiple goroutines and processes.
+type Cache struct {
+	path string
+	lock *storage.FileLock
+
+	mu      sync.Mutex
+	buckets map[string]map[string]*entry
+	loaded  os.FileInfo // the file buckets was read from, nil if none
+	now     func() time.Time
+}
+
+// Open opens the cache at path, creating its directory if needed. The file
+// itself is created on the first write.
+func Open(path string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+	c := &Cache{
+		path:    path,
+		lock:    storage.NewFileLock(path),
+		buckets: make(map[string]map[string]*entry),
+		now:     time.Now,
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.refresh(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Path returns the cache file path.
+func (c *Cache) Path() string {
+	return c.path
+}
+
+// Close releases the cache. Every write is already on disk.
+func (c *Cache) Close() error {
+	return nil
+}
+
+// Get decodes the value stored under key in bucket into v and reports
+// whether one was found. Expired entries are not found.
+func (c *Cache) Get(bucket, key string, v any) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.refresh(); err != nil {
+		return false, err
+	}
+	e, ok := c.buckets[bucket][key]
+	if !ok || e.expired(c.now()) {
+		return false, nil
+	}
+	if err := json.Unmarshal(e.Value, v); err != nil {
+		return false, fmt.Errorf("decode cached %s %q: %w", bucket, key, err)
+	}
+	return true, nil
+}
+
+// Put stores v under key in bucket. A positive ttl makes the entry expire
+// after that long; zero keeps it until it is deleted or replaced.
+func (c *Cache) Put(bucket, key string, v any, ttl time.Duration) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode %s %q: %w", bucket, key, err)
+	}
+	return c.update(func(buckets map[string]map[string]*entry, now time.Time) bool {
+		e := &entry{Value: value, StoredAt: now}
+		if ttl > 0 {
+			e.ExpiresAt = now.Add(ttl)
+		}
+		if buckets[bucket] == nil {
+			buckets[bucket] = make(map[string]*entry)
+		}
+		buckets[bucket][key] = e
+		return true
+	})
+}
+
+// Delete removes key from bucket. Deleting a missing key is not an error.
+func (c *Cache) Delete(bucket, key string) error {
+	return c.update(func(buckets map[string]map[string]*entry, now time.Time) bool {
+		if _, ok := buckets[bucket][key]; !ok {
+			return false
+		}
+		delete(buckets[bucket], key)
+		return true
+	})
+}
+
+// Keys returns the unexpired keys of bucket in sorted order.
+func (c *Cache) Keys(bucket string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.refresh(); err != nil {
+		return nil, err
+	}
+	now := c.now()
+	var keys []string
+	for key, e := range c.buckets[bucket] {
+		if !e.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Stats returns the number of entries in each bucket, expired ones
+// included.
+func (c *Cache) Stats() (map[string]int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.refresh(); err != nil {
+		return nil, err
+	}
+	stats := make(map[string]int, len(c.buckets))
+	for name, entries := range c.buckets {
+		stats[name] = len(entries)
+	}
+	return stats, nil
+}
+
+// Prune removes expired entries and returns how many were removed.
+func (c *Cache) Prune() (int, error) {
+	return c.PruneOlderThan(0)
+}
+
+// PruneOlderThan removes expired entries and, if maxAge is positive,
+// entries stored more than maxAge ago. It returns how many were removed.
+func (c *Cache) PruneOlderThan(maxAge time.Duration) (int, error) {
+	removed := 0
+	err := c.update(func(buckets map[string]map[string]*entry, now time.Time) bool {
+		for name, entries := range buckets {
+			for key, e := range entries {
+				if e.expired(now) || (maxAge > 0 && now.Sub(e.StoredAt) > maxAge) {
+					delete(entries, key)
+					removed++
+				}
+			}
+			if len(entries) == 0 {
+				delete(buckets, name)
+			}
+		}
+		return removed > 0
+	})
+	return removed, err
+}
+
+// update applies fn to the latest contents of the cache file under the
+// file lock and writes the result if fn reports a change.
+func (c *Cache) update(fn func(buckets map[string]map[string]*entry, now time.Time) bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.lock.Lock(lockTimeout); err != nil {
+		return err
+	}
+	defer c.lock.Unlock()
+
+	// Another process may have written since we last read
+	if err := c.refresh(); err != nil {
+		return err
+	}
+	if !fn(c.buckets, c.now()) {
+		return nil
+	}
+	if err := c.write(); err != nil {
+		// Memory now differs from the file; reread it next time
+		c.loaded = nil
+		return err
+	}
+	return nil
+}
+
+// refresh reloads the cache file if it changed since it was last read.
+// The caller must hold c.mu.
+func (c *Cache) refresh() error {
+	info, err := os.Stat(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		c.buckets = make(map[string]map[string]*entry)
+		c.loaded = nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("stat cache: %w", err)
+	}
+	// Every write replaces the file, so the same file is unchanged
+	if c.loaded != nil && os.SameFile(c.loaded, info) && info.ModTime().Equal(c.loaded.ModTime()) {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return fmt.Errorf("read cache: %w", err)
+	}
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrCorrupt, c.path, err)
+	}
+	if file.Version > formatVersion {
+		return fmt.Errorf("%w: %s has format version %d, this version reads %d", ErrCorrupt, c.path, file.Version, formatVersion)
+	}
+	if file.Buckets == nil {
+		file.Buckets = make(map[string]map[string]*entry)
+	}
+	c.buckets = file.Buckets
+	c.loaded = info
+	return nil
+}
+
+// write replaces the cache file with the current contents. The caller must
+// hold c.mu and the file lock.
+func (c *Cache) write() error {
+	data, err := json.Marshal(cacheFile{Version: formatVersion, Buckets: c.buckets})
+	if err != nil {
+		return fmt.Errorf("encode cache: %w", err)
+	}
+	w, err := storage.NewAtomicWriter(c.path)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Abort()
+		return fmt.Errorf("write cache: %w", err)
+	}
+	if err := w.Commit(); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+	if info, err := os.Stat(c.path); err == nil {
+		c.loaded = info
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"ytsync/errcode"
+	"ytsync/storage"
+	"ytsync/youtube"
+)
+
+func openTestCache(t *testing.T, path string) *Cache {
+	t.Helper()
+	c, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestCache_PutGetDelete(t *testing.T) {
+	c := openTestCache(t, filepath.Join(t.TempDir(), "cache.json"))
+
+	var got []string
+	if ok, err := c.Get("b", "k", &got); ok || err != nil {
+		t.Fatalf("Get(missing) = %v, %v; want false, nil", ok, err)
+	}
+	if err := c.Put("b", "k", []string{"x", "y"}, 0); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if ok, err := c.Get("b", "k", &got); !ok || err != nil || !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Errorf("Get() = %v, %v, %q", ok, err, got)
+	}
+	if err := c.Delete("b", "k"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if ok, _ := c.Get("b", "k", &got); ok {
+		t.Error("Get() after Delete found the entry")
+	}
+	if err := c.Delete("b", "k"); err != nil {
+		t.Errorf("Delete(missing) error = %v", err)
+	}
+}
+
+func TestCache_TTLAndPrune(t *testing.T) {
+	c := openTestCache(t, filepath.Join(t.TempDir(), "cache.json"))
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Put("b", "short", 1, time.Minute)
+	c.Put("b", "long", 2, time.Hour)
+	c.Put("b", "forever", 3, 0)
+
+	now = now.Add(2 * time.Minute)
+	var v int
+	if ok, _ := c.Get("b", "short", &v); ok {
+		t.Error("Get() returned an expired entry")
+	}
+	if keys, _ := c.Keys("b"); !reflect.DeepEqual(keys, []string{"forever", "long"}) {
+		t.Errorf("Keys() = %q, want [forever long]", keys)
+	}
+
+	removed, err := c.Prune()
+	if err != nil || removed != 1 {
+		t.Fatalf("Prune() = %d, %v; want 1", removed, err)
+	}
+	if stats, _ := c.Stats(); stats["b"] != 2 {
+		t.Errorf("Stats() = %v, want 2 entries in b", stats)
+	}
+
+	removed, err = c.PruneOlderThan(time.Minute)
+	if err != nil || removed != 2 {
+		t.Errorf("PruneOlderThan() = %d, %v; want 2", removed, err)
+	}
+}
+
+func TestCache_SharedBetweenInstances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	a := openTestCache(t, path)
+	b := openTestCache(t, path)
+
+	if err := a.Put("b", "from-a", "a", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Put("b", "from-b", "b", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each write starts from the other's latest file, so neither is lost
+	for _, c := range []*Cache{a, b} {
+		if keys, err := c.Keys("b"); err != nil || !reflect.DeepEqual(keys, []string{"from-a", "from-b"}) {
+			t.Errorf("Keys() = %q, %v; want both writes", keys, err)
+		}
+	}
+}
+
+func TestCache_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Open(path)
+	if !errors.Is(err, ErrCorrupt) || errcode.Of(err) != errcode.Corrupt {
+		t.Errorf("Open(corrupt) error = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestMetadataStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	store := openTestCache(t, path).MetadataStore(time.Hour)
+
+	if err := store.Put(&youtube.VideoMetadata{ID: "vid1", Title: "First"}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	other := openTestCache(t, path).MetadataStore(time.Hour)
+	if m, ok := other.Get("vid1"); !ok || m.Title != "First" {
+		t.Errorf("Get() from another instance = %+v, %v", m, ok)
+	}
+	if err := other.Delete("vid1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.Get("vid1"); ok {
+		t.Error("Get() after Delete found the entry")
+	}
+}
+
+func TestAliasStore(t *testing.T) {
+	ctx := context.Background()
+	aliases := openTestCache(t, filepath.Join(t.TempDir(), "cache.json")).AliasStore(DefaultAliasTTL)
+
+	if _, err := aliases.GetChannelAlias(ctx, "@Workshop"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("GetChannelAlias(missing) error = %v, want ErrNotFound", err)
+	}
+	if err := aliases.SaveChannelAlias(ctx, &storage.ChannelAlias{Alias: "https://www.youtube.com/@Workshop", YouTubeID: "UCold"}); err != nil {
+		t.Fatalf("SaveChannelAlias() error = %v", err)
+	}
+	if err := aliases.SaveChannelAlias(ctx, &storage.ChannelAlias{Alias: "@workshop", YouTubeID: "UCnew"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := aliases.GetChannelAlias(ctx, "workshop")
+	if err != nil {
+		t.Fatalf("GetChannelAlias() error = %v", err)
+	}
+	if got.Alias != "@workshop" || got.YouTubeID != "UCnew" || len(got.Previous) != 1 || got.Previous[0].YouTubeID != "UCold" {
+		t.Errorf("GetChannelAlias() = %+v, want UCnew with UCold in Previous", got)
+	}
+	if list, err := aliases.ListChannelAliases(ctx, "UCnew"); err != nil || len(list) != 1 {
+		t.Errorf("ListChannelAliases() = %v, %v; want one alias", list, err)
+	}
+	if err := aliases.SaveChannelAlias(ctx, &storage.ChannelAlias{Alias: "not a handle.com", YouTubeID: "UCx"}); !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("SaveChannelAlias(invalid) error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+	"ytsync/storage"
+	"ytsync/youtube"
+)
+
+// DefaultAliasTTL is how long a cached handle resolution is trusted before
+// it is resolved again. Handles can be released and claimed by another
+// channel, so resolutions should not be kept forever.
+const DefaultAliasTTL = 7 * 24 * time.Hour
+
+// MetadataStore returns a youtube.MetadataCacheStore kept in the cache, so
+// processes sharing the cache file share fetched metadata. Entries are
+// dropped ttl after they are stored; set it to at least the MetadataCache's
+// TTL plus StaleTTL. Read errors are treated as misses.
+func (c *Cache) MetadataStore(ttl time.Duration) youtube.MetadataCacheStore {
+	return &metadataStore{cache: c, ttl: ttl}
+}
+
+type metadataStore struct {
+	cache *Cache
+	ttl   time.Duration
+}
+
+func (s *metadataStore) Get(videoID string) (*youtube.VideoMetadata, bool) {
+	var m youtube.VideoMetadata
+	ok, err := s.cache.Get(BucketMetadata, videoID, &m)
+	if err != nil || !ok {
+		return nil, false
+	}
+	return &m, true
+}
+
+func (s *metadataStore) Put(m *youtube.VideoMetadata) error {
+	return s.cache.Put(BucketMetadata, m.ID, m, s.ttl)
+}
+
+func (s *metadataStore) Delete(videoID string) error {
+	return s.cache.Delete(BucketMetadata, videoID)
+}
+
+// AliasStore returns a storage.ChannelAliasStore kept in the cache, for
+// sharing handle and custom URL resolutions between processes without a
+// main store. Resolutions expire ttl after they were last saved.
+func (c *Cache) AliasStore(ttl time.Duration) storage.ChannelAliasStore {
+	return &aliasStore{cache: c, ttl: ttl}
+}
+
+type aliasStore struct {
+	cache *Cache
+	ttl   time.Duration
+}
+
+func (s *aliasStore) SaveChannelAlias(ctx context.Context, alias *storage.ChannelAlias) error {
+	if alias == nil || alias.YouTubeID == "" {
+		return &storage.StorageError{Op: "update", Entity: "channel_alias", Err: storage.ErrInvalidInput}
+	}
+	key, kind, ok := storage.NormalizeChannelAlias(alias.Alias)
+	if !ok {
+		return &storage.StorageError{Op: "update", Entity: "channel_alias", ID: alias.Alias, Err: storage.ErrInvalidInput}
+	}
+
+	var encodeErr error
+	err := s.cache.update(func(buckets map[string]map[string]*entry, now time.Time) bool {
+		var existing *storage.ChannelAlias
+		if e, ok := buckets[BucketAliases][key]; ok && !e.expired(now) {
+			existing = new(storage.ChannelAlias)
+			if json.Unmarshal(e.Value, existing) != nil {
+				existing = nil
+			}
+		}
+		switch {
+		case existing == nil:
+			existing = &storage.ChannelAlias{Alias: key, Kind: kind, YouTubeID: alias.YouTubeID, ResolvedAt: now}
+		case existing.YouTubeID != alias.YouTubeID:
+			// The alias was released and claimed by another channel
+			existing.Previous = append(existing.Previous, storage.AliasTarget{
+				YouTubeID: existing.YouTubeID,
+				From:      existing.ResolvedAt,
+				Until:     existing.LastSeenAt,
+			})
+			existing.YouTubeID = alias.YouTubeID
+			existing.ResolvedAt = now
+		}
+		existing.LastSeenAt = now
+
+		value, err := json.Marshal(existing)
+		if err != nil {
+			encodeErr = err
+			return false
+		}
+		e := &entry{Value: value, StoredAt: now}
+		if s.ttl > 0 {
+			e.ExpiresAt = now.Add(s.ttl)
+		}
+		if buckets[BucketAliases] == nil {
+			buckets[BucketAliases] = make(map[string]*entry)
+		}
+		buckets[BucketAliases][key] = e
+		*alias = *existing
+		return true
+	})
+	if encodeErr != nil {
+		return encodeErr
+	}
+	return err
+}
+
+func (s *aliasStore) GetChannelAlias(ctx context.Context, alias string) (*storage.ChannelAlias, error) {
+	key, _, ok := storage.NormalizeChannelAlias(alias)
+	if !ok {
+		return nil, &storage.StorageError{Op: "read", Entity: "channel_alias", ID: alias, Err: storage.ErrInvalidInput}
+	}
+	var mapping storage.ChannelAlias
+	found, err := s.cache.Get(BucketAliases, key, &mapping)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &storage.StorageError{Op: "read", Entity: "channel_alias", ID: alias, Err: storage.ErrNotFound}
+	}
+	return &mapping, nil
+}
+
+func (s *aliasStore) ListChannelAliases(ctx context.Context, youtubeID string) ([]*storage.ChannelAlias, error) {
+	keys, err := s.cache.Keys(BucketAliases)
+	if err != nil {
+		return nil, err
+	}
+	var aliases []*storage.ChannelAlias
+	for _, key := range keys {
+		var mapping storage.ChannelAlias
+		found, err := s.cache.Get(BucketAliases, key, &mapping)
+		if err != nil {
+			return nil, err
+		}
+		if found && mapping.YouTubeID == youtubeID {
+			aliases = append(aliases, &mapping)
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].ResolvedAt.Before(aliases[j].ResolvedAt)
+	})
+	return aliases, nil
+}
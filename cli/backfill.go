@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"ytsync"
+	"ytsync/youtube"
+)
+
+func cmdBackfill(args []string) {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	channel := fs.String("channel", "", "Only backfill this tracked channel (ID, URL, or handle)")
+	batch := fs.Int("batch", youtube.DefaultBackfillBatchSize, "Videos to look up per request batch")
+	timeout := addTimeoutFlag(fs, "Give up after this long, keeping the dates found so far (default: global --timeout, else no limit)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: ytsync backfill [flags]
+
+Looks up the publish dates of stored videos that have none and saves them.
+Uses the YouTube Data API when it is enabled (1 quota unit per 50 videos),
+player requests otherwise.
+
+Flags:
+`)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ctx, cancel := commandContext(timeout(0))
+	defer cancel()
+	var channelIDs []string
+	if *channel != "" {
+		store := openStore(*storePath, true)
+		channelIDs = []string{findChannel(ctx, store, *channel).YouTubeID}
+		store.Close()
+	}
+
+	result, err := ytsync.BackfillPublished(ctx, &ytsync.BackfillOptions{
+		StorePath:  *storePath,
+		ChannelIDs: channelIDs,
+		BatchSize:  *batch,
+		OnProgress: func(p youtube.BackfillProgress) {
+			fmt.Fprintf(os.Stderr, "Looked up %d/%d videos, %d dated\n", p.Done, p.Total, p.Updated)
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error backfilling publish dates: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, id := range result.Unresolved {
+		fmt.Printf("%s\tno publish date found\n", id)
+	}
+	for id, err := range result.Errors {
+		fmt.Printf("%s\t%v\n", id, err)
+	}
+	fmt.Fprintf(os.Stderr, "Checked %d videos: %d without a publish date, %d updated, %d unresolved, %d failed\n",
+		result.Scanned, result.Missing, result.Updated, len(result.Unresolved), len(result.Errors))
+	if len(result.Errors) > 0 {
+		os.Exit(1)
+	}
+}
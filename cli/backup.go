@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"ytsync/storage"
+)
+
+func cmdBackup(args []string) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	blobDir := fs.String("blobs", "", "Blob directory holding transcript text, if the store uses one")
+	output := fs.String("o", "", "Write the archive to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync backup [flags] > backup.tar.gz\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	store := openStore(*storePath, true)
+	defer store.Close()
+	attachBlobs(store, *blobDir)
+
+	var w io.Writer = os.Stdout
+	var writer *storage.AtomicWriter
+	if *output != "" {
+		var err error
+		writer, err = storage.NewAtomicWriter(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", *output, err)
+			os.Exit(1)
+		}
+		w = writer
+	}
+
+	manifest, err := store.Backup(context.Background(), w)
+	if err != nil {
+		if writer != nil {
+			writer.Abort()
+		}
+		fmt.Fprintf(os.Stderr, "Error backing up store: %v\n", err)
+		os.Exit(1)
+	}
+	if writer != nil {
+		if err := writer.Commit(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *output, err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Backed up %d channels, %d videos, %d transcripts (schema %s)\n",
+		manifest.Channels, manifest.Videos, manifest.Transcripts, manifest.SchemaVersion)
+}
+
+func cmdRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	blobDir := fs.String("blobs", "", "Blob directory to write transcript text to")
+	force := fs.Bool("force", false, "Replace a store that already tracks channels")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync restore [flags] <backup.tar.gz>\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Error: missing backup file\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening backup: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	store := openStore(*storePath, false)
+	defer store.Close()
+	attachBlobs(store, *blobDir)
+
+	ctx := context.Background()
+	if !*force {
+		channels, err := store.ListChannels(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading store: %v\n", err)
+			os.Exit(1)
+		}
+		if len(channels) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: %s already tracks %d channels; use --force to replace it\n", *storePath, len(channels))
+			os.Exit(1)
+		}
+	}
+
+	manifest, err := store.Restore(ctx, f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error restoring backup: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Restored %d channels, %d videos, %d transcripts from a backup taken %s\n",
+		manifest.Channels, manifest.Videos, manifest.Transcripts, manifest.CreatedAt.Local().Format("2006-01-02 15:04"))
+}
+
+// attachBlobs sets the blob store in dir on store, if dir is not empty.
+func attachBlobs(store *storage.JSONStore, dir string) {
+	if dir == "" {
+		return
+	}
+	blobs, err := storage.NewBlobStore(dir, storage.CompressionGzip)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening blob store %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+	if enc := storeEncryptor(storeConfig()); enc != nil {
+		blobs.SetEncryptor(enc)
+	}
+	store.SetBlobStore(blobs)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"ytsync/cache"
+	"ytsync/config"
+)
+
+func cmdCache(args []string) {
+	if len(args) == 0 {
+		printCacheUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "prune":
+		cmdCachePrune(args[1:])
+	case "stats":
+		cmdCacheStats(args[1:])
+	case "help", "-h", "--help":
+		printCacheUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown cache command %q\n\n", args[0])
+		printCacheUsage()
+		os.Exit(1)
+	}
+}
+
+func printCacheUsage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  ytsync cache prune [flags]  Remove expired entries from the shared cache
+  ytsync cache stats [flags]  Show the number of entries in each bucket
+
+The cache file is YTSYNC_CACHE_PATH if set, else ytsync/cache.json in the
+user cache directory.
+
+Examples:
+  ytsync cache prune
+  ytsync cache prune --older-than 168h
+  ytsync cache stats --cache /shared/ytsync-cache.json
+`)
+}
+
+func cmdCachePrune(args []string) {
+	fs := flag.NewFlagSet("cache prune", flag.ExitOnError)
+	cachePath := fs.String("cache", "", "Path to the cache file")
+	olderThan := fs.Duration("older-than", 0, "Also remove entries stored longer ago than this")
+	fs.Parse(args)
+
+	c := openCache(*cachePath)
+	defer c.Close()
+
+	removed, err := c.PruneOlderThan(*olderThan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error pruning cache: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Removed %d entries from %s\n", removed, c.Path())
+}
+
+func cmdCacheStats(args []string) {
+	fs := flag.NewFlagSet("cache stats", flag.ExitOnError)
+	cachePath := fs.String("cache", "", "Path to the cache file")
+	fs.Parse(args)
+
+	c := openCache(*cachePath)
+	defer c.Close()
+
+	stats, err := c.Stats()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading cache: %v\n", err)
+		os.Exit(1)
+	}
+	buckets := make([]string, 0, len(stats))
+	for name := range stats {
+		buckets = append(buckets, name)
+	}
+	sort.Strings(buckets)
+
+	fmt.Println(c.Path())
+	for _, name := range buckets {
+		fmt.Printf("  %-20s %d\n", name, stats[name])
+	}
+}
+
+// openCache opens the cache at path, the configured cache, or the default
+// cache, exiting on failure.
+func openCache(path string) *cache.Cache {
+	if path == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		path = cfg.CachePath
+	}
+	if path == "" {
+		var err error
+		if path, err = cache.DefaultPath(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error locating cache: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	c, err := cache.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening cache: %v\n", err)
+		os.Exit(1)
+	}
+	return c
+}
@@ -0,0 +1,689 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"ytsync"
+	"ytsync/config"
+	"ytsync/storage"
+	"ytsync/youtube"
+
+	"github.com/google/uuid"
+)
+
+// defaultStorePath is the store the channel commands use when --store is
+// not given. main replaces it with the global --store flag or the
+// configured store_path.
+var defaultStorePath = "ytsync.json"
+
+func cmdChannel(args []string) {
+	if len(args) == 0 {
+		printChannelUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "add":
+		cmdChannelAdd(args[1:])
+	case "remove", "rm":
+		cmdChannelRemove(args[1:])
+	case "list", "ls":
+		cmdChannelList(args[1:])
+	case "show":
+		cmdChannelShow(args[1:])
+	case "coverage":
+		cmdChannelCoverage(args[1:])
+	case "import":
+		cmdChannelImport(args[1:])
+	case "help", "-h", "--help":
+		printChannelUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown channel command %q\n\n", args[0])
+		printChannelUsage()
+		os.Exit(1)
+	}
+}
+
+func printChannelUsage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  ytsync channel add [flags] <channel>     Track a channel (ID, URL, or @handle)
+  ytsync channel remove [flags] <channel>  Stop tracking a channel
+  ytsync channel list [flags]              List tracked channels
+  ytsync channel show [flags] <channel>    Show a channel's details and sync status
+  ytsync channel coverage [flags] <channel>
+                                           Report transcript coverage and missing transcripts
+  ytsync channel import [flags] <file>     Track every channel in a subscription export
+
+Examples:
+  ytsync channel add @Fireship --transcripts
+  ytsync channel add https://www.youtube.com/c/Fireship --type both --max 500
+  ytsync channel add @Fireship --art --blobs ~/archive/blobs
+  ytsync channel list --store ~/archive/ytsync.json
+  ytsync channel remove @Fireship --purge
+  ytsync channel coverage @Fireship --missing 50
+  ytsync channel import subscriptions.csv --transcripts
+
+For help on a command: ytsync channel <command> -h
+`)
+}
+
+func cmdChannelAdd(args []string) {
+	fs := flag.NewFlagSet("channel add", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	contentType := fs.String("type", "videos", "Content to sync: videos, streams, or both")
+	maxVideos := fs.Int("max", 0, "Maximum videos to list per sync (0 = all)")
+	transcripts := fs.Bool("transcripts", false, "Fetch transcripts for new videos")
+	metadata := fs.Bool("metadata", false, "Fetch full metadata for new videos")
+	paused := fs.Bool("paused", false, "Add the channel without syncing it yet")
+	art := fs.Bool("art", false, "Download the channel's avatar and banner")
+	blobDir := fs.String("blobs", "", "Blob directory to keep channel art in (default: inline in the store)")
+	timeout := addTimeoutFlag(fs, "Give up on resolving the channel after this long (default: global --timeout, else ytdlp_timeout)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync channel add [flags] <channel>\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	input := requireChannelArg(fs)
+	switch *contentType {
+	case "videos", "streams", "both":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid --type value %q (use videos, streams, or both)\n", *contentType)
+		os.Exit(1)
+	}
+
+	store := openStore(*storePath, false)
+	defer store.Close()
+	attachBlobs(store, *blobDir)
+
+	ctx, cancel := commandContext(timeout(storeConfig().YtdlpTimeout))
+	defer cancel()
+
+	fmt.Fprintf(os.Stderr, "Resolving %s...\n", input)
+	resolver := youtube.NewChannelResolver()
+	resolver.Aliases = store
+	info, err := resolver.FetchChannelInfo(ctx, input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving channel: %v\n", err)
+		os.Exit(1)
+	}
+
+	if existing, err := store.GetChannelByYouTubeID(ctx, info.ID); err == nil {
+		fmt.Fprintf(os.Stderr, "Error: channel %s (%s) is already tracked\n", existing.Name, existing.YouTubeID)
+		os.Exit(1)
+	}
+
+	channel := &storage.Channel{
+		YouTubeID:   info.ID,
+		Name:        info.Name,
+		Description: info.Description,
+		URL:         info.URL,
+		Handle:      info.Handle,
+		Policy: &storage.SyncPolicy{
+			ContentType: *contentType,
+			MaxVideos:   *maxVideos,
+			Transcripts: *transcripts,
+			Metadata:    *metadata,
+			Paused:      *paused,
+		},
+	}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving channel: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Added %s (%s)\n", channelLabel(channel), channel.YouTubeID)
+
+	if *art {
+		changed, err := youtube.NewChannelArtFetcher().Sync(ctx, store, channel, info)
+		for _, kind := range changed {
+			fmt.Fprintf(os.Stderr, "Saved %s\n", kind)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: downloading channel art: %v\n", err)
+		}
+	}
+}
+
+func cmdChannelRemove(args []string) {
+	fs := flag.NewFlagSet("channel remove", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	purge := fs.Bool("purge", false, "Also delete the channel's videos and transcripts")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync channel remove [flags] <channel>\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	input := requireChannelArg(fs)
+	store := openStore(*storePath, false)
+	defer store.Close()
+	ctx := context.Background()
+
+	channel := findChannel(ctx, store, input)
+	purged := 0
+	if *purge {
+		videos, err := store.ListVideosByChannel(ctx, channel.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing videos: %v\n", err)
+			os.Exit(1)
+		}
+		for _, v := range videos {
+			if err := store.DeleteVideo(ctx, v.ID); err != nil {
+				fmt.Fprintf(os.Stderr, "Error deleting video %s: %v\n", v.YouTubeID, err)
+				os.Exit(1)
+			}
+			purged++
+		}
+	}
+	if err := store.DeleteChannel(ctx, channel.ID); err != nil {
+		fmt.Fprintf(os.Stderr, "Error removing channel: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Removed %s (%s)", channelLabel(channel), channel.YouTubeID)
+	if *purge {
+		fmt.Printf(" and %d videos", purged)
+	}
+	fmt.Println()
+}
+
+func cmdChannelList(args []string) {
+	fs := flag.NewFlagSet("channel list", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync channel list [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	store := openStore(*storePath, true)
+	defer store.Close()
+	ctx := context.Background()
+
+	channels, err := store.ListChannels(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing channels: %v\n", err)
+		os.Exit(1)
+	}
+	if len(channels) == 0 {
+		fmt.Println("No channels tracked. Add one with: ytsync channel add <channel>")
+		return
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return strings.ToLower(channels[i].Name) < strings.ToLower(channels[j].Name)
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANNEL ID\tNAME\tVIDEOS\tTRANSCRIPTS\tLAST SYNC\tSTATUS")
+	for _, ch := range channels {
+		stats := channelStats(ctx, store, ch)
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n",
+			ch.YouTubeID,
+			truncate(channelLabel(ch), 40),
+			stats.videos,
+			stats.coverage(),
+			formatLastSync(stats.lastSync),
+			stats.status,
+		)
+	}
+	w.Flush()
+
+	fmt.Fprintf(os.Stderr, "\nTotal: %d channels\n", len(channels))
+}
+
+func cmdChannelShow(args []string) {
+	fs := flag.NewFlagSet("channel show", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	about := fs.Bool("about", false, "Also fetch the channel's about tab: links, country, join date, and views")
+	timeout := addTimeoutFlag(fs, "Give up on fetching the about tab after this long (default: global --timeout, else ytdlp_timeout)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync channel show [flags] <channel>\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	input := requireChannelArg(fs)
+	store := openStore(*storePath, true)
+	defer store.Close()
+	ctx := context.Background()
+
+	ch := findChannel(ctx, store, input)
+	stats := channelStats(ctx, store, ch)
+	policy := ch.Policy
+	if policy == nil {
+		policy = &storage.SyncPolicy{}
+	}
+	contentType := policy.ContentType
+	if contentType == "" {
+		contentType = "videos"
+	}
+	maxVideos := "all"
+	if policy.MaxVideos > 0 {
+		maxVideos = fmt.Sprintf("%d", policy.MaxVideos)
+	}
+
+	fmt.Printf("Name:          %s\n", ch.Name)
+	fmt.Printf("Channel ID:    %s\n", ch.YouTubeID)
+	if ch.Handle != "" {
+		fmt.Printf("Handle:        %s\n", ch.Handle)
+	}
+	fmt.Printf("URL:           %s\n", ch.URL)
+	if ch.Avatar != nil {
+		fmt.Printf("Avatar:        %s (%d bytes)\n", ch.Avatar.URL, ch.Avatar.Size)
+	}
+	if ch.Banner != nil {
+		fmt.Printf("Banner:        %s (%d bytes)\n", ch.Banner.URL, ch.Banner.Size)
+	}
+	fmt.Printf("Added:         %s\n", ch.CreatedAt.Format("2006-01-02 15:04"))
+	fmt.Println()
+	fmt.Printf("Videos:        %d\n", stats.videos)
+	fmt.Printf("Transcripts:   %d (%s)\n", stats.transcripts, stats.coverage())
+	fmt.Printf("Last sync:     %s\n", formatLastSync(stats.lastSync))
+	fmt.Printf("Status:        %s\n", stats.status)
+	if stats.lastError != "" {
+		fmt.Printf("Last error:    %s\n", stats.lastError)
+	}
+	fmt.Println("\nSync policy:")
+	fmt.Printf("  Content:     %s\n", contentType)
+	fmt.Printf("  Max videos:  %s\n", maxVideos)
+	fmt.Printf("  Transcripts: %v\n", policy.Transcripts)
+	fmt.Printf("  Metadata:    %v\n", policy.Metadata)
+	fmt.Printf("  Paused:      %v\n", policy.Paused)
+	if ch.Description != "" {
+		fmt.Printf("\n%s\n", ch.Description)
+	}
+	if *about {
+		printChannelAbout(timeout(storeConfig().YtdlpTimeout), ch.YouTubeID)
+	}
+}
+
+// printChannelAbout fetches and prints the about tab of a channel, giving
+// up after timeout.
+func printChannelAbout(timeout time.Duration, channelID string) {
+	ctx, cancel := commandContext(timeout)
+	defer cancel()
+	info, err := ytsync.FetchChannelInfo(ctx, channelID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	about := info.About
+
+	fmt.Println("\nAbout:")
+	if about.Country != "" {
+		fmt.Printf("  Country:     %s\n", about.Country)
+	}
+	if !about.JoinedAt.IsZero() {
+		fmt.Printf("  Joined:      %s\n", about.JoinedAt.Format("2006-01-02"))
+	}
+	if about.ViewCount > 0 {
+		fmt.Printf("  Views:       %d\n", about.ViewCount)
+	}
+	if about.SubscriberCount != "" {
+		fmt.Printf("  Subscribers: %s\n", about.SubscriberCount)
+	}
+	fmt.Printf("  Business:    %v\n", about.HasBusinessEmail)
+	for _, email := range about.Emails {
+		fmt.Printf("  Email:       %s\n", email)
+	}
+	for _, link := range about.Links {
+		fmt.Printf("  Link:        %s <%s>\n", link.Title, link.URL)
+	}
+}
+
+func cmdChannelCoverage(args []string) {
+	fs := flag.NewFlagSet("channel coverage", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	blobDir := fs.String("blobs", "", "Directory of the blob store, if transcripts are kept in one")
+	missing := fs.Int("missing", 20, "Number of videos without a transcript to list (0 for all)")
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync channel coverage [flags] <channel>\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	input := requireChannelArg(fs)
+	store := openStore(*storePath, true)
+	defer store.Close()
+	attachBlobs(store, *blobDir)
+	ctx := context.Background()
+
+	ch := findChannel(ctx, store, input)
+	report, err := storage.TranscriptCoverage(ctx, store, ch.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reporting coverage: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error formatting JSON: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Printf("Channel:       %s\n", channelLabel(ch))
+	fmt.Printf("Videos:        %d\n", report.Videos)
+	fmt.Printf("Transcribed:   %d (%.0f%%)\n", report.WithTranscript, 100*report.Coverage())
+	fmt.Printf("Transcripts:   %d (%d automatic, %d manual)\n", report.Transcripts, report.AutoGenerated, report.Manual)
+
+	if len(report.Languages) > 0 {
+		fmt.Println("\nLanguages:")
+		languages := make([]string, 0, len(report.Languages))
+		for lang := range report.Languages {
+			languages = append(languages, lang)
+		}
+		sort.Slice(languages, func(i, j int) bool {
+			if report.Languages[languages[i]] != report.Languages[languages[j]] {
+				return report.Languages[languages[i]] > report.Languages[languages[j]]
+			}
+			return languages[i] < languages[j]
+		})
+		for _, lang := range languages {
+			fmt.Printf("  %-12s %d\n", lang, report.Languages[lang])
+		}
+	}
+
+	if len(report.Missing) == 0 {
+		return
+	}
+	fmt.Printf("\nMissing transcripts (%d):\n", len(report.Missing))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VIDEO ID\tPUBLISHED\tTITLE\tLAST FAILURE")
+	for i, m := range report.Missing {
+		if *missing > 0 && i == *missing {
+			break
+		}
+		failure := "-"
+		if m.Failure != nil {
+			failure = fmt.Sprintf("%s: %s", m.Failure.At.Local().Format("2006-01-02"), truncate(m.Failure.Reason, 60))
+		}
+		published := "-"
+		if !m.PublishedAt.IsZero() {
+			published = m.PublishedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.YouTubeID, published, truncate(m.Title, 40), failure)
+	}
+	w.Flush()
+	if *missing > 0 && len(report.Missing) > *missing {
+		fmt.Fprintf(os.Stderr, "\n%d more not shown (see: --missing 0)\n", len(report.Missing)-*missing)
+	}
+}
+
+func cmdChannelImport(args []string) {
+	fs := flag.NewFlagSet("channel import", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	format := fs.String("format", "", "File format: opml, csv, or takeout (default: from extension)")
+	contentType := fs.String("type", "videos", "Content to sync: videos, streams, or both")
+	maxVideos := fs.Int("max", 0, "Maximum videos to list per sync (0 = all)")
+	transcripts := fs.Bool("transcripts", false, "Fetch transcripts for new videos")
+	metadata := fs.Bool("metadata", false, "Fetch full metadata for new videos")
+	paused := fs.Bool("paused", false, "Add the channels without syncing them yet")
+	timeout := addTimeoutFlag(fs, "Give up on the import after this long (default: global --timeout, else no limit)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync channel import [flags] <file>\n\n")
+		fmt.Fprintf(os.Stderr, "Reads an OPML feed list, a CSV file (such as Google Takeout's\n")
+		fmt.Fprintf(os.Stderr, "subscriptions.csv), or Takeout's subscriptions.json.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Error: missing file\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	path := fs.Arg(0)
+	switch *contentType {
+	case "videos", "streams", "both":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid --type value %q (use videos, streams, or both)\n", *contentType)
+		os.Exit(1)
+	}
+	subFormat := youtube.SubscriptionFormat(*format)
+	if subFormat == "" {
+		subFormat = subscriptionFormatFor(path)
+	}
+	switch subFormat {
+	case youtube.SubscriptionsOPML, youtube.SubscriptionsCSV, youtube.SubscriptionsTakeout:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: cannot tell the format of %s; use --format opml, csv, or takeout\n", path)
+		os.Exit(1)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	store := openStore(*storePath, false)
+	defer store.Close()
+
+	resolver := youtube.NewChannelResolver()
+	resolver.Aliases = store
+	importer := &youtube.SubscriptionImporter{
+		Store:    store,
+		Resolver: resolver,
+		Policy: &storage.SyncPolicy{
+			ContentType: *contentType,
+			MaxVideos:   *maxVideos,
+			Transcripts: *transcripts,
+			Metadata:    *metadata,
+			Paused:      *paused,
+		},
+	}
+	ctx, cancel := commandContext(timeout(0))
+	defer cancel()
+	results, err := importer.Import(ctx, f, subFormat)
+	if err != nil && len(results) == 0 {
+		fmt.Fprintf(os.Stderr, "Error importing %s: %v\n", path, err)
+		os.Exit(1)
+	}
+
+	counts := make(map[youtube.ImportStatus]int)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROW\tCHANNEL\tCHANNEL ID\tSTATUS")
+	for _, r := range results {
+		counts[r.Status]++
+		label := r.Title
+		if label == "" {
+			label = r.Channel
+		}
+		status := string(r.Status)
+		if r.Err != nil {
+			status += ": " + r.Err.Error()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", r.Row, truncate(label, 40), r.ChannelID, status)
+	}
+	w.Flush()
+
+	fmt.Fprintf(os.Stderr, "\nImported %d channels (%d already tracked, %d duplicates, %d failed)\n",
+		counts[youtube.ImportCreated], counts[youtube.ImportExisting],
+		counts[youtube.ImportDuplicate], counts[youtube.ImportFailed])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	if counts[youtube.ImportFailed] > 0 {
+		os.Exit(1)
+	}
+}
+
+// subscriptionFormatFor guesses a subscription export's format from its
+// file extension.
+func subscriptionFormatFor(path string) youtube.SubscriptionFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".opml", ".xml":
+		return youtube.SubscriptionsOPML
+	case ".csv":
+		return youtube.SubscriptionsCSV
+	case ".json":
+		return youtube.SubscriptionsTakeout
+	}
+	return ""
+}
+
+// requireChannelArg returns the single positional channel argument, exiting
+// with usage if it is missing.
+func requireChannelArg(fs *flag.FlagSet) string {
+	argv := fs.Args()
+	if len(argv) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: missing channel\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+	return argv[0]
+}
+
+// openStore opens the JSON store at path, exiting on failure. A read-only
+// store can be opened while another process, such as a running sync, has
+// the store open for writing.
+func openStore(path string, readOnly bool) *storage.JSONStore {
+	cfg := storeConfig()
+	opts := &storage.JSONStoreOptions{
+		Encryptor: storeEncryptor(cfg),
+		ReadOnly:  readOnly,
+		Lock:      &storage.LockPolicy{Timeout: cfg.StoreLockTimeout, StaleAfter: cfg.StoreLockStaleAfter},
+		IDs:       storeIDs(cfg),
+	}
+	store, err := storage.NewJSONStoreWithOptions(path, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening store %s: %v\n", path, err)
+		if errors.Is(err, storage.ErrStoreLocked) {
+			fmt.Fprintf(os.Stderr, "Another ytsync process has the store open; retry when it finishes or raise YTSYNC_STORE_LOCK_TIMEOUT\n")
+		}
+		os.Exit(1)
+	}
+	return store
+}
+
+// storeConfig loads the configuration for opening stores, exiting on
+// failure.
+func storeConfig() *config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// storeEncryptor returns the encryptor for cfg's store key, or nil if
+// stores are not encrypted, exiting if the key is invalid.
+func storeEncryptor(cfg *config.Config) storage.Encryptor {
+	if cfg.StoreEncryptionKey == "" {
+		return nil
+	}
+	enc, err := storage.ParseAESGCMKeys(cfg.StoreEncryptionKey, cfg.StorePreviousKeys...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing store encryption key: %v\n", err)
+		os.Exit(1)
+	}
+	return enc
+}
+
+// storeIDs returns how new channels and videos get their IDs under cfg,
+// exiting if the ID namespace is invalid.
+func storeIDs(cfg *config.Config) storage.IDGenerator {
+	if !cfg.StoreDeterministicIDs {
+		return storage.RandomIDs
+	}
+	namespace := uuid.Nil
+	if cfg.StoreIDNamespace != "" {
+		var err error
+		if namespace, err = uuid.Parse(cfg.StoreIDNamespace); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing store ID namespace: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	return storage.DeterministicIDs(namespace)
+}
+
+// findChannel looks up a tracked channel by internal ID, channel ID, URL,
+// or handle, exiting if it is not tracked.
+func findChannel(ctx context.Context, store *storage.JSONStore, input string) *storage.Channel {
+	if ch, err := store.GetChannel(ctx, input); err == nil {
+		return ch
+	}
+	ch, err := store.GetChannelByHandle(ctx, input)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			fmt.Fprintf(os.Stderr, "Error: channel %s is not tracked (see: ytsync channel list)\n", input)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error looking up channel: %v\n", err)
+		}
+		os.Exit(1)
+	}
+	return ch
+}
+
+// channelSummary holds the per-channel figures shown by list and show.
+type channelSummary struct {
+	videos      int
+	transcripts int
+	lastSync    time.Time
+	status      string
+	lastError   string
+}
+
+func (s channelSummary) coverage() string {
+	if s.videos == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", 100*float64(s.transcripts)/float64(s.videos))
+}
+
+func channelStats(ctx context.Context, store *storage.JSONStore, ch *storage.Channel) channelSummary {
+	summary := channelSummary{status: "never synced"}
+	if videos, err := store.ListVideosByChannel(ctx, ch.ID); err == nil {
+		summary.videos = len(videos)
+		for _, v := range videos {
+			if v.HasTranscript {
+				summary.transcripts++
+			}
+		}
+	}
+	if state, err := store.GetSyncState(ctx, ch.YouTubeID); err == nil {
+		summary.lastSync = state.LastSyncAt
+		summary.status = state.Status
+		summary.lastError = state.LastError
+	}
+	if ch.Policy != nil && ch.Policy.Paused {
+		summary.status = "paused"
+	}
+	return summary
+}
+
+// channelLabel returns the channel's name, or its handle or ID if unnamed.
+func channelLabel(ch *storage.Channel) string {
+	switch {
+	case ch.Name != "":
+		return ch.Name
+	case ch.Handle != "":
+		return ch.Handle
+	}
+	return ch.YouTubeID
+}
+
+func formatLastSync(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	"ytsync/config"
+	"ytsync/download"
+	"ytsync/youtube"
+)
+
+// batchDownload configures a download of every video of a channel or
+// playlist.
+type batchDownload struct {
+	storePath   string
+	max         int
+	since       string
+	concurrency int
+	rulesPath   string
+	timeout     time.Duration
+	options     *download.Options
+}
+
+// interruptContext returns a context cancelled on Ctrl-C or SIGTERM, so
+// running tools are stopped instead of left behind.
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// isVideoTarget reports whether a download argument names a single video
+// rather than a channel or playlist.
+func isVideoTarget(target string) bool {
+	_, err := youtube.ParseVideoID(target)
+	return err == nil
+}
+
+// runBatchDownload lists the channel or playlist at target and downloads
+// the videos not yet in the store's download archive with a
+// download.Manager, recording each finished download in the archive.
+func runBatchDownload(target string, batch *batchDownload) {
+	var since time.Time
+	if batch.since != "" {
+		t, err := time.Parse(time.RFC3339, batch.since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing --since: %v (use RFC3339 format)\n", err)
+			os.Exit(1)
+		}
+		since = t
+	}
+	if batch.options.Select != "" {
+		if _, err := youtube.ParseFormatSelector(batch.options.Select); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := commandContext(batch.timeout)
+	defer stop()
+
+	store := openStore(batch.storePath, false)
+	defer store.Close()
+	rules, rulesPath := loadRules(batch.rulesPath, batch.storePath)
+	if rules != nil {
+		fmt.Fprintf(os.Stderr, "Applying rules from %s\n", rulesPath)
+	}
+
+	if strings.HasPrefix(target, "@") {
+		target = "https://www.youtube.com/" + target
+	}
+	lister := youtube.NewYtdlpLister()
+	lister.Path = cfg.YtdlpPath
+	lister.Timeout = cfg.YtdlpTimeout
+	// Channel tabs are newest first, so --since can end the listing early;
+	// playlists are in the playlist's own order
+	lister.Incremental = !strings.Contains(target, "list=")
+
+	fmt.Fprintf(os.Stderr, "Listing videos from %s...\n", target)
+	videos, err := lister.ListVideos(ctx, target, &youtube.ListOptions{PublishedAfter: since, Rules: rules})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing videos: %v\n", err)
+		os.Exit(1)
+	}
+
+	var ids []string
+	archived := 0
+	for _, v := range videos {
+		done, err := store.IsDownloaded(ctx, v.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading download archive: %v\n", err)
+			os.Exit(1)
+		}
+		if done {
+			archived++
+			continue
+		}
+		if batch.max > 0 && len(ids) == batch.max {
+			break
+		}
+		ids = append(ids, v.ID)
+	}
+	fmt.Fprintf(os.Stderr, "Found %d videos, %d already downloaded, downloading %d\n", len(videos), archived, len(ids))
+	if len(ids) == 0 {
+		return
+	}
+
+	downloader := youtube.NewDownloader()
+	downloader.YtdlpPath = cfg.YtdlpPath
+	finished := 0
+	manager, err := download.NewManager(downloader, download.Config{
+		Concurrency: batch.concurrency,
+		Archive:     store,
+		OnUpdate: func(item download.Item) {
+			switch item.Status {
+			case download.StatusDone:
+				finished++
+				fmt.Fprintf(os.Stderr, "[%d/%d] Downloaded %s\n", finished, len(ids), item.VideoID)
+			case download.StatusFailed:
+				finished++
+				fmt.Fprintf(os.Stderr, "[%d/%d] Failed %s: %s\n", finished, len(ids), item.VideoID, item.Error)
+			}
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating download queue: %v\n", err)
+		os.Exit(1)
+	}
+	if err := manager.AddAll(ids, batch.options); err != nil {
+		fmt.Fprintf(os.Stderr, "Error queuing downloads: %v\n", err)
+		os.Exit(1)
+	}
+
+	runErr := manager.Run(ctx)
+	stats := manager.Stats()
+	fmt.Fprintf(os.Stderr, "Downloaded %d, failed %d, not started %d\n", stats.Done, stats.Failed, stats.Pending)
+	if runErr != nil {
+		fmt.Fprintf(os.Stderr, "Download interrupted: %v\n", runErr)
+		os.Exit(1)
+	}
+	if stats.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"ytsync/storage"
+)
+
+func cmdFsck(args []string) {
+	fs := flag.NewFlagSet("fsck", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	blobDir := fs.String("blobs", "", "Blob directory holding transcript text, if the store uses one")
+	repair := fs.Bool("repair", false, "Rebuild the indexes, drop orphaned records, and rewrite the store")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync fsck [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	store := openStore(*storePath, !*repair)
+	defer store.Close()
+	attachBlobs(store, *blobDir)
+
+	ctx := context.Background()
+	var report *storage.CheckReport
+	var err error
+	if *repair {
+		report, err = store.Compact(ctx)
+	} else {
+		report, err = store.Check(ctx)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error checking store: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Println(issue)
+	}
+	fmt.Fprintf(os.Stderr, "Checked %d channels, %d videos, %d transcripts: %d issues\n",
+		report.Channels, report.Videos, report.Transcripts, len(report.Issues))
+
+	switch {
+	case report.OK():
+	case *repair:
+		fmt.Fprintf(os.Stderr, "Repaired and rewrote %s; videos of removed channels were kept\n", *storePath)
+	default:
+		fmt.Fprintf(os.Stderr, "Run with --repair to fix them\n")
+		os.Exit(1)
+	}
+}
@@ -6,22 +6,26 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
+	"regexp"
 	"strings"
 	"text/tabwriter"
 	"time"
+	"ytsync"
 	"ytsync/config"
+	"ytsync/download"
+	"ytsync/proc"
 	"ytsync/youtube"
 )
 
 func main() {
-	if len(os.Args) < 2 {
+	argv := parseGlobalFlags(os.Args[1:])
+	if len(argv) < 1 {
 		printUsage()
 		os.Exit(1)
 	}
 
-	command := os.Args[1]
-	args := os.Args[2:]
+	command := argv[0]
+	args := argv[1:]
 
 	switch command {
 	case "list":
@@ -32,24 +36,99 @@ func main() {
 		cmdDownload(args)
 	case "metadata":
 		cmdMetadata(args)
+	case "channel":
+		cmdChannel(args)
+	case "backup":
+		cmdBackup(args)
+	case "restore":
+		cmdRestore(args)
+	case "cache":
+		cmdCache(args)
+	case "backfill":
+		cmdBackfill(args)
+	case "fsck":
+		cmdFsck(args)
+	case "profile":
+		cmdProfile(args)
+	case "rules":
+		cmdRules(args)
+	case "tui":
+		cmdTUI(args)
 	case "help", "-h", "--help":
 		printUsage()
 	default:
 		// Assume it's a list command for backward compatibility
-		cmdList(os.Args[1:])
+		cmdList(argv)
 	}
 }
 
+// globalFlags maps the flags accepted before the command to the
+// environment variables config.Load reads them from, so every command
+// picks them up.
+var globalFlags = map[string]string{
+	"config":  "YTSYNC_CONFIG",
+	"profile": "YTSYNC_PROFILE",
+	"store":   "YTSYNC_STORE",
+	"timeout": "YTSYNC_TIMEOUT",
+}
+
+// parseGlobalFlags consumes the global --config, --profile, --store, and
+// --timeout flags at the start of args and returns the remaining arguments.
+// The first other argument ends the global flags.
+func parseGlobalFlags(args []string) []string {
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		env, ok := globalFlags[name]
+		if !ok || !strings.HasPrefix(args[0], "-") {
+			break
+		}
+		args = args[1:]
+		if !hasValue {
+			if len(args) == 0 {
+				fmt.Fprintf(os.Stderr, "Error: flag --%s needs a value\n", name)
+				os.Exit(1)
+			}
+			value, args = args[0], args[1:]
+		}
+		os.Setenv(env, value)
+	}
+
+	// Commands default --store to the configured store. A config that fails
+	// to load is reported by the command itself.
+	if cfg, err := config.Load(); err == nil && cfg.StorePath != "" {
+		defaultStorePath = cfg.StorePath
+	}
+	return args
+}
+
 func printUsage() {
 	fmt.Fprintf(os.Stderr, `ytsync - YouTube downloader and transcript extractor
 
 Usage:
+  ytsync [global flags] <command> [flags] [args]
+
+Commands:
   ytsync list [flags] <youtube-url>     List videos from a channel
   ytsync transcript [flags] <video-id>  Extract transcript from a video
-  ytsync download [flags] <video-id>    Download a video
-  ytsync metadata [flags] <video-id>    Fetch video metadata
+  ytsync download [flags] <video-id>    Download a video, or a channel or playlist
+  ytsync metadata [flags] <video-id>    Fetch video metadata (--batch reads IDs from stdin)
+  ytsync channel <command> [flags]      Manage tracked channels (add, remove, list, show)
+  ytsync backup [flags]                 Write a backup archive of the store
+  ytsync restore [flags] <file>         Replace the store with a backup
+  ytsync cache <command> [flags]        Manage the shared cache (prune, stats)
+  ytsync fsck [flags]                   Check the store for inconsistencies (--repair fixes them)
+  ytsync backfill [flags]               Look up missing publish dates of stored videos
+  ytsync profile <command>              Manage configuration profiles (list, use)
+  ytsync rules <command> [flags]        Check the store's include/exclude rules (test, check)
+  ytsync tui [flags]                    Watch and sync tracked channels in a terminal dashboard
   ytsync help                           Show this help message
 
+Global flags:
+  --config PATH     Config file to load instead of ytsync.json (YTSYNC_CONFIG)
+  --profile NAME    Apply the named profile on top of it (YTSYNC_PROFILE)
+  --store PATH      Default store for every command (YTSYNC_STORE)
+  --timeout DUR     Default --timeout for every command, e.g. 30m (YTSYNC_TIMEOUT)
+
 Examples:
   ytsync https://www.youtube.com/channel/UCxxxxx              # List videos (default)
   ytsync --type both --max 10 <url>                           # Advanced listing
@@ -58,8 +137,22 @@ Examples:
   ytsync download dQw4w9WgXcQ                                 # Download video
   ytsync download dQw4w9WgXcQ --audio-only                    # Audio only
   ytsync download dQw4w9WgXcQ --dir ~/Downloads               # Specify directory
+  ytsync download @Fireship --max 20 --dir ~/Fireship         # New videos of a channel
   ytsync metadata dQw4w9WgXcQ                                # Get metadata
-  ytsync metadata --format json dQw4w9WgXcQ                  # Get metadata as JSON
+  ytsync metadata --json dQw4w9WgXcQ                          # Get metadata as JSON
+  ytsync metadata --batch --json < ids.txt                    # JSON Lines for many videos
+  ytsync channel add @Fireship --transcripts                  # Track a channel
+  ytsync channel list                                         # Tracked channels and coverage
+  ytsync backup -o ytsync-backup.tar.gz                       # Back up the store
+  ytsync restore --force ytsync-backup.tar.gz                 # Restore it
+  ytsync cache prune                                          # Drop expired cache entries
+  ytsync fsck --repair                                        # Check and repair the store
+  ytsync backfill --channel @Fireship                         # Fill in missing publish dates
+  ytsync --profile staging channel list                       # Use the staging profile
+  ytsync --timeout 30m list @LargeChannel                     # Allow a long listing
+  ytsync profile use production                               # Make production the default
+  ytsync rules test dQw4w9WgXcQ                               # Why the rules include or skip a video
+  ytsync tui --store ~/archive/ytsync.json                    # Sync channels interactively
 
 For help on specific command: ytsync <command> -h
 `)
@@ -70,7 +163,17 @@ func cmdList(args []string) {
 	useRSS := fs.Bool("rss", false, "Use RSS feed instead of yt-dlp for listing")
 	maxVideos := fs.Int("max", 0, "Maximum videos to list (0 = all)")
 	since := fs.String("since", "", "Only videos published after this date (RFC3339)")
+	until := fs.String("until", "", "Only videos published before this date (RFC3339)")
 	contentTypeStr := fs.String("type", "videos", "Content type: videos, streams, or both")
+	minDuration := fs.Duration("min-duration", 0, "Skip videos shorter than this (e.g., 2m)")
+	maxDuration := fs.Duration("max-duration", 0, "Skip videos longer than this (e.g., 1h)")
+	titleMatch := fs.String("title", "", "Only videos whose title matches this regular expression")
+	minViews := fs.Int64("min-views", 0, "Skip videos with fewer views")
+	noLive := fs.Bool("no-live", false, "Skip live streams and stream recordings")
+	noMembers := fs.Bool("no-members", false, "Skip members-only videos")
+	noUpcoming := fs.Bool("no-upcoming", false, "Skip scheduled streams and premieres that have not aired")
+	rulesPath := fs.String("rules", "", "Skip videos the include/exclude rules file denies")
+	timeout := addTimeoutFlag(fs, "Give up on the listing after this long (default: global --timeout, else ytdlp_timeout)")
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: ytsync list [flags] <youtube-url>\n\nFlags:\n")
 		fs.PrintDefaults()
@@ -103,6 +206,15 @@ func cmdList(args []string) {
 		}
 		publishedAfter = t
 	}
+	var publishedBefore time.Time
+	if *until != "" {
+		t, err := time.Parse(time.RFC3339, *until)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing --until: %v (use RFC3339 format)\n", err)
+			os.Exit(1)
+		}
+		publishedBefore = t
+	}
 
 	// Parse content type
 	var contentType youtube.ContentType
@@ -118,26 +230,51 @@ func cmdList(args []string) {
 		os.Exit(1)
 	}
 
+	// Compile title filter if provided
+	var titleRegexp *regexp.Regexp
+	if *titleMatch != "" {
+		titleRegexp, err = regexp.Compile(*titleMatch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing --title: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var rules *youtube.RuleSet
+	if *rulesPath != "" {
+		rules, _ = loadRules(*rulesPath, "")
+	}
+
 	// Create lister
+	listTimeout := timeout(cfg.YtdlpTimeout)
 	var lister youtube.VideoLister
 	if *useRSS {
 		lister = youtube.NewRSSLister()
 	} else {
 		ytdlp := youtube.NewYtdlpLister()
 		ytdlp.Path = cfg.YtdlpPath
-		ytdlp.Timeout = cfg.YtdlpTimeout
+		ytdlp.Timeout = listTimeout
 		lister = ytdlp
 	}
 
 	// Build list options
 	opts := &youtube.ListOptions{
-		MaxResults:     *maxVideos,
-		PublishedAfter: publishedAfter,
-		ContentType:    contentType,
-	}
-
-	// List videos with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		MaxResults:         *maxVideos,
+		PublishedAfter:     publishedAfter,
+		PublishedBefore:    publishedBefore,
+		ContentType:        contentType,
+		MinDuration:        *minDuration,
+		MaxDuration:        *maxDuration,
+		TitleMatch:         titleRegexp,
+		MinViews:           *minViews,
+		ExcludeLive:        *noLive,
+		ExcludeMembersOnly: *noMembers,
+		ExcludeUpcoming:    *noUpcoming,
+		Rules:              rules,
+	}
+
+	// List videos with timeout, stopping yt-dlp on Ctrl-C
+	ctx, cancel := commandContext(listTimeout)
 	defer cancel()
 
 	fmt.Fprintf(os.Stderr, "Fetching videos from %s...\n", channelURL)
@@ -182,8 +319,14 @@ func cmdList(args []string) {
 
 func cmdTranscript(args []string) {
 	fs := flag.NewFlagSet("transcript", flag.ExitOnError)
-	langStr := fs.String("lang", "", "Comma-separated language codes (e.g., en,es). Empty = all available")
+	langStr := fs.String("lang", "", "Comma-separated language codes (e.g., en,es). Empty = configured preference (YTSYNC_TRANSCRIPT_LANGUAGES, English first)")
 	skipAuto := fs.Bool("no-auto", false, "Skip auto-generated captions")
+	normalize := fs.Bool("normalize", false, "Merge repeated auto-generated caption lines and strip tags like [Music]")
+	format := fs.String("format", "", "Output format: vtt, srt, json, txt, or ttml (default: a readable listing, or guessed from --out)")
+	out := fs.String("out", "", "Write to FILE, or into DIR as <video-id>.<lang>.<ext>")
+	fs.StringVar(out, "o", "", "Shorthand for --out")
+	allLangs := fs.Bool("all-langs", false, "Write one file per available language into --out DIR (default: current directory)")
+	timeout := addTimeoutFlag(fs, "Give up on the transcript after this long (default: global --timeout, else ytdlp_timeout)")
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: ytsync transcript [flags] <video-id>\n\nFlags:\n")
 		fs.PrintDefaults()
@@ -199,6 +342,24 @@ func cmdTranscript(args []string) {
 
 	videoID := argv[0]
 
+	// Check the output options before fetching anything
+	if *allLangs && *out == "" {
+		*out = "."
+	}
+	var target *transcriptTarget
+	if *out != "" {
+		var err error
+		if target, err = newTranscriptTarget(*out, *format, *allLangs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if *format != "" {
+		if _, ok := parseTranscriptFormat(*format); !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown format %q (want vtt, srt, json, txt, or ttml)\n", *format)
+			os.Exit(1)
+		}
+	}
+
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
@@ -216,22 +377,36 @@ func cmdTranscript(args []string) {
 	}
 
 	// Create extractor
+	extractTimeout := timeout(cfg.YtdlpTimeout)
 	extractor := youtube.NewTranscriptExtractor()
 	extractor.YtdlpPath = cfg.YtdlpPath
-	extractor.Timeout = cfg.YtdlpTimeout
+	extractor.Timeout = extractTimeout
 
-	// Extract transcript with configured timeout
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.YtdlpTimeout)
+	// Extract transcript with the timeout, stopping yt-dlp on Ctrl-C
+	ctx, cancel := commandContext(extractTimeout)
 	defer cancel()
 
 	fmt.Fprintf(os.Stderr, "Fetching transcript for %s...\n", videoID)
 	opts := &youtube.ExtractOptions{
 		Languages:         languages,
 		Format:            "json3",
-		SkipAutoGenerated: *skipAuto,
+		SkipAutoGenerated: *skipAuto || cfg.TranscriptSkipAutoGenerated,
+		Normalize:         *normalize,
+	}
+	if len(languages) == 0 {
+		pref := youtube.PreferenceForLanguages(cfg.TranscriptLanguages)
+		opts.Preference = &pref
 	}
 
-	transcript, err := extractor.Extract(ctx, videoID, opts)
+	var transcripts []*youtube.Transcript
+	if *allLangs {
+		opts.Preference = nil
+		transcripts, err = extractor.ExtractAll(ctx, videoID, opts)
+	} else {
+		var transcript *youtube.Transcript
+		transcript, err = extractor.Extract(ctx, videoID, opts)
+		transcripts = []*youtube.Transcript{transcript}
+	}
 	if err != nil {
 		// Check for timeout errors
 		if strings.Contains(err.Error(), "context deadline exceeded") {
@@ -243,6 +418,34 @@ func cmdTranscript(args []string) {
 		os.Exit(1)
 	}
 
+	if target != nil {
+		for _, t := range transcripts {
+			if len(t.Entries) == 0 {
+				fmt.Fprintf(os.Stderr, "No %s transcript entries to write\n", t.Language)
+				continue
+			}
+			path, err := target.write(t)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing transcript: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "Wrote %s (%s, %d entries)\n", path, t.LanguageName, len(t.Entries))
+		}
+		return
+	}
+
+	transcript := transcripts[0]
+	if *format != "" {
+		f, _ := parseTranscriptFormat(*format)
+		data, err := youtube.NewFormatConverter(transcript.Entries).ToFormat(f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(data)
+		return
+	}
+
 	// Display result
 	fmt.Printf("Video ID:      %s\n", transcript.VideoID)
 	fmt.Printf("Language:      %s (%s)\n", transcript.Language, transcript.LanguageName)
@@ -269,9 +472,17 @@ func cmdDownload(args []string) {
 	audioOnly := fs.Bool("audio-only", false, "Download audio only (MP3)")
 	outputDir := fs.String("dir", ".", "Directory to save video")
 	format := fs.String("format", "best", "Video format: best, mp4, webm, or audio quality")
+	selectRule := fs.String("select", "", "Format rule, e.g. \"best audio m4a\" or \"<=1080p avc1 preferred\" (overrides --format)")
 	noMetadata := fs.Bool("no-metadata", false, "Skip downloading metadata JSON")
+	audioLang := fs.String("audio-lang", "", "Audio track language of dubbed videos, e.g. \"en\", or \"original\"")
+	storePath := fs.String("store", defaultStorePath, "Store keeping the download archive (channels and playlists)")
+	maxVideos := fs.Int("max", 0, "Download at most this many new videos (channels and playlists, 0 = all)")
+	since := fs.String("since", "", "Only videos published after this date (RFC3339, channels and playlists)")
+	concurrency := fs.Int("concurrency", download.DefaultConcurrency, "Parallel downloads (channels and playlists)")
+	rulesPath := fs.String("rules", "", "Include/exclude rules file (channels and playlists, default: the store's rules file)")
+	timeout := addTimeoutFlag(fs, "Give up on the whole download after this long (default: global --timeout, else no limit)")
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: ytsync download [flags] <video-id>\n\nFlags:\n")
+		fmt.Fprintf(os.Stderr, "Usage: ytsync download [flags] <video-id | channel-url | playlist-url>\n\nFlags:\n")
 		fs.PrintDefaults()
 	}
 	fs.Parse(args)
@@ -285,6 +496,35 @@ func cmdDownload(args []string) {
 
 	videoID := argv[0]
 
+	if !isVideoTarget(videoID) {
+		runBatchDownload(videoID, &batchDownload{
+			storePath:   *storePath,
+			max:         *maxVideos,
+			since:       *since,
+			concurrency: *concurrency,
+			rulesPath:   *rulesPath,
+			timeout:     timeout(0),
+			options: &download.Options{
+				OutputDir:       *outputDir,
+				Format:          *format,
+				Select:          *selectRule,
+				AudioOnly:       *audioOnly,
+				AudioLanguage:   *audioLang,
+				IncludeMetadata: !*noMetadata,
+			},
+		})
+		return
+	}
+
+	var selector *youtube.FormatSelector
+	if *selectRule != "" {
+		var err error
+		if selector, err = youtube.ParseFormatSelector(*selectRule); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
@@ -292,19 +532,51 @@ func cmdDownload(args []string) {
 		os.Exit(1)
 	}
 
-	// Fetch metadata first if not skipped
+	// Stop yt-dlp, and everything it started, on Ctrl-C
+	ctx, stop := commandContext(timeout(0))
+	defer stop()
+
+	// Fetch metadata first if not skipped; format and audio track
+	// selection need it too
 	var metadata *youtube.VideoMetadata
-	if !*noMetadata {
+	if !*noMetadata || selector != nil || *audioLang != "" {
 		fmt.Fprintf(os.Stderr, "Fetching metadata...\n")
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		metadata, err = youtube.FetchMetadata(ctx, videoID, cfg.YtdlpPath)
+		metadataCtx, cancel := context.WithTimeout(ctx, cfg.YtdlpTimeout)
+		metadata, err = ytsync.FetchVideoMetadataWithConfig(metadataCtx, videoID, cfg)
 		cancel()
+		if err != nil && selector != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching formats: %v\n", err)
+			os.Exit(1)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: could not fetch metadata: %v\n", err)
 			fmt.Fprintf(os.Stderr, "Continuing with download without metadata...\n")
 		}
 	}
 
+	// Find the audio track in the requested language
+	var audioLanguage string
+	if *audioLang != "" && metadata != nil {
+		if track := youtube.FindAudioTrack(metadata.AudioTracks, *audioLang); track != nil {
+			audioLanguage = track.Language
+			fmt.Fprintf(os.Stderr, "Selected audio track %s\n", track.Language)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: no %s audio track, using the default\n", *audioLang)
+		}
+	}
+
+	// Resolve the rule to exact format IDs
+	var selected string
+	if selector != nil {
+		selection, err := selector.Select(metadata.Formats)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error selecting format: %v\n", err)
+			os.Exit(1)
+		}
+		selected = selection.FormatID()
+		fmt.Fprintf(os.Stderr, "Selected format %s\n", selected)
+	}
+
 	// Build yt-dlp arguments
 	ytdlpArgs := []string{
 		"-o", fmt.Sprintf("%s/%%(title)s.%%(ext)s", *outputDir),
@@ -312,19 +584,25 @@ func cmdDownload(args []string) {
 	}
 
 	if *audioOnly {
+		audioFormat := youtube.PreferAudioLanguage("bestaudio/best", audioLanguage)
+		if selected != "" {
+			audioFormat = selected
+		}
 		ytdlpArgs = append(ytdlpArgs,
-			"-f", "bestaudio/best",
+			"-f", audioFormat,
 			"-x",
 			"--audio-format", "mp3",
 			"--audio-quality", "192",
 		)
 	} else {
 		// Video download with best format
-		if *format == "best" {
+		if selected != "" {
+			ytdlpArgs = append(ytdlpArgs, "-f", selected)
+		} else if *format == "best" {
 			// Use a more robust format selection that falls back gracefully
-			ytdlpArgs = append(ytdlpArgs, "-f", "bestvideo[height<=1080]+bestaudio/best[height<=1080]/best")
+			ytdlpArgs = append(ytdlpArgs, "-f", youtube.PreferAudioLanguage("bestvideo[height<=1080]+bestaudio/best[height<=1080]/best", audioLanguage))
 		} else {
-			ytdlpArgs = append(ytdlpArgs, "-f", *format)
+			ytdlpArgs = append(ytdlpArgs, "-f", youtube.PreferAudioLanguage(*format, audioLanguage))
 		}
 	}
 
@@ -332,18 +610,23 @@ func cmdDownload(args []string) {
 
 	// Run yt-dlp
 	fmt.Fprintf(os.Stderr, "Downloading %s...\n", videoID)
-	cmd := exec.Command(cfg.YtdlpPath, ytdlpArgs...)
+	// yt-dlp runs in its own process group, which does not get the
+	// terminal's input, so it is not given stdin
+	cmd := proc.Command(ctx, cfg.YtdlpPath, ytdlpArgs...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
 
 	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			fmt.Fprintf(os.Stderr, "Download interrupted\n")
+			os.Exit(1)
+		}
 		fmt.Fprintf(os.Stderr, "Error downloading video: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Save metadata if we have it
-	if metadata != nil {
+	if metadata != nil && !*noMetadata {
 		metadataPath := fmt.Sprintf("%s/%s.json", *outputDir, sanitizeFilename(metadata.Title))
 		if err := saveMetadata(metadata, metadataPath); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: could not save metadata: %v\n", err)
@@ -355,101 +638,6 @@ func cmdDownload(args []string) {
 	fmt.Fprintf(os.Stderr, "Download complete!\n")
 }
 
-func cmdMetadata(args []string) {
-	fs := flag.NewFlagSet("metadata", flag.ExitOnError)
-	format := fs.String("format", "table", "Output format: table, json")
-	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: ytsync metadata [flags] <video-id>\n\nFlags:\n")
-		fs.PrintDefaults()
-	}
-	fs.Parse(args)
-
-	argv := fs.Args()
-	if len(argv) == 0 {
-		fmt.Fprintf(os.Stderr, "Error: missing video-id\n")
-		fs.Usage()
-		os.Exit(1)
-	}
-
-	videoID := argv[0]
-
-	// Load config
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Fetch metadata with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	fmt.Fprintf(os.Stderr, "Fetching metadata for %s...\n", videoID)
-	metadata, err := youtube.FetchMetadata(ctx, videoID, cfg.YtdlpPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error fetching metadata: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Display result based on format
-	switch *format {
-	case "json":
-		data, err := json.MarshalIndent(metadata, "", "  ")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error formatting JSON: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Println(string(data))
-	case "table":
-		fallthrough
-	default:
-		// Table format
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "FIELD\tVALUE")
-		fmt.Fprintln(w, "-----\t-----")
-		fmt.Fprintf(w, "ID\t%s\n", metadata.ID)
-		fmt.Fprintf(w, "Title\t%s\n", metadata.Title)
-		fmt.Fprintf(w, "Uploader\t%s\n", metadata.Uploader)
-		fmt.Fprintf(w, "Uploader ID\t%s\n", metadata.UploaderID)
-		fmt.Fprintf(w, "Upload Date\t%s\n", metadata.UploadDate)
-
-		if metadata.Duration > 0 {
-			duration := fmt.Sprintf("%d:%02d", int(metadata.Duration)/60, int(metadata.Duration)%60)
-			fmt.Fprintf(w, "Duration\t%s\n", duration)
-		}
-
-		if metadata.ViewCount > 0 {
-			fmt.Fprintf(w, "View Count\t%d\n", metadata.ViewCount)
-		}
-
-		if metadata.UploaderURL != "" {
-			fmt.Fprintf(w, "Channel URL\t%s\n", metadata.UploaderURL)
-		}
-
-		if metadata.ThumbnailURL != "" {
-			fmt.Fprintf(w, "Thumbnail\t%s\n", metadata.ThumbnailURL)
-		}
-
-		if len(metadata.Categories) > 0 {
-			fmt.Fprintf(w, "Categories\t%s\n", strings.Join(metadata.Categories, ", "))
-		}
-
-		if len(metadata.Tags) > 0 {
-			fmt.Fprintf(w, "Tags\t%s\n", strings.Join(metadata.Tags, ", "))
-		}
-
-		fmt.Fprintf(w, "Live Content\t%t\n", metadata.IsLiveContent)
-		fmt.Fprintf(w, "Fetched At\t%s\n", metadata.FetchedAt.Format(time.RFC3339))
-
-		w.Flush()
-
-		// Show description separately if it exists
-		if metadata.Description != "" {
-			fmt.Printf("\nDESCRIPTION:\n%s\n", metadata.Description)
-		}
-	}
-}
-
 // saveMetadata saves video metadata to a JSON file.
 func saveMetadata(metadata *youtube.VideoMetadata, path string) error {
 	data, err := json.MarshalIndent(metadata, "", "  ")
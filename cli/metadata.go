@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"ytsync"
+	"ytsync/config"
+	"ytsync/youtube"
+)
+
+func cmdMetadata(args []string) {
+	fs := flag.NewFlagSet("metadata", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	asJSON := fs.Bool("json", false, "Print JSON (same as --format json); one object per line with --batch")
+	batch := fs.Bool("batch", false, "Read video IDs or URLs from stdin, one per line")
+	timeout := addTimeoutFlag(fs, "Give up on each video after this long (default: global --timeout, else ytdlp_timeout)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync metadata [flags] <video-id>\n       ytsync metadata --batch [flags] < ids.txt\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *asJSON {
+		*format = "json"
+	}
+
+	argv := fs.Args()
+	if !*batch && len(argv) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: missing video-id\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.YtdlpTimeout = timeout(cfg.YtdlpTimeout)
+
+	ctx, cancel := interruptContext()
+	defer cancel()
+
+	if *batch {
+		if !runBatchMetadata(ctx, cfg, os.Stdin, *format) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	videoID := argv[0]
+	fmt.Fprintf(os.Stderr, "Fetching metadata for %s...\n", videoID)
+	metadata, err := fetchMetadata(ctx, cfg, videoID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching metadata: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Display result based on format
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(metadata, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error formatting JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	default:
+		printMetadataTable(metadata)
+	}
+}
+
+// fetchMetadata fetches the metadata of one video through the library,
+// with the configured retries and rate limit, bounded by the yt-dlp
+// timeout.
+func fetchMetadata(ctx context.Context, cfg *config.Config, videoID string) (*youtube.VideoMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.YtdlpTimeout)
+	defer cancel()
+	return ytsync.FetchVideoMetadataWithConfig(ctx, videoID, cfg)
+}
+
+// runBatchMetadata fetches the metadata of every video ID read from r,
+// skipping blank lines and lines starting with #. It prints JSON Lines in
+// json format, or a table of one row per video otherwise, and reports
+// failures on stderr without stopping. It returns whether every fetch
+// succeeded.
+func runBatchMetadata(ctx context.Context, cfg *config.Config, r io.Reader, format string) bool {
+	var w *tabwriter.Writer
+	enc := json.NewEncoder(os.Stdout)
+	if format != "json" {
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tUPLOADED\tDURATION\tVIEWS\tTITLE")
+		defer w.Flush()
+	}
+
+	ok := true
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		videoID := strings.TrimSpace(scanner.Text())
+		if videoID == "" || strings.HasPrefix(videoID, "#") {
+			continue
+		}
+		if ctx.Err() != nil {
+			fmt.Fprintf(os.Stderr, "Interrupted\n")
+			return false
+		}
+
+		metadata, err := fetchMetadata(ctx, cfg, videoID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching metadata for %s: %v\n", videoID, err)
+			ok = false
+			continue
+		}
+		if w == nil {
+			if err := enc.Encode(metadata); err != nil {
+				fmt.Fprintf(os.Stderr, "Error formatting JSON: %v\n", err)
+				return false
+			}
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", metadata.ID, metadata.UploadDate,
+			formatTimestamp(float64(metadata.Duration)), metadata.ViewCount, truncate(metadata.Title, 60))
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading video IDs: %v\n", err)
+		return false
+	}
+	return ok
+}
+
+// printMetadataTable prints the metadata of one video as a field table
+// followed by its description.
+func printMetadataTable(metadata *youtube.VideoMetadata) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tVALUE")
+	fmt.Fprintln(w, "-----\t-----")
+	fmt.Fprintf(w, "ID\t%s\n", metadata.ID)
+	fmt.Fprintf(w, "Title\t%s\n", metadata.Title)
+	fmt.Fprintf(w, "Uploader\t%s\n", metadata.Uploader)
+	fmt.Fprintf(w, "Uploader ID\t%s\n", metadata.UploaderID)
+	fmt.Fprintf(w, "Upload Date\t%s\n", metadata.UploadDate)
+
+	if metadata.Duration > 0 {
+		duration := fmt.Sprintf("%d:%02d", int(metadata.Duration)/60, int(metadata.Duration)%60)
+		fmt.Fprintf(w, "Duration\t%s\n", duration)
+	}
+
+	if metadata.ViewCount > 0 {
+		fmt.Fprintf(w, "View Count\t%d\n", metadata.ViewCount)
+	}
+
+	if metadata.UploaderURL != "" {
+		fmt.Fprintf(w, "Channel URL\t%s\n", metadata.UploaderURL)
+	}
+
+	if metadata.ThumbnailURL != "" {
+		fmt.Fprintf(w, "Thumbnail\t%s\n", metadata.ThumbnailURL)
+	}
+
+	if len(metadata.Categories) > 0 {
+		fmt.Fprintf(w, "Categories\t%s\n", strings.Join(metadata.Categories, ", "))
+	}
+
+	if len(metadata.Tags) > 0 {
+		fmt.Fprintf(w, "Tags\t%s\n", strings.Join(metadata.Tags, ", "))
+	}
+
+	if links := metadata.DescriptionLinks; links != nil {
+		if len(links.Hashtags) > 0 {
+			fmt.Fprintf(w, "Hashtags\t#%s\n", strings.Join(links.Hashtags, " #"))
+		}
+		for _, u := range links.URLs {
+			fmt.Fprintf(w, "Link\t%s\n", u)
+		}
+	}
+
+	fmt.Fprintf(w, "Live Content\t%t\n", metadata.IsLiveContent)
+	fmt.Fprintf(w, "Fetched At\t%s\n", metadata.FetchedAt.Format(time.RFC3339))
+
+	w.Flush()
+
+	// Show description separately if it exists
+	if metadata.Description != "" {
+		fmt.Printf("\nDESCRIPTION:\n%s\n", metadata.Description)
+	}
+
+	chapters := metadata.Chapters
+	if len(chapters) == 0 {
+		chapters = metadata.DerivedChapters
+	}
+	if len(chapters) > 0 {
+		fmt.Printf("\nCHAPTERS:\n")
+		for _, ch := range chapters {
+			fmt.Printf("%s  %s\n", formatTimestamp(ch.StartTime), ch.Title)
+		}
+	}
+}
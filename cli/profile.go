@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"ytsync/config"
+)
+
+func cmdProfile(args []string) {
+	if len(args) == 0 {
+		printProfileUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		cmdProfileList(args[1:])
+	case "use":
+		cmdProfileUse(args[1:])
+	case "help", "-h", "--help":
+		printProfileUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown profile command %q\n\n", args[0])
+		printProfileUsage()
+		os.Exit(1)
+	}
+}
+
+func printProfileUsage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  ytsync profile list          List profiles, marking the active one
+  ytsync profile use <name>    Make a profile the default
+  ytsync profile use --clear   Stop applying a profile by default
+
+A profile is a config file in ~/.config/ytsync/profiles/<name>.json whose
+settings are applied on top of ytsync.json, for example its own API keys,
+proxy, and store_path. --profile or YTSYNC_PROFILE selects a profile for
+one run and takes precedence over the default.
+
+Examples:
+  ytsync profile list
+  ytsync profile use staging
+  ytsync --profile production channel list
+`)
+}
+
+func cmdProfileList(args []string) {
+	fs := flag.NewFlagSet("profile list", flag.ExitOnError)
+	fs.Parse(args)
+
+	names, err := config.Profiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing profiles: %v\n", err)
+		os.Exit(1)
+	}
+	active, err := config.ActiveProfile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(names) == 0 {
+		fmt.Printf("No profiles in %s\n", config.ProfilePath("*"))
+		return
+	}
+	for _, name := range names {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+}
+
+func cmdProfileUse(args []string) {
+	fs := flag.NewFlagSet("profile use", flag.ExitOnError)
+	clearDefault := fs.Bool("clear", false, "Stop applying a profile by default")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync profile use <name>\n       ytsync profile use --clear\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	name := fs.Arg(0)
+	if (name == "") == !*clearDefault {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if err := config.SetActiveProfile(name); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if name == "" {
+		fmt.Println("No profile is applied by default")
+		return
+	}
+	fmt.Printf("Using profile %s\n", name)
+	if env := os.Getenv("YTSYNC_PROFILE"); env != "" && env != name {
+		fmt.Fprintf(os.Stderr, "Note: YTSYNC_PROFILE=%s overrides it in this shell\n", env)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"ytsync/config"
+	"ytsync/youtube"
+)
+
+func cmdRules(args []string) {
+	if len(args) == 0 {
+		printRulesUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "test":
+		cmdRulesTest(args[1:])
+	case "check":
+		cmdRulesCheck(args[1:])
+	case "help", "-h", "--help":
+		printRulesUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown rules command %q\n\n", args[0])
+		printRulesUsage()
+		os.Exit(1)
+	}
+}
+
+func printRulesUsage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  ytsync rules test [flags] <video-url>   Explain whether the rules include a video
+  ytsync rules check [flags]              Validate the rules file and list its rules
+
+The rules file of a store sits next to it: ytsync.rules.json or
+ytsync.rules.yaml for ytsync.json. Syncs and channel downloads skip the
+videos it denies. The first rule matching a video decides; videos no rule
+matches get the default action.
+
+  default: allow
+  rules:
+    - name: no-shorts
+      action: deny
+      max_duration: 60s
+    - action: deny
+      channels: [UCxxxxx]
+      title: "(?i)podcast"
+      published_before: 2020-01-01
+
+Examples:
+  ytsync rules check
+  ytsync rules test https://www.youtube.com/watch?v=dQw4w9WgXcQ
+  ytsync rules test --rules archive.rules.yaml dQw4w9WgXcQ
+`)
+}
+
+// loadRules loads the rules file at path, or the store's rules file if
+// path is empty, exiting on failure. It returns the rules and the file
+// they came from, or nil and "" if the store has no rules file.
+func loadRules(path, storePath string) (*youtube.RuleSet, string) {
+	if path == "" {
+		if path = youtube.StoreRulesPath(storePath); path == "" {
+			return nil, ""
+		}
+	}
+	rules, err := youtube.LoadRules(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading rules: %v\n", err)
+		os.Exit(1)
+	}
+	return rules, path
+}
+
+// rulesFlags adds the flags choosing the rules file to fs.
+func rulesFlags(fs *flag.FlagSet) (rulesPath, storePath *string) {
+	rulesPath = fs.String("rules", "", "Rules file (default: the store's rules file)")
+	storePath = fs.String("store", defaultStorePath, "Store whose rules file is used")
+	return rulesPath, storePath
+}
+
+func cmdRulesTest(args []string) {
+	fs := flag.NewFlagSet("rules test", flag.ExitOnError)
+	rulesPath, storePath := rulesFlags(fs)
+	timeout := addTimeoutFlag(fs, "Give up on fetching the video after this long (default: global --timeout, else ytdlp_timeout)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync rules test [flags] <video-url>\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Error: missing video-url\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	rules, path := loadRules(*rulesPath, *storePath)
+	if rules == nil {
+		fmt.Fprintf(os.Stderr, "No rules file for store %s; every video is included\n", *storePath)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	fetchTimeout := timeout(cfg.YtdlpTimeout)
+	lister := youtube.NewYtdlpLister()
+	lister.Path = cfg.YtdlpPath
+	lister.Timeout = fetchTimeout
+
+	ctx, cancel := commandContext(fetchTimeout)
+	defer cancel()
+	video, err := lister.FetchVideo(ctx, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Video:     %s %q\n", video.ID, video.Title)
+	fmt.Printf("Channel:   %s (%s)\n", video.ChannelName, video.ChannelID)
+	if video.Duration > 0 {
+		fmt.Printf("Duration:  %s\n", video.Duration)
+	}
+	if !video.Published.IsZero() {
+		fmt.Printf("Published: %s\n", video.Published.UTC().Format(time.RFC3339))
+	}
+	if rules == nil {
+		return
+	}
+	fmt.Printf("Rules:     %s\n\n", path)
+	decision := rules.Evaluate(video)
+	for _, check := range decision.Checks {
+		fmt.Printf("  %s\n", check)
+	}
+	if decision.Rule == nil {
+		fmt.Printf("  no rule matched; the default is %s\n", rules.Default)
+	}
+	fmt.Printf("\nResult: %s\n", decision)
+}
+
+func cmdRulesCheck(args []string) {
+	fs := flag.NewFlagSet("rules check", flag.ExitOnError)
+	rulesPath, storePath := rulesFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync rules check [flags]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	rules, path := loadRules(*rulesPath, *storePath)
+	if rules == nil {
+		fmt.Printf("No rules file for store %s; every video is included\n", *storePath)
+		return
+	}
+	fmt.Printf("%s: %d rules, default %s\n", path, len(rules.Rules), rules.Default)
+	for i, r := range rules.Rules {
+		var conds []string
+		if len(r.Channels) > 0 {
+			conds = append(conds, "channels "+strings.Join(r.Channels, ", "))
+		}
+		if r.Title != "" {
+			conds = append(conds, fmt.Sprintf("title %q", r.Title))
+		}
+		if r.MinDuration != "" {
+			conds = append(conds, "duration >= "+r.MinDuration)
+		}
+		if r.MaxDuration != "" {
+			conds = append(conds, "duration <= "+r.MaxDuration)
+		}
+		if r.PublishedAfter != "" {
+			conds = append(conds, "published after "+r.PublishedAfter)
+		}
+		if r.PublishedBefore != "" {
+			conds = append(conds, "published before "+r.PublishedBefore)
+		}
+		if len(conds) == 0 {
+			conds = append(conds, "every video")
+		}
+		name := ""
+		if r.Name != "" {
+			name = " " + r.Name
+		}
+		fmt.Printf("  %d.%s %s: %s\n", i+1, name, r.Action, strings.Join(conds, "; "))
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd || windows)
+
+package main
+
+import "errors"
+
+// errNoTerminal is returned where raw terminal input is not supported.
+var errNoTerminal = errors.New("terminal control is not supported on this platform")
+
+// rawTerminal is not supported on this platform.
+func rawTerminal() (restore func(), err error) {
+	return nil, errNoTerminal
+}
+
+// terminalSize is not supported on this platform.
+func terminalSize() (width, height int, err error) {
+	return 0, 0, errNoTerminal
+}
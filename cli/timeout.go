@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// addTimeoutFlag registers --timeout on fs. The returned function, called
+// after fs.Parse, returns the timeout to use: the flag if given, else the
+// global --timeout (YTSYNC_TIMEOUT) if given, else fallback. Zero means no
+// limit, so --timeout 0 lifts a global or default limit for one command.
+func addTimeoutFlag(fs *flag.FlagSet, usage string) func(fallback time.Duration) time.Duration {
+	timeout := fs.Duration("timeout", 0, usage)
+	return func(fallback time.Duration) time.Duration {
+		given := false
+		fs.Visit(func(f *flag.Flag) {
+			given = given || f.Name == "timeout"
+		})
+		if given {
+			return *timeout
+		}
+		if d, ok := globalTimeout(); ok {
+			return d
+		}
+		return fallback
+	}
+}
+
+// globalTimeout returns the global --timeout and whether it was given.
+func globalTimeout() (time.Duration, bool) {
+	v := os.Getenv("YTSYNC_TIMEOUT")
+	if v == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid --timeout %q (use a duration such as 90s or 10m)\n", v)
+		os.Exit(1)
+	}
+	return d, true
+}
+
+// commandContext returns a context cancelled on Ctrl-C or SIGTERM, so
+// running tools are stopped at once, and after timeout if it is positive.
+func commandContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := interruptContext()
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"ytsync/youtube"
+)
+
+// transcriptFormats are the formats `ytsync transcript --format` writes.
+var transcriptFormats = []youtube.Format{
+	youtube.FormatVTT,
+	youtube.FormatSRT,
+	youtube.FormatJSON,
+	youtube.FormatPlainText,
+	youtube.FormatTTML,
+}
+
+// defaultTranscriptFormat is the format of transcript files whose format
+// is neither given nor implied by the file name.
+const defaultTranscriptFormat = youtube.FormatSRT
+
+// parseTranscriptFormat returns the format named s, which may also be a
+// file extension with its dot.
+func parseTranscriptFormat(s string) (youtube.Format, bool) {
+	s = strings.ToLower(strings.TrimPrefix(s, "."))
+	for _, f := range transcriptFormats {
+		if string(f) == s {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// transcriptTarget decides where `ytsync transcript --out` writes: into
+// out as <video-id>.<lang>.<ext> if it is a directory (or must be one, for
+// several transcripts), or to the file out otherwise. The format is
+// format if given, else guessed from the file name.
+type transcriptTarget struct {
+	dir    string
+	file   string
+	format youtube.Format
+}
+
+func newTranscriptTarget(out, format string, several bool) (*transcriptTarget, error) {
+	target := &transcriptTarget{}
+	if format != "" {
+		f, ok := parseTranscriptFormat(format)
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q (want vtt, srt, json, txt, or ttml)", format)
+		}
+		target.format = f
+	}
+
+	info, err := os.Stat(out)
+	exists := err == nil
+	isDir := exists && info.IsDir()
+	if several && exists && !isDir {
+		return nil, fmt.Errorf("%s is a file; --all-langs writes into a directory", out)
+	}
+	if isDir || several || strings.HasSuffix(out, "/") || strings.HasSuffix(out, string(os.PathSeparator)) {
+		target.dir = out
+	} else {
+		target.file = out
+		if target.format == "" {
+			target.format, _ = parseTranscriptFormat(filepath.Ext(out))
+		}
+	}
+	if target.format == "" {
+		target.format = defaultTranscriptFormat
+	}
+	return target, nil
+}
+
+// path returns the file t is written to.
+func (tt *transcriptTarget) path(t *youtube.Transcript) string {
+	if tt.file != "" {
+		return tt.file
+	}
+	lang := t.Language
+	if lang == "" {
+		lang = "und"
+	}
+	return filepath.Join(tt.dir, fmt.Sprintf("%s.%s.%s", t.VideoID, lang, tt.format))
+}
+
+// write converts t and writes it to its file, returning the path.
+func (tt *transcriptTarget) write(t *youtube.Transcript) (string, error) {
+	data, err := youtube.NewFormatConverter(t.Entries).ToFormat(tt.format)
+	if err != nil {
+		return "", err
+	}
+	path := tt.path(t)
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,704 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+	"ytsync"
+	"ytsync/config"
+	ythttp "ytsync/http"
+	"ytsync/storage"
+	"ytsync/youtube"
+)
+
+// Escape sequences the dashboard draws with.
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l" // alternate screen, cursor hidden
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// maxDashboardErrors bounds the recent errors the dashboard shows.
+const maxDashboardErrors = 5
+
+// dashboardRefresh is how often the dashboard redraws between events.
+const dashboardRefresh = 250 * time.Millisecond
+
+// Dashboard states of a channel, besides the stored sync status.
+const (
+	rowQueued    = "queued"
+	rowSyncing   = "syncing"
+	rowDone      = "done"
+	rowFailed    = "failed"
+	rowCancelled = "cancelled"
+)
+
+func cmdTUI(args []string) {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	storePath := fs.String("store", defaultStorePath, "Path to the JSON store")
+	timeout := addTimeoutFlag(fs, "Give up on each channel's sync after this long (default: global --timeout, else no limit)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ytsync tui [flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Show the store's channels and sync them interactively.\n\n")
+		fmt.Fprintf(os.Stderr, "Keys: up/down or j/k select, s or enter sync, a sync all (skipping paused),\n")
+		fmt.Fprintf(os.Stderr, "c cancel the selected sync, x cancel all, r reload the store, q quit\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg := storeConfig()
+	// Syncs open the store for writing one at a time; the dashboard only
+	// reads it
+	store := openStore(*storePath, true)
+	defer store.Close()
+
+	d := &dashboard{
+		storePath: *storePath,
+		store:     store,
+		cfg:       cfg,
+		client:    ytsync.NewHTTPClient(cfg),
+		timeout:   timeout(0),
+		wake:      make(chan struct{}, 1),
+		changed:   make(chan struct{}, 1),
+	}
+	if err := d.load(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing channels: %v\n", err)
+		os.Exit(1)
+	}
+	// Edits to the config file apply to the syncs started after them
+	if path, err := config.FilePath(); err == nil {
+		if d.watcher, err = config.NewWatcher(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error watching config file: %v\n", err)
+			os.Exit(1)
+		}
+		d.watcher.OnReload = d.configReloaded
+	}
+
+	restore, err := rawTerminal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: ytsync tui needs an interactive terminal: %v\n", err)
+		os.Exit(1)
+	}
+	d.run()
+	restore()
+}
+
+// dashboard is the state of ytsync tui: the store's channels, the queue
+// of syncs, and what the running sync reported.
+type dashboard struct {
+	storePath string
+	store     *storage.JSONStore
+	watcher   *config.Watcher // nil without a config file
+	timeout   time.Duration
+	wake      chan struct{} // a sync was queued
+	changed   chan struct{} // something to redraw
+
+	mu       sync.Mutex
+	cfg      *config.Config
+	client   *ythttp.Client
+	rows     []*channelRow
+	selected int
+	queue    []*channelRow
+	running  *channelRow
+	errors   []string
+	logLine  string
+}
+
+// channelRow is one channel of the dashboard.
+type channelRow struct {
+	channel *storage.Channel
+	summary channelSummary
+	total   int // video count saved by the last full sync, if any
+
+	state       string // one of the row states, or empty for the stored status
+	phase       string
+	page        int
+	videos      int
+	transcripts int
+	stored      int
+	retries     int
+	result      string // outcome of the last sync run here
+	cancel      context.CancelFunc
+}
+
+// load reads the channels and their sync status from the store, keeping
+// the dashboard state of channels already shown.
+func (d *dashboard) load(ctx context.Context) error {
+	channels, err := d.store.ListChannels(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return strings.ToLower(channels[i].Name) < strings.ToLower(channels[j].Name)
+	})
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	known := make(map[string]*channelRow, len(d.rows))
+	for _, row := range d.rows {
+		known[row.channel.ID] = row
+	}
+	rows := make([]*channelRow, 0, len(channels))
+	for _, ch := range channels {
+		row := known[ch.ID]
+		if row == nil {
+			row = &channelRow{}
+		}
+		row.channel = ch
+		row.summary = channelStats(ctx, d.store, ch)
+		row.total = 0
+		if state, err := d.store.GetSyncState(ctx, ch.YouTubeID); err == nil {
+			row.total = state.TotalVideos
+		}
+		rows = append(rows, row)
+	}
+	d.rows = rows
+	if d.selected >= len(rows) {
+		d.selected = max(len(rows)-1, 0)
+	}
+	return nil
+}
+
+// reload picks up what syncs saved to the store.
+func (d *dashboard) reload(ctx context.Context) {
+	err := d.store.Reload(ctx)
+	if err == nil {
+		err = d.load(ctx)
+	}
+	if err != nil {
+		d.mu.Lock()
+		d.addError(fmt.Sprintf("reload %s: %v", d.storePath, err))
+		d.mu.Unlock()
+	}
+	d.redraw()
+}
+
+// run draws the dashboard and handles keys until the user quits, then
+// cancels the running sync and waits for it to save its checkpoint.
+func (d *dashboard) run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.syncLoop(ctx)
+	}()
+	if d.watcher != nil {
+		d.watcher.Start(ctx)
+	}
+
+	// Sync logs would scroll the dashboard away
+	flags := log.Flags()
+	log.SetFlags(0)
+	log.SetOutput(dashboardLog{d})
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	}()
+
+	keys := make(chan string)
+	go readKeys(keys)
+	terminate := make(chan os.Signal, 1)
+	signal.Notify(terminate, syscall.SIGTERM)
+	defer signal.Stop(terminate)
+
+	fmt.Print(enterScreen)
+	defer fmt.Print(leaveScreen)
+	ticker := time.NewTicker(dashboardRefresh)
+	defer ticker.Stop()
+	for quit := false; !quit; {
+		d.draw()
+		select {
+		case key, ok := <-keys:
+			quit = !ok || d.handleKey(ctx, key)
+		case <-terminate:
+			quit = true
+		case <-ticker.C:
+		case <-d.changed:
+		}
+	}
+
+	d.mu.Lock()
+	d.logLine = "stopping..."
+	d.mu.Unlock()
+	d.draw()
+	cancel()
+	<-done
+}
+
+// handleKey acts on a key press and reports whether to quit.
+func (d *dashboard) handleKey(ctx context.Context, key string) (quit bool) {
+	switch key {
+	case "q", "ctrl-c":
+		return true
+	case "r":
+		go d.reload(ctx)
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch key {
+	case "up", "k":
+		if d.selected > 0 {
+			d.selected--
+		}
+	case "down", "j":
+		if d.selected < len(d.rows)-1 {
+			d.selected++
+		}
+	case "s", "enter":
+		if row := d.selectedRow(); row != nil {
+			d.enqueue(row)
+		}
+	case "a":
+		for _, row := range d.rows {
+			if row.channel.Policy == nil || !row.channel.Policy.Paused {
+				d.enqueue(row)
+			}
+		}
+	case "c":
+		if row := d.selectedRow(); row != nil {
+			d.cancelRow(row)
+		}
+	case "x":
+		for _, row := range append([]*channelRow(nil), d.queue...) {
+			d.cancelRow(row)
+		}
+		if d.running != nil {
+			d.cancelRow(d.running)
+		}
+	}
+	return false
+}
+
+// selectedRow returns the selected channel, or nil if there are none. The
+// caller must hold d.mu.
+func (d *dashboard) selectedRow() *channelRow {
+	if d.selected < len(d.rows) {
+		return d.rows[d.selected]
+	}
+	return nil
+}
+
+// enqueue queues a sync of row unless it is queued or syncing. The caller
+// must hold d.mu.
+func (d *dashboard) enqueue(row *channelRow) {
+	if row.state == rowQueued || row.state == rowSyncing {
+		return
+	}
+	row.state = rowQueued
+	d.queue = append(d.queue, row)
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// cancelRow cancels the sync of row, or takes it off the queue. The caller
+// must hold d.mu.
+func (d *dashboard) cancelRow(row *channelRow) {
+	switch row.state {
+	case rowSyncing:
+		if row.cancel != nil {
+			row.cancel()
+		}
+	case rowQueued:
+		for i, queued := range d.queue {
+			if queued == row {
+				d.queue = append(d.queue[:i], d.queue[i+1:]...)
+				break
+			}
+		}
+		row.state = rowCancelled
+	}
+}
+
+// syncLoop runs the queued syncs one at a time, since each opens the
+// store for writing, until ctx is done.
+func (d *dashboard) syncLoop(ctx context.Context) {
+	for ctx.Err() == nil {
+		row := d.next()
+		if row == nil {
+			select {
+			case <-d.wake:
+			case <-ctx.Done():
+			}
+			continue
+		}
+		d.sync(ctx, row)
+		d.reload(context.WithoutCancel(ctx))
+	}
+}
+
+// next takes the first queued sync off the queue and marks it running.
+func (d *dashboard) next() *channelRow {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.queue) == 0 {
+		return nil
+	}
+	row := d.queue[0]
+	d.queue = d.queue[1:]
+	row.state = rowSyncing
+	row.phase, row.page, row.videos = "", 0, 0
+	row.transcripts, row.stored, row.retries = 0, 0, 0
+	row.result = ""
+	d.running = row
+	return row
+}
+
+// sync syncs the channel of row under its policy.
+func (d *dashboard) sync(ctx context.Context, row *channelRow) {
+	ctx, cancel := context.WithCancel(ctx)
+	if d.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+	}
+	defer cancel()
+
+	d.mu.Lock()
+	row.cancel = cancel
+	channel := row.channel
+	client, cfg := d.client, d.cfg
+	d.mu.Unlock()
+
+	opts := &ytsync.SyncOptions{
+		StorePath:  d.storePath,
+		SaveReport: true,
+		HTTPClient: client,
+		Config:     cfg,
+		OnEvent:    func(e youtube.SyncEvent) { d.event(row, e) },
+	}
+	if policy := channel.Policy; policy != nil {
+		opts.MaxResults = policy.MaxVideos
+		switch policy.ContentType {
+		case "streams":
+			opts.ContentType = youtube.ContentTypeStreams
+		case "both":
+			opts.ContentType = youtube.ContentTypeBoth
+		}
+		opts.Enrich = policy.Transcripts || policy.Metadata
+	}
+	result, err := ytsync.SyncChannelVideos(ctx, channel.YouTubeID, opts)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	row.cancel = nil
+	d.running = nil
+	switch {
+	case err == nil:
+		row.state = rowDone
+		row.result = fmt.Sprintf("%d new", result.NewVideosCount)
+		if row.transcripts > 0 {
+			row.result += fmt.Sprintf(", %d transcripts", row.transcripts)
+		}
+	case errors.Is(err, context.Canceled):
+		row.state = rowCancelled
+		row.result = "cancelled; the next sync resumes"
+	default:
+		row.state = rowFailed
+		row.result = err.Error()
+		d.addError(fmt.Sprintf("%s: %v", channelLabel(channel), err))
+	}
+	d.redraw()
+}
+
+// event records an event of the sync of row.
+func (d *dashboard) event(row *channelRow, e youtube.SyncEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	row.phase, row.page, row.videos = e.Phase, e.Page, e.Videos
+	switch e.Kind {
+	case youtube.EventTranscriptFetched:
+		row.transcripts++
+	case youtube.EventVideoStored:
+		row.stored++
+	case youtube.EventRetry:
+		row.retries++
+	case youtube.EventError:
+		d.addError(fmt.Sprintf("%s: %v", channelLabel(row.channel), e.Err))
+	}
+	d.redraw()
+}
+
+// configReloaded applies a change to the config file to the syncs started
+// after it. Changed HTTP limits replace the dashboard's HTTP client, which
+// also starts its circuits afresh.
+func (d *dashboard) configReloaded(e config.ConfigReloaded) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer d.redraw()
+	if e.Err != nil {
+		d.addError(fmt.Sprintf("config: %v", e.Err))
+		return
+	}
+
+	d.cfg = e.New
+	for _, name := range e.Changed {
+		if strings.HasPrefix(name, "http_") {
+			d.client = ytsync.NewHTTPClient(e.New)
+			break
+		}
+	}
+	var parts []string
+	if len(e.Changed) > 0 {
+		parts = append(parts, "config reloaded: "+strings.Join(e.Changed, ", "))
+	}
+	if len(e.Ignored) > 0 {
+		parts = append(parts, "restart to apply "+strings.Join(e.Ignored, ", "))
+	}
+	d.logLine = strings.Join(parts, "; ")
+}
+
+// addError records a recent error, dropping the oldest past
+// maxDashboardErrors. The caller must hold d.mu.
+func (d *dashboard) addError(msg string) {
+	d.errors = append(d.errors, time.Now().Format("15:04:05")+" "+msg)
+	if n := len(d.errors); n > maxDashboardErrors {
+		d.errors = d.errors[n-maxDashboardErrors:]
+	}
+}
+
+// redraw asks for the dashboard to be drawn again.
+func (d *dashboard) redraw() {
+	select {
+	case d.changed <- struct{}{}:
+	default:
+	}
+}
+
+// draw renders the dashboard to the terminal.
+func (d *dashboard) draw() {
+	width, height, err := terminalSize()
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 100, 30
+	}
+	lines := d.render(width, height)
+	fmt.Print(clearScreen + strings.Join(lines, "\r\n"))
+}
+
+// render lays the dashboard out in width columns and height lines.
+func (d *dashboard) render(width, height int) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	status := fmt.Sprintf("%d channels", len(d.rows))
+	if d.running != nil {
+		status += ", syncing " + channelLabel(d.running.channel)
+	}
+	if len(d.queue) > 0 {
+		status += fmt.Sprintf(", %d queued", len(d.queue))
+	}
+	top := []string{
+		fmt.Sprintf("ytsync  %s  %s  %s", d.storePath, status, time.Now().Format("15:04:05")),
+		"",
+		fmt.Sprintf("  %-28s %-12s %-16s %6s %11s  %s", "CHANNEL", "STATUS", "LAST SYNC", "VIDEOS", "TRANSCRIPTS", "PROGRESS"),
+	}
+
+	bottom := []string{""}
+	bottom = append(bottom, d.limiterLines()...)
+	if len(d.errors) > 0 {
+		bottom = append(bottom, "Recent errors:")
+		for _, msg := range d.errors {
+			bottom = append(bottom, "  "+msg)
+		}
+	}
+	if d.logLine != "" {
+		bottom = append(bottom, "Log: "+d.logLine)
+	}
+	bottom = append(bottom, "", "up/down select  s sync  a sync all  c cancel  x cancel all  r reload  q quit")
+
+	// Scroll the channel list to keep the selection in view
+	room := max(height-len(top)-len(bottom), 1)
+	first := 0
+	if d.selected >= room {
+		first = d.selected - room + 1
+	}
+	lines := top
+	if len(d.rows) == 0 {
+		lines = append(lines, "  No channels tracked. Add one with: ytsync channel add <channel>")
+	}
+	for i := first; i < len(d.rows) && i < first+room; i++ {
+		cursor := " "
+		if i == d.selected {
+			cursor = ">"
+		}
+		lines = append(lines, cursor+" "+d.rows[i].line(width-2))
+	}
+	lines = append(lines, bottom...)
+
+	for i, line := range lines {
+		lines[i] = clip(line, width)
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return lines
+}
+
+// line renders the row in width columns.
+func (r *channelRow) line(width int) string {
+	state := r.state
+	if state == "" {
+		state = r.summary.status
+	}
+	line := fmt.Sprintf("%-28s %-12s %-16s %6d %11s  ",
+		clip(channelLabel(r.channel), 28), state, formatLastSync(r.summary.lastSync),
+		r.summary.videos, r.summary.coverage())
+	return line + r.progress(width-utf8.RuneCountInString(line))
+}
+
+// progress describes the running or last sync of the row in width
+// columns, with a bar while a full sync lists a channel of known size.
+func (r *channelRow) progress(width int) string {
+	if r.state != rowSyncing {
+		if r.result == "" && r.summary.lastError != "" {
+			return r.summary.lastError
+		}
+		return r.result
+	}
+
+	text := r.phase
+	if r.page > 0 {
+		text += fmt.Sprintf(" page %d", r.page)
+	}
+	if r.phase == "full" && r.total > 0 {
+		text += " " + progressBar(r.videos, r.total, 20) + fmt.Sprintf(" %d/%d", r.videos, r.total)
+	} else {
+		text += fmt.Sprintf(" %d videos", r.videos)
+	}
+	if r.transcripts > 0 || r.stored > 0 {
+		text += fmt.Sprintf(", %d transcripts, %d stored", r.transcripts, r.stored)
+	}
+	if r.retries > 0 {
+		text += fmt.Sprintf(", %d retries", r.retries)
+	}
+	return clip(text, width)
+}
+
+// limiterLines describes the request rates, backoffs, and open circuits
+// of the dashboard's HTTP client. The caller must hold d.mu.
+func (d *dashboard) limiterLines() []string {
+	var lines []string
+	if limiter := d.client.RateLimiter(); limiter != nil {
+		var rates []string
+		for domain, rps := range limiter.Stats() {
+			rates = append(rates, fmt.Sprintf("%s %.1f/s", domain, rps))
+		}
+		sort.Strings(rates)
+		if len(rates) > 0 {
+			lines = append(lines, "Rate limits: "+strings.Join(rates, ", "))
+		}
+		var backoffs []string
+		for domain, state := range limiter.BackoffStates() {
+			if wait := time.Until(state.LastError.Add(state.CurrentBackoff)); wait > 0 {
+				backoffs = append(backoffs, fmt.Sprintf("%s %s (%d errors)", domain, wait.Round(time.Second), state.ConsecutiveErrors))
+			}
+		}
+		sort.Strings(backoffs)
+		if len(backoffs) > 0 {
+			lines = append(lines, "Backing off: "+strings.Join(backoffs, ", "))
+		}
+	}
+
+	var circuits []string
+	for domain, stats := range d.client.CircuitBreaker().AllStats() {
+		switch stats.State {
+		case ythttp.CircuitOpen:
+			circuits = append(circuits, fmt.Sprintf("%s open, probe in %s", domain, time.Until(stats.NextProbe).Round(time.Second)))
+		case ythttp.CircuitHalfOpen:
+			circuits = append(circuits, domain+" half-open")
+		}
+	}
+	sort.Strings(circuits)
+	if len(circuits) == 0 {
+		lines = append(lines, "Circuits: all closed")
+	} else {
+		lines = append(lines, "Circuits: "+strings.Join(circuits, ", "))
+	}
+	return lines
+}
+
+// progressBar draws done out of total as a bar width cells wide.
+func progressBar(done, total, width int) string {
+	filled := min(done*width/total, width)
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
+
+// clip cuts s to at most width characters.
+func clip(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width])
+}
+
+// dashboardLog shows the last log line on the dashboard instead of
+// writing it over the screen.
+type dashboardLog struct {
+	d *dashboard
+}
+
+func (l dashboardLog) Write(p []byte) (int, error) {
+	l.d.mu.Lock()
+	l.d.logLine = strings.TrimSpace(string(p))
+	l.d.mu.Unlock()
+	l.d.redraw()
+	return len(p), nil
+}
+
+// readKeys sends the keys pressed on stdin to keys, naming arrows "up" and
+// "down", Enter "enter", and Ctrl-C "ctrl-c", until stdin is closed.
+func readKeys(keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return
+		}
+		for in := buf[:n]; len(in) > 0; {
+			key, size := parseKey(in)
+			in = in[size:]
+			if key != "" {
+				keys <- key
+			}
+		}
+	}
+}
+
+// parseKey returns the key at the start of in and the bytes it takes.
+// Escape sequences other than the up and down arrows are skipped.
+func parseKey(in []byte) (key string, size int) {
+	switch b := in[0]; {
+	case b == 0x1b && len(in) >= 3 && (in[1] == '[' || in[1] == 'O'):
+		switch in[2] {
+		case 'A':
+			return "up", 3
+		case 'B':
+			return "down", 3
+		}
+		return "", 3
+	case b == 0x03:
+		return "ctrl-c", 1
+	case b == '\r' || b == '\n':
+		return "enter", 1
+	case b < 0x20 || b >= 0x7f:
+		return "", 1
+	default:
+		return string(b), 1
+	}
+}
@@ -7,7 +7,11 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
+	"ytsync/errcode"
+
+	"github.com/google/uuid"
 )
 
 // Config holds all application configuration for YouTube synchronization operations.
@@ -37,13 +41,110 @@ type Config struct {
 	// BackoffMultiplier is the multiplier for exponential backoff (must be > 1)
 	BackoffMultiplier float64 `json:"backoff_multiplier"`
 
+	// HTTPUserAgent is the User-Agent of ytsync's own HTTP requests
+	// (default: "ytsync/1.0").
+	HTTPUserAgent string `json:"http_user_agent,omitempty"`
+	// HTTPTimeout bounds each attempt of an HTTP request (default: 30s).
+	HTTPTimeout time.Duration `json:"http_timeout,omitempty"`
+	// HTTPMaxConcurrent caps the HTTP requests in flight (default: 10).
+	HTTPMaxConcurrent int `json:"http_max_concurrent,omitempty"`
+	// HTTPInnertubeRPS is the request rate to youtube.com, in requests per
+	// second (default: 2.5).
+	HTTPInnertubeRPS float64 `json:"http_innertube_rps,omitempty"`
+	// HTTPDataAPIRPS is the request rate to the YouTube Data API
+	// (default: 1).
+	HTTPDataAPIRPS float64 `json:"http_data_api_rps,omitempty"`
+	// HTTPRSSRPS is the request rate for RSS feeds (default: 10).
+	HTTPRSSRPS float64 `json:"http_rss_rps,omitempty"`
+	// HTTPGlobalRPS caps the combined rate to all YouTube domains
+	// (default: 0 = no combined cap).
+	HTTPGlobalRPS float64 `json:"http_global_rps,omitempty"`
+	// HTTPBurst is how many requests to a domain may be sent back-to-back
+	// before its rate applies (default: 1).
+	HTTPBurst int `json:"http_burst,omitempty"`
+	// HTTPCircuitFailureThreshold is the number of consecutive failures
+	// that stop requests to a domain (default: 5).
+	HTTPCircuitFailureThreshold int `json:"http_circuit_failure_threshold,omitempty"`
+	// HTTPCircuitRecoveryTimeout is how long requests to a failing domain
+	// stay stopped before one is tried again (default: 30s).
+	HTTPCircuitRecoveryTimeout time.Duration `json:"http_circuit_recovery_timeout,omitempty"`
+
 	// YouTubeAPIKey is the API key for YouTube Data API v3
 	YouTubeAPIKey string `json:"youtube_api_key"`
+	// YouTubeAPIKeys are further Data API keys, typically of other Google
+	// Cloud projects, whose quota is pooled with YouTubeAPIKey's. Keys are
+	// used in order, moving to the next when one runs out of quota.
+	YouTubeAPIKeys []string `json:"youtube_api_keys,omitempty"`
 	// YouTubeAPIEnabled enables YouTube Data API v3 for video listing (default: false)
 	YouTubeAPIEnabled bool `json:"youtube_api_enabled"`
 	// YouTubeAPIQuotaReserve is the minimum quota units to keep in reserve before
 	// falling back to yt-dlp. Default is 0 (use API until exhausted).
 	YouTubeAPIQuotaReserve int `json:"youtube_api_quota_reserve"`
+
+	// MetadataCacheTTL is how long fetched video metadata is reused before
+	// being refreshed. Default is 0 (caching disabled).
+	MetadataCacheTTL time.Duration `json:"metadata_cache_ttl"`
+	// MetadataCacheStaleTTL is how long past MetadataCacheTTL stale metadata may
+	// be served while it is refreshed in the background.
+	MetadataCacheStaleTTL time.Duration `json:"metadata_cache_stale_ttl"`
+	// CachePath is the file of the shared cache holding video metadata and
+	// channel handle resolutions, shared by every process pointed at it.
+	// Default is empty (no shared cache; metadata is cached in memory).
+	CachePath string `json:"cache_path"`
+
+	// TranscriptLanguages is the ordered list of preferred transcript language
+	// codes (e.g. ["de", "en"]). Manual captions are preferred over
+	// auto-generated ones in each language, then English, then any track.
+	// Default is empty (English first).
+	TranscriptLanguages []string `json:"transcript_languages"`
+	// TranscriptSkipAutoGenerated ignores auto-generated captions when
+	// selecting a transcript (default: false)
+	TranscriptSkipAutoGenerated bool `json:"transcript_skip_auto_generated"`
+	// TranscriptRetryAfter is how long to wait before asking again for the
+	// transcript of a video whose last attempt failed (default: 24h).
+	TranscriptRetryAfter time.Duration `json:"transcript_retry_after"`
+	// TranscriptMaxAttempts gives up on a video's transcript after this
+	// many failed attempts in a row (default: 5, 0 = never give up).
+	TranscriptMaxAttempts int `json:"transcript_max_attempts"`
+	// TranscriptPollSchedule is how long to wait after each failed
+	// transcript attempt for a video published less than
+	// TranscriptPollWindow ago, since automatic captions often appear hours
+	// after upload. The last wait repeats (default: 15m, 1h, 6h, 24h;
+	// empty = treat fresh videos like any other).
+	TranscriptPollSchedule []time.Duration `json:"transcript_poll_schedule"`
+	// TranscriptPollWindow is how long after publication a video's
+	// transcript is polled for before TranscriptMaxAttempts applies
+	// (default: 7 days).
+	TranscriptPollWindow time.Duration `json:"transcript_poll_window"`
+
+	// StoreEncryptionKey, if set, encrypts the JSON store and blob store at
+	// rest with AES-GCM. It is a 16, 24, or 32 byte key in hex or base64.
+	StoreEncryptionKey string `json:"store_encryption_key"`
+	// StorePreviousKeys are older encryption keys still accepted for reading
+	// while a store is re-keyed.
+	StorePreviousKeys []string `json:"store_previous_keys"`
+	// StoreLockTimeout is how long opening a store waits for another
+	// process that has it open for writing (default: 5s; 0 = don't wait)
+	StoreLockTimeout time.Duration `json:"store_lock_timeout"`
+	// StoreLockStaleAfter breaks a store lock of a process on another host
+	// once it has not been refreshed for this long (default: 0 = never).
+	// Holders refresh their locks every minute. Locks of exited processes
+	// on this host are always broken.
+	StoreLockStaleAfter time.Duration `json:"store_lock_stale_after"`
+	// StoreDeterministicIDs derives the IDs of new channels and videos from
+	// their YouTube IDs as UUIDv5s, so stores built from the same channels
+	// agree on IDs (default: false = random UUIDs).
+	StoreDeterministicIDs bool `json:"store_deterministic_ids"`
+	// StoreIDNamespace is the UUID namespace of deterministic IDs (default:
+	// storage.DefaultIDNamespace).
+	StoreIDNamespace string `json:"store_id_namespace"`
+	// StorePath is the JSON store the CLI commands use when --store is not
+	// given (default: "ytsync.json" in the current directory). Set it in a
+	// profile to point each profile at its own archive.
+	StorePath string `json:"store_path,omitempty"`
+
+	// Profile is the name of the profile Load applied, or "" if none.
+	Profile string `json:"-"`
 }
 
 // DefaultConfig returns configuration with safe defaults.
@@ -58,11 +159,23 @@ func DefaultConfig() *Config {
 		InitialBackoff:    1 * time.Second,
 		MaxBackoff:        30 * time.Second,
 		BackoffMultiplier: 2.0,
+		StoreLockTimeout:  5 * time.Second,
+
+		TranscriptRetryAfter:  24 * time.Hour,
+		TranscriptMaxAttempts: 5,
+		TranscriptPollSchedule: []time.Duration{
+			15 * time.Minute, time.Hour, 6 * time.Hour, 24 * time.Hour,
+		},
+		TranscriptPollWindow: 7 * 24 * time.Hour,
 	}
 }
 
 // Load loads configuration from environment variables, config file, and applies defaults.
-// Priority: env vars > config file > defaults
+// Priority: env vars > profile > config file > defaults
+//
+// The config file is YTSYNC_CONFIG if set, else the first ytsync.json found
+// in the current directory or Dir(). The active profile (see ActiveProfile)
+// is then applied on top of it from Dir()/profiles/<name>.json.
 func Load() (*Config, error) {
 	cfg := DefaultConfig()
 
@@ -74,6 +187,17 @@ func Load() (*Config, error) {
 		}
 	}
 
+	profile, err := ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		if err := cfg.loadProfile(profile); err != nil {
+			return nil, fmt.Errorf("load profile: %w", err)
+		}
+		cfg.Profile = profile
+	}
+
 	// Override with environment variables
 	cfg.loadFromEnv()
 
@@ -85,29 +209,45 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
-// loadFromFile attempts to load config from ytsync.json in current directory or home directory.
-func (c *Config) loadFromFile() error {
-	paths := []string{
-		"ytsync.json",
-		filepath.Join(os.Getenv("HOME"), ".config", "ytsync", "ytsync.json"),
+// FilePath returns the config file Load reads: YTSYNC_CONFIG if set, or
+// else ytsync.json in the current directory or Dir(). It returns
+// os.ErrNotExist if there is none. A YTSYNC_CONFIG file that does not
+// exist is an error.
+func FilePath() (string, error) {
+	if v := os.Getenv("YTSYNC_CONFIG"); v != "" {
+		if _, err := os.Stat(v); err != nil {
+			return "", fmt.Errorf("YTSYNC_CONFIG: %w", err)
+		}
+		return v, nil
 	}
 
-	for _, path := range paths {
-		data, err := os.ReadFile(path)
-		if err != nil {
+	for _, path := range []string{"ytsync.json", filepath.Join(Dir(), "ytsync.json")} {
+		if _, err := os.Stat(path); err != nil {
 			if os.IsNotExist(err) {
 				continue
 			}
-			return err
-		}
-
-		if err := json.Unmarshal(data, c); err != nil {
-			return fmt.Errorf("parse %s: %w", path, err)
+			return "", err
 		}
-		return nil
+		return path, nil
 	}
 
-	return os.ErrNotExist
+	return "", os.ErrNotExist
+}
+
+// loadFromFile loads config from the file FilePath finds.
+func (c *Config) loadFromFile() error {
+	path, err := FilePath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
 }
 
 // loadFromEnv overrides config with environment variables.
@@ -146,9 +286,60 @@ func (c *Config) loadFromEnv() {
 			c.MaxBackoff = d
 		}
 	}
+	if v := os.Getenv("YTSYNC_HTTP_USER_AGENT"); v != "" {
+		c.HTTPUserAgent = v
+	}
+	if v := os.Getenv("YTSYNC_HTTP_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.HTTPTimeout = d
+		}
+	}
+	if v := os.Getenv("YTSYNC_HTTP_MAX_CONCURRENT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.HTTPMaxConcurrent = n
+		}
+	}
+	if v := os.Getenv("YTSYNC_HTTP_INNERTUBE_RPS"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			c.HTTPInnertubeRPS = f
+		}
+	}
+	if v := os.Getenv("YTSYNC_HTTP_DATA_API_RPS"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			c.HTTPDataAPIRPS = f
+		}
+	}
+	if v := os.Getenv("YTSYNC_HTTP_RSS_RPS"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			c.HTTPRSSRPS = f
+		}
+	}
+	if v := os.Getenv("YTSYNC_HTTP_GLOBAL_RPS"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			c.HTTPGlobalRPS = f
+		}
+	}
+	if v := os.Getenv("YTSYNC_HTTP_BURST"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.HTTPBurst = n
+		}
+	}
+	if v := os.Getenv("YTSYNC_HTTP_CIRCUIT_FAILURE_THRESHOLD"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.HTTPCircuitFailureThreshold = n
+		}
+	}
+	if v := os.Getenv("YTSYNC_HTTP_CIRCUIT_RECOVERY_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.HTTPCircuitRecoveryTimeout = d
+		}
+	}
 	if v := os.Getenv("YOUTUBE_API_KEY"); v != "" {
 		c.YouTubeAPIKey = v
 	}
+	if v := os.Getenv("YTSYNC_YOUTUBE_API_KEYS"); v != "" {
+		c.YouTubeAPIKeys = splitList(v)
+	}
 	if v := os.Getenv("YTSYNC_YOUTUBE_API_ENABLED"); v != "" {
 		c.YouTubeAPIEnabled = v == "true" || v == "1"
 	}
@@ -157,37 +348,181 @@ func (c *Config) loadFromEnv() {
 			c.YouTubeAPIQuotaReserve = n
 		}
 	}
+	if v := os.Getenv("YTSYNC_METADATA_CACHE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.MetadataCacheTTL = d
+		}
+	}
+	if v := os.Getenv("YTSYNC_METADATA_CACHE_STALE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.MetadataCacheStaleTTL = d
+		}
+	}
+	if v := os.Getenv("YTSYNC_CACHE_PATH"); v != "" {
+		c.CachePath = v
+	}
+	if v := os.Getenv("YTSYNC_TRANSCRIPT_LANGUAGES"); v != "" {
+		c.TranscriptLanguages = splitList(v)
+	}
+	if v := os.Getenv("YTSYNC_TRANSCRIPT_SKIP_AUTO_GENERATED"); v != "" {
+		c.TranscriptSkipAutoGenerated = v == "true" || v == "1"
+	}
+	if v := os.Getenv("YTSYNC_TRANSCRIPT_RETRY_AFTER"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.TranscriptRetryAfter = d
+		}
+	}
+	if v := os.Getenv("YTSYNC_TRANSCRIPT_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.TranscriptMaxAttempts = n
+		}
+	}
+	if v, ok := os.LookupEnv("YTSYNC_TRANSCRIPT_POLL_SCHEDULE"); ok {
+		if schedule, err := parseDurations(v); err == nil {
+			c.TranscriptPollSchedule = schedule
+		}
+	}
+	if v := os.Getenv("YTSYNC_TRANSCRIPT_POLL_WINDOW"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.TranscriptPollWindow = d
+		}
+	}
+	if v := os.Getenv("YTSYNC_STORE_KEY"); v != "" {
+		c.StoreEncryptionKey = v
+	}
+	if v := os.Getenv("YTSYNC_STORE_PREVIOUS_KEYS"); v != "" {
+		c.StorePreviousKeys = splitList(v)
+	}
+	if v := os.Getenv("YTSYNC_STORE_LOCK_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.StoreLockTimeout = d
+		}
+	}
+	if v := os.Getenv("YTSYNC_STORE_LOCK_STALE_AFTER"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.StoreLockStaleAfter = d
+		}
+	}
+	if v := os.Getenv("YTSYNC_STORE_DETERMINISTIC_IDS"); v != "" {
+		c.StoreDeterministicIDs = v == "true" || v == "1"
+	}
+	if v := os.Getenv("YTSYNC_STORE_ID_NAMESPACE"); v != "" {
+		c.StoreIDNamespace = v
+	}
+	if v := os.Getenv("YTSYNC_STORE"); v != "" {
+		c.StorePath = v
+	}
 }
 
-// Validate checks that configuration values are valid and consistent.
-// It returns an error if any configuration value is invalid.
-func (c *Config) Validate() error {
-	if c.YtdlpTimeout <= 0 {
-		return fmt.Errorf("ytdlp_timeout must be positive")
-	}
-	if c.MaxVideos < 0 {
-		return fmt.Errorf("max_videos must be non-negative")
+// APIKeys returns every configured Data API key, YouTubeAPIKey first,
+// without duplicates.
+func (c *Config) APIKeys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range append([]string{c.YouTubeAPIKey}, c.YouTubeAPIKeys...) {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
 	}
-	if c.MaxRetries < 0 {
-		return fmt.Errorf("max_retries must be non-negative")
+	return keys
+}
+
+// splitList splits a comma-separated environment value, dropping empty items.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
 	}
-	if c.InitialBackoff <= 0 {
-		return fmt.Errorf("initial_backoff must be positive")
+	return out
+}
+
+// parseDurations parses a comma-separated list of durations. An empty value
+// is an empty list.
+func parseDurations(v string) ([]time.Duration, error) {
+	var out []time.Duration
+	for _, item := range splitList(v) {
+		d, err := time.ParseDuration(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
 	}
-	if c.MaxBackoff <= 0 {
-		return fmt.Errorf("max_backoff must be positive")
+	return out, nil
+}
+
+// Validate checks that configuration values are valid and consistent.
+// It returns a *ValidationError listing every invalid value, or nil.
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, problem string) {
+		if !ok {
+			problems = append(problems, problem)
+		}
 	}
-	if c.MaxBackoff < c.InitialBackoff {
-		return fmt.Errorf("max_backoff must be >= initial_backoff")
+
+	check(c.YtdlpTimeout > 0, "ytdlp_timeout must be positive")
+	check(c.MaxVideos >= 0, "max_videos must be non-negative")
+	check(c.MaxRetries >= 0, "max_retries must be non-negative")
+	check(c.InitialBackoff > 0, "initial_backoff must be positive")
+	check(c.MaxBackoff > 0, "max_backoff must be positive")
+	check(c.MaxBackoff >= c.InitialBackoff, "max_backoff must be >= initial_backoff")
+	check(c.BackoffMultiplier > 1, "backoff_multiplier must be > 1")
+	check(c.HTTPTimeout >= 0, "http_timeout must be non-negative")
+	check(c.HTTPMaxConcurrent >= 0, "http_max_concurrent must be non-negative")
+	check(c.HTTPInnertubeRPS >= 0, "http_innertube_rps must be non-negative")
+	check(c.HTTPDataAPIRPS >= 0, "http_data_api_rps must be non-negative")
+	check(c.HTTPRSSRPS >= 0, "http_rss_rps must be non-negative")
+	check(c.HTTPGlobalRPS >= 0, "http_global_rps must be non-negative")
+	check(c.HTTPBurst >= 0, "http_burst must be non-negative")
+	check(c.HTTPCircuitFailureThreshold >= 0, "http_circuit_failure_threshold must be non-negative")
+	check(c.HTTPCircuitRecoveryTimeout >= 0, "http_circuit_recovery_timeout must be non-negative")
+	check(!c.YouTubeAPIEnabled || len(c.APIKeys()) > 0, "youtube_api_key or youtube_api_keys must be set when youtube_api_enabled is true")
+	check(c.YouTubeAPIQuotaReserve >= 0, "youtube_api_quota_reserve must be non-negative")
+	check(c.MetadataCacheTTL >= 0, "metadata_cache_ttl must be non-negative")
+	check(c.MetadataCacheStaleTTL >= 0, "metadata_cache_stale_ttl must be non-negative")
+	check(c.DateAfter.IsZero() || c.DateBefore.IsZero() || c.DateAfter.Before(c.DateBefore), "date_after must be before date_before")
+	for _, lang := range c.TranscriptLanguages {
+		check(strings.TrimSpace(lang) != "", "transcript_languages must not contain empty codes")
 	}
-	if c.BackoffMultiplier <= 1 {
-		return fmt.Errorf("backoff_multiplier must be > 1")
+	check(c.TranscriptRetryAfter >= 0, "transcript_retry_after must be non-negative")
+	check(c.TranscriptMaxAttempts >= 0, "transcript_max_attempts must be non-negative")
+	for _, d := range c.TranscriptPollSchedule {
+		check(d > 0, "transcript_poll_schedule must contain only positive durations")
 	}
-	if c.YouTubeAPIEnabled && c.YouTubeAPIKey == "" {
-		return fmt.Errorf("youtube_api_key must be set when youtube_api_enabled is true")
+	check(c.TranscriptPollWindow >= 0, "transcript_poll_window must be non-negative")
+	check(len(c.StorePreviousKeys) == 0 || c.StoreEncryptionKey != "", "store_encryption_key must be set when store_previous_keys is")
+	check(c.StoreLockTimeout >= 0, "store_lock_timeout must be non-negative")
+	check(c.StoreLockStaleAfter >= 0, "store_lock_stale_after must be non-negative")
+	if c.StoreIDNamespace != "" {
+		_, err := uuid.Parse(c.StoreIDNamespace)
+		check(err == nil, "store_id_namespace must be a UUID")
+		check(c.StoreDeterministicIDs, "store_deterministic_ids must be true when store_id_namespace is set")
 	}
-	if c.YouTubeAPIQuotaReserve < 0 {
-		return fmt.Errorf("youtube_api_quota_reserve must be non-negative")
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
 	}
 	return nil
 }
+
+// ValidationError reports every problem found by Validate.
+type ValidationError struct {
+	// Problems describes each invalid value, in field order.
+	Problems []string
+}
+
+// Error returns the problems joined into a single message.
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0]
+	}
+	return fmt.Sprintf("%d config problems: %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// ErrorCode classifies validation failures as errcode.InvalidInput.
+func (e *ValidationError) ErrorCode() errcode.Code {
+	return errcode.InvalidInput
+}
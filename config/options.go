@@ -0,0 +1,209 @@
+package config
+
+import "time"
+
+// Option configures a Config built with New.
+type Option func(*Config)
+
+// New builds a Config in code, starting from DefaultConfig and applying opts
+// in order. Unlike Load, it does not read ytsync.json or the environment
+// unless WithEnv is given. The result is validated; on failure the returned
+// error is a *ValidationError listing every problem.
+//
+//	cfg, err := config.New(
+//		config.WithYtdlpPath("/usr/local/bin/yt-dlp"),
+//		config.WithRetry(3, time.Second, 10*time.Second, 2),
+//		config.WithYouTubeAPI(apiKey, 500),
+//	)
+func New(opts ...Option) (*Config, error) {
+	cfg := DefaultConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// WithEnv applies YTSYNC_* environment variable overrides at this point in
+// the option list, so later options take precedence over the environment.
+func WithEnv() Option {
+	return func(c *Config) {
+		c.loadFromEnv()
+	}
+}
+
+// WithYtdlpPath sets the yt-dlp executable path.
+func WithYtdlpPath(path string) Option {
+	return func(c *Config) {
+		c.YtdlpPath = path
+	}
+}
+
+// WithYtdlpTimeout sets the maximum time to wait for yt-dlp operations.
+func WithYtdlpTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.YtdlpTimeout = d
+	}
+}
+
+// WithMaxVideos limits the number of videos retrieved (0 = all).
+func WithMaxVideos(n int) Option {
+	return func(c *Config) {
+		c.MaxVideos = n
+	}
+}
+
+// WithShorts sets whether YouTube Shorts are included.
+func WithShorts(include bool) Option {
+	return func(c *Config) {
+		c.IncludeShorts = include
+	}
+}
+
+// WithLive sets whether live streams are included.
+func WithLive(include bool) Option {
+	return func(c *Config) {
+		c.IncludeLive = include
+	}
+}
+
+// WithDateRange restricts videos to those published between after and before.
+// A zero time leaves that side of the range open.
+func WithDateRange(after, before time.Time) Option {
+	return func(c *Config) {
+		c.DateAfter = after
+		c.DateBefore = before
+	}
+}
+
+// WithRetry sets the retry policy for failed operations.
+func WithRetry(maxRetries int, initialBackoff, maxBackoff time.Duration, multiplier float64) Option {
+	return func(c *Config) {
+		c.MaxRetries = maxRetries
+		c.InitialBackoff = initialBackoff
+		c.MaxBackoff = maxBackoff
+		c.BackoffMultiplier = multiplier
+	}
+}
+
+// WithHTTPRates sets the request rates, in requests per second, to
+// youtube.com, the Data API, and RSS feeds, and the combined cap across
+// YouTube domains. Zero keeps a rate's default.
+func WithHTTPRates(innertube, dataAPI, rss, global float64) Option {
+	return func(c *Config) {
+		c.HTTPInnertubeRPS = innertube
+		c.HTTPDataAPIRPS = dataAPI
+		c.HTTPRSSRPS = rss
+		c.HTTPGlobalRPS = global
+	}
+}
+
+// WithHTTPCircuitBreaker stops requests to a domain after failureThreshold
+// consecutive failures, for recoveryTimeout. Zero keeps a setting's default.
+func WithHTTPCircuitBreaker(failureThreshold int, recoveryTimeout time.Duration) Option {
+	return func(c *Config) {
+		c.HTTPCircuitFailureThreshold = failureThreshold
+		c.HTTPCircuitRecoveryTimeout = recoveryTimeout
+	}
+}
+
+// WithUserAgent sets the User-Agent of ytsync's HTTP requests.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Config) {
+		c.HTTPUserAgent = userAgent
+	}
+}
+
+// WithYouTubeAPI enables the YouTube Data API v3 with the given key, keeping
+// quotaReserve units in reserve before falling back to yt-dlp.
+func WithYouTubeAPI(apiKey string, quotaReserve int) Option {
+	return func(c *Config) {
+		c.YouTubeAPIEnabled = true
+		c.YouTubeAPIKey = apiKey
+		c.YouTubeAPIQuotaReserve = quotaReserve
+	}
+}
+
+// WithYouTubeAPIKeys adds Data API keys whose quota is pooled with the
+// primary key's (see Config.YouTubeAPIKeys).
+func WithYouTubeAPIKeys(apiKeys ...string) Option {
+	return func(c *Config) {
+		c.YouTubeAPIKeys = apiKeys
+	}
+}
+
+// WithTranscriptLanguages sets the preferred transcript languages, in order.
+func WithTranscriptLanguages(languages ...string) Option {
+	return func(c *Config) {
+		c.TranscriptLanguages = languages
+	}
+}
+
+// WithTranscriptRetry waits retryAfter before asking again for a transcript
+// that could not be fetched, and gives up after maxAttempts failures in a
+// row (0 never gives up).
+func WithTranscriptRetry(retryAfter time.Duration, maxAttempts int) Option {
+	return func(c *Config) {
+		c.TranscriptRetryAfter = retryAfter
+		c.TranscriptMaxAttempts = maxAttempts
+	}
+}
+
+// WithTranscriptPolling asks again for the transcript of a video published
+// less than window ago after each failed attempt, waiting schedule[n-1]
+// after the nth failure in a row and repeating the last wait. An empty
+// schedule turns polling off.
+func WithTranscriptPolling(window time.Duration, schedule ...time.Duration) Option {
+	return func(c *Config) {
+		c.TranscriptPollWindow = window
+		c.TranscriptPollSchedule = schedule
+	}
+}
+
+// WithStoreEncryption encrypts stores at rest with key, accepting data
+// sealed with any of previous while the store is re-keyed. Keys are hex or
+// base64.
+func WithStoreEncryption(key string, previous ...string) Option {
+	return func(c *Config) {
+		c.StoreEncryptionKey = key
+		c.StorePreviousKeys = previous
+	}
+}
+
+// WithStoreLock sets how long opening a store waits for another process,
+// and after how long without a refresh a lock held by a process on another
+// host is broken.
+func WithStoreLock(timeout, staleAfter time.Duration) Option {
+	return func(c *Config) {
+		c.StoreLockTimeout = timeout
+		c.StoreLockStaleAfter = staleAfter
+	}
+}
+
+// WithDeterministicIDs derives the IDs of new channels and videos from
+// their YouTube IDs as UUIDv5s in namespace, or in
+// storage.DefaultIDNamespace if namespace is empty.
+func WithDeterministicIDs(namespace string) Option {
+	return func(c *Config) {
+		c.StoreDeterministicIDs = true
+		c.StoreIDNamespace = namespace
+	}
+}
+
+// WithMetadataCache enables the metadata cache with the given TTL and
+// stale-while-revalidate window.
+func WithMetadataCache(ttl, staleTTL time.Duration) Option {
+	return func(c *Config) {
+		c.MetadataCacheTTL = ttl
+		c.MetadataCacheStaleTTL = staleTTL
+	}
+}
+
+// WithCachePath sets the shared cache file.
+func WithCachePath(path string) Option {
+	return func(c *Config) {
+		c.CachePath = path
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"ytsync/errcode"
+)
+
+// ErrProfileNotFound is returned when the selected profile has no file in
+// the profiles directory.
+var ErrProfileNotFound = errcode.New(errcode.NotFound, "config: profile not found")
+
+// Dir returns the per-user configuration directory, $HOME/.config/ytsync.
+func Dir() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "ytsync")
+}
+
+// ProfilePath returns the file of the named profile,
+// Dir()/profiles/<name>.json.
+func ProfilePath(name string) string {
+	return filepath.Join(Dir(), "profiles", name+".json")
+}
+
+// activeProfilePath is the file ytsync profile use writes the active
+// profile's name to.
+func activeProfilePath() string {
+	return filepath.Join(Dir(), "profile")
+}
+
+// Profiles returns the names of the profiles in Dir()/profiles, sorted.
+func Profiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(Dir(), "profiles"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() && name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ActiveProfile returns the profile Load applies: YTSYNC_PROFILE if set,
+// else the one last selected with SetActiveProfile, else "" (no profile).
+func ActiveProfile() (string, error) {
+	if v := os.Getenv("YTSYNC_PROFILE"); v != "" {
+		return v, nil
+	}
+	data, err := os.ReadFile(activeProfilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read active profile: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SetActiveProfile makes name the profile Load applies when YTSYNC_PROFILE
+// is not set. The profile must exist. An empty name clears the selection.
+func SetActiveProfile(name string) error {
+	if name == "" {
+		if err := os.Remove(activeProfilePath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := checkProfileName(name); err != nil {
+		return err
+	}
+	if _, err := os.Stat(ProfilePath(name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+		}
+		return err
+	}
+	if err := os.MkdirAll(Dir(), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(activeProfilePath(), []byte(name+"\n"), 0o644)
+}
+
+// checkProfileName rejects names that would resolve outside the profiles
+// directory.
+func checkProfileName(name string) error {
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return &ValidationError{Problems: []string{fmt.Sprintf("invalid profile name %q", name)}}
+	}
+	return nil
+}
+
+// loadProfile applies the named profile's file on top of c.
+func (c *Config) loadProfile(name string) error {
+	if err := checkProfileName(name); err != nil {
+		return err
+	}
+	path := ProfilePath(name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s (expected %s)", ErrProfileNotFound, name, path)
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval is how often a Watcher checks the config file by default.
+const DefaultWatchInterval = 5 * time.Second
+
+// reloadableFields lists the settings (by JSON name) a Watcher applies at
+// runtime. Other settings, such as the yt-dlp path or API credentials, are
+// wired into long-lived components at startup and need a restart to change.
+var reloadableFields = map[string]bool{
+	"max_videos":         true,
+	"include_shorts":     true,
+	"include_live":       true,
+	"date_after":         true,
+	"date_before":        true,
+	"max_retries":        true,
+	"initial_backoff":    true,
+	"max_backoff":        true,
+	"backoff_multiplier": true,
+	"ytdlp_timeout":      true,
+	// Transcript settings are read on every extraction
+	"transcript_languages":           true,
+	"transcript_skip_auto_generated": true,
+	// HTTP limits are fixed when a client is built, so they take effect in
+	// clients built after the reload (ytsync tui builds a new one)
+	"http_innertube_rps":             true,
+	"http_data_api_rps":              true,
+	"http_rss_rps":                   true,
+	"http_global_rps":                true,
+	"http_burst":                     true,
+	"http_circuit_failure_threshold": true,
+	"http_circuit_recovery_timeout":  true,
+}
+
+// ConfigReloaded describes the outcome of a config file reload.
+type ConfigReloaded struct {
+	// Path is the watched config file.
+	Path string
+	// Time is when the change was detected.
+	Time time.Time
+	// Old is the configuration that was active before the reload.
+	Old *Config
+	// New is the configuration now active. It equals Old when the update was rejected.
+	New *Config
+	// Changed lists the settings (by JSON name) that were applied.
+	Changed []string
+	// Ignored lists changed settings that require a restart and were not applied.
+	Ignored []string
+	// Err is set when the file could not be read, parsed, or validated.
+	// The previous configuration stays active.
+	Err error
+}
+
+// Watcher polls a config file and applies safe-to-change settings while a
+// long-running process keeps going. Create one with NewWatcher, set
+// OnReload, and call Start.
+type Watcher struct {
+	// Interval is how often the file is checked. Default: DefaultWatchInterval.
+	Interval time.Duration
+	// OnReload is called after every detected change, including rejected ones.
+	OnReload func(ConfigReloaded)
+
+	path string
+
+	mu      sync.RWMutex
+	current *Config
+	content []byte
+}
+
+// NewWatcher loads path (with the active profile and environment overrides
+// applied, as in Load)
+// and returns a Watcher for it. The file must exist and be valid.
+func NewWatcher(path string) (*Watcher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	cfg, err := parseConfigFile(path, data)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		Interval: DefaultWatchInterval,
+		path:     path,
+		current:  cfg,
+		content:  data,
+	}, nil
+}
+
+// Config returns a copy of the active configuration.
+func (w *Watcher) Config() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	cfg := *w.current
+	return &cfg
+}
+
+// Start polls the file until ctx is canceled. It runs in the background and
+// returns immediately.
+func (w *Watcher) Start(ctx context.Context) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.Check()
+			}
+		}
+	}()
+}
+
+// Check reads the file once and applies any change. It reports whether the
+// file changed; OnReload is called when it did.
+func (w *Watcher) Check() bool {
+	data, err := os.ReadFile(w.path)
+
+	w.mu.Lock()
+	if err == nil && bytes.Equal(data, w.content) {
+		w.mu.Unlock()
+		return false
+	}
+
+	event := ConfigReloaded{Path: w.path, Time: time.Now(), Old: w.current, New: w.current}
+	if err != nil {
+		if os.IsNotExist(err) && w.content == nil {
+			// Still missing since the last check
+			w.mu.Unlock()
+			return false
+		}
+		w.content = nil
+		event.Err = fmt.Errorf("read config file: %w", err)
+	} else {
+		w.content = data
+		next, err := parseConfigFile(w.path, data)
+		if err != nil {
+			event.Err = err
+		} else {
+			applied, changed, ignored, err := mergeReloadable(w.current, next)
+			if err != nil {
+				event.Err = err
+			} else {
+				w.current = applied
+				event.New, event.Changed, event.Ignored = applied, changed, ignored
+			}
+		}
+	}
+	onReload := w.OnReload
+	w.mu.Unlock()
+
+	if onReload != nil {
+		onReload(event)
+	}
+	return true
+}
+
+// parseConfigFile builds a validated Config from defaults, the file
+// contents, the active profile, and environment overrides.
+func parseConfigFile(path string, data []byte) (*Config, error) {
+	cfg := DefaultConfig()
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	profile, err := ActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		if err := cfg.loadProfile(profile); err != nil {
+			return nil, fmt.Errorf("load profile: %w", err)
+		}
+		cfg.Profile = profile
+	}
+	cfg.loadFromEnv()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// mergeReloadable returns a copy of current with next's reloadable settings
+// applied, along with the names of applied and ignored changes.
+func mergeReloadable(current, next *Config) (*Config, []string, []string, error) {
+	merged := *current
+	var changed, ignored []string
+
+	dst := reflect.ValueOf(&merged).Elem()
+	src := reflect.ValueOf(next).Elem()
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if reflect.DeepEqual(dst.Field(i).Interface(), src.Field(i).Interface()) {
+			continue
+		}
+		if reloadableFields[name] {
+			dst.Field(i).Set(src.Field(i))
+			changed = append(changed, name)
+		} else {
+			ignored = append(ignored, name)
+		}
+	}
+
+	// Applied settings must still be consistent with the ones kept
+	if err := merged.Validate(); err != nil {
+		return nil, nil, nil, err
+	}
+	return &merged, changed, ignored, nil
+}
@@ -0,0 +1,499 @@
+// Package download runs bulk video downloads from a persistent queue.
+//
+// A Manager downloads queued videos with a bounded number of workers,
+// retrying each item with exponential backoff. Queue state is saved after
+// every change, so a bulk download of a channel that is interrupted can be
+// resumed by creating a new Manager with the same StatePath and calling Run.
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+	"ytsync/errcode"
+	"ytsync/retry"
+	"ytsync/storage"
+	"ytsync/youtube"
+)
+
+// DefaultConcurrency is the number of parallel downloads when
+// Config.Concurrency is zero. YouTube throttles aggressive parallel
+// downloads, so it is kept low.
+const DefaultConcurrency = 2
+
+// ErrEmptyVideoID is returned when queuing an item without a video ID.
+var ErrEmptyVideoID = errcode.New(errcode.InvalidInput, "download: empty video ID")
+
+// Status is the state of a queued item.
+type Status string
+
+const (
+	// StatusPending items are waiting to be downloaded.
+	StatusPending Status = "pending"
+	// StatusRunning items are being downloaded.
+	StatusRunning Status = "running"
+	// StatusDone items were downloaded, or skipped because the output
+	// already existed.
+	StatusDone Status = "done"
+	// StatusFailed items failed with a permanent error or ran out of retries.
+	StatusFailed Status = "failed"
+)
+
+// Options configures the download of one item. It mirrors the persistable
+// fields of youtube.DownloadOptions.
+type Options struct {
+	OutputDir string `json:"output_dir,omitempty"`
+	Format    string `json:"format,omitempty"`
+	// Select is a youtube.FormatSelector rule; it overrides Format.
+	Select          string `json:"select,omitempty"`
+	AudioOnly       bool   `json:"audio_only,omitempty"`
+	AudioQuality    int    `json:"audio_quality,omitempty"`
+	AudioLanguage   string `json:"audio_language,omitempty"`
+	IncludeMetadata bool   `json:"include_metadata,omitempty"`
+	Filename        string `json:"filename,omitempty"`
+	// OutputTemplate is parsed with youtube.ParseOutputTemplate.
+	OutputTemplate string                  `json:"output_template,omitempty"`
+	Collision      youtube.CollisionPolicy `json:"collision,omitempty"`
+	Resume         bool                    `json:"resume,omitempty"`
+	Restart        bool                    `json:"restart,omitempty"`
+	Verify         bool                    `json:"verify,omitempty"`
+	// Subtitles is copied to youtube.DownloadOptions.Subtitles.
+	Subtitles *youtube.SubtitleOptions `json:"subtitles,omitempty"`
+}
+
+// downloadOptions converts o to youtube.DownloadOptions.
+func (o *Options) downloadOptions() (*youtube.DownloadOptions, error) {
+	opts := &youtube.DownloadOptions{}
+	if o == nil {
+		return opts, nil
+	}
+	opts.OutputDir = o.OutputDir
+	opts.Format = o.Format
+	opts.AudioOnly = o.AudioOnly
+	opts.AudioQuality = o.AudioQuality
+	opts.AudioLanguage = o.AudioLanguage
+	opts.IncludeMetadata = o.IncludeMetadata
+	opts.Filename = o.Filename
+	opts.Collision = o.Collision
+	opts.Resume = o.Resume
+	opts.Restart = o.Restart
+	opts.Verify = o.Verify
+	opts.Subtitles = o.Subtitles
+	if o.Select != "" {
+		sel, err := youtube.ParseFormatSelector(o.Select)
+		if err != nil {
+			return nil, err
+		}
+		opts.Selector = sel
+	}
+	if o.OutputTemplate != "" {
+		tmpl, err := youtube.ParseOutputTemplate(o.OutputTemplate)
+		if err != nil {
+			return nil, err
+		}
+		opts.OutputTemplate = tmpl
+	}
+	return opts, nil
+}
+
+// Item is a queued download.
+type Item struct {
+	VideoID string   `json:"video_id"`
+	Options *Options `json:"options,omitempty"`
+	Status  Status   `json:"status"`
+	// Attempts counts download attempts across all runs.
+	Attempts int `json:"attempts"`
+	// Error is the last failure, if any.
+	Error string `json:"error,omitempty"`
+	// VideoPath is the downloaded file, once done.
+	VideoPath string    `json:"video_path,omitempty"`
+	AddedAt   time.Time `json:"added_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Stats counts queued items by status.
+type Stats struct {
+	Pending int
+	Running int
+	Done    int
+	Failed  int
+}
+
+// Downloader downloads a single video. *youtube.Downloader implements it.
+type Downloader interface {
+	Download(ctx context.Context, videoID string, opts *youtube.DownloadOptions) (*youtube.DownloadResult, error)
+}
+
+// Config configures a Manager.
+type Config struct {
+	// Concurrency is the number of parallel downloads. Defaults to
+	// DefaultConcurrency.
+	Concurrency int
+	// Retry controls per-item retries. Defaults to three retries with
+	// exponential backoff starting at 5s.
+	Retry *retry.Config
+	// StatePath is the JSON file the queue is saved to. If it exists, the
+	// queue is loaded from it. Empty keeps the queue in memory only.
+	StatePath string
+	// OnUpdate, if set, is called with a copy of an item whenever its
+	// status changes. It must not call back into the Manager.
+	OnUpdate func(Item)
+	// Archive, if set, records every finished download. Items whose video
+	// is already in the archive are marked done without downloading.
+	Archive storage.DownloadArchiveStore
+}
+
+// Manager downloads a queue of videos in parallel. It is safe for
+// concurrent use.
+type Manager struct {
+	downloader  Downloader
+	concurrency int
+	retry       retry.Config
+	statePath   string
+	onUpdate    func(Item)
+	archive     storage.DownloadArchiveStore
+
+	mu      sync.Mutex
+	items   []*Item
+	byID    map[string]*Item
+	paused  bool
+	resumed chan struct{} // closed by Resume to wake paused workers
+}
+
+// queueState is the persisted form of the queue.
+type queueState struct {
+	Items []*Item `json:"items"`
+}
+
+// NewManager creates a manager that downloads with d. If cfg.StatePath
+// exists, the queue saved there is loaded; items that were running when
+// the previous process stopped are queued again.
+func NewManager(d Downloader, cfg Config) (*Manager, error) {
+	m := &Manager{
+		downloader:  d,
+		concurrency: cfg.Concurrency,
+		statePath:   cfg.StatePath,
+		onUpdate:    cfg.OnUpdate,
+		archive:     cfg.Archive,
+		byID:        make(map[string]*Item),
+		resumed:     make(chan struct{}),
+	}
+	if m.concurrency <= 0 {
+		m.concurrency = DefaultConcurrency
+	}
+	if cfg.Retry != nil {
+		m.retry = *cfg.Retry
+	} else {
+		m.retry = retry.DefaultConfig()
+		m.retry.MaxRetries = 3
+		m.retry.InitialBackoff = 5 * time.Second
+		m.retry.MaxBackoff = 2 * time.Minute
+	}
+
+	if m.statePath != "" {
+		if err := m.load(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Add queues videoID for download. A video already in the queue keeps its
+// place and status; use RetryFailed to queue failed items again.
+func (m *Manager) Add(videoID string, opts *Options) error {
+	return m.AddAll([]string{videoID}, opts)
+}
+
+// AddAll queues several videos with the same options.
+func (m *Manager) AddAll(videoIDs []string, opts *Options) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	added := false
+	for _, id := range videoIDs {
+		if id == "" {
+			return ErrEmptyVideoID
+		}
+		if _, exists := m.byID[id]; exists {
+			continue
+		}
+		item := &Item{VideoID: id, Options: opts, Status: StatusPending, AddedAt: now, UpdatedAt: now}
+		m.items = append(m.items, item)
+		m.byID[id] = item
+		added = true
+	}
+	if !added {
+		return nil
+	}
+	return m.saveLocked()
+}
+
+// Run downloads pending items until none are left, then returns. Items
+// added before the queue drains are picked up by the running workers. If
+// ctx is cancelled, downloads in progress are stopped and their items
+// return to pending, so a later Run resumes them; Run then returns
+// ctx.Err().
+func (m *Manager) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < m.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				item, ok := m.next(ctx)
+				if !ok {
+					return
+				}
+				m.process(ctx, item)
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// Pause stops workers from starting new downloads. Downloads in progress
+// run to completion.
+func (m *Manager) Pause() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = true
+}
+
+// Resume lets paused workers start downloads again.
+func (m *Manager) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.paused {
+		m.paused = false
+		close(m.resumed)
+		m.resumed = make(chan struct{})
+	}
+}
+
+// Paused reports whether the queue is paused.
+func (m *Manager) Paused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused
+}
+
+// RetryFailed returns failed items to pending and reports how many were
+// requeued.
+func (m *Manager) RetryFailed() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for _, item := range m.items {
+		if item.Status == StatusFailed {
+			m.setStatusLocked(item, StatusPending)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return n, m.saveLocked()
+}
+
+// Items returns a copy of the queue in the order items were added.
+func (m *Manager) Items() []Item {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := make([]Item, len(m.items))
+	for i, item := range m.items {
+		items[i] = *item
+	}
+	return items
+}
+
+// Stats returns the number of items in each status.
+func (m *Manager) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var s Stats
+	for _, item := range m.items {
+		switch item.Status {
+		case StatusPending:
+			s.Pending++
+		case StatusRunning:
+			s.Running++
+		case StatusDone:
+			s.Done++
+		case StatusFailed:
+			s.Failed++
+		}
+	}
+	return s
+}
+
+// next claims the next pending item, waiting while the queue is paused. It
+// returns false when no items are pending or ctx is done.
+func (m *Manager) next(ctx context.Context) (*Item, bool) {
+	for {
+		m.mu.Lock()
+		if ctx.Err() != nil {
+			m.mu.Unlock()
+			return nil, false
+		}
+		if m.paused {
+			resumed := m.resumed
+			m.mu.Unlock()
+			select {
+			case <-resumed:
+				continue
+			case <-ctx.Done():
+				return nil, false
+			}
+		}
+		for _, item := range m.items {
+			if item.Status == StatusPending {
+				m.setStatusLocked(item, StatusRunning)
+				m.persistLocked()
+				m.mu.Unlock()
+				return item, true
+			}
+		}
+		m.mu.Unlock()
+		return nil, false
+	}
+}
+
+// process downloads item with retries and records the outcome.
+func (m *Manager) process(ctx context.Context, item *Item) {
+	m.mu.Lock()
+	videoID := item.VideoID
+	opts, err := item.Options.downloadOptions()
+	m.mu.Unlock()
+
+	if err == nil && m.archive != nil {
+		archived, archiveErr := m.archive.IsDownloaded(ctx, videoID)
+		if archiveErr != nil {
+			log.Printf("download: check archive for %s: %v", videoID, archiveErr)
+		}
+		if archived {
+			m.mu.Lock()
+			item.Error = ""
+			m.setStatusLocked(item, StatusDone)
+			m.persistLocked()
+			m.mu.Unlock()
+			return
+		}
+	}
+
+	var result *youtube.DownloadResult
+	var report *retry.Report
+	if err == nil {
+		result, report, err = retry.DoWithResult(ctx, m.retry, retry.IsRetryable, func(ctx context.Context) (*youtube.DownloadResult, error) {
+			return m.downloader.Download(ctx, videoID, opts)
+		})
+	}
+
+	if err == nil && m.archive != nil {
+		archived := &storage.ArchivedDownload{VideoID: videoID}
+		if result != nil {
+			archived.Path = result.VideoPath
+		}
+		if archiveErr := m.archive.RecordDownload(ctx, archived); archiveErr != nil {
+			// The video is downloaded; it may only be fetched again
+			log.Printf("download: archive %s: %v", videoID, archiveErr)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if report != nil {
+		item.Attempts += report.Count()
+	}
+	switch {
+	case err == nil:
+		item.Error = ""
+		if result != nil {
+			item.VideoPath = result.VideoPath
+		}
+		m.setStatusLocked(item, StatusDone)
+	case ctx.Err() != nil:
+		// Interrupted, not failed: resume on the next Run
+		m.setStatusLocked(item, StatusPending)
+	default:
+		item.Error = err.Error()
+		m.setStatusLocked(item, StatusFailed)
+	}
+	m.persistLocked()
+}
+
+// setStatusLocked updates item's status and notifies OnUpdate. Callers must
+// hold m.mu.
+func (m *Manager) setStatusLocked(item *Item, status Status) {
+	item.Status = status
+	item.UpdatedAt = time.Now()
+	if m.onUpdate != nil {
+		m.onUpdate(*item)
+	}
+}
+
+// persistLocked saves the queue, logging failures: losing one state update
+// only means an item may be downloaded again after a restart. Callers must
+// hold m.mu.
+func (m *Manager) persistLocked() {
+	if err := m.saveLocked(); err != nil {
+		log.Printf("download: %v", err)
+	}
+}
+
+// saveLocked writes the queue to StatePath. Callers must hold m.mu.
+func (m *Manager) saveLocked() error {
+	if m.statePath == "" {
+		return nil
+	}
+	writer, err := storage.NewAtomicWriter(m.statePath)
+	if err != nil {
+		return fmt.Errorf("save queue state: %w", err)
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(queueState{Items: m.items}); err != nil {
+		writer.Abort()
+		return fmt.Errorf("save queue state: %w", err)
+	}
+	if err := writer.Commit(); err != nil {
+		return fmt.Errorf("save queue state: %w", err)
+	}
+	return nil
+}
+
+// load reads the queue from StatePath, if it exists.
+func (m *Manager) load() error {
+	data, err := os.ReadFile(m.statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load queue state: %w", err)
+	}
+	var state queueState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("load queue state %s: %w", m.statePath, storage.ErrStorageCorrupt)
+	}
+	for _, item := range state.Items {
+		if item == nil || item.VideoID == "" || m.byID[item.VideoID] != nil {
+			continue
+		}
+		if item.Status == StatusRunning {
+			// The previous process stopped mid-download
+			item.Status = StatusPending
+		}
+		m.items = append(m.items, item)
+		m.byID[item.VideoID] = item
+	}
+	return nil
+}
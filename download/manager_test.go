@@ -0,0 +1,249 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"ytsync/errcode"
+	"ytsync/retry"
+	"ytsync/storage"
+	"ytsync/youtube"
+)
+
+// fakeDownloader fails each video a configured number of times before
+// succeeding, and tracks how many downloads run at once.
+type fakeDownloader struct {
+	mu        sync.Mutex
+	failures  map[string]int
+	permanent map[string]bool
+	calls     map[string]int
+	block     chan struct{}
+
+	running, maxRunning int32
+}
+
+func newFakeDownloader() *fakeDownloader {
+	return &fakeDownloader{failures: map[string]int{}, permanent: map[string]bool{}, calls: map[string]int{}}
+}
+
+func (f *fakeDownloader) Download(ctx context.Context, videoID string, opts *youtube.DownloadOptions) (*youtube.DownloadResult, error) {
+	n := atomic.AddInt32(&f.running, 1)
+	defer atomic.AddInt32(&f.running, -1)
+	for {
+		max := atomic.LoadInt32(&f.maxRunning)
+		if n <= max || atomic.CompareAndSwapInt32(&f.maxRunning, max, n) {
+			break
+		}
+	}
+
+	f.mu.Lock()
+	f.calls[videoID]++
+	call := f.calls[videoID]
+	block := f.block
+	f.mu.Unlock()
+
+	if block != nil {
+		select {
+		case <-block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	if f.permanent[videoID] {
+		return nil, errcode.New(errcode.NotFound, "video unavailable")
+	}
+	if call <= f.failures[videoID] {
+		return nil, errors.New("connection reset")
+	}
+	return &youtube.DownloadResult{VideoPath: filepath.Join(opts.OutputDir, videoID+".mp4")}, nil
+}
+
+func fastRetry() *retry.Config {
+	return &retry.Config{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2}
+}
+
+func TestManagerRun(t *testing.T) {
+	d := newFakeDownloader()
+	d.failures["flaky"] = 2
+	d.failures["broken"] = 10
+	d.permanent["gone"] = true
+
+	var updates int32
+	m, err := NewManager(d, Config{
+		Concurrency: 3,
+		Retry:       fastRetry(),
+		OnUpdate:    func(Item) { atomic.AddInt32(&updates, 1) },
+	})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	ids := []string{"a", "b", "c", "d", "flaky", "broken", "gone"}
+	if err := m.AddAll(ids, &Options{OutputDir: "/out"}); err != nil {
+		t.Fatalf("AddAll() error = %v", err)
+	}
+	if err := m.Add("a", nil); err != nil {
+		t.Fatalf("Add() duplicate error = %v", err)
+	}
+	if err := m.Add("", nil); !errors.Is(err, ErrEmptyVideoID) {
+		t.Errorf("Add(\"\") error = %v, want ErrEmptyVideoID", err)
+	}
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := m.Stats(); got != (Stats{Done: 5, Failed: 2}) {
+		t.Errorf("Stats() = %+v, want 5 done, 2 failed", got)
+	}
+	if d.maxRunning > 3 {
+		t.Errorf("max concurrent downloads = %d, want <= 3", d.maxRunning)
+	}
+	byID := map[string]Item{}
+	for _, item := range m.Items() {
+		byID[item.VideoID] = item
+	}
+	if item := byID["flaky"]; item.Status != StatusDone || item.Attempts != 3 || item.VideoPath != "/out/flaky.mp4" {
+		t.Errorf("flaky = %+v, want done after 3 attempts", item)
+	}
+	if item := byID["broken"]; item.Status != StatusFailed || item.Attempts != 3 || item.Error == "" {
+		t.Errorf("broken = %+v, want failed after 3 attempts", item)
+	}
+	if item := byID["gone"]; item.Status != StatusFailed || item.Attempts != 1 {
+		t.Errorf("gone = %+v, want failed without retrying a permanent error", item)
+	}
+	if updates != int32(2*len(ids)) {
+		t.Errorf("OnUpdate called %d times, want %d", updates, 2*len(ids))
+	}
+
+	// Requeued failures run again
+	d.failures["broken"] = 0
+	if n, err := m.RetryFailed(); err != nil || n != 2 {
+		t.Errorf("RetryFailed() = %d, %v; want 2", n, err)
+	}
+	m.Run(context.Background())
+	if got := m.Stats(); got != (Stats{Done: 6, Failed: 1}) {
+		t.Errorf("Stats() after retry = %+v, want 6 done, 1 failed", got)
+	}
+}
+
+func TestManagerPersistsAndResumes(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "queue.json")
+	d := newFakeDownloader()
+	d.block = make(chan struct{})
+
+	m, err := NewManager(d, Config{Concurrency: 1, Retry: fastRetry(), StatePath: statePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.AddAll([]string{"a", "b", "c"}, &Options{Format: "mp4"})
+
+	// Interrupt the run while the first download is in progress
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Run(ctx) }()
+	waitFor(t, func() bool { return m.Stats().Running == 1 })
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+	if got := m.Stats(); got != (Stats{Pending: 3}) {
+		t.Errorf("Stats() after cancel = %+v, want 3 pending", got)
+	}
+
+	// A new manager picks the queue up from disk
+	d.block = nil
+	resumed, err := NewManager(d, Config{Concurrency: 2, Retry: fastRetry(), StatePath: statePath})
+	if err != nil {
+		t.Fatalf("NewManager() reload error = %v", err)
+	}
+	items := resumed.Items()
+	if len(items) != 3 || items[0].VideoID != "a" || items[0].Options.Format != "mp4" {
+		t.Fatalf("reloaded items = %+v", items)
+	}
+	if err := resumed.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := resumed.Stats(); got != (Stats{Done: 3}) {
+		t.Errorf("Stats() after resume = %+v, want 3 done", got)
+	}
+}
+
+func TestManagerPause(t *testing.T) {
+	d := newFakeDownloader()
+	m, _ := NewManager(d, Config{Concurrency: 2, Retry: fastRetry()})
+	m.AddAll([]string{"a", "b", "c"}, nil)
+
+	m.Pause()
+	done := make(chan error)
+	go func() { done <- m.Run(context.Background()) }()
+
+	time.Sleep(20 * time.Millisecond)
+	if got := m.Stats(); got.Pending != 3 {
+		t.Fatalf("Stats() while paused = %+v, want nothing started", got)
+	}
+	if !m.Paused() {
+		t.Error("Paused() = false")
+	}
+
+	m.Resume()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not finish after Resume()")
+	}
+	if got := m.Stats(); got.Done != 3 {
+		t.Errorf("Stats() = %+v, want 3 done", got)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestManagerArchive(t *testing.T) {
+	archive, err := storage.NewJSONStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	ctx := context.Background()
+	if err := archive.RecordDownload(ctx, &storage.ArchivedDownload{VideoID: "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newFakeDownloader()
+	m, err := NewManager(d, Config{Retry: fastRetry(), Archive: archive})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	m.AddAll([]string{"old", "new"}, &Options{OutputDir: "/out"})
+	if err := m.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := m.Stats(); got != (Stats{Done: 2}) {
+		t.Errorf("Stats() = %+v, want 2 done", got)
+	}
+	if d.calls["old"] != 0 || d.calls["new"] != 1 {
+		t.Errorf("download calls = %v, want only the new video", d.calls)
+	}
+	downloads, err := archive.ListDownloads(ctx)
+	if err != nil || len(downloads) != 2 || downloads[1].VideoID != "new" || downloads[1].Path != "/out/new.mp4" {
+		t.Errorf("ListDownloads() = %+v, %v; want the new video archived", downloads, err)
+	}
+}
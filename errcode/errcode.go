@@ -0,0 +1,176 @@
+// Package errcode defines a stable set of error codes shared by all ytsync
+// packages, so callers can branch on the kind of failure without matching
+// error strings.
+//
+// Every package attaches a code to its errors, either by defining sentinels
+// with New, wrapping with Wrap, or implementing Coder on its error types.
+// Use Of to read the code from any error chain:
+//
+//	switch errcode.Of(err) {
+//	case errcode.RateLimited, errcode.BotDetected:
+//		// back off
+//	case errcode.NotFound:
+//		// skip
+//	}
+package errcode
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net"
+	"os/exec"
+	"strconv"
+)
+
+// Code identifies a category of failure. Codes are stable strings and safe
+// to persist or expose in logs and APIs.
+type Code string
+
+const (
+	// Unknown is returned for errors that carry no code.
+	Unknown Code = "unknown"
+	// NotFound indicates the requested channel, video, transcript, or record does not exist.
+	NotFound Code = "not_found"
+	// AlreadyExists indicates the entity or output file already exists.
+	AlreadyExists Code = "already_exists"
+	// InvalidInput indicates a malformed URL, ID, option, or argument.
+	InvalidInput Code = "invalid_input"
+	// RateLimited indicates the remote service is throttling requests.
+	RateLimited Code = "rate_limited"
+	// BotDetected indicates the remote service rejected the request as automated traffic.
+	BotDetected Code = "bot_detected"
+	// QuotaExceeded indicates an API quota has been used up.
+	QuotaExceeded Code = "quota_exceeded"
+	// Unavailable indicates a remote service is down, failing, or short-circuited.
+	Unavailable Code = "unavailable"
+	// Timeout indicates an operation ran out of time.
+	Timeout Code = "timeout"
+	// Canceled indicates the operation was canceled by the caller.
+	Canceled Code = "canceled"
+	// ParseFailure indicates a response or file could not be parsed.
+	ParseFailure Code = "parse_failure"
+	// SubprocessFailure indicates an external tool (yt-dlp, ffmpeg) failed or is missing.
+	SubprocessFailure Code = "subprocess_failure"
+	// Corrupt indicates stored or downloaded data failed an integrity check.
+	Corrupt Code = "corrupt"
+)
+
+// Coder is implemented by error types that know their own code.
+type Coder interface {
+	ErrorCode() Code
+}
+
+// Error is an error annotated with a code.
+type Error struct {
+	// Code is the error category.
+	Code Code
+	// Op is the operation that failed (optional).
+	Op string
+	// Err is the underlying error (nil for sentinels created with New).
+	Err error
+
+	msg string
+}
+
+// New creates a sentinel error with a fixed message and code.
+// Sentinels compare by identity, so errors.Is works as with errors.New.
+func New(code Code, msg string) error {
+	return &Error{Code: code, msg: msg}
+}
+
+// Wrap annotates err with a code and operation. Returns nil if err is nil.
+// The wrapped error's message is preserved, prefixed by op if set.
+func Wrap(code Code, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Op: op, Err: err}
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.msg
+	}
+	if e.Op != "" {
+		return e.Op + ": " + e.Err.Error()
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the error's code.
+func (e *Error) ErrorCode() Code {
+	return e.Code
+}
+
+// Of returns the code of the outermost coded error in err's chain.
+// Errors from the standard library are classified where possible:
+// context errors, network timeouts, JSON/XML syntax errors, and
+// subprocess failures. Returns Unknown for nil or unclassified errors.
+func Of(err error) Code {
+	if err == nil {
+		return Unknown
+	}
+
+	var coder Coder
+	if errors.As(err, &coder) {
+		if code := coder.ErrorCode(); code != "" && code != Unknown {
+			return code
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Timeout
+	}
+
+	var jsonSyntax *json.SyntaxError
+	var jsonType *json.UnmarshalTypeError
+	var xmlSyntax *xml.SyntaxError
+	var numErr *strconv.NumError
+	if errors.As(err, &jsonSyntax) || errors.As(err, &jsonType) || errors.As(err, &xmlSyntax) || errors.As(err, &numErr) {
+		return ParseFailure
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) || errors.Is(err, exec.ErrNotFound) {
+		return SubprocessFailure
+	}
+
+	return Unknown
+}
+
+// Is reports whether err's code is code.
+func Is(err error, code Code) bool {
+	return Of(err) == code
+}
+
+// IsTemporary reports whether the code describes a condition that may
+// clear on its own, so the operation is worth retrying later.
+func (c Code) IsTemporary() bool {
+	switch c {
+	case RateLimited, BotDetected, QuotaExceeded, Unavailable, Timeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the code as a string.
+func (c Code) String() string {
+	return string(c)
+}
@@ -0,0 +1,103 @@
+package errcode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+type codedError struct{ code Code }
+
+func (e *codedError) Error() string   { return "coded" }
+func (e *codedError) ErrorCode() Code { return e.code }
+
+func TestNew_Sentinel(t *testing.T) {
+	sentinel := New(NotFound, "thing: not found")
+	if sentinel.Error() != "thing: not found" {
+		t.Errorf("Error() = %q, want %q", sentinel.Error(), "thing: not found")
+	}
+
+	wrapped := fmt.Errorf("lookup: %w", sentinel)
+	if !errors.Is(wrapped, sentinel) {
+		t.Error("errors.Is(wrapped, sentinel) = false, want true")
+	}
+	if errors.Is(wrapped, New(NotFound, "thing: not found")) {
+		t.Error("errors.Is matched a distinct sentinel with the same message")
+	}
+	if got := Of(wrapped); got != NotFound {
+		t.Errorf("Of() = %q, want %q", got, NotFound)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if Wrap(Timeout, "op", nil) != nil {
+		t.Error("Wrap(nil) should return nil")
+	}
+
+	base := errors.New("boom")
+	err := Wrap(Unavailable, "fetch page", base)
+	if err.Error() != "fetch page: boom" {
+		t.Errorf("Error() = %q, want %q", err.Error(), "fetch page: boom")
+	}
+	if !errors.Is(err, base) {
+		t.Error("errors.Is(err, base) = false, want true")
+	}
+	if got := Of(err); got != Unavailable {
+		t.Errorf("Of() = %q, want %q", got, Unavailable)
+	}
+
+	if got := Wrap(Unavailable, "", base).Error(); got != "boom" {
+		t.Errorf("Error() without op = %q, want %q", got, "boom")
+	}
+}
+
+func TestOf(t *testing.T) {
+	var syntaxErr error
+	if err := json.Unmarshal([]byte("{"), new(map[string]any)); err != nil {
+		syntaxErr = err
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"nil", nil, Unknown},
+		{"plain", errors.New("plain"), Unknown},
+		{"coder", &codedError{BotDetected}, BotDetected},
+		{"wrapped coder", fmt.Errorf("ctx: %w", &codedError{QuotaExceeded}), QuotaExceeded},
+		{"unknown coder falls through", fmt.Errorf("%w: %w", &codedError{Unknown}, context.Canceled), Canceled},
+		{"outermost code wins", Wrap(Timeout, "", New(NotFound, "x")), Timeout},
+		{"deadline", fmt.Errorf("run: %w", context.DeadlineExceeded), Timeout},
+		{"canceled", context.Canceled, Canceled},
+		{"json syntax", fmt.Errorf("parse: %w", syntaxErr), ParseFailure},
+		{"exec not found", &exec.Error{Name: "yt-dlp", Err: exec.ErrNotFound}, SubprocessFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Of(tt.err); got != tt.want {
+				t.Errorf("Of() = %q, want %q", got, tt.want)
+			}
+			if tt.err != nil && !Is(tt.err, tt.want) {
+				t.Errorf("Is(err, %q) = false, want true", tt.want)
+			}
+		})
+	}
+}
+
+func TestCode_IsTemporary(t *testing.T) {
+	for _, c := range []Code{RateLimited, BotDetected, QuotaExceeded, Unavailable, Timeout} {
+		if !c.IsTemporary() {
+			t.Errorf("%s.IsTemporary() = false, want true", c)
+		}
+	}
+	for _, c := range []Code{Unknown, NotFound, InvalidInput, ParseFailure, SubprocessFailure, Corrupt, Canceled} {
+		if c.IsTemporary() {
+			t.Errorf("%s.IsTemporary() = true, want false", c)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ytsync
 
 import (
+	"ytsync/errcode"
 	"ytsync/retry"
 	"ytsync/storage"
 	"ytsync/youtube"
@@ -16,6 +17,15 @@ import (
 //		fmt.Println("Channel not found")
 //	}
 //
+// Using ErrorCode() to branch on the kind of failure without string matching:
+//
+//	switch ytsync.ErrorCode(err) {
+//	case errcode.RateLimited, errcode.BotDetected:
+//		// back off and retry later
+//	case errcode.NotFound:
+//		// skip this channel
+//	}
+//
 // Using errors.As() for wrapped errors:
 //
 //	var listerErr *youtube.ListerError
@@ -31,6 +41,11 @@ import (
 //   - youtube.ErrNetworkTimeout: Network timeout occurred
 //   - youtube.ErrInvalidURL: Invalid YouTube URL
 //   - youtube.ErrYtdlpNotInstalled: yt-dlp binary not found
+//   - youtube.ErrBotDetected: YouTube flagged requests as automated
+//   - youtube.ErrChannelTerminated: Channel was terminated or does not exist
+//   - youtube.ErrAgeRestricted: Content requires a signed-in adult viewer
+//   - youtube.ErrLoginRequired: Content requires a signed-in viewer
+//   - youtube.ErrCorruptDownload: Downloaded file failed verification
 //   - youtube.VideoLister: Interface for video listing
 //   - youtube.ListerError: Error during video listing
 //   - youtube.TranscriptError: Error during transcript extraction
@@ -46,6 +61,8 @@ import (
 //   - storage.ErrInvalidInput: Invalid input provided
 //   - storage.ErrStorageCorrupt: Data corruption detected
 //   - storage.ErrLockTimeout: File lock timeout
+//   - storage.ErrStoreLocked: Store held by another process
+//   - storage.ErrReadOnly: Write to a store opened read-only
 //   - storage.StorageError: General storage operation error
 
 // Type aliases for convenient error handling.
@@ -72,6 +89,18 @@ var (
 	ErrInvalidURL = youtube.ErrInvalidURL
 	// ErrYtdlpNotInstalled indicates yt-dlp binary was not found.
 	ErrYtdlpNotInstalled = youtube.ErrYtdlpNotInstalled
+	// ErrBotDetected indicates YouTube flagged requests as automated traffic.
+	ErrBotDetected = youtube.ErrBotDetected
+	// ErrChannelTerminated indicates YouTube reports the channel as
+	// terminated or nonexistent.
+	ErrChannelTerminated = youtube.ErrChannelTerminated
+	// ErrAgeRestricted indicates the content requires a signed-in viewer
+	// who confirmed their age.
+	ErrAgeRestricted = youtube.ErrAgeRestricted
+	// ErrLoginRequired indicates the content requires a signed-in viewer.
+	ErrLoginRequired = youtube.ErrLoginRequired
+	// ErrCorruptDownload indicates a downloaded file is truncated or corrupted.
+	ErrCorruptDownload = youtube.ErrCorruptDownload
 
 	// Storage errors
 	// ErrNotFound indicates an entity was not found in storage.
@@ -84,6 +113,10 @@ var (
 	ErrStorageCorrupt = storage.ErrStorageCorrupt
 	// ErrLockTimeout indicates a timeout acquiring a file lock.
 	ErrLockTimeout = storage.ErrLockTimeout
+	// ErrStoreLocked indicates another process holds the store's lock.
+	ErrStoreLocked = storage.ErrStoreLocked
+	// ErrReadOnly indicates a write to a store opened read-only.
+	ErrReadOnly = storage.ErrReadOnly
 )
 
 // IsRetryable determines if an error should be retried.
@@ -91,3 +124,10 @@ var (
 func IsRetryable(err error) bool {
 	return retry.IsRetryable(err)
 }
+
+// ErrorCode returns the errcode.Code classifying err, such as
+// errcode.NotFound or errcode.RateLimited. Returns errcode.Unknown
+// for nil or unclassified errors.
+func ErrorCode(err error) errcode.Code {
+	return errcode.Of(err)
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"log"
 	"testing"
+	"time"
+	"ytsync/config"
+	ythttp "ytsync/http"
 )
 
 // ExampleListVideos demonstrates how to list videos from a YouTube channel.
@@ -45,6 +48,32 @@ func ExampleListVideosWithOptions() {
 	fmt.Printf("Found %d videos\n", len(videos))
 }
 
+// ExampleListVideosWithOptions_config demonstrates passing a Config built in
+// code instead of loading ytsync.json and the environment.
+func ExampleListVideosWithOptions_config() {
+	ctx := context.Background()
+
+	cfg, err := config.New(
+		config.WithYtdlpPath("/usr/local/bin/yt-dlp"),
+		config.WithRetry(3, time.Second, 10*time.Second, 2),
+	)
+	if err != nil {
+		log.Printf("Invalid config: %v\n", err)
+		return
+	}
+
+	videos, err := ListVideosWithOptions(ctx, "https://www.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw", &ListOptions{
+		MaxResults: 50,
+		Config:     cfg,
+	})
+	if err != nil {
+		log.Printf("Error listing videos: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Found %d videos\n", len(videos))
+}
+
 // ExampleExtractTranscript demonstrates how to extract a transcript.
 func ExampleExtractTranscript() {
 	ctx := context.Background()
@@ -174,3 +203,58 @@ func TestErrorHandling(t *testing.T) {
 		}
 	}
 }
+
+func TestExplicitConfigValidation(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.YtdlpTimeout = 0
+	cfg.MaxRetries = -1
+
+	_, err := ListVideosWithOptions(context.Background(), "UCuAXFkgsw1L7xaCfnd5JJOw", &ListOptions{Config: cfg})
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("ListVideosWithOptions() error = %v, want *config.ValidationError", err)
+	}
+	if len(validationErr.Problems) != 2 {
+		t.Errorf("Problems = %v, want 2 problems", validationErr.Problems)
+	}
+	if ErrorCode(err) != "invalid_input" {
+		t.Errorf("ErrorCode() = %q, want invalid_input", ErrorCode(err))
+	}
+
+	if _, err := config.New(config.WithYouTubeAPI("", 0), config.WithMaxVideos(-1)); err == nil {
+		t.Error("config.New() accepted an invalid configuration")
+	}
+	if _, err := config.New(config.WithYtdlpPath("/opt/yt-dlp")); err != nil {
+		t.Errorf("config.New() error = %v", err)
+	}
+}
+
+func TestHTTPConfig(t *testing.T) {
+	cfg, err := config.New(
+		config.WithUserAgent("archiver/2.0"),
+		config.WithHTTPRates(0, 2, 0, 8),
+		config.WithHTTPCircuitBreaker(3, 0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.MaxRetries = 2
+
+	hc := httpConfig(cfg)
+	defaults := ythttp.DefaultConfig()
+	if hc.UserAgent != "archiver/2.0" || hc.Retry.MaxRetries != 2 {
+		t.Errorf("UserAgent, MaxRetries = %q, %d", hc.UserAgent, hc.Retry.MaxRetries)
+	}
+	if hc.RateLimiter.DataAPIRPS != 2 || hc.RateLimiter.GlobalRPS != 8 {
+		t.Errorf("DataAPIRPS, GlobalRPS = %v, %v; want 2, 8", hc.RateLimiter.DataAPIRPS, hc.RateLimiter.GlobalRPS)
+	}
+	if hc.CircuitBreaker.FailureThreshold != 3 {
+		t.Errorf("FailureThreshold = %d, want 3", hc.CircuitBreaker.FailureThreshold)
+	}
+	// Zero settings keep the http package defaults
+	if hc.RateLimiter.InnertubeRPS != defaults.RateLimiter.InnertubeRPS ||
+		hc.CircuitBreaker.RecoveryTimeout != defaults.CircuitBreaker.RecoveryTimeout ||
+		hc.Timeout != defaults.Timeout || hc.MaxConcurrent != defaults.MaxConcurrent {
+		t.Errorf("httpConfig() = %+v, want defaults for unset fields", hc)
+	}
+}
@@ -0,0 +1,352 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"ytsync/tags"
+)
+
+// BotSignal classifies how a response indicated suspected bot traffic.
+type BotSignal string
+
+const (
+	// BotSignalForbidden is a 403 response with no more specific marker.
+	BotSignalForbidden BotSignal = "forbidden"
+	// BotSignalCaptcha is a Google "unusual traffic" page asking for a CAPTCHA.
+	BotSignalCaptcha BotSignal = "captcha"
+	// BotSignalConsent is a redirect to the cookie consent interstitial,
+	// usually meaning the request carried no consent cookie from the EU.
+	BotSignalConsent BotSignal = "consent"
+	// BotSignalSignIn is YouTube's "Sign in to confirm you're not a bot" page.
+	BotSignalSignIn BotSignal = "sign_in"
+)
+
+// botMarkers are lower-cased body substrings that identify a BotSignal,
+// checked in order.
+var botMarkers = []struct {
+	signal BotSignal
+	marker string
+}{
+	{BotSignalSignIn, "confirm you're not a bot"},
+	{BotSignalSignIn, "confirm you’re not a bot"},
+	{BotSignalCaptcha, "unusual traffic from your computer network"},
+	{BotSignalCaptcha, "g-recaptcha"},
+	{BotSignalCaptcha, "google.com/sorry"},
+	{BotSignalConsent, "consent.youtube.com"},
+	{BotSignalConsent, "consent.google.com"},
+	{BotSignalConsent, "before you continue to youtube"},
+}
+
+// ClassifyBotResponse reports whether a response looks like anti-bot
+// protection and, if so, how. finalURL is the URL after redirects; a
+// successful response from a consent or CAPTCHA page counts. evidence is
+// the URL or body marker that matched.
+func ClassifyBotResponse(statusCode int, finalURL string, body []byte) (signal BotSignal, evidence string) {
+	if u, err := url.Parse(finalURL); err == nil {
+		host := strings.ToLower(u.Hostname())
+		switch {
+		case strings.HasPrefix(host, "consent."):
+			return BotSignalConsent, u.Host + u.Path
+		case strings.HasSuffix(host, "google.com") && strings.HasPrefix(u.Path, "/sorry"):
+			return BotSignalCaptcha, u.Host + u.Path
+		}
+	}
+	if statusCode >= 200 && statusCode < 300 {
+		return "", ""
+	}
+
+	lower := bytes.ToLower(body)
+	for _, m := range botMarkers {
+		if bytes.Contains(lower, []byte(m.marker)) {
+			return m.signal, m.marker
+		}
+	}
+	if statusCode == http.StatusForbidden {
+		return BotSignalForbidden, "status 403"
+	}
+	return "", ""
+}
+
+// BotDetectionConfig configures bot-detection diagnostics.
+type BotDetectionConfig struct {
+	// BufferSize is the maximum number of reports kept; older reports are
+	// discarded first. Default: 100
+	BufferSize int
+
+	// SnippetSize is the number of response body bytes kept per report.
+	// Default: 1 KiB
+	SnippetSize int
+
+	// OnReport, if set, is called with every new report, e.g. to log it or
+	// raise an alert. It must not block.
+	OnReport func(BotDetectionReport)
+}
+
+// DefaultBotDetectionConfig returns the default diagnostics configuration.
+func DefaultBotDetectionConfig() BotDetectionConfig {
+	return BotDetectionConfig{
+		BufferSize:  100,
+		SnippetSize: 1024,
+	}
+}
+
+// BotDetectionReport is a diagnostic bundle for one response classified as
+// anti-bot protection. It records what was sent and the client's state at
+// the time, so operators can tell why a deployment started being blocked.
+type BotDetectionReport struct {
+	// Time is when the response was received.
+	Time time.Time `json:"time"`
+	// Method and URL identify the request.
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// FinalURL is the URL after redirects, if different from URL.
+	FinalURL string `json:"final_url,omitempty"`
+	// Domain is the request host, as used for rate limiting.
+	Domain string `json:"domain"`
+	// Tags are the tags of the request's context, such as the channel and
+	// operation it was made for.
+	Tags map[string]string `json:"tags,omitempty"`
+	// Attempt is the 1-based attempt number within the request.
+	Attempt int `json:"attempt"`
+	// StatusCode is the response status.
+	StatusCode int `json:"status_code"`
+	// Signal is the classification of the response.
+	Signal BotSignal `json:"signal"`
+	// Evidence is the URL or body marker the classification is based on.
+	Evidence string `json:"evidence"`
+	// Snippet is the start of the response body.
+	Snippet string `json:"snippet,omitempty"`
+	// RequestHeaders and ResponseHeaders are the headers, with credentials
+	// redacted.
+	RequestHeaders  http.Header `json:"request_headers,omitempty"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	// UserAgent is the User-Agent the request was sent with.
+	UserAgent string `json:"user_agent"`
+	// Cookies are the names, not values, of the cookies sent.
+	Cookies []string `json:"cookies,omitempty"`
+	// Proxy is the proxy the request went through, without credentials, or
+	// empty for a direct connection.
+	Proxy string `json:"proxy,omitempty"`
+	// Rate is the domain's request rate limit when the request was sent,
+	// in requests per second. 0 means unlimited.
+	Rate float64 `json:"rate"`
+	// ConsecutiveErrors is the number of rate limit and bot-detection
+	// responses from the domain in a row, including this one.
+	ConsecutiveErrors int `json:"consecutive_errors"`
+}
+
+// BotDetectionStats counts bot-detection responses.
+type BotDetectionStats struct {
+	// Total is the number of responses classified as bot detection.
+	Total int64 `json:"total"`
+	// BySignal counts responses per classification.
+	BySignal map[BotSignal]int64 `json:"by_signal"`
+	// ByDomain counts responses per request domain.
+	ByDomain map[string]int64 `json:"by_domain"`
+	// ByUserAgent counts responses per User-Agent, to spot a burned one.
+	ByUserAgent map[string]int64 `json:"by_user_agent"`
+	// First and Last are when the first and latest responses were seen.
+	First time.Time `json:"first,omitempty"`
+	Last  time.Time `json:"last,omitempty"`
+}
+
+// BotDetector collects bot-detection reports in a ring buffer and keeps
+// running counters. All methods are safe for concurrent use and on a nil
+// BotDetector.
+type BotDetector struct {
+	mu      sync.Mutex
+	config  BotDetectionConfig
+	entries []BotDetectionReport
+	next    int
+	full    bool
+	stats   BotDetectionStats
+}
+
+// NewBotDetector creates a detector with the given configuration.
+func NewBotDetector(cfg BotDetectionConfig) *BotDetector {
+	defaults := DefaultBotDetectionConfig()
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaults.BufferSize
+	}
+	if cfg.SnippetSize <= 0 {
+		cfg.SnippetSize = defaults.SnippetSize
+	}
+	d := &BotDetector{
+		config:  cfg,
+		entries: make([]BotDetectionReport, cfg.BufferSize),
+	}
+	d.resetStatsLocked()
+	return d
+}
+
+// Reports returns the recorded reports, oldest first.
+func (d *BotDetector) Reports() []BotDetectionReport {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var out []BotDetectionReport
+	if d.full {
+		out = append(out, d.entries[d.next:]...)
+	}
+	return append(out, d.entries[:d.next]...)
+}
+
+// Stats returns a copy of the counters.
+func (d *BotDetector) Stats() BotDetectionStats {
+	if d == nil {
+		return BotDetectionStats{}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	stats := d.stats
+	stats.BySignal = make(map[BotSignal]int64, len(d.stats.BySignal))
+	for k, v := range d.stats.BySignal {
+		stats.BySignal[k] = v
+	}
+	stats.ByDomain = make(map[string]int64, len(d.stats.ByDomain))
+	for k, v := range d.stats.ByDomain {
+		stats.ByDomain[k] = v
+	}
+	stats.ByUserAgent = make(map[string]int64, len(d.stats.ByUserAgent))
+	for k, v := range d.stats.ByUserAgent {
+		stats.ByUserAgent[k] = v
+	}
+	return stats
+}
+
+// Reset discards all reports and counters.
+func (d *BotDetector) Reset() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = make([]BotDetectionReport, len(d.entries))
+	d.next = 0
+	d.full = false
+	d.resetStatsLocked()
+}
+
+func (d *BotDetector) resetStatsLocked() {
+	d.stats = BotDetectionStats{
+		BySignal:    make(map[BotSignal]int64),
+		ByDomain:    make(map[string]int64),
+		ByUserAgent: make(map[string]int64),
+	}
+}
+
+// WriteJSON writes the counters and reports as one JSON document.
+func (d *BotDetector) WriteJSON(w io.Writer) error {
+	reports := d.Reports()
+	if reports == nil {
+		reports = []BotDetectionReport{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Stats   BotDetectionStats    `json:"stats"`
+		Reports []BotDetectionReport `json:"reports"`
+	}{d.Stats(), reports})
+}
+
+// snippetSize returns the number of body bytes to keep per report.
+func (d *BotDetector) snippetSize() int {
+	if d == nil {
+		return 0
+	}
+	return d.config.SnippetSize
+}
+
+// record stores a report and updates the counters.
+func (d *BotDetector) record(report BotDetectionReport) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.entries[d.next] = report
+	d.next = (d.next + 1) % len(d.entries)
+	if d.next == 0 {
+		d.full = true
+	}
+	d.stats.Total++
+	d.stats.BySignal[report.Signal]++
+	d.stats.ByDomain[report.Domain]++
+	d.stats.ByUserAgent[report.UserAgent]++
+	if d.stats.First.IsZero() {
+		d.stats.First = report.Time
+	}
+	d.stats.Last = report.Time
+	onReport := d.config.OnReport
+	d.mu.Unlock()
+
+	if onReport != nil {
+		onReport(report)
+	}
+}
+
+// responseURL returns the URL resp was served from, after redirects.
+func responseURL(resp *http.Response) string {
+	if resp.Request == nil || resp.Request.URL == nil {
+		return ""
+	}
+	return resp.Request.URL.String()
+}
+
+// botReport builds the diagnostic report for a bot-detection response to
+// req. Cookies are read from the header and the client's jar, since the
+// jar's cookies are added to a copy of req when it is sent.
+func (c *Client) botReport(req *http.Request, resp *http.Response, body []byte, signal BotSignal, evidence string, attempt int) BotDetectionReport {
+	report := BotDetectionReport{
+		Time:            time.Now(),
+		Method:          req.Method,
+		URL:             req.URL.String(),
+		Domain:          c.rateLimiter.extractDomain(req.URL.String()),
+		Tags:            tags.From(req.Context()).Map(),
+		Attempt:         attempt,
+		StatusCode:      resp.StatusCode,
+		Signal:          signal,
+		Evidence:        evidence,
+		Snippet:         string(truncateBody(body, c.botDetector.snippetSize())),
+		RequestHeaders:  redactHeaders(req.Header),
+		ResponseHeaders: redactHeaders(resp.Header),
+		UserAgent:       req.Header.Get("User-Agent"),
+		Rate:            c.rateLimiter.CurrentRate(req.URL.String()),
+	}
+	if final := responseURL(resp); final != report.URL {
+		report.FinalURL = final
+	}
+
+	seen := make(map[string]bool)
+	cookies := req.Cookies()
+	if c.base.Jar != nil {
+		cookies = append(cookies, c.base.Jar.Cookies(req.URL)...)
+	}
+	for _, cookie := range cookies {
+		if !seen[cookie.Name] {
+			seen[cookie.Name] = true
+			report.Cookies = append(report.Cookies, cookie.Name)
+		}
+	}
+
+	if c.proxy != nil {
+		if proxyURL, err := c.proxy(req); err == nil && proxyURL != nil {
+			redacted := *proxyURL
+			redacted.User = nil
+			report.Proxy = redacted.String()
+		}
+	}
+	if state := c.rateLimiter.GetBackoffState(req.URL.String()); state != nil {
+		report.ConsecutiveErrors = state.ConsecutiveErrors
+	}
+	return report
+}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newBotTestClient() *Client {
+	cfg := DefaultConfig()
+	cfg.Retry.MaxRetries = 0
+	cfg.RateLimiter.EnableDynamicBackoff = false
+	cfg.UserAgent = "test-agent/1.0"
+	cfg.BotDetection = BotDetectionConfig{BufferSize: 2, SnippetSize: 16}
+	return New(cfg)
+}
+
+func TestClassifyBotResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		url      string
+		body     string
+		want     BotSignal
+		evidence string
+	}{
+		{"plain 403", 403, "https://www.youtube.com/watch?v=x", "Forbidden", BotSignalForbidden, "status 403"},
+		{"sign in wall", 403, "https://www.youtube.com/watch?v=x", "Sign in to confirm you’re not a bot", BotSignalSignIn, "confirm you’re not a bot"},
+		{"captcha 429", 429, "https://www.google.com/sorry/index?continue=x", "", BotSignalCaptcha, "www.google.com/sorry/index"},
+		{"captcha body", 429, "https://www.youtube.com/", "Our systems have detected unusual traffic from your computer network", BotSignalCaptcha, "unusual traffic from your computer network"},
+		{"consent redirect", 200, "https://consent.youtube.com/m?continue=x", "<html>", BotSignalConsent, "consent.youtube.com/m"},
+		{"plain 429", 429, "https://www.youtube.com/", "slow down", "", ""},
+		{"ok page mentioning captcha", 200, "https://www.youtube.com/watch?v=x", "g-recaptcha", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signal, evidence := ClassifyBotResponse(tt.status, tt.url, []byte(tt.body))
+			if signal != tt.want || evidence != tt.evidence {
+				t.Errorf("ClassifyBotResponse() = %q, %q; want %q, %q", signal, evidence, tt.want, tt.evidence)
+			}
+		})
+	}
+}
+
+func TestBotDetectorRecordsReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "NID=abc")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("Sign in to confirm you're not a bot. This helps protect our community."))
+	}))
+	defer server.Close()
+
+	client := newBotTestClient()
+	defer client.Close()
+
+	_, err := client.Do(context.Background(), http.MethodGet, server.URL+"/watch", nil,
+		map[string]string{"Cookie": "CONSENT=YES+1; SID=secret"})
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) || !rateErr.IsBotDetection {
+		t.Fatalf("error = %v, want bot detection", err)
+	}
+
+	reports := client.BotDetector().Reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	r := reports[0]
+	if r.Signal != BotSignalSignIn || r.StatusCode != 403 || r.Attempt != 1 {
+		t.Errorf("report = %+v", r)
+	}
+	if r.UserAgent != "test-agent/1.0" {
+		t.Errorf("UserAgent = %q", r.UserAgent)
+	}
+	if strings.Join(r.Cookies, ",") != "CONSENT,SID" {
+		t.Errorf("Cookies = %v, want names only", r.Cookies)
+	}
+	if r.RequestHeaders.Get("Cookie") != "[redacted]" || r.ResponseHeaders.Get("Set-Cookie") != "[redacted]" {
+		t.Errorf("cookies not redacted: %v / %v", r.RequestHeaders, r.ResponseHeaders)
+	}
+	if r.Snippet != "Sign in to confi" {
+		t.Errorf("Snippet = %q, want the first 16 bytes", r.Snippet)
+	}
+	if r.Rate <= 0 {
+		t.Errorf("Rate = %v, want the domain's rate limit", r.Rate)
+	}
+
+	stats := client.BotDetector().Stats()
+	if stats.Total != 1 || stats.BySignal[BotSignalSignIn] != 1 || stats.ByUserAgent["test-agent/1.0"] != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestBotDetectorCaptchaOn429(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`<div class="g-recaptcha"></div>`))
+	}))
+	defer server.Close()
+
+	client := newBotTestClient()
+	defer client.Close()
+
+	_, err := client.Get(context.Background(), server.URL)
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) || !rateErr.IsBotDetection {
+		t.Fatalf("error = %v, want a CAPTCHA page classified as bot detection", err)
+	}
+	if stats := client.BotDetector().Stats(); stats.BySignal[BotSignalCaptcha] != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestBotDetectorIgnoresPlainRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := newBotTestClient()
+	defer client.Close()
+
+	client.Get(context.Background(), server.URL)
+	if stats := client.BotDetector().Stats(); stats.Total != 0 {
+		t.Errorf("plain 429 counted as bot detection: %+v", stats)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestBotDetectorConsentRedirect(t *testing.T) {
+	client := newBotTestClient()
+	defer client.Close()
+	client.base.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Request: req}
+		if req.URL.Host == "consent.youtube.com" {
+			resp.Body = io.NopCloser(strings.NewReader("Before you continue to YouTube"))
+		} else {
+			resp.StatusCode = http.StatusFound
+			resp.Header.Set("Location", "https://consent.youtube.com/m?continue=x")
+			resp.Body = io.NopCloser(strings.NewReader(""))
+		}
+		return resp, nil
+	})
+
+	resp, err := client.Get(context.Background(), "https://www.youtube.com/watch?v=x")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !bytes.Contains(resp.Body, []byte("Before you continue")) {
+		t.Errorf("body = %q", resp.Body)
+	}
+	reports := client.BotDetector().Reports()
+	if len(reports) != 1 || reports[0].Signal != BotSignalConsent {
+		t.Fatalf("reports = %+v, want one consent report", reports)
+	}
+	if reports[0].FinalURL != "https://consent.youtube.com/m?continue=x" {
+		t.Errorf("FinalURL = %q", reports[0].FinalURL)
+	}
+}
+
+func TestBotDetectorRingBufferAndJSON(t *testing.T) {
+	d := NewBotDetector(BotDetectionConfig{BufferSize: 2})
+	var notified int
+	d.config.OnReport = func(BotDetectionReport) { notified++ }
+	for i := 0; i < 3; i++ {
+		d.record(BotDetectionReport{Time: time.Unix(int64(i), 0), Domain: "www.youtube.com", Signal: BotSignalForbidden, Attempt: i})
+	}
+
+	reports := d.Reports()
+	if len(reports) != 2 || reports[0].Attempt != 1 || reports[1].Attempt != 2 {
+		t.Errorf("reports = %+v, want the last two oldest first", reports)
+	}
+	if notified != 3 {
+		t.Errorf("OnReport called %d times, want 3", notified)
+	}
+	stats := d.Stats()
+	if stats.Total != 3 || stats.ByDomain["www.youtube.com"] != 3 || !stats.First.Equal(time.Unix(0, 0)) || !stats.Last.Equal(time.Unix(2, 0)) {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var doc struct {
+		Stats   BotDetectionStats
+		Reports []BotDetectionReport
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.Stats.Total != 3 || len(doc.Reports) != 2 {
+		t.Errorf("decoded = %+v", doc)
+	}
+
+	d.Reset()
+	if len(d.Reports()) != 0 || d.Stats().Total != 0 {
+		t.Error("Reset() kept reports or counters")
+	}
+
+	// Nil detectors are safe to use
+	var nilDetector *BotDetector
+	if nilDetector.Reports() != nil || nilDetector.Stats().Total != 0 {
+		t.Error("nil BotDetector returned data")
+	}
+}
@@ -3,8 +3,10 @@ package http
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
+	"ytsync/errcode"
 )
 
 // CircuitState represents the state of a circuit breaker.
@@ -44,7 +46,7 @@ const (
 )
 
 // ErrCircuitOpen is returned when the circuit breaker is open.
-var ErrCircuitOpen = errors.New("circuit breaker is open")
+var ErrCircuitOpen = errcode.New(errcode.Unavailable, "circuit breaker is open")
 
 // CircuitBreakerConfig configures circuit breaker behavior.
 type CircuitBreakerConfig struct {
@@ -61,6 +63,23 @@ type CircuitBreakerConfig struct {
 	// Transient errors increment the failure count; permanent errors don't affect the circuit.
 	// If nil, all errors are treated as transient.
 	IsTransientError func(error) bool
+	// HalfOpenProbeInterval is how long a half-open circuit waits for a probe
+	// result before allowing another round of probe requests. This keeps a
+	// circuit from staying half-open forever when a probe's outcome is never
+	// recorded. Default: 0 (probes are only allowed once per half-open period)
+	HalfOpenProbeInterval time.Duration
+	// ProbeBackoffMultiplier scales RecoveryTimeout after each failed probe,
+	// so a domain that keeps failing is probed less often. Values <= 1 keep
+	// the recovery timeout fixed. Default: 1
+	ProbeBackoffMultiplier float64
+	// MaxRecoveryTimeout caps the recovery timeout grown by
+	// ProbeBackoffMultiplier. Default: 10 * RecoveryTimeout
+	MaxRecoveryTimeout time.Duration
+	// OnStateChange, if set, is called after a circuit changes state, with
+	// the domain, the old and new states, and a human-readable reason.
+	// It is called without internal locks held, so it may call back into
+	// the circuit breaker; it should return quickly.
+	OnStateChange func(domain string, from, to CircuitState, reason string)
 }
 
 // DefaultCircuitBreakerConfig returns sensible defaults for circuit breaker configuration.
@@ -80,6 +99,16 @@ type circuitState struct {
 	lastError         time.Time
 	lastStateChange   time.Time
 	halfOpenRequests  int
+	lastProbe         time.Time
+	failedProbes      int
+	recoveryTimeout   time.Duration // open duration for the current open period
+}
+
+// stateChange is a pending OnStateChange notification.
+type stateChange struct {
+	domain   string
+	from, to CircuitState
+	reason   string
 }
 
 // CircuitBreaker implements the circuit breaker pattern for fault tolerance.
@@ -102,6 +131,9 @@ func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
 	if cfg.HalfOpenMaxRequests <= 0 {
 		cfg.HalfOpenMaxRequests = DefaultHalfOpenMaxRequests
 	}
+	if cfg.MaxRecoveryTimeout <= 0 {
+		cfg.MaxRecoveryTimeout = 10 * cfg.RecoveryTimeout
+	}
 
 	return &CircuitBreaker{
 		circuits: make(map[string]*circuitState),
@@ -116,6 +148,9 @@ func (cb *CircuitBreaker) Allow(domain string) error {
 		return nil
 	}
 
+	var change *stateChange
+	defer func() { cb.notify(change) }()
+
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
@@ -127,19 +162,24 @@ func (cb *CircuitBreaker) Allow(domain string) error {
 
 	case CircuitOpen:
 		// Check if recovery timeout has elapsed
-		if time.Since(circuit.lastStateChange) >= cb.config.RecoveryTimeout {
+		if time.Since(circuit.lastStateChange) >= circuit.recoveryTimeout {
 			// Transition to half-open and count this as the first test request
-			circuit.state = CircuitHalfOpen
-			circuit.lastStateChange = time.Now()
+			change = cb.transition(domain, circuit, CircuitHalfOpen, "recovery timeout elapsed, probing")
 			circuit.halfOpenRequests = 1 // This request counts as the first test
+			circuit.lastProbe = time.Now()
 			return nil
 		}
 		return ErrCircuitOpen
 
 	case CircuitHalfOpen:
+		// Replenish the probe budget if earlier probes never reported back
+		if cb.config.HalfOpenProbeInterval > 0 && time.Since(circuit.lastProbe) >= cb.config.HalfOpenProbeInterval {
+			circuit.halfOpenRequests = 0
+		}
 		// Allow limited requests in half-open state
 		if circuit.halfOpenRequests < cb.config.HalfOpenMaxRequests {
 			circuit.halfOpenRequests++
+			circuit.lastProbe = time.Now()
 			return nil
 		}
 		return ErrCircuitOpen
@@ -156,6 +196,9 @@ func (cb *CircuitBreaker) RecordSuccess(domain string) {
 		return
 	}
 
+	var change *stateChange
+	defer func() { cb.notify(change) }()
+
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
@@ -164,10 +207,11 @@ func (cb *CircuitBreaker) RecordSuccess(domain string) {
 	switch circuit.state {
 	case CircuitHalfOpen:
 		// Success in half-open state closes the circuit
-		circuit.state = CircuitClosed
-		circuit.lastStateChange = time.Now()
+		change = cb.transition(domain, circuit, CircuitClosed, "probe succeeded")
 		circuit.consecutiveErrors = 0
 		circuit.halfOpenRequests = 0
+		circuit.failedProbes = 0
+		circuit.recoveryTimeout = cb.config.RecoveryTimeout
 
 	case CircuitClosed:
 		// Reset consecutive errors on success
@@ -188,6 +232,9 @@ func (cb *CircuitBreaker) RecordFailure(domain string, err error) {
 		return
 	}
 
+	var change *stateChange
+	defer func() { cb.notify(change) }()
+
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
@@ -200,16 +247,56 @@ func (cb *CircuitBreaker) RecordFailure(domain string, err error) {
 
 		// Open the circuit if threshold reached
 		if circuit.consecutiveErrors >= cb.config.FailureThreshold {
-			circuit.state = CircuitOpen
-			circuit.lastStateChange = time.Now()
+			reason := fmt.Sprintf("%d consecutive failures, last: %v", circuit.consecutiveErrors, err)
+			change = cb.transition(domain, circuit, CircuitOpen, reason)
+			circuit.recoveryTimeout = cb.config.RecoveryTimeout
 		}
 
 	case CircuitHalfOpen:
-		// Failure in half-open state reopens the circuit
-		circuit.state = CircuitOpen
-		circuit.lastStateChange = time.Now()
+		// Failure in half-open state reopens the circuit, backing off the
+		// next probe if configured
 		circuit.consecutiveErrors++
+		circuit.lastError = time.Now()
+		circuit.failedProbes++
+		circuit.recoveryTimeout = cb.backoffTimeout(circuit.failedProbes)
+		change = cb.transition(domain, circuit, CircuitOpen, fmt.Sprintf("probe failed: %v", err))
+	}
+}
+
+// transition moves circuit to state and returns the notification to send
+// once the lock is released. Must be called with mutex held.
+func (cb *CircuitBreaker) transition(domain string, circuit *circuitState, to CircuitState, reason string) *stateChange {
+	from := circuit.state
+	circuit.state = to
+	circuit.lastStateChange = time.Now()
+	if from == to || cb.config.OnStateChange == nil {
+		return nil
+	}
+	return &stateChange{domain: domain, from: from, to: to, reason: reason}
+}
+
+// notify delivers a state change to OnStateChange. Must be called without
+// the mutex held.
+func (cb *CircuitBreaker) notify(change *stateChange) {
+	if change != nil && cb.config.OnStateChange != nil {
+		cb.config.OnStateChange(change.domain, change.from, change.to, change.reason)
+	}
+}
+
+// backoffTimeout returns the recovery timeout after failedProbes
+// consecutive failed probes.
+func (cb *CircuitBreaker) backoffTimeout(failedProbes int) time.Duration {
+	timeout := cb.config.RecoveryTimeout
+	if cb.config.ProbeBackoffMultiplier <= 1 {
+		return timeout
+	}
+	for i := 0; i < failedProbes && timeout < cb.config.MaxRecoveryTimeout; i++ {
+		timeout = time.Duration(float64(timeout) * cb.config.ProbeBackoffMultiplier)
+	}
+	if timeout > cb.config.MaxRecoveryTimeout {
+		timeout = cb.config.MaxRecoveryTimeout
 	}
+	return timeout
 }
 
 // GetState returns the current state of the circuit for a domain.
@@ -228,7 +315,7 @@ func (cb *CircuitBreaker) GetState(domain string) CircuitState {
 
 	// Check for automatic state transitions
 	if circuit.state == CircuitOpen {
-		if time.Since(circuit.lastStateChange) >= cb.config.RecoveryTimeout {
+		if time.Since(circuit.lastStateChange) >= circuit.recoveryTimeout {
 			return CircuitHalfOpen
 		}
 	}
@@ -249,19 +336,47 @@ func (cb *CircuitBreaker) GetStats(domain string) CircuitStats {
 	if !exists {
 		return CircuitStats{State: CircuitClosed}
 	}
+	return circuit.stats()
+}
+
+// AllStats returns the statistics of every domain's circuit, keyed by
+// domain. Domains that have not been requested, or were reset, are closed
+// and left out.
+func (cb *CircuitBreaker) AllStats() map[string]CircuitStats {
+	if cb == nil {
+		return nil
+	}
 
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	stats := make(map[string]CircuitStats, len(cb.circuits))
+	for domain, circuit := range cb.circuits {
+		stats[domain] = circuit.stats()
+	}
+	return stats
+}
+
+// stats returns the statistics of the circuit. The caller must hold the
+// breaker's lock.
+func (circuit *circuitState) stats() CircuitStats {
 	state := circuit.state
 	// Check for automatic state transitions
-	if state == CircuitOpen && time.Since(circuit.lastStateChange) >= cb.config.RecoveryTimeout {
+	if state == CircuitOpen && time.Since(circuit.lastStateChange) >= circuit.recoveryTimeout {
 		state = CircuitHalfOpen
 	}
 
-	return CircuitStats{
+	stats := CircuitStats{
 		State:             state,
 		ConsecutiveErrors: circuit.consecutiveErrors,
 		LastError:         circuit.lastError,
 		LastStateChange:   circuit.lastStateChange,
+		FailedProbes:      circuit.failedProbes,
+	}
+	if circuit.state == CircuitOpen {
+		stats.NextProbe = circuit.lastStateChange.Add(circuit.recoveryTimeout)
 	}
+	return stats
 }
 
 // CircuitStats contains statistics about a circuit's state.
@@ -270,6 +385,11 @@ type CircuitStats struct {
 	ConsecutiveErrors int
 	LastError         time.Time
 	LastStateChange   time.Time
+	// FailedProbes is the number of consecutive failed half-open probes.
+	FailedProbes int
+	// NextProbe is when an open circuit will allow a probe request. Zero
+	// unless the circuit is open. Schedulers can pause work until then.
+	NextProbe time.Time
 }
 
 // Reset resets the circuit for a domain to the closed state.
@@ -278,9 +398,15 @@ func (cb *CircuitBreaker) Reset(domain string) {
 		return
 	}
 
+	var change *stateChange
+	defer func() { cb.notify(change) }()
+
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	if circuit, exists := cb.circuits[domain]; exists {
+		change = cb.transition(domain, circuit, CircuitClosed, "reset")
+	}
 	delete(cb.circuits, domain)
 }
 
@@ -290,9 +416,21 @@ func (cb *CircuitBreaker) ResetAll() {
 		return
 	}
 
+	var changes []*stateChange
+	defer func() {
+		for _, change := range changes {
+			cb.notify(change)
+		}
+	}()
+
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	for domain, circuit := range cb.circuits {
+		if change := cb.transition(domain, circuit, CircuitClosed, "reset"); change != nil {
+			changes = append(changes, change)
+		}
+	}
 	cb.circuits = make(map[string]*circuitState)
 }
 
@@ -304,6 +442,7 @@ func (cb *CircuitBreaker) getOrCreateCircuit(domain string) *circuitState {
 		circuit = &circuitState{
 			state:           CircuitClosed,
 			lastStateChange: time.Now(),
+			recoveryTimeout: cb.config.RecoveryTimeout,
 		}
 		cb.circuits[domain] = circuit
 	}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -254,6 +255,29 @@ func TestCircuitBreakerPerDomainIsolation(t *testing.T) {
 	}
 }
 
+func TestCircuitBreakerAllStats(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold:    2,
+		RecoveryTimeout:     30 * time.Second,
+		HalfOpenMaxRequests: 1,
+	})
+	testErr := errors.New("test error")
+	cb.RecordFailure("domain1.com", testErr)
+	cb.RecordFailure("domain1.com", testErr)
+	cb.RecordFailure("domain2.com", testErr)
+
+	stats := cb.AllStats()
+	if len(stats) != 2 {
+		t.Fatalf("AllStats() = %v, want 2 domains", stats)
+	}
+	if s := stats["domain1.com"]; s.State != CircuitOpen || s.NextProbe.IsZero() {
+		t.Errorf("domain1 stats = %+v, want open with a next probe", s)
+	}
+	if s := stats["domain2.com"]; s.State != CircuitClosed || s.ConsecutiveErrors != 1 {
+		t.Errorf("domain2 stats = %+v, want closed after 1 error", s)
+	}
+}
+
 func TestCircuitBreakerReset(t *testing.T) {
 	cfg := CircuitBreakerConfig{
 		FailureThreshold:    2,
@@ -453,3 +477,117 @@ func TestDefaultCircuitBreakerConfig(t *testing.T) {
 		t.Errorf("HalfOpenMaxRequests = %d, want %d", cfg.HalfOpenMaxRequests, DefaultHalfOpenMaxRequests)
 	}
 }
+
+func TestCircuitBreakerOnStateChange(t *testing.T) {
+	type change struct {
+		domain   string
+		from, to CircuitState
+		reason   string
+	}
+	var changes []change
+	var cb *CircuitBreaker
+	cb = NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		RecoveryTimeout:  20 * time.Millisecond,
+		OnStateChange: func(domain string, from, to CircuitState, reason string) {
+			// Callbacks run without locks held, so querying the breaker must not deadlock
+			_ = cb.GetState(domain)
+			changes = append(changes, change{domain, from, to, reason})
+		},
+	})
+
+	testErr := errors.New("sign in to confirm you're not a bot")
+	cb.RecordFailure("youtube.com", testErr)
+	cb.RecordFailure("youtube.com", testErr)
+	time.Sleep(30 * time.Millisecond)
+	cb.Allow("youtube.com")
+	cb.RecordSuccess("youtube.com")
+	cb.RecordSuccess("youtube.com") // already closed, no callback
+
+	want := []struct{ from, to CircuitState }{
+		{CircuitClosed, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitClosed},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d state changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		if changes[i].domain != "youtube.com" || changes[i].from != w.from || changes[i].to != w.to {
+			t.Errorf("change %d = %+v, want %v -> %v", i, changes[i], w.from, w.to)
+		}
+	}
+	if !strings.Contains(changes[0].reason, "2 consecutive failures") || !strings.Contains(changes[0].reason, "not a bot") {
+		t.Errorf("open reason = %q, want failure count and last error", changes[0].reason)
+	}
+
+	// Reset notifies only for circuits that were not closed
+	cb.RecordFailure("youtube.com", testErr)
+	cb.RecordFailure("youtube.com", testErr)
+	changes = nil
+	cb.ResetAll()
+	if len(changes) != 1 || changes[0].to != CircuitClosed || changes[0].reason != "reset" {
+		t.Errorf("ResetAll() changes = %+v, want one reset to closed", changes)
+	}
+}
+
+func TestCircuitBreakerProbeBackoff(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold:       1,
+		RecoveryTimeout:        20 * time.Millisecond,
+		ProbeBackoffMultiplier: 3,
+		MaxRecoveryTimeout:     100 * time.Millisecond,
+	})
+	testErr := errors.New("test error")
+
+	cb.RecordFailure("example.com", testErr)
+	if next := cb.GetStats("example.com").NextProbe; next.IsZero() {
+		t.Fatal("NextProbe is zero for open circuit")
+	}
+
+	// A failed probe triples the wait before the next one
+	time.Sleep(25 * time.Millisecond)
+	if err := cb.Allow("example.com"); err != nil {
+		t.Fatalf("Allow() after recovery timeout error = %v", err)
+	}
+	cb.RecordFailure("example.com", testErr)
+
+	stats := cb.GetStats("example.com")
+	if stats.FailedProbes != 1 {
+		t.Errorf("FailedProbes = %d, want 1", stats.FailedProbes)
+	}
+	if wait := stats.NextProbe.Sub(stats.LastStateChange); wait != 60*time.Millisecond {
+		t.Errorf("next probe after %v, want 60ms", wait)
+	}
+	time.Sleep(25 * time.Millisecond)
+	if err := cb.Allow("example.com"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow() before backed-off timeout error = %v, want ErrCircuitOpen", err)
+	}
+
+	// Backoff is capped
+	if got := cb.backoffTimeout(10); got != 100*time.Millisecond {
+		t.Errorf("backoffTimeout(10) = %v, want 100ms cap", got)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbeInterval(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold:      1,
+		RecoveryTimeout:       10 * time.Millisecond,
+		HalfOpenProbeInterval: 20 * time.Millisecond,
+	})
+
+	cb.RecordFailure("example.com", errors.New("test error"))
+	time.Sleep(15 * time.Millisecond)
+	if err := cb.Allow("example.com"); err != nil {
+		t.Fatalf("first probe error = %v", err)
+	}
+	// The probe's result is never recorded
+	if err := cb.Allow("example.com"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second request error = %v, want ErrCircuitOpen while probe outstanding", err)
+	}
+	time.Sleep(25 * time.Millisecond)
+	if err := cb.Allow("example.com"); err != nil {
+		t.Errorf("probe after interval error = %v, want allowed", err)
+	}
+}
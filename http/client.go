@@ -3,15 +3,23 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
+	"net/url"
 	"time"
+	"ytsync/errcode"
 	"ytsync/retry"
+	"ytsync/tags"
 )
 
+// maxBotBodySize limits how much of a rate limit or bot-detection response
+// is read to classify it.
+const maxBotBodySize = 64 * 1024
+
 // Client wraps an HTTP client with retry logic and rate limit handling.
 type Client struct {
 	base           *http.Client
@@ -19,13 +27,27 @@ type Client struct {
 	rateLimiter    *RateLimiter
 	circuitBreaker *CircuitBreaker
 	session        *SessionManager
+	tracer         *Tracer
+	botDetector    *BotDetector
+	proxy          func(*http.Request) (*url.URL, error)
+	interceptors   interceptors
 }
 
 // Config holds HTTP client configuration including retry and rate limit settings.
 type Config struct {
-	// Timeout for individual HTTP requests
+	// Timeout for each attempt of an HTTP request, from dialing through
+	// reading the response body. OperationTimeouts overrides it for
+	// listing, transcript, and download requests.
 	Timeout time.Duration
 
+	// OperationTimeouts overrides Timeout by the kind of request (see
+	// OperationOf).
+	OperationTimeouts OperationTimeouts
+
+	// TotalTimeout, if positive, caps a whole request including retries,
+	// backoff, and rate limit waits.
+	TotalTimeout time.Duration
+
 	// Retry configuration
 	Retry retry.Config
 
@@ -38,15 +60,74 @@ type Config struct {
 	// Rate limiter configuration
 	RateLimiter RateLimiterConfig
 
+	// SharedRateLimiter, if set, is used instead of a limiter built from
+	// RateLimiter, so several Clients share one request budget and backoff
+	// state. Create it with NewRateLimiter.
+	SharedRateLimiter *RateLimiter
+
 	// Circuit breaker configuration
 	CircuitBreaker CircuitBreakerConfig
 
 	// Connection pool configuration
 	Transport TransportConfig
+
+	// RetryAfter bounds the delays servers request with Retry-After
+	RetryAfter RetryAfterConfig
+
+	// Request tracing configuration
+	Trace TraceConfig
+
+	// Bot-detection diagnostics configuration
+	BotDetection BotDetectionConfig
+
+	// RecordDir, if set, saves every response to a fixture file in this
+	// directory, keyed by a digest of the request. Intended for capturing
+	// Innertube and timedtext responses for offline tests and bug reports.
+	RecordDir string
+
+	// ReplayDir, if set, serves responses from fixtures recorded in this
+	// directory instead of the network. Requests without a fixture fail with
+	// ErrFixtureNotFound, unless RecordDir is also set, in which case they
+	// are fetched live and recorded.
+	ReplayDir string
+
+	// RoundTripper, if set, sends requests in place of the pooled network
+	// transport configured by Transport. Intended for tests; the
+	// ytsynctest package provides a scripted one.
+	RoundTripper http.RoundTripper
+
+	// Validation checks the bodies of successful responses, retrying those
+	// that are empty, cut short, or not well-formed JSON where JSON is
+	// expected.
+	Validation ResponseValidation
+
+	// RequestInterceptors and ResponseInterceptors are run, in order, on
+	// every request and successful response, for features layered on the
+	// client such as auth headers, tracing, or caching. Client.UseRequest
+	// and Client.UseResponse add more.
+	RequestInterceptors  []RequestInterceptor
+	ResponseInterceptors []ResponseInterceptor
 }
 
 // TransportConfig configures the HTTP transport (connection pooling).
 type TransportConfig struct {
+	// DialTimeout is the maximum time to establish a TCP connection.
+	// Default: 10 seconds
+	DialTimeout time.Duration
+
+	// KeepAlive is the interval between TCP keep-alive probes on open
+	// connections. Default: 30 seconds
+	KeepAlive time.Duration
+
+	// TLSHandshakeTimeout is the maximum time to complete a TLS handshake.
+	// Default: 10 seconds
+	TLSHandshakeTimeout time.Duration
+
+	// ResponseHeaderTimeout is the maximum time to wait for response
+	// headers after the request is sent; it does not limit reading the
+	// body. Default: 20 seconds
+	ResponseHeaderTimeout time.Duration
+
 	// MaxIdleConns is the maximum number of idle connections across all hosts.
 	// Default: 20
 	MaxIdleConns int
@@ -70,6 +151,31 @@ type TransportConfig struct {
 	// DisableKeepAlives disables HTTP keep-alives (connection reuse).
 	// Default: false (keep-alives enabled)
 	DisableKeepAlives bool
+
+	// DisableHTTP2 restricts connections to HTTP/1.1, overriding
+	// ForceAttemptHTTP2. Useful behind proxies that mishandle HTTP/2.
+	// Default: false
+	DisableHTTP2 bool
+
+	// HTTP2PingTimeout is how long an HTTP/2 connection may go without
+	// receiving a frame before a health-check ping is sent; a connection
+	// that does not answer the ping is closed. Zero disables health checks,
+	// so a stalled connection is only noticed when a request times out.
+	// Default: 30 seconds
+	HTTP2PingTimeout time.Duration
+
+	// TLSSessionCacheSize is the number of TLS sessions kept for
+	// resumption, which skips full handshakes on reconnect. Zero disables
+	// session resumption.
+	// Default: 64
+	TLSSessionCacheSize int
+
+	// IsolateDomains gives each site (youtube.com, googleapis.com,
+	// googlevideo.com, ...) its own transport and connection pool, so
+	// connections stuck on one site cannot use up the idle pool or
+	// MaxIdleConns budget of another. MaxIdleConns then applies per site.
+	// Default: false
+	IsolateDomains bool
 }
 
 // DefaultConfig returns sensible defaults for HTTP client configuration.
@@ -77,25 +183,44 @@ func DefaultConfig() *Config {
 	cbConfig := DefaultCircuitBreakerConfig()
 	cbConfig.IsTransientError = IsTransientHTTPError
 	return &Config{
-		Timeout:        30 * time.Second,
-		Retry:          retry.DefaultConfig(),
-		MaxConcurrent:  10,
-		UserAgent:      "ytsync/1.0",
-		RateLimiter:    DefaultRateLimiterConfig(),
-		CircuitBreaker: cbConfig,
-		Transport:      DefaultTransportConfig(),
+		Timeout:           30 * time.Second,
+		OperationTimeouts: DefaultOperationTimeouts(),
+		Retry:             retry.DefaultConfig(),
+		MaxConcurrent:     10,
+		UserAgent:         "ytsync/1.0",
+		RateLimiter:       DefaultRateLimiterConfig(),
+		CircuitBreaker:    cbConfig,
+		Transport:         DefaultTransportConfig(),
+		RetryAfter:        DefaultRetryAfterConfig(),
+		Trace:             DefaultTraceConfig(),
+		BotDetection:      DefaultBotDetectionConfig(),
+		Validation:        DefaultResponseValidation(),
+	}
+}
+
+// rateLimiter returns the shared rate limiter, or a new one built from RateLimiter.
+func (c *Config) rateLimiter() *RateLimiter {
+	if c.SharedRateLimiter != nil {
+		return c.SharedRateLimiter
 	}
+	return NewRateLimiter(c.RateLimiter)
 }
 
 // DefaultTransportConfig returns sensible defaults for HTTP transport configuration.
 func DefaultTransportConfig() TransportConfig {
 	return TransportConfig{
-		MaxIdleConns:        20,
-		MaxIdleConnsPerHost: 10,
-		MaxConnsPerHost:     20,
-		IdleConnTimeout:     90 * time.Second,
-		ForceAttemptHTTP2:   true,
-		DisableKeepAlives:   false,
+		DialTimeout:           10 * time.Second,
+		KeepAlive:             30 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 20 * time.Second,
+		MaxIdleConns:          20,
+		MaxIdleConnsPerHost:   10,
+		MaxConnsPerHost:       20,
+		IdleConnTimeout:       90 * time.Second,
+		ForceAttemptHTTP2:     true,
+		DisableKeepAlives:     false,
+		HTTP2PingTimeout:      30 * time.Second,
+		TLSSessionCacheSize:   64,
 	}
 }
 
@@ -105,32 +230,24 @@ func New(cfg *Config) *Client {
 		cfg = DefaultConfig()
 	}
 
-	// Configure transport with optimized settings for YouTube interactions
-	transport := &http.Transport{
-		// Connection pool settings
-		MaxIdleConns:        cfg.Transport.MaxIdleConns,
-		MaxIdleConnsPerHost: cfg.Transport.MaxIdleConnsPerHost,
-		MaxConnsPerHost:     cfg.Transport.MaxConnsPerHost,
-		IdleConnTimeout:     cfg.Transport.IdleConnTimeout,
-
-		// HTTP/2 support
-		ForceAttemptHTTP2: cfg.Transport.ForceAttemptHTTP2,
-
-		// TCP keepalive
-		DisableKeepAlives: cfg.Transport.DisableKeepAlives,
-	}
-
 	base := &http.Client{
 		Timeout:   cfg.Timeout,
-		Transport: transport,
+		Transport: cfg.wrapTransport(cfg.newRoundTripper()),
 	}
 
 	return &Client{
 		base:           base,
 		config:         cfg,
-		rateLimiter:    NewRateLimiter(cfg.RateLimiter),
+		rateLimiter:    cfg.rateLimiter(),
 		circuitBreaker: NewCircuitBreaker(cfg.CircuitBreaker),
 		session:        nil,
+		tracer:         NewTracer(cfg.Trace),
+		botDetector:    NewBotDetector(cfg.BotDetection),
+		proxy:          http.ProxyFromEnvironment,
+		interceptors: interceptors{
+			request:  append([]RequestInterceptor(nil), cfg.RequestInterceptors...),
+			response: append([]ResponseInterceptor(nil), cfg.ResponseInterceptors...),
+		},
 	}
 }
 
@@ -150,56 +267,147 @@ func (c *Client) Get(ctx context.Context, url string) (*Response, error) {
 // It automatically retries on transient failures and detects rate limiting.
 // The circuit breaker pattern is used to fail fast when a domain is unresponsive.
 func (c *Client) Do(ctx context.Context, method, urlStr string, body io.Reader, headers map[string]string) (*Response, error) {
+	resp, _, err := c.DoDetailed(ctx, method, urlStr, body, headers)
+	return resp, err
+}
+
+// bodyLimitKey is the context key of WithBodyLimit.
+type bodyLimitKey struct{}
+
+// WithBodyLimit returns a context whose requests read at most n bytes of
+// each response body and drop the rest, so an unexpectedly large page
+// cannot exhaust memory. Zero or less means no limit.
+func WithBodyLimit(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, bodyLimitKey{}, n)
+}
+
+// limitBody returns r limited to the body limit ctx carries, if any.
+func limitBody(ctx context.Context, r io.Reader) io.Reader {
+	if n, ok := ctx.Value(bodyLimitKey{}).(int64); ok && n > 0 {
+		return io.LimitReader(r, n)
+	}
+	return r
+}
+
+// DoDetailed performs an HTTP request like Do and also returns a report of
+// every attempt made: status codes, classifier decisions, and time spent
+// waiting on the rate limiter and retry backoff. The report is returned
+// even when err is non-nil. If ctx carries tags, they are recorded in the
+// report and the trace, and a returned error is wrapped in a *tags.Error.
+func (c *Client) DoDetailed(ctx context.Context, method, urlStr string, body io.Reader, headers map[string]string) (_ *Response, report *AttemptReport, err error) {
+	report = &AttemptReport{Tags: tags.From(ctx)}
+	start := time.Now()
+	defer func() {
+		report.Elapsed = time.Since(start)
+		err = tags.Wrap(ctx, err)
+	}()
+
+	if c.config.TotalTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.TotalTimeout)
+		defer cancel()
+	}
+
+	// Build the request headers once; interceptors may change the request
+	// or answer it themselves
+	tmpl, err := c.newRequest(ctx, method, urlStr, headers)
+	if err != nil {
+		return nil, report, err
+	}
+	if resp, err := c.interceptRequest(tmpl); resp != nil || err != nil {
+		return resp, report, err
+	}
+	urlStr = tmpl.URL.String()
+
 	// Extract domain for circuit breaker
 	domain := c.rateLimiter.extractDomain(urlStr)
 
+	// Record the request if tracing is enabled
+	trace := c.tracer.begin(ctx, method, urlStr, domain)
+	defer func() { c.tracer.finish(trace, err) }()
+
 	// Check circuit breaker first - fail fast if circuit is open
 	if err := c.circuitBreaker.Allow(domain); err != nil {
-		return nil, err
+		return nil, report, err
 	}
 
 	// Wait for any backoff period from previous rate limit errors
+	waitStart := time.Now()
 	if err := c.rateLimiter.WaitForBackoff(ctx, urlStr); err != nil {
 		c.circuitBreaker.RecordFailure(domain, err)
-		return nil, err
+		return nil, report, err
 	}
 
 	// Wait for rate limit before attempting request
 	if err := c.rateLimiter.Wait(ctx, urlStr); err != nil {
 		c.circuitBreaker.RecordFailure(domain, err)
-		return nil, err
+		return nil, report, err
+	}
+	report.RateLimitWait = time.Since(waitStart)
+	if trace != nil {
+		trace.RateLimitWait = report.RateLimitWait
 	}
 
-	var lastResp *http.Response
+	// Buffer the request body so that every attempt sends all of it
+	var reqBody []byte
+	if body != nil {
+		if reqBody, err = io.ReadAll(body); err != nil {
+			return nil, report, fmt.Errorf("read request body: %w", err)
+		}
+	}
 
-	err := retry.Do(ctx, c.config.Retry, c.isRetryableHTTPError, func(ctx context.Context) error {
-		req, err := http.NewRequestWithContext(ctx, method, urlStr, body)
+	var lastResp *http.Response
+	var lastBody []byte
+	var lastReq *http.Request
+	var statuses []int
+
+	attemptFn := func(ctx context.Context) (attemptErr error) {
+		statuses = append(statuses, 0)
+		var attemptBody io.Reader
+		if reqBody != nil {
+			attemptBody = bytes.NewReader(reqBody)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, urlStr, attemptBody)
 		if err != nil {
 			return err
 		}
-
-		// Set default user agent
-		if req.Header.Get("User-Agent") == "" {
-			req.Header.Set("User-Agent", c.config.UserAgent)
+		req.Header = tmpl.Header.Clone()
+		req.Host = tmpl.Host
+
+		var attempt *TraceAttempt
+		if trace != nil {
+			trace.Attempts = append(trace.Attempts, TraceAttempt{
+				StartedAt:      time.Now(),
+				RequestHeaders: redactHeaders(req.Header),
+			})
+			attempt = &trace.Attempts[len(trace.Attempts)-1]
+			defer func() {
+				if attemptErr != nil {
+					attempt.Error = attemptErr.Error()
+				}
+			}()
 		}
 
-		// Apply custom headers
-		for k, v := range headers {
-			req.Header.Set(k, v)
+		// The per-operation timeout replaces the base client's
+		base := *c.base
+		base.Timeout = c.config.timeoutFor(urlStr)
+		resp, err := base.Do(req)
+		if attempt != nil {
+			attempt.Duration = time.Since(attempt.StartedAt)
 		}
-
-		// Apply session headers if available
-		if c.session != nil {
-			for k, v := range c.session.GetHeaders() {
-				if req.Header.Get(k) == "" { // Don't override explicitly set headers
-					req.Header.Set(k, v)
-				}
-			}
+		if err == nil {
+			statuses[len(statuses)-1] = resp.StatusCode
 		}
-
-		resp, err := c.base.Do(req)
 		if err != nil {
-			return fmt.Errorf("http request failed: %w", err)
+			code := errcode.Of(err)
+			if code != errcode.Timeout && code != errcode.Canceled && !errors.Is(err, ErrFixtureNotFound) {
+				code = errcode.Unavailable
+			}
+			return errcode.Wrap(code, "http request failed", err)
+		}
+		if attempt != nil {
+			attempt.StatusCode = resp.StatusCode
+			attempt.ResponseHeaders = redactHeaders(resp.Header)
 		}
 
 		// Check for rate limiting (429) or anti-bot detection (403)
@@ -209,67 +417,138 @@ func (c *Client) Do(ctx context.Context, method, urlStr string, body io.Reader,
 			defer resp.Body.Close()
 
 			// Parse Retry-After header
-			retryAfter := c.parseRetryAfter(resp.Header)
+			serverRetryAfter := c.parseRetryAfter(resp.Header)
 
 			// Record rate limit error and get recommended backoff
-			recommendedBackoff := c.rateLimiter.RecordRateLimitError(urlStr, retryAfter)
-			if recommendedBackoff > retryAfter {
-				retryAfter = recommendedBackoff
+			retryAfter := c.rateLimiter.RecordRateLimitError(urlStr, serverRetryAfter)
+			if serverRetryAfter > retryAfter {
+				retryAfter = serverRetryAfter
 			}
 
-			isBotDetection := resp.StatusCode == http.StatusForbidden
+			// Classify the page: a 429 may be a CAPTCHA, a 403 a sign-in wall
+			bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, maxBotBodySize))
+			signal, evidence := ClassifyBotResponse(resp.StatusCode, responseURL(resp), bodyBytes)
+			isBotDetection := signal != ""
+			if isBotDetection {
+				c.botDetector.record(c.botReport(req, resp, bodyBytes, signal, evidence, len(statuses)))
+			}
+			if trace != nil {
+				trace.BotDetection = trace.BotDetection || isBotDetection
+				if c.tracer.captureBodies() {
+					attempt.ResponseBody = truncateBody(bodyBytes, c.tracer.maxBodySize())
+				}
+			}
 			return &RateLimitError{
-				StatusCode:     resp.StatusCode,
-				RetryAfter:     retryAfter,
-				IsBotDetection: isBotDetection,
+				StatusCode:       resp.StatusCode,
+				RetryAfter:       retryAfter,
+				ServerRetryAfter: serverRetryAfter,
+				IsBotDetection:   isBotDetection,
 			}
 		}
 
 		// Non-2xx status codes
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			defer resp.Body.Close()
-			bodyBytes, _ := io.ReadAll(resp.Body)
+			bodyBytes, _ := io.ReadAll(limitBody(ctx, resp.Body))
+			if c.tracer.captureBodies() {
+				attempt.ResponseBody = truncateBody(bodyBytes, c.tracer.maxBodySize())
+			}
 			return &HTTPError{
 				StatusCode: resp.StatusCode,
 				Body:       bodyBytes,
 			}
 		}
 
+		// Read the body here so that one cut short is retried
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(limitBody(ctx, resp.Body))
+		if attempt != nil && c.tracer.captureBodies() {
+			attempt.ResponseBody = truncateBody(respBody, c.tracer.maxBodySize())
+		}
+		if err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return &InvalidResponseError{
+					StatusCode: resp.StatusCode,
+					Size:       len(respBody),
+					Err:        fmt.Errorf("%w: %v", ErrTruncatedResponse, err),
+				}
+			}
+			return errcode.Wrap(errcode.Unavailable, "read response body", err)
+		}
+		if err := c.config.Validation.check(req, &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}); err != nil {
+			return err
+		}
+
 		lastResp = resp
+		lastBody = respBody
+		lastReq = req
 		return nil
+	}
+
+	_, retryReport, err := retry.DoWithResult(ctx, c.config.Retry, c.isRetryableHTTPError, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, attemptFn(ctx)
 	})
+	report.addRetryReport(retryReport, statuses)
 
 	if err != nil {
-		if lastResp != nil {
-			lastResp.Body.Close()
-		}
 		// Record failure to circuit breaker
 		c.circuitBreaker.RecordFailure(domain, err)
-		return nil, err
+		return nil, report, err
 	}
 
 	if lastResp == nil {
-		err := fmt.Errorf("no response received")
-		c.circuitBreaker.RecordFailure(domain, err)
-		return nil, err
+		c.circuitBreaker.RecordFailure(domain, ErrNoResponse)
+		return nil, report, ErrNoResponse
 	}
 
-	defer lastResp.Body.Close()
-	respBody, err := io.ReadAll(lastResp.Body)
-	if err != nil {
-		c.circuitBreaker.RecordFailure(domain, err)
-		return nil, fmt.Errorf("read response body: %w", err)
+	// A redirect to a consent or CAPTCHA page succeeds but is still a block
+	if signal, evidence := ClassifyBotResponse(lastResp.StatusCode, responseURL(lastResp), nil); signal != "" {
+		c.botDetector.record(c.botReport(lastReq, lastResp, lastBody, signal, evidence, len(statuses)))
+		if trace != nil {
+			trace.BotDetection = true
+		}
 	}
 
 	// Record successful request to help recover from backoff and circuit breaker
 	c.rateLimiter.RecordSuccess(urlStr)
 	c.circuitBreaker.RecordSuccess(domain)
 
-	return &Response{
+	resp := &Response{
 		StatusCode: lastResp.StatusCode,
 		Header:     lastResp.Header,
-		Body:       respBody,
-	}, nil
+		Body:       lastBody,
+	}
+	if err := c.interceptResponse(lastReq, resp); err != nil {
+		return nil, report, err
+	}
+	return resp, report, nil
+}
+
+// newRequest builds a request with the client's default headers, headers,
+// and any session headers, for interceptors to see and attempts to copy.
+func (c *Client) newRequest(ctx context.Context, method, urlStr string, headers map[string]string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set default user agent
+	req.Header.Set("User-Agent", c.config.UserAgent)
+
+	// Apply custom headers
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	// Apply session headers if available
+	if c.session != nil {
+		for k, v := range c.session.GetHeaders() {
+			if req.Header.Get(k) == "" { // Don't override explicitly set headers
+				req.Header.Set(k, v)
+			}
+		}
+	}
+	return req, nil
 }
 
 // isRetryableHTTPError determines if an HTTP error is retryable.
@@ -292,25 +571,10 @@ func (c *Client) isRetryableHTTPError(err error) bool {
 	return true
 }
 
-// parseRetryAfter extracts the Retry-After header value.
-// Returns the number of seconds to wait, or 0 if not present.
+// parseRetryAfter returns the Retry-After delay of a response bounded by
+// Config.RetryAfter, or 0 if not present.
 func (c *Client) parseRetryAfter(header http.Header) time.Duration {
-	retryAfter := header.Get("Retry-After")
-	if retryAfter == "" {
-		return 0
-	}
-
-	// Try parsing as seconds (integer)
-	if seconds, err := strconv.Atoi(retryAfter); err == nil {
-		return time.Duration(seconds) * time.Second
-	}
-
-	// Try parsing as HTTP date
-	if t, err := http.ParseTime(retryAfter); err == nil {
-		return time.Until(t)
-	}
-
-	return 0
+	return c.config.RetryAfter.FromHeader(header)
 }
 
 // Close closes the HTTP client connections and releases all resources.
@@ -321,6 +585,27 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// RateLimiter returns the client's rate limiter. Pass it as
+// Config.SharedRateLimiter to make another Client share this one's budget.
+func (c *Client) RateLimiter() *RateLimiter {
+	return c.rateLimiter
+}
+
+// CircuitBreaker returns the client's per-domain circuit breaker.
+func (c *Client) CircuitBreaker() *CircuitBreaker {
+	return c.circuitBreaker
+}
+
+// BotDetector returns the client's bot-detection diagnostics.
+func (c *Client) BotDetector() *BotDetector {
+	return c.botDetector
+}
+
+// Tracer returns the client's request tracer, or nil if tracing is disabled.
+func (c *Client) Tracer() *Tracer {
+	return c.tracer
+}
+
 // GetTransportConfig returns the transport configuration being used.
 func (c *Client) GetTransportConfig() TransportConfig {
 	return c.config.Transport
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"ytsync/errcode"
 )
 
 func TestNewClient(t *testing.T) {
@@ -53,6 +54,32 @@ func TestClientGetSuccess(t *testing.T) {
 	}
 }
 
+func TestClientBodyLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 1000)))
+	}))
+	defer server.Close()
+
+	client := New(DefaultConfig())
+	defer client.Close()
+
+	resp, err := client.Get(WithBodyLimit(context.Background(), 100), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Body) != 100 {
+		t.Errorf("limited body is %d bytes, want 100", len(resp.Body))
+	}
+
+	resp, err = client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Body) != 1000 {
+		t.Errorf("unlimited body is %d bytes, want 1000", len(resp.Body))
+	}
+}
+
 func TestClientDoWithHeaders(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -268,3 +295,110 @@ func TestHTTPError(t *testing.T) {
 		t.Errorf("expected '404' in message, got: %s", msg)
 	}
 }
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want errcode.Code
+	}{
+		{"rate limit", &RateLimitError{StatusCode: 429}, errcode.RateLimited},
+		{"bot detection", &RateLimitError{StatusCode: 403, IsBotDetection: true}, errcode.BotDetected},
+		{"not found", &HTTPError{StatusCode: 404}, errcode.NotFound},
+		{"server error", &HTTPError{StatusCode: 502}, errcode.Unavailable},
+		{"bad request", &HTTPError{StatusCode: 400}, errcode.InvalidInput},
+		{"circuit open", ErrCircuitOpen, errcode.Unavailable},
+	}
+	for _, tt := range tests {
+		if got := errcode.Of(tt.err); got != tt.want {
+			t.Errorf("%s: errcode.Of() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClient_TransportErrorCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Retry.MaxRetries = 0
+	client := New(cfg)
+	defer client.Close()
+
+	_, err := client.Get(context.Background(), url)
+	if err == nil {
+		t.Fatal("expected error for closed server")
+	}
+	if got := errcode.Of(err); got != errcode.Unavailable {
+		t.Errorf("errcode.Of() = %q, want %q (err: %v)", got, errcode.Unavailable, err)
+	}
+	if !strings.Contains(err.Error(), "http request failed") {
+		t.Errorf("error = %q, want it to mention the failed request", err)
+	}
+}
+
+func TestClient_DoDetailed(t *testing.T) {
+	attempt := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		if attempt < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Retry.InitialBackoff = 10 * time.Millisecond
+	cfg.Retry.MaxBackoff = 10 * time.Millisecond
+	cfg.Retry.JitterFraction = 0
+	client := New(cfg)
+	defer client.Close()
+
+	resp, report, err := client.DoDetailed(context.Background(), http.MethodGet, server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(resp.Body) != "ok" {
+		t.Errorf("body = %q, want %q", resp.Body, "ok")
+	}
+	if report.Count() != 3 || report.Retries() != 2 {
+		t.Fatalf("Count() = %d, Retries() = %d, want 3 and 2", report.Count(), report.Retries())
+	}
+	wantStatuses := []int{502, 502, 200}
+	for i, a := range report.Attempts {
+		if a.StatusCode != wantStatuses[i] {
+			t.Errorf("attempt %d status = %d, want %d", a.Number, a.StatusCode, wantStatuses[i])
+		}
+	}
+	if !report.Attempts[0].Retryable || report.Attempts[2].Retryable {
+		t.Error("expected failed attempts to be marked retryable and the final one not")
+	}
+	if report.BackoffWait != 20*time.Millisecond {
+		t.Errorf("BackoffWait = %v, want 20ms", report.BackoffWait)
+	}
+	if report.LastStatus() != 200 {
+		t.Errorf("LastStatus() = %d, want 200", report.LastStatus())
+	}
+}
+
+func TestClient_DoDetailedPermanentError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := New(DefaultConfig())
+	defer client.Close()
+
+	_, report, err := client.DoDetailed(context.Background(), http.MethodGet, server.URL, nil, nil)
+	if err == nil {
+		t.Fatal("expected error for 404")
+	}
+	if report.Count() != 1 || report.LastStatus() != 404 || report.Attempts[0].Retryable {
+		t.Errorf("report = %+v, want one non-retryable 404 attempt", report.Attempts)
+	}
+}
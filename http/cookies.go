@@ -0,0 +1,167 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"ytsync/proc"
+)
+
+// httpOnlyPrefix marks HttpOnly cookies in Netscape cookie files written by
+// browser extensions and curl.
+const httpOnlyPrefix = "#HttpOnly_"
+
+// ParseNetscapeCookies parses cookies in the Netscape cookies.txt format
+// used by curl, wget, yt-dlp, and browser export extensions. Each line holds
+// seven tab-separated fields: domain, include-subdomains flag, path, secure
+// flag, expiry (Unix seconds, 0 for a session cookie), name, and value.
+func ParseNetscapeCookies(r io.Reader) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+		if httpOnly {
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) == 6 {
+			// Some exporters drop the value of empty cookies entirely
+			fields = append(fields, "")
+		}
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("cookies line %d: want 7 tab-separated fields, got %d", lineNo, len(fields))
+		}
+
+		expiry, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cookies line %d: invalid expiry %q", lineNo, fields[4])
+		}
+
+		cookie := &http.Cookie{
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		if strings.EqualFold(fields[1], "TRUE") {
+			if !strings.HasPrefix(cookie.Domain, ".") {
+				cookie.Domain = "." + cookie.Domain
+			}
+		} else {
+			// Host-only cookie: the jar must not send it to subdomains
+			cookie.Domain = strings.TrimPrefix(cookie.Domain, ".")
+		}
+		if expiry > 0 {
+			cookie.Expires = time.Unix(expiry, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read cookies: %w", err)
+	}
+	return cookies, nil
+}
+
+// ImportCookies adds cookies to the session's jar. Each cookie is set for
+// its own Domain, so cookies for youtube.com, google.com, and googleapis.com
+// can be imported together. Expired cookies are skipped. It returns the
+// number of cookies imported.
+func (sm *SessionManager) ImportCookies(cookies []*http.Cookie) int {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	now := time.Now()
+	byHost := make(map[string][]*http.Cookie)
+	for _, c := range cookies {
+		if c.Domain == "" || (!c.Expires.IsZero() && c.Expires.Before(now)) {
+			continue
+		}
+		host := strings.TrimPrefix(c.Domain, ".")
+		imported := *c
+		if !strings.HasPrefix(c.Domain, ".") {
+			// cookiejar treats a cookie with a Domain attribute as a domain
+			// cookie; clearing it keeps host-only cookies host-only
+			imported.Domain = ""
+		}
+		byHost[host] = append(byHost[host], &imported)
+	}
+
+	count := 0
+	for host, hostCookies := range byHost {
+		sm.jar.SetCookies(&url.URL{Scheme: "https", Host: host, Path: "/"}, hostCookies)
+		count += len(hostCookies)
+	}
+	return count
+}
+
+// ImportCookiesFile imports cookies from a Netscape cookies.txt file, such
+// as one exported from a logged-in browser, and returns how many were
+// imported.
+func (sm *SessionManager) ImportCookiesFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("open cookies file: %w", err)
+	}
+	defer f.Close()
+
+	cookies, err := ParseNetscapeCookies(f)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return sm.ImportCookies(cookies), nil
+}
+
+// ImportBrowserCookies reads cookies directly from a browser profile and
+// imports them. browser takes yt-dlp's --cookies-from-browser syntax:
+// BROWSER[+KEYRING][:PROFILE][::CONTAINER], e.g. "firefox", "chrome:Profile 1",
+// or "chromium+gnomekeyring". Browser cookie databases are encrypted
+// differently on every platform, so extraction is delegated to yt-dlp
+// (SessionConfig.YtdlpPath), which exports them to a temporary cookies.txt.
+func (sm *SessionManager) ImportBrowserCookies(ctx context.Context, browser string) (int, error) {
+	if browser == "" {
+		return 0, errors.New("import browser cookies: no browser given")
+	}
+	ytdlp := sm.config.YtdlpPath
+	if ytdlp == "" {
+		ytdlp = "yt-dlp"
+	}
+
+	dir, err := os.MkdirTemp("", "ytsync-cookies-")
+	if err != nil {
+		return 0, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "cookies.txt")
+
+	// Without a URL yt-dlp exits with a usage error, but only after loading
+	// the browser's cookies and saving them to --cookies
+	cmd := proc.Command(ctx, ytdlp, "--ignore-config", "--cookies-from-browser", browser, "--cookies", out)
+	output, runErr := cmd.CombinedOutput()
+	if _, err := os.Stat(out); err != nil {
+		if runErr == nil {
+			runErr = errors.New("no cookies written")
+		}
+		return 0, fmt.Errorf("extract %s cookies with %s: %w: %s", browser, ytdlp, runErr, strings.TrimSpace(string(output)))
+	}
+	return sm.ImportCookiesFile(out)
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNetscapeCookies(t *testing.T) {
+	future := time.Now().Add(time.Hour).Unix()
+	input := fmt.Sprintf(`# Netscape HTTP Cookie File
+# This is a generated file!  Do not edit.
+
+.youtube.com	TRUE	/	TRUE	%d	LOGIN_INFO	abc
+#HttpOnly_.youtube.com	TRUE	/	TRUE	%d	SID	def
+www.youtube.com	FALSE	/feed	FALSE	0	PREF	f1=1
+`, future, future)
+
+	cookies, err := ParseNetscapeCookies(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseNetscapeCookies() error = %v", err)
+	}
+	if len(cookies) != 3 {
+		t.Fatalf("parsed %d cookies, want 3", len(cookies))
+	}
+
+	login := cookies[0]
+	if login.Name != "LOGIN_INFO" || login.Value != "abc" || login.Domain != ".youtube.com" || !login.Secure || login.Expires.Unix() != future {
+		t.Errorf("LOGIN_INFO = %+v", login)
+	}
+	if !cookies[1].HttpOnly || cookies[1].Name != "SID" {
+		t.Errorf("SID = %+v, want HttpOnly", cookies[1])
+	}
+	pref := cookies[2]
+	if pref.Domain != "www.youtube.com" || pref.Path != "/feed" || !pref.Expires.IsZero() || pref.Value != "f1=1" {
+		t.Errorf("PREF = %+v, want host-only session cookie", pref)
+	}
+
+	if _, err := ParseNetscapeCookies(strings.NewReader("youtube.com\tTRUE\t/\n")); err == nil {
+		t.Error("ParseNetscapeCookies() malformed line error = nil")
+	}
+}
+
+func TestSessionManagerImportCookiesFile(t *testing.T) {
+	var gotCookie string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCookie = r.Header.Get("Cookie")
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	host = host[:strings.Index(host, ":")]
+
+	future := time.Now().Add(time.Hour).Unix()
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	content := fmt.Sprintf(".youtube.com\tTRUE\t/\tTRUE\t%d\tLOGIN_INFO\tabc\n"+
+		".youtube.com\tTRUE\t/\tFALSE\t1\tEXPIRED\tx\n"+
+		"%s\tFALSE\t/\tFALSE\t0\tSESSION\tlocal\n", future, host)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultSessionConfig()
+	cfg.ImportCookiesFrom = path
+	sm, err := NewSessionManager(cfg)
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+
+	yt, _ := url.Parse("https://m.youtube.com/watch")
+	if got := sm.jar.Cookies(yt); len(got) != 1 || got[0].Name != "LOGIN_INFO" {
+		t.Errorf("youtube.com cookies = %v, want LOGIN_INFO only", got)
+	}
+
+	cfgHTTP := DefaultConfig()
+	cfgHTTP.Retry.MaxRetries = 0
+	if _, err := sm.GetClient(cfgHTTP).Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if gotCookie != "SESSION=local" {
+		t.Errorf("Cookie header = %q, want SESSION=local", gotCookie)
+	}
+
+	cfg.ImportCookiesFrom = filepath.Join(t.TempDir(), "missing.txt")
+	if _, err := NewSessionManager(cfg); err == nil {
+		t.Error("NewSessionManager() with missing cookies file error = nil")
+	}
+}
+
+func TestSessionManagerImportBrowserCookies(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mock yt-dlp requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	argsLog := filepath.Join(dir, "args")
+	script := `echo "$@" > "` + argsLog + `"
+while [ $# -gt 0 ]; do
+  if [ "$1" = "--cookies" ]; then out="$2"; fi
+  shift
+done
+printf '.youtube.com\tTRUE\t/\tTRUE\t0\tSID\tfrom-browser\n' > "$out"
+echo "yt-dlp: error: You must provide at least one URL." >&2
+exit 2
+`
+	ytdlp := filepath.Join(dir, "yt-dlp")
+	if err := os.WriteFile(ytdlp, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultSessionConfig()
+	cfg.YtdlpPath = ytdlp
+	sm, err := NewSessionManager(cfg)
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+
+	n, err := sm.ImportBrowserCookies(context.Background(), "firefox:default-release")
+	if err != nil {
+		t.Fatalf("ImportBrowserCookies() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("ImportBrowserCookies() imported %d cookies, want 1", n)
+	}
+	args, _ := os.ReadFile(argsLog)
+	if !strings.Contains(string(args), "--cookies-from-browser firefox:default-release") {
+		t.Errorf("yt-dlp args = %q", args)
+	}
+
+	// A failure that writes no cookies is reported with yt-dlp's output
+	os.WriteFile(ytdlp, []byte("#!/bin/sh\necho 'could not find firefox cookies database' >&2\nexit 1\n"), 0755)
+	if _, err := sm.ImportBrowserCookies(context.Background(), "firefox"); err == nil || !strings.Contains(err.Error(), "could not find") {
+		t.Errorf("ImportBrowserCookies() error = %v, want yt-dlp output", err)
+	}
+}
@@ -2,7 +2,9 @@ package http
 
 import (
 	"fmt"
+	"net/http"
 	"time"
+	"ytsync/errcode"
 )
 
 // RateLimitError indicates the server rate limited the request.
@@ -10,8 +12,12 @@ import (
 type RateLimitError struct {
 	// StatusCode is the HTTP status code (429, 403, or 503)
 	StatusCode int
-	// RetryAfter indicates how long to wait before retrying
+	// RetryAfter indicates how long to wait before retrying: the longer of
+	// ServerRetryAfter and the rate limiter's backoff
 	RetryAfter time.Duration
+	// ServerRetryAfter is the server's Retry-After delay, bounded by
+	// Config.RetryAfter, or 0 if the response had none
+	ServerRetryAfter time.Duration
 	// IsBotDetection indicates this may be anti-bot protection (403)
 	IsBotDetection bool
 }
@@ -27,6 +33,14 @@ func (e *RateLimitError) Error() string {
 	return fmt.Sprintf("rate limited (status %d)", e.StatusCode)
 }
 
+// ErrorCode classifies the error as errcode.BotDetected or errcode.RateLimited.
+func (e *RateLimitError) ErrorCode() errcode.Code {
+	if e.IsBotDetection {
+		return errcode.BotDetected
+	}
+	return errcode.RateLimited
+}
+
 // HTTPError indicates an HTTP error response.
 type HTTPError struct {
 	// StatusCode is the HTTP status code
@@ -40,11 +54,27 @@ func (e *HTTPError) Error() string {
 	return fmt.Sprintf("http error: status %d", e.StatusCode)
 }
 
+// ErrorCode classifies the error by status code.
+func (e *HTTPError) ErrorCode() errcode.Code {
+	switch {
+	case e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone:
+		return errcode.NotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return errcode.RateLimited
+	case e.StatusCode == http.StatusBadRequest:
+		return errcode.InvalidInput
+	case e.StatusCode >= 500:
+		return errcode.Unavailable
+	default:
+		return errcode.Unknown
+	}
+}
+
 // Sentinel errors for HTTP operations.
 var (
 	// ErrNoResponse indicates no response was received from the server.
-	ErrNoResponse = fmt.Errorf("no response received")
+	ErrNoResponse = errcode.New(errcode.Unavailable, "no response received")
 
 	// ErrRequestFailed indicates the request itself failed (network error).
-	ErrRequestFailed = fmt.Errorf("http request failed")
+	ErrRequestFailed = errcode.New(errcode.Unavailable, "http request failed")
 )
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+	"ytsync/errcode"
+)
+
+// ErrFixtureNotFound is returned in replay mode when no fixture has been
+// recorded for a request and recording is disabled.
+var ErrFixtureNotFound = errcode.New(errcode.NotFound, "no recorded fixture for request")
+
+// Fixture is a recorded request and response, stored as one JSON file per
+// request under Config.RecordDir and read back from Config.ReplayDir.
+type Fixture struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header,omitempty"`
+	// Body is the response body. Non-UTF-8 bodies are base64 encoded and
+	// BodyBase64 is set.
+	Body       string `json:"body"`
+	BodyBase64 bool   `json:"body_base64,omitempty"`
+}
+
+// FixtureKey returns the file name a request is recorded under: the host
+// followed by a digest of the method, URL, and body. Requests that differ
+// only in headers share a fixture.
+func FixtureKey(method, url string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, method)
+	h.Write([]byte{0})
+	io.WriteString(h, url)
+	h.Write([]byte{0})
+	h.Write(body)
+	digest := hex.EncodeToString(h.Sum(nil))[:32]
+
+	host := "request"
+	if i := strings.Index(url, "://"); i >= 0 {
+		host = url[i+3:]
+		if j := strings.IndexAny(host, "/?#"); j >= 0 {
+			host = host[:j]
+		}
+		host = strings.NewReplacer(":", "_", "/", "_").Replace(host)
+	}
+	return host + "_" + digest + ".json"
+}
+
+// fixtureTransport replays responses from replayDir and records live
+// responses to recordDir. With both set, recorded fixtures are served and
+// missing ones are fetched and recorded.
+type fixtureTransport struct {
+	base      http.RoundTripper
+	recordDir string
+	replayDir string
+}
+
+// wrapTransport wraps base with fixture recording and replay if
+// RecordDir or ReplayDir is set. RoundTripper, if set, replaces base.
+func (c *Config) wrapTransport(base http.RoundTripper) http.RoundTripper {
+	if c.RoundTripper != nil {
+		base = c.RoundTripper
+	}
+	if c.RecordDir == "" && c.ReplayDir == "" {
+		return base
+	}
+	return &fixtureTransport{base: base, recordDir: c.RecordDir, replayDir: c.ReplayDir}
+}
+
+// RoundTrip serves req from a fixture or forwards it to the base transport.
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	key := FixtureKey(req.Method, req.URL.String(), reqBody)
+
+	if t.replayDir != "" {
+		fixture, err := readFixture(filepath.Join(t.replayDir, key))
+		if err == nil {
+			return fixture.response(req)
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if t.recordDir == "" {
+			return nil, fmt.Errorf("%w: %s %s", ErrFixtureNotFound, req.Method, req.URL)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || t.recordDir == "" {
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fixture := &Fixture{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		RequestBody: string(reqBody),
+		StatusCode:  resp.StatusCode,
+		Header:      redactHeaders(resp.Header),
+		Body:        string(respBody),
+	}
+	if !utf8.Valid(respBody) {
+		fixture.Body = base64.StdEncoding.EncodeToString(respBody)
+		fixture.BodyBase64 = true
+	}
+	if err := writeFixture(filepath.Join(t.recordDir, key), fixture); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// response builds an *http.Response for req from the fixture.
+func (f *Fixture) response(req *http.Request) (*http.Response, error) {
+	body := []byte(f.Body)
+	if f.BodyBase64 {
+		var err error
+		body, err = base64.StdEncoding.DecodeString(f.Body)
+		if err != nil {
+			return nil, fmt.Errorf("decode fixture body for %s: %w", f.URL, err)
+		}
+	}
+	header := f.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.StatusCode, http.StatusText(f.StatusCode)),
+		StatusCode:    f.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func readFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("parse fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+func writeFixture(path string, fixture *Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create fixture dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write fixture: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"ytsync/errcode"
+)
+
+func TestFixtureRecordAndReplay(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "secret=1")
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`{"kind":"browse"}`))
+			return
+		}
+		w.Write([]byte{0xff, 0xfe, 0x00})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	cfg := DefaultConfig()
+	cfg.RecordDir = dir
+	recorder := New(cfg)
+	if _, err := recorder.Do(ctx, http.MethodPost, server.URL+"/youtubei/v1/browse", strings.NewReader(`{"browseId":"UC1"}`), nil); err != nil {
+		t.Fatalf("record POST: %v", err)
+	}
+	if _, err := recorder.Get(ctx, server.URL+"/binary"); err != nil {
+		t.Fatalf("record GET: %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("recorded %d fixtures, want 2", len(entries))
+	}
+	data, _ := os.ReadFile(dir + "/" + FixtureKey(http.MethodPost, server.URL+"/youtubei/v1/browse", []byte(`{"browseId":"UC1"}`)))
+	if strings.Contains(string(data), "secret=1") {
+		t.Error("fixture contains unredacted Set-Cookie header")
+	}
+
+	server.Close()
+	cfg = DefaultConfig()
+	cfg.ReplayDir = dir
+	replayer := New(cfg)
+
+	resp, err := replayer.Do(ctx, http.MethodPost, server.URL+"/youtubei/v1/browse", strings.NewReader(`{"browseId":"UC1"}`), nil)
+	if err != nil {
+		t.Fatalf("replay POST: %v", err)
+	}
+	if string(resp.Body) != `{"kind":"browse"}` || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("replayed response = %q %v", resp.Body, resp.Header)
+	}
+	resp, err = replayer.Get(ctx, server.URL+"/binary")
+	if err != nil {
+		t.Fatalf("replay GET: %v", err)
+	}
+	if string(resp.Body) != "\xff\xfe\x00" {
+		t.Errorf("replayed binary body = %q", resp.Body)
+	}
+	if hits != 2 {
+		t.Errorf("server hits = %d, want 2", hits)
+	}
+
+	// A different request body has no fixture
+	_, err = replayer.Do(ctx, http.MethodPost, server.URL+"/youtubei/v1/browse", strings.NewReader(`{"browseId":"UC2"}`), nil)
+	if !errors.Is(err, ErrFixtureNotFound) {
+		t.Fatalf("replay unknown request error = %v, want ErrFixtureNotFound", err)
+	}
+	if !errcode.Is(err, errcode.NotFound) {
+		t.Errorf("error code = %v, want %v", errcode.Of(err), errcode.NotFound)
+	}
+}
+
+func TestFixtureReplayFallsBackToRecord(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte("live"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.RecordDir = dir
+	cfg.ReplayDir = dir
+	client := New(cfg)
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if string(resp.Body) != "live" {
+			t.Errorf("body = %q, want live", resp.Body)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("server hits = %d, want 1 (later requests replayed)", hits)
+	}
+}
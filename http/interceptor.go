@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+)
+
+// RequestInterceptor is called with each request before the client sends
+// it, to add headers such as auth tokens, consent cookies, or trace IDs, or
+// to rewrite the URL. Changes apply to every attempt of the request.
+//
+// Returning a non-nil Response answers the request without sending it, as
+// a cache would: no rate limiting, circuit breaker, or retries apply, and
+// later interceptors are skipped. Returning an error fails the request
+// with that error.
+type RequestInterceptor func(req *http.Request) (*Response, error)
+
+// ResponseInterceptor is called with each successful response and the
+// request that produced it, to observe it or change it in place. Returning
+// an error fails the request with that error. Responses answered by a
+// RequestInterceptor are not passed to response interceptors.
+type ResponseInterceptor func(req *http.Request, resp *Response) error
+
+// interceptors is a client's chain of request and response interceptors,
+// run in the order they were added.
+type interceptors struct {
+	mu       sync.RWMutex
+	request  []RequestInterceptor
+	response []ResponseInterceptor
+}
+
+// UseRequest appends fn to the client's request interceptors, after those
+// from Config.RequestInterceptors. It affects requests started afterwards.
+func (c *Client) UseRequest(fn RequestInterceptor) {
+	c.interceptors.mu.Lock()
+	defer c.interceptors.mu.Unlock()
+	c.interceptors.request = append(c.interceptors.request, fn)
+}
+
+// UseResponse appends fn to the client's response interceptors, after
+// those from Config.ResponseInterceptors. It affects requests started
+// afterwards.
+func (c *Client) UseResponse(fn ResponseInterceptor) {
+	c.interceptors.mu.Lock()
+	defer c.interceptors.mu.Unlock()
+	c.interceptors.response = append(c.interceptors.response, fn)
+}
+
+// interceptRequest runs the request interceptors on req, stopping at the
+// first that answers or fails it.
+func (c *Client) interceptRequest(req *http.Request) (*Response, error) {
+	c.interceptors.mu.RLock()
+	chain := c.interceptors.request
+	c.interceptors.mu.RUnlock()
+	for _, fn := range chain {
+		if resp, err := fn(req); resp != nil || err != nil {
+			return resp, err
+		}
+	}
+	return nil, nil
+}
+
+// interceptResponse runs the response interceptors on resp, stopping at
+// the first error.
+func (c *Client) interceptResponse(req *http.Request, resp *Response) error {
+	c.interceptors.mu.RLock()
+	chain := c.interceptors.response
+	c.interceptors.mu.RUnlock()
+	for _, fn := range chain {
+		if err := fn(req, resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientInterceptors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q, want the injected token", got)
+		}
+		if got := r.Header.Get("X-Trace-Id"); got != "trace-1" {
+			t.Errorf("X-Trace-Id = %q, want trace-1", got)
+		}
+		if n == 1 {
+			// The injected headers must survive the retry
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("fresh"))
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Retry.InitialBackoff = time.Millisecond
+	cfg.Retry.MaxBackoff = time.Millisecond
+	cfg.RequestInterceptors = []RequestInterceptor{func(req *http.Request) (*Response, error) {
+		req.Header.Set("Authorization", "Bearer token")
+		return nil, nil
+	}}
+	client := New(cfg)
+	defer client.Close()
+
+	cached := map[string][]byte{server.URL + "/cached": []byte("from cache")}
+	client.UseRequest(func(req *http.Request) (*Response, error) {
+		req.Header.Set("X-Trace-Id", "trace-1")
+		if body, ok := cached[req.URL.String()]; ok {
+			return &Response{StatusCode: http.StatusOK, Body: body}, nil
+		}
+		return nil, nil
+	})
+	var observed []string
+	client.UseResponse(func(req *http.Request, resp *Response) error {
+		observed = append(observed, req.URL.Path)
+		resp.Body = append(resp.Body, '!')
+		return nil
+	})
+
+	resp, err := client.Get(context.Background(), server.URL+"/live")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(resp.Body) != "fresh!" || requests.Load() != 2 {
+		t.Errorf("Get() = %q after %d requests, want fresh! after a retry", resp.Body, requests.Load())
+	}
+
+	resp, err = client.Get(context.Background(), server.URL+"/cached")
+	if err != nil || string(resp.Body) != "from cache" {
+		t.Errorf("Get(cached) = %v, %v; want the intercepted response", resp, err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("the short-circuited request reached the server")
+	}
+	if len(observed) != 1 || observed[0] != "/live" {
+		t.Errorf("response interceptor saw %q, want only /live", observed)
+	}
+
+	errDenied := errors.New("denied")
+	client.UseResponse(func(req *http.Request, resp *Response) error { return errDenied })
+	if _, err := client.Get(context.Background(), server.URL+"/live"); !errors.Is(err, errDenied) {
+		t.Errorf("Get() error = %v, want the response interceptor's error", err)
+	}
+}
+
+func TestClientRequestInterceptorError(t *testing.T) {
+	cfg := DefaultConfig()
+	errNoToken := errors.New("no token")
+	cfg.RequestInterceptors = []RequestInterceptor{func(req *http.Request) (*Response, error) {
+		return nil, errNoToken
+	}}
+	client := New(cfg)
+	defer client.Close()
+
+	if _, err := client.Get(context.Background(), "http://127.0.0.1:1/"); !errors.Is(err, errNoToken) {
+		t.Errorf("Get() error = %v, want the interceptor's error", err)
+	}
+}
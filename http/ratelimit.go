@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,8 +14,14 @@ import (
 
 // RateLimiter manages per-domain request rate limiting using token bucket algorithm.
 // It supports configurable rates for different endpoints and dynamic rate adjustment.
+//
+// A RateLimiter is safe for concurrent use and can be shared by several
+// Clients (see Config.SharedRateLimiter) so their combined traffic stays
+// within the same budget.
 type RateLimiter struct {
 	limiters     map[string]*rate.Limiter
+	classes      map[string]*rate.Limiter // keyed by domain + "/" + class
+	global       *rate.Limiter
 	backoffState map[string]*BackoffState
 	mu           sync.RWMutex
 	config       RateLimiterConfig
@@ -59,10 +66,67 @@ type RateLimiterConfig struct {
 	RSSRPS float64
 	// CustomRates maps domain patterns to RPS values
 	CustomRates map[string]float64
+	// Burst is how many requests to a domain may be sent back-to-back
+	// before its rate applies (default: 1)
+	Burst int
+	// CustomBursts maps domains to burst sizes, overriding Burst
+	CustomBursts map[string]int
+	// GlobalRPS caps the combined request rate across all YouTube-owned
+	// domains (youtube.com, googleapis.com, ytimg.com, ...), on top of the
+	// per-domain rates (0 = no aggregate cap)
+	GlobalRPS float64
+	// GlobalBurst is the burst size for the aggregate limiter (default: 1)
+	GlobalBurst int
+	// ClassRates gives path classes of youtube.com requests (see
+	// PathClassOf) their own rate, on top of the domain's rate, so one kind
+	// of request such as transcript fetches cannot use up the budget that
+	// browse pagination needs. The domain rate still caps the classes'
+	// combined traffic. Classes without a rate are limited only by the
+	// domain rate.
+	ClassRates map[PathClass]float64
 	// EnableDynamicBackoff enables automatic rate reduction on errors
 	EnableDynamicBackoff bool
 }
 
+// PathClass groups requests to a domain by the kind of endpoint they hit.
+type PathClass string
+
+const (
+	// PathClassNone is a request that belongs to no class: any request
+	// outside youtube.com.
+	PathClassNone PathClass = ""
+	// PathClassBrowse is an Innertube browse request (channel listings).
+	PathClassBrowse PathClass = "browse"
+	// PathClassNext is an Innertube next request (watch page data).
+	PathClassNext PathClass = "next"
+	// PathClassPlayer is an Innertube player request.
+	PathClassPlayer PathClass = "player"
+	// PathClassTimedText is a caption track or transcript request.
+	PathClassTimedText PathClass = "timedtext"
+	// PathClassFeed is an RSS feed request.
+	PathClassFeed PathClass = "feed"
+	// PathClassHTML is any other youtube.com request, such as a watch or
+	// channel page.
+	PathClassHTML PathClass = "html"
+)
+
+// PathClassOf classifies a request URL by the same endpoint table as
+// OperationOf. Only youtube.com requests are classified.
+func PathClassOf(urlStr string) PathClass {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return PathClassNone
+	}
+	host := strings.ToLower(u.Hostname())
+	if host != "youtube.com" && !strings.HasSuffix(host, ".youtube.com") {
+		return PathClassNone
+	}
+	if class := endpointOf(u.Path).class; class != PathClassNone {
+		return class
+	}
+	return PathClassHTML
+}
+
 // DefaultRateLimiterConfig returns sensible defaults aligned with YouTube's rate limits.
 func DefaultRateLimiterConfig() RateLimiterConfig {
 	return RateLimiterConfig{
@@ -71,6 +135,9 @@ func DefaultRateLimiterConfig() RateLimiterConfig {
 		RSSRPS:               10.0, // RSS is generous with rate limits
 		CustomRates:          make(map[string]float64),
 		EnableDynamicBackoff: true,
+		// Bulk transcript fetches leave at least 1 req/s of the
+		// youtube.com budget for listings and everything else
+		ClassRates: map[PathClass]float64{PathClassTimedText: 1.5},
 	}
 }
 
@@ -88,12 +155,23 @@ func NewRateLimiter(cfg RateLimiterConfig) *RateLimiter {
 	if cfg.CustomRates == nil {
 		cfg.CustomRates = make(map[string]float64)
 	}
+	if cfg.CustomBursts == nil {
+		cfg.CustomBursts = make(map[string]int)
+	}
+	if cfg.ClassRates == nil {
+		cfg.ClassRates = make(map[PathClass]float64)
+	}
 
-	return &RateLimiter{
+	rl := &RateLimiter{
 		limiters:     make(map[string]*rate.Limiter),
+		classes:      make(map[string]*rate.Limiter),
 		backoffState: make(map[string]*BackoffState),
 		config:       cfg,
 	}
+	if cfg.GlobalRPS > 0 {
+		rl.global = rate.NewLimiter(rate.Limit(cfg.GlobalRPS), max(cfg.GlobalBurst, 1))
+	}
+	return rl
 }
 
 // Wait waits until the rate limit allows a request for the given URL.
@@ -103,12 +181,29 @@ func (rl *RateLimiter) Wait(ctx context.Context, urlStr string) error {
 		return nil
 	}
 
-	limiter := rl.getLimiter(urlStr)
-	if limiter == nil {
-		// No rate limiting for this domain
-		return nil
+	// The class limit applies first, so requests of a busy class queue
+	// behind each other rather than ahead of other classes for the domain
+	if limiter := rl.getClassLimiter(urlStr); limiter != nil {
+		if err := waitLimiter(ctx, limiter); err != nil {
+			return err
+		}
+	}
+	if limiter := rl.getLimiter(urlStr); limiter != nil {
+		if err := waitLimiter(ctx, limiter); err != nil {
+			return err
+		}
+	}
+
+	// The aggregate cap applies after the domain's own limit, so a request
+	// holds at most one global token while it is actually about to be sent
+	if rl.global != nil && IsYouTubeDomain(rl.extractDomain(urlStr)) {
+		return waitLimiter(ctx, rl.global)
 	}
+	return nil
+}
 
+// waitLimiter waits until limiter allows one request.
+func waitLimiter(ctx context.Context, limiter *rate.Limiter) error {
 	if !limiter.Allow() {
 		// Calculate wait time and use reservation for accurate timing
 		reservation := limiter.Reserve()
@@ -147,12 +242,69 @@ func (rl *RateLimiter) getLimiter(urlStr string) *rate.Limiter {
 		return limiter
 	}
 
-	// Create new limiter with token bucket: tokens=1 (burst of 1), rate=rps
-	limiter := rate.NewLimiter(rate.Limit(rps), 1)
+	// Create new limiter with token bucket: tokens=burst, rate=rps
+	limiter := rate.NewLimiter(rate.Limit(rps), rl.getBurst(domain))
 	rl.limiters[domain] = limiter
 	return limiter
 }
 
+// getClassLimiter returns the path class rate limiter for a given URL,
+// creating one if necessary, or nil if its class has no rate.
+func (rl *RateLimiter) getClassLimiter(urlStr string) *rate.Limiter {
+	class := PathClassOf(urlStr)
+	if class == PathClassNone {
+		return nil
+	}
+	domain := rl.extractDomain(urlStr)
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rps := rl.config.ClassRates[class]
+	if rps <= 0 {
+		return nil
+	}
+	key := domain + "/" + string(class)
+	if limiter, ok := rl.classes[key]; ok {
+		return limiter
+	}
+	limiter := rate.NewLimiter(rate.Limit(rps), max(rl.config.Burst, 1))
+	rl.classes[key] = limiter
+	return limiter
+}
+
+// getBurst returns the token bucket size for a given domain.
+// Must be called with mutex held.
+func (rl *RateLimiter) getBurst(domain string) int {
+	if burst, ok := rl.config.CustomBursts[domain]; ok && burst > 0 {
+		return burst
+	}
+	return max(rl.config.Burst, 1)
+}
+
+// youtubeDomains are the registrable domains owned by YouTube. Requests to
+// these and their subdomains count against RateLimiterConfig.GlobalRPS.
+var youtubeDomains = []string{
+	"youtube.com",
+	"youtu.be",
+	"youtube-nocookie.com",
+	"googleapis.com",
+	"googlevideo.com",
+	"ytimg.com",
+	"ggpht.com",
+}
+
+// IsYouTubeDomain reports whether host belongs to a YouTube-owned domain.
+func IsYouTubeDomain(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, d := range youtubeDomains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
 // getRPS returns the requests per second for a given domain.
 func (rl *RateLimiter) getRPS(domain string) float64 {
 	// Check custom rates first
@@ -209,6 +361,26 @@ func indexAny(s, chars string) int {
 	return -1
 }
 
+// SetCustomBurst sets a custom burst size for a specific domain.
+func (rl *RateLimiter) SetCustomBurst(domain string, burst int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.config.CustomBursts[domain] = burst
+
+	// Clear existing limiter to force recreation with new burst
+	delete(rl.limiters, domain)
+}
+
+// GlobalRate returns the aggregate rate cap across YouTube-owned domains,
+// or 0 if there is none.
+func (rl *RateLimiter) GlobalRate() float64 {
+	if rl == nil || rl.global == nil {
+		return 0
+	}
+	return float64(rl.global.Limit())
+}
+
 // SetCustomRate sets a custom rate limit for a specific domain.
 func (rl *RateLimiter) SetCustomRate(domain string, rps float64) {
 	rl.mu.Lock()
@@ -220,8 +392,39 @@ func (rl *RateLimiter) SetCustomRate(domain string, rps float64) {
 	delete(rl.limiters, domain)
 }
 
-// Stats returns statistics about the rate limiters.
-// Useful for monitoring and debugging.
+// SetClassRate sets the rate limit for a path class; 0 removes it, leaving
+// the class limited only by its domain's rate.
+func (rl *RateLimiter) SetClassRate(class PathClass, rps float64) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.config.ClassRates[class] = rps
+
+	// Clear existing limiters to force recreation with the new rate
+	for key := range rl.classes {
+		if strings.HasSuffix(key, "/"+string(class)) {
+			delete(rl.classes, key)
+		}
+	}
+}
+
+// CurrentRate returns the request rate currently allowed for urlStr's
+// domain, in requests per second, including any reduction from backoff.
+// Returns 0 if the domain is unlimited.
+func (rl *RateLimiter) CurrentRate(urlStr string) float64 {
+	if rl == nil {
+		return 0
+	}
+	limiter := rl.getLimiter(urlStr)
+	if limiter == nil {
+		return 0
+	}
+	return float64(limiter.Limit())
+}
+
+// Stats returns the configured rate of each rate limiter in use, keyed by
+// domain, and by domain and path class (e.g. "www.youtube.com/browse") for
+// class limiters. Useful for monitoring and debugging.
 func (rl *RateLimiter) Stats() map[string]float64 {
 	rl.mu.RLock()
 	defer rl.mu.RUnlock()
@@ -230,6 +433,10 @@ func (rl *RateLimiter) Stats() map[string]float64 {
 	for domain := range rl.limiters {
 		stats[domain] = rl.getRPS(domain)
 	}
+	for key := range rl.classes {
+		class := PathClass(key[strings.LastIndex(key, "/")+1:])
+		stats[key] = rl.config.ClassRates[class]
+	}
 	return stats
 }
 
@@ -383,6 +590,23 @@ func (rl *RateLimiter) GetBackoffState(urlStr string) *BackoffState {
 	return nil
 }
 
+// BackoffStates returns a copy of the backoff state of every domain that
+// has hit a rate limit error, keyed by domain.
+func (rl *RateLimiter) BackoffStates() map[string]BackoffState {
+	if rl == nil {
+		return nil
+	}
+
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	states := make(map[string]BackoffState, len(rl.backoffState))
+	for domain, state := range rl.backoffState {
+		states[domain] = *state
+	}
+	return states
+}
+
 // IsBackedOff returns true if the domain is currently in a backoff state.
 func (rl *RateLimiter) IsBackedOff(urlStr string) bool {
 	state := rl.GetBackoffState(urlStr)
@@ -260,6 +260,11 @@ func TestRateLimiterBackoffState(t *testing.T) {
 	if state.OriginalRPS == 0 {
 		t.Error("OriginalRPS should be set")
 	}
+
+	states := rl.BackoffStates()
+	if len(states) != 1 || states["www.youtube.com"].ConsecutiveErrors != 1 {
+		t.Errorf("BackoffStates() = %+v, want one www.youtube.com entry", states)
+	}
 }
 
 func TestRateLimiterRecordSuccess(t *testing.T) {
@@ -370,3 +375,210 @@ func TestBackoffStateConstants(t *testing.T) {
 		t.Errorf("MinRPSMultiplier = %v, want 0.25", MinRPSMultiplier)
 	}
 }
+
+func TestRateLimiterBurst(t *testing.T) {
+	cfg := RateLimiterConfig{
+		InnertubeRPS: 1.0,
+		Burst:        3,
+		CustomBursts: map[string]int{"api.example.com": 5},
+		CustomRates:  map[string]float64{"api.example.com": 1.0},
+	}
+	rl := NewRateLimiter(cfg)
+	ctx := context.Background()
+
+	// A full bucket lets burst requests through without waiting
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := rl.Wait(ctx, "https://www.youtube.com/api/test"); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		if err := rl.Wait(ctx, "https://api.example.com/test"); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("burst requests took %v, want no waiting", elapsed)
+	}
+
+	// The next request must wait for a token
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := rl.Wait(waitCtx, "https://www.youtube.com/api/test"); err == nil {
+		t.Error("Wait after exhausting burst succeeded immediately, want wait")
+	}
+}
+
+func TestRateLimiterGlobalCap(t *testing.T) {
+	cfg := RateLimiterConfig{
+		InnertubeRPS: 100,
+		DataAPIRPS:   100,
+		RSSRPS:       100,
+		CustomRates:  map[string]float64{"api.example.com": 100},
+		GlobalRPS:    1,
+		GlobalBurst:  2,
+	}
+	rl := NewRateLimiter(cfg)
+	ctx := context.Background()
+
+	if got := rl.GlobalRate(); got != 1 {
+		t.Errorf("GlobalRate() = %v, want 1", got)
+	}
+
+	// Two different YouTube domains use up the shared global burst
+	for _, u := range []string{"https://www.youtube.com/youtubei/v1/browse", "https://www.googleapis.com/youtube/v3/videos"} {
+		if err := rl.Wait(ctx, u); err != nil {
+			t.Fatalf("Wait(%s) failed: %v", u, err)
+		}
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := rl.Wait(waitCtx, "https://feeds.youtube.com/feeds/videos.xml"); err == nil {
+		t.Error("Wait on a third YouTube domain succeeded immediately, want global cap to apply")
+	}
+
+	// Non-YouTube domains are not counted against the global cap
+	if err := rl.Wait(waitCtx, "https://api.example.com/test"); err != nil {
+		t.Errorf("Wait on non-YouTube domain failed: %v", err)
+	}
+}
+
+func TestRateLimiterNoGlobalCap(t *testing.T) {
+	rl := NewRateLimiter(DefaultRateLimiterConfig())
+	if got := rl.GlobalRate(); got != 0 {
+		t.Errorf("GlobalRate() = %v, want 0 by default", got)
+	}
+}
+
+func TestIsYouTubeDomain(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"www.youtube.com", true},
+		{"youtube.com", true},
+		{"m.youtube.com", true},
+		{"youtu.be", true},
+		{"www.googleapis.com", true},
+		{"rr3---sn-abc.googlevideo.com", true},
+		{"i.ytimg.com", true},
+		{"WWW.YOUTUBE.COM", true},
+		{"notyoutube.com", false},
+		{"example.com", false},
+		{"sponsor.ajay.app", false},
+	}
+	for _, tt := range tests {
+		if got := IsYouTubeDomain(tt.host); got != tt.want {
+			t.Errorf("IsYouTubeDomain(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	shared := NewRateLimiter(RateLimiterConfig{InnertubeRPS: 1})
+
+	cfg := DefaultConfig()
+	cfg.SharedRateLimiter = shared
+	a := New(cfg)
+	b := New(cfg)
+
+	if a.RateLimiter() != shared || b.RateLimiter() != shared {
+		t.Fatal("clients did not use the shared rate limiter")
+	}
+
+	// A token taken through one client is gone for the other
+	ctx := context.Background()
+	if err := a.RateLimiter().Wait(ctx, "https://www.youtube.com/"); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := b.RateLimiter().Wait(waitCtx, "https://www.youtube.com/"); err == nil {
+		t.Error("second client was not limited by the shared budget")
+	}
+
+	if New(DefaultConfig()).RateLimiter() == shared {
+		t.Error("client without SharedRateLimiter used the shared limiter")
+	}
+}
+
+func TestPathClassOf(t *testing.T) {
+	tests := []struct {
+		url  string
+		want PathClass
+	}{
+		{"https://www.youtube.com/youtubei/v1/browse?prettyPrint=false", PathClassBrowse},
+		{"https://www.youtube.com/youtubei/v1/next", PathClassNext},
+		{"https://www.youtube.com/youtubei/v1/player", PathClassPlayer},
+		{"https://www.youtube.com/api/timedtext?v=abc&lang=en", PathClassTimedText},
+		{"https://www.youtube.com/youtubei/v1/get_transcript", PathClassTimedText},
+		{"https://www.youtube.com/feeds/videos.xml?channel_id=UC123", PathClassFeed},
+		{"https://www.youtube.com/@handle/videos", PathClassHTML},
+		{"https://m.youtube.com/watch?v=abc", PathClassHTML},
+		{"https://www.googleapis.com/youtube/v3/videos", PathClassNone},
+		{"https://example.com/youtubei/v1/browse", PathClassNone},
+	}
+	for _, tt := range tests {
+		if got := PathClassOf(tt.url); got != tt.want {
+			t.Errorf("PathClassOf(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiterPathClasses(t *testing.T) {
+	rl := NewRateLimiter(RateLimiterConfig{
+		InnertubeRPS: 100,
+		ClassRates:   map[PathClass]float64{PathClassTimedText: 1},
+	})
+	ctx := context.Background()
+	timedtext := "https://www.youtube.com/api/timedtext?v=abc"
+
+	if err := rl.Wait(ctx, timedtext); err != nil {
+		t.Fatalf("Wait(timedtext) failed: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := rl.Wait(waitCtx, timedtext); err == nil {
+		t.Error("second timedtext request succeeded immediately, want class limit to apply")
+	}
+
+	// Browse requests on the same domain are not held up by transcript fetches
+	for i := 0; i < 3; i++ {
+		start := time.Now()
+		if err := rl.Wait(ctx, "https://www.youtube.com/youtubei/v1/browse"); err != nil {
+			t.Fatalf("Wait(browse) failed: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("browse request waited %v behind the timedtext class", elapsed)
+		}
+	}
+
+	stats := rl.Stats()
+	if stats["www.youtube.com/timedtext"] != 1 || stats["www.youtube.com"] != 100 {
+		t.Errorf("Stats() = %v, want class and domain rates", stats)
+	}
+
+	rl.SetClassRate(PathClassTimedText, 0)
+	if err := rl.Wait(ctx, timedtext); err != nil {
+		t.Errorf("Wait(timedtext) after removing the class rate failed: %v", err)
+	}
+}
+
+func TestRateLimiterPathClasses_DomainCap(t *testing.T) {
+	rl := NewRateLimiter(RateLimiterConfig{
+		InnertubeRPS: 1,
+		ClassRates:   map[PathClass]float64{PathClassBrowse: 100, PathClassTimedText: 100},
+	})
+	ctx := context.Background()
+
+	if err := rl.Wait(ctx, "https://www.youtube.com/youtubei/v1/browse"); err != nil {
+		t.Fatalf("Wait(browse) failed: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := rl.Wait(waitCtx, "https://www.youtube.com/api/timedtext"); err == nil {
+		t.Error("timedtext request succeeded immediately, want the domain rate to cap all classes")
+	}
+}
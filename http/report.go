@@ -0,0 +1,75 @@
+package http
+
+import (
+	"time"
+	"ytsync/retry"
+	"ytsync/tags"
+)
+
+// RequestAttempt describes one attempt of a request made by DoDetailed.
+type RequestAttempt struct {
+	retry.Attempt
+	// StatusCode is the HTTP status of the response, or 0 if no response was received.
+	StatusCode int
+}
+
+// AttemptReport summarizes the attempts made for a single request.
+type AttemptReport struct {
+	// Attempts lists every attempt in order.
+	Attempts []RequestAttempt
+	// RateLimitWait is the time spent waiting on the rate limiter before the first attempt.
+	RateLimitWait time.Duration
+	// BackoffWait is the time spent sleeping between retries.
+	BackoffWait time.Duration
+	// Elapsed is the total time the request took, including waits.
+	Elapsed time.Duration
+	// Tags are the tags of the request's context.
+	Tags tags.Tags
+}
+
+// Count returns the number of attempts made.
+func (r *AttemptReport) Count() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.Attempts)
+}
+
+// Retries returns the number of attempts after the first.
+func (r *AttemptReport) Retries() int {
+	if n := r.Count(); n > 1 {
+		return n - 1
+	}
+	return 0
+}
+
+// TotalWait returns the time spent waiting on the rate limiter and retry backoff.
+func (r *AttemptReport) TotalWait() time.Duration {
+	if r == nil {
+		return 0
+	}
+	return r.RateLimitWait + r.BackoffWait
+}
+
+// LastStatus returns the status code of the final attempt, or 0 if none.
+func (r *AttemptReport) LastStatus() int {
+	if r.Count() == 0 {
+		return 0
+	}
+	return r.Attempts[len(r.Attempts)-1].StatusCode
+}
+
+// addRetryReport merges a retry report and the per-attempt status codes.
+func (r *AttemptReport) addRetryReport(report *retry.Report, statuses []int) {
+	if report == nil {
+		return
+	}
+	r.BackoffWait += report.TotalWait
+	for i, attempt := range report.Attempts {
+		ra := RequestAttempt{Attempt: attempt}
+		if i < len(statuses) {
+			ra.StatusCode = statuses[i]
+		}
+		r.Attempts = append(r.Attempts, ra)
+	}
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetryAfterMax is the default cap on Retry-After delays.
+const DefaultRetryAfterMax = 10 * time.Minute
+
+// RetryAfterConfig bounds the delays servers request with the Retry-After
+// header, so a misconfigured or hostile server cannot stall a sync for
+// hours, and a zero delay cannot cause a tight retry loop.
+type RetryAfterConfig struct {
+	// Min is the least delay used when a Retry-After header is present,
+	// including one with a date already past (default: 0).
+	Min time.Duration
+	// Max caps the delay (0 = no cap; default: DefaultRetryAfterMax).
+	Max time.Duration
+}
+
+// DefaultRetryAfterConfig returns the default Retry-After bounds.
+func DefaultRetryAfterConfig() RetryAfterConfig {
+	return RetryAfterConfig{Max: DefaultRetryAfterMax}
+}
+
+// Clamp bounds d to [Min, Max].
+func (c RetryAfterConfig) Clamp(d time.Duration) time.Duration {
+	if d < c.Min {
+		d = c.Min
+	}
+	if c.Max > 0 && d > c.Max {
+		d = c.Max
+	}
+	return d
+}
+
+// FromHeader returns the bounded Retry-After delay of a response, or 0 if
+// the header is absent or malformed.
+func (c RetryAfterConfig) FromHeader(header http.Header) time.Duration {
+	d, ok := ParseRetryAfter(header, time.Now())
+	if !ok {
+		return 0
+	}
+	return c.Clamp(d)
+}
+
+// ParseRetryAfter parses the Retry-After header of a response, which is
+// either a number of seconds or an HTTP date. A date is measured against
+// the response's Date header when it has one, so a skewed local clock does
+// not stretch or cancel the delay; otherwise against now. Dates in the
+// past give a zero delay. ok is false if the header is absent or
+// malformed.
+func ParseRetryAfter(header http.Header, now time.Time) (d time.Duration, ok bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > math.MaxInt64/int64(time.Second) {
+			return math.MaxInt64, true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	retryAt, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		now = date
+	}
+	if d := retryAt.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
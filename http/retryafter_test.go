@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfterHeader(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{"absent", http.Header{}, 0, false},
+		{"seconds", http.Header{"Retry-After": {"120"}}, 2 * time.Minute, true},
+		{"negative", http.Header{"Retry-After": {"-5"}}, 0, false},
+		{"garbage", http.Header{"Retry-After": {"soon"}}, 0, false},
+		{"date", http.Header{"Retry-After": {"Fri, 01 Mar 2024 12:01:30 GMT"}}, 90 * time.Second, true},
+		{"past date", http.Header{"Retry-After": {"Fri, 01 Mar 2024 11:00:00 GMT"}}, 0, true},
+		{
+			// The server's clock is an hour behind ours; its Date header
+			// keeps the 30s delay from vanishing.
+			"skewed clock",
+			http.Header{"Retry-After": {"Fri, 01 Mar 2024 11:00:30 GMT"}, "Date": {"Fri, 01 Mar 2024 11:00:00 GMT"}},
+			30 * time.Second, true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseRetryAfter(tt.header, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ParseRetryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetryAfterConfigClamp(t *testing.T) {
+	cfg := RetryAfterConfig{Min: time.Second, Max: time.Minute}
+	header := http.Header{"Retry-After": {"3600"}}
+	if got := cfg.FromHeader(header); got != time.Minute {
+		t.Errorf("FromHeader(3600) = %v, want Max", got)
+	}
+	header.Set("Retry-After", "0")
+	if got := cfg.FromHeader(header); got != time.Second {
+		t.Errorf("FromHeader(0) = %v, want Min", got)
+	}
+	if got := cfg.FromHeader(http.Header{}); got != 0 {
+		t.Errorf("FromHeader(absent) = %v, want 0", got)
+	}
+	if got := (RetryAfterConfig{}).Clamp(time.Hour); got != time.Hour {
+		t.Errorf("zero config Clamp(1h) = %v, want 1h", got)
+	}
+}
+
+func TestClientRetryAfterHTTPDate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		date := time.Now().Add(-time.Hour).UTC()
+		w.Header().Set("Date", date.Format(http.TimeFormat))
+		w.Header().Set("Retry-After", date.Add(20*time.Second).Format(http.TimeFormat))
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Retry.MaxRetries = 0
+	client := New(cfg)
+	defer client.Close()
+
+	_, err := client.Get(context.Background(), server.URL)
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("Get() error = %v, want RateLimitError", err)
+	}
+	if rateErr.ServerRetryAfter != 20*time.Second || rateErr.RetryAfter < rateErr.ServerRetryAfter {
+		t.Errorf("RateLimitError = %+v, want a 20s server Retry-After", rateErr)
+	}
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -39,6 +40,19 @@ type SessionConfig struct {
 
 	// CookieJarOptions for cookiejar.New (nil uses defaults)
 	CookieJarOptions *cookiejar.Options
+
+	// ImportCookiesFrom is a Netscape cookies.txt file, e.g. exported from a
+	// logged-in browser, whose cookies are imported when the session is created
+	ImportCookiesFrom string
+
+	// ImportBrowser names a browser profile whose cookies are imported when
+	// the session is created, in yt-dlp's --cookies-from-browser syntax
+	// (e.g. "firefox" or "chrome:Profile 1"). See ImportBrowserCookies.
+	ImportBrowser string
+
+	// YtdlpPath is the yt-dlp executable used to read browser profiles
+	// (default "yt-dlp")
+	YtdlpPath string
 }
 
 // DefaultSessionConfig returns sensible defaults.
@@ -85,6 +99,18 @@ func NewSessionManager(cfg SessionConfig) (*SessionManager, error) {
 		}
 	}
 
+	// Explicitly requested imports override persisted cookies
+	if cfg.ImportCookiesFrom != "" {
+		if _, err := sm.ImportCookiesFile(cfg.ImportCookiesFrom); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.ImportBrowser != "" {
+		if _, err := sm.ImportBrowserCookies(context.Background(), cfg.ImportBrowser); err != nil {
+			return nil, err
+		}
+	}
+
 	return sm, nil
 }
 
@@ -101,22 +127,18 @@ func (sm *SessionManager) GetClient(baseConfig *Config) *Client {
 	httpClient := &http.Client{
 		Timeout: baseConfig.Timeout,
 		Jar:     sm.jar,
-		Transport: &http.Transport{
-			MaxIdleConns:        baseConfig.Transport.MaxIdleConns,
-			MaxIdleConnsPerHost: baseConfig.Transport.MaxIdleConnsPerHost,
-			MaxConnsPerHost:     baseConfig.Transport.MaxConnsPerHost,
-			IdleConnTimeout:     baseConfig.Transport.IdleConnTimeout,
-			ForceAttemptHTTP2:   baseConfig.Transport.ForceAttemptHTTP2,
-			DisableKeepAlives:   baseConfig.Transport.DisableKeepAlives,
-		},
+		Transport: baseConfig.wrapTransport(baseConfig.newRoundTripper()),
 	}
 
 	// Wrap with our custom client
 	client := &Client{
 		base:        httpClient,
 		config:      baseConfig,
-		rateLimiter: NewRateLimiter(baseConfig.RateLimiter),
+		rateLimiter: baseConfig.rateLimiter(),
 		session:     sm,
+		tracer:      NewTracer(baseConfig.Trace),
+		botDetector: NewBotDetector(baseConfig.BotDetection),
+		proxy:       http.ProxyFromEnvironment,
 	}
 
 	return client
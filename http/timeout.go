@@ -0,0 +1,160 @@
+package http
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Operation classifies a request by what it is for, so it can be given its
+// own timeout: a listing page should fail fast, a media download may take
+// minutes.
+type Operation string
+
+const (
+	// OperationOther is any request not classified below.
+	OperationOther Operation = ""
+	// OperationListing is a channel listing request: Innertube browse
+	// pages, RSS feeds, and Data API playlist and search pages.
+	OperationListing Operation = "listing"
+	// OperationTranscript is a caption track or transcript request.
+	OperationTranscript Operation = "transcript"
+	// OperationDownload is a media request to googlevideo.com.
+	OperationDownload Operation = "download"
+)
+
+// OperationTimeouts overrides Config.Timeout for requests of an operation.
+// A zero field uses Config.Timeout.
+type OperationTimeouts struct {
+	Listing    time.Duration
+	Transcript time.Duration
+	Download   time.Duration
+}
+
+// DefaultOperationTimeouts returns defaults that give downloads far longer
+// than the 30 second Timeout other requests get.
+func DefaultOperationTimeouts() OperationTimeouts {
+	return OperationTimeouts{
+		Download: 10 * time.Minute,
+	}
+}
+
+// endpoint is a kind of request, recognized by its path prefix.
+type endpoint struct {
+	prefix    string
+	class     PathClass
+	operation Operation
+}
+
+// endpoints classifies request paths for both rate limiting (PathClassOf)
+// and timeouts (OperationOf), so the two agree on what a request is.
+// PathClassNone leaves the path to PathClassOf's default.
+var endpoints = []endpoint{
+	{"/youtubei/v1/browse", PathClassBrowse, OperationListing},
+	{"/youtubei/v1/next", PathClassNext, OperationOther},
+	{"/youtubei/v1/player", PathClassPlayer, OperationOther},
+	{"/youtubei/v1/get_transcript", PathClassTimedText, OperationTranscript},
+	{"/api/timedtext", PathClassTimedText, OperationTranscript},
+	{"/feeds/videos.xml", PathClassFeed, OperationListing},
+	{"/feeds/", PathClassFeed, OperationOther},
+	{"/youtube/v3/playlistItems", PathClassNone, OperationListing},
+	{"/youtube/v3/search", PathClassNone, OperationListing},
+}
+
+// endpointOf returns the endpoint path belongs to, or the zero endpoint.
+func endpointOf(path string) endpoint {
+	for _, e := range endpoints {
+		if strings.HasPrefix(path, e.prefix) {
+			return e
+		}
+	}
+	return endpoint{}
+}
+
+// OperationOf classifies a request URL.
+func OperationOf(urlStr string) Operation {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return OperationOther
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "googlevideo.com" || strings.HasSuffix(host, ".googlevideo.com") {
+		return OperationDownload
+	}
+	return endpointOf(u.Path).operation
+}
+
+// timeoutFor returns the per-attempt timeout for a request to urlStr.
+func (c *Config) timeoutFor(urlStr string) time.Duration {
+	var override time.Duration
+	switch OperationOf(urlStr) {
+	case OperationListing:
+		override = c.OperationTimeouts.Listing
+	case OperationTranscript:
+		override = c.OperationTimeouts.Transcript
+	case OperationDownload:
+		override = c.OperationTimeouts.Download
+	}
+	if override > 0 {
+		return override
+	}
+	return c.Timeout
+}
+
+// newTransport builds the pooled network transport described by
+// c.Transport.
+func (c *Config) newTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   c.Transport.DialTimeout,
+		KeepAlive: c.Transport.KeepAlive,
+	}
+	transport := &http.Transport{
+		// Connection setup
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   c.Transport.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: c.Transport.ResponseHeaderTimeout,
+
+		// Connection pool settings
+		MaxIdleConns:        c.Transport.MaxIdleConns,
+		MaxIdleConnsPerHost: c.Transport.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     c.Transport.MaxConnsPerHost,
+		IdleConnTimeout:     c.Transport.IdleConnTimeout,
+
+		// HTTP/2 support
+		ForceAttemptHTTP2: c.Transport.ForceAttemptHTTP2,
+
+		// TCP keepalive
+		DisableKeepAlives: c.Transport.DisableKeepAlives,
+
+		// Honor HTTP_PROXY, HTTPS_PROXY, and NO_PROXY
+		Proxy: http.ProxyFromEnvironment,
+	}
+	if c.Transport.TLSSessionCacheSize > 0 {
+		transport.TLSClientConfig = &tls.Config{
+			ClientSessionCache: tls.NewLRUClientSessionCache(c.Transport.TLSSessionCacheSize),
+		}
+	}
+	if c.Transport.DisableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		transport.Protocols = new(http.Protocols)
+		transport.Protocols.SetHTTP1(true)
+	} else if c.Transport.HTTP2PingTimeout > 0 {
+		transport.HTTP2 = &http.HTTP2Config{
+			SendPingTimeout: c.Transport.HTTP2PingTimeout,
+			PingTimeout:     c.Transport.HTTP2PingTimeout / 2,
+		}
+	}
+	return transport
+}
+
+// newRoundTripper returns the network transport for a client: a single
+// pooled transport, or one per site if c.Transport.IsolateDomains is set.
+func (c *Config) newRoundTripper() http.RoundTripper {
+	if c.Transport.IsolateDomains {
+		return newDomainTransport(c.newTransport)
+	}
+	return c.newTransport()
+}
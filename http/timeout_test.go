@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"ytsync/errcode"
+)
+
+func TestOperationOf(t *testing.T) {
+	tests := []struct {
+		url  string
+		want Operation
+	}{
+		{"https://www.youtube.com/youtubei/v1/browse?prettyPrint=false", OperationListing},
+		{"https://www.youtube.com/feeds/videos.xml?channel_id=UC123", OperationListing},
+		{"https://www.googleapis.com/youtube/v3/playlistItems?part=snippet", OperationListing},
+		{"https://www.youtube.com/api/timedtext?v=abc&lang=en", OperationTranscript},
+		{"https://rr3---sn-abc.googlevideo.com/videoplayback?id=1", OperationDownload},
+		{"https://www.youtube.com/youtubei/v1/player", OperationOther},
+		{"https://www.youtube.com/watch?v=abc", OperationOther},
+		{"://bad", OperationOther},
+	}
+	for _, tt := range tests {
+		if got := OperationOf(tt.url); got != tt.want {
+			t.Errorf("OperationOf(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestConfig_TimeoutFor(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.OperationTimeouts.Listing = 5 * time.Second
+
+	if got := cfg.timeoutFor("https://www.youtube.com/youtubei/v1/browse"); got != 5*time.Second {
+		t.Errorf("listing timeout = %v, want 5s", got)
+	}
+	if got := cfg.timeoutFor("https://rr1---sn-x.googlevideo.com/videoplayback"); got != 10*time.Minute {
+		t.Errorf("download timeout = %v, want the 10m default", got)
+	}
+	if got := cfg.timeoutFor("https://www.youtube.com/api/timedtext?v=abc"); got != cfg.Timeout {
+		t.Errorf("transcript timeout = %v, want Timeout (%v)", got, cfg.Timeout)
+	}
+}
+
+func TestClient_OperationTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Retry.MaxRetries = 0
+	cfg.Timeout = 5 * time.Second
+	cfg.OperationTimeouts.Transcript = 50 * time.Millisecond
+	client := New(cfg)
+	defer client.Close()
+
+	_, err := client.Get(context.Background(), server.URL+"/api/timedtext?v=abc")
+	if got := errcode.Of(err); got != errcode.Timeout {
+		t.Fatalf("transcript request error = %v (code %q), want a timeout", err, got)
+	}
+	if _, err := client.Get(context.Background(), server.URL+"/watch?v=abc"); err != nil {
+		t.Errorf("other request error = %v, want the longer Timeout to apply", err)
+	}
+}
+
+func TestClient_TotalTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Retry.MaxRetries = 10
+	cfg.Retry.InitialBackoff = 50 * time.Millisecond
+	cfg.Retry.MaxBackoff = 50 * time.Millisecond
+	cfg.CircuitBreaker.FailureThreshold = 100
+	cfg.TotalTimeout = 120 * time.Millisecond
+	client := New(cfg)
+	defer client.Close()
+
+	start := time.Now()
+	_, err := client.Get(context.Background(), server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Get() error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Get() took %v, want it stopped by TotalTimeout", elapsed)
+	}
+}
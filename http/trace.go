@@ -0,0 +1,408 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+	"ytsync/tags"
+)
+
+// TraceConfig configures request tracing.
+// Tracing records every request made through the Client into a fixed-size
+// ring buffer, which is useful for debugging why particular channels trigger
+// rate limiting or bot detection.
+type TraceConfig struct {
+	// Enabled turns on request tracing. Default: false
+	Enabled bool
+
+	// BufferSize is the maximum number of traces kept; older traces are
+	// discarded first. Default: 500
+	BufferSize int
+
+	// CaptureBodies records response bodies (truncated to MaxBodySize).
+	// Bodies can be large and may contain personal data, so this is off by default.
+	CaptureBodies bool
+
+	// MaxBodySize is the maximum number of body bytes captured per response.
+	// Default: 64 KiB
+	MaxBodySize int
+}
+
+// DefaultTraceConfig returns the default (disabled) tracing configuration.
+func DefaultTraceConfig() TraceConfig {
+	return TraceConfig{
+		Enabled:     false,
+		BufferSize:  500,
+		MaxBodySize: 64 * 1024,
+	}
+}
+
+// redactedHeaders are never recorded verbatim in traces.
+var redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Goog-Api-Key"}
+
+// Trace records a single Client.Do call, including all retry attempts.
+type Trace struct {
+	// Domain is the request host, as used for rate limiting and circuit breaking.
+	Domain string `json:"domain"`
+	// Method is the HTTP method.
+	Method string `json:"method"`
+	// URL is the full request URL.
+	URL string `json:"url"`
+	// Tags are the tags of the request's context, such as the channel and
+	// operation it was made for.
+	Tags map[string]string `json:"tags,omitempty"`
+	// StartedAt is when Do was called.
+	StartedAt time.Time `json:"started_at"`
+	// Duration is the total time spent in Do, including waits and retries.
+	Duration time.Duration `json:"duration"`
+	// RateLimitWait is the time spent waiting on backoff and the rate limiter.
+	RateLimitWait time.Duration `json:"rate_limit_wait"`
+	// Retries is the number of attempts after the first.
+	Retries int `json:"retries"`
+	// StatusCode is the status of the final attempt (0 if no response).
+	StatusCode int `json:"status_code"`
+	// BotDetection is true if any attempt was rejected as suspected bot traffic.
+	BotDetection bool `json:"bot_detection"`
+	// Error is the final error message, if the request failed.
+	Error string `json:"error,omitempty"`
+	// Attempts records each individual HTTP round trip.
+	Attempts []TraceAttempt `json:"attempts"`
+}
+
+// TraceAttempt records a single HTTP round trip within a Trace.
+type TraceAttempt struct {
+	// StartedAt is when the attempt was sent.
+	StartedAt time.Time `json:"started_at"`
+	// Duration is the time until the response headers arrived.
+	Duration time.Duration `json:"duration"`
+	// StatusCode is the response status (0 if the request failed).
+	StatusCode int `json:"status_code"`
+	// RequestHeaders are the headers sent, with credentials redacted.
+	RequestHeaders http.Header `json:"request_headers,omitempty"`
+	// ResponseHeaders are the headers received, with cookies redacted.
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	// ResponseBody is the captured body, if TraceConfig.CaptureBodies is set.
+	ResponseBody []byte `json:"response_body,omitempty"`
+	// Error is the attempt's error message, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Tracer stores request traces in a ring buffer.
+// All methods are safe for concurrent use and on a nil Tracer.
+type Tracer struct {
+	mu      sync.Mutex
+	config  TraceConfig
+	entries []Trace
+	next    int
+	full    bool
+}
+
+// NewTracer creates a tracer with the given configuration.
+// Returns nil if tracing is disabled.
+func NewTracer(cfg TraceConfig) *Tracer {
+	if !cfg.Enabled {
+		return nil
+	}
+	defaults := DefaultTraceConfig()
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaults.BufferSize
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaults.MaxBodySize
+	}
+	return &Tracer{
+		config:  cfg,
+		entries: make([]Trace, cfg.BufferSize),
+	}
+}
+
+// Traces returns the recorded traces, oldest first.
+func (t *Tracer) Traces() []Trace {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out []Trace
+	if t.full {
+		out = append(out, t.entries[t.next:]...)
+	}
+	return append(out, t.entries[:t.next]...)
+}
+
+// TracesForDomain returns the recorded traces for a single domain, oldest first.
+func (t *Tracer) TracesForDomain(domain string) []Trace {
+	var out []Trace
+	for _, tr := range t.Traces() {
+		if tr.Domain == domain {
+			out = append(out, tr)
+		}
+	}
+	return out
+}
+
+// Reset discards all recorded traces.
+func (t *Tracer) Reset() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = make([]Trace, len(t.entries))
+	t.next = 0
+	t.full = false
+}
+
+// WriteJSON writes the recorded traces as a JSON array.
+func (t *Tracer) WriteJSON(w io.Writer) error {
+	traces := t.Traces()
+	if traces == nil {
+		traces = []Trace{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(traces)
+}
+
+// WriteHAR writes the recorded traces in HTTP Archive (HAR 1.2) format,
+// viewable in browser developer tools. Each attempt becomes one HAR entry;
+// ytsync-specific details are stored in underscore-prefixed custom fields.
+func (t *Tracer) WriteHAR(w io.Writer) error {
+	entries := []harEntry{}
+	for _, tr := range t.Traces() {
+		for i, a := range tr.Attempts {
+			entries = append(entries, newHAREntry(tr, i, a))
+		}
+	}
+
+	doc := harDocument{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "ytsync", Version: "1.0"},
+		Entries: entries,
+	}}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// captureBodies reports whether response bodies should be recorded.
+func (t *Tracer) captureBodies() bool {
+	return t != nil && t.config.CaptureBodies
+}
+
+// maxBodySize returns the body capture limit.
+func (t *Tracer) maxBodySize() int {
+	if t == nil {
+		return 0
+	}
+	return t.config.MaxBodySize
+}
+
+// begin starts a trace for a request. Returns nil if tracing is disabled.
+func (t *Tracer) begin(ctx context.Context, method, urlStr, domain string) *Trace {
+	if t == nil {
+		return nil
+	}
+	return &Trace{
+		Domain:    domain,
+		Method:    method,
+		URL:       urlStr,
+		Tags:      tags.From(ctx).Map(),
+		StartedAt: time.Now(),
+	}
+}
+
+// finish completes a trace and stores it in the ring buffer.
+func (t *Tracer) finish(tr *Trace, err error) {
+	if t == nil || tr == nil {
+		return
+	}
+	tr.Duration = time.Since(tr.StartedAt)
+	if len(tr.Attempts) > 1 {
+		tr.Retries = len(tr.Attempts) - 1
+	}
+	if n := len(tr.Attempts); n > 0 {
+		tr.StatusCode = tr.Attempts[n-1].StatusCode
+	}
+	if err != nil {
+		tr.Error = err.Error()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries[t.next] = *tr
+	t.next = (t.next + 1) % len(t.entries)
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+// truncateBody returns at most max bytes of body.
+func truncateBody(body []byte, max int) []byte {
+	if len(body) > max {
+		body = body[:max]
+	}
+	return append([]byte(nil), body...)
+}
+
+// redactHeaders returns a copy of h with credential headers redacted.
+func redactHeaders(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	out := h.Clone()
+	for _, name := range redactedHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, "[redacted]")
+		}
+	}
+	return out
+}
+
+// HAR 1.2 document types. See http://www.softwareishard.com/blog/har-12-spec/
+
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+
+	Domain        string  `json:"_domain"`
+	Attempt       int     `json:"_attempt"`
+	RateLimitWait float64 `json:"_rateLimitWaitMs"`
+	BotDetection  bool    `json:"_botDetection"`
+}
+
+type harRequest struct {
+	Method      string  `json:"method"`
+	URL         string  `json:"url"`
+	HTTPVersion string  `json:"httpVersion"`
+	Cookies     []harNV `json:"cookies"`
+	Headers     []harNV `json:"headers"`
+	QueryString []harNV `json:"queryString"`
+	HeadersSize int     `json:"headersSize"`
+	BodySize    int     `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int        `json:"status"`
+	StatusText  string     `json:"statusText"`
+	HTTPVersion string     `json:"httpVersion"`
+	Cookies     []harNV    `json:"cookies"`
+	Headers     []harNV    `json:"headers"`
+	Content     harContent `json:"content"`
+	RedirectURL string     `json:"redirectURL"`
+	HeadersSize int        `json:"headersSize"`
+	BodySize    int        `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type harNV struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// newHAREntry converts one attempt of a trace into a HAR entry.
+func newHAREntry(tr Trace, index int, a TraceAttempt) harEntry {
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+
+	var query []harNV
+	if u, err := url.Parse(tr.URL); err == nil {
+		for name, values := range u.Query() {
+			for _, v := range values {
+				query = append(query, harNV{Name: name, Value: v})
+			}
+		}
+	}
+
+	entry := harEntry{
+		StartedDateTime: a.StartedAt.Format(time.RFC3339Nano),
+		Time:            ms(a.Duration),
+		Request: harRequest{
+			Method:      tr.Method,
+			URL:         tr.URL,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNV{},
+			Headers:     headerPairs(a.RequestHeaders),
+			QueryString: nonNil(query),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Response: harResponse{
+			Status:      a.StatusCode,
+			StatusText:  http.StatusText(a.StatusCode),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []harNV{},
+			Headers:     headerPairs(a.ResponseHeaders),
+			Content: harContent{
+				Size:     len(a.ResponseBody),
+				MimeType: a.ResponseHeaders.Get("Content-Type"),
+				Text:     string(a.ResponseBody),
+			},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{Wait: ms(a.Duration)},
+		Comment: a.Error,
+
+		Domain:       tr.Domain,
+		Attempt:      index + 1,
+		BotDetection: tr.BotDetection,
+	}
+	if index == 0 {
+		entry.RateLimitWait = ms(tr.RateLimitWait)
+	}
+	return entry
+}
+
+// headerPairs flattens headers into HAR name/value pairs.
+func headerPairs(h http.Header) []harNV {
+	pairs := []harNV{}
+	for name, values := range h {
+		for _, v := range values {
+			pairs = append(pairs, harNV{Name: name, Value: v})
+		}
+	}
+	return pairs
+}
+
+func nonNil(pairs []harNV) []harNV {
+	if pairs == nil {
+		return []harNV{}
+	}
+	return pairs
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"ytsync/tags"
+)
+
+func newTracingClient(captureBodies bool) *Client {
+	cfg := DefaultConfig()
+	cfg.Retry.MaxRetries = 2
+	cfg.Retry.InitialBackoff = time.Millisecond
+	cfg.Retry.MaxBackoff = 5 * time.Millisecond
+	cfg.RateLimiter.EnableDynamicBackoff = false
+	cfg.Trace = TraceConfig{Enabled: true, BufferSize: 3, CaptureBodies: captureBodies}
+	return New(cfg)
+}
+
+func TestTracerDisabledByDefault(t *testing.T) {
+	client := New(DefaultConfig())
+	defer client.Close()
+
+	if client.Tracer() != nil {
+		t.Error("expected nil tracer when tracing is disabled")
+	}
+	// Nil tracer methods must be safe
+	if traces := client.Tracer().Traces(); traces != nil {
+		t.Errorf("expected no traces, got %d", len(traces))
+	}
+}
+
+func TestTracerRecordsRetries(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("boom"))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := newTracingClient(false)
+	defer client.Close()
+
+	if _, err := client.Do(context.Background(), http.MethodGet, server.URL, nil,
+		map[string]string{"Authorization": "Bearer secret"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	traces := client.Tracer().Traces()
+	if len(traces) != 1 {
+		t.Fatalf("expected 1 trace, got %d", len(traces))
+	}
+	tr := traces[0]
+	if tr.Retries != 1 {
+		t.Errorf("expected 1 retry, got %d", tr.Retries)
+	}
+	if tr.StatusCode != http.StatusOK {
+		t.Errorf("expected final status 200, got %d", tr.StatusCode)
+	}
+	if tr.Attempts[0].StatusCode != http.StatusInternalServerError || tr.Attempts[0].Error == "" {
+		t.Errorf("expected first attempt to record 500 error, got %+v", tr.Attempts[0])
+	}
+	if got := tr.Attempts[0].RequestHeaders.Get("Authorization"); got != "[redacted]" {
+		t.Errorf("expected Authorization to be redacted, got %q", got)
+	}
+	if tr.Attempts[1].ResponseBody != nil {
+		t.Error("expected no body capture when CaptureBodies is false")
+	}
+}
+
+func TestTracerRecordsTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newTracingClient(false)
+	defer client.Close()
+
+	ctx := tags.WithOperation(tags.WithChannel(context.Background(), "UCtest"), "rss")
+	_, report, err := client.DoDetailed(ctx, http.MethodGet, server.URL, nil, nil)
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("error = %v, want a 404 HTTPError", err)
+	}
+	if got := tags.Of(err).Get(tags.Channel); got != "UCtest" {
+		t.Errorf("error channel tag = %q, want UCtest (error %q)", got, err)
+	}
+	if got := report.Tags.String(); got != "channel=UCtest op=rss" {
+		t.Errorf("report tags = %q", got)
+	}
+	traces := client.Tracer().Traces()
+	if len(traces) != 1 || traces[0].Tags["op"] != "rss" {
+		t.Fatalf("traces = %+v, want one tagged op=rss", traces)
+	}
+	if traces[0].Error != "http error: status 404" {
+		t.Errorf("trace error = %q, want it without tags", traces[0].Error)
+	}
+}
+
+func TestTracerCapturesBotDetectionBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("unusual traffic"))
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.Retry.MaxRetries = 0
+	cfg.Trace = TraceConfig{Enabled: true, CaptureBodies: true}
+	client := New(cfg)
+	defer client.Close()
+
+	if _, err := client.Get(context.Background(), server.URL); err == nil {
+		t.Fatal("expected error for 403 response")
+	}
+
+	traces := client.Tracer().Traces()
+	if len(traces) != 1 {
+		t.Fatalf("expected 1 trace, got %d", len(traces))
+	}
+	if !traces[0].BotDetection {
+		t.Error("expected BotDetection to be set")
+	}
+	if traces[0].Error == "" {
+		t.Error("expected trace error to be recorded")
+	}
+	if got := string(traces[0].Attempts[0].ResponseBody); got != "unusual traffic" {
+		t.Errorf("expected captured body, got %q", got)
+	}
+}
+
+func TestTracerRingBuffer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := newTracingClient(false)
+	defer client.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := client.Get(context.Background(), server.URL+"/"+string(rune('a'+i))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	traces := client.Tracer().Traces()
+	if len(traces) != 3 {
+		t.Fatalf("expected buffer of 3 traces, got %d", len(traces))
+	}
+	if traces[0].URL != server.URL+"/c" || traces[2].URL != server.URL+"/e" {
+		t.Errorf("expected oldest-first traces c..e, got %s..%s", traces[0].URL, traces[2].URL)
+	}
+
+	client.Tracer().Reset()
+	if n := len(client.Tracer().Traces()); n != 0 {
+		t.Errorf("expected 0 traces after reset, got %d", n)
+	}
+}
+
+func TestTracerExport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := newTracingClient(true)
+	defer client.Close()
+
+	if _, err := client.Get(context.Background(), server.URL+"/feed?id=1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := client.Tracer().WriteHAR(&buf); err != nil {
+		t.Fatalf("WriteHAR error: %v", err)
+	}
+	var har harDocument
+	if err := json.Unmarshal(buf.Bytes(), &har); err != nil {
+		t.Fatalf("invalid HAR JSON: %v", err)
+	}
+	if har.Log.Version != "1.2" || len(har.Log.Entries) != 1 {
+		t.Fatalf("unexpected HAR log: %+v", har.Log)
+	}
+	entry := har.Log.Entries[0]
+	if entry.Response.Status != http.StatusOK || entry.Response.Content.Text != "ok" {
+		t.Errorf("unexpected HAR response: %+v", entry.Response)
+	}
+	if len(entry.Request.QueryString) != 1 || entry.Request.QueryString[0].Value != "1" {
+		t.Errorf("unexpected HAR query string: %+v", entry.Request.QueryString)
+	}
+
+	buf.Reset()
+	if err := client.Tracer().WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON error: %v", err)
+	}
+	var traces []Trace
+	if err := json.Unmarshal(buf.Bytes(), &traces); err != nil {
+		t.Fatalf("invalid JSON export: %v", err)
+	}
+	if len(traces) != 1 {
+		t.Errorf("expected 1 exported trace, got %d", len(traces))
+	}
+}
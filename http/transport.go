@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// domainTransport sends requests through one transport per site, created
+// on first use, so each site has its own connection pool.
+type domainTransport struct {
+	newTransport func() *http.Transport
+
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+func newDomainTransport(newTransport func() *http.Transport) *domainTransport {
+	return &domainTransport{
+		newTransport: newTransport,
+		transports:   make(map[string]*http.Transport),
+	}
+}
+
+// RoundTrip sends req through the transport for its site.
+func (t *domainTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport(siteOf(req.URL.Hostname())).RoundTrip(req)
+}
+
+// transport returns the transport for site, creating it if needed.
+func (t *domainTransport) transport(site string) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	transport, ok := t.transports[site]
+	if !ok {
+		transport = t.newTransport()
+		t.transports[site] = transport
+	}
+	return transport
+}
+
+// CloseIdleConnections closes idle connections of every site's transport.
+func (t *domainTransport) CloseIdleConnections() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, transport := range t.transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+// siteOf returns the site a host belongs to: its last two labels, so
+// www.youtube.com and m.youtube.com share a pool while youtube.com and
+// googleapis.com do not. IP addresses and single-label hosts are their
+// own site.
+func siteOf(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) <= 2 {
+		return host
+	}
+	return strings.Join(labels[len(labels)-2:], ".")
+}
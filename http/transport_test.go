@@ -175,3 +175,61 @@ func TestClientClose(t *testing.T) {
 		t.Errorf("Close again returned error: %v", err)
 	}
 }
+
+func TestNewTransport_HTTP2AndSessionCache(t *testing.T) {
+	cfg := DefaultConfig()
+	transport := cfg.newTransport()
+	if transport.TLSClientConfig == nil || transport.TLSClientConfig.ClientSessionCache == nil {
+		t.Error("default transport has no TLS session cache")
+	}
+	if transport.HTTP2 == nil || transport.HTTP2.SendPingTimeout != 30*time.Second {
+		t.Errorf("HTTP2 = %+v, want ping health checks after 30s", transport.HTTP2)
+	}
+
+	cfg.Transport.DisableHTTP2 = true
+	cfg.Transport.TLSSessionCacheSize = 0
+	transport = cfg.newTransport()
+	if transport.ForceAttemptHTTP2 || transport.Protocols == nil || transport.Protocols.HTTP2() || !transport.Protocols.HTTP1() {
+		t.Errorf("DisableHTTP2 transport allows HTTP/2: ForceAttemptHTTP2=%v Protocols=%v", transport.ForceAttemptHTTP2, transport.Protocols)
+	}
+	if transport.TLSClientConfig != nil {
+		t.Error("TLSSessionCacheSize 0 still sets a session cache")
+	}
+}
+
+func TestIsolateDomains(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Transport.IsolateDomains = true
+	client := New(cfg)
+	defer client.Close()
+
+	domains, ok := client.base.Transport.(*domainTransport)
+	if !ok {
+		t.Fatalf("Transport = %T, want *domainTransport", client.base.Transport)
+	}
+	youtube := domains.transport(siteOf("www.youtube.com"))
+	if domains.transport(siteOf("m.youtube.com")) != youtube {
+		t.Error("hosts of the same site got different transports")
+	}
+	if domains.transport(siteOf("www.googleapis.com")) == youtube {
+		t.Error("youtube.com and googleapis.com share a transport")
+	}
+}
+
+func TestSiteOf(t *testing.T) {
+	tests := map[string]string{
+		"www.youtube.com":              "youtube.com",
+		"youtube.com":                  "youtube.com",
+		"WWW.YouTube.com.":             "youtube.com",
+		"youtube.googleapis.com":       "googleapis.com",
+		"rr1---sn-abc.googlevideo.com": "googlevideo.com",
+		"localhost":                    "localhost",
+		"127.0.0.1":                    "127.0.0.1",
+		"::1":                          "::1",
+	}
+	for host, want := range tests {
+		if got := siteOf(host); got != want {
+			t.Errorf("siteOf(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
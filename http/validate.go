@@ -0,0 +1,125 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"ytsync/errcode"
+)
+
+var (
+	// ErrEmptyResponse indicates a successful response had no body, or a
+	// shorter one than ResponseValidation.MinBodySize, where one was
+	// expected, as with a 204 No Content from an API endpoint.
+	ErrEmptyResponse = errcode.New(errcode.Unavailable, "empty response body")
+
+	// ErrTruncatedResponse indicates the connection ended before the whole
+	// response body was read.
+	ErrTruncatedResponse = errcode.New(errcode.Unavailable, "truncated response body")
+
+	// ErrMalformedJSON indicates the body of a JSON response is not
+	// well-formed JSON, usually because it was cut short.
+	ErrMalformedJSON = errcode.New(errcode.Unavailable, "malformed JSON response")
+)
+
+// InvalidResponseError is returned for a successful (2xx) response whose
+// body failed validation. Like 5xx responses it is retried, and it matches
+// the sentinel error of the check that failed: ErrEmptyResponse,
+// ErrTruncatedResponse, ErrMalformedJSON, or the error returned by
+// ResponseValidation.Validate.
+type InvalidResponseError struct {
+	// StatusCode is the HTTP status code
+	StatusCode int
+	// Size is the number of body bytes received
+	Size int
+	// Err describes the failed check
+	Err error
+}
+
+// Error returns a string representation of the validation failure.
+func (e *InvalidResponseError) Error() string {
+	return fmt.Sprintf("invalid response (status %d, %d bytes): %v", e.StatusCode, e.Size, e.Err)
+}
+
+// Unwrap returns the error of the failed check.
+func (e *InvalidResponseError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode classifies the error as errcode.Unavailable, so it is retried.
+func (e *InvalidResponseError) ErrorCode() errcode.Code {
+	return errcode.Unavailable
+}
+
+// ResponseValidation configures the checks of successful responses. A
+// response that fails one is retried. Bodies cut short by the connection
+// are always retried, whatever the configuration.
+type ResponseValidation struct {
+	// MinBodySize rejects responses with a shorter body, for every
+	// request. 0 disables the check.
+	MinBodySize int
+
+	// CheckJSON rejects responses from JSON API endpoints, those whose URL
+	// path starts with one of JSONPaths, whose body is empty or not
+	// well-formed JSON.
+	CheckJSON bool
+
+	// JSONPaths are the URL path prefixes of endpoints that always answer
+	// with JSON. Default: DefaultJSONPaths.
+	JSONPaths []string
+
+	// Validate, if set, is called with each successful response that
+	// passed the other checks. An error rejects the response.
+	Validate func(req *http.Request, resp *Response) error
+}
+
+// DefaultJSONPaths are the Innertube and Data API endpoints, which always
+// answer with JSON.
+var DefaultJSONPaths = []string{"/youtubei/v1/", "/youtube/v3/"}
+
+// DefaultResponseValidation returns the default checks: JSON API responses
+// must hold well-formed JSON.
+func DefaultResponseValidation() ResponseValidation {
+	return ResponseValidation{CheckJSON: true}
+}
+
+// check validates a successful response, returning an
+// *InvalidResponseError if it is rejected.
+func (v *ResponseValidation) check(req *http.Request, resp *Response) error {
+	fail := func(err error) error {
+		return &InvalidResponseError{StatusCode: resp.StatusCode, Size: len(resp.Body), Err: err}
+	}
+	if len(resp.Body) < v.MinBodySize {
+		return fail(fmt.Errorf("%w: got %d bytes, want at least %d", ErrEmptyResponse, len(resp.Body), v.MinBodySize))
+	}
+	if v.CheckJSON && v.isJSONEndpoint(req) {
+		if len(strings.TrimSpace(string(resp.Body))) == 0 {
+			return fail(ErrEmptyResponse)
+		}
+		if !json.Valid(resp.Body) {
+			return fail(ErrMalformedJSON)
+		}
+	}
+	if v.Validate != nil {
+		if err := v.Validate(req, resp); err != nil {
+			return fail(err)
+		}
+	}
+	return nil
+}
+
+// isJSONEndpoint reports whether req is to an endpoint that answers with
+// JSON.
+func (v *ResponseValidation) isJSONEndpoint(req *http.Request) bool {
+	paths := v.JSONPaths
+	if paths == nil {
+		paths = DefaultJSONPaths
+	}
+	for _, prefix := range paths {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
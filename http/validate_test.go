@@ -0,0 +1,146 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"ytsync/retry"
+)
+
+// truncatedReader returns its data and then io.ErrUnexpectedEOF, like a
+// connection closed partway through a response body.
+type truncatedReader struct {
+	r io.Reader
+}
+
+func (t *truncatedReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// newValidationTestClient returns a client that answers each request with
+// the next of bodies and records the request bodies it sent.
+func newValidationTestClient(status int, bodies []io.Reader, sent *[]string) *Client {
+	cfg := DefaultConfig()
+	cfg.Retry = retry.Config{MaxRetries: len(bodies) - 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 1}
+	cfg.RateLimiter.EnableDynamicBackoff = false
+	client := New(cfg)
+	client.base.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			data, _ := io.ReadAll(req.Body)
+			*sent = append(*sent, string(data))
+		}
+		body := bodies[0]
+		bodies = bodies[1:]
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(body), Request: req}, nil
+	})
+	return client
+}
+
+func TestClientRetriesInvalidResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		cut    bool
+		want   error
+	}{
+		{"truncated", http.StatusOK, `{"contents":`, true, ErrTruncatedResponse},
+		{"malformed JSON", http.StatusOK, `{"contents":`, false, ErrMalformedJSON},
+		{"no content", http.StatusNoContent, "", false, ErrEmptyResponse},
+	}
+	first := func(body string, cut bool) io.Reader {
+		if cut {
+			return &truncatedReader{strings.NewReader(body)}
+		}
+		return strings.NewReader(body)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			client := newValidationTestClient(tt.status, []io.Reader{first(tt.body, tt.cut), strings.NewReader(`{"contents":{}}`)}, &sent)
+			defer client.Close()
+
+			resp, report, err := client.DoDetailed(context.Background(), http.MethodPost,
+				"https://www.youtube.com/youtubei/v1/browse", strings.NewReader(`{"browseId":"UC1"}`), nil)
+			if err != nil {
+				t.Fatalf("DoDetailed() error = %v", err)
+			}
+			if string(resp.Body) != `{"contents":{}}` {
+				t.Errorf("body = %q", resp.Body)
+			}
+			if len(report.Attempts) != 2 {
+				t.Errorf("attempts = %d, want 2", len(report.Attempts))
+			}
+			if len(sent) != 2 || sent[1] != `{"browseId":"UC1"}` {
+				t.Errorf("request bodies = %q, want the body sent on each attempt", sent)
+			}
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" exhausted", func(t *testing.T) {
+			var sent []string
+			client := newValidationTestClient(tt.status, []io.Reader{first(tt.body, tt.cut)}, &sent)
+			defer client.Close()
+
+			_, err := client.Get(context.Background(), "https://www.youtube.com/youtubei/v1/browse")
+			var invalid *InvalidResponseError
+			if !errors.As(err, &invalid) || !errors.Is(err, tt.want) {
+				t.Fatalf("Get() error = %v, want %v", err, tt.want)
+			}
+			if invalid.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", invalid.StatusCode, tt.status)
+			}
+			if !retry.IsRetryable(err) {
+				t.Error("invalid response error classified as permanent")
+			}
+		})
+	}
+}
+
+func TestResponseValidation(t *testing.T) {
+	errTooSmall := errors.New("too small")
+	v := ResponseValidation{
+		MinBodySize: 2,
+		CheckJSON:   true,
+		Validate: func(req *http.Request, resp *Response) error {
+			if len(resp.Body) < 4 {
+				return errTooSmall
+			}
+			return nil
+		},
+	}
+	tests := []struct {
+		url  string
+		body string
+		want error
+	}{
+		{"https://www.youtube.com/youtubei/v1/next", `{"a":1}`, nil},
+		{"https://www.youtube.com/youtubei/v1/next", `{"a":`, ErrMalformedJSON},
+		{"https://www.googleapis.com/youtube/v3/videos", "  \n ", ErrEmptyResponse},
+		{"https://www.youtube.com/watch?v=x", `<html>`, nil},
+		{"https://www.youtube.com/watch?v=x", `<`, ErrEmptyResponse},
+		{"https://www.youtube.com/watch?v=x", `<ht`, errTooSmall},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+		err := v.check(req, &Response{StatusCode: http.StatusOK, Body: []byte(tt.body)})
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("check(%s, %q) = %v, want %v", tt.url, tt.body, err, tt.want)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://www.youtube.com/youtubei/v1/next", nil)
+	var none ResponseValidation
+	if err := none.check(req, &Response{StatusCode: http.StatusNoContent}); err != nil {
+		t.Errorf("zero ResponseValidation rejected a response: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // YouTubeRateLimitDetector detects YouTube-specific rate limiting signals.
@@ -69,11 +70,9 @@ func (d *YouTubeRateLimitDetector) hasRateLimitHeaders(header http.Header) bool
 // GetRetryAfterDuration extracts retry-after duration from response headers.
 // Checks Retry-After, X-RateLimit-Reset, and other timing headers.
 func (d *YouTubeRateLimitDetector) GetRetryAfterDuration(header http.Header) int64 {
-	// Standard Retry-After header (in seconds as integer)
-	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
-		if seconds := parseSeconds(retryAfter); seconds > 0 {
-			return seconds
-		}
+	// Standard Retry-After header (seconds or an HTTP date)
+	if d, ok := ParseRetryAfter(header, time.Now()); ok && d >= time.Second {
+		return int64(d / time.Second)
 	}
 
 	// YouTube-specific headers
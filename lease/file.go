@@ -0,0 +1,106 @@
+package lease
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+	"ytsync/storage"
+)
+
+// fileLockTimeout bounds how long FileBackend waits for another process
+// reading or writing the same lease file.
+const fileLockTimeout = 5 * time.Second
+
+// FileBackend keeps each lease in a JSON file in Dir, which is shared by
+// every instance, for example on NFS. Access to a lease file is serialized
+// with an advisory lock on a file next to it, so the shared file system
+// must support flock(2) or LockFileEx across hosts. Expiry is judged by
+// the clock of the host taking the lease, so hosts' clocks should be
+// synchronized to well within the TTL.
+type FileBackend struct {
+	// Dir is the directory of the lease files. It is created if needed.
+	Dir string
+}
+
+// Acquire takes or renews the lease on key if it is free, expired, or held
+// by owner.
+func (b *FileBackend) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (*Record, error) {
+	var rec *Record
+	err := b.locked(key, func(path string) error {
+		current, err := readRecord(path)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if current != nil && current.Owner != owner && now.Before(current.Expires) {
+			return &HeldError{Holder: *current}
+		}
+		rec = &Record{Key: key, Owner: owner, Expires: now.Add(ttl)}
+		return writeRecord(path, rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Release removes owner's lease on key.
+func (b *FileBackend) Release(ctx context.Context, key, owner string) error {
+	return b.locked(key, func(path string) error {
+		current, err := readRecord(path)
+		if err != nil || current == nil || current.Owner != owner {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// locked calls fn with the path of key's lease file while holding its lock.
+func (b *FileBackend) locked(key string, fn func(path string) error) error {
+	if err := os.MkdirAll(b.Dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(b.Dir, url.PathEscape(key)+".json")
+	lock := storage.NewFileLock(path)
+	if err := lock.Lock(fileLockTimeout); err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	return fn(path)
+}
+
+// readRecord reads the lease file at path, returning nil if there is none.
+func readRecord(path string) (*Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		// A torn write leaves the lease free to take
+		return nil, nil
+	}
+	return &rec, nil
+}
+
+// writeRecord replaces the lease file at path with rec.
+func writeRecord(path string, rec *Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,270 @@
+// Package lease coordinates work between ytsync instances on several hosts
+// with expiring leases, so that instances sharing a channel list do not
+// sync the same channel at once.
+//
+// A lease is held by one owner for a limited time and renewed while the
+// work runs. If the holder stops renewing it, for example because its host
+// went down, the lease expires and another instance takes it over. Leases
+// are kept in a pluggable Backend: FileBackend keeps them in a directory on
+// shared storage and RedisBackend in a Redis server. Other stores, such as
+// etcd, are supported by implementing Backend.
+//
+//	locker := lease.NewLocker(&lease.FileBackend{Dir: "/mnt/shared/ytsync-leases"})
+//	held, err := locker.Acquire(ctx, "channel/UCxxxxx")
+//	if errors.Is(err, lease.ErrHeld) {
+//		return // another instance is syncing the channel
+//	}
+//	defer held.Release(ctx)
+//	ctx, cancel := held.Context(ctx) // canceled if the lease is lost
+//	defer cancel()
+package lease
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+	"ytsync/errcode"
+)
+
+// DefaultTTL is how long a lease lasts without renewal when Locker.TTL is
+// zero.
+const DefaultTTL = 2 * time.Minute
+
+var (
+	// ErrHeld indicates the lease is held by another owner. Errors from
+	// Acquire match it with errors.Is, and errors.As extracts the
+	// *HeldError naming the holder.
+	ErrHeld = errcode.New(errcode.Unavailable, "lease: held by another owner")
+	// ErrLost is the cause of the cancellation of a lease's context when
+	// the lease could not be renewed before it expired.
+	ErrLost = errcode.New(errcode.Unavailable, "lease: lost")
+)
+
+// Record is the state of a lease as kept by a Backend.
+type Record struct {
+	// Key identifies the leased resource, such as "channel/UCxxxxx".
+	Key string `json:"key"`
+	// Owner identifies the holder.
+	Owner string `json:"owner"`
+	// Expires is when the lease ends unless it is renewed.
+	Expires time.Time `json:"expires"`
+}
+
+// HeldError is returned by Acquire when another owner holds the lease. It
+// matches ErrHeld.
+type HeldError struct {
+	// Holder is the lease of the current holder. Its Owner may be empty
+	// if the backend does not report it.
+	Holder Record
+}
+
+// Error returns a message naming the holder and when its lease expires.
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("lease: %s is held by %s until %s",
+		e.Holder.Key, e.Holder.Owner, e.Holder.Expires.Local().Format(time.RFC3339))
+}
+
+// Unwrap returns ErrHeld.
+func (e *HeldError) Unwrap() error {
+	return ErrHeld
+}
+
+// Backend stores leases. Implementations must be safe for concurrent use
+// and make Acquire atomic across every process sharing the backend.
+type Backend interface {
+	// Acquire takes the lease on key for owner until ttl from now if it is
+	// free, expired, or already held by owner, which renews it. Otherwise
+	// it returns a *HeldError.
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (*Record, error)
+	// Release ends owner's lease on key. Releasing a lease that owner does
+	// not hold is not an error.
+	Release(ctx context.Context, key, owner string) error
+}
+
+// NewOwner returns an owner ID naming this host and process, with a random
+// suffix that tells apart Lockers in the same process.
+func NewOwner() string {
+	host, _ := os.Hostname()
+	var b [4]byte
+	rand.Read(b[:])
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(b[:]))
+}
+
+// Locker acquires leases from a Backend and renews them while they are
+// held.
+type Locker struct {
+	// Backend keeps the leases.
+	Backend Backend
+	// Owner identifies this instance to the others. Default: NewOwner().
+	Owner string
+	// TTL is how long a lease lasts without renewal. Leases are renewed
+	// every third of it. Default: DefaultTTL.
+	TTL time.Duration
+
+	once sync.Once
+	mu   sync.Mutex
+	held map[string]*Lease
+}
+
+// NewLocker returns a Locker using backend, with a new owner ID and the
+// default TTL.
+func NewLocker(backend Backend) *Locker {
+	return &Locker{Backend: backend, Owner: NewOwner(), TTL: DefaultTTL}
+}
+
+// Acquire takes the lease on key and renews it in the background until it
+// is released or lost. It does not wait: if another owner holds the lease,
+// or this Locker already holds it, it returns a *HeldError at once.
+func (l *Locker) Acquire(ctx context.Context, key string) (*Lease, error) {
+	l.once.Do(func() {
+		if l.Owner == "" {
+			l.Owner = NewOwner()
+		}
+		if l.TTL <= 0 {
+			l.TTL = DefaultTTL
+		}
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if other := l.held[key]; other != nil {
+		return nil, &HeldError{Holder: Record{Key: key, Owner: l.Owner, Expires: other.Expires()}}
+	}
+	rec, err := l.Backend.Acquire(ctx, key, l.Owner, l.TTL)
+	if err != nil {
+		return nil, err
+	}
+	held := &Lease{
+		locker:  l,
+		key:     key,
+		expires: rec.Expires,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		lost:    make(chan struct{}),
+	}
+	if l.held == nil {
+		l.held = make(map[string]*Lease)
+	}
+	l.held[key] = held
+	go held.renew()
+	return held, nil
+}
+
+// forget removes a released or lost lease from the leases l holds.
+func (l *Locker) forget(held *Lease) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[held.key] == held {
+		delete(l.held, held.key)
+	}
+}
+
+// Lease is a lease held by a Locker.
+type Lease struct {
+	locker *Locker
+	key    string
+
+	mu      sync.Mutex
+	expires time.Time
+	err     error
+
+	stop     chan struct{}
+	stopped  chan struct{}
+	lost     chan struct{}
+	stopOnce sync.Once
+}
+
+// Key returns the leased key.
+func (h *Lease) Key() string {
+	return h.key
+}
+
+// Expires returns when the lease ends unless it is renewed again.
+func (h *Lease) Expires() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.expires
+}
+
+// Lost returns a channel that is closed when the lease could not be
+// renewed and another owner may have taken it over.
+func (h *Lease) Lost() <-chan struct{} {
+	return h.lost
+}
+
+// Err returns why the lease was lost, or nil while it is held.
+func (h *Lease) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// Context returns a copy of parent that is canceled with cause ErrLost
+// when the lease is lost, so that work under the lease stops before
+// another owner starts it again.
+func (h *Lease) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	go func() {
+		select {
+		case <-h.lost:
+			cancel(h.Err())
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() { cancel(nil) }
+}
+
+// Release stops renewing the lease and releases it, so another owner can
+// take it at once instead of waiting for it to expire.
+func (h *Lease) Release(ctx context.Context) error {
+	h.stopOnce.Do(func() { close(h.stop) })
+	<-h.stopped
+	defer h.locker.forget(h)
+	if h.Err() != nil {
+		return nil
+	}
+	return h.locker.Backend.Release(ctx, h.key, h.locker.Owner)
+}
+
+// renew renews the lease every third of its TTL until it is released. A
+// renewal that fails is retried until the lease expires; a lease taken over
+// by another owner is lost at once.
+func (h *Lease) renew() {
+	defer close(h.stopped)
+	interval := h.locker.TTL / 3
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-timer.C:
+		}
+
+		ctx, cancel := context.WithDeadline(context.Background(), h.Expires())
+		rec, err := h.locker.Backend.Acquire(ctx, h.key, h.locker.Owner, h.locker.TTL)
+		cancel()
+		if err == nil {
+			h.mu.Lock()
+			h.expires = rec.Expires
+			h.mu.Unlock()
+			timer.Reset(interval)
+			continue
+		}
+		if errors.Is(err, ErrHeld) || !time.Now().Before(h.Expires()) {
+			h.mu.Lock()
+			h.err = fmt.Errorf("%w: %s: %w", ErrLost, h.key, err)
+			h.mu.Unlock()
+			h.locker.forget(h)
+			close(h.lost)
+			return
+		}
+		// Retry sooner than the regular interval while time is left
+		timer.Reset(min(interval, time.Until(h.Expires()))/2 + time.Millisecond)
+	}
+}
@@ -0,0 +1,106 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLocker(t *testing.T) {
+	backend := &FileBackend{Dir: t.TempDir()}
+	a := NewLocker(backend)
+	b := NewLocker(backend)
+	ctx := context.Background()
+
+	held, err := a.Acquire(ctx, "channel/UCa")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	_, err = b.Acquire(ctx, "channel/UCa")
+	var heldErr *HeldError
+	if !errors.As(err, &heldErr) || heldErr.Holder.Owner != a.Owner {
+		t.Fatalf("Acquire() of a held lease error = %v, want a *HeldError naming %s", err, a.Owner)
+	}
+	// A Locker does not hand out a lease it already holds
+	if _, err := a.Acquire(ctx, "channel/UCa"); !errors.Is(err, ErrHeld) {
+		t.Errorf("second Acquire() by the holder error = %v, want ErrHeld", err)
+	}
+	if _, err := b.Acquire(ctx, "channel/UCb"); err != nil {
+		t.Errorf("Acquire() of another key error = %v", err)
+	}
+
+	if err := held.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := b.Acquire(ctx, "channel/UCa"); err != nil {
+		t.Errorf("Acquire() after Release() error = %v", err)
+	}
+}
+
+func TestLockerTakeover(t *testing.T) {
+	backend := &FileBackend{Dir: t.TempDir()}
+	ctx := context.Background()
+
+	// A holder that stopped renewing, as after a crash
+	if _, err := backend.Acquire(ctx, "channel/UCa", "crashed", 10*time.Millisecond); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	held, err := NewLocker(backend).Acquire(ctx, "channel/UCa")
+	if err != nil {
+		t.Fatalf("Acquire() of an expired lease error = %v", err)
+	}
+	defer held.Release(ctx)
+	// The old holder cannot release the lease it lost
+	if err := backend.Release(ctx, "channel/UCa", "crashed"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := backend.Acquire(ctx, "channel/UCa", "crashed", time.Minute); !errors.Is(err, ErrHeld) {
+		t.Errorf("Acquire() by the old holder error = %v, want ErrHeld", err)
+	}
+}
+
+func TestLeaseRenewAndLose(t *testing.T) {
+	backend := &FileBackend{Dir: t.TempDir()}
+	locker := &Locker{Backend: backend, TTL: 60 * time.Millisecond}
+	ctx := context.Background()
+
+	held, err := locker.Acquire(ctx, "channel/UCa")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	leaseCtx, cancel := held.Context(ctx)
+	defer cancel()
+
+	// Renewals keep the lease past its first expiry
+	time.Sleep(150 * time.Millisecond)
+	if _, err := backend.Acquire(ctx, "channel/UCa", "other", time.Minute); !errors.Is(err, ErrHeld) {
+		t.Fatalf("Acquire() of a renewed lease error = %v, want ErrHeld", err)
+	}
+
+	// Another owner taking the lease over is noticed at the next renewal
+	if err := backend.Release(ctx, "channel/UCa", locker.Owner); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backend.Acquire(ctx, "channel/UCa", "other", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-held.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lease not lost after a takeover")
+	}
+	<-leaseCtx.Done()
+	if cause := context.Cause(leaseCtx); !errors.Is(cause, ErrLost) {
+		t.Errorf("context cause = %v, want ErrLost", cause)
+	}
+	// Releasing a lost lease leaves the new holder alone
+	if err := held.Release(ctx); err != nil {
+		t.Errorf("Release() of a lost lease error = %v", err)
+	}
+	if _, err := backend.Acquire(ctx, "channel/UCa", locker.Owner, time.Minute); !errors.Is(err, ErrHeld) {
+		t.Errorf("Acquire() after releasing a lost lease error = %v, want ErrHeld", err)
+	}
+}
@@ -0,0 +1,176 @@
+package lease
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// acquireScript sets KEYS[1] to the owner ARGV[1] for ARGV[2] milliseconds
+// if it is unset or already holds the owner. It returns 1 and the TTL, or 0
+// with the current owner and its remaining TTL.
+const acquireScript = `local v = redis.call('GET', KEYS[1])
+if v == false or v == ARGV[1] then
+  redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+  return {1, ARGV[1], tonumber(ARGV[2])}
+end
+return {0, v, redis.call('PTTL', KEYS[1])}`
+
+// releaseScript deletes KEYS[1] if it holds the owner ARGV[1].
+const releaseScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0`
+
+// RedisBackend keeps leases as keys in a Redis server, which expires them
+// by itself. Each lease is a key holding its owner with a TTL, taken and
+// released with Lua scripts so that an owner never overwrites or deletes
+// another's lease. A connection is opened for each call.
+type RedisBackend struct {
+	// Addr is the server's host:port.
+	Addr string
+	// Password authenticates with AUTH if set.
+	Password string
+	// DB selects a database other than 0.
+	DB int
+	// Prefix is prepended to lease keys. Default: "ytsync:lease:".
+	Prefix string
+	// DialTimeout bounds connecting to the server. Default: 5s.
+	DialTimeout time.Duration
+}
+
+// Acquire takes or renews the lease on key if it is free or held by owner.
+func (b *RedisBackend) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (*Record, error) {
+	now := time.Now()
+	reply, err := b.do(ctx, "EVAL", acquireScript, "1", b.key(key), owner, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := reply.([]any)
+	if !ok || len(fields) != 3 {
+		return nil, fmt.Errorf("lease: unexpected redis reply %v", reply)
+	}
+	taken, _ := fields[0].(int64)
+	holder, _ := fields[1].(string)
+	remaining, _ := fields[2].(int64)
+	rec := Record{Key: key, Owner: holder, Expires: now.Add(time.Duration(remaining) * time.Millisecond)}
+	if taken != 1 {
+		return nil, &HeldError{Holder: rec}
+	}
+	return &rec, nil
+}
+
+// Release deletes owner's lease on key.
+func (b *RedisBackend) Release(ctx context.Context, key, owner string) error {
+	_, err := b.do(ctx, "EVAL", releaseScript, "1", b.key(key), owner)
+	return err
+}
+
+func (b *RedisBackend) key(key string) string {
+	if b.Prefix == "" {
+		return "ytsync:lease:" + key
+	}
+	return b.Prefix + key
+}
+
+// do runs a command on a new connection, after authenticating and
+// selecting the database as configured.
+func (b *RedisBackend) do(ctx context.Context, args ...string) (any, error) {
+	timeout := b.DialTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", b.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("lease: connect to redis: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	var cmds [][]string
+	if b.Password != "" {
+		cmds = append(cmds, []string{"AUTH", b.Password})
+	}
+	if b.DB != 0 {
+		cmds = append(cmds, []string{"SELECT", strconv.Itoa(b.DB)})
+	}
+	cmds = append(cmds, args)
+
+	r := bufio.NewReader(conn)
+	var reply any
+	for _, cmd := range cmds {
+		if _, err := conn.Write(encodeCommand(cmd)); err != nil {
+			return nil, fmt.Errorf("lease: redis %s: %w", cmd[0], err)
+		}
+		if reply, err = readReply(r); err != nil {
+			return nil, fmt.Errorf("lease: redis %s: %w", cmd[0], err)
+		}
+	}
+	return reply, nil
+}
+
+// encodeCommand encodes args as a RESP array of bulk strings.
+func encodeCommand(args []string) []byte {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, "$"+strconv.Itoa(len(arg))+"\r\n"+arg+"\r\n"...)
+	}
+	return buf
+}
+
+// errRedisReply wraps error replies of the server.
+var errRedisReply = errors.New("server error")
+
+// readReply reads one RESP reply: a string, an int64, nil, or a []any of
+// these. Error replies are returned as errors.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, fmt.Errorf("%w: %s", errRedisReply, body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("malformed reply %q", line)
+}
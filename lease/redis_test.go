@@ -0,0 +1,130 @@
+package lease
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves the scripts of RedisBackend from a map, without expiry.
+type fakeRedis struct {
+	mu       sync.Mutex
+	keys     map[string]string
+	commands []string
+}
+
+func newFakeRedis(t *testing.T) (*fakeRedis, string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	f := &fakeRedis{keys: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f, ln.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		items := reply.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i] = item.(string)
+		}
+		conn.Write(f.handle(args))
+	}
+}
+
+func (f *fakeRedis) handle(args []string) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commands = append(f.commands, args[0])
+	switch {
+	case args[0] == "AUTH" || args[0] == "SELECT":
+		return []byte("+OK\r\n")
+	case args[0] == "EVAL" && args[1] == acquireScript:
+		key, owner := args[3], args[4]
+		if v, ok := f.keys[key]; ok && v != owner {
+			return []byte("*3\r\n:0\r\n" + bulk(v) + ":30000\r\n")
+		}
+		f.keys[key] = owner
+		return []byte("*3\r\n:1\r\n" + bulk(owner) + ":" + args[5] + "\r\n")
+	case args[0] == "EVAL" && args[1] == releaseScript:
+		key, owner := args[3], args[4]
+		if f.keys[key] == owner {
+			delete(f.keys, key)
+			return []byte(":1\r\n")
+		}
+		return []byte(":0\r\n")
+	}
+	return []byte("-ERR unknown command\r\n")
+}
+
+func bulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+func TestRedisBackend(t *testing.T) {
+	fake, addr := newFakeRedis(t)
+	backend := &RedisBackend{Addr: addr, Password: "secret", DB: 2}
+	ctx := context.Background()
+
+	start := time.Now()
+	rec, err := backend.Acquire(ctx, "channel/UCa", "a", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if rec.Owner != "a" || rec.Expires.Before(start.Add(time.Minute)) {
+		t.Errorf("Acquire() = %+v", rec)
+	}
+	if fake.keys["ytsync:lease:channel/UCa"] != "a" {
+		t.Errorf("keys = %v", fake.keys)
+	}
+	if got := fake.commands[:3]; got[0] != "AUTH" || got[1] != "SELECT" || got[2] != "EVAL" {
+		t.Errorf("commands = %v, want AUTH, SELECT, EVAL", got)
+	}
+
+	_, err = backend.Acquire(ctx, "channel/UCa", "b", time.Minute)
+	var held *HeldError
+	if !errors.As(err, &held) || held.Holder.Owner != "a" {
+		t.Fatalf("Acquire() of a held lease error = %v, want a *HeldError naming a", err)
+	}
+
+	// Only the holder's release deletes the lease
+	if err := backend.Release(ctx, "channel/UCa", "b"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if err := backend.Release(ctx, "channel/UCa", "a"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := backend.Acquire(ctx, "channel/UCa", "b", time.Minute); err != nil {
+		t.Errorf("Acquire() after Release() error = %v", err)
+	}
+}
+
+func TestRedisBackendServerError(t *testing.T) {
+	_, addr := newFakeRedis(t)
+	backend := &RedisBackend{Addr: addr}
+	if _, err := backend.do(context.Background(), "PING"); !errors.Is(err, errRedisReply) {
+		t.Errorf("do() error = %v, want a server error", err)
+	}
+}
@@ -0,0 +1,226 @@
+// Package library manages an on-disk media library of synced channels, laid
+// out the way media servers such as Jellyfin and Plex expect:
+//
+//	<root>/
+//	    Channel Name [UCxxxxxxxxxxxxxxxxxxxxxx]/
+//	        channel.json
+//	        2024-01-02 Video Title [dQw4w9WgXcQ]/
+//	            2024-01-02 Video Title [dQw4w9WgXcQ].mp4
+//	            2024-01-02 Video Title [dQw4w9WgXcQ].info.json
+//	            2024-01-02 Video Title [dQw4w9WgXcQ].en.srt
+//	            2024-01-02 Video Title [dQw4w9WgXcQ].jpg
+//
+// Every channel and video folder ends in its YouTube ID in brackets, so
+// Scan recognizes folders by ID even after a title changes. Reconcile
+// compares a scan with a store and reports orphaned folders and missing
+// files.
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+	"ytsync/storage"
+	"ytsync/youtube"
+)
+
+const (
+	// ChannelInfoFile is the name of the channel metadata sidecar in each
+	// channel folder.
+	ChannelInfoFile = "channel.json"
+	// InfoSuffix ends the name of a video's metadata sidecar.
+	InfoSuffix = ".info.json"
+
+	// maxTitleBytes bounds the title part of a folder name, leaving room
+	// for the date, ID, and file suffixes within the usual 255-byte limit.
+	maxTitleBytes = 150
+)
+
+// Library is a media library rooted at a directory.
+type Library struct {
+	// Root is the library directory.
+	Root string
+}
+
+// New returns the library rooted at root.
+func New(root string) *Library {
+	return &Library{Root: root}
+}
+
+// ChannelDir returns the folder of a channel.
+func (l *Library) ChannelDir(ch *storage.Channel) string {
+	return filepath.Join(l.Root, folderName(ch.Name, ch.YouTubeID))
+}
+
+// VideoDir returns the folder of a video of channel ch.
+func (l *Library) VideoDir(ch *storage.Channel, v *storage.Video) string {
+	return filepath.Join(l.ChannelDir(ch), videoBase(v))
+}
+
+// VideoPath returns the path of a file of a video: the video folder joined
+// with the folder name and suffix, such as ".mp4" or ".en.srt".
+func (l *Library) VideoPath(ch *storage.Channel, v *storage.Video, suffix string) string {
+	return filepath.Join(l.VideoDir(ch, v), videoBase(v)+suffix)
+}
+
+// videoBase returns the folder name of a video, which is also the base
+// name of its files.
+func videoBase(v *storage.Video) string {
+	title := v.Title
+	if !v.PublishedAt.IsZero() {
+		title = v.PublishedAt.UTC().Format("2006-01-02") + " " + title
+	}
+	return folderName(title, v.YouTubeID)
+}
+
+// folderName returns "<name> [<id>]" with name made safe for file systems.
+func folderName(name, id string) string {
+	name = truncate(sanitize(name), maxTitleBytes)
+	if name == "" {
+		return "[" + id + "]"
+	}
+	return name + " [" + id + "]"
+}
+
+// sanitize replaces path separators and characters reserved on common file
+// systems, drops control characters, and trims the dots and spaces Windows
+// rejects at the ends of names.
+func sanitize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Trim(s, " .")
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8
+// sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return strings.TrimRight(s[:n], " .")
+}
+
+// channelInfo is the channel.json sidecar.
+type channelInfo struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Handle      string `json:"handle,omitempty"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// videoInfo is the .info.json sidecar of a video.
+type videoInfo struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	ChannelID   string    `json:"channel_id"`
+	Channel     string    `json:"channel"`
+	PublishedAt time.Time `json:"published_at"`
+	Duration    int       `json:"duration"`
+	URL         string    `json:"url"`
+}
+
+// WriteChannelInfo writes the channel.json sidecar of a channel and returns
+// its path.
+func (l *Library) WriteChannelInfo(ch *storage.Channel) (string, error) {
+	path := filepath.Join(l.ChannelDir(ch), ChannelInfoFile)
+	info := channelInfo{
+		ID:          ch.YouTubeID,
+		Name:        ch.Name,
+		Handle:      ch.Handle,
+		URL:         ch.URL,
+		Description: ch.Description,
+	}
+	return path, writeJSON(path, info)
+}
+
+// WriteVideoInfo writes the .info.json sidecar of a video and returns its
+// path.
+func (l *Library) WriteVideoInfo(ch *storage.Channel, v *storage.Video) (string, error) {
+	path := l.VideoPath(ch, v, InfoSuffix)
+	info := videoInfo{
+		ID:          v.YouTubeID,
+		Title:       v.Title,
+		Description: v.Description,
+		ChannelID:   ch.YouTubeID,
+		Channel:     ch.Name,
+		PublishedAt: v.PublishedAt,
+		Duration:    v.Duration,
+		URL:         "https://www.youtube.com/watch?v=" + v.YouTubeID,
+	}
+	return path, writeJSON(path, info)
+}
+
+// WriteTranscript writes a transcript next to the video and returns its
+// path: "<base>.<lang>.srt" if it has timed segments, which media servers
+// show as subtitles, or "<base>.<lang>.txt" otherwise.
+func (l *Library) WriteTranscript(ch *storage.Channel, v *storage.Video, t *storage.Transcript) (string, error) {
+	lang := sanitize(t.Language)
+	if lang == "" {
+		lang = "und"
+	}
+	if len(t.Segments) == 0 {
+		path := l.VideoPath(ch, v, "."+lang+".txt")
+		return path, writeFile(path, strings.NewReader(t.Content))
+	}
+
+	entries := make([]youtube.TranscriptEntry, len(t.Segments))
+	for i, s := range t.Segments {
+		entries[i] = youtube.TranscriptEntry{Start: s.Start, Duration: s.End - s.Start, Text: s.Text}
+	}
+	srt, err := youtube.NewFormatConverter(entries).ToFormat(youtube.FormatSRT)
+	if err != nil {
+		return "", err
+	}
+	path := l.VideoPath(ch, v, "."+lang+".srt")
+	return path, writeFile(path, strings.NewReader(srt))
+}
+
+// WriteThumbnail copies a thumbnail image from r next to the video and
+// returns its path. ext is the image extension, such as "jpg" or "webp".
+func (l *Library) WriteThumbnail(ch *storage.Channel, v *storage.Video, r io.Reader, ext string) (string, error) {
+	path := l.VideoPath(ch, v, "."+strings.TrimPrefix(ext, "."))
+	return path, writeFile(path, r)
+}
+
+// writeJSON writes v as indented JSON to path.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", filepath.Base(path), err)
+	}
+	return writeFile(path, strings.NewReader(string(data)+"\n"))
+}
+
+// writeFile atomically replaces path with the contents of r, creating its
+// folder if needed.
+func writeFile(path string, r io.Reader) error {
+	w, err := storage.NewAtomicWriter(path)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Abort()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := w.Commit(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
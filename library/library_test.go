@@ -0,0 +1,180 @@
+package library
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"ytsync/storage"
+	"ytsync/ytsynctest"
+)
+
+const testChannelID = "UCsXVk37bltHxD1rDPwtNM8Q"
+
+func testChannel() *storage.Channel {
+	return &storage.Channel{YouTubeID: testChannelID, Name: "Kurz: gesagt", URL: "https://www.youtube.com/@kurzgesagt"}
+}
+
+func TestPaths(t *testing.T) {
+	lib := New("/lib")
+	ch := testChannel()
+	v := &storage.Video{
+		YouTubeID:   "dQw4w9WgXcQ",
+		Title:       "What? A/B test. ",
+		PublishedAt: time.Date(2024, 1, 2, 23, 0, 0, 0, time.UTC),
+	}
+
+	wantDir := filepath.Join("/lib", "Kurz_ gesagt ["+testChannelID+"]", "2024-01-02 What_ A_B test [dQw4w9WgXcQ]")
+	if got := lib.VideoDir(ch, v); got != wantDir {
+		t.Errorf("VideoDir() = %q, want %q", got, wantDir)
+	}
+	if got, want := lib.VideoPath(ch, v, ".en.srt"), filepath.Join(wantDir, "2024-01-02 What_ A_B test [dQw4w9WgXcQ].en.srt"); got != want {
+		t.Errorf("VideoPath() = %q, want %q", got, want)
+	}
+
+	v.Title = strings.Repeat("é", 200)
+	base := filepath.Base(lib.VideoDir(ch, v))
+	if len(base) > 255 || !strings.HasSuffix(base, " [dQw4w9WgXcQ]") || !videoFolderPattern.MatchString(base) {
+		t.Errorf("long title folder = %q (%d bytes)", base, len(base))
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := map[string]FileKind{
+		"a [id].mp4":       KindMedia,
+		"a [id].OPUS":      KindMedia,
+		"a [id].info.json": KindMetadata,
+		"movie.nfo":        KindMetadata,
+		"a [id].en.srt":    KindTranscript,
+		"a [id].de.txt":    KindTranscript,
+		"a [id].webp":      KindThumbnail,
+		"a [id].mp4.part":  KindOther,
+		"notes.json":       KindOther,
+	}
+	for name, want := range tests {
+		if got := Classify(name); got != want {
+			t.Errorf("Classify(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestWriteAndReconcile(t *testing.T) {
+	ctx := context.Background()
+	store := ytsynctest.NewMemoryStore()
+	ch := testChannel()
+	if err := store.CreateChannel(ctx, ch); err != nil {
+		t.Fatal(err)
+	}
+	complete := &storage.Video{YouTubeID: "aaaaaaaaaaa", ChannelID: ch.ID, Title: "Complete", PublishedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	partial := &storage.Video{YouTubeID: "bbbbbbbbbbb", ChannelID: ch.ID, Title: "Partial"}
+	absent := &storage.Video{YouTubeID: "ccccccccccc", ChannelID: ch.ID, Title: "Absent"}
+	for _, v := range []*storage.Video{complete, partial, absent} {
+		if err := store.CreateVideo(ctx, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.CreateTranscript(ctx, &storage.Transcript{
+		VideoID:  complete.ID,
+		Language: "en",
+		Segments: []storage.Segment{{Start: 0, End: 1.5, Text: "Hello"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	lib := New(t.TempDir())
+	if _, err := lib.WriteChannelInfo(ch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lib.WriteVideoInfo(ch, complete); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lib.WriteThumbnail(ch, complete, strings.NewReader("jpeg"), "jpg"); err != nil {
+		t.Fatal(err)
+	}
+	transcript, _ := store.GetTranscript(ctx, complete.ID)
+	srtPath, err := lib.WriteTranscript(ch, complete, transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(srtPath, ".en.srt") {
+		t.Errorf("transcript path = %s, want an .en.srt file", srtPath)
+	}
+	if data, _ := os.ReadFile(srtPath); !strings.Contains(string(data), "00:00:00,000 --> 00:00:01,500") {
+		t.Errorf("srt = %q", data)
+	}
+	writeTestFile(t, lib.VideoPath(ch, complete, ".mp4"))
+	if _, err := lib.WriteVideoInfo(ch, partial); err != nil {
+		t.Fatal(err)
+	}
+
+	// A folder of a deleted video, a folder of an untracked channel, and
+	// a stray file.
+	orphanVideo := filepath.Join(lib.ChannelDir(ch), "Gone [zzzzzzzzzzz]")
+	writeTestFile(t, filepath.Join(orphanVideo, "Gone [zzzzzzzzzzz].mp4"))
+	orphanChannel := filepath.Join(lib.Root, "Other [UCaaaaaaaaaaaaaaaaaaaaaa]")
+	writeTestFile(t, filepath.Join(orphanChannel, ChannelInfoFile))
+	stray := filepath.Join(lib.Root, "notes.txt")
+	writeTestFile(t, stray)
+
+	report, err := lib.Reconcile(ctx, store, &ReconcileOptions{Expect: []FileKind{KindMedia, KindMetadata, KindTranscript}})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if report.Videos != 3 {
+		t.Errorf("Videos = %d, want 3", report.Videos)
+	}
+
+	missing := make(map[string][]FileKind)
+	for _, m := range report.Missing {
+		missing[m.VideoID] = append(missing[m.VideoID], m.Kind)
+	}
+	if len(missing[complete.YouTubeID]) != 0 {
+		t.Errorf("complete video missing %v", missing[complete.YouTubeID])
+	}
+	if got := missing[partial.YouTubeID]; len(got) != 1 || got[0] != KindMedia {
+		t.Errorf("partial video missing %v, want [media]", got)
+	}
+	if got := missing[absent.YouTubeID]; len(got) != 2 {
+		t.Errorf("absent video missing %v, want [media metadata]", got)
+	}
+
+	if len(report.Orphans) != 2 {
+		t.Fatalf("Orphans = %+v, want 2", report.Orphans)
+	}
+	for _, o := range report.Orphans {
+		switch o.Path {
+		case orphanVideo:
+			if o.Reason != "unknown video" {
+				t.Errorf("orphan video reason = %q", o.Reason)
+			}
+		case orphanChannel:
+			if o.Reason != "unknown channel" {
+				t.Errorf("orphan channel reason = %q", o.Reason)
+			}
+		default:
+			t.Errorf("unexpected orphan %+v", o)
+		}
+	}
+	if len(report.Unrecognized) != 1 || report.Unrecognized[0] != stray {
+		t.Errorf("Unrecognized = %v, want [%s]", report.Unrecognized, stray)
+	}
+}
+
+func TestScan_MissingRoot(t *testing.T) {
+	result, err := New(filepath.Join(t.TempDir(), "missing")).Scan()
+	if err != nil || len(result.Channels) != 0 {
+		t.Errorf("Scan() = %+v, %v, want an empty library", result, err)
+	}
+}
+
+func writeTestFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
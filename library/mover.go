@@ -0,0 +1,76 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"ytsync/storage"
+	"ytsync/youtube"
+)
+
+// Mover is a youtube.PostProcessor that moves each download, with its
+// metadata sidecar if it has one, into the video's folder in a library.
+// It needs the video metadata to name the folders. Run it before
+// post-processors that write files next to the download, such as a
+// youtube.ChecksumProcessor with WriteFile, so they write into the library.
+type Mover struct {
+	// Library is the library downloads are moved into.
+	Library *Library
+}
+
+// NewMover returns a Mover into lib.
+func NewMover(lib *Library) *Mover {
+	return &Mover{Library: lib}
+}
+
+// PostProcess moves result.VideoPath and result.MetadataPath into the
+// library and updates them.
+func (m *Mover) PostProcess(ctx context.Context, result *youtube.DownloadResult) error {
+	meta := result.Metadata
+	if meta == nil {
+		return fmt.Errorf("move into library: %w", youtube.ErrNoMetadata)
+	}
+	ch := &storage.Channel{YouTubeID: meta.UploaderID, Name: meta.Uploader}
+	v := videoFromMetadata(meta)
+
+	dst := m.Library.VideoPath(ch, v, strings.ToLower(filepath.Ext(result.VideoPath)))
+	if err := moveFile(result.VideoPath, dst); err != nil {
+		return err
+	}
+	result.VideoPath = dst
+
+	if result.MetadataPath != "" {
+		dst := m.Library.VideoPath(ch, v, InfoSuffix)
+		if err := moveFile(result.MetadataPath, dst); err != nil {
+			return err
+		}
+		result.MetadataPath = dst
+	}
+	return nil
+}
+
+// moveFile moves src to dst, creating dst's folder, and copies it if they
+// are on different file systems.
+func moveFile(src, dst string) error {
+	if src == dst {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("move %s: %w", src, err)
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("move %s: %w", src, err)
+	}
+	defer f.Close()
+	if err := writeFile(dst, f); err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(src)
+}
@@ -0,0 +1,55 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"ytsync/youtube"
+)
+
+func TestMover(t *testing.T) {
+	dir := t.TempDir()
+	videoPath := filepath.Join(dir, "download", "Video.MP4")
+	metadataPath := filepath.Join(dir, "download", "Video.json")
+	for _, path := range []string{videoPath, metadataPath} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lib := New(filepath.Join(dir, "lib"))
+	result := &youtube.DownloadResult{
+		VideoPath:    videoPath,
+		MetadataPath: metadataPath,
+		Metadata:     &youtube.VideoMetadata{ID: "dQw4w9WgXcQ", Title: "Title", UploadDate: "20240102", Uploader: "Kurz: gesagt", UploaderID: testChannelID},
+	}
+	if err := NewMover(lib).PostProcess(context.Background(), result); err != nil {
+		t.Fatalf("PostProcess() error = %v", err)
+	}
+
+	videoDir := filepath.Join(lib.Root, "Kurz_ gesagt ["+testChannelID+"]", "2024-01-02 Title [dQw4w9WgXcQ]")
+	wantVideo := filepath.Join(videoDir, "2024-01-02 Title [dQw4w9WgXcQ].mp4")
+	wantInfo := filepath.Join(videoDir, "2024-01-02 Title [dQw4w9WgXcQ]"+InfoSuffix)
+	if result.VideoPath != wantVideo || result.MetadataPath != wantInfo {
+		t.Errorf("paths = %q, %q, want %q, %q", result.VideoPath, result.MetadataPath, wantVideo, wantInfo)
+	}
+	if data, err := os.ReadFile(wantVideo); err != nil || string(data) != "Video.MP4" {
+		t.Errorf("moved video = %q, %v", data, err)
+	}
+	if _, err := os.Stat(videoPath); !os.IsNotExist(err) {
+		t.Errorf("download left behind: %v", err)
+	}
+	if scan, err := lib.Scan(); err != nil || len(scan.Channels) != 1 {
+		t.Errorf("Scan() = %+v, %v", scan, err)
+	}
+
+	err := NewMover(lib).PostProcess(context.Background(), &youtube.DownloadResult{VideoPath: wantVideo})
+	if !errors.Is(err, youtube.ErrNoMetadata) {
+		t.Errorf("PostProcess() without metadata = %v, want ErrNoMetadata", err)
+	}
+}
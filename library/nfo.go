@@ -0,0 +1,214 @@
+package library
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"time"
+	"ytsync/storage"
+	"ytsync/youtube"
+)
+
+// NFOStyle selects how media servers are told to present videos.
+type NFOStyle string
+
+const (
+	// NFOEpisode presents each channel as a TV show and its videos as
+	// episodes, with the upload year as season. This is the default.
+	NFOEpisode NFOStyle = "episode"
+	// NFOMovie presents each video as a movie.
+	NFOMovie NFOStyle = "movie"
+)
+
+// TVShowNFOFile is the name of the show NFO written to channel folders.
+const TVShowNFOFile = "tvshow.nfo"
+
+// NFOExporter writes Kodi-style .nfo metadata files, which Kodi and
+// Jellyfin read, next to the media in a library.
+type NFOExporter struct {
+	// Library is the library the files are written to.
+	Library *Library
+	// Style is the kind of NFO written for videos (default NFOEpisode).
+	Style NFOStyle
+	// Template, if set, names video NFO files relative to the video
+	// folder; it is rendered with Ext "nfo". By default the NFO takes the
+	// base name of the media file, as Jellyfin expects, e.g.
+	// "2024-01-02 Title [dQw4w9WgXcQ].nfo". Use "movie.{{.Ext}}" for
+	// Kodi's one-movie-per-folder naming.
+	Template *youtube.OutputTemplate
+}
+
+// NewNFOExporter returns an exporter writing episode NFOs into lib.
+func NewNFOExporter(lib *Library) *NFOExporter {
+	return &NFOExporter{Library: lib, Style: NFOEpisode}
+}
+
+func (e *NFOExporter) style() NFOStyle {
+	if e.Style == "" {
+		return NFOEpisode
+	}
+	return e.Style
+}
+
+// nfoUniqueID is an external ID of a show, episode, or movie.
+type nfoUniqueID struct {
+	Type    string `xml:"type,attr"`
+	Default bool   `xml:"default,attr"`
+	Value   string `xml:",chardata"`
+}
+
+// nfoThumb is an artwork URL.
+type nfoThumb struct {
+	Aspect string `xml:"aspect,attr,omitempty"`
+	URL    string `xml:",chardata"`
+}
+
+// tvShowNFO is the <tvshow> document of a channel.
+type tvShowNFO struct {
+	XMLName  xml.Name    `xml:"tvshow"`
+	Title    string      `xml:"title"`
+	Plot     string      `xml:"plot,omitempty"`
+	UniqueID nfoUniqueID `xml:"uniqueid"`
+	Studio   string      `xml:"studio,omitempty"`
+}
+
+// videoNFO is the <episodedetails> or <movie> document of a video; the two
+// share their fields.
+type videoNFO struct {
+	XMLName   xml.Name    `xml:""`
+	Title     string      `xml:"title"`
+	ShowTitle string      `xml:"showtitle,omitempty"`
+	Plot      string      `xml:"plot,omitempty"`
+	Season    int         `xml:"season,omitempty"`
+	Episode   int         `xml:"episode,omitempty"`
+	Aired     string      `xml:"aired,omitempty"`
+	Premiered string      `xml:"premiered,omitempty"`
+	Year      int         `xml:"year,omitempty"`
+	Runtime   int         `xml:"runtime,omitempty"` // minutes
+	UniqueID  nfoUniqueID `xml:"uniqueid"`
+	Studio    string      `xml:"studio,omitempty"`
+	Genres    []string    `xml:"genre,omitempty"`
+	Tags      []string    `xml:"tag,omitempty"`
+	Thumb     *nfoThumb   `xml:"thumb,omitempty"`
+	Trailer   string      `xml:"trailer,omitempty"`
+}
+
+// WriteChannelNFO writes the tvshow.nfo of a channel into its folder and
+// returns its path. Only the episode style uses it.
+func (e *NFOExporter) WriteChannelNFO(info *youtube.ChannelInfo) (string, error) {
+	doc := tvShowNFO{
+		Title:    info.Name,
+		Plot:     info.Description,
+		UniqueID: nfoUniqueID{Type: "youtube", Default: true, Value: info.ID},
+		Studio:   "YouTube",
+	}
+	path := filepath.Join(e.Library.ChannelDir(channelFromInfo(info)), TVShowNFOFile)
+	return path, writeNFO(path, doc)
+}
+
+// WriteVideoNFO writes the NFO of a video into its folder and returns its
+// path.
+func (e *NFOExporter) WriteVideoNFO(info *youtube.ChannelInfo, m *youtube.VideoMetadata) (string, error) {
+	ch := channelFromInfo(info)
+	v := videoFromMetadata(m)
+
+	path := e.Library.VideoPath(ch, v, ".nfo")
+	if e.Template != nil {
+		name, err := e.Template.Render(youtube.TemplateDataFromMetadata(m, "nfo"))
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(e.Library.VideoDir(ch, v), name)
+	}
+	return path, writeNFO(path, videoNFODoc(m, info.Name, e.style()))
+}
+
+// MarshalVideoNFO returns the NFO document of a video in the given style,
+// for callers that place NFO files themselves. channelName is the show
+// title and studio.
+func MarshalVideoNFO(m *youtube.VideoMetadata, channelName string, style NFOStyle) ([]byte, error) {
+	return marshalNFO(videoNFODoc(m, channelName, style))
+}
+
+// videoNFODoc builds the NFO document of a video.
+func videoNFODoc(m *youtube.VideoMetadata, channelName string, style NFOStyle) videoNFO {
+	doc := videoNFO{
+		Title:    m.Title,
+		Plot:     m.Description,
+		Runtime:  (m.Duration + 59) / 60,
+		UniqueID: nfoUniqueID{Type: "youtube", Default: true, Value: m.ID},
+		Studio:   channelName,
+		Genres:   m.Categories,
+		Tags:     m.Tags,
+	}
+	if m.ThumbnailURL != "" {
+		doc.Thumb = &nfoThumb{Aspect: "thumb", URL: m.ThumbnailURL}
+	}
+	published, err := time.Parse("20060102", m.UploadDate)
+	if err == nil {
+		doc.Aired = published.Format("2006-01-02")
+		doc.Premiered = doc.Aired
+		doc.Year = published.Year()
+	}
+
+	if style == NFOMovie {
+		doc.XMLName = xml.Name{Local: "movie"}
+		doc.Aired = ""
+		doc.Trailer = "plugin://plugin.video.youtube/play/?video_id=" + m.ID
+		return doc
+	}
+	doc.XMLName = xml.Name{Local: "episodedetails"}
+	doc.ShowTitle = channelName
+	if err == nil {
+		// Seasons are years and episodes are the month and day, so
+		// episodes sort by upload date: 2024-01-02 is S2024E102.
+		doc.Season = published.Year()
+		doc.Episode = int(published.Month())*100 + published.Day()
+	}
+	return doc
+}
+
+// nfoHeader starts every NFO document.
+const nfoHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// marshalNFO encodes doc as an NFO document.
+func marshalNFO(doc any) ([]byte, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal nfo: %w", err)
+	}
+	return append(append([]byte(nfoHeader), data...), '\n'), nil
+}
+
+// writeNFO writes doc as an NFO document to path.
+func writeNFO(path string, doc any) error {
+	data, err := marshalNFO(doc)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, bytes.NewReader(data))
+}
+
+// channelFromInfo returns the storage record of a channel for its paths.
+func channelFromInfo(info *youtube.ChannelInfo) *storage.Channel {
+	return &storage.Channel{
+		YouTubeID:   info.ID,
+		Name:        info.Name,
+		Handle:      info.Handle,
+		URL:         info.URL,
+		Description: info.Description,
+	}
+}
+
+// videoFromMetadata returns the storage record of a video for its paths.
+func videoFromMetadata(m *youtube.VideoMetadata) *storage.Video {
+	published, _ := time.Parse("20060102", m.UploadDate)
+	return &storage.Video{
+		YouTubeID:   m.ID,
+		Title:       m.Title,
+		Description: m.Description,
+		PublishedAt: published,
+		Duration:    m.Duration,
+	}
+}
@@ -0,0 +1,95 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"ytsync/youtube"
+)
+
+func testNFOMetadata() *youtube.VideoMetadata {
+	return &youtube.VideoMetadata{
+		ID:           "dQw4w9WgXcQ",
+		Title:        "Rock & Roll",
+		Description:  "A <classic>.",
+		Duration:     212,
+		UploadDate:   "20240102",
+		ThumbnailURL: "https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg",
+		Categories:   []string{"Music"},
+		Tags:         []string{"rick", "astley"},
+	}
+}
+
+func TestNFOExporter_Episode(t *testing.T) {
+	lib := New(t.TempDir())
+	exporter := NewNFOExporter(lib)
+	info := &youtube.ChannelInfo{ID: testChannelID, Name: "Rick Astley", Description: "Official channel"}
+
+	showPath, err := exporter.WriteChannelNFO(info)
+	if err != nil {
+		t.Fatalf("WriteChannelNFO() error = %v", err)
+	}
+	show, _ := os.ReadFile(showPath)
+	for _, want := range []string{"<tvshow>", "<title>Rick Astley</title>", `<uniqueid type="youtube" default="true">` + testChannelID + "</uniqueid>"} {
+		if !strings.Contains(string(show), want) {
+			t.Errorf("tvshow.nfo missing %q:\n%s", want, show)
+		}
+	}
+
+	path, err := exporter.WriteVideoNFO(info, testNFOMetadata())
+	if err != nil {
+		t.Fatalf("WriteVideoNFO() error = %v", err)
+	}
+	if want := "2024-01-02 Rock & Roll [dQw4w9WgXcQ].nfo"; filepath.Base(path) != want {
+		t.Errorf("NFO name = %q, want %q", filepath.Base(path), want)
+	}
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`,
+		"<episodedetails>",
+		"<title>Rock &amp; Roll</title>",
+		"<showtitle>Rick Astley</showtitle>",
+		"<plot>A &lt;classic&gt;.</plot>",
+		"<season>2024</season>",
+		"<episode>102</episode>",
+		"<aired>2024-01-02</aired>",
+		"<runtime>4</runtime>",
+		"<genre>Music</genre>",
+		"<tag>astley</tag>",
+		`<thumb aspect="thumb">https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg</thumb>`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("episode NFO missing %q:\n%s", want, data)
+		}
+	}
+
+	scan, err := lib.Scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scan.Unrecognized) != 0 || len(scan.Channels) != 1 || !scan.Channels[0].Videos[0].Has(KindMetadata) {
+		t.Errorf("Scan() = %+v, want the NFO recognized as metadata", scan)
+	}
+}
+
+func TestNFOExporter_MovieTemplate(t *testing.T) {
+	lib := New(t.TempDir())
+	exporter := &NFOExporter{
+		Library:  lib,
+		Style:    NFOMovie,
+		Template: youtube.MustParseOutputTemplate("movie.{{.Ext}}"),
+	}
+
+	path, err := exporter.WriteVideoNFO(&youtube.ChannelInfo{ID: testChannelID, Name: "Rick Astley"}, testNFOMetadata())
+	if err != nil {
+		t.Fatalf("WriteVideoNFO() error = %v", err)
+	}
+	if filepath.Base(path) != "movie.nfo" || !videoFolderPattern.MatchString(filepath.Base(filepath.Dir(path))) {
+		t.Errorf("NFO path = %q, want movie.nfo in the video folder", path)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "<movie>") || strings.Contains(string(data), "<season>") || !strings.Contains(string(data), "<year>2024</year>") {
+		t.Errorf("movie NFO:\n%s", data)
+	}
+}
@@ -0,0 +1,301 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"ytsync/storage"
+)
+
+// FileKind classifies the files of a video folder.
+type FileKind string
+
+const (
+	// KindMedia is a video or audio file.
+	KindMedia FileKind = "media"
+	// KindMetadata is the .info.json sidecar or an NFO file.
+	KindMetadata FileKind = "metadata"
+	// KindTranscript is a transcript or subtitle file.
+	KindTranscript FileKind = "transcript"
+	// KindThumbnail is a thumbnail image.
+	KindThumbnail FileKind = "thumbnail"
+	// KindOther is any other file, such as an unfinished download.
+	KindOther FileKind = "other"
+)
+
+var (
+	mediaExts      = map[string]bool{".mp4": true, ".mkv": true, ".webm": true, ".mov": true, ".m4a": true, ".mp3": true, ".opus": true, ".ogg": true, ".flac": true, ".wav": true, ".aac": true}
+	transcriptExts = map[string]bool{".srt": true, ".vtt": true, ".txt": true, ".ttml": true}
+	thumbnailExts  = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".webp": true}
+
+	channelFolderPattern = regexp.MustCompile(`\[(UC[A-Za-z0-9_-]{22})\]$`)
+	videoFolderPattern   = regexp.MustCompile(`\[([A-Za-z0-9_-]{11})\]$`)
+)
+
+// Classify returns the kind of a file in a video folder from its name.
+func Classify(name string) FileKind {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, InfoSuffix) || strings.HasSuffix(lower, ".nfo") {
+		return KindMetadata
+	}
+	ext := filepath.Ext(lower)
+	switch {
+	case mediaExts[ext]:
+		return KindMedia
+	case transcriptExts[ext]:
+		return KindTranscript
+	case thumbnailExts[ext]:
+		return KindThumbnail
+	default:
+		return KindOther
+	}
+}
+
+// ChannelFolder is a channel folder found by Scan.
+type ChannelFolder struct {
+	// YouTubeID is the channel ID from the folder name.
+	YouTubeID string
+	// Path is the folder.
+	Path string
+	// Videos are the video folders inside it.
+	Videos []*VideoFolder
+}
+
+// VideoFolder is a video folder found by Scan.
+type VideoFolder struct {
+	// YouTubeID is the video ID from the folder name.
+	YouTubeID string
+	// Path is the folder.
+	Path string
+	// Files are the paths of the files in the folder by kind.
+	Files map[FileKind][]string
+}
+
+// Has reports whether the folder holds a file of the given kind.
+func (v *VideoFolder) Has(kind FileKind) bool {
+	return len(v.Files[kind]) > 0
+}
+
+// ScanResult is the contents of a library.
+type ScanResult struct {
+	// Channels are the channel folders, sorted by path.
+	Channels []*ChannelFolder
+	// Unrecognized are folders and files not named like a channel or video
+	// folder, where one was expected.
+	Unrecognized []string
+}
+
+// Scan walks the library and returns its channel and video folders. A
+// missing root is an empty library. Hidden files are ignored.
+func (l *Library) Scan() (*ScanResult, error) {
+	result := &ScanResult{}
+	entries, err := readDir(l.Root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("scan library: %w", err)
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(l.Root, e.Name())
+		m := channelFolderPattern.FindStringSubmatch(e.Name())
+		if !e.IsDir() || m == nil {
+			result.Unrecognized = append(result.Unrecognized, path)
+			continue
+		}
+		channel, err := scanChannel(path, m[1], result)
+		if err != nil {
+			return nil, err
+		}
+		result.Channels = append(result.Channels, channel)
+	}
+	return result, nil
+}
+
+// scanChannel reads the video folders of a channel folder, adding
+// unrecognized entries to result.
+func scanChannel(path, youtubeID string, result *ScanResult) (*ChannelFolder, error) {
+	channel := &ChannelFolder{YouTubeID: youtubeID, Path: path}
+	entries, err := readDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("scan library: %w", err)
+	}
+	for _, e := range entries {
+		videoPath := filepath.Join(path, e.Name())
+		if !e.IsDir() {
+			if e.Name() != ChannelInfoFile && e.Name() != TVShowNFOFile {
+				result.Unrecognized = append(result.Unrecognized, videoPath)
+			}
+			continue
+		}
+		m := videoFolderPattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			result.Unrecognized = append(result.Unrecognized, videoPath)
+			continue
+		}
+
+		video := &VideoFolder{YouTubeID: m[1], Path: videoPath, Files: make(map[FileKind][]string)}
+		files, err := readDir(videoPath)
+		if err != nil {
+			return nil, fmt.Errorf("scan library: %w", err)
+		}
+		for _, f := range files {
+			if f.IsDir() {
+				continue
+			}
+			kind := Classify(f.Name())
+			video.Files[kind] = append(video.Files[kind], filepath.Join(videoPath, f.Name()))
+		}
+		channel.Videos = append(channel.Videos, video)
+	}
+	return channel, nil
+}
+
+// readDir returns the entries of dir that are not hidden, sorted by name.
+func readDir(dir string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	visible := entries[:0]
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), ".") {
+			visible = append(visible, e)
+		}
+	}
+	return visible, nil
+}
+
+// Orphan is a library folder with no matching record in the store.
+type Orphan struct {
+	// Path is the folder.
+	Path string
+	// YouTubeID is the channel or video ID from the folder name.
+	YouTubeID string
+	// Reason is "unknown channel", "unknown video", or "duplicate" for a
+	// second folder of the same video.
+	Reason string
+}
+
+// MissingFile is a file a stored video should have in the library but
+// does not.
+type MissingFile struct {
+	// ChannelID and VideoID are the YouTube IDs of the video.
+	ChannelID string
+	VideoID   string
+	// Title is the video title.
+	Title string
+	// Kind is the kind of file missing.
+	Kind FileKind
+	// Dir is the video folder the file belongs in.
+	Dir string
+}
+
+// ReconcileOptions configures Reconcile.
+type ReconcileOptions struct {
+	// Expect lists the kinds of file every stored video should have.
+	// KindTranscript is only expected of videos with a transcript in the
+	// store. Default: KindMedia and KindMetadata.
+	Expect []FileKind
+}
+
+func (o *ReconcileOptions) expect() []FileKind {
+	if o == nil || len(o.Expect) == 0 {
+		return []FileKind{KindMedia, KindMetadata}
+	}
+	return o.Expect
+}
+
+// Report is the result of Reconcile.
+type Report struct {
+	// Videos is the number of stored videos checked.
+	Videos int
+	// Orphans are folders whose channel or video is not in the store.
+	Orphans []Orphan
+	// Missing are the expected files of stored videos that are not in the
+	// library, including every expected file of videos with no folder.
+	Missing []MissingFile
+	// Unrecognized are the unrecognized entries from the scan.
+	Unrecognized []string
+}
+
+// Reconcile scans the library and compares it with the channels and videos
+// in store. Video folders are matched by video ID wherever they are, so a
+// folder left behind by a title change still counts.
+func (l *Library) Reconcile(ctx context.Context, store storage.Store, opts *ReconcileOptions) (*Report, error) {
+	scan, err := l.Scan()
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Unrecognized: scan.Unrecognized}
+
+	channels, err := store.ListChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile library: %w", err)
+	}
+	knownChannels := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		knownChannels[ch.YouTubeID] = true
+	}
+
+	folders := make(map[string]*VideoFolder)
+	for _, cf := range scan.Channels {
+		if !knownChannels[cf.YouTubeID] {
+			report.Orphans = append(report.Orphans, Orphan{Path: cf.Path, YouTubeID: cf.YouTubeID, Reason: "unknown channel"})
+			continue
+		}
+		for _, vf := range cf.Videos {
+			if _, dup := folders[vf.YouTubeID]; dup {
+				report.Orphans = append(report.Orphans, Orphan{Path: vf.Path, YouTubeID: vf.YouTubeID, Reason: "duplicate"})
+				continue
+			}
+			folders[vf.YouTubeID] = vf
+		}
+	}
+
+	expect := opts.expect()
+	known := make(map[string]bool)
+	for _, ch := range channels {
+		videos, err := store.ListVideosByChannel(ctx, ch.ID)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile library: %w", err)
+		}
+		for _, v := range videos {
+			known[v.YouTubeID] = true
+			report.Videos++
+
+			folder := folders[v.YouTubeID]
+			dir := l.VideoDir(ch, v)
+			if folder != nil {
+				dir = folder.Path
+			}
+			for _, kind := range expect {
+				if kind == KindTranscript && !v.HasTranscript {
+					continue
+				}
+				if folder == nil || !folder.Has(kind) {
+					report.Missing = append(report.Missing, MissingFile{
+						ChannelID: ch.YouTubeID,
+						VideoID:   v.YouTubeID,
+						Title:     v.Title,
+						Kind:      kind,
+						Dir:       dir,
+					})
+				}
+			}
+		}
+	}
+
+	for id, vf := range folders {
+		if !known[id] {
+			report.Orphans = append(report.Orphans, Orphan{Path: vf.Path, YouTubeID: id, Reason: "unknown video"})
+		}
+	}
+	sort.Slice(report.Orphans, func(i, j int) bool { return report.Orphans[i].Path < report.Orphans[j].Path })
+	return report, nil
+}
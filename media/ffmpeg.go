@@ -0,0 +1,180 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"ytsync/proc"
+)
+
+// FFmpeg implements Tool by running the ffmpeg and ffprobe executables.
+type FFmpeg struct {
+	// FFmpegPath is the ffmpeg executable. If empty, uses "ffmpeg" from PATH.
+	FFmpegPath string
+	// FFprobePath is the ffprobe executable. If empty, uses "ffprobe" from PATH.
+	FFprobePath string
+}
+
+var _ Tool = (*FFmpeg)(nil)
+
+// New returns an FFmpeg using the given executables. Empty paths use the
+// binaries from PATH. Use Detect to search common install locations.
+func New(ffmpegPath, ffprobePath string) *FFmpeg {
+	return &FFmpeg{FFmpegPath: ffmpegPath, FFprobePath: ffprobePath}
+}
+
+func (f *FFmpeg) ffmpeg() string {
+	if f.FFmpegPath == "" {
+		return "ffmpeg"
+	}
+	return f.FFmpegPath
+}
+
+func (f *FFmpeg) ffprobe() string {
+	if f.FFprobePath == "" {
+		return "ffprobe"
+	}
+	return f.FFprobePath
+}
+
+// Version returns the ffmpeg version.
+func (f *FFmpeg) Version(ctx context.Context) (Version, error) {
+	stdout, err := run(ctx, f.ffmpeg(), "-hide_banner", "-version")
+	if err != nil {
+		return Version{}, err
+	}
+	v := parseVersion(strings.SplitN(stdout, "\n", 2)[0])
+	if v.Raw == "" {
+		return Version{}, fmt.Errorf("media: unrecognized ffmpeg version output %q", strings.TrimSpace(stdout))
+	}
+	return v, nil
+}
+
+// Duration returns the media duration of path in seconds. It uses ffprobe
+// and falls back to parsing ffmpeg's stream summary if ffprobe is not installed.
+func (f *FFmpeg) Duration(ctx context.Context, path string) (float64, error) {
+	stdout, err := run(ctx, f.ffprobe(),
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		path,
+	)
+	if errors.Is(err, ErrNotInstalled) {
+		return f.durationFromFFmpeg(ctx, path)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	duration, err := strconv.ParseFloat(strings.TrimSpace(stdout), 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse duration: %w", err)
+	}
+	return duration, nil
+}
+
+var ffmpegDurationPattern = regexp.MustCompile(`Duration: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+
+// durationFromFFmpeg reads the duration from the summary ffmpeg prints when
+// given an input and no output. ffmpeg exits non-zero in that case, so only
+// a missing binary or missing duration is treated as an error.
+func (f *FFmpeg) durationFromFFmpeg(ctx context.Context, path string) (float64, error) {
+	cmd := proc.Command(ctx, f.ffmpeg(), "-hide_banner", "-i", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil && notInstalled(err) {
+		return 0, fmt.Errorf("%w: %v", ErrNotInstalled, err)
+	}
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+
+	m := ffmpegDurationPattern.FindStringSubmatch(stderr.String())
+	if m == nil {
+		return 0, fmt.Errorf("media: no duration reported for %s", path)
+	}
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.ParseFloat(m[3], 64)
+	return float64(hours*3600+minutes*60) + seconds, nil
+}
+
+// ExtractAudio writes the audio of in to out, re-encoded as described by opts.
+func (f *FFmpeg) ExtractAudio(ctx context.Context, in, out string, opts AudioOptions) error {
+	args := []string{"-y", "-v", "error"}
+	if opts.Start > 0 {
+		args = append(args, "-ss", formatSeconds(opts.Start))
+	}
+	if opts.Duration > 0 {
+		args = append(args, "-t", formatSeconds(opts.Duration))
+	}
+	args = append(args, "-i", in, "-vn")
+	if opts.Channels > 0 {
+		args = append(args, "-ac", strconv.Itoa(opts.Channels))
+	}
+	if opts.SampleRate > 0 {
+		args = append(args, "-ar", strconv.Itoa(opts.SampleRate))
+	}
+	if opts.Codec != "" {
+		args = append(args, "-c:a", opts.Codec)
+	}
+	if opts.Bitrate > 0 {
+		args = append(args, "-b:a", strconv.Itoa(opts.Bitrate)+"k")
+	}
+	args = append(args, out)
+
+	_, err := run(ctx, f.ffmpeg(), args...)
+	return err
+}
+
+// Remux copies all streams of in into out's container without re-encoding.
+func (f *FFmpeg) Remux(ctx context.Context, in, out string) error {
+	_, err := run(ctx, f.ffmpeg(), "-y", "-v", "error", "-i", in, "-map", "0", "-c", "copy", out)
+	return err
+}
+
+// Thumbnail writes the video frame at offset at to the image file out.
+func (f *FFmpeg) Thumbnail(ctx context.Context, in, out string, at time.Duration) error {
+	_, err := run(ctx, f.ffmpeg(), "-y", "-v", "error", "-ss", formatSeconds(at), "-i", in, "-frames:v", "1", out)
+	return err
+}
+
+// formatSeconds formats d as seconds with millisecond precision.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// run executes a media binary and returns its stdout. A missing binary is
+// reported as ErrNotInstalled; other failures include trimmed stderr.
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := proc.Command(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if notInstalled(err) {
+			return "", fmt.Errorf("%w: %v", ErrNotInstalled, err)
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// notInstalled reports whether err means the executable does not exist.
+func notInstalled(err error) bool {
+	return errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist)
+}
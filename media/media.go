@@ -0,0 +1,168 @@
+// Package media wraps ffmpeg and ffprobe for the media processing ytsync
+// needs after a download: duration probing, audio extraction, remuxing,
+// thumbnail frame extraction, and adding subtitles.
+//
+// Callers depend on the Tool interface so tests can substitute a fake:
+//
+//	tool, err := media.Detect()
+//	if errors.Is(err, media.ErrNotInstalled) {
+//		// skip media steps
+//	}
+//	seconds, err := tool.Duration(ctx, "video.mp4")
+package media
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"ytsync/errcode"
+)
+
+// ErrNotInstalled indicates ffmpeg or ffprobe could not be found.
+var ErrNotInstalled = errcode.New(errcode.SubprocessFailure, "media: ffmpeg not installed")
+
+// Prober reads information about media files.
+type Prober interface {
+	// Duration returns the media duration in seconds.
+	Duration(ctx context.Context, path string) (float64, error)
+}
+
+// Processor converts media files.
+type Processor interface {
+	// ExtractAudio writes the audio of in to out, re-encoded as described by opts.
+	ExtractAudio(ctx context.Context, in, out string, opts AudioOptions) error
+	// Remux copies the streams of in into the container implied by out's
+	// extension without re-encoding.
+	Remux(ctx context.Context, in, out string) error
+	// Thumbnail writes a single video frame at offset at to the image file out.
+	Thumbnail(ctx context.Context, in, out string, at time.Duration) error
+}
+
+// Tool is the full set of media operations.
+type Tool interface {
+	Prober
+	Processor
+	Subtitler
+	// Version returns the ffmpeg version.
+	Version(ctx context.Context) (Version, error)
+}
+
+// AudioOptions configures ExtractAudio. Zero values keep the source setting.
+type AudioOptions struct {
+	// Start is the offset to start extracting from.
+	Start time.Duration
+	// Duration limits the extracted length (0 = to the end).
+	Duration time.Duration
+	// Channels is the output channel count (e.g. 1 for mono).
+	Channels int
+	// SampleRate is the output sample rate in Hz.
+	SampleRate int
+	// Codec is the ffmpeg audio codec name (e.g. "pcm_s16le", "flac", "libmp3lame").
+	// Empty lets ffmpeg choose from out's extension.
+	Codec string
+	// Bitrate is the target bitrate in kbps for lossy codecs (0 = codec default).
+	Bitrate int
+}
+
+// Version is a parsed ffmpeg version.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	// Raw is the version string as reported (e.g. "6.1.1-3ubuntu5").
+	Raw string
+}
+
+// String returns the raw version string.
+func (v Version) String() string {
+	return v.Raw
+}
+
+// AtLeast reports whether v is major.minor or newer. Unparseable versions
+// (such as git builds) are assumed to be recent.
+func (v Version) AtLeast(major, minor int) bool {
+	if v.Major == 0 && v.Minor == 0 {
+		return true
+	}
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+var (
+	versionPattern    = regexp.MustCompile(`version n?(\d+)\.(\d+)(?:\.(\d+))?\S*`)
+	rawVersionPattern = regexp.MustCompile(`version (\S+)`)
+)
+
+// parseVersion extracts the version from the first line of `ffmpeg -version`.
+func parseVersion(output string) Version {
+	m := versionPattern.FindStringSubmatch(output)
+	if m == nil {
+		if fields := rawVersionPattern.FindStringSubmatch(output); fields != nil {
+			return Version{Raw: fields[1]}
+		}
+		return Version{}
+	}
+	v := Version{Raw: m[0][len("version "):]}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	return v
+}
+
+// searchDirs are checked after PATH when discovering binaries, since
+// package managers often install outside the PATH of services and IDEs.
+var searchDirs = []string{
+	"/usr/local/bin",
+	"/opt/homebrew/bin",
+	"/usr/bin",
+	"/snap/bin",
+}
+
+// Discover returns the path of the named binary ("ffmpeg" or "ffprobe").
+// It checks, in order: the YTSYNC_<NAME>_PATH environment variable, PATH,
+// and common install locations. Returns ErrNotInstalled if none match.
+func Discover(name string) (string, error) {
+	envVar := "YTSYNC_" + strings.ToUpper(name) + "_PATH"
+	if p := os.Getenv(envVar); p != "" {
+		if isExecutable(p) {
+			return p, nil
+		}
+		return "", fmt.Errorf("%w: %s=%s is not executable", ErrNotInstalled, envVar, p)
+	}
+
+	if p, err := exec.LookPath(name); err == nil {
+		return p, nil
+	}
+	for _, dir := range searchDirs {
+		if p := filepath.Join(dir, name); isExecutable(p) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s not found", ErrNotInstalled, name)
+}
+
+// Detect discovers ffmpeg and ffprobe and returns an FFmpeg using them.
+// ffprobe is optional: if only ffmpeg is found, probing falls back to
+// parsing ffmpeg's output. Returns ErrNotInstalled if neither is found.
+func Detect() (*FFmpeg, error) {
+	ffmpegPath, ffmpegErr := Discover("ffmpeg")
+	ffprobePath, ffprobeErr := Discover("ffprobe")
+	if ffmpegErr != nil && ffprobeErr != nil {
+		return nil, ffmpegErr
+	}
+	return &FFmpeg{FFmpegPath: ffmpegPath, FFprobePath: ffprobePath}, nil
+}
+
+// isExecutable reports whether path is an executable regular file.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+}
@@ -0,0 +1,333 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"ytsync/errcode"
+)
+
+// writeMockScript writes an executable shell script standing in for a media binary.
+func writeMockScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("mock tools require a POSIX shell")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatalf("failed to create mock %s: %v", name, err)
+	}
+	return path
+}
+
+// argsRecorder returns a mock body that logs its arguments to logPath.
+func argsRecorder(logPath string) string {
+	return `echo "$@" > "` + logPath + `"` + "\n"
+}
+
+func readArgs(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read args log: %v", err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   Version
+	}{
+		{
+			name:   "release",
+			output: "ffmpeg version 6.1.1 Copyright (c) 2000-2023 the FFmpeg developers",
+			want:   Version{Major: 6, Minor: 1, Patch: 1, Raw: "6.1.1"},
+		},
+		{
+			name:   "distro suffix",
+			output: "ffmpeg version 4.4.2-0ubuntu0.22.04.1 Copyright (c) 2000-2021",
+			want:   Version{Major: 4, Minor: 4, Patch: 2, Raw: "4.4.2-0ubuntu0.22.04.1"},
+		},
+		{
+			name:   "n prefix without patch",
+			output: "ffmpeg version n7.0 Copyright (c) 2000-2024",
+			want:   Version{Major: 7, Minor: 0, Raw: "n7.0"},
+		},
+		{
+			name:   "git build",
+			output: "ffmpeg version N-113348-g0a5813fc68-20240110 Copyright (c) 2000-2024",
+			want:   Version{Raw: "N-113348-g0a5813fc68-20240110"},
+		},
+		{
+			name:   "unrecognized",
+			output: "something else",
+			want:   Version{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseVersion(tt.output); got != tt.want {
+				t.Errorf("parseVersion() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersion_AtLeast(t *testing.T) {
+	v := Version{Major: 5, Minor: 1}
+	if !v.AtLeast(4, 4) || !v.AtLeast(5, 1) {
+		t.Error("AtLeast() = false for older or equal version, want true")
+	}
+	if v.AtLeast(5, 2) || v.AtLeast(6, 0) {
+		t.Error("AtLeast() = true for newer version, want false")
+	}
+	if !(Version{Raw: "N-1234"}).AtLeast(7, 0) {
+		t.Error("AtLeast() = false for unparsed git build, want true")
+	}
+}
+
+func TestDiscover_EnvOverride(t *testing.T) {
+	dir := t.TempDir()
+	path := writeMockScript(t, dir, "my-ffmpeg", "exit 0\n")
+
+	t.Setenv("YTSYNC_FFMPEG_PATH", path)
+	got, err := Discover("ffmpeg")
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if got != path {
+		t.Errorf("Discover() = %q, want %q", got, path)
+	}
+
+	t.Setenv("YTSYNC_FFMPEG_PATH", filepath.Join(dir, "missing"))
+	if _, err := Discover("ffmpeg"); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Discover() with missing override error = %v, want ErrNotInstalled", err)
+	}
+}
+
+func TestDiscover_NotFound(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	orig := searchDirs
+	searchDirs = nil
+	defer func() { searchDirs = orig }()
+
+	_, err := Discover("ffmpeg-does-not-exist")
+	if !errors.Is(err, ErrNotInstalled) {
+		t.Fatalf("Discover() error = %v, want ErrNotInstalled", err)
+	}
+	if !errcode.Is(err, errcode.SubprocessFailure) {
+		t.Errorf("ErrorCode = %v, want %v", errcode.Of(err), errcode.SubprocessFailure)
+	}
+}
+
+func TestFFmpeg_Version(t *testing.T) {
+	dir := t.TempDir()
+	f := New(writeMockScript(t, dir, "ffmpeg",
+		"echo 'ffmpeg version 6.0 Copyright (c) 2000-2023 the FFmpeg developers'\necho 'built with gcc'\n"), "")
+
+	v, err := f.Version(context.Background())
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if v.Major != 6 || v.Minor != 0 || v.String() != "6.0" {
+		t.Errorf("Version() = %+v, want 6.0", v)
+	}
+}
+
+func TestFFmpeg_Duration(t *testing.T) {
+	dir := t.TempDir()
+	f := New("", writeMockScript(t, dir, "ffprobe", "echo 212.480000\n"))
+
+	got, err := f.Duration(context.Background(), "video.mp4")
+	if err != nil {
+		t.Fatalf("Duration() error = %v", err)
+	}
+	if got != 212.48 {
+		t.Errorf("Duration() = %v, want 212.48", got)
+	}
+}
+
+func TestFFmpeg_Duration_Unparseable(t *testing.T) {
+	dir := t.TempDir()
+	f := New("", writeMockScript(t, dir, "ffprobe", "echo N/A\n"))
+
+	if _, err := f.Duration(context.Background(), "video.mp4"); err == nil {
+		t.Error("Duration() error = nil, want parse error")
+	}
+}
+
+func TestFFmpeg_Duration_FFmpegFallback(t *testing.T) {
+	dir := t.TempDir()
+	ffmpeg := writeMockScript(t, dir, "ffmpeg", `cat >&2 <<'OUT'
+Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'video.mp4':
+  Duration: 01:02:03.50, start: 0.000000, bitrate: 1205 kb/s
+At least one output file must be specified
+OUT
+exit 1
+`)
+	f := New(ffmpeg, filepath.Join(dir, "missing-ffprobe"))
+
+	got, err := f.Duration(context.Background(), "video.mp4")
+	if err != nil {
+		t.Fatalf("Duration() error = %v", err)
+	}
+	if want := 3723.5; got != want {
+		t.Errorf("Duration() = %v, want %v", got, want)
+	}
+}
+
+func TestFFmpeg_NotInstalled(t *testing.T) {
+	dir := t.TempDir()
+	f := New(filepath.Join(dir, "missing-ffmpeg"), filepath.Join(dir, "missing-ffprobe"))
+	ctx := context.Background()
+
+	if _, err := f.Duration(ctx, "video.mp4"); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Duration() error = %v, want ErrNotInstalled", err)
+	}
+	if err := f.Remux(ctx, "in.webm", "out.mkv"); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Remux() error = %v, want ErrNotInstalled", err)
+	}
+	if _, err := f.Version(ctx); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Version() error = %v, want ErrNotInstalled", err)
+	}
+}
+
+func TestFFmpeg_ExtractAudio(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "args.log")
+	f := New(writeMockScript(t, dir, "ffmpeg", argsRecorder(logPath)), "")
+
+	err := f.ExtractAudio(context.Background(), "in.webm", "out.wav", AudioOptions{
+		Start:      90 * time.Second,
+		Duration:   1500 * time.Millisecond,
+		Channels:   1,
+		SampleRate: 16000,
+		Codec:      "pcm_s16le",
+	})
+	if err != nil {
+		t.Fatalf("ExtractAudio() error = %v", err)
+	}
+
+	want := "-y -v error -ss 90.000 -t 1.500 -i in.webm -vn -ac 1 -ar 16000 -c:a pcm_s16le out.wav"
+	if got := readArgs(t, logPath); got != want {
+		t.Errorf("ffmpeg args = %q, want %q", got, want)
+	}
+}
+
+func TestFFmpeg_ExtractAudio_Defaults(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "args.log")
+	f := New(writeMockScript(t, dir, "ffmpeg", argsRecorder(logPath)), "")
+
+	if err := f.ExtractAudio(context.Background(), "in.webm", "out.mp3", AudioOptions{Bitrate: 192}); err != nil {
+		t.Fatalf("ExtractAudio() error = %v", err)
+	}
+
+	want := "-y -v error -i in.webm -vn -b:a 192k out.mp3"
+	if got := readArgs(t, logPath); got != want {
+		t.Errorf("ffmpeg args = %q, want %q", got, want)
+	}
+}
+
+func TestFFmpeg_Remux(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "args.log")
+	f := New(writeMockScript(t, dir, "ffmpeg", argsRecorder(logPath)), "")
+
+	if err := f.Remux(context.Background(), "in.webm", "out.mkv"); err != nil {
+		t.Fatalf("Remux() error = %v", err)
+	}
+
+	want := "-y -v error -i in.webm -map 0 -c copy out.mkv"
+	if got := readArgs(t, logPath); got != want {
+		t.Errorf("ffmpeg args = %q, want %q", got, want)
+	}
+}
+
+func TestFFmpeg_Thumbnail(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "args.log")
+	f := New(writeMockScript(t, dir, "ffmpeg", argsRecorder(logPath)), "")
+
+	if err := f.Thumbnail(context.Background(), "in.mp4", "thumb.jpg", 30*time.Second); err != nil {
+		t.Fatalf("Thumbnail() error = %v", err)
+	}
+
+	want := "-y -v error -ss 30.000 -i in.mp4 -frames:v 1 thumb.jpg"
+	if got := readArgs(t, logPath); got != want {
+		t.Errorf("ffmpeg args = %q, want %q", got, want)
+	}
+}
+
+func TestFFmpeg_CommandFailureIncludesStderr(t *testing.T) {
+	dir := t.TempDir()
+	f := New(writeMockScript(t, dir, "ffmpeg", "echo 'in.webm: Invalid data found' >&2\nexit 1\n"), "")
+
+	err := f.Remux(context.Background(), "in.webm", "out.mkv")
+	if err == nil {
+		t.Fatal("Remux() error = nil, want error")
+	}
+	if errors.Is(err, ErrNotInstalled) {
+		t.Errorf("Remux() error = %v, should not be ErrNotInstalled", err)
+	}
+	if !strings.Contains(err.Error(), "Invalid data found") {
+		t.Errorf("Remux() error = %v, want stderr included", err)
+	}
+}
+
+func TestFFmpeg_MuxSubtitles(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "args.log")
+	f := New(writeMockScript(t, dir, "ffmpeg", argsRecorder(logPath)), "")
+
+	tracks := []SubtitleTrack{
+		{Path: "v.en.vtt", Language: "eng", Title: "English"},
+		{Path: "v.de.vtt", Language: "ger"},
+	}
+	if err := f.MuxSubtitles(context.Background(), "v.mp4", "out.mp4", tracks); err != nil {
+		t.Fatalf("MuxSubtitles() error = %v", err)
+	}
+
+	want := "-y -v error -i v.mp4 -i v.en.vtt -i v.de.vtt -map 0:v? -map 0:a? -map 1 -map 2 -c copy -c:s mov_text " +
+		"-metadata:s:s:0 language=eng -metadata:s:s:0 title=English -metadata:s:s:1 language=ger out.mp4"
+	if got := readArgs(t, logPath); got != want {
+		t.Errorf("ffmpeg args = %q, want %q", got, want)
+	}
+
+	if err := f.MuxSubtitles(context.Background(), "v.mp4", "out.mkv", nil); err == nil {
+		t.Error("MuxSubtitles() with no tracks error = nil")
+	}
+}
+
+func TestSubtitleCodec(t *testing.T) {
+	for out, want := range map[string]string{"a.mp4": "mov_text", "a.MOV": "mov_text", "a.webm": "webvtt", "a.mkv": "srt"} {
+		if got := subtitleCodec(out); got != want {
+			t.Errorf("subtitleCodec(%q) = %q, want %q", out, got, want)
+		}
+	}
+}
+
+func TestFFmpeg_BurnSubtitles(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "args.log")
+	// printf keeps backslashes intact, unlike echo in some shells
+	f := New(writeMockScript(t, dir, "ffmpeg", `printf '%s\n' "$@" > "`+logPath+`"`+"\n"), "")
+
+	if err := f.BurnSubtitles(context.Background(), "v.mp4", "out.mp4", SubtitleTrack{Path: "C:/subs/it's.en.vtt"}); err != nil {
+		t.Fatalf("BurnSubtitles() error = %v", err)
+	}
+
+	want := strings.Join([]string{"-y", "-v", "error", "-i", "v.mp4",
+		`-vf`, `subtitles=filename=C\\:/subs/it\\\'s.en.vtt`, "-c:a", "copy", "out.mp4"}, "\n")
+	if got := readArgs(t, logPath); got != want {
+		t.Errorf("ffmpeg args = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,87 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SubtitleTrack is a subtitle file to add to a video.
+type SubtitleTrack struct {
+	// Path is the subtitle file (SRT, WebVTT, or ASS).
+	Path string
+	// Language is the ISO 639 language code recorded in the stream metadata.
+	Language string
+	// Title is an optional track name shown by players.
+	Title string
+}
+
+// Subtitler adds subtitles to videos.
+type Subtitler interface {
+	// MuxSubtitles copies in to out and adds tracks as soft subtitle
+	// streams, without re-encoding audio or video.
+	MuxSubtitles(ctx context.Context, in, out string, tracks []SubtitleTrack) error
+	// BurnSubtitles re-encodes the video of in into out with track drawn
+	// onto the frames.
+	BurnSubtitles(ctx context.Context, in, out string, track SubtitleTrack) error
+}
+
+// subtitleCodec returns the subtitle codec out's container supports: MP4
+// only carries mov_text, WebM only WebVTT, and Matroska anything, for which
+// SRT is the most widely supported.
+func subtitleCodec(out string) string {
+	switch strings.ToLower(filepath.Ext(out)) {
+	case ".mp4", ".m4v", ".mov":
+		return "mov_text"
+	case ".webm":
+		return "webvtt"
+	default:
+		return "srt"
+	}
+}
+
+// MuxSubtitles copies in to out and adds tracks as soft subtitle streams.
+// The subtitle codec is chosen from out's container.
+func (f *FFmpeg) MuxSubtitles(ctx context.Context, in, out string, tracks []SubtitleTrack) error {
+	if len(tracks) == 0 {
+		return fmt.Errorf("media: no subtitle tracks to add")
+	}
+	args := []string{"-y", "-v", "error", "-i", in}
+	for _, t := range tracks {
+		args = append(args, "-i", t.Path)
+	}
+	args = append(args, "-map", "0:v?", "-map", "0:a?")
+	for i := range tracks {
+		args = append(args, "-map", strconv.Itoa(i+1))
+	}
+	args = append(args, "-c", "copy", "-c:s", subtitleCodec(out))
+	for i, t := range tracks {
+		if t.Language != "" {
+			args = append(args, fmt.Sprintf("-metadata:s:s:%d", i), "language="+t.Language)
+		}
+		if t.Title != "" {
+			args = append(args, fmt.Sprintf("-metadata:s:s:%d", i), "title="+t.Title)
+		}
+	}
+	args = append(args, out)
+
+	_, err := run(ctx, f.ffmpeg(), args...)
+	return err
+}
+
+// BurnSubtitles re-encodes the video of in into out with track drawn onto
+// the frames. Audio is copied.
+func (f *FFmpeg) BurnSubtitles(ctx context.Context, in, out string, track SubtitleTrack) error {
+	filter := "subtitles=filename=" + escapeFilterValue(track.Path)
+	_, err := run(ctx, f.ffmpeg(), "-y", "-v", "error", "-i", in, "-vf", filter, "-c:a", "copy", out)
+	return err
+}
+
+// escapeFilterValue escapes a filter option value for use in a filtergraph.
+// ffmpeg unescapes twice: once for the option value and once for the graph.
+func escapeFilterValue(s string) string {
+	option := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`).Replace(s)
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`).Replace(option)
+}
@@ -0,0 +1,44 @@
+// Package proc runs external tools such as yt-dlp and ffmpeg so that
+// cancelling their context stops them together with everything they
+// started.
+//
+// exec.CommandContext kills only the process it started. yt-dlp runs ffmpeg
+// and other helpers as child processes, which keep running, and keep
+// downloading, after yt-dlp itself is killed. Command instead starts the
+// tool in its own process group, asks the whole group to terminate when the
+// context is done, and kills the group if it is still running after a grace
+// period.
+package proc
+
+import (
+	"context"
+	"os/exec"
+	"time"
+)
+
+// DefaultGracePeriod is how long a cancelled tool gets to exit after being
+// asked to terminate before it is killed.
+const DefaultGracePeriod = 5 * time.Second
+
+// gracePeriod is DefaultGracePeriod, shortened by tests.
+var gracePeriod = DefaultGracePeriod
+
+// Command returns an exec.Cmd like exec.CommandContext, except that the
+// command runs in its own process group. When ctx is done, the group is sent
+// SIGTERM and, if it has not exited after DefaultGracePeriod, SIGKILL. On
+// Windows the process is killed straight away, as with exec.CommandContext.
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+	grace := gracePeriod
+	cmd.Cancel = func() error {
+		err := terminateGroup(cmd.Process)
+		pid := cmd.Process.Pid
+		time.AfterFunc(grace, func() { killGroup(pid) })
+		return err
+	}
+	// Wait returns even if a process that inherited the output pipes
+	// outlives the group
+	cmd.WaitDelay = grace + time.Second
+	return cmd
+}
@@ -0,0 +1,92 @@
+//go:build !windows
+
+package proc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// startScript runs script with Command and returns the PID its background
+// child wrote to a file, once it has.
+func startScript(t *testing.T, ctx context.Context, script string) (wait func() error, childPID int) {
+	t.Helper()
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	cmd := Command(ctx, "/bin/sh", "-c", script, "sh", pidFile)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		data, _ := os.ReadFile(pidFile)
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			return cmd.Wait, pid
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cmd.Process.Kill()
+	t.Fatal("child PID was never written")
+	return nil, 0
+}
+
+// exited reports whether pid has exited. An orphan that exited stays a
+// zombie until init reaps it, which counts as exited.
+func exited(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return true
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
+
+// waitGone waits for pid to exit.
+func waitGone(t *testing.T, pid int, within time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(within)
+	for time.Now().Before(deadline) {
+		if exited(pid) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	syscall.Kill(pid, syscall.SIGKILL)
+	t.Errorf("process %d still running after %v", pid, within)
+}
+
+func TestCommand_CancelStopsChildren(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wait, child := startScript(t, ctx, `sleep 30 & echo $! > "$1"; wait`)
+
+	cancel()
+	if err := wait(); err == nil {
+		t.Error("Wait() after cancel returned nil")
+	}
+	waitGone(t, child, 2*time.Second)
+}
+
+func TestCommand_KillsAfterGracePeriod(t *testing.T) {
+	gracePeriod = 200 * time.Millisecond
+	defer func() { gracePeriod = DefaultGracePeriod }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// Both the shell and its child ignore SIGTERM
+	wait, child := startScript(t, ctx, `trap '' TERM; sh -c 'trap "" TERM; sleep 30' & echo $! > "$1"; wait`)
+
+	start := time.Now()
+	cancel()
+	wait()
+	if elapsed := time.Since(start); elapsed < gracePeriod {
+		t.Errorf("Wait() returned after %v, before the grace period", elapsed)
+	}
+	waitGone(t, child, 2*time.Second)
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package proc
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup does nothing on Windows, where child processes are not
+// stopped with their parent's group.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateGroup kills p. Windows has no SIGTERM to ask it to exit.
+func terminateGroup(p *os.Process) error {
+	return p.Kill()
+}
+
+// killGroup does nothing; terminateGroup already killed the process.
+func killGroup(pid int) {}
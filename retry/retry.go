@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/rand"
 	"time"
+	"ytsync/errcode"
 )
 
 // Config holds retry configuration.
@@ -19,10 +20,38 @@ type Config struct {
 	MaxBackoff time.Duration
 	// Multiplier is the exponential backoff multiplier.
 	Multiplier float64
-	// JitterFraction is the fraction of backoff used for jitter (0.0-1.0).
+	// JitterFraction is the fraction of backoff used for jitter (0.0-1.0)
+	// by StrategyProportional and StrategyConstant.
 	JitterFraction float64
+	// Strategy shapes the delay between attempts. Default:
+	// StrategyProportional.
+	Strategy Strategy
 }
 
+// Strategy selects how the delay before each retry is computed from the
+// exponential backoff, which starts at InitialBackoff and grows by
+// Multiplier up to MaxBackoff. Every strategy caps delays at MaxBackoff.
+type Strategy string
+
+const (
+	// StrategyProportional waits the backoff plus or minus JitterFraction
+	// of it. It is the default.
+	StrategyProportional Strategy = "proportional"
+	// StrategyFullJitter waits a random duration between zero and the
+	// backoff, spreading out clients that failed together the most.
+	StrategyFullJitter Strategy = "full"
+	// StrategyEqualJitter waits half the backoff plus a random duration up
+	// to the other half, so no retry is immediate.
+	StrategyEqualJitter Strategy = "equal"
+	// StrategyDecorrelated waits a random duration between InitialBackoff
+	// and three times the previous delay, ignoring Multiplier, as in AWS's
+	// "decorrelated jitter".
+	StrategyDecorrelated Strategy = "decorrelated"
+	// StrategyConstant waits InitialBackoff, plus or minus JitterFraction
+	// of it, before every retry.
+	StrategyConstant Strategy = "constant"
+)
+
 // DefaultConfig returns sensible defaults.
 func DefaultConfig() Config {
 	return Config{
@@ -49,37 +78,147 @@ func IsRetryable(err error) bool {
 		return false
 	}
 
+	// Errors from other packages classified as permanent
+	switch errcode.Of(err) {
+	case errcode.NotFound, errcode.InvalidInput, errcode.AlreadyExists:
+		return false
+	}
+
 	// Everything else is retryable
 	return true
 }
 
 // Sentinel errors that are permanent.
 var (
-	ErrChannelNotFound = errors.New("channel not found")
-	ErrInvalidURL      = errors.New("invalid url")
+	ErrChannelNotFound = errcode.New(errcode.NotFound, "channel not found")
+	ErrInvalidURL      = errcode.New(errcode.InvalidInput, "invalid url")
 )
 
 // Do executes fn with retry logic, using the provided classifier to determine
 // if errors are retryable.
 func Do(ctx context.Context, cfg Config, classifier ErrorClassifier, fn func(context.Context) error) error {
+	_, _, err := DoWithResult(ctx, cfg, classifier, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// Observer is told of each failed attempt that Do or DoWithResult will
+// retry, with its Backoff set to the wait before the next attempt. ctx is
+// the context the attempt ran with.
+type Observer func(ctx context.Context, attempt Attempt)
+
+type observerKey struct{}
+
+// WithObserver returns a copy of ctx whose retries are reported to observe,
+// so that callers can watch retries made deep inside listers and clients.
+// Observers set on ctx earlier are still called, first.
+func WithObserver(ctx context.Context, observe Observer) context.Context {
+	if prev := observerOf(ctx); prev != nil {
+		next := observe
+		observe = func(ctx context.Context, attempt Attempt) {
+			prev(ctx, attempt)
+			next(ctx, attempt)
+		}
+	}
+	return context.WithValue(ctx, observerKey{}, observe)
+}
+
+// observerOf returns the observer of ctx, or nil if it has none.
+func observerOf(ctx context.Context) Observer {
+	observe, _ := ctx.Value(observerKey{}).(Observer)
+	return observe
+}
+
+// Attempt describes a single execution of a retried function.
+type Attempt struct {
+	// Number is the 1-based attempt number.
+	Number int
+	// Err is the error returned by the attempt, or nil if it succeeded.
+	Err error
+	// Duration is how long the attempt took to run.
+	Duration time.Duration
+	// Retryable is the classifier's decision for Err (false on success).
+	Retryable bool
+	// Backoff is how long Do waited after this attempt before the next one.
+	// Zero for the final attempt.
+	Backoff time.Duration
+}
+
+// Report summarizes the attempts made by DoWithResult.
+type Report struct {
+	// Attempts lists every attempt in order.
+	Attempts []Attempt
+	// TotalWait is the total time spent sleeping between attempts.
+	TotalWait time.Duration
+	// Elapsed is the wall time from the first attempt to the final result.
+	Elapsed time.Duration
+}
+
+// Count returns the number of attempts made.
+func (r *Report) Count() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.Attempts)
+}
+
+// Retries returns the number of attempts after the first.
+func (r *Report) Retries() int {
+	if n := r.Count(); n > 1 {
+		return n - 1
+	}
+	return 0
+}
+
+// Last returns the final attempt, or nil if no attempt was made.
+func (r *Report) Last() *Attempt {
+	if r.Count() == 0 {
+		return nil
+	}
+	return &r.Attempts[len(r.Attempts)-1]
+}
+
+// DoWithResult executes fn with retry logic like Do, returning fn's result
+// along with a Report of every attempt made. The report is returned even
+// when err is non-nil.
+func DoWithResult[T any](ctx context.Context, cfg Config, classifier ErrorClassifier, fn func(context.Context) (T, error)) (T, *Report, error) {
 	if classifier == nil {
 		classifier = IsRetryable
 	}
 
+	var zero T
 	var lastErr error
+	report := &Report{}
+	start := time.Now()
 	backoff := cfg.InitialBackoff
+	prevSleep := cfg.InitialBackoff
+
+	finish := func() *Report {
+		report.Elapsed = time.Since(start)
+		return report
+	}
 
 	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
 		// Attempt the operation
-		if err := fn(ctx); err == nil {
-			return nil
-		} else {
-			lastErr = err
-			if !classifier(err) {
-				// Permanent error, don't retry
-				return err
-			}
+		attemptStart := time.Now()
+		result, err := fn(ctx)
+		report.Attempts = append(report.Attempts, Attempt{
+			Number:   attempt + 1,
+			Err:      err,
+			Duration: time.Since(attemptStart),
+		})
+		current := &report.Attempts[len(report.Attempts)-1]
+
+		if err == nil {
+			return result, finish(), nil
+		}
+		lastErr = err
+		if !classifier(err) {
+			// Permanent error, don't retry
+			return zero, finish(), err
 		}
+		current.Retryable = true
 
 		// Last attempt, don't sleep
 		if attempt == cfg.MaxRetries {
@@ -87,18 +226,26 @@ func Do(ctx context.Context, cfg Config, classifier ErrorClassifier, fn func(con
 		}
 
 		// Calculate backoff with jitter
-		sleep := backoff + jitter(backoff, cfg.JitterFraction)
-		if sleep > cfg.MaxBackoff {
-			sleep = cfg.MaxBackoff
+		sleep := cfg.delay(backoff, prevSleep, rand.Float64)
+		prevSleep = sleep
+		if observe := observerOf(ctx); observe != nil {
+			retried := *current
+			retried.Backoff = sleep
+			observe(ctx, retried)
 		}
 
 		// Sleep or return if context is canceled
+		sleepStart := time.Now()
 		select {
 		case <-time.After(sleep):
 			// Continue to next attempt
 		case <-ctx.Done():
-			return ctx.Err()
+			current.Backoff = time.Since(sleepStart)
+			report.TotalWait += current.Backoff
+			return zero, finish(), ctx.Err()
 		}
+		current.Backoff = sleep
+		report.TotalWait += sleep
 
 		// Increase backoff for next attempt
 		backoff = time.Duration(float64(backoff) * cfg.Multiplier)
@@ -107,16 +254,45 @@ func Do(ctx context.Context, cfg Config, classifier ErrorClassifier, fn func(con
 		}
 	}
 
-	return fmt.Errorf("max retries exceeded: %w", lastErr)
+	return zero, finish(), fmt.Errorf("max retries exceeded: %w", lastErr)
+}
+
+// delay returns the sleep before a retry under cfg's strategy. backoff is
+// the exponential backoff for the attempt and prev the previous sleep, or
+// InitialBackoff before the first retry. rnd returns values in [0, 1).
+func (cfg Config) delay(backoff, prev time.Duration, rnd func() float64) time.Duration {
+	var sleep time.Duration
+	switch cfg.Strategy {
+	case StrategyFullJitter:
+		sleep = time.Duration(rnd() * float64(backoff))
+	case StrategyEqualJitter:
+		half := backoff / 2
+		sleep = half + time.Duration(rnd()*float64(backoff-half))
+	case StrategyDecorrelated:
+		low, high := cfg.InitialBackoff, 3*prev
+		if high < low {
+			high = low
+		}
+		sleep = low + time.Duration(rnd()*float64(high-low))
+	case StrategyConstant:
+		sleep = cfg.InitialBackoff + jitter(cfg.InitialBackoff, cfg.JitterFraction, rnd)
+	default:
+		sleep = backoff + jitter(backoff, cfg.JitterFraction, rnd)
+	}
+	if sleep > cfg.MaxBackoff {
+		sleep = cfg.MaxBackoff
+	}
+	return sleep
 }
 
-// jitter returns a random duration in range [-jitterFraction*d, +jitterFraction*d].
-func jitter(d time.Duration, fraction float64) time.Duration {
+// jitter returns a random duration in range [-jitterFraction*d, +jitterFraction*d],
+// drawing from rnd.
+func jitter(d time.Duration, fraction float64, rnd func() float64) time.Duration {
 	if fraction <= 0 {
 		return 0
 	}
 	jitterRange := float64(d) * fraction
-	jitterValue := (rand.Float64() - 0.5) * 2 * jitterRange
+	jitterValue := (rnd() - 0.5) * 2 * jitterRange
 	return time.Duration(jitterValue)
 }
 
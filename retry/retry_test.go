@@ -3,6 +3,7 @@ package retry
 import (
 	"context"
 	"errors"
+	"math/rand"
 	"testing"
 	"time"
 )
@@ -225,3 +226,162 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("DefaultConfig().Multiplier = %f, want 2.0", cfg.Multiplier)
 	}
 }
+
+func TestDoWithResult(t *testing.T) {
+	cfg := Config{
+		MaxRetries:     3,
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		Multiplier:     2.0,
+	}
+
+	attempts := 0
+	transient := errors.New("transient")
+	got, report, err := DoWithResult(context.Background(), cfg, nil, func(ctx context.Context) (string, error) {
+		attempts++
+		if attempts < 3 {
+			return "", transient
+		}
+		return "done", nil
+	})
+	if err != nil {
+		t.Fatalf("DoWithResult() error = %v", err)
+	}
+	if got != "done" {
+		t.Errorf("DoWithResult() = %q, want %q", got, "done")
+	}
+	if report.Count() != 3 || report.Retries() != 2 {
+		t.Fatalf("Count() = %d, Retries() = %d, want 3 and 2", report.Count(), report.Retries())
+	}
+	for i, a := range report.Attempts[:2] {
+		if a.Number != i+1 || a.Err != transient || !a.Retryable {
+			t.Errorf("attempt %d = %+v, want retryable transient error", i+1, a)
+		}
+	}
+	if last := report.Last(); last.Err != nil || last.Backoff != 0 {
+		t.Errorf("last attempt = %+v, want success without backoff", last)
+	}
+	if want := 15 * time.Millisecond; report.TotalWait != want {
+		t.Errorf("TotalWait = %v, want %v", report.TotalWait, want)
+	}
+}
+
+func TestDoWithResult_PermanentError(t *testing.T) {
+	cfg := Config{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2}
+
+	_, report, err := DoWithResult(context.Background(), cfg, nil, func(ctx context.Context) (int, error) {
+		return 0, ErrInvalidURL
+	})
+	if !errors.Is(err, ErrInvalidURL) {
+		t.Fatalf("DoWithResult() error = %v, want ErrInvalidURL", err)
+	}
+	if report.Count() != 1 || report.Attempts[0].Retryable {
+		t.Errorf("report = %+v, want a single non-retryable attempt", report.Attempts)
+	}
+	if report.TotalWait != 0 {
+		t.Errorf("TotalWait = %v, want 0", report.TotalWait)
+	}
+}
+
+func TestDelayDistributions(t *testing.T) {
+	const samples = 10000
+	base := Config{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 10 * time.Second, Multiplier: 2, JitterFraction: 0.2}
+	backoff := 800 * time.Millisecond
+
+	tests := []struct {
+		strategy Strategy
+		min, max time.Duration
+		mean     time.Duration
+	}{
+		{StrategyProportional, 640 * time.Millisecond, 960 * time.Millisecond, 800 * time.Millisecond},
+		{StrategyFullJitter, 0, 800 * time.Millisecond, 400 * time.Millisecond},
+		{StrategyEqualJitter, 400 * time.Millisecond, 800 * time.Millisecond, 600 * time.Millisecond},
+		{StrategyConstant, 80 * time.Millisecond, 120 * time.Millisecond, 100 * time.Millisecond},
+		// Between InitialBackoff and three times the previous 500ms delay
+		{StrategyDecorrelated, 100 * time.Millisecond, 1500 * time.Millisecond, 800 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			cfg := base
+			cfg.Strategy = tt.strategy
+			rnd := rand.New(rand.NewSource(1)).Float64
+
+			var sum time.Duration
+			for i := 0; i < samples; i++ {
+				d := cfg.delay(backoff, 500*time.Millisecond, rnd)
+				if d < tt.min || d > tt.max {
+					t.Fatalf("delay = %v, want within [%v, %v]", d, tt.min, tt.max)
+				}
+				sum += d
+			}
+			mean := sum / samples
+			if diff := mean - tt.mean; diff < -tt.mean/20 || diff > tt.mean/20 {
+				t.Errorf("mean delay = %v, want about %v", mean, tt.mean)
+			}
+		})
+	}
+}
+
+func TestDelayCappedAtMaxBackoff(t *testing.T) {
+	for _, s := range []Strategy{StrategyProportional, StrategyFullJitter, StrategyEqualJitter, StrategyDecorrelated, StrategyConstant} {
+		cfg := Config{InitialBackoff: time.Second, MaxBackoff: time.Second, JitterFraction: 0.5, Strategy: s}
+		rnd := func() float64 { return 0.999 }
+		if d := cfg.delay(4*time.Second, 4*time.Second, rnd); d > time.Second {
+			t.Errorf("%s: delay = %v, want at most MaxBackoff", s, d)
+		}
+	}
+}
+
+func TestDoWithResult_DecorrelatedGrows(t *testing.T) {
+	cfg := Config{MaxRetries: 4, InitialBackoff: time.Millisecond, MaxBackoff: 50 * time.Millisecond, Strategy: StrategyDecorrelated}
+
+	_, report, _ := DoWithResult(context.Background(), cfg, nil, func(ctx context.Context) (int, error) {
+		return 0, errors.New("transient")
+	})
+	prev := cfg.InitialBackoff
+	for _, a := range report.Attempts[:cfg.MaxRetries] {
+		if a.Backoff < cfg.InitialBackoff || a.Backoff > 3*prev {
+			t.Errorf("attempt %d backoff = %v, want within [%v, %v]", a.Number, a.Backoff, cfg.InitialBackoff, 3*prev)
+		}
+		prev = a.Backoff
+	}
+}
+
+func TestWithObserver(t *testing.T) {
+	cfg := Config{
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Multiplier:     1,
+	}
+	var outer, inner []Attempt
+	ctx := WithObserver(context.Background(), func(ctx context.Context, a Attempt) { outer = append(outer, a) })
+	ctx = WithObserver(ctx, func(ctx context.Context, a Attempt) { inner = append(inner, a) })
+
+	calls := 0
+	err := Do(ctx, cfg, nil, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if len(outer) != 2 || len(inner) != 2 {
+		t.Fatalf("observers saw %d and %d retries, want 2 each", len(outer), len(inner))
+	}
+	for i, a := range inner {
+		if a.Number != i+1 || a.Err == nil || !a.Retryable || a.Backoff != time.Millisecond {
+			t.Errorf("retry %d = %+v, want attempt %d with its error and backoff", i, a, i+1)
+		}
+	}
+
+	// Permanent errors and the last attempt are not retried, so not observed
+	outer = nil
+	Do(ctx, cfg, func(error) bool { return false }, func(ctx context.Context) error { return errors.New("permanent") })
+	if len(outer) != 0 {
+		t.Errorf("observer saw %d retries of a permanent error, want 0", len(outer))
+	}
+}
@@ -61,3 +61,16 @@ func (w *AtomicWriter) Abort() error {
 	w.file.Close()
 	return os.Remove(w.tmpPath)
 }
+
+// writeFileAtomic replaces the file at path with data using an AtomicWriter.
+func writeFileAtomic(path string, data []byte) error {
+	writer, err := NewAtomicWriter(path)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Abort()
+		return err
+	}
+	return writer.Commit()
+}
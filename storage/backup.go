@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"ytsync/errcode"
+)
+
+const (
+	// backupFormatVersion is the version of the archive layout written by
+	// Backup. Restore rejects archives from newer versions.
+	backupFormatVersion = 1
+
+	backupManifestName = "manifest.json"
+	backupStoreName    = "store.json"
+
+	// maxBackupEntrySize bounds each archive entry read by Restore.
+	maxBackupEntrySize = 1 << 30
+)
+
+// ErrBackupVersion is returned by Restore for archives written by a newer
+// version of ytsync. Backups of older store schemas are migrated, and
+// schemas newer than this version reads fail with ErrSchemaVersion.
+var ErrBackupVersion = errcode.New(errcode.InvalidInput, "storage: unsupported backup version")
+
+// BackupManifest describes a backup archive. It is the first entry of the
+// archive, so the versions can be checked before the data is read.
+type BackupManifest struct {
+	// FormatVersion is the version of the archive layout.
+	FormatVersion int `json:"format_version"`
+	// SchemaVersion is the store schema version of the backed up data.
+	SchemaVersion string `json:"schema_version"`
+	// CreatedAt is when the backup was taken.
+	CreatedAt time.Time `json:"created_at"`
+	// Files maps each data entry of the archive to its hex SHA-256.
+	Files map[string]string `json:"files"`
+	// Channels, Videos, and Transcripts count the backed up records.
+	Channels    int `json:"channels"`
+	Videos      int `json:"videos"`
+	Transcripts int `json:"transcripts"`
+	// Encrypted reports whether the store data is sealed with the store's
+	// Encryptor. The manifest itself is never encrypted.
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+// Backup writes a gzip-compressed tar archive of the whole store to w. The
+// archive holds a manifest with the schema version and a checksum of every
+// entry, followed by the store data. Transcript text and channel images
+// kept in the blob store are included inline, so the archive is
+// self-contained. The store data of an encrypted store is sealed with its
+// Encryptor, so restoring it needs the key. It returns the archive's
+// manifest.
+func (s *JSONStore) Backup(ctx context.Context, w io.Writer) (*BackupManifest, error) {
+	s.mu.RLock()
+	snapshot := *s.data
+	snapshot.Transcripts = make(map[string]transcriptSet, len(s.data.Transcripts))
+	for videoID, set := range s.data.Transcripts {
+		if err := ctx.Err(); err != nil {
+			s.mu.RUnlock()
+			return nil, err
+		}
+		inlined := make(transcriptSet, len(set))
+		for language, t := range set {
+			loaded, err := s.loadTranscript(t)
+			if err != nil {
+				s.mu.RUnlock()
+				return nil, &StorageError{Op: "backup", Entity: "store", Err: err}
+			}
+			inline := *loaded
+			inline.Blob = nil
+			inlined[language] = &inline
+		}
+		snapshot.Transcripts[videoID] = inlined
+	}
+	snapshot.Channels = make(map[string]*Channel, len(s.data.Channels))
+	for id, ch := range s.data.Channels {
+		inlined := *ch
+		for _, art := range []**ChannelArt{&inlined.Avatar, &inlined.Banner} {
+			if *art == nil || (*art).Blob == nil {
+				continue
+			}
+			data, err := s.loadChannelArt(*art)
+			if err != nil {
+				s.mu.RUnlock()
+				return nil, &StorageError{Op: "backup", Entity: "store", Err: err}
+			}
+			inline := **art
+			inline.Data = data
+			inline.Blob = nil
+			*art = &inline
+		}
+		snapshot.Channels[id] = &inlined
+	}
+	data, err := json.Marshal(&snapshot)
+	enc := s.enc
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, &StorageError{Op: "backup", Entity: "store", Err: err}
+	}
+	if data, err = sealFile(enc, data); err != nil {
+		return nil, &StorageError{Op: "backup", Entity: "store", Err: err}
+	}
+
+	manifest := BackupManifest{
+		FormatVersion: backupFormatVersion,
+		SchemaVersion: snapshot.Version,
+		CreatedAt:     time.Now().UTC(),
+		Files:         map[string]string{backupStoreName: checksum(data)},
+		Channels:      len(snapshot.Channels),
+		Videos:        len(snapshot.Videos),
+		Transcripts:   snapshot.transcriptCount(),
+		Encrypted:     enc != nil,
+	}
+	manifestData, err := json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return nil, &StorageError{Op: "backup", Entity: "store", Err: err}
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{backupManifestName, manifestData},
+		{backupStoreName, data},
+	} {
+		hdr := &tar.Header{
+			Name:    entry.name,
+			Mode:    0600,
+			Size:    int64(len(entry.data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, &StorageError{Op: "backup", Entity: "store", Err: err}
+		}
+		if _, err := tw.Write(entry.data); err != nil {
+			return nil, &StorageError{Op: "backup", Entity: "store", Err: err}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, &StorageError{Op: "backup", Entity: "store", Err: err}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, &StorageError{Op: "backup", Entity: "store", Err: err}
+	}
+	return &manifest, nil
+}
+
+// Restore replaces the contents of the store with a backup written by
+// Backup. The archive is verified against its manifest before anything is
+// changed, so a corrupt or truncated archive leaves the store as it was.
+// Backups of older schemas are migrated as they are restored. With a blob
+// store set, restored transcript text and channel images are written to it.
+// An encrypted backup is opened with the store's Encryptor and fails with
+// ErrEncryptionKey if that does not hold its key.
+func (s *JSONStore) Restore(ctx context.Context, r io.Reader) (*BackupManifest, error) {
+	if err := s.writable("restore", "store"); err != nil {
+		return nil, err
+	}
+	manifest, data, err := readBackup(r)
+	if err != nil {
+		return nil, &StorageError{Op: "restore", Entity: "store", Err: err}
+	}
+	s.mu.RLock()
+	enc := s.enc
+	s.mu.RUnlock()
+	if data, err = openFile(enc, data); err != nil {
+		return nil, &StorageError{Op: "restore", Entity: "store", Err: err}
+	}
+
+	data, err = migrateDocument(data)
+	if err != nil {
+		return nil, &StorageError{Op: "restore", Entity: "store", Err: err}
+	}
+	restored := &storeData{}
+	if err := json.Unmarshal(data, restored); err != nil {
+		return nil, &StorageError{Op: "restore", Entity: "store", Err: ErrStorageCorrupt}
+	}
+	restored.ensureMaps()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, set := range restored.Transcripts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for language, t := range set {
+			stored, err := s.storeTranscript(t)
+			if err != nil {
+				return nil, &StorageError{Op: "restore", Entity: "store", Err: err}
+			}
+			set[language] = stored
+		}
+	}
+	for _, ch := range restored.Channels {
+		for _, art := range []**ChannelArt{&ch.Avatar, &ch.Banner} {
+			stored, err := s.storeChannelArt(*art)
+			if err != nil {
+				return nil, &StorageError{Op: "restore", Entity: "store", Err: err}
+			}
+			*art = stored
+		}
+	}
+
+	previous := s.data
+	s.data = restored
+	if err := s.saveLocked(); err != nil {
+		s.data = previous
+		return nil, err
+	}
+	for _, set := range previous.Transcripts {
+		for _, t := range set {
+			s.releaseBlob(t.Blob)
+		}
+	}
+	for _, ch := range previous.Channels {
+		for _, art := range []*ChannelArt{ch.Avatar, ch.Banner} {
+			if art != nil {
+				s.releaseBlob(art.Blob)
+			}
+		}
+	}
+	return manifest, nil
+}
+
+// readBackup reads a backup archive and returns its manifest and verified
+// store data.
+func readBackup(r io.Reader) (*BackupManifest, []byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: not a backup archive", ErrStorageCorrupt)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	var manifest *BackupManifest
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrStorageCorrupt, err)
+		}
+		if hdr.Size > maxBackupEntrySize {
+			return nil, nil, fmt.Errorf("%w: entry %s too large", ErrStorageCorrupt, hdr.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrStorageCorrupt, err)
+		}
+
+		if manifest == nil {
+			if hdr.Name != backupManifestName {
+				return nil, nil, fmt.Errorf("%w: archive has no manifest", ErrStorageCorrupt)
+			}
+			manifest = &BackupManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, nil, fmt.Errorf("%w: malformed manifest", ErrStorageCorrupt)
+			}
+			if manifest.FormatVersion < 1 || manifest.FormatVersion > backupFormatVersion {
+				return nil, nil, fmt.Errorf("%w: format %d", ErrBackupVersion, manifest.FormatVersion)
+			}
+			continue
+		}
+		files[hdr.Name] = data
+	}
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("%w: archive has no manifest", ErrStorageCorrupt)
+	}
+
+	for name, sum := range manifest.Files {
+		data, ok := files[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: archive is missing %s", ErrStorageCorrupt, name)
+		}
+		if checksum(data) != sum {
+			return nil, nil, fmt.Errorf("%w: checksum mismatch for %s", ErrStorageCorrupt, name)
+		}
+	}
+	data, ok := files[backupStoreName]
+	if !ok || manifest.Files[backupStoreName] == "" {
+		return nil, nil, fmt.Errorf("%w: archive is missing %s", ErrStorageCorrupt, backupStoreName)
+	}
+	return manifest, data, nil
+}
+
+// checksum returns the hex SHA-256 of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONStore_BackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	source, err := NewJSONStore(filepath.Join(dir, "source.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	blobs, err := NewBlobStore(filepath.Join(dir, "blobs"), CompressionGzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.SetBlobStore(blobs)
+
+	channel := &Channel{YouTubeID: "UCbackup", Name: "Backup Channel"}
+	if err := source.CreateChannel(ctx, channel); err != nil {
+		t.Fatal(err)
+	}
+	video := &Video{YouTubeID: "vid1", ChannelID: channel.ID, Title: "First"}
+	if err := source.CreateVideo(ctx, video); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.CreateTranscript(ctx, &Transcript{VideoID: video.ID, Content: "hello world"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	manifest, err := source.Backup(ctx, &archive)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if manifest.SchemaVersion != schemaVersion || manifest.Channels != 1 || manifest.Videos != 1 || manifest.Transcripts != 1 {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	// Restore into a store without a blob store: transcripts come back inline
+	target, err := NewJSONStore(filepath.Join(dir, "target.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	if err := target.CreateChannel(ctx, &Channel{YouTubeID: "UCreplaced", Name: "Replaced"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := target.Restore(ctx, bytes.NewReader(archive.Bytes())); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if _, err := target.GetChannelByYouTubeID(ctx, "UCreplaced"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("channel from before the restore still present: %v", err)
+	}
+	restored, err := target.GetVideoByYouTubeID(ctx, "vid1")
+	if err != nil || restored.Title != "First" {
+		t.Fatalf("GetVideoByYouTubeID() = %+v, %v", restored, err)
+	}
+	transcript, err := target.GetTranscript(ctx, restored.ID)
+	if err != nil || transcript.Content != "hello world" || transcript.Blob != nil {
+		t.Errorf("GetTranscript() = %+v, %v", transcript, err)
+	}
+}
+
+func TestJSONStore_BackupRestoreEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	enc := mustAESGCM(t, bytes.Repeat([]byte{7}, 32))
+
+	source, err := NewJSONStoreWithOptions(filepath.Join(dir, "source.json"), &JSONStoreOptions{Encryptor: enc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	channel := &Channel{YouTubeID: "UCsecret", Name: "Secret Channel"}
+	if err := source.CreateChannel(ctx, channel); err != nil {
+		t.Fatal(err)
+	}
+	video := &Video{YouTubeID: "vid1", ChannelID: channel.ID, Title: "First"}
+	if err := source.CreateVideo(ctx, video); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.CreateTranscript(ctx, &Transcript{VideoID: video.ID, Content: "private words"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	manifest, err := source.Backup(ctx, &archive)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if !manifest.Encrypted {
+		t.Error("manifest.Encrypted = false for an encrypted store")
+	}
+	for name, data := range readTestArchive(t, archive.Bytes()) {
+		if bytes.Contains(data, []byte("private words")) || bytes.Contains(data, []byte("Secret Channel")) {
+			t.Errorf("archive entry %s holds plaintext", name)
+		}
+	}
+
+	plain, err := NewJSONStore(filepath.Join(dir, "plain.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plain.Close()
+	if _, err := plain.Restore(ctx, bytes.NewReader(archive.Bytes())); !errors.Is(err, ErrEncryptionKey) {
+		t.Errorf("Restore() without the key error = %v, want ErrEncryptionKey", err)
+	}
+
+	target, err := NewJSONStoreWithOptions(filepath.Join(dir, "target.json"), &JSONStoreOptions{Encryptor: enc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	if _, err := target.Restore(ctx, bytes.NewReader(archive.Bytes())); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	restored, err := target.GetVideoByYouTubeID(ctx, "vid1")
+	if err != nil {
+		t.Fatalf("GetVideoByYouTubeID() error = %v", err)
+	}
+	if transcript, err := target.GetTranscript(ctx, restored.ID); err != nil || transcript.Content != "private words" {
+		t.Errorf("GetTranscript() = %+v, %v", transcript, err)
+	}
+}
+
+func TestJSONStore_RestoreRejectsBadArchives(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewJSONStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.CreateChannel(ctx, &Channel{YouTubeID: "UCkeep", Name: "Keep"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if _, err := store.Backup(ctx, &archive); err != nil {
+		t.Fatal(err)
+	}
+	entries := readTestArchive(t, archive.Bytes())
+
+	tests := []struct {
+		name    string
+		archive []byte
+		wantErr error
+	}{
+		{"not gzip", []byte("plain text"), ErrStorageCorrupt},
+		{"truncated", archive.Bytes()[:archive.Len()/2], ErrStorageCorrupt},
+		{"tampered", writeTestArchive(t, map[string][]byte{
+			backupManifestName: entries[backupManifestName],
+			backupStoreName:    bytes.Replace(entries[backupStoreName], []byte("Keep"), []byte("Evil"), 1),
+		}), ErrStorageCorrupt},
+		{"newer format", writeTestArchive(t, map[string][]byte{
+			backupManifestName: []byte(`{"format_version": 99}`),
+		}), ErrBackupVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := store.Restore(ctx, bytes.NewReader(tt.archive)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Restore() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := store.GetChannelByYouTubeID(ctx, "UCkeep"); err != nil {
+				t.Errorf("store changed by a failed restore: %v", err)
+			}
+		})
+	}
+}
+
+func readTestArchive(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	entries := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[hdr.Name], _ = io.ReadAll(tr)
+	}
+}
+
+// writeTestArchive writes entries as a backup archive, manifest first.
+func writeTestArchive(t *testing.T, entries map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	names := []string{backupManifestName, backupStoreName}
+	for _, name := range names {
+		data, ok := entries[name]
+		if !ok {
+			continue
+		}
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data))})
+		tw.Write(data)
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Compression names the codec blobs are compressed with.
+type Compression string
+
+const (
+	// CompressionNone stores blobs uncompressed.
+	CompressionNone Compression = "none"
+	// CompressionGzip compresses blobs with gzip. It is the default.
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd compresses blobs with Zstandard. The standard library
+	// has no zstd codec, so one must be registered with RegisterCompression
+	// before use.
+	CompressionZstd Compression = "zstd"
+)
+
+// codec encodes and decodes blobs for one Compression.
+type codec struct {
+	ext    string
+	encode func(io.Writer) (io.WriteCloser, error)
+	decode func(io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[Compression]codec{
+		CompressionNone: {
+			encode: func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil },
+			decode: func(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil },
+		},
+		CompressionGzip: {
+			ext: ".gz",
+			encode: func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriterLevel(w, gzip.BestCompression)
+			},
+			decode: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+		},
+	}
+)
+
+// RegisterCompression makes a codec available to blob stores. ext is the
+// file extension of blobs compressed with it, such as ".zst". Registering
+// an existing name replaces its codec.
+//
+//	storage.RegisterCompression(storage.CompressionZstd, ".zst",
+//		func(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) },
+//		func(r io.Reader) (io.ReadCloser, error) {
+//			d, err := zstd.NewReader(r)
+//			return d.IOReadCloser(), err
+//		})
+func RegisterCompression(c Compression, ext string, encode func(io.Writer) (io.WriteCloser, error), decode func(io.Reader) (io.ReadCloser, error)) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[c] = codec{ext: ext, encode: encode, decode: decode}
+}
+
+func lookupCodec(c Compression) (codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	cd, ok := codecs[c]
+	if !ok {
+		return codec{}, fmt.Errorf("%w: compression %q is not registered", ErrInvalidInput, c)
+	}
+	return cd, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// BlobRef references content held in a BlobStore. It is what the structured
+// store keeps in place of the content itself.
+type BlobRef struct {
+	// Digest is the hex SHA-256 of the uncompressed content.
+	Digest string `json:"digest"`
+	// Size is the uncompressed size in bytes.
+	Size int64 `json:"size"`
+	// StoredSize is the size on disk after compression.
+	StoredSize int64 `json:"stored_size"`
+	// Compression is the codec the blob was written with.
+	Compression Compression `json:"compression"`
+}
+
+// BlobStore keeps large content in content-addressed files under a
+// directory, compressed with a registered codec. Identical content is
+// stored once. Blobs are written atomically and verified against their
+// digest when read.
+type BlobStore struct {
+	dir         string
+	compression Compression
+
+	mu  sync.RWMutex
+	enc Encryptor
+}
+
+// NewBlobStore creates a blob store rooted at dir, writing new blobs with
+// compression. An empty compression uses CompressionGzip.
+func NewBlobStore(dir string, compression Compression) (*BlobStore, error) {
+	if compression == "" {
+		compression = CompressionGzip
+	}
+	if _, err := lookupCodec(compression); err != nil {
+		return nil, &StorageError{Op: "open", Entity: "blob store", Err: err}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, &StorageError{Op: "open", Entity: "blob store", Err: err}
+	}
+	return &BlobStore{dir: dir, compression: compression}, nil
+}
+
+// SetEncryptor encrypts blobs written from now on with enc, after
+// compression. Blobs are read whether encrypted or not; use RotateKey to
+// encrypt existing blobs. Pass nil to write plain blobs again.
+func (b *BlobStore) SetEncryptor(enc Encryptor) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.enc = enc
+}
+
+func (b *BlobStore) encryptor() Encryptor {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.enc
+}
+
+// RotateKey re-encrypts every blob with enc, or decrypts them if enc is
+// nil, and makes enc the encryptor for new blobs. It returns the number of
+// blobs rewritten. Blobs must not be written concurrently.
+func (b *BlobStore) RotateKey(ctx context.Context, enc Encryptor) (int, error) {
+	current := b.encryptor()
+	rewritten := 0
+	err := filepath.WalkDir(b.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".ytsync-") {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		plain, err := openFile(current, data)
+		if err != nil {
+			// Already rewritten by an earlier, interrupted rotation
+			if plain, err = openFile(enc, data); err != nil {
+				return fmt.Errorf("blob %s: %w", d.Name(), err)
+			}
+		}
+		sealed, err := sealFile(enc, plain)
+		if err != nil {
+			return err
+		}
+		writer, err := NewAtomicWriter(path)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(sealed); err != nil {
+			writer.Abort()
+			return err
+		}
+		if err := writer.Commit(); err != nil {
+			return err
+		}
+		rewritten++
+		return nil
+	})
+	if err != nil {
+		return rewritten, &StorageError{Op: "update", Entity: "blob store", Err: err}
+	}
+	b.SetEncryptor(enc)
+	return rewritten, nil
+}
+
+// Dir returns the directory blobs are stored in.
+func (b *BlobStore) Dir() string {
+	return b.dir
+}
+
+// Put stores data and returns its reference. Content already in the store
+// is not written again.
+func (b *BlobStore) Put(data []byte) (*BlobRef, error) {
+	sum := sha256.Sum256(data)
+	ref := &BlobRef{
+		Digest:      hex.EncodeToString(sum[:]),
+		Size:        int64(len(data)),
+		Compression: b.compression,
+	}
+	cd, err := lookupCodec(b.compression)
+	if err != nil {
+		return nil, &StorageError{Op: "write", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+
+	path := b.path(ref, cd)
+	if info, err := os.Stat(path); err == nil {
+		ref.StoredSize = info.Size()
+		return ref, nil
+	}
+
+	var buf bytes.Buffer
+	enc, err := cd.encode(&buf)
+	if err != nil {
+		return nil, &StorageError{Op: "write", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+	if _, err := enc.Write(data); err != nil {
+		return nil, &StorageError{Op: "write", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, &StorageError{Op: "write", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+	stored, err := sealFile(b.encryptor(), buf.Bytes())
+	if err != nil {
+		return nil, &StorageError{Op: "write", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+
+	writer, err := NewAtomicWriter(path)
+	if err != nil {
+		return nil, &StorageError{Op: "write", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+	if _, err := writer.Write(stored); err != nil {
+		writer.Abort()
+		return nil, &StorageError{Op: "write", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+	if err := writer.Commit(); err != nil {
+		return nil, &StorageError{Op: "write", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+	ref.StoredSize = int64(len(stored))
+	return ref, nil
+}
+
+// Get reads the content ref points to. It returns ErrNotFound if the blob
+// is missing and ErrStorageCorrupt if it does not match its digest.
+func (b *BlobStore) Get(ref *BlobRef) ([]byte, error) {
+	cd, err := lookupCodec(ref.Compression)
+	if err == nil {
+		err = ref.validate()
+	}
+	if err != nil {
+		return nil, &StorageError{Op: "read", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+	stored, err := os.ReadFile(b.path(ref, cd))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = ErrNotFound
+		}
+		return nil, &StorageError{Op: "read", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+	stored, err = openFile(b.encryptor(), stored)
+	if err != nil {
+		return nil, &StorageError{Op: "read", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+
+	dec, err := cd.decode(bytes.NewReader(stored))
+	if err != nil {
+		return nil, &StorageError{Op: "read", Entity: "blob", ID: ref.Digest, Err: ErrStorageCorrupt}
+	}
+	defer dec.Close()
+	data, err := io.ReadAll(dec)
+	if err != nil {
+		return nil, &StorageError{Op: "read", Entity: "blob", ID: ref.Digest, Err: ErrStorageCorrupt}
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != ref.Digest {
+		return nil, &StorageError{Op: "read", Entity: "blob", ID: ref.Digest, Err: ErrStorageCorrupt}
+	}
+	return data, nil
+}
+
+// Delete removes the blob ref points to. Deleting a missing blob is not an
+// error. Blobs are shared by identical content, so callers must only delete
+// blobs nothing else references.
+func (b *BlobStore) Delete(ref *BlobRef) error {
+	cd, err := lookupCodec(ref.Compression)
+	if err == nil {
+		err = ref.validate()
+	}
+	if err != nil {
+		return &StorageError{Op: "delete", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+	if err := os.Remove(b.path(ref, cd)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return &StorageError{Op: "delete", Entity: "blob", ID: ref.Digest, Err: err}
+	}
+	return nil
+}
+
+// validate rejects references whose digest is not a SHA-256 hex string, so
+// a corrupt store cannot address files outside the blob directory.
+func (r *BlobRef) validate() error {
+	if len(r.Digest) != sha256.Size*2 {
+		return fmt.Errorf("%w: malformed blob digest", ErrInvalidInput)
+	}
+	if _, err := hex.DecodeString(r.Digest); err != nil {
+		return fmt.Errorf("%w: malformed blob digest", ErrInvalidInput)
+	}
+	return nil
+}
+
+// path returns the file a blob is stored in. Blobs are sharded by the first
+// two hex digits of their digest to keep directories small.
+func (b *BlobStore) path(ref *BlobRef, cd codec) string {
+	return filepath.Join(b.dir, ref.Digest[:2], ref.Digest+cd.ext)
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBlobStore_PutGet(t *testing.T) {
+	for _, compression := range []Compression{CompressionGzip, CompressionNone} {
+		t.Run(string(compression), func(t *testing.T) {
+			blobs, err := NewBlobStore(t.TempDir(), compression)
+			if err != nil {
+				t.Fatalf("NewBlobStore() error = %v", err)
+			}
+			data := []byte(strings.Repeat("never gonna give you up ", 200))
+
+			ref, err := blobs.Put(data)
+			if err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			if ref.Size != int64(len(data)) || ref.Compression != compression || len(ref.Digest) != 64 {
+				t.Errorf("ref = %+v", ref)
+			}
+			if compression == CompressionGzip && ref.StoredSize >= ref.Size/10 {
+				t.Errorf("StoredSize = %d, want compressed well below %d", ref.StoredSize, ref.Size)
+			}
+
+			got, err := blobs.Get(ref)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if string(got) != string(data) {
+				t.Error("Get() returned different content")
+			}
+
+			// Identical content is stored once
+			again, err := blobs.Put(data)
+			if err != nil || *again != *ref {
+				t.Errorf("Put() same content = %+v, %v; want %+v", again, err, ref)
+			}
+
+			if err := blobs.Delete(ref); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if _, err := blobs.Get(ref); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestBlobStore_Corruption(t *testing.T) {
+	dir := t.TempDir()
+	blobs, err := NewBlobStore(dir, CompressionNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := blobs.Put([]byte("original"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, ref.Digest[:2], ref.Digest)
+	if err := os.WriteFile(path, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blobs.Get(ref); !errors.Is(err, ErrStorageCorrupt) {
+		t.Errorf("Get() tampered blob error = %v, want ErrStorageCorrupt", err)
+	}
+
+	if _, err := blobs.Get(&BlobRef{Digest: "../../etc/passwd", Compression: CompressionNone}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Get() malformed digest error = %v, want ErrInvalidInput", err)
+	}
+	if _, err := NewBlobStore(dir, CompressionZstd); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("NewBlobStore(zstd) without a registered codec error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// SetChannelArt records data, downloaded from url, as the channel's image of
+// the given kind, writing it to the blob store if one is set. If the stored
+// image has the same URL and content, only its FetchedAt is updated. It
+// reports whether the URL or the image changed. The blob of a replaced
+// image is deleted unless something else still shares it.
+func (s *JSONStore) SetChannelArt(ctx context.Context, channelID string, kind ChannelArtKind, url, contentType string, data []byte) (bool, error) {
+	if err := s.writable("update", "channel_art"); err != nil {
+		return false, err
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if kind != ChannelAvatar && kind != ChannelBanner {
+		return false, &StorageError{Op: "update", Entity: "channel_art", ID: channelID, Err: fmt.Errorf("%w: unknown channel art kind %q", ErrInvalidInput, kind)}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channel, exists := s.data.Channels[channelID]
+	if !exists {
+		return false, &StorageError{Op: "update", Entity: "channel", ID: channelID, Err: ErrNotFound}
+	}
+
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	now := time.Now()
+	previous := channel.Art(kind)
+	if previous != nil && previous.URL == url && previous.Digest == digest {
+		previous.FetchedAt = now
+		return false, s.save()
+	}
+
+	art := &ChannelArt{
+		URL:         url,
+		ContentType: contentType,
+		Digest:      digest,
+		Size:        int64(len(data)),
+		FetchedAt:   now,
+	}
+	if s.blobs == nil {
+		art.Data = data
+	} else {
+		ref, err := s.blobs.Put(data)
+		if err != nil {
+			return false, &StorageError{Op: "write", Entity: "channel_art", ID: channelID, Err: err}
+		}
+		art.Blob = ref
+	}
+
+	setChannelArt(channel, kind, art)
+	channel.UpdatedAt = now
+	if err := s.saveLocked(); err != nil {
+		setChannelArt(channel, kind, previous)
+		s.releaseBlob(art.Blob)
+		return false, err
+	}
+	if previous != nil {
+		s.releaseBlob(previous.Blob)
+	}
+	return true, nil
+}
+
+// GetChannelArt returns the channel's image of the given kind and its
+// content. It returns ErrNotFound if the image has not been downloaded.
+func (s *JSONStore) GetChannelArt(ctx context.Context, channelID string, kind ChannelArtKind) (*ChannelArt, []byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	channel, exists := s.data.Channels[channelID]
+	if !exists {
+		return nil, nil, &StorageError{Op: "read", Entity: "channel", ID: channelID, Err: ErrNotFound}
+	}
+	art := channel.Art(kind)
+	if art == nil {
+		return nil, nil, &StorageError{Op: "read", Entity: "channel_art", ID: channelID + "/" + string(kind), Err: ErrNotFound}
+	}
+	data, err := s.loadChannelArt(art)
+	if err != nil {
+		return nil, nil, &StorageError{Op: "read", Entity: "channel_art", ID: channelID + "/" + string(kind), Err: err}
+	}
+	return art, data, nil
+}
+
+// loadChannelArt returns the content of art, reading it from the blob store
+// if it is kept there. Callers must hold s.mu.
+func (s *JSONStore) loadChannelArt(art *ChannelArt) ([]byte, error) {
+	if art.Blob == nil {
+		return art.Data, nil
+	}
+	if s.blobs == nil {
+		return nil, errNoBlobStore
+	}
+	return s.blobs.Get(art.Blob)
+}
+
+// storeChannelArt returns art with its content moved to the blob store, if
+// one is set. Callers must hold s.mu.
+func (s *JSONStore) storeChannelArt(art *ChannelArt) (*ChannelArt, error) {
+	if art == nil || art.Blob != nil || s.blobs == nil {
+		return art, nil
+	}
+	ref, err := s.blobs.Put(art.Data)
+	if err != nil {
+		return nil, err
+	}
+	stored := *art
+	stored.Data = nil
+	stored.Blob = ref
+	return &stored, nil
+}
+
+// setChannelArt sets the channel's image of the given kind.
+func setChannelArt(channel *Channel, kind ChannelArtKind, art *ChannelArt) {
+	switch kind {
+	case ChannelAvatar:
+		channel.Avatar = art
+	case ChannelBanner:
+		channel.Banner = art
+	}
+}
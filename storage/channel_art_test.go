@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONStore_ChannelArt(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewJSONStore(filepath.Join(dir, "store.json"))
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer store.Close()
+	blobs, err := NewBlobStore(filepath.Join(dir, "blobs"), CompressionNone)
+	if err != nil {
+		t.Fatalf("NewBlobStore() error = %v", err)
+	}
+	store.SetBlobStore(blobs)
+	ctx := context.Background()
+
+	channel := &Channel{YouTubeID: "UC123"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	if _, _, err := store.GetChannelArt(ctx, channel.ID, ChannelAvatar); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetChannelArt() before download error = %v, want ErrNotFound", err)
+	}
+
+	changed, err := store.SetChannelArt(ctx, channel.ID, ChannelAvatar, "https://example.com/a1", "image/jpeg", []byte("avatar one"))
+	if err != nil || !changed {
+		t.Fatalf("SetChannelArt() = %v, %v, want changed", changed, err)
+	}
+	first := channel.Avatar
+	if first == nil || first.Blob == nil || first.Data != nil {
+		t.Fatalf("Avatar = %+v, want a blob reference", first)
+	}
+
+	// Same URL and content: only FetchedAt moves
+	changed, err = store.SetChannelArt(ctx, channel.ID, ChannelAvatar, "https://example.com/a1", "image/jpeg", []byte("avatar one"))
+	if err != nil || changed {
+		t.Errorf("SetChannelArt() unchanged = %v, %v, want not changed", changed, err)
+	}
+
+	// Same URL, new content
+	changed, err = store.SetChannelArt(ctx, channel.ID, ChannelAvatar, "https://example.com/a1", "image/png", []byte("avatar two"))
+	if err != nil || !changed {
+		t.Fatalf("SetChannelArt() new content = %v, %v, want changed", changed, err)
+	}
+	if _, err := blobs.Get(first.Blob); !errors.Is(err, ErrNotFound) {
+		t.Errorf("blob of the replaced avatar error = %v, want ErrNotFound", err)
+	}
+	art, data, err := store.GetChannelArt(ctx, channel.ID, ChannelAvatar)
+	if err != nil || string(data) != "avatar two" || art.ContentType != "image/png" {
+		t.Errorf("GetChannelArt() = %+v, %q, %v", art, data, err)
+	}
+
+	// An identical banner shares the blob, which outlives the avatar
+	if _, err := store.SetChannelArt(ctx, channel.ID, ChannelBanner, "https://example.com/b", "image/png", []byte("avatar two")); err != nil {
+		t.Fatalf("SetChannelArt(banner) error = %v", err)
+	}
+	if _, err := store.SetChannelArt(ctx, channel.ID, ChannelAvatar, "https://example.com/a2", "image/png", []byte("avatar three")); err != nil {
+		t.Fatalf("SetChannelArt() error = %v", err)
+	}
+	if _, data, err := store.GetChannelArt(ctx, channel.ID, ChannelBanner); err != nil || string(data) != "avatar two" {
+		t.Errorf("GetChannelArt(banner) = %q, %v", data, err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := store.Backup(ctx, &buf); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	avatar := channel.Avatar.Blob
+	if err := store.DeleteChannel(ctx, channel.ID); err != nil {
+		t.Fatalf("DeleteChannel() error = %v", err)
+	}
+	if _, err := blobs.Get(avatar); !errors.Is(err, ErrNotFound) {
+		t.Errorf("blob of a removed channel's avatar error = %v, want ErrNotFound", err)
+	}
+
+	restored, err := NewJSONStore(filepath.Join(dir, "restored.json"))
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer restored.Close()
+	if _, err := restored.Restore(ctx, &buf); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	art, data, err = restored.GetChannelArt(ctx, channel.ID, ChannelAvatar)
+	if err != nil || string(data) != "avatar three" || art.Blob != nil {
+		t.Errorf("GetChannelArt() after Restore() = %+v, %q, %v, want the image inline", art, data, err)
+	}
+}
@@ -0,0 +1,320 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// IssueKind classifies the inconsistencies found by Check.
+type IssueKind string
+
+const (
+	// IssueDanglingIndex is an index entry naming a record that does not
+	// exist.
+	IssueDanglingIndex IssueKind = "dangling_index"
+	// IssueIndexMismatch is a record missing from an index, listed twice,
+	// or listed under the wrong key.
+	IssueIndexMismatch IssueKind = "index_mismatch"
+	// IssueOrphanTranscript is a transcript whose video does not exist.
+	IssueOrphanTranscript IssueKind = "orphan_transcript"
+	// IssueOrphanRecord is a sync state, keyword summary, or stats history
+	// whose channel or video does not exist.
+	IssueOrphanRecord IssueKind = "orphan_record"
+	// IssueFlagMismatch is a video whose HasTranscript flag disagrees with
+	// the transcripts stored.
+	IssueFlagMismatch IssueKind = "flag_mismatch"
+	// IssueOrphanVideo is a video whose channel does not exist, as left by
+	// removing a channel without its videos. Compact keeps these.
+	IssueOrphanVideo IssueKind = "orphan_video"
+)
+
+// Issue is an inconsistency found by Check.
+type Issue struct {
+	// Kind classifies the issue.
+	Kind IssueKind
+	// Entity is the kind of record or index involved, such as "video" or
+	// "videos_by_channel".
+	Entity string
+	// ID is the internal or YouTube ID the issue was found at.
+	ID string
+	// Detail describes the issue.
+	Detail string
+}
+
+// String returns a one-line description of the issue.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s %s: %s", i.Kind, i.Entity, i.ID, i.Detail)
+}
+
+// CheckReport is the result of Check or Compact.
+type CheckReport struct {
+	// Channels, Videos, and Transcripts count the records checked.
+	Channels    int
+	Videos      int
+	Transcripts int
+	// Issues are the inconsistencies found, sorted by kind, entity, and ID.
+	Issues []Issue
+}
+
+// OK reports whether no issues were found.
+func (r *CheckReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// Check verifies that the indexes of the store agree with its records and
+// that no transcript, sync state, keyword summary, or stats history is
+// left without its video or channel. It changes nothing; Compact repairs
+// what it finds.
+func (s *JSONStore) Check(ctx context.Context) (*CheckReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data.check(), nil
+}
+
+// Compact repairs the store and rewrites its file. It rebuilds the indexes
+// from the records, corrects HasTranscript flags and transcripts filed
+// under another video or language than their own, and deletes transcripts,
+// sync states, keyword summaries, and stats histories whose video or
+// channel no longer exists. Videos whose channel was removed are kept and
+// indexed under their channel ID. It returns the issues found before the
+// repair.
+func (s *JSONStore) Compact(ctx context.Context) (*CheckReport, error) {
+	if err := s.writable("write", "store"); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := s.data.check()
+	var released []*BlobRef
+	for videoID, set := range s.data.Transcripts {
+		if _, exists := s.data.Videos[videoID]; !exists {
+			delete(s.data.Transcripts, videoID)
+			for _, t := range set {
+				released = append(released, t.Blob)
+			}
+			continue
+		}
+		for language, t := range set {
+			t.VideoID, t.Language = videoID, language
+		}
+	}
+	for videoID := range s.data.VideoStats {
+		if _, exists := s.data.Videos[videoID]; !exists {
+			delete(s.data.VideoStats, videoID)
+		}
+	}
+	for videoID := range s.data.Attempts {
+		if _, exists := s.data.Videos[videoID]; !exists {
+			delete(s.data.Attempts, videoID)
+		}
+	}
+	for channelID := range s.data.SyncStates {
+		if _, exists := s.data.Channels[channelID]; !exists {
+			delete(s.data.SyncStates, channelID)
+		}
+	}
+	for channelID := range s.data.Keywords {
+		if _, exists := s.data.Channels[channelID]; !exists {
+			delete(s.data.Keywords, channelID)
+		}
+	}
+	for id, v := range s.data.Videos {
+		v.HasTranscript = len(s.data.Transcripts[id]) > 0
+	}
+	s.data.Indexes = s.data.rebuildIndexes()
+
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	for _, ref := range released {
+		s.releaseBlob(ref)
+	}
+	return report, nil
+}
+
+// check returns the inconsistencies of d.
+func (d *storeData) check() *CheckReport {
+	report := &CheckReport{
+		Channels:    len(d.Channels),
+		Videos:      len(d.Videos),
+		Transcripts: d.transcriptCount(),
+	}
+	add := func(kind IssueKind, entity, id, format string, args ...any) {
+		report.Issues = append(report.Issues, Issue{Kind: kind, Entity: entity, ID: id, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	for youtubeID, id := range d.Indexes.YouTubeChannelID {
+		ch, exists := d.Channels[id]
+		switch {
+		case !exists:
+			add(IssueDanglingIndex, "youtube_channel_id", youtubeID, "names missing channel %s", id)
+		case ch.YouTubeID != youtubeID:
+			add(IssueIndexMismatch, "youtube_channel_id", youtubeID, "names channel %s, which has YouTube ID %s", id, ch.YouTubeID)
+		}
+	}
+	for id, ch := range d.Channels {
+		if indexed, exists := d.Indexes.YouTubeChannelID[ch.YouTubeID]; !exists {
+			add(IssueIndexMismatch, "channel", id, "YouTube ID %s is not indexed", ch.YouTubeID)
+		} else if indexed != id {
+			add(IssueIndexMismatch, "channel", id, "YouTube ID %s is indexed to channel %s", ch.YouTubeID, indexed)
+		}
+	}
+
+	for youtubeID, id := range d.Indexes.YouTubeVideoID {
+		v, exists := d.Videos[id]
+		switch {
+		case !exists:
+			add(IssueDanglingIndex, "youtube_video_id", youtubeID, "names missing video %s", id)
+		case v.YouTubeID != youtubeID:
+			add(IssueIndexMismatch, "youtube_video_id", youtubeID, "names video %s, which has YouTube ID %s", id, v.YouTubeID)
+		}
+	}
+
+	listed := make(map[string]bool, len(d.Videos))
+	for channelID, ids := range d.Indexes.VideosByChannel {
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			v, exists := d.Videos[id]
+			switch {
+			case !exists:
+				add(IssueDanglingIndex, "videos_by_channel", channelID, "lists missing video %s", id)
+			case v.ChannelID != channelID:
+				add(IssueIndexMismatch, "videos_by_channel", channelID, "lists video %s of channel %s", id, v.ChannelID)
+			case seen[id]:
+				add(IssueIndexMismatch, "videos_by_channel", channelID, "lists video %s more than once", id)
+			default:
+				listed[id] = true
+			}
+			seen[id] = true
+		}
+	}
+
+	for id, v := range d.Videos {
+		if indexed, exists := d.Indexes.YouTubeVideoID[v.YouTubeID]; !exists {
+			add(IssueIndexMismatch, "video", id, "YouTube ID %s is not indexed", v.YouTubeID)
+		} else if indexed != id {
+			add(IssueIndexMismatch, "video", id, "YouTube ID %s is indexed to video %s", v.YouTubeID, indexed)
+		}
+		if !listed[id] {
+			add(IssueIndexMismatch, "video", id, "not listed under channel %s", v.ChannelID)
+		}
+		if _, exists := d.Channels[v.ChannelID]; !exists {
+			add(IssueOrphanVideo, "video", id, "channel %s does not exist", v.ChannelID)
+		}
+		if has := len(d.Transcripts[id]) > 0; has && !v.HasTranscript {
+			add(IssueFlagMismatch, "video", id, "HasTranscript is false but a transcript is stored")
+		} else if !has && v.HasTranscript {
+			add(IssueFlagMismatch, "video", id, "HasTranscript is true but no transcript is stored")
+		}
+	}
+
+	for videoID, set := range d.Transcripts {
+		if _, exists := d.Videos[videoID]; !exists {
+			add(IssueOrphanTranscript, "transcript", videoID, "video does not exist")
+		}
+		for language, t := range set {
+			if t.VideoID != videoID || t.Language != language {
+				add(IssueIndexMismatch, "transcript", transcriptKey(videoID, language), "record is for %s", transcriptKey(t.VideoID, t.Language))
+			}
+		}
+	}
+	for videoID := range d.VideoStats {
+		if _, exists := d.Videos[videoID]; !exists {
+			add(IssueOrphanRecord, "video_stats", videoID, "video does not exist")
+		}
+	}
+	for videoID := range d.Attempts {
+		if _, exists := d.Videos[videoID]; !exists {
+			add(IssueOrphanRecord, "transcript_attempts", videoID, "video does not exist")
+		}
+	}
+	for channelID := range d.SyncStates {
+		if _, exists := d.Channels[channelID]; !exists {
+			add(IssueOrphanRecord, "sync_state", channelID, "channel does not exist")
+		}
+	}
+	for channelID := range d.Keywords {
+		if _, exists := d.Channels[channelID]; !exists {
+			add(IssueOrphanRecord, "keywords", channelID, "channel does not exist")
+		}
+	}
+
+	sort.Slice(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Entity != b.Entity {
+			return a.Entity < b.Entity
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Detail < b.Detail
+	})
+	return report
+}
+
+// rebuildIndexes returns indexes built from the records of d. The order of
+// each channel's video list is kept where it was valid; videos missing
+// from it follow, oldest first. Where records share a YouTube ID, the one
+// already indexed keeps it, or else the oldest.
+func (d *storeData) rebuildIndexes() *indexes {
+	idx := newIndexes()
+
+	channels := make([]*Channel, 0, len(d.Channels))
+	for _, ch := range d.Channels {
+		channels = append(channels, ch)
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		if !channels[i].CreatedAt.Equal(channels[j].CreatedAt) {
+			return channels[i].CreatedAt.Before(channels[j].CreatedAt)
+		}
+		return channels[i].ID < channels[j].ID
+	})
+	for _, ch := range channels {
+		if _, taken := idx.YouTubeChannelID[ch.YouTubeID]; !taken || d.Indexes.YouTubeChannelID[ch.YouTubeID] == ch.ID {
+			idx.YouTubeChannelID[ch.YouTubeID] = ch.ID
+		}
+	}
+
+	videos := make([]*Video, 0, len(d.Videos))
+	for _, v := range d.Videos {
+		videos = append(videos, v)
+	}
+	sort.Slice(videos, func(i, j int) bool {
+		if !videos[i].CreatedAt.Equal(videos[j].CreatedAt) {
+			return videos[i].CreatedAt.Before(videos[j].CreatedAt)
+		}
+		return videos[i].ID < videos[j].ID
+	})
+	for _, v := range videos {
+		if _, taken := idx.YouTubeVideoID[v.YouTubeID]; !taken || d.Indexes.YouTubeVideoID[v.YouTubeID] == v.ID {
+			idx.YouTubeVideoID[v.YouTubeID] = v.ID
+		}
+	}
+
+	placed := make(map[string]bool, len(d.Videos))
+	for channelID, ids := range d.Indexes.VideosByChannel {
+		for _, id := range ids {
+			if v, exists := d.Videos[id]; exists && v.ChannelID == channelID && !placed[id] {
+				idx.VideosByChannel[channelID] = append(idx.VideosByChannel[channelID], id)
+				placed[id] = true
+			}
+		}
+	}
+	for _, v := range videos {
+		if !placed[v.ID] {
+			idx.VideosByChannel[v.ChannelID] = append(idx.VideosByChannel[v.ChannelID], v.ID)
+		}
+	}
+	return idx
+}
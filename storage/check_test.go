@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONStore_CheckAndCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	channel := &Channel{YouTubeID: "UC123", Name: "Test"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	for _, id := range []string{"vid1", "vid2", "vid3"} {
+		if err := store.CreateVideo(ctx, &Video{ID: id, ChannelID: channel.ID, YouTubeID: "yt-" + id}); err != nil {
+			t.Fatalf("CreateVideo() error = %v", err)
+		}
+	}
+	if err := store.CreateTranscript(ctx, &Transcript{VideoID: "vid1", Content: "hello"}); err != nil {
+		t.Fatalf("CreateTranscript() error = %v", err)
+	}
+
+	report, err := store.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !report.OK() || report.Videos != 3 || report.Transcripts != 1 {
+		t.Fatalf("Check() on a consistent store = %+v", report)
+	}
+
+	// Simulate the damage of a crash or a hand edit
+	store.mu.Lock()
+	store.data.Indexes.VideosByChannel[channel.ID] = []string{"vid1", "gone", "vid1"}
+	delete(store.data.Indexes.YouTubeVideoID, "yt-vid3")
+	store.data.Transcripts["gone"] = transcriptSet{"": &Transcript{VideoID: "gone"}}
+	store.data.Videos["vid2"].HasTranscript = true
+	store.data.SyncStates["missing-channel"] = &SyncState{ChannelID: "missing-channel"}
+	store.mu.Unlock()
+
+	report, err = store.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	kinds := make(map[IssueKind]int)
+	for _, issue := range report.Issues {
+		kinds[issue.Kind]++
+	}
+	want := map[IssueKind]int{
+		IssueDanglingIndex:    1, // "gone" in videos_by_channel
+		IssueIndexMismatch:    4, // vid1 twice; vid2, vid3 unlisted; vid3 unindexed
+		IssueOrphanTranscript: 1,
+		IssueFlagMismatch:     1,
+		IssueOrphanRecord:     1,
+	}
+	for kind, n := range want {
+		if kinds[kind] != n {
+			t.Errorf("Check() found %d %s issues, want %d: %v", kinds[kind], kind, n, report.Issues)
+		}
+	}
+
+	compacted, err := store.Compact(ctx)
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if len(compacted.Issues) != len(report.Issues) {
+		t.Errorf("Compact() reported %d issues, want the %d found by Check", len(compacted.Issues), len(report.Issues))
+	}
+	store.Close()
+
+	reopened, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() reopen error = %v", err)
+	}
+	defer reopened.Close()
+	report, err = reopened.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Check() after Compact() = %v", report.Issues)
+	}
+	videos, err := reopened.ListVideosByChannel(ctx, channel.ID)
+	if err != nil || len(videos) != 3 || videos[0].ID != "vid1" {
+		t.Errorf("ListVideosByChannel() = %v, %v, want vid1 first of 3", videos, err)
+	}
+	if v, err := reopened.GetVideoByYouTubeID(ctx, "yt-vid3"); err != nil || v.ID != "vid3" {
+		t.Errorf("GetVideoByYouTubeID(yt-vid3) = %v, %v", v, err)
+	}
+}
+
+func TestJSONStore_CompactKeepsOrphanVideos(t *testing.T) {
+	store, err := NewJSONStore(filepath.Join(t.TempDir(), "test.json"))
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	channel := &Channel{YouTubeID: "UC123"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	if err := store.CreateVideo(ctx, &Video{ID: "vid1", ChannelID: channel.ID, YouTubeID: "yt1"}); err != nil {
+		t.Fatalf("CreateVideo() error = %v", err)
+	}
+	if err := store.DeleteChannel(ctx, channel.ID); err != nil {
+		t.Fatalf("DeleteChannel() error = %v", err)
+	}
+
+	if _, err := store.Compact(ctx); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if _, err := store.GetVideo(ctx, "vid1"); err != nil {
+		t.Errorf("GetVideo() after Compact() error = %v", err)
+	}
+	report, err := store.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Kind != IssueOrphanVideo {
+		t.Errorf("Check() after Compact() = %v, want only the orphan video", report.Issues)
+	}
+}
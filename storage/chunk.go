@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultChunkTokens is the chunk size used when ChunkOptions.MaxTokens is
+// zero.
+const DefaultChunkTokens = 512
+
+// ChunkOptions configures Chunk.
+type ChunkOptions struct {
+	// MaxTokens is the largest chunk, as counted by Tokenizer (default
+	// DefaultChunkTokens). Only a single word longer than this can produce
+	// a larger chunk.
+	MaxTokens int
+	// Overlap is how many tokens of whole segments or sentences from the
+	// end of each chunk are repeated at the start of the next, so context
+	// spanning a boundary is not lost.
+	Overlap int
+	// Tokenizer counts the tokens in a piece of text. Use the embedding
+	// model's tokenizer for exact sizes; the default is ApproxTokens.
+	Tokenizer func(text string) int
+	// Video and Channel, if set, are copied into each chunk's metadata.
+	Video   *Video
+	Channel *Channel
+}
+
+// TranscriptChunk is a piece of a transcript sized for embedding, with the
+// metadata needed to cite it.
+type TranscriptChunk struct {
+	// Index is the chunk's zero-based position in the transcript.
+	Index int `json:"index"`
+	// Text is the chunk content.
+	Text string `json:"text"`
+	// Tokens is the size of Text as counted by the tokenizer.
+	Tokens int `json:"tokens"`
+	// Start and End are the chunk's bounds in seconds. Both are zero for
+	// transcripts without timed segments.
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	// Chapter is the title of the chapter the chunk starts in, if any.
+	Chapter string `json:"chapter,omitempty"`
+
+	// VideoID is the internal video ID; the remaining metadata is set from
+	// ChunkOptions.Video and ChunkOptions.Channel.
+	VideoID        string `json:"video_id"`
+	Language       string `json:"language,omitempty"`
+	YouTubeVideoID string `json:"youtube_video_id,omitempty"`
+	Title          string `json:"title,omitempty"`
+	ChannelID      string `json:"channel_id,omitempty"`
+	ChannelName    string `json:"channel_name,omitempty"`
+	// URL links to the video at the chunk's start time.
+	URL string `json:"url,omitempty"`
+}
+
+// ApproxTokens estimates the token count of text at four characters per
+// token, a close enough match for English with common subword tokenizers.
+func ApproxTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// chunkUnit is the smallest piece a chunk boundary may not split: a
+// segment or a sentence, or a run of words of one that is too long.
+type chunkUnit struct {
+	text        string
+	start, end  float64
+	tokens      int
+	sentenceEnd bool
+}
+
+// Chunk splits a transcript into chunks of at most opts.MaxTokens tokens.
+// Chunks are made of whole timed segments, or whole sentences for
+// transcripts without segments, and end at a sentence boundary when one
+// falls in the second half of the chunk. Segments or sentences too long
+// for a chunk on their own are split between words, with their times
+// interpolated.
+func Chunk(t *Transcript, opts ChunkOptions) []TranscriptChunk {
+	if t == nil {
+		return nil
+	}
+	maxTokens := opts.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultChunkTokens
+	}
+	count := opts.Tokenizer
+	if count == nil {
+		count = ApproxTokens
+	}
+
+	units := chunkUnits(t, maxTokens, count)
+	var chunks []TranscriptChunk
+	for i := 0; i < len(units); {
+		// Take as many units as fit
+		j, tokens := i, 0
+		for j < len(units) && (j == i || tokens+units[j].tokens <= maxTokens) {
+			tokens += units[j].tokens
+			j++
+		}
+
+		// Back up to the last sentence end in the second half of the chunk
+		end := j
+		if j < len(units) {
+			acc := 0
+			for k := i; k < j; k++ {
+				acc += units[k].tokens
+				if units[k].sentenceEnd && acc*2 >= maxTokens {
+					end = k + 1
+				}
+			}
+		}
+
+		chunks = append(chunks, newChunk(t, units[i:end], len(chunks), count, &opts))
+		if end == len(units) {
+			break
+		}
+
+		// Repeat whole units from the end, always moving forward
+		next, acc := end, 0
+		for next > i+1 && acc+units[next-1].tokens <= opts.Overlap {
+			next--
+			acc += units[next].tokens
+		}
+		i = next
+	}
+	return chunks
+}
+
+// chunkUnits splits t into the units chunks are built from.
+func chunkUnits(t *Transcript, maxTokens int, count func(string) int) []chunkUnit {
+	var units []chunkUnit
+	add := func(u chunkUnit) {
+		u.tokens = count(u.text)
+		if u.tokens > maxTokens {
+			units = append(units, splitUnit(u, maxTokens, count)...)
+			return
+		}
+		units = append(units, u)
+	}
+
+	if len(t.Segments) > 0 {
+		for _, seg := range t.Segments {
+			text := strings.Join(strings.Fields(seg.Text), " ")
+			if text == "" {
+				continue
+			}
+			add(chunkUnit{text: text, start: seg.Start, end: seg.End, sentenceEnd: endsSentence(text)})
+		}
+		return units
+	}
+	for _, sentence := range splitSentences(t.Content) {
+		add(chunkUnit{text: sentence, sentenceEnd: true})
+	}
+	return units
+}
+
+// splitUnit splits an oversized unit between words into pieces of at most
+// maxTokens, interpolating their times across the unit.
+func splitUnit(u chunkUnit, maxTokens int, count func(string) int) []chunkUnit {
+	words := strings.Fields(u.text)
+	duration := u.end - u.start
+	at := func(i int) float64 {
+		return u.start + duration*float64(i)/float64(len(words))
+	}
+
+	var pieces []chunkUnit
+	first, tokens := 0, 0
+	for i, word := range words {
+		n := count(word)
+		if i > first && tokens+n > maxTokens {
+			pieces = append(pieces, chunkUnit{text: strings.Join(words[first:i], " "), start: at(first), end: at(i), tokens: tokens})
+			first, tokens = i, 0
+		}
+		tokens += n
+	}
+	pieces = append(pieces, chunkUnit{
+		text: strings.Join(words[first:], " "), start: at(first), end: u.end, tokens: tokens, sentenceEnd: u.sentenceEnd,
+	})
+	return pieces
+}
+
+// splitSentences splits text after sentence-ending punctuation followed by
+// whitespace.
+func splitSentences(text string) []string {
+	var sentences []string
+	words := strings.Fields(text)
+	first := 0
+	for i, word := range words {
+		if endsSentence(word) || i == len(words)-1 {
+			sentences = append(sentences, strings.Join(words[first:i+1], " "))
+			first = i + 1
+		}
+	}
+	return sentences
+}
+
+// endsSentence reports whether text ends with sentence-ending punctuation,
+// ignoring closing quotes and brackets.
+func endsSentence(text string) bool {
+	text = strings.TrimRight(text, `"'”’)]`)
+	return strings.HasSuffix(text, ".") || strings.HasSuffix(text, "!") ||
+		strings.HasSuffix(text, "?") || strings.HasSuffix(text, "…")
+}
+
+func newChunk(t *Transcript, units []chunkUnit, index int, count func(string) int, opts *ChunkOptions) TranscriptChunk {
+	texts := make([]string, len(units))
+	end := 0.0
+	for i, u := range units {
+		texts[i] = u.text
+		end = max(end, u.end)
+	}
+	c := TranscriptChunk{
+		Index:    index,
+		Text:     strings.Join(texts, " "),
+		Start:    units[0].start,
+		End:      end,
+		VideoID:  t.VideoID,
+		Language: t.Language,
+	}
+	c.Tokens = count(c.Text)
+
+	for _, ch := range t.Chapters {
+		if c.Start >= ch.Start && (c.Start < ch.End || ch.End == 0) {
+			c.Chapter = ch.Title
+			break
+		}
+	}
+	if v := opts.Video; v != nil {
+		c.YouTubeVideoID = v.YouTubeID
+		c.Title = v.Title
+		c.ChannelID = v.ChannelID
+		if v.YouTubeID != "" {
+			c.URL = fmt.Sprintf("https://www.youtube.com/watch?v=%s&t=%ds", v.YouTubeID, int(c.Start))
+		}
+	}
+	if ch := opts.Channel; ch != nil {
+		c.ChannelID = ch.ID
+		c.ChannelName = ch.Name
+	}
+	return c
+}
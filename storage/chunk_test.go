@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+// wordTokens counts one token per word, which keeps expectations readable.
+func wordTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+func chunkTexts(chunks []TranscriptChunk) []string {
+	out := make([]string, len(chunks))
+	for i, c := range chunks {
+		out[i] = c.Text
+	}
+	return out
+}
+
+func TestChunk_SegmentsAndSentenceBoundaries(t *testing.T) {
+	transcript := &Transcript{
+		VideoID:  "v1",
+		Language: "en",
+		Segments: []Segment{
+			{Start: 0, End: 2, Text: "Welcome back everyone."},
+			{Start: 2, End: 4, Text: "Today we build"},
+			{Start: 4, End: 6, Text: "a bench."},
+			{Start: 6, End: 8, Text: "First the legs"},
+			{Start: 8, End: 10, Text: "are cut to length."},
+		},
+		Chapters: []Chapter{{Title: "Intro", Start: 0, End: 6}, {Title: "Legs", Start: 6, End: 10}},
+	}
+
+	chunks := Chunk(transcript, ChunkOptions{
+		MaxTokens: 8,
+		Tokenizer: wordTokens,
+		Video:     &Video{YouTubeID: "abc123", Title: "Bench", ChannelID: "c1"},
+		Channel:   &Channel{ID: "c1", Name: "Workshop"},
+	})
+
+	// The first chunk could take "First the legs" too, but ends at the sentence
+	want := []string{"Welcome back everyone. Today we build a bench.", "First the legs are cut to length."}
+	if got := chunkTexts(chunks); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("Chunk() = %q, want %q", got, want)
+	}
+	first, second := chunks[0], chunks[1]
+	if first.Start != 0 || first.End != 6 || second.Start != 6 || second.End != 10 {
+		t.Errorf("chunk times = [%v,%v] [%v,%v], want [0,6] [6,10]", first.Start, first.End, second.Start, second.End)
+	}
+	if first.Chapter != "Intro" || second.Chapter != "Legs" {
+		t.Errorf("chapters = %q, %q", first.Chapter, second.Chapter)
+	}
+	if second.Index != 1 || second.Tokens != 7 || second.VideoID != "v1" || second.Language != "en" ||
+		second.YouTubeVideoID != "abc123" || second.ChannelName != "Workshop" || second.Title != "Bench" {
+		t.Errorf("chunk metadata = %+v", second)
+	}
+	if second.URL != "https://www.youtube.com/watch?v=abc123&t=6s" {
+		t.Errorf("URL = %q", second.URL)
+	}
+}
+
+func TestChunk_Overlap(t *testing.T) {
+	transcript := &Transcript{Segments: []Segment{
+		{Start: 0, End: 1, Text: "one two"},
+		{Start: 1, End: 2, Text: "three four"},
+		{Start: 2, End: 3, Text: "five six"},
+		{Start: 3, End: 4, Text: "seven eight"},
+	}}
+
+	chunks := Chunk(transcript, ChunkOptions{MaxTokens: 4, Overlap: 2, Tokenizer: wordTokens})
+	want := []string{"one two three four", "three four five six", "five six seven eight"}
+	if got := chunkTexts(chunks); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Chunk() = %q, want %q", got, want)
+	}
+	if chunks[1].Start != 1 {
+		t.Errorf("overlapping chunk Start = %v, want 1", chunks[1].Start)
+	}
+
+	// An overlap as large as a chunk still moves forward
+	chunks = Chunk(transcript, ChunkOptions{MaxTokens: 4, Overlap: 100, Tokenizer: wordTokens})
+	if len(chunks) != 3 {
+		t.Errorf("Chunk(large overlap) = %q, want 3 chunks", chunkTexts(chunks))
+	}
+}
+
+func TestChunk_OversizedSegment(t *testing.T) {
+	transcript := &Transcript{Segments: []Segment{
+		{Start: 0, End: 6, Text: "a b c d e f"},
+	}}
+
+	chunks := Chunk(transcript, ChunkOptions{MaxTokens: 4, Tokenizer: wordTokens})
+	want := []string{"a b c d", "e f"}
+	if got := chunkTexts(chunks); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("Chunk() = %q, want %q", got, want)
+	}
+	if chunks[1].Start != 4 || chunks[1].End != 6 {
+		t.Errorf("second piece = [%v,%v], want interpolated [4,6]", chunks[1].Start, chunks[1].End)
+	}
+}
+
+func TestChunk_ContentOnly(t *testing.T) {
+	transcript := &Transcript{Content: "Short one. This sentence is a little longer! And a question? trailing words"}
+
+	chunks := Chunk(transcript, ChunkOptions{MaxTokens: 8, Tokenizer: wordTokens})
+	want := []string{"Short one. This sentence is a little longer!", "And a question? trailing words"}
+	if got := chunkTexts(chunks); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Chunk() = %q, want %q", got, want)
+	}
+	if chunks[0].Start != 0 || chunks[0].End != 0 || chunks[0].URL != "" {
+		t.Errorf("untimed chunk = %+v", chunks[0])
+	}
+
+	if got := Chunk(&Transcript{}, ChunkOptions{}); len(got) != 0 {
+		t.Errorf("Chunk(empty) = %q, want none", chunkTexts(got))
+	}
+}
+
+func TestApproxTokens(t *testing.T) {
+	if got := ApproxTokens("abcdefgh"); got != 2 {
+		t.Errorf("ApproxTokens(8 chars) = %d, want 2", got)
+	}
+	if got := ApproxTokens("héé"); got != 1 {
+		t.Errorf("ApproxTokens(3 runes) = %d, want 1", got)
+	}
+}
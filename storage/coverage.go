@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// CoverageReport summarizes how many of a channel's videos have
+// transcripts, and why the others do not.
+type CoverageReport struct {
+	// ChannelID is the internal ID of the channel.
+	ChannelID string `json:"channel_id"`
+	// Videos is the number of stored videos.
+	Videos int `json:"videos"`
+	// WithTranscript is the number of videos with at least one transcript.
+	WithTranscript int `json:"with_transcript"`
+	// Transcripts is the number of transcripts, counting each language.
+	Transcripts int `json:"transcripts"`
+	// Languages counts transcripts per language.
+	Languages map[string]int `json:"languages"`
+	// Sources counts transcripts per source, such as "youtube" or "whisper".
+	Sources map[string]int `json:"sources"`
+	// AutoGenerated is the number of transcripts from automatic captions.
+	AutoGenerated int `json:"auto_generated"`
+	// Manual is the number of transcripts from uploaded captions or other
+	// sources.
+	Manual int `json:"manual"`
+	// Missing lists the videos without a transcript, newest first.
+	Missing []MissingTranscript `json:"missing,omitempty"`
+}
+
+// MissingTranscript is a video without a transcript in a CoverageReport.
+type MissingTranscript struct {
+	// VideoID is the internal ID of the video.
+	VideoID string `json:"video_id"`
+	// YouTubeID is the YouTube video ID.
+	YouTubeID string `json:"youtube_id"`
+	// Title is the video title.
+	Title string `json:"title"`
+	// PublishedAt is when the video was published.
+	PublishedAt time.Time `json:"published_at"`
+	// Failure is why the last attempt to fetch its transcript failed, or
+	// nil if none has been recorded.
+	Failure *TranscriptFailure `json:"failure,omitempty"`
+}
+
+// Coverage returns the fraction of videos with a transcript, from 0 to 1.
+// A channel without videos has a coverage of 0.
+func (r *CoverageReport) Coverage() float64 {
+	if r.Videos == 0 {
+		return 0
+	}
+	return float64(r.WithTranscript) / float64(r.Videos)
+}
+
+// TranscriptCoverage reports the transcript coverage of the channel with
+// internal ID channelID.
+func TranscriptCoverage(ctx context.Context, store Store, channelID string) (*CoverageReport, error) {
+	if _, err := store.GetChannel(ctx, channelID); err != nil {
+		return nil, err
+	}
+	videos, err := store.ListVideosByChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	transcripts, err := store.ListTranscriptsByChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CoverageReport{
+		ChannelID: channelID,
+		Videos:    len(videos),
+		Languages: make(map[string]int),
+		Sources:   make(map[string]int),
+	}
+	transcribed := make(map[string]bool)
+	for _, t := range transcripts {
+		transcribed[t.VideoID] = true
+		report.Transcripts++
+		report.Languages[t.Language]++
+		report.Sources[t.Source]++
+		if t.AutoGenerated {
+			report.AutoGenerated++
+		} else {
+			report.Manual++
+		}
+	}
+
+	for _, v := range videos {
+		if transcribed[v.ID] {
+			report.WithTranscript++
+			continue
+		}
+		report.Missing = append(report.Missing, MissingTranscript{
+			VideoID:     v.ID,
+			YouTubeID:   v.YouTubeID,
+			Title:       v.Title,
+			PublishedAt: v.PublishedAt,
+			Failure:     v.TranscriptFailure,
+		})
+	}
+	sort.SliceStable(report.Missing, func(i, j int) bool {
+		return report.Missing[i].PublishedAt.After(report.Missing[j].PublishedAt)
+	})
+	return report, nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTranscriptCoverage(t *testing.T) {
+	store, err := NewJSONStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	channel := &Channel{YouTubeID: "UC123"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	failure := NewTranscriptFailure("v3", ErrNotFound)
+	videos := []*Video{
+		{YouTubeID: "v1", PublishedAt: day},
+		{YouTubeID: "v2", PublishedAt: day.AddDate(0, 0, 1)},
+		{YouTubeID: "v3", PublishedAt: day.AddDate(0, 0, 2), TranscriptFailure: failure},
+		{YouTubeID: "v4", PublishedAt: day.AddDate(0, 0, 3)},
+	}
+	for _, v := range videos {
+		v.ChannelID = channel.ID
+		if err := store.CreateVideo(ctx, v); err != nil {
+			t.Fatalf("CreateVideo() error = %v", err)
+		}
+	}
+	for _, tr := range []*Transcript{
+		{VideoID: videos[0].ID, Language: "en", Source: "youtube", AutoGenerated: true},
+		{VideoID: videos[0].ID, Language: "de", Source: "youtube"},
+		{VideoID: videos[1].ID, Language: "en", Source: "whisper"},
+	} {
+		if err := store.CreateTranscript(ctx, tr); err != nil {
+			t.Fatalf("CreateTranscript() error = %v", err)
+		}
+	}
+
+	report, err := TranscriptCoverage(ctx, store, channel.ID)
+	if err != nil {
+		t.Fatalf("TranscriptCoverage() error = %v", err)
+	}
+	if report.Videos != 4 || report.WithTranscript != 2 || report.Transcripts != 3 || report.Coverage() != 0.5 {
+		t.Errorf("report counts = %+v", report)
+	}
+	if report.Languages["en"] != 2 || report.Languages["de"] != 1 || report.Sources["whisper"] != 1 {
+		t.Errorf("Languages = %v, Sources = %v", report.Languages, report.Sources)
+	}
+	if report.AutoGenerated != 1 || report.Manual != 2 {
+		t.Errorf("AutoGenerated = %d, Manual = %d, want 1, 2", report.AutoGenerated, report.Manual)
+	}
+	if len(report.Missing) != 2 || report.Missing[0].YouTubeID != "v4" || report.Missing[1].YouTubeID != "v3" {
+		t.Fatalf("Missing = %+v, want v4 then v3", report.Missing)
+	}
+	if f := report.Missing[1].Failure; f == nil || f.Code != "not_found" || f.Reason != ErrNotFound.Error() {
+		t.Errorf("v3 failure = %+v", f)
+	}
+
+	if _, err := TranscriptCoverage(ctx, store, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("TranscriptCoverage() of unknown channel error = %v, want ErrNotFound", err)
+	}
+}
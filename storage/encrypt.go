@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"ytsync/errcode"
+)
+
+// ErrEncryptionKey indicates stored data could not be decrypted, because no
+// key was given for an encrypted store or the key does not match.
+var ErrEncryptionKey = errcode.New(errcode.InvalidInput, "storage: missing or wrong encryption key")
+
+// encryptedMagic prefixes every file written through an Encryptor, so
+// encrypted and plain files can be told apart on load whatever the cipher.
+var encryptedMagic = []byte("YTSYNC-ENC1\n")
+
+// Encryptor seals data written to disk and opens it again on read. The
+// store file and blobs are passed through it whole. Implementations must be
+// safe for concurrent use. AESGCM is the built-in implementation; other
+// schemes, such as age, can be plugged in by implementing this interface.
+type Encryptor interface {
+	// Seal encrypts plaintext.
+	Seal(plaintext []byte) ([]byte, error)
+	// Open decrypts data returned by Seal. It should accept data sealed
+	// with earlier keys during a key rotation.
+	Open(ciphertext []byte) ([]byte, error)
+}
+
+// sealFile encrypts data for writing with enc, or returns it unchanged if
+// enc is nil.
+func sealFile(enc Encryptor, data []byte) ([]byte, error) {
+	if enc == nil {
+		return data, nil
+	}
+	sealed, err := enc.Seal(data)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt: %w", err)
+	}
+	return append(append([]byte(nil), encryptedMagic...), sealed...), nil
+}
+
+// openFile decrypts data read from disk with enc. Plain data is returned
+// unchanged, so stores written before encryption was enabled stay readable.
+func openFile(enc Encryptor, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, encryptedMagic) {
+		return data, nil
+	}
+	if enc == nil {
+		return nil, ErrEncryptionKey
+	}
+	plain, err := enc.Open(data[len(encryptedMagic):])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEncryptionKey, err)
+	}
+	return plain, nil
+}
+
+// keyIDSize is the length of the key fingerprint stored with AESGCM data.
+const keyIDSize = 8
+
+// AESGCM encrypts with AES in Galois/Counter Mode. Sealed data records a
+// fingerprint of the key it was sealed with, so data sealed with a previous
+// key can still be opened while a store is being re-keyed.
+type AESGCM struct {
+	primary [keyIDSize]byte
+	keys    map[[keyIDSize]byte]cipher.AEAD
+}
+
+// NewAESGCM creates an encryptor that seals with key and opens data sealed
+// with key or any of previous. Keys must be 16, 24, or 32 bytes long,
+// selecting AES-128, AES-192, or AES-256.
+func NewAESGCM(key []byte, previous ...[]byte) (*AESGCM, error) {
+	e := &AESGCM{keys: make(map[[keyIDSize]byte]cipher.AEAD)}
+	for i, k := range append([][]byte{key}, previous...) {
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		id := keyID(k)
+		if i == 0 {
+			e.primary = id
+		}
+		if _, ok := e.keys[id]; !ok {
+			e.keys[id] = aead
+		}
+	}
+	return e, nil
+}
+
+// ParseAESGCMKeys creates an AESGCM encryptor from keys in hex or base64,
+// as given in configuration. key seals new data; key and previous are
+// accepted when opening.
+func ParseAESGCMKeys(key string, previous ...string) (*AESGCM, error) {
+	primary, err := ParseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	var older [][]byte
+	for _, p := range previous {
+		k, err := ParseKey(p)
+		if err != nil {
+			return nil, err
+		}
+		older = append(older, k)
+	}
+	return NewAESGCM(primary, older...)
+}
+
+// Seal encrypts plaintext with the primary key under a random nonce.
+func (e *AESGCM) Seal(plaintext []byte) ([]byte, error) {
+	aead := e.keys[e.primary]
+	out := make([]byte, keyIDSize+aead.NonceSize(), keyIDSize+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	copy(out, e.primary[:])
+	if _, err := rand.Read(out[keyIDSize:]); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, out[keyIDSize:], plaintext, nil), nil
+}
+
+// Open decrypts data sealed with any of the encryptor's keys.
+func (e *AESGCM) Open(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < keyIDSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	var id [keyIDSize]byte
+	copy(id[:], ciphertext)
+	aead, ok := e.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("sealed with an unknown key %x", id)
+	}
+	rest := ciphertext[keyIDSize:]
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], nil)
+}
+
+// keyID returns the fingerprint recorded with data sealed by key.
+func keyID(key []byte) [keyIDSize]byte {
+	sum := sha256.Sum256(key)
+	var id [keyIDSize]byte
+	copy(id[:], sum[:])
+	return id
+}
+
+// GenerateKey returns a random 32-byte AES-256 key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ParseKey decodes a key given as hex or base64, as in configuration or
+// environment variables. The decoded key must be 16, 24, or 32 bytes.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	decoders := []func(string) ([]byte, error){
+		hex.DecodeString,
+		base64.StdEncoding.DecodeString,
+		base64.RawStdEncoding.DecodeString,
+		base64.URLEncoding.DecodeString,
+		base64.RawURLEncoding.DecodeString,
+	}
+	for _, decode := range decoders {
+		if key, err := decode(s); err == nil {
+			switch len(key) {
+			case 16, 24, 32:
+				return key, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: encryption key must be 16, 24, or 32 bytes in hex or base64", ErrInvalidInput)
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mustAESGCM(t *testing.T, key []byte, previous ...[]byte) *AESGCM {
+	t.Helper()
+	enc, err := NewAESGCM(key, previous...)
+	if err != nil {
+		t.Fatalf("NewAESGCM() error = %v", err)
+	}
+	return enc
+}
+
+func TestAESGCM(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 16)
+
+	old := mustAESGCM(t, oldKey)
+	sealed, err := old.Seal([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Error("sealed data contains the plaintext")
+	}
+
+	// A rotated encryptor opens data sealed with the previous key
+	rotated := mustAESGCM(t, newKey, oldKey)
+	if plain, err := rotated.Open(sealed); err != nil || string(plain) != "secret" {
+		t.Errorf("Open() with previous key = %q, %v", plain, err)
+	}
+	if _, err := mustAESGCM(t, newKey).Open(sealed); err == nil {
+		t.Error("Open() with an unrelated key succeeded")
+	}
+
+	sealed[len(sealed)-1] ^= 1
+	if _, err := old.Open(sealed); err == nil {
+		t.Error("Open() accepted tampered data")
+	}
+
+	if _, err := NewAESGCM([]byte("short")); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("NewAESGCM(short key) error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key, _ := GenerateKey()
+	for _, s := range []string{hex.EncodeToString(key), " " + hex.EncodeToString(key) + "\n"} {
+		if got, err := ParseKey(s); err != nil || !bytes.Equal(got, key) {
+			t.Errorf("ParseKey(%q) = %x, %v", s, got, err)
+		}
+	}
+	if got, err := ParseKey("AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE="); err != nil || len(got) != 32 {
+		t.Errorf("ParseKey(base64) = %x, %v", got, err)
+	}
+	if _, err := ParseKey("not a key"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("ParseKey(invalid) error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestJSONStore_Encryption(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+	ctx := context.Background()
+	key := bytes.Repeat([]byte{7}, 32)
+
+	// A plain store is encrypted on its next write
+	plain, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.CreateChannel(ctx, &Channel{YouTubeID: "UCplain", Name: "Internal Channel"}); err != nil {
+		t.Fatal(err)
+	}
+	plain.Close()
+
+	store, err := NewJSONStoreWithOptions(path, &JSONStoreOptions{Encryptor: mustAESGCM(t, key)})
+	if err != nil {
+		t.Fatalf("NewJSONStoreWithOptions() on plain store error = %v", err)
+	}
+	if err := store.CreateChannel(ctx, &Channel{YouTubeID: "UCsecret", Name: "Unlisted Channel"}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	raw, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(raw, encryptedMagic) || bytes.Contains(raw, []byte("Channel")) {
+		t.Fatalf("store file is not encrypted: %.60q", raw)
+	}
+
+	if _, err := NewJSONStore(path); !errors.Is(err, ErrEncryptionKey) {
+		t.Errorf("NewJSONStore() without key error = %v, want ErrEncryptionKey", err)
+	}
+	wrong := &JSONStoreOptions{Encryptor: mustAESGCM(t, bytes.Repeat([]byte{8}, 32))}
+	if _, err := NewJSONStoreWithOptions(path, wrong); !errors.Is(err, ErrEncryptionKey) {
+		t.Errorf("NewJSONStoreWithOptions() with wrong key error = %v, want ErrEncryptionKey", err)
+	}
+
+	store, err = NewJSONStoreWithOptions(path, &JSONStoreOptions{Encryptor: mustAESGCM(t, key)})
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer store.Close()
+	if channels, _ := store.ListChannels(ctx); len(channels) != 2 {
+		t.Errorf("ListChannels() = %d channels, want 2", len(channels))
+	}
+}
+
+func TestJSONStore_RotateKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+	ctx := context.Background()
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	store, err := NewJSONStoreWithOptions(path, &JSONStoreOptions{Encryptor: mustAESGCM(t, oldKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := NewBlobStore(filepath.Join(dir, "blobs"), CompressionNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs.SetEncryptor(mustAESGCM(t, oldKey))
+	store.SetBlobStore(blobs)
+
+	text := strings.Repeat("confidential transcript ", 50)
+	if err := store.CreateTranscript(ctx, &Transcript{VideoID: "vid", Content: text}); err != nil {
+		t.Fatal(err)
+	}
+	transcript, _ := store.GetTranscript(ctx, "vid")
+	blobPath := filepath.Join(dir, "blobs", transcript.Blob.Digest[:2], transcript.Blob.Digest)
+	if raw, _ := os.ReadFile(blobPath); bytes.Contains(raw, []byte("confidential")) {
+		t.Fatal("blob is not encrypted")
+	}
+
+	if err := store.RotateKey(ctx, mustAESGCM(t, newKey, oldKey)); err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+	store.Close()
+
+	// Only the new key is needed afterwards
+	store, err = NewJSONStoreWithOptions(path, &JSONStoreOptions{Encryptor: mustAESGCM(t, newKey)})
+	if err != nil {
+		t.Fatalf("reopen with new key error = %v", err)
+	}
+	defer store.Close()
+	reopened, _ := NewBlobStore(filepath.Join(dir, "blobs"), CompressionNone)
+	reopened.SetEncryptor(mustAESGCM(t, newKey))
+	store.SetBlobStore(reopened)
+	if got, err := store.GetTranscript(ctx, "vid"); err != nil || got.Content != text {
+		t.Errorf("GetTranscript() after rotation = %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+	"ytsync/errcode"
+)
+
+const (
+	// lockPollInterval is how often a held lock is retried.
+	lockPollInterval = 10 * time.Millisecond
+
+	// lockHeartbeat is how often the holder of an exclusive lock refreshes
+	// the lock file, to show processes on other hosts that it still runs.
+	lockHeartbeat = time.Minute
+)
+
+var (
+	// ErrStoreLocked indicates another process holds the lock on a store or
+	// cache file. Errors from FileLock and NewJSONStore match it with
+	// errors.Is, and errors.As extracts the *LockError naming the holder.
+	ErrStoreLocked = errcode.New(errcode.Unavailable, "storage: locked by another process")
+	// ErrReadOnly is returned by writes to a store opened read-only.
+	ErrReadOnly = errcode.New(errcode.InvalidInput, "storage: store is opened read-only")
+)
+
+// LockPolicy controls how a lock held by another process is waited for.
+type LockPolicy struct {
+	// Timeout is how long to wait for the lock. Zero or negative tries
+	// once and fails at once if the lock is held.
+	Timeout time.Duration
+	// StaleAfter breaks the lock of a process on another host, whose
+	// liveness cannot be checked, once it has not been refreshed for this
+	// long (0 = never). Holders refresh their locks every minute while
+	// they run, so this should be several minutes. Locks of processes on
+	// this host are broken as soon as the process is gone, whatever their
+	// age.
+	StaleAfter time.Duration
+}
+
+// DefaultLockPolicy returns the policy used when none is given: wait up to
+// five seconds and never break the locks of other hosts.
+func DefaultLockPolicy() LockPolicy {
+	return LockPolicy{Timeout: lockTimeout}
+}
+
+// LockHolder identifies the process holding an exclusive lock. It is
+// written to the lock file when the lock is acquired.
+type LockHolder struct {
+	// PID is the holder's process ID.
+	PID int `json:"pid"`
+	// Host is the hostname of the holder's machine.
+	Host string `json:"host"`
+	// Since is when the lock was acquired.
+	Since time.Time `json:"since"`
+	// Refreshed is when the holder last showed it still runs, or zero if
+	// it has not yet, or is an older version that does not.
+	Refreshed time.Time `json:"refreshed,omitzero"`
+}
+
+// stale reports whether the holder can no longer be holding the lock: it
+// ran on this host and has exited, or it ran elsewhere and has not
+// refreshed the lock for longer than policy.StaleAfter.
+func (h *LockHolder) stale(policy LockPolicy, host string, now time.Time) bool {
+	if h.Host == host {
+		return h.PID > 0 && !processAlive(h.PID)
+	}
+	seen := h.Since
+	if h.Refreshed.After(seen) {
+		seen = h.Refreshed
+	}
+	return policy.StaleAfter > 0 && now.Sub(seen) > policy.StaleAfter
+}
+
+// LockError is returned when a lock is still held by another process after
+// the wait allowed by the LockPolicy. It matches ErrStoreLocked and, for
+// compatibility, ErrLockTimeout.
+type LockError struct {
+	// Path is the lock file.
+	Path string
+	// Holder is the process holding the lock, or nil if it is unknown,
+	// as for shared locks and locks taken by older versions.
+	Holder *LockHolder
+}
+
+// Error returns a message naming the holder when it is known.
+func (e *LockError) Error() string {
+	if e.Holder == nil {
+		return fmt.Sprintf("storage: %s is locked by another process", e.Path)
+	}
+	return fmt.Sprintf("storage: %s is locked by process %d on %s since %s",
+		e.Path, e.Holder.PID, e.Holder.Host, e.Holder.Since.Local().Format(time.RFC3339))
+}
+
+// Unwrap returns ErrStoreLocked and ErrLockTimeout.
+func (e *LockError) Unwrap() []error {
+	return []error{ErrStoreLocked, ErrLockTimeout}
+}
+
+// FileLock provides advisory file locking for cross-process synchronization,
+// using flock(2) on Unix-like systems and LockFileEx on Windows. Exclusive
+// locks record their holder in the lock file, and refresh it while held,
+// so a lock left behind by a process that no longer runs can be recognized
+// and broken.
+type FileLock struct {
+	path string
+	file *os.File
+	// keep leaves the lock file in place on Unlock, for locks that are
+	// also taken shared.
+	keep bool
+	// host is the host name recorded in the lock file and compared with
+	// that of other holders (default: os.Hostname()).
+	host string
+	// heartbeat is how often the holder is refreshed (default:
+	// lockHeartbeat).
+	heartbeat time.Duration
+	// stop ends the refreshes, which close done once they have.
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewFileLock creates a file lock. The lock is not acquired until Lock() is called.
+// The lock file will be created at path + ".lock".
+func NewFileLock(path string) *FileLock {
+	return &FileLock{path: path + ".lock"}
+}
+
+// Lock acquires an exclusive lock with the specified timeout, breaking the
+// lock of a holder on this host that has exited. It returns a *LockError
+// if the lock cannot be acquired within the timeout.
+func (l *FileLock) Lock(timeout time.Duration) error {
+	return l.LockWithPolicy(LockPolicy{Timeout: timeout})
+}
+
+// LockWithPolicy acquires an exclusive lock, waiting and breaking stale
+// locks as policy allows.
+func (l *FileLock) LockWithPolicy(policy LockPolicy) error {
+	return l.acquire(true, policy)
+}
+
+// RLock acquires a shared lock with the specified timeout. Any number of
+// processes may hold a shared lock at once, but none while another holds
+// the exclusive lock. Shared locks do not record their holders.
+func (l *FileLock) RLock(timeout time.Duration) error {
+	return l.acquire(false, LockPolicy{Timeout: timeout})
+}
+
+func (l *FileLock) acquire(exclusive bool, policy LockPolicy) error {
+	deadline := time.Now().Add(policy.Timeout)
+	host := l.host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	broken := false
+	for {
+		f, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0600)
+		if err != nil {
+			return &StorageError{Op: "lock", Entity: "file", ID: l.path, Err: err}
+		}
+		if err := tryLock(f, exclusive); err == nil {
+			// The holder we waited for may have removed the file before
+			// releasing it; a lock on a removed file protects nothing.
+			if !l.keep && !sameFile(f, l.path) {
+				unlockFile(f)
+				f.Close()
+				continue
+			}
+			l.file = f
+			if exclusive && !l.keep {
+				l.hold(host)
+			}
+			return nil
+		}
+		f.Close()
+
+		holder := readLockHolder(l.path)
+		if exclusive && !l.keep && !broken && holder != nil && holder.stale(policy, host, time.Now()) {
+			// Another process can hold the lock of the removed file
+			// forever without blocking us.
+			broken = true
+			os.Remove(l.path)
+			continue
+		}
+		if !time.Now().Before(deadline) {
+			return &LockError{Path: l.path, Holder: holder}
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// Unlock releases the lock.
+func (l *FileLock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+	if l.stop != nil {
+		close(l.stop)
+		<-l.done
+		l.stop, l.done = nil, nil
+	}
+	unlockFile(l.file)
+	l.file.Close()
+	if !l.keep {
+		os.Remove(l.path)
+	}
+	l.file = nil
+	return nil
+}
+
+// hold records this process as the holder of the lock, and refreshes the
+// record until Unlock.
+func (l *FileLock) hold(host string) {
+	holder := LockHolder{PID: os.Getpid(), Host: host, Since: time.Now()}
+	if err := l.file.Truncate(0); err != nil {
+		return
+	}
+	size := writeLockHolder(l.file, holder, 0)
+
+	interval := l.heartbeat
+	if interval <= 0 {
+		interval = lockHeartbeat
+	}
+	f, stop, done := l.file, make(chan struct{}), make(chan struct{})
+	l.stop, l.done = stop, done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				holder.Refreshed = now
+				size = writeLockHolder(f, holder, size)
+			}
+		}
+	}()
+}
+
+// writeLockHolder records holder in the lock file f, which holds size
+// bytes, and returns its new size. The record is written over the old one,
+// padded with spaces to its length, so processes reading the file never
+// find it empty. A failure only leaves the holder unknown to processes
+// waiting for the lock.
+func writeLockHolder(f *os.File, holder LockHolder, size int) int {
+	data, err := json.Marshal(holder)
+	if err != nil {
+		return size
+	}
+	if pad := size - len(data); pad > 0 {
+		data = append(data, bytes.Repeat([]byte(" "), pad)...)
+	}
+	if _, err := f.WriteAt(data, 0); err != nil {
+		return size
+	}
+	return len(data)
+}
+
+// readLockHolder returns the holder recorded in the lock file at path, or
+// nil if there is none.
+func readLockHolder(path string) *LockHolder {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	var holder LockHolder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return nil
+	}
+	return &holder
+}
+
+// sameFile reports whether f is still the file at path.
+func sameFile(f *os.File, path string) bool {
+	opened, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(opened, current)
+}
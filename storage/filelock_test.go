@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileLock_Shared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	r1, r2 := NewFileLock(path), NewFileLock(path)
+	r1.keep, r2.keep = true, true
+	if err := r1.RLock(0); err != nil {
+		t.Fatalf("RLock() error = %v", err)
+	}
+	if err := r2.RLock(0); err != nil {
+		t.Fatalf("second RLock() error = %v", err)
+	}
+
+	w := &FileLock{path: path + ".lock", keep: true}
+	err := w.Lock(0)
+	if !errors.Is(err, ErrStoreLocked) || !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("Lock() while shared = %v, want ErrStoreLocked and ErrLockTimeout", err)
+	}
+
+	r1.Unlock()
+	r2.Unlock()
+	if err := w.Lock(0); err != nil {
+		t.Fatalf("Lock() after RUnlock error = %v", err)
+	}
+	w.Unlock()
+}
+
+// holdLock takes the lock of path and records holder as its holder.
+func holdLock(t *testing.T, path string, holder LockHolder) *FileLock {
+	t.Helper()
+	l := NewFileLock(path)
+	if err := l.Lock(0); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	t.Cleanup(func() { l.Unlock() })
+	data, _ := json.Marshal(holder)
+	l.file.Truncate(0)
+	l.file.WriteAt(data, 0)
+	return l
+}
+
+func TestFileLock_BreaksLockOfExitedProcess(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("run helper process: %v", err)
+	}
+	host, _ := os.Hostname()
+	path := filepath.Join(t.TempDir(), "store.json")
+	holdLock(t, path, LockHolder{PID: cmd.Process.Pid, Host: host, Since: time.Now()})
+
+	l := NewFileLock(path)
+	if err := l.Lock(0); err != nil {
+		t.Fatalf("Lock() over an exited holder error = %v", err)
+	}
+	defer l.Unlock()
+	if holder := readLockHolder(l.path); holder == nil || holder.PID != os.Getpid() {
+		t.Errorf("holder = %+v, want this process", holder)
+	}
+}
+
+func TestFileLock_StaleAfter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	holdLock(t, path, LockHolder{PID: 1, Host: "elsewhere", Since: time.Now().Add(-time.Hour)})
+
+	err := NewFileLock(path).LockWithPolicy(LockPolicy{})
+	var lockErr *LockError
+	if !errors.As(err, &lockErr) || lockErr.Holder == nil || lockErr.Holder.Host != "elsewhere" {
+		t.Fatalf("LockWithPolicy() = %v, want a LockError naming the holder", err)
+	}
+
+	l := NewFileLock(path)
+	if err := l.LockWithPolicy(LockPolicy{StaleAfter: time.Minute}); err != nil {
+		t.Fatalf("LockWithPolicy(StaleAfter) error = %v", err)
+	}
+	l.Unlock()
+}
+
+func TestFileLock_StaleAfterLiveHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	holder := NewFileLock(path)
+	holder.host, holder.heartbeat = "elsewhere", 10*time.Millisecond
+	if err := holder.Lock(0); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+
+	policy := LockPolicy{StaleAfter: 100 * time.Millisecond}
+	time.Sleep(3 * policy.StaleAfter)
+	err := NewFileLock(path).LockWithPolicy(policy)
+	if !errors.Is(err, ErrStoreLocked) {
+		t.Fatalf("LockWithPolicy() over a live holder = %v, want ErrStoreLocked", err)
+	}
+	if h := readLockHolder(holder.path); h == nil || h.Refreshed.IsZero() {
+		t.Errorf("holder = %+v, want a refreshed holder", h)
+	}
+
+	holder.Unlock()
+	l := NewFileLock(path)
+	if err := l.LockWithPolicy(policy); err != nil {
+		t.Fatalf("LockWithPolicy() after Unlock error = %v", err)
+	}
+	l.Unlock()
+}
+
+func TestJSONStore_ReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	ctx := context.Background()
+
+	if _, err := NewJSONStoreWithOptions(path, &JSONStoreOptions{ReadOnly: true}); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("read-only open of a missing store error = %v, want ErrNotExist", err)
+	}
+
+	writer, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer writer.Close()
+	if err := writer.CreateChannel(ctx, &Channel{ID: "ch1", YouTubeID: "UC1"}); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+
+	_, err = NewJSONStoreWithOptions(path, &JSONStoreOptions{Lock: &LockPolicy{}})
+	var lockErr *LockError
+	if !errors.Is(err, ErrStoreLocked) || !errors.As(err, &lockErr) || lockErr.Holder == nil || lockErr.Holder.PID != os.Getpid() {
+		t.Fatalf("second writable open error = %v, want ErrStoreLocked naming this process", err)
+	}
+
+	reader, err := NewJSONStoreWithOptions(path, &JSONStoreOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("read-only open error = %v", err)
+	}
+	defer reader.Close()
+	if _, err := reader.GetChannel(ctx, "ch1"); err != nil {
+		t.Errorf("GetChannel() error = %v", err)
+	}
+
+	if err := reader.CreateChannel(ctx, &Channel{ID: "ch2", YouTubeID: "UC2"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("CreateChannel() on a read-only store error = %v, want ErrReadOnly", err)
+	}
+	if _, err := reader.GetChannel(ctx, "ch2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetChannel() of a rejected channel error = %v, want ErrNotFound", err)
+	}
+	if err := reader.UpdateChannel(ctx, &Channel{ID: "ch1", YouTubeID: "UC1", Name: "Renamed"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("UpdateChannel() on a read-only store error = %v, want ErrReadOnly", err)
+	}
+	if ch, err := reader.GetChannel(ctx, "ch1"); err != nil || ch.Name != "" {
+		t.Errorf("GetChannel() after a rejected update = %+v, %v, want the channel unchanged", ch, err)
+	}
+	if _, err := reader.Compact(ctx); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Compact() on a read-only store error = %v, want ErrReadOnly", err)
+	}
+
+	if err := writer.CreateChannel(ctx, &Channel{ID: "ch3", YouTubeID: "UC3"}); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	if err := reader.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if _, err := reader.GetChannel(ctx, "ch3"); err != nil {
+		t.Errorf("GetChannel() after Reload() error = %v", err)
+	}
+}
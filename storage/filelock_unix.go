@@ -3,55 +3,27 @@
 package storage
 
 import (
+	"errors"
 	"os"
 	"syscall"
-	"time"
 )
 
-// FileLock provides advisory file locking for cross-process synchronization.
-// This uses flock(2) system call which is available on Unix-like systems.
-type FileLock struct {
-	path string
-	file *os.File
-}
-
-// NewFileLock creates a file lock. The lock is not acquired until Lock() is called.
-// The lock file will be created at path + ".lock".
-func NewFileLock(path string) *FileLock {
-	return &FileLock{path: path + ".lock"}
-}
-
-// Lock acquires an exclusive lock with the specified timeout.
-// Returns ErrLockTimeout if the lock cannot be acquired within the timeout.
-func (l *FileLock) Lock(timeout time.Duration) error {
-	var err error
-	l.file, err = os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0600)
-	if err != nil {
-		return &StorageError{Op: "lock", Entity: "file", ID: l.path, Err: err}
-	}
-
-	deadline := time.Now().Add(timeout)
-	for time.Now().Before(deadline) {
-		err = syscall.Flock(int(l.file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
-		if err == nil {
-			return nil
-		}
-		time.Sleep(10 * time.Millisecond)
+// tryLock takes an exclusive or shared flock(2) lock on f without blocking.
+func tryLock(f *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
 	}
+	return syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB)
+}
 
-	l.file.Close()
-	l.file = nil
-	return ErrLockTimeout
+// unlockFile releases the lock on f.
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
 }
 
-// Unlock releases the lock.
-func (l *FileLock) Unlock() error {
-	if l.file == nil {
-		return nil
-	}
-	syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
-	l.file.Close()
-	os.Remove(l.path)
-	l.file = nil
-	return nil
+// processAlive reports whether a process with the given ID is running.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
 }
@@ -4,84 +4,46 @@ package storage
 
 import (
 	"os"
-	"time"
 
 	"golang.org/x/sys/windows"
 )
 
-// FileLock provides advisory file locking for cross-process synchronization.
-// This uses LockFileEx on Windows.
-type FileLock struct {
-	path string
-	file *os.File
-}
-
-// NewFileLock creates a file lock. The lock is not acquired until Lock() is called.
-// The lock file will be created at path + ".lock".
-func NewFileLock(path string) *FileLock {
-	return &FileLock{path: path + ".lock"}
-}
-
-// Lock acquires an exclusive lock with the specified timeout.
-// Returns ErrLockTimeout if the lock cannot be acquired within the timeout.
-func (l *FileLock) Lock(timeout time.Duration) error {
-	var err error
-	l.file, err = os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0600)
-	if err != nil {
-		return &StorageError{Op: "lock", Entity: "file", ID: l.path, Err: err}
-	}
-
-	deadline := time.Now().Add(timeout)
-	for time.Now().Before(deadline) {
-		// Try to acquire exclusive lock without blocking
-		err = lockFile(l.file)
-		if err == nil {
-			return nil
-		}
-		time.Sleep(10 * time.Millisecond)
-	}
+// lockOffsetHigh places the locked byte far past the holder record, since
+// other processes cannot read a byte range locked with LockFileEx.
+const lockOffsetHigh = 0x7fffffff
 
-	l.file.Close()
-	l.file = nil
-	return ErrLockTimeout
-}
+// stillActive is the exit code GetExitCodeProcess reports for a running
+// process.
+const stillActive = 259
 
-// Unlock releases the lock.
-func (l *FileLock) Unlock() error {
-	if l.file == nil {
-		return nil
+// tryLock takes an exclusive or shared lock on f using the Windows API,
+// without blocking.
+func tryLock(f *os.File, exclusive bool) error {
+	flags := uint32(windows.LOCKFILE_FAIL_IMMEDIATELY)
+	if exclusive {
+		flags |= windows.LOCKFILE_EXCLUSIVE_LOCK
 	}
-	unlockFile(l.file)
-	l.file.Close()
-	os.Remove(l.path)
-	l.file = nil
-	return nil
+	overlapped := windows.Overlapped{OffsetHigh: lockOffsetHigh}
+	return windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, 1, 0, &overlapped)
 }
 
-// lockFile acquires an exclusive lock on the file using Windows API.
-func lockFile(f *os.File) error {
-	var overlapped windows.Overlapped
-	// LOCKFILE_EXCLUSIVE_LOCK | LOCKFILE_FAIL_IMMEDIATELY
-	err := windows.LockFileEx(
-		windows.Handle(f.Fd()),
-		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY,
-		0,
-		1,
-		0,
-		&overlapped,
-	)
-	return err
+// unlockFile releases the lock on f using the Windows API.
+func unlockFile(f *os.File) error {
+	overlapped := windows.Overlapped{OffsetHigh: lockOffsetHigh}
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &overlapped)
 }
 
-// unlockFile releases the lock on the file using Windows API.
-func unlockFile(f *os.File) error {
-	var overlapped windows.Overlapped
-	err := windows.UnlockFileEx(
-		windows.Handle(f.Fd()),
-		0,
-		1,
-		0,
-		&overlapped,
-	)
-	return err
+// processAlive reports whether a process with the given ID is running.
+func processAlive(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access denied means the process exists but belongs to another user
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return true
+	}
+	return code == stillActive
 }
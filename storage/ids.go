@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"github.com/google/uuid"
+)
+
+// IDGenerator returns the internal ID of a new entity ("channel" or
+// "video") with the given YouTube ID.
+type IDGenerator func(entity, youtubeID string) string
+
+// DefaultIDNamespace is the namespace DeterministicIDs uses when given the
+// zero UUID: the UUIDv5 of https://www.youtube.com/ in the URL namespace.
+var DefaultIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://www.youtube.com/"))
+
+// RandomIDs gives every entity a random UUID. It is the default.
+func RandomIDs(entity, youtubeID string) string {
+	return uuid.NewString()
+}
+
+// DeterministicIDs derives IDs from YouTube IDs as UUIDv5s in namespace, so
+// stores built from the same channels give their channels and videos the
+// same IDs. Entities without a YouTube ID still get random UUIDs.
+func DeterministicIDs(namespace uuid.UUID) IDGenerator {
+	if namespace == uuid.Nil {
+		namespace = DefaultIDNamespace
+	}
+	return func(entity, youtubeID string) string {
+		if youtubeID == "" {
+			return uuid.NewString()
+		}
+		return uuid.NewSHA1(namespace, []byte(entity+":"+youtubeID)).String()
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDeterministicIDs(t *testing.T) {
+	ctx := context.Background()
+	ids := DeterministicIDs(uuid.Nil)
+
+	// createAll stores one channel and video and returns their IDs.
+	createAll := func(t *testing.T, store Store) (string, string) {
+		t.Helper()
+		channel := &Channel{YouTubeID: "UCdeterministic", Name: "Deterministic"}
+		if err := store.CreateChannel(ctx, channel); err != nil {
+			t.Fatalf("CreateChannel() error = %v", err)
+		}
+		video := &Video{YouTubeID: "dQw4w9WgXcQ", ChannelID: channel.ID, Title: "Video"}
+		if err := store.CreateVideo(ctx, video); err != nil {
+			t.Fatalf("CreateVideo() error = %v", err)
+		}
+		return channel.ID, video.ID
+	}
+
+	jsonStore, err := NewJSONStoreWithOptions(filepath.Join(t.TempDir(), "store.json"), &JSONStoreOptions{IDs: ids})
+	if err != nil {
+		t.Fatalf("NewJSONStoreWithOptions() error = %v", err)
+	}
+	defer jsonStore.Close()
+	memoryStore := NewMemoryStore()
+	memoryStore.SetIDs(ids)
+
+	channelID, videoID := createAll(t, jsonStore)
+	if got, want := channelID, ids("channel", "UCdeterministic"); got != want {
+		t.Errorf("channel ID = %s, want %s", got, want)
+	}
+	if v, err := uuid.Parse(videoID); err != nil || v.Version() != 5 {
+		t.Errorf("video ID = %s, want a UUIDv5", videoID)
+	}
+	if gotChannel, gotVideo := createAll(t, memoryStore); gotChannel != channelID || gotVideo != videoID {
+		t.Errorf("second store IDs = %s, %s, want %s, %s", gotChannel, gotVideo, channelID, videoID)
+	}
+
+	other := DeterministicIDs(uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://example.com/")))
+	if other("channel", "UCdeterministic") == channelID {
+		t.Error("IDs in another namespace match the default namespace")
+	}
+	if ids("channel", "") == ids("channel", "") {
+		t.Error("IDs without a YouTube ID are not random")
+	}
+
+	random := NewMemoryStore()
+	if gotChannel, _ := createAll(t, random); gotChannel == channelID {
+		t.Error("default IDs are not random")
+	}
+}
@@ -1,38 +1,101 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"sort"
 	"sync"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 const (
-	schemaVersion = "1.0"
+	schemaVersion = "1.1"
 	lockTimeout   = 5 * time.Second
+
+	// maxSyncReports is the number of sync reports kept per channel.
+	maxSyncReports = 50
+
+	// maxStatsSamples is the number of stats samples kept per video.
+	maxStatsSamples = 1000
+
+	// maxTranscriptAttempts is the number of transcript attempts kept per
+	// video.
+	maxTranscriptAttempts = 20
 )
 
 // JSONStore implements Store using a single JSON file.
 type JSONStore struct {
-	path string
-	lock *FileLock
-	data *storeData
-	mu   sync.RWMutex
+	*storeState
+
+	// batch marks the view of the store a Batch passes to its fn: saves of
+	// the writes made through it are deferred to the end of the batch.
+	batch bool
+}
+
+// storeState is the state a JSONStore shares with the views Batch makes
+// of it.
+type storeState struct {
+	path     string
+	lock     *FileLock
+	access   *FileLock
+	readOnly bool
+	data     *storeData
+	blobs    *BlobStore
+	enc      Encryptor
+	ids      IDGenerator
+	mu       sync.RWMutex
+
+	// dirty records that a batch deferred a save, and released the blobs
+	// to release once it is made.
+	dirty    bool
+	released []*BlobRef
+
+	// writeMu orders the writes of the store file. snapshots counts the
+	// snapshots save has taken, under mu; written numbers the newest one
+	// on disk, under writeMu.
+	writeMu   sync.Mutex
+	snapshots uint64
+	written   uint64
+}
+
+// JSONStoreOptions configures NewJSONStoreWithOptions.
+type JSONStoreOptions struct {
+	// Encryptor, if set, encrypts the store file at rest. A plain store
+	// file is still read and is encrypted on the next write.
+	Encryptor Encryptor
+	// ReadOnly opens the store without taking its lock, so it can be read
+	// while another process has it open for writing. Writes fail with
+	// ErrReadOnly, and the store must already exist. Call Reload to see
+	// what the writer has saved since.
+	ReadOnly bool
+	// Lock controls how long to wait for a store another process has open,
+	// and when to break a stale lock (default: DefaultLockPolicy()).
+	Lock *LockPolicy
+	// IDs gives new channels and videos stored without an ID their IDs
+	// (default: RandomIDs).
+	IDs IDGenerator
 }
 
 // storeData is the top-level JSON structure.
 type storeData struct {
-	Version     string                 `json:"version"`
-	UpdatedAt   time.Time              `json:"updated_at"`
-	Channels    map[string]*Channel    `json:"channels"`
-	Videos      map[string]*Video      `json:"videos"`
-	Transcripts map[string]*Transcript `json:"transcripts"`
-	SyncStates  map[string]*SyncState  `json:"sync_states"`
-	Indexes     *indexes               `json:"indexes"`
+	Version     string                          `json:"version"`
+	UpdatedAt   time.Time                       `json:"updated_at"`
+	Channels    map[string]*Channel             `json:"channels"`
+	Videos      map[string]*Video               `json:"videos"`
+	Transcripts map[string]transcriptSet        `json:"transcripts"` // video_id -> language -> transcript
+	SyncStates  map[string]*SyncState           `json:"sync_states"`
+	SyncReports map[string][]*SyncReport        `json:"sync_reports,omitempty"`
+	Quota       map[string]*QuotaUsage          `json:"quota,omitempty"` // key -> current day's usage
+	Aliases     map[string]*ChannelAlias        `json:"channel_aliases,omitempty"`
+	VideoStats  map[string][]*VideoStats        `json:"video_stats,omitempty"`         // video_id -> samples, oldest first
+	Attempts    map[string][]*TranscriptAttempt `json:"transcript_attempts,omitempty"` // video_id -> attempts, oldest first
+	Keywords    map[string]*ChannelKeywords     `json:"keywords,omitempty"`            // channel_id -> summary
+	Downloads   map[string]*ArchivedDownload    `json:"downloads,omitempty"`           // youtube video_id -> record
+	Indexes     *indexes                        `json:"indexes"`
 }
 
 // indexes maintains lookup tables for efficient queries.
@@ -45,13 +108,35 @@ type indexes struct {
 // NewJSONStore creates a new JSON file store at the given path.
 // If the file exists, it is loaded; otherwise an empty store is created.
 func NewJSONStore(path string) (*JSONStore, error) {
-	s := &JSONStore{
-		path: path,
-		lock: NewFileLock(path),
+	return NewJSONStoreWithOptions(path, nil)
+}
+
+// NewJSONStoreWithOptions creates a JSON file store at path with options.
+// Opening an encrypted store without its key fails with ErrEncryptionKey.
+func NewJSONStoreWithOptions(path string, opts *JSONStoreOptions) (*JSONStore, error) {
+	if opts == nil {
+		opts = &JSONStoreOptions{}
+	}
+	s := &JSONStore{storeState: &storeState{
+		path:     path,
+		lock:     NewFileLock(path),
+		access:   &FileLock{path: path + ".access.lock", keep: true},
+		readOnly: opts.ReadOnly,
+		enc:      opts.Encryptor,
+		ids:      opts.IDs,
+	}}
+	if s.ids == nil {
+		s.ids = RandomIDs
 	}
 
-	if err := s.lock.Lock(lockTimeout); err != nil {
-		return nil, err
+	if !s.readOnly {
+		policy := DefaultLockPolicy()
+		if opts.Lock != nil {
+			policy = *opts.Lock
+		}
+		if err := s.lock.LockWithPolicy(policy); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := s.load(); err != nil {
@@ -62,54 +147,243 @@ func NewJSONStore(path string) (*JSONStore, error) {
 	return s, nil
 }
 
-// load reads the JSON file into memory. Creates empty data if file doesn't exist.
+// load reads the JSON file into memory. Creates empty data if file doesn't
+// exist, unless the store is read-only.
 func (s *JSONStore) load() error {
-	data, err := os.ReadFile(s.path)
+	data, err := s.readFile()
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
+		if errors.Is(err, os.ErrNotExist) && !s.readOnly {
 			s.data = newStoreData()
 			// Save immediately to catch permission errors early
-			return s.save()
+			return s.saveLocked()
 		}
 		return &StorageError{Op: "read", Entity: "store", Err: err}
 	}
+	plain, err := openFile(s.enc, data)
+	if err != nil {
+		return &StorageError{Op: "read", Entity: "store", Err: err}
+	}
+	version, err := documentVersion(plain)
+	if err != nil {
+		return &StorageError{Op: "read", Entity: "store", Err: err}
+	}
+	migrated, err := migrateDocument(plain)
+	if err != nil {
+		return &StorageError{Op: "migrate", Entity: "store", Err: err}
+	}
 
-	s.data = &storeData{}
-	if err := json.Unmarshal(data, s.data); err != nil {
+	loaded := &storeData{}
+	if err := json.Unmarshal(migrated, loaded); err != nil {
 		return &StorageError{Op: "read", Entity: "store", Err: ErrStorageCorrupt}
 	}
+	loaded.ensureMaps()
+	s.data = loaded
 
-	// Ensure indexes exist
-	if s.data.Indexes == nil {
-		s.data.Indexes = newIndexes()
+	if version != schemaVersion && !s.readOnly {
+		// Keep the file as it was, in case the upgrade has to be undone
+		if err := writeFileAtomic(migrationBackupPath(s.path, version), data); err != nil {
+			return &StorageError{Op: "migrate", Entity: "store", Err: err}
+		}
+		if err := s.saveLocked(); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// ensureMaps fills in the indexes and the collections that stores written
+// by earlier versions, or backups of them, may lack.
+func (d *storeData) ensureMaps() {
+	if d.Channels == nil {
+		d.Channels = make(map[string]*Channel)
+	}
+	if d.Videos == nil {
+		d.Videos = make(map[string]*Video)
+	}
+	if d.Transcripts == nil {
+		d.Transcripts = make(map[string]transcriptSet)
+	}
+	if d.SyncStates == nil {
+		d.SyncStates = make(map[string]*SyncState)
+	}
+	if d.Indexes == nil {
+		d.Indexes = newIndexes()
+	}
+	if d.SyncReports == nil {
+		d.SyncReports = make(map[string][]*SyncReport)
+	}
+	if d.Quota == nil {
+		d.Quota = make(map[string]*QuotaUsage)
+	}
+	if d.Aliases == nil {
+		d.Aliases = make(map[string]*ChannelAlias)
+	}
+	if d.VideoStats == nil {
+		d.VideoStats = make(map[string][]*VideoStats)
+	}
+	if d.Attempts == nil {
+		d.Attempts = make(map[string][]*TranscriptAttempt)
+	}
+	if d.Keywords == nil {
+		d.Keywords = make(map[string]*ChannelKeywords)
+	}
+	if d.Downloads == nil {
+		d.Downloads = make(map[string]*ArchivedDownload)
+	}
+}
+
+// readFile reads the store file under a shared lock, so a writer never
+// replaces it mid-read, which fails on Windows.
+func (s *JSONStore) readFile() ([]byte, error) {
+	if err := s.access.RLock(lockTimeout); err != nil {
+		return nil, err
+	}
+	defer s.access.Unlock()
+	return os.ReadFile(s.path)
+}
 
+// writable returns an error matching ErrReadOnly if the store is opened
+// read-only. Every write calls it before changing anything, so a rejected
+// write leaves the store as it was.
+func (s *JSONStore) writable(op, entity string) error {
+	if s.readOnly {
+		return &StorageError{Op: op, Entity: entity, Err: ErrReadOnly}
+	}
 	return nil
 }
 
-// save persists the data to disk atomically.
+// save persists the data to disk atomically. Callers must hold s.mu for
+// writing: save takes a snapshot of the data under it, then releases it
+// while the snapshot is written, so readers and other writers are not held
+// off by the disk, and takes it again before returning. Saves of writes
+// made through a Batch's view of the store are deferred to the batch's end.
 func (s *JSONStore) save() error {
+	data, seq, err := s.snapshot()
+	if err != nil || data == nil {
+		return err
+	}
+	s.mu.Unlock()
+	defer s.mu.Lock()
+	return s.write(data, seq)
+}
+
+// saveLocked is save without releasing s.mu, for callers that undo their
+// change if it cannot be saved, and for load.
+func (s *JSONStore) saveLocked() error {
+	data, seq, err := s.snapshot()
+	if err != nil || data == nil {
+		return err
+	}
+	return s.write(data, seq)
+}
+
+// snapshot encodes the data for save, numbering it so write can skip it
+// once a newer one is on disk. It returns no data if the save is deferred
+// to the end of a batch. Callers must hold s.mu for writing.
+func (s *JSONStore) snapshot() ([]byte, uint64, error) {
+	if s.readOnly {
+		return nil, 0, &StorageError{Op: "write", Entity: "store", Err: ErrReadOnly}
+	}
+	if s.batch {
+		s.dirty = true
+		return nil, 0, nil
+	}
 	s.data.UpdatedAt = time.Now()
 
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s.data); err != nil {
+		return nil, 0, &StorageError{Op: "write", Entity: "store", Err: err}
+	}
+	data, err := sealFile(s.enc, buf.Bytes())
+	if err != nil {
+		return nil, 0, &StorageError{Op: "write", Entity: "store", Err: err}
+	}
+	s.snapshots++
+	return data, s.snapshots, nil
+}
+
+// write replaces the store file with snapshot seq, unless a newer snapshot
+// has already been written.
+func (s *JSONStore) write(data []byte, seq uint64) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if seq <= s.written {
+		return nil
+	}
+
 	writer, err := NewAtomicWriter(s.path)
 	if err != nil {
 		return &StorageError{Op: "write", Entity: "store", Err: err}
 	}
-
-	encoder := json.NewEncoder(writer)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(s.data); err != nil {
+	if _, err := writer.Write(data); err != nil {
 		writer.Abort()
 		return &StorageError{Op: "write", Entity: "store", Err: err}
 	}
 
-	if err := writer.Commit(); err != nil {
+	if err := s.access.Lock(lockTimeout); err != nil {
+		writer.Abort()
 		return &StorageError{Op: "write", Entity: "store", Err: err}
 	}
-
+	err = writer.Commit()
+	s.access.Unlock()
+	if err != nil {
+		return &StorageError{Op: "write", Entity: "store", Err: err}
+	}
+	s.written = seq
 	return nil
 }
 
+// Reload re-reads the store file, picking up changes saved by other
+// processes since the store was opened. It is meant for stores opened
+// read-only next to a writer; a writable store is the only writer of its
+// file.
+func (s *JSONStore) Reload(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Batch runs fn with a view of the store whose writes are saved once, at
+// the end of the batch, rather than each on its own. Writes through the
+// view return before they are saved; writes through the store itself are
+// saved as usual, along with whatever the batch has written so far.
+// Readers are held off only while each write and the final save run, not
+// for the whole of fn. If the final save fails, Batch returns its error,
+// and otherwise fn's. A Batch of the view is saved with the outer one.
+func (s *JSONStore) Batch(ctx context.Context, fn func(Store) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if s.batch {
+		return fn(s)
+	}
+
+	err := fn(&JSONStore{storeState: s.storeState, batch: true})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	released := s.released
+	s.released = nil
+	if s.dirty {
+		s.dirty = false
+		if saveErr := s.save(); saveErr != nil {
+			// Leave the save to the next batch, or the next write
+			s.dirty = true
+			s.released = append(released, s.released...)
+			return saveErr
+		}
+	}
+	for _, ref := range released {
+		s.releaseBlob(ref)
+	}
+	return err
+}
+
 // Close releases resources held by the store.
 func (s *JSONStore) Close() error {
 	s.mu.Lock()
@@ -117,14 +391,107 @@ func (s *JSONStore) Close() error {
 	return s.lock.Unlock()
 }
 
+// SetBlobStore moves transcript text out of the JSON file: from now on the
+// Content, Segments, and Chapters of created and updated transcripts are
+// written to blobs and the store keeps only a BlobRef. Reads fill the text
+// back in. Transcripts stored inline before are left as they are until
+// updated or moved with MoveTranscriptsToBlobs. Pass nil to store text
+// inline again; transcripts already in blobs then cannot be read.
+func (s *JSONStore) SetBlobStore(blobs *BlobStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs = blobs
+}
+
+// MoveTranscriptsToBlobs moves the text of every transcript stored inline
+// into the blob store and returns how many were moved.
+func (s *JSONStore) MoveTranscriptsToBlobs(ctx context.Context) (int, error) {
+	if err := s.writable("update", "transcript"); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.blobs == nil {
+		return 0, &StorageError{Op: "update", Entity: "transcript", Err: errNoBlobStore}
+	}
+	moved := 0
+moving:
+	for _, set := range s.data.Transcripts {
+		for language, transcript := range set {
+			if transcript.Blob != nil {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				break moving
+			}
+			stored, err := s.storeTranscript(transcript)
+			if err != nil {
+				return moved, err
+			}
+			set[language] = stored
+			moved++
+		}
+	}
+	if moved == 0 {
+		return 0, ctx.Err()
+	}
+	if err := s.save(); err != nil {
+		return 0, err
+	}
+	return moved, ctx.Err()
+}
+
+// RotateKey re-encrypts the store file, and the blobs of its blob store if
+// one is set, with enc. enc should also accept the current key, so blobs
+// not yet re-encrypted stay readable if rotation is interrupted; run it
+// again to finish. A nil enc decrypts the store. The store must not be open
+// in other processes during rotation.
+func (s *JSONStore) RotateKey(ctx context.Context, enc Encryptor) error {
+	if err := s.writable("write", "store"); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.blobs != nil {
+		if _, err := s.blobs.RotateKey(ctx, enc); err != nil {
+			return err
+		}
+	}
+	previous := s.enc
+	s.enc = enc
+	if err := s.saveLocked(); err != nil {
+		s.enc = previous
+		return err
+	}
+	return nil
+}
+
+// transcriptCount returns the number of transcripts in all languages.
+func (d *storeData) transcriptCount() int {
+	n := 0
+	for _, set := range d.Transcripts {
+		n += len(set)
+	}
+	return n
+}
+
 func newStoreData() *storeData {
 	return &storeData{
 		Version:     schemaVersion,
 		UpdatedAt:   time.Now(),
 		Channels:    make(map[string]*Channel),
 		Videos:      make(map[string]*Video),
-		Transcripts: make(map[string]*Transcript),
+		Transcripts: make(map[string]transcriptSet),
 		SyncStates:  make(map[string]*SyncState),
+		SyncReports: make(map[string][]*SyncReport),
+		Quota:       make(map[string]*QuotaUsage),
+		Aliases:     make(map[string]*ChannelAlias),
+		VideoStats:  make(map[string][]*VideoStats),
+		Attempts:    make(map[string][]*TranscriptAttempt),
+		Keywords:    make(map[string]*ChannelKeywords),
+		Downloads:   make(map[string]*ArchivedDownload),
 		Indexes:     newIndexes(),
 	}
 }
@@ -140,11 +507,14 @@ func newIndexes() *indexes {
 // --- ChannelStore implementation ---
 
 func (s *JSONStore) CreateChannel(ctx context.Context, channel *Channel) error {
+	if err := s.writable("create", "channel"); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if channel.ID == "" {
-		channel.ID = uuid.NewString()
+		channel.ID = s.ids("channel", channel.YouTubeID)
 	}
 
 	if _, exists := s.data.Channels[channel.ID]; exists {
@@ -192,7 +562,28 @@ func (s *JSONStore) GetChannelByYouTubeID(ctx context.Context, youtubeID string)
 	return channel, nil
 }
 
+func (s *JSONStore) GetChannelByHandle(ctx context.Context, handle string) (*Channel, error) {
+	if id := ChannelIDFromInput(handle); id != "" {
+		return s.GetChannelByYouTubeID(ctx, id)
+	}
+	alias, _, ok := NormalizeChannelAlias(handle)
+	if !ok {
+		return nil, &StorageError{Op: "read", Entity: "channel", ID: handle, Err: ErrInvalidInput}
+	}
+
+	s.mu.RLock()
+	mapping, exists := s.data.Aliases[alias]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, &StorageError{Op: "read", Entity: "channel", ID: handle, Err: ErrNotFound}
+	}
+	return s.GetChannelByYouTubeID(ctx, mapping.YouTubeID)
+}
+
 func (s *JSONStore) UpdateChannel(ctx context.Context, channel *Channel) error {
+	if err := s.writable("update", "channel"); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -214,6 +605,9 @@ func (s *JSONStore) UpdateChannel(ctx context.Context, channel *Channel) error {
 }
 
 func (s *JSONStore) DeleteChannel(ctx context.Context, id string) error {
+	if err := s.writable("delete", "channel"); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -226,8 +620,17 @@ func (s *JSONStore) DeleteChannel(ctx context.Context, id string) error {
 	delete(s.data.Indexes.YouTubeChannelID, channel.YouTubeID)
 	delete(s.data.Indexes.VideosByChannel, id)
 	delete(s.data.SyncStates, id)
+	delete(s.data.Keywords, id)
 
-	return s.save()
+	if err := s.save(); err != nil {
+		return err
+	}
+	for _, art := range []*ChannelArt{channel.Avatar, channel.Banner} {
+		if art != nil {
+			s.releaseBlob(art.Blob)
+		}
+	}
+	return nil
 }
 
 func (s *JSONStore) ListChannels(ctx context.Context) ([]*Channel, error) {
@@ -244,11 +647,14 @@ func (s *JSONStore) ListChannels(ctx context.Context) ([]*Channel, error) {
 // --- VideoStore implementation ---
 
 func (s *JSONStore) CreateVideo(ctx context.Context, video *Video) error {
+	if err := s.writable("create", "video"); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if video.ID == "" {
-		video.ID = uuid.NewString()
+		video.ID = s.ids("video", video.YouTubeID)
 	}
 
 	if _, exists := s.data.Videos[video.ID]; exists {
@@ -299,6 +705,9 @@ func (s *JSONStore) GetVideoByYouTubeID(ctx context.Context, youtubeID string) (
 }
 
 func (s *JSONStore) UpdateVideo(ctx context.Context, video *Video) error {
+	if err := s.writable("update", "video"); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -320,6 +729,9 @@ func (s *JSONStore) UpdateVideo(ctx context.Context, video *Video) error {
 }
 
 func (s *JSONStore) DeleteVideo(ctx context.Context, id string) error {
+	if err := s.writable("delete", "video"); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -330,7 +742,10 @@ func (s *JSONStore) DeleteVideo(ctx context.Context, id string) error {
 
 	delete(s.data.Videos, id)
 	delete(s.data.Indexes.YouTubeVideoID, video.YouTubeID)
+	transcripts := s.data.Transcripts[id]
 	delete(s.data.Transcripts, id)
+	delete(s.data.VideoStats, id)
+	delete(s.data.Attempts, id)
 
 	// Remove from channel index
 	channelVideos := s.data.Indexes.VideosByChannel[video.ChannelID]
@@ -342,7 +757,13 @@ func (s *JSONStore) DeleteVideo(ctx context.Context, id string) error {
 		}
 	}
 
-	return s.save()
+	if err := s.save(); err != nil {
+		return err
+	}
+	for _, t := range transcripts {
+		s.releaseBlob(t.Blob)
+	}
+	return nil
 }
 
 func (s *JSONStore) ListVideosByChannel(ctx context.Context, channelID string) ([]*Video, error) {
@@ -374,19 +795,61 @@ func (s *JSONStore) ListVideosNeedingTranscript(ctx context.Context) ([]*Video,
 
 // --- TranscriptStore implementation ---
 
+// transcriptSet holds the transcripts of one video by language.
+type transcriptSet map[string]*Transcript
+
+// ordered returns the transcripts oldest first, by language for ties. The
+// first is the video's primary transcript.
+func (ts transcriptSet) ordered() []*Transcript {
+	transcripts := make([]*Transcript, 0, len(ts))
+	for _, t := range ts {
+		transcripts = append(transcripts, t)
+	}
+	sort.Slice(transcripts, func(i, j int) bool {
+		if !transcripts[i].CreatedAt.Equal(transcripts[j].CreatedAt) {
+			return transcripts[i].CreatedAt.Before(transcripts[j].CreatedAt)
+		}
+		return transcripts[i].Language < transcripts[j].Language
+	})
+	return transcripts
+}
+
+// transcriptKey identifies the transcript of a video in a language in
+// errors.
+func transcriptKey(videoID, language string) string {
+	if language == "" {
+		return videoID
+	}
+	return videoID + "/" + language
+}
+
 func (s *JSONStore) CreateTranscript(ctx context.Context, transcript *Transcript) error {
+	if err := s.writable("create", "transcript"); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.data.Transcripts[transcript.VideoID]; exists {
-		return &StorageError{Op: "create", Entity: "transcript", ID: transcript.VideoID, Err: ErrAlreadyExists}
+	if _, exists := s.data.Transcripts[transcript.VideoID][transcript.Language]; exists {
+		return &StorageError{Op: "create", Entity: "transcript", ID: transcriptKey(transcript.VideoID, transcript.Language), Err: ErrAlreadyExists}
 	}
 
+	transcript.normalizeSegments()
+
 	now := time.Now()
 	transcript.CreatedAt = now
 	transcript.UpdatedAt = now
 
-	s.data.Transcripts[transcript.VideoID] = transcript
+	stored, err := s.storeTranscript(transcript)
+	if err != nil {
+		return err
+	}
+	set := s.data.Transcripts[transcript.VideoID]
+	if set == nil {
+		set = make(transcriptSet)
+		s.data.Transcripts[transcript.VideoID] = set
+	}
+	set[transcript.Language] = stored
 
 	// Update video's HasTranscript flag
 	if video, exists := s.data.Videos[transcript.VideoID]; exists {
@@ -401,44 +864,119 @@ func (s *JSONStore) GetTranscript(ctx context.Context, videoID string) (*Transcr
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	transcript, exists := s.data.Transcripts[videoID]
-	if !exists {
+	transcripts := s.data.Transcripts[videoID].ordered()
+	if len(transcripts) == 0 {
 		return nil, &StorageError{Op: "read", Entity: "transcript", ID: videoID, Err: ErrNotFound}
 	}
-	return transcript, nil
+	return s.loadTranscript(transcripts[0])
+}
+
+func (s *JSONStore) GetTranscriptByLanguage(ctx context.Context, videoID, language string) (*Transcript, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	transcript, exists := s.data.Transcripts[videoID][language]
+	if !exists {
+		return nil, &StorageError{Op: "read", Entity: "transcript", ID: transcriptKey(videoID, language), Err: ErrNotFound}
+	}
+	return s.loadTranscript(transcript)
+}
+
+func (s *JSONStore) ListTranscriptLanguages(ctx context.Context, videoID string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	transcripts := s.data.Transcripts[videoID].ordered()
+	languages := make([]string, len(transcripts))
+	for i, t := range transcripts {
+		languages[i] = t.Language
+	}
+	return languages, nil
 }
 
 func (s *JSONStore) UpdateTranscript(ctx context.Context, transcript *Transcript) error {
+	if err := s.writable("update", "transcript"); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.data.Transcripts[transcript.VideoID]; !exists {
-		return &StorageError{Op: "update", Entity: "transcript", ID: transcript.VideoID, Err: ErrNotFound}
+	previous, exists := s.data.Transcripts[transcript.VideoID][transcript.Language]
+	if !exists {
+		return &StorageError{Op: "update", Entity: "transcript", ID: transcriptKey(transcript.VideoID, transcript.Language), Err: ErrNotFound}
 	}
 
+	transcript.normalizeSegments()
 	transcript.UpdatedAt = time.Now()
-	s.data.Transcripts[transcript.VideoID] = transcript
+	stored, err := s.storeTranscript(transcript)
+	if err != nil {
+		return err
+	}
+	s.data.Transcripts[transcript.VideoID][transcript.Language] = stored
 
-	return s.save()
+	if err := s.save(); err != nil {
+		return err
+	}
+	s.releaseBlob(previous.Blob)
+	return nil
 }
 
 func (s *JSONStore) DeleteTranscript(ctx context.Context, videoID string) error {
+	if err := s.writable("delete", "transcript"); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.data.Transcripts[videoID]; !exists {
+	previous, exists := s.data.Transcripts[videoID]
+	if !exists {
 		return &StorageError{Op: "delete", Entity: "transcript", ID: videoID, Err: ErrNotFound}
 	}
 
 	delete(s.data.Transcripts, videoID)
+	s.clearTranscriptFlag(videoID)
 
-	// Update video's HasTranscript flag
+	if err := s.save(); err != nil {
+		return err
+	}
+	for _, t := range previous {
+		s.releaseBlob(t.Blob)
+	}
+	return nil
+}
+
+func (s *JSONStore) DeleteTranscriptByLanguage(ctx context.Context, videoID, language string) error {
+	if err := s.writable("delete", "transcript"); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, exists := s.data.Transcripts[videoID][language]
+	if !exists {
+		return &StorageError{Op: "delete", Entity: "transcript", ID: transcriptKey(videoID, language), Err: ErrNotFound}
+	}
+
+	delete(s.data.Transcripts[videoID], language)
+	if len(s.data.Transcripts[videoID]) == 0 {
+		delete(s.data.Transcripts, videoID)
+		s.clearTranscriptFlag(videoID)
+	}
+
+	if err := s.save(); err != nil {
+		return err
+	}
+	s.releaseBlob(previous.Blob)
+	return nil
+}
+
+// clearTranscriptFlag marks the video with internal ID videoID as having
+// no transcript. Callers must hold s.mu.
+func (s *JSONStore) clearTranscriptFlag(videoID string) {
 	if video, exists := s.data.Videos[videoID]; exists {
 		video.HasTranscript = false
 		video.UpdatedAt = time.Now()
 	}
-
-	return s.save()
 }
 
 func (s *JSONStore) ListTranscriptsByChannel(ctx context.Context, channelID string) ([]*Transcript, error) {
@@ -448,13 +986,108 @@ func (s *JSONStore) ListTranscriptsByChannel(ctx context.Context, channelID stri
 	videoIDs := s.data.Indexes.VideosByChannel[channelID]
 	var transcripts []*Transcript
 	for _, videoID := range videoIDs {
-		if transcript, exists := s.data.Transcripts[videoID]; exists {
-			transcripts = append(transcripts, transcript)
+		for _, transcript := range s.data.Transcripts[videoID].ordered() {
+			loaded, err := s.loadTranscript(transcript)
+			if err != nil {
+				return nil, err
+			}
+			transcripts = append(transcripts, loaded)
 		}
 	}
 	return transcripts, nil
 }
 
+// errNoBlobStore is returned for transcripts and channel images kept in a
+// blob store when none is configured.
+var errNoBlobStore = fmt.Errorf("%w: content is in a blob store but none is configured", ErrInvalidInput)
+
+// transcriptBody is the part of a transcript kept in the blob store.
+type transcriptBody struct {
+	Content  string    `json:"content"`
+	Segments []Segment `json:"segments,omitempty"`
+	Chapters []Chapter `json:"chapters,omitempty"`
+}
+
+// storeTranscript returns the record to keep in the JSON file for t. With a
+// blob store, its text is written to a blob and the record holds only the
+// reference. Callers must hold s.mu.
+func (s *JSONStore) storeTranscript(t *Transcript) (*Transcript, error) {
+	stored := *t
+	if s.blobs == nil {
+		stored.Blob = nil
+		return &stored, nil
+	}
+
+	data, err := json.Marshal(transcriptBody{Content: t.Content, Segments: t.Segments, Chapters: t.Chapters})
+	if err != nil {
+		return nil, &StorageError{Op: "write", Entity: "transcript", ID: t.VideoID, Err: err}
+	}
+	ref, err := s.blobs.Put(data)
+	if err != nil {
+		return nil, &StorageError{Op: "write", Entity: "transcript", ID: t.VideoID, Err: err}
+	}
+	stored.Content = ""
+	stored.Segments = nil
+	stored.Chapters = nil
+	stored.Blob = ref
+	return &stored, nil
+}
+
+// loadTranscript returns t with its text read back from the blob store if
+// it was stored there. Callers must hold s.mu.
+func (s *JSONStore) loadTranscript(t *Transcript) (*Transcript, error) {
+	if t.Blob == nil {
+		return t, nil
+	}
+	if s.blobs == nil {
+		return nil, &StorageError{Op: "read", Entity: "transcript", ID: t.VideoID, Err: errNoBlobStore}
+	}
+	data, err := s.blobs.Get(t.Blob)
+	if err != nil {
+		return nil, &StorageError{Op: "read", Entity: "transcript", ID: t.VideoID, Err: err}
+	}
+	var body transcriptBody
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, &StorageError{Op: "read", Entity: "transcript", ID: t.VideoID, Err: ErrStorageCorrupt}
+	}
+	loaded := *t
+	loaded.Content = body.Content
+	loaded.Segments = body.Segments
+	loaded.Chapters = body.Chapters
+	return &loaded, nil
+}
+
+// releaseBlob deletes the blob ref points to unless another transcript or
+// channel image still shares it. Callers must hold s.mu.
+func (s *JSONStore) releaseBlob(ref *BlobRef) {
+	if ref == nil || s.blobs == nil {
+		return
+	}
+	if s.batch {
+		// The file still names the blob until the batch is saved
+		s.released = append(s.released, ref)
+		return
+	}
+	shares := func(other *BlobRef) bool {
+		return other != nil && other.Digest == ref.Digest && other.Compression == ref.Compression
+	}
+	for _, set := range s.data.Transcripts {
+		for _, t := range set {
+			if shares(t.Blob) {
+				return
+			}
+		}
+	}
+	for _, ch := range s.data.Channels {
+		for _, art := range []*ChannelArt{ch.Avatar, ch.Banner} {
+			if art != nil && shares(art.Blob) {
+				return
+			}
+		}
+	}
+	s.blobs.Delete(ref) // Best effort; a failure only leaves an orphaned file
+}
+
 // --- SyncStateStore implementation ---
 
 func (s *JSONStore) GetSyncState(ctx context.Context, channelID string) (*SyncState, error) {
@@ -469,6 +1102,9 @@ func (s *JSONStore) GetSyncState(ctx context.Context, channelID string) (*SyncSt
 }
 
 func (s *JSONStore) UpdateSyncState(ctx context.Context, state *SyncState) error {
+	if err := s.writable("update", "sync_state"); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -486,3 +1122,371 @@ func (s *JSONStore) GetLastSync(ctx context.Context, channelID string) (time.Tim
 	}
 	return state.LastSyncAt, nil
 }
+
+// --- SyncReportStore implementation ---
+
+// SaveSyncReport appends report to its channel's history, dropping the
+// oldest reports beyond the retention limit.
+func (s *JSONStore) SaveSyncReport(ctx context.Context, report *SyncReport) error {
+	if err := s.writable("create", "sync_report"); err != nil {
+		return err
+	}
+	if report == nil || report.ChannelID == "" {
+		return &StorageError{Op: "create", Entity: "sync_report", Err: ErrInvalidInput}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reports := append(s.data.SyncReports[report.ChannelID], report)
+	if len(reports) > maxSyncReports {
+		reports = reports[len(reports)-maxSyncReports:]
+	}
+	s.data.SyncReports[report.ChannelID] = reports
+	return s.save()
+}
+
+// ListSyncReports returns the stored reports for channelID, oldest first.
+func (s *JSONStore) ListSyncReports(ctx context.Context, channelID string) ([]*SyncReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reports := s.data.SyncReports[channelID]
+	out := make([]*SyncReport, len(reports))
+	copy(out, reports)
+	return out, nil
+}
+
+// --- VideoStatsStore implementation ---
+
+// RecordVideoStats appends a sample to the video's history, dropping the
+// oldest samples beyond the retention limit. Samples are kept in
+// SampledAt order even if recorded out of order.
+func (s *JSONStore) RecordVideoStats(ctx context.Context, stats *VideoStats) error {
+	if err := s.writable("create", "video_stats"); err != nil {
+		return err
+	}
+	if stats == nil || stats.VideoID == "" {
+		return &StorageError{Op: "create", Entity: "video_stats", Err: ErrInvalidInput}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.data.Videos[stats.VideoID]; !exists {
+		return &StorageError{Op: "create", Entity: "video_stats", ID: stats.VideoID, Err: ErrNotFound}
+	}
+
+	sample := *stats
+	if sample.SampledAt.IsZero() {
+		sample.SampledAt = time.Now()
+	}
+	samples := s.data.VideoStats[sample.VideoID]
+	i := sort.Search(len(samples), func(i int) bool {
+		return samples[i].SampledAt.After(sample.SampledAt)
+	})
+	samples = append(samples, nil)
+	copy(samples[i+1:], samples[i:])
+	samples[i] = &sample
+	if len(samples) > maxStatsSamples {
+		samples = samples[len(samples)-maxStatsSamples:]
+	}
+	s.data.VideoStats[sample.VideoID] = samples
+	return s.save()
+}
+
+// GetStatsHistory returns videoID's samples taken in [since, until],
+// oldest first. A video with no samples yields an empty slice.
+func (s *JSONStore) GetStatsHistory(ctx context.Context, videoID string, since, until time.Time) ([]*VideoStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []*VideoStats
+	for _, sample := range s.data.VideoStats[videoID] {
+		if !since.IsZero() && sample.SampledAt.Before(since) {
+			continue
+		}
+		if !until.IsZero() && sample.SampledAt.After(until) {
+			break
+		}
+		copied := *sample
+		out = append(out, &copied)
+	}
+	if out == nil {
+		out = []*VideoStats{}
+	}
+	return out, nil
+}
+
+// --- TranscriptAttemptStore implementation ---
+
+// RecordTranscriptAttempt appends an attempt to the video's history,
+// keeping the most recent maxTranscriptAttempts.
+func (s *JSONStore) RecordTranscriptAttempt(ctx context.Context, attempt *TranscriptAttempt) error {
+	if err := s.writable("create", "transcript_attempt"); err != nil {
+		return err
+	}
+	if attempt == nil || attempt.VideoID == "" {
+		return &StorageError{Op: "create", Entity: "transcript_attempt", Err: ErrInvalidInput}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.data.Videos[attempt.VideoID]; !exists {
+		return &StorageError{Op: "create", Entity: "transcript_attempt", ID: attempt.VideoID, Err: ErrNotFound}
+	}
+
+	recorded := *attempt
+	if recorded.At.IsZero() {
+		recorded.At = time.Now()
+	}
+	attempts := append(s.data.Attempts[recorded.VideoID], &recorded)
+	if len(attempts) > maxTranscriptAttempts {
+		attempts = attempts[len(attempts)-maxTranscriptAttempts:]
+	}
+	s.data.Attempts[recorded.VideoID] = attempts
+	return s.save()
+}
+
+// ListTranscriptAttempts returns videoID's attempts, oldest first. A video
+// never tried yields an empty slice.
+func (s *JSONStore) ListTranscriptAttempts(ctx context.Context, videoID string) ([]*TranscriptAttempt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]*TranscriptAttempt, 0, len(s.data.Attempts[videoID]))
+	for _, attempt := range s.data.Attempts[videoID] {
+		copied := *attempt
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+// --- KeywordStore implementation ---
+
+// SaveChannelKeywords replaces the keyword summary of a channel.
+func (s *JSONStore) SaveChannelKeywords(ctx context.Context, keywords *ChannelKeywords) error {
+	if err := s.writable("update", "channel_keywords"); err != nil {
+		return err
+	}
+	if keywords == nil || keywords.ChannelID == "" {
+		return &StorageError{Op: "update", Entity: "channel_keywords", Err: ErrInvalidInput}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.data.Channels[keywords.ChannelID]; !exists {
+		return &StorageError{Op: "update", Entity: "channel_keywords", ID: keywords.ChannelID, Err: ErrNotFound}
+	}
+
+	saved := *keywords
+	if saved.ComputedAt.IsZero() {
+		saved.ComputedAt = time.Now()
+	}
+	s.data.Keywords[saved.ChannelID] = &saved
+	return s.save()
+}
+
+// GetChannelKeywords returns the keyword summary saved for channelID.
+func (s *JSONStore) GetChannelKeywords(ctx context.Context, channelID string) (*ChannelKeywords, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keywords, exists := s.data.Keywords[channelID]
+	if !exists {
+		return nil, &StorageError{Op: "read", Entity: "channel_keywords", ID: channelID, Err: ErrNotFound}
+	}
+	copied := *keywords
+	return &copied, nil
+}
+
+// --- DownloadArchiveStore implementation ---
+
+// RecordDownload adds a video to the download archive.
+func (s *JSONStore) RecordDownload(ctx context.Context, download *ArchivedDownload) error {
+	if err := s.writable("update", "download"); err != nil {
+		return err
+	}
+	if download == nil || download.VideoID == "" {
+		return &StorageError{Op: "update", Entity: "download", Err: ErrInvalidInput}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *download
+	if saved.DownloadedAt.IsZero() {
+		saved.DownloadedAt = time.Now()
+	}
+	s.data.Downloads[saved.VideoID] = &saved
+	return s.save()
+}
+
+// IsDownloaded reports whether videoID is in the download archive.
+func (s *JSONStore) IsDownloaded(ctx context.Context, videoID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, exists := s.data.Downloads[videoID]
+	return exists, nil
+}
+
+// ListDownloads returns the download archive, oldest first.
+func (s *JSONStore) ListDownloads(ctx context.Context) ([]*ArchivedDownload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	downloads := make([]*ArchivedDownload, 0, len(s.data.Downloads))
+	for _, d := range s.data.Downloads {
+		copied := *d
+		downloads = append(downloads, &copied)
+	}
+	sort.Slice(downloads, func(i, j int) bool {
+		if !downloads[i].DownloadedAt.Equal(downloads[j].DownloadedAt) {
+			return downloads[i].DownloadedAt.Before(downloads[j].DownloadedAt)
+		}
+		return downloads[i].VideoID < downloads[j].VideoID
+	})
+	return downloads, nil
+}
+
+// --- QuotaStore implementation ---
+
+// AddQuotaUsage adds units to key's usage for day. Only the most recent day
+// is kept per key; usage recorded for an earlier day is discarded.
+func (s *JSONStore) AddQuotaUsage(ctx context.Context, key, day, method string, units int) (*QuotaUsage, error) {
+	if err := s.writable("update", "quota"); err != nil {
+		return nil, err
+	}
+	if key == "" || day == "" {
+		return nil, &StorageError{Op: "update", Entity: "quota", ID: key, Err: ErrInvalidInput}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := s.data.Quota[key]
+	if usage == nil || usage.Day != day {
+		usage = &QuotaUsage{Key: key, Day: day}
+		s.data.Quota[key] = usage
+	}
+	usage.Used += units
+	if method != "" {
+		if usage.ByMethod == nil {
+			usage.ByMethod = make(map[string]int)
+		}
+		usage.ByMethod[method] += units
+	}
+	usage.UpdatedAt = time.Now()
+
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	return copyQuotaUsage(usage), nil
+}
+
+// GetQuotaUsage returns key's usage for day, or ErrNotFound if none has
+// been recorded.
+func (s *JSONStore) GetQuotaUsage(ctx context.Context, key, day string) (*QuotaUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usage := s.data.Quota[key]
+	if usage == nil || usage.Day != day {
+		return nil, &StorageError{Op: "read", Entity: "quota", ID: key, Err: ErrNotFound}
+	}
+	return copyQuotaUsage(usage), nil
+}
+
+func copyQuotaUsage(u *QuotaUsage) *QuotaUsage {
+	out := *u
+	if u.ByMethod != nil {
+		out.ByMethod = make(map[string]int, len(u.ByMethod))
+		for k, v := range u.ByMethod {
+			out.ByMethod[k] = v
+		}
+	}
+	return &out
+}
+
+// --- ChannelAliasStore implementation ---
+
+func (s *JSONStore) SaveChannelAlias(ctx context.Context, alias *ChannelAlias) error {
+	if err := s.writable("update", "channel_alias"); err != nil {
+		return err
+	}
+	if alias == nil || alias.YouTubeID == "" {
+		return &StorageError{Op: "update", Entity: "channel_alias", Err: ErrInvalidInput}
+	}
+	key, kind, ok := NormalizeChannelAlias(alias.Alias)
+	if !ok {
+		return &StorageError{Op: "update", Entity: "channel_alias", ID: alias.Alias, Err: ErrInvalidInput}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	existing := s.data.Aliases[key]
+	switch {
+	case existing == nil:
+		existing = &ChannelAlias{Alias: key, Kind: kind, YouTubeID: alias.YouTubeID, ResolvedAt: now}
+		s.data.Aliases[key] = existing
+	case existing.YouTubeID != alias.YouTubeID:
+		// The alias was released and claimed by another channel
+		existing.Previous = append(existing.Previous, AliasTarget{
+			YouTubeID: existing.YouTubeID,
+			From:      existing.ResolvedAt,
+			Until:     existing.LastSeenAt,
+		})
+		existing.YouTubeID = alias.YouTubeID
+		existing.ResolvedAt = now
+	}
+	existing.LastSeenAt = now
+	*alias = *copyChannelAlias(existing)
+
+	return s.save()
+}
+
+func (s *JSONStore) GetChannelAlias(ctx context.Context, alias string) (*ChannelAlias, error) {
+	key, _, ok := NormalizeChannelAlias(alias)
+	if !ok {
+		return nil, &StorageError{Op: "read", Entity: "channel_alias", ID: alias, Err: ErrInvalidInput}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	mapping, exists := s.data.Aliases[key]
+	if !exists {
+		return nil, &StorageError{Op: "read", Entity: "channel_alias", ID: alias, Err: ErrNotFound}
+	}
+	return copyChannelAlias(mapping), nil
+}
+
+func (s *JSONStore) ListChannelAliases(ctx context.Context, youtubeID string) ([]*ChannelAlias, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var aliases []*ChannelAlias
+	for _, mapping := range s.data.Aliases {
+		if mapping.YouTubeID == youtubeID {
+			aliases = append(aliases, copyChannelAlias(mapping))
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		if !aliases[i].ResolvedAt.Equal(aliases[j].ResolvedAt) {
+			return aliases[i].ResolvedAt.Before(aliases[j].ResolvedAt)
+		}
+		return aliases[i].Alias < aliases[j].Alias
+	})
+	return aliases, nil
+}
+
+func copyChannelAlias(a *ChannelAlias) *ChannelAlias {
+	out := *a
+	out.Previous = append([]AliasTarget(nil), a.Previous...)
+	return &out
+}
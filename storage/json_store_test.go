@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -286,6 +287,165 @@ func TestJSONStore_TranscriptCRUD(t *testing.T) {
 	}
 }
 
+func TestJSONStore_TranscriptLanguages(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	channel := &Channel{YouTubeID: "UC123"}
+	store.CreateChannel(ctx, channel)
+	video := &Video{YouTubeID: "vid123", ChannelID: channel.ID}
+	store.CreateVideo(ctx, video)
+
+	for _, lang := range []string{"en", "es"} {
+		if err := store.CreateTranscript(ctx, &Transcript{VideoID: video.ID, Language: lang, Content: "text " + lang}); err != nil {
+			t.Fatalf("CreateTranscript(%s) error = %v", lang, err)
+		}
+	}
+	err := store.CreateTranscript(ctx, &Transcript{VideoID: video.ID, Language: "es"})
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("CreateTranscript(es) again error = %v, want ErrAlreadyExists", err)
+	}
+
+	languages, err := store.ListTranscriptLanguages(ctx, video.ID)
+	if err != nil || strings.Join(languages, ",") != "en,es" {
+		t.Errorf("ListTranscriptLanguages() = %v, %v, want [en es]", languages, err)
+	}
+	es, err := store.GetTranscriptByLanguage(ctx, video.ID, "es")
+	if err != nil || es.Content != "text es" {
+		t.Errorf("GetTranscriptByLanguage(es) = %+v, %v", es, err)
+	}
+	if _, err := store.GetTranscriptByLanguage(ctx, video.ID, "de"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTranscriptByLanguage(de) error = %v, want ErrNotFound", err)
+	}
+	if primary, err := store.GetTranscript(ctx, video.ID); err != nil || primary.Language != "en" {
+		t.Errorf("GetTranscript() = %+v, %v, want the en transcript stored first", primary, err)
+	}
+	if transcripts, _ := store.ListTranscriptsByChannel(ctx, channel.ID); len(transcripts) != 2 {
+		t.Errorf("ListTranscriptsByChannel() len = %d, want 2", len(transcripts))
+	}
+
+	if err := store.DeleteTranscriptByLanguage(ctx, video.ID, "en"); err != nil {
+		t.Fatalf("DeleteTranscriptByLanguage(en) error = %v", err)
+	}
+	if v, _ := store.GetVideo(ctx, video.ID); !v.HasTranscript {
+		t.Error("HasTranscript cleared while the es transcript remains")
+	}
+	if primary, err := store.GetTranscript(ctx, video.ID); err != nil || primary.Language != "es" {
+		t.Errorf("GetTranscript() after deleting en = %+v, %v, want es", primary, err)
+	}
+	if err := store.DeleteTranscriptByLanguage(ctx, video.ID, "es"); err != nil {
+		t.Fatalf("DeleteTranscriptByLanguage(es) error = %v", err)
+	}
+	if v, _ := store.GetVideo(ctx, video.ID); v.HasTranscript {
+		t.Error("HasTranscript still set after deleting every transcript")
+	}
+}
+
+func TestJSONStore_TranscriptSegments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.json")
+	ctx := context.Background()
+
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+
+	// Segments out of order and no Content: store sorts and derives Content
+	transcript := &Transcript{
+		VideoID:  "video-1",
+		Language: "en",
+		Source:   "youtube",
+		Segments: []Segment{
+			{Start: 2, End: 4, Text: "world"},
+			{Start: 0, End: 2, Text: "hello"},
+		},
+	}
+	if err := store.CreateTranscript(ctx, transcript); err != nil {
+		t.Fatalf("CreateTranscript() error = %v", err)
+	}
+	store.Close()
+
+	// Reopen to verify segments were persisted
+	store, err = NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() reopen error = %v", err)
+	}
+	defer store.Close()
+
+	got, err := store.GetTranscript(ctx, "video-1")
+	if err != nil {
+		t.Fatalf("GetTranscript() error = %v", err)
+	}
+	if got.Content != "hello world" {
+		t.Errorf("GetTranscript() content = %q, want %q", got.Content, "hello world")
+	}
+	if len(got.Segments) != 2 || got.Segments[0].Text != "hello" {
+		t.Errorf("GetTranscript() segments = %+v, want sorted by start", got.Segments)
+	}
+}
+
+func TestTranscript_TextAt(t *testing.T) {
+	transcript := &Transcript{Segments: []Segment{
+		{Start: 0, End: 2, Text: "one"},
+		{Start: 2, End: 6, Text: "two"},
+		{Start: 3, End: 4, Text: "overlap"},
+		{Start: 10, End: 12, Text: "three"},
+	}}
+
+	tests := []struct {
+		offset float64
+		want   string
+	}{
+		{0, "one"},
+		{1.9, "one"},
+		{2, "two"},
+		{3.5, "overlap"},
+		{5, "two"},
+		{8, ""},
+		{11, "three"},
+		{12, ""},
+		{-1, ""},
+	}
+	for _, tt := range tests {
+		if got := transcript.TextAt(tt.offset); got != tt.want {
+			t.Errorf("TextAt(%v) = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestTranscript_Window(t *testing.T) {
+	transcript := &Transcript{Segments: []Segment{
+		{Start: 0, End: 2, Text: "one"},
+		{Start: 2, End: 6, Text: "two"},
+		{Start: 10, End: 12, Text: "three"},
+	}}
+
+	tests := []struct {
+		name       string
+		start, end float64
+		want       []string
+	}{
+		{"partial overlap", 1, 3, []string{"one", "two"}},
+		{"exact boundary excludes ending segment", 2, 10, []string{"two"}},
+		{"gap", 7, 9, nil},
+		{"everything", 0, 100, []string{"one", "two", "three"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := transcript.Window(tt.start, tt.end)
+			var texts []string
+			for _, seg := range got {
+				texts = append(texts, seg.Text)
+			}
+			if strings.Join(texts, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Window(%v, %v) = %v, want %v", tt.start, tt.end, texts, tt.want)
+			}
+		})
+	}
+}
+
 func TestJSONStore_SyncState(t *testing.T) {
 	store := newTestStore(t)
 	defer store.Close()
@@ -332,6 +492,206 @@ func TestJSONStore_SyncState(t *testing.T) {
 	}
 }
 
+func TestJSONStore_SyncReports(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.json")
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if err := store.SaveSyncReport(ctx, &SyncReport{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("SaveSyncReport() without channel error = %v, want ErrInvalidInput", err)
+	}
+
+	for i := 0; i < maxSyncReports+5; i++ {
+		report := NewSyncReport("UC123", "@test")
+		report.VideosSeen = i
+		report.Finish(nil)
+		if err := store.SaveSyncReport(ctx, report); err != nil {
+			t.Fatalf("SaveSyncReport() error = %v", err)
+		}
+	}
+	store.Close()
+
+	// Reports survive a reload and are capped, oldest first
+	store, err = NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() reload error = %v", err)
+	}
+	defer store.Close()
+
+	reports, err := store.ListSyncReports(ctx, "UC123")
+	if err != nil {
+		t.Fatalf("ListSyncReports() error = %v", err)
+	}
+	if len(reports) != maxSyncReports {
+		t.Fatalf("ListSyncReports() returned %d reports, want %d", len(reports), maxSyncReports)
+	}
+	if reports[0].VideosSeen != 5 || reports[len(reports)-1].VideosSeen != maxSyncReports+4 {
+		t.Errorf("retained reports %d..%d, want 5..%d", reports[0].VideosSeen, reports[len(reports)-1].VideosSeen, maxSyncReports+4)
+	}
+
+	none, err := store.ListSyncReports(ctx, "UCother")
+	if err != nil || len(none) != 0 {
+		t.Errorf("ListSyncReports() for unknown channel = %v, %v, want empty", none, err)
+	}
+}
+
+func TestJSONStore_QuotaUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := store.GetQuotaUsage(ctx, "key1", "2024-06-01"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetQuotaUsage() before use error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.AddQuotaUsage(ctx, "", "2024-06-01", "search.list", 100); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("AddQuotaUsage() without key error = %v, want ErrInvalidInput", err)
+	}
+
+	store.AddQuotaUsage(ctx, "key1", "2024-06-01", "search.list", 100)
+	usage, err := store.AddQuotaUsage(ctx, "key1", "2024-06-01", "playlistItems.list", 1)
+	if err != nil {
+		t.Fatalf("AddQuotaUsage() error = %v", err)
+	}
+	if usage.Used != 101 || usage.ByMethod["search.list"] != 100 || usage.ByMethod["playlistItems.list"] != 1 {
+		t.Errorf("usage = %+v, want 101 split by method", usage)
+	}
+	store.Close()
+
+	// Usage survives a reload and starts over on a new day
+	store, err = NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() reload error = %v", err)
+	}
+	defer store.Close()
+
+	if usage, err := store.GetQuotaUsage(ctx, "key1", "2024-06-01"); err != nil || usage.Used != 101 {
+		t.Errorf("GetQuotaUsage() after reload = %+v, %v, want 101", usage, err)
+	}
+	usage, err = store.AddQuotaUsage(ctx, "key1", "2024-06-02", "channels.list", 1)
+	if err != nil || usage.Used != 1 {
+		t.Errorf("AddQuotaUsage() on new day = %+v, %v, want 1", usage, err)
+	}
+	if _, err := store.GetQuotaUsage(ctx, "key1", "2024-06-01"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetQuotaUsage() for previous day error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestNormalizeChannelAlias(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantAlias string
+		wantKind  AliasKind
+		wantOK    bool
+	}{
+		{"@Fireship", "@fireship", AliasHandle, true},
+		{"Fireship", "@fireship", AliasHandle, true},
+		{"https://www.youtube.com/@Fireship/videos?view=0", "@fireship", AliasHandle, true},
+		{"youtube.com/@fireship", "@fireship", AliasHandle, true},
+		{"https://m.youtube.com/c/Fireship", "c/fireship", AliasCustomURL, true},
+		{"https://www.youtube.com/user/PewDiePie/", "user/pewdiepie", AliasUser, true},
+		{"UCsBjURrPoezykLs9EqgamOA", "", "", false},
+		{"https://www.youtube.com/channel/UCsBjURrPoezykLs9EqgamOA", "", "", false},
+		{"https://example.com/@x", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		alias, kind, ok := NormalizeChannelAlias(tt.input)
+		if alias != tt.wantAlias || kind != tt.wantKind || ok != tt.wantOK {
+			t.Errorf("NormalizeChannelAlias(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.input, alias, kind, ok, tt.wantAlias, tt.wantKind, tt.wantOK)
+		}
+	}
+}
+
+func TestJSONStore_ChannelAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	const first, second = "UCaaaaaaaaaaaaaaaaaaaaaa", "UCbbbbbbbbbbbbbbbbbbbbbb"
+	if err := store.CreateChannel(ctx, &Channel{YouTubeID: first, Name: "First"}); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+
+	// A channel renamed from @oldname to @NewName keeps both aliases
+	for _, alias := range []string{"@oldname", "https://www.youtube.com/@NewName"} {
+		if err := store.SaveChannelAlias(ctx, &ChannelAlias{Alias: alias, YouTubeID: first}); err != nil {
+			t.Fatalf("SaveChannelAlias(%q) error = %v", alias, err)
+		}
+	}
+	if err := store.SaveChannelAlias(ctx, &ChannelAlias{Alias: "not a url/x/y", YouTubeID: first}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("SaveChannelAlias() invalid alias error = %v, want ErrInvalidInput", err)
+	}
+	store.Close()
+
+	store, err = NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() reload error = %v", err)
+	}
+	defer store.Close()
+
+	for _, input := range []string{"@newname", "NewName", "youtube.com/@OldName", first} {
+		channel, err := store.GetChannelByHandle(ctx, input)
+		if err != nil || channel.Name != "First" {
+			t.Errorf("GetChannelByHandle(%q) = %v, %v, want First", input, channel, err)
+		}
+	}
+	if _, err := store.GetChannelByHandle(ctx, "@unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetChannelByHandle(unknown) error = %v, want ErrNotFound", err)
+	}
+
+	aliases, err := store.ListChannelAliases(ctx, first)
+	if err != nil || len(aliases) != 2 {
+		t.Fatalf("ListChannelAliases() = %v, %v, want 2 aliases", aliases, err)
+	}
+
+	// The old handle is released and claimed by another channel
+	if err := store.SaveChannelAlias(ctx, &ChannelAlias{Alias: "@oldname", YouTubeID: second}); err != nil {
+		t.Fatalf("SaveChannelAlias() reassign error = %v", err)
+	}
+	mapping, err := store.GetChannelAlias(ctx, "@OldName")
+	if err != nil {
+		t.Fatalf("GetChannelAlias() error = %v", err)
+	}
+	if mapping.YouTubeID != second || len(mapping.Previous) != 1 || mapping.Previous[0].YouTubeID != first {
+		t.Errorf("reassigned alias = %+v, want %s with %s in history", mapping, second, first)
+	}
+	if aliases, _ := store.ListChannelAliases(ctx, first); len(aliases) != 1 || aliases[0].Alias != "@newname" {
+		t.Errorf("ListChannelAliases() after reassign = %v, want only @newname", aliases)
+	}
+}
+
+func TestSyncReport_Record(t *testing.T) {
+	report := NewSyncReport("UC123", "")
+	report.AddPhase("rss", time.Now().Add(-time.Second))
+	report.RecordTranscript("a", nil)
+	report.RecordTranscript("b", ErrNotFound)
+	report.Finish(errors.New("boom"))
+
+	if report.TranscriptsFetched != 1 {
+		t.Errorf("TranscriptsFetched = %d, want 1", report.TranscriptsFetched)
+	}
+	if len(report.TranscriptFailures) != 1 || report.TranscriptFailures[0].VideoID != "b" || report.TranscriptFailures[0].Code != "not_found" {
+		t.Errorf("TranscriptFailures = %+v", report.TranscriptFailures)
+	}
+	if len(report.Phases) != 1 || report.Phases[0].Phase != "rss" || report.Phases[0].Duration < time.Second {
+		t.Errorf("Phases = %+v", report.Phases)
+	}
+	if report.Error != "boom" || report.FinishedAt.IsZero() || report.Duration <= 0 {
+		t.Errorf("Finish() left report = %+v", report)
+	}
+}
+
 func TestStorageError(t *testing.T) {
 	err := &StorageError{
 		Op:     "read",
@@ -726,3 +1086,292 @@ func newTestStore(t *testing.T) *JSONStore {
 	}
 	return store
 }
+
+func TestJSONStore_TranscriptBlobs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.json")
+	ctx := context.Background()
+
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	// A transcript stored inline before blobs were enabled
+	if err := store.CreateTranscript(ctx, &Transcript{VideoID: "inline", Content: "old inline text"}); err != nil {
+		t.Fatal(err)
+	}
+
+	blobs, err := NewBlobStore(filepath.Join(dir, "blobs"), "")
+	if err != nil {
+		t.Fatalf("NewBlobStore() error = %v", err)
+	}
+	store.SetBlobStore(blobs)
+
+	text := strings.Repeat("a long transcript line ", 500)
+	transcript := &Transcript{
+		VideoID:  "video-1",
+		Language: "en",
+		Segments: []Segment{{Start: 0, End: 2, Text: text}},
+		Chapters: []Chapter{{Title: "Intro", Content: text}},
+	}
+	if err := store.CreateTranscript(ctx, transcript); err != nil {
+		t.Fatalf("CreateTranscript() error = %v", err)
+	}
+	store.Close()
+
+	raw, _ := os.ReadFile(path)
+	if strings.Contains(string(raw), "a long transcript line") {
+		t.Error("store file contains transcript text stored in a blob")
+	}
+
+	store, err = NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() reopen error = %v", err)
+	}
+	defer store.Close()
+	if _, err := store.GetTranscript(ctx, "video-1"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("GetTranscript() without blob store error = %v, want ErrInvalidInput", err)
+	}
+	store.SetBlobStore(blobs)
+
+	got, err := store.GetTranscript(ctx, "video-1")
+	if err != nil {
+		t.Fatalf("GetTranscript() error = %v", err)
+	}
+	if got.Content != strings.TrimSpace(text) || len(got.Segments) != 1 || len(got.Chapters) != 1 || got.Blob == nil {
+		t.Errorf("GetTranscript() = content %d bytes, %d segments, %d chapters, blob %v",
+			len(got.Content), len(got.Segments), len(got.Chapters), got.Blob)
+	}
+	if got.Blob.Size <= got.Blob.StoredSize {
+		t.Errorf("blob sizes = %d stored / %d raw, want compressed", got.Blob.StoredSize, got.Blob.Size)
+	}
+
+	// Updating replaces the blob and removes the old one
+	oldRef := got.Blob
+	got.Content = "short"
+	got.Segments, got.Chapters = nil, nil
+	if err := store.UpdateTranscript(ctx, got); err != nil {
+		t.Fatalf("UpdateTranscript() error = %v", err)
+	}
+	if _, err := blobs.Get(oldRef); !errors.Is(err, ErrNotFound) {
+		t.Errorf("old blob after update error = %v, want ErrNotFound", err)
+	}
+	if updated, _ := store.GetTranscript(ctx, "video-1"); updated.Content != "short" {
+		t.Errorf("updated content = %q, want short", updated.Content)
+	}
+
+	moved, err := store.MoveTranscriptsToBlobs(ctx)
+	if err != nil || moved != 1 {
+		t.Errorf("MoveTranscriptsToBlobs() = %d, %v; want 1", moved, err)
+	}
+	if inline, err := store.GetTranscript(ctx, "inline"); err != nil || inline.Content != "old inline text" || inline.Blob == nil {
+		t.Errorf("moved transcript = %+v, %v", inline, err)
+	}
+
+	if err := store.DeleteTranscript(ctx, "video-1"); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := filepath.Glob(filepath.Join(dir, "blobs", "*", "*"))
+	if len(entries) != 1 {
+		t.Errorf("blobs after delete = %v, want only the moved transcript's", entries)
+	}
+}
+
+func TestJSONStore_VideoStats(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	channel := &Channel{YouTubeID: "UC123", Name: "Test"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	video := &Video{YouTubeID: "vid1", ChannelID: channel.ID, Title: "Video"}
+	if err := store.CreateVideo(ctx, video); err != nil {
+		t.Fatalf("CreateVideo() error = %v", err)
+	}
+
+	if err := store.RecordVideoStats(ctx, &VideoStats{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("RecordVideoStats() without video error = %v, want ErrInvalidInput", err)
+	}
+	if err := store.RecordVideoStats(ctx, &VideoStats{VideoID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RecordVideoStats() for unknown video error = %v, want ErrNotFound", err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Recorded out of order; history is returned by SampledAt
+	for _, day := range []int{0, 2, 1} {
+		sample := &VideoStats{VideoID: video.ID, SampledAt: base.AddDate(0, 0, day), ViewCount: int64(100 * (day + 1))}
+		if err := store.RecordVideoStats(ctx, sample); err != nil {
+			t.Fatalf("RecordVideoStats() error = %v", err)
+		}
+	}
+
+	history, err := store.GetStatsHistory(ctx, video.ID, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("GetStatsHistory() error = %v", err)
+	}
+	if len(history) != 3 || history[0].ViewCount != 100 || history[1].ViewCount != 200 || history[2].ViewCount != 300 {
+		t.Errorf("GetStatsHistory() = %+v, want 3 samples oldest first", history)
+	}
+
+	history, _ = store.GetStatsHistory(ctx, video.ID, base.AddDate(0, 0, 1), base.AddDate(0, 0, 1))
+	if len(history) != 1 || history[0].ViewCount != 200 {
+		t.Errorf("GetStatsHistory() in range = %+v, want the day 1 sample", history)
+	}
+
+	if err := store.DeleteVideo(ctx, video.ID); err != nil {
+		t.Fatalf("DeleteVideo() error = %v", err)
+	}
+	if history, _ := store.GetStatsHistory(ctx, video.ID, time.Time{}, time.Time{}); len(history) != 0 {
+		t.Errorf("history kept after DeleteVideo: %+v", history)
+	}
+}
+
+func TestJSONStore_TranscriptAttempts(t *testing.T) {
+	store := newTestStore(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	channel := &Channel{YouTubeID: "UC123", Name: "Test"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	video := &Video{YouTubeID: "vid1", ChannelID: channel.ID, Title: "Video"}
+	if err := store.CreateVideo(ctx, video); err != nil {
+		t.Fatalf("CreateVideo() error = %v", err)
+	}
+
+	if err := store.RecordTranscriptAttempt(ctx, &TranscriptAttempt{VideoID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RecordTranscriptAttempt() for unknown video error = %v, want ErrNotFound", err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < maxTranscriptAttempts+5; i++ {
+		attempt := &TranscriptAttempt{VideoID: video.ID, At: base.Add(time.Duration(i) * time.Hour), Outcome: TranscriptFailed, Code: "not_found"}
+		if err := store.RecordTranscriptAttempt(ctx, attempt); err != nil {
+			t.Fatalf("RecordTranscriptAttempt() error = %v", err)
+		}
+	}
+
+	attempts, err := store.ListTranscriptAttempts(ctx, video.ID)
+	if err != nil {
+		t.Fatalf("ListTranscriptAttempts() error = %v", err)
+	}
+	if len(attempts) != maxTranscriptAttempts || !attempts[0].At.Equal(base.Add(5*time.Hour)) {
+		t.Errorf("ListTranscriptAttempts() = %d attempts from %v, want the last %d", len(attempts), attempts[0].At, maxTranscriptAttempts)
+	}
+
+	if err := store.DeleteVideo(ctx, video.ID); err != nil {
+		t.Fatalf("DeleteVideo() error = %v", err)
+	}
+	if attempts, _ := store.ListTranscriptAttempts(ctx, video.ID); len(attempts) != 0 {
+		t.Errorf("attempts kept after DeleteVideo: %+v", attempts)
+	}
+}
+
+func TestTranscriptRetryPolicy_Due(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	failed := func(daysAgo int) *TranscriptAttempt {
+		return &TranscriptAttempt{At: now.AddDate(0, 0, -daysAgo), Outcome: TranscriptFailed}
+	}
+	succeeded := &TranscriptAttempt{At: now.AddDate(0, 0, -9), Outcome: TranscriptSucceeded}
+	policy := &TranscriptRetryPolicy{RetryAfter: 48 * time.Hour, MaxAttempts: 3}
+
+	tests := []struct {
+		name     string
+		policy   *TranscriptRetryPolicy
+		attempts []*TranscriptAttempt
+		want     bool
+	}{
+		{"never tried", policy, nil, true},
+		{"last succeeded", policy, []*TranscriptAttempt{failed(8), succeeded}, true},
+		{"failed recently", policy, []*TranscriptAttempt{failed(1)}, false},
+		{"failed long enough ago", policy, []*TranscriptAttempt{failed(2)}, true},
+		{"gave up", policy, []*TranscriptAttempt{failed(7), failed(5), failed(3)}, false},
+		{"failures before a success do not count", policy, []*TranscriptAttempt{failed(8), failed(8), succeeded, failed(3), failed(3)}, true},
+		{"no policy", nil, []*TranscriptAttempt{failed(0)}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Due(tt.attempts, now); got != tt.want {
+			t.Errorf("%s: Due() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTranscriptRetryPolicy_DueFor(t *testing.T) {
+	published := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	policy := &TranscriptRetryPolicy{
+		RetryAfter:   24 * time.Hour,
+		MaxAttempts:  2,
+		PollSchedule: []time.Duration{15 * time.Minute, time.Hour, 6 * time.Hour},
+		PollWindow:   48 * time.Hour,
+	}
+	failedAt := func(after time.Duration) *TranscriptAttempt {
+		return &TranscriptAttempt{At: published.Add(after), Outcome: TranscriptFailed}
+	}
+
+	tests := []struct {
+		name     string
+		attempts []*TranscriptAttempt
+		now      time.Duration // since publication
+		want     bool
+	}{
+		{"first wait not over", []*TranscriptAttempt{failedAt(0)}, 10 * time.Minute, false},
+		{"first wait over", []*TranscriptAttempt{failedAt(0)}, 15 * time.Minute, true},
+		{"second wait", []*TranscriptAttempt{failedAt(0), failedAt(15 * time.Minute)}, time.Hour, false},
+		{"past max attempts while polling", []*TranscriptAttempt{failedAt(0), failedAt(15 * time.Minute)}, 75 * time.Minute, true},
+		{"last wait repeats", []*TranscriptAttempt{failedAt(0), failedAt(time.Hour), failedAt(3 * time.Hour), failedAt(20 * time.Hour)}, 26 * time.Hour, true},
+		{"gave up after the window", []*TranscriptAttempt{failedAt(0), failedAt(time.Hour), failedAt(49 * time.Hour)}, 96 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		if got := policy.DueFor(published, tt.attempts, published.Add(tt.now)); got != tt.want {
+			t.Errorf("%s: DueFor() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Without a publish time the regular retry applies
+	if policy.DueFor(time.Time{}, []*TranscriptAttempt{failedAt(0)}, published.Add(time.Hour)) {
+		t.Error("DueFor(zero publish time) = true, want RetryAfter to apply")
+	}
+	if !policy.Polling(published, published.Add(47*time.Hour)) || policy.Polling(published, published.Add(48*time.Hour)) {
+		t.Error("Polling() does not end with PollWindow")
+	}
+}
+
+func TestJSONStore_DownloadArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if err := store.RecordDownload(ctx, &ArchivedDownload{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("RecordDownload() without video error = %v, want ErrInvalidInput", err)
+	}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"vid2", "vid1"} {
+		if err := store.RecordDownload(ctx, &ArchivedDownload{VideoID: id, DownloadedAt: base.Add(time.Duration(i) * time.Hour)}); err != nil {
+			t.Fatalf("RecordDownload() error = %v", err)
+		}
+	}
+	store.Close()
+
+	// The archive survives reopening the store
+	store, err = NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer store.Close()
+	if ok, err := store.IsDownloaded(ctx, "vid1"); !ok || err != nil {
+		t.Errorf("IsDownloaded(vid1) = %v, %v; want true", ok, err)
+	}
+	if ok, _ := store.IsDownloaded(ctx, "vid3"); ok {
+		t.Error("IsDownloaded(vid3) = true for a video never recorded")
+	}
+	downloads, err := store.ListDownloads(ctx)
+	if err != nil || len(downloads) != 2 || downloads[0].VideoID != "vid2" {
+		t.Errorf("ListDownloads() = %+v, %v; want vid2 then vid1", downloads, err)
+	}
+}
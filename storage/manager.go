@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"ytsync/errcode"
+)
+
+// maxWriteBatch bounds how many queued writes Manager applies in one batch.
+const maxWriteBatch = 64
+
+// ErrManagerClosed is returned by writes to a Manager after Close.
+var ErrManagerClosed = errcode.New(errcode.Unavailable, "storage: manager is closed")
+
+// Batcher is implemented by stores that can apply several writes and save
+// them once. *JSONStore implements it.
+type Batcher interface {
+	// Batch runs fn with a view of the store whose writes have their
+	// saves deferred to the end of the batch. Writes through the store
+	// itself are not deferred.
+	Batch(ctx context.Context, fn func(Store) error) error
+}
+
+// Manager shares one Store between the components of an application, such
+// as a scheduler, an HTTP API, and sync workers. Reads go straight to the
+// store and run concurrently. Writes are queued and applied one at a time
+// by a single goroutine, so writers wait in line instead of contending for
+// the store; if the store is a Batcher, writes that queued up while one was
+// applied are applied together and saved once.
+//
+// Manager implements Store. Writes through the other interfaces the store
+// implements, such as SyncReportStore, go through Write. Calling the
+// embedded Store's write methods directly bypasses the queue.
+type Manager struct {
+	Store
+
+	writes chan *managedWrite
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+	once   sync.Once
+	err    error
+}
+
+// managedWrite is a write waiting in the queue.
+type managedWrite struct {
+	ctx    context.Context
+	fn     func(Store) error
+	result chan error
+}
+
+// NewManager starts a manager for store. Close the manager, not the store,
+// when done with it.
+func NewManager(store Store) *Manager {
+	m := &Manager{
+		Store:  store,
+		writes: make(chan *managedWrite, maxWriteBatch),
+		done:   make(chan struct{}),
+	}
+	go m.run()
+	return m
+}
+
+// Write queues fn and waits until the writer goroutine has run it with the
+// store. fn must not call the Manager's own write methods, which would wait
+// on the queue fn holds up. Write returns ctx's error if ctx ends before fn
+// is run, and otherwise fn's error or, for a Batcher, the error saving the
+// batch fn was applied in.
+func (m *Manager) Write(ctx context.Context, fn func(Store) error) error {
+	w := &managedWrite{ctx: ctx, fn: fn, result: make(chan error, 1)}
+
+	m.mu.RLock()
+	if m.closed {
+		m.mu.RUnlock()
+		return &StorageError{Op: "write", Entity: "store", Err: ErrManagerClosed}
+	}
+	select {
+	case m.writes <- w:
+	case <-ctx.Done():
+		m.mu.RUnlock()
+		return ctx.Err()
+	}
+	m.mu.RUnlock()
+
+	// Once queued, a write is always answered, if only with ctx's error
+	return <-w.result
+}
+
+// Close applies the writes already queued, then closes the store. Writes
+// made after Close fail with ErrManagerClosed.
+func (m *Manager) Close() error {
+	m.once.Do(func() {
+		m.mu.Lock()
+		m.closed = true
+		close(m.writes)
+		m.mu.Unlock()
+		<-m.done
+		m.err = m.Store.Close()
+	})
+	return m.err
+}
+
+// run applies queued writes until the queue is closed.
+func (m *Manager) run() {
+	defer close(m.done)
+	for w := range m.writes {
+		batch := []*managedWrite{w}
+	drain:
+		for len(batch) < maxWriteBatch {
+			select {
+			case next, ok := <-m.writes:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, next)
+			default:
+				break drain
+			}
+		}
+		m.apply(batch)
+	}
+}
+
+// apply runs a batch of writes and answers each.
+func (m *Manager) apply(batch []*managedWrite) {
+	errs := make([]error, len(batch))
+	runAll := func(store Store) error {
+		for i, w := range batch {
+			if err := w.ctx.Err(); err != nil {
+				errs[i] = err
+				continue
+			}
+			errs[i] = w.fn(store)
+		}
+		return nil
+	}
+
+	var saveErr error
+	if b, ok := m.Store.(Batcher); ok && len(batch) > 1 {
+		saveErr = b.Batch(context.Background(), runAll)
+	} else {
+		runAll(m.Store)
+	}
+	for i, w := range batch {
+		if errs[i] == nil {
+			errs[i] = saveErr
+		}
+		w.result <- errs[i]
+	}
+}
+
+// --- Store writes, through the queue ---
+
+// CreateChannel queues the creation of channel.
+func (m *Manager) CreateChannel(ctx context.Context, channel *Channel) error {
+	return m.Write(ctx, func(s Store) error { return s.CreateChannel(ctx, channel) })
+}
+
+// UpdateChannel queues an update of channel.
+func (m *Manager) UpdateChannel(ctx context.Context, channel *Channel) error {
+	return m.Write(ctx, func(s Store) error { return s.UpdateChannel(ctx, channel) })
+}
+
+// DeleteChannel queues the removal of the channel with internal ID id.
+func (m *Manager) DeleteChannel(ctx context.Context, id string) error {
+	return m.Write(ctx, func(s Store) error { return s.DeleteChannel(ctx, id) })
+}
+
+// CreateVideo queues the creation of video.
+func (m *Manager) CreateVideo(ctx context.Context, video *Video) error {
+	return m.Write(ctx, func(s Store) error { return s.CreateVideo(ctx, video) })
+}
+
+// UpdateVideo queues an update of video.
+func (m *Manager) UpdateVideo(ctx context.Context, video *Video) error {
+	return m.Write(ctx, func(s Store) error { return s.UpdateVideo(ctx, video) })
+}
+
+// DeleteVideo queues the removal of the video with internal ID id.
+func (m *Manager) DeleteVideo(ctx context.Context, id string) error {
+	return m.Write(ctx, func(s Store) error { return s.DeleteVideo(ctx, id) })
+}
+
+// CreateTranscript queues the creation of transcript.
+func (m *Manager) CreateTranscript(ctx context.Context, transcript *Transcript) error {
+	return m.Write(ctx, func(s Store) error { return s.CreateTranscript(ctx, transcript) })
+}
+
+// UpdateTranscript queues an update of transcript.
+func (m *Manager) UpdateTranscript(ctx context.Context, transcript *Transcript) error {
+	return m.Write(ctx, func(s Store) error { return s.UpdateTranscript(ctx, transcript) })
+}
+
+// DeleteTranscript queues the removal of every transcript of a video.
+func (m *Manager) DeleteTranscript(ctx context.Context, videoID string) error {
+	return m.Write(ctx, func(s Store) error { return s.DeleteTranscript(ctx, videoID) })
+}
+
+// DeleteTranscriptByLanguage queues the removal of a video's transcript in
+// language.
+func (m *Manager) DeleteTranscriptByLanguage(ctx context.Context, videoID, language string) error {
+	return m.Write(ctx, func(s Store) error { return s.DeleteTranscriptByLanguage(ctx, videoID, language) })
+}
+
+// UpdateSyncState queues an update of a channel's sync state.
+func (m *Manager) UpdateSyncState(ctx context.Context, state *SyncState) error {
+	return m.Write(ctx, func(s Store) error { return s.UpdateSyncState(ctx, state) })
+}
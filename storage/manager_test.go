@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestManager_ConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	m := NewManager(store)
+	ctx := context.Background()
+
+	channel := &Channel{YouTubeID: "UC123"}
+	if err := m.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+
+	const writers = 40
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			errs <- m.CreateVideo(ctx, &Video{YouTubeID: fmt.Sprintf("vid%02d", i), ChannelID: channel.ID})
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := m.ListVideosByChannel(ctx, channel.ID)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent call error = %v", err)
+		}
+	}
+
+	report := &SyncReport{ChannelID: channel.ID}
+	if err := m.Write(ctx, func(s Store) error {
+		return s.(SyncReportStore).SaveSyncReport(ctx, report)
+	}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := m.UpdateChannel(ctx, channel); !errors.Is(err, ErrManagerClosed) {
+		t.Errorf("UpdateChannel() after Close() error = %v, want ErrManagerClosed", err)
+	}
+
+	reopened, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() reopen error = %v", err)
+	}
+	defer reopened.Close()
+	videos, err := reopened.ListVideosByChannel(ctx, channel.ID)
+	if err != nil || len(videos) != writers {
+		t.Errorf("ListVideosByChannel() after reopen = %d videos, %v, want %d", len(videos), err, writers)
+	}
+	if reports, err := reopened.ListSyncReports(ctx, channel.ID); err != nil || len(reports) != 1 {
+		t.Errorf("ListSyncReports() after reopen = %d, %v, want 1", len(reports), err)
+	}
+}
+
+func TestManager_WriteErrors(t *testing.T) {
+	store, err := NewJSONStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	m := NewManager(store)
+	defer m.Close()
+	ctx := context.Background()
+
+	// Each write in a batch gets its own error
+	if err := m.CreateChannel(ctx, &Channel{ID: "c1", YouTubeID: "UC1"}); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	if err := m.CreateChannel(ctx, &Channel{ID: "c1", YouTubeID: "UC2"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("CreateChannel() duplicate error = %v, want ErrAlreadyExists", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := m.CreateChannel(canceled, &Channel{YouTubeID: "UC3"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CreateChannel() canceled error = %v, want context.Canceled", err)
+	}
+	if _, err := m.GetChannelByYouTubeID(ctx, "UC3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("channel written with a canceled context: GetChannelByYouTubeID() error = %v", err)
+	}
+}
+
+func TestJSONStore_Batch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	onDisk := func() int {
+		t.Helper()
+		reader, err := NewJSONStoreWithOptions(path, &JSONStoreOptions{ReadOnly: true})
+		if err != nil {
+			t.Fatalf("open read-only: %v", err)
+		}
+		defer reader.Close()
+		channels, _ := reader.ListChannels(ctx)
+		return len(channels)
+	}
+
+	err = store.Batch(ctx, func(batch Store) error {
+		for i := 0; i < 3; i++ {
+			if err := batch.CreateChannel(ctx, &Channel{YouTubeID: fmt.Sprintf("UC%d", i)}); err != nil {
+				return err
+			}
+		}
+		if n := onDisk(); n != 0 {
+			t.Errorf("channels saved during the batch = %d, want 0", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+	if n := onDisk(); n != 3 {
+		t.Errorf("channels saved after the batch = %d, want 3", n)
+	}
+}
+
+func TestJSONStore_BatchSavesOtherWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	onDisk := func() int {
+		t.Helper()
+		reader, err := NewJSONStoreWithOptions(path, &JSONStoreOptions{ReadOnly: true})
+		if err != nil {
+			t.Fatalf("open read-only: %v", err)
+		}
+		defer reader.Close()
+		channels, _ := reader.ListChannels(ctx)
+		return len(channels)
+	}
+
+	err = store.Batch(ctx, func(batch Store) error {
+		if err := batch.CreateChannel(ctx, &Channel{YouTubeID: "UCbatch"}); err != nil {
+			return err
+		}
+		if n := onDisk(); n != 0 {
+			t.Errorf("channels saved after a batch write = %d, want 0", n)
+		}
+		// A write outside the batch is saved before it returns
+		if err := store.CreateChannel(ctx, &Channel{YouTubeID: "UCother"}); err != nil {
+			return err
+		}
+		if n := onDisk(); n != 2 {
+			t.Errorf("channels saved after a write outside the batch = %d, want 2", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+}
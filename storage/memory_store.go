@@ -0,0 +1,556 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps everything in memory, for programs
+// that need no persistence, such as short-lived batch jobs and unit tests.
+// It is the reference implementation of Store: IDs are assigned on create,
+// duplicates fail with ErrAlreadyExists, missing records with ErrNotFound,
+// lookups by YouTube ID and channel use the same indexes as JSONStore, and
+// creating or deleting a transcript updates the video's HasTranscript flag.
+// It also implements SyncReportStore and ChannelAliasStore.
+//
+// Like JSONStore, it returns the stored records rather than copies. It is
+// safe for concurrent use.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	ids         IDGenerator
+	channels    map[string]*Channel
+	videos      map[string]*Video
+	transcripts map[string]map[string]*Transcript // video ID -> language
+	syncStates  map[string]*SyncState
+	reports     map[string][]*SyncReport
+	aliases     map[string]*ChannelAlias
+
+	channelsByYouTubeID map[string]string
+	videosByYouTubeID   map[string]string
+	videosByChannel     map[string][]string
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		ids:                 RandomIDs,
+		channels:            make(map[string]*Channel),
+		videos:              make(map[string]*Video),
+		transcripts:         make(map[string]map[string]*Transcript),
+		syncStates:          make(map[string]*SyncState),
+		reports:             make(map[string][]*SyncReport),
+		aliases:             make(map[string]*ChannelAlias),
+		channelsByYouTubeID: make(map[string]string),
+		videosByYouTubeID:   make(map[string]string),
+		videosByChannel:     make(map[string][]string),
+	}
+}
+
+// SetIDs sets how new channels and videos stored without an ID get their
+// IDs (default: RandomIDs). Call it before storing anything.
+func (m *MemoryStore) SetIDs(ids IDGenerator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ids == nil {
+		ids = RandomIDs
+	}
+	m.ids = ids
+}
+
+// Close does nothing; a MemoryStore holds no resources.
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// notFound returns the error for a missing record.
+func notFound(op, entity, id string) error {
+	return &StorageError{Op: op, Entity: entity, ID: id, Err: ErrNotFound}
+}
+
+// --- ChannelStore ---
+
+func (m *MemoryStore) CreateChannel(ctx context.Context, channel *Channel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if channel.ID == "" {
+		channel.ID = m.ids("channel", channel.YouTubeID)
+	}
+	if _, exists := m.channels[channel.ID]; exists {
+		return &StorageError{Op: "create", Entity: "channel", ID: channel.ID, Err: ErrAlreadyExists}
+	}
+	if _, exists := m.channelsByYouTubeID[channel.YouTubeID]; exists {
+		return &StorageError{Op: "create", Entity: "channel", ID: channel.YouTubeID, Err: ErrAlreadyExists}
+	}
+
+	now := time.Now()
+	channel.CreatedAt = now
+	channel.UpdatedAt = now
+	m.channels[channel.ID] = channel
+	m.channelsByYouTubeID[channel.YouTubeID] = channel.ID
+	return nil
+}
+
+func (m *MemoryStore) GetChannel(ctx context.Context, id string) (*Channel, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	channel, exists := m.channels[id]
+	if !exists {
+		return nil, notFound("read", "channel", id)
+	}
+	return channel, nil
+}
+
+func (m *MemoryStore) GetChannelByYouTubeID(ctx context.Context, youtubeID string) (*Channel, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	id, exists := m.channelsByYouTubeID[youtubeID]
+	if !exists {
+		return nil, notFound("read", "channel", youtubeID)
+	}
+	return m.channels[id], nil
+}
+
+func (m *MemoryStore) GetChannelByHandle(ctx context.Context, handle string) (*Channel, error) {
+	if id := ChannelIDFromInput(handle); id != "" {
+		return m.GetChannelByYouTubeID(ctx, id)
+	}
+	alias, _, ok := NormalizeChannelAlias(handle)
+	if !ok {
+		return nil, &StorageError{Op: "read", Entity: "channel", ID: handle, Err: ErrInvalidInput}
+	}
+
+	m.mu.RLock()
+	mapping, exists := m.aliases[alias]
+	m.mu.RUnlock()
+	if !exists {
+		return nil, notFound("read", "channel", handle)
+	}
+	return m.GetChannelByYouTubeID(ctx, mapping.YouTubeID)
+}
+
+func (m *MemoryStore) UpdateChannel(ctx context.Context, channel *Channel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.channels[channel.ID]
+	if !exists {
+		return notFound("update", "channel", channel.ID)
+	}
+	if existing.YouTubeID != channel.YouTubeID {
+		delete(m.channelsByYouTubeID, existing.YouTubeID)
+		m.channelsByYouTubeID[channel.YouTubeID] = channel.ID
+	}
+	channel.UpdatedAt = time.Now()
+	m.channels[channel.ID] = channel
+	return nil
+}
+
+func (m *MemoryStore) DeleteChannel(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	channel, exists := m.channels[id]
+	if !exists {
+		return notFound("delete", "channel", id)
+	}
+	delete(m.channels, id)
+	delete(m.channelsByYouTubeID, channel.YouTubeID)
+	delete(m.videosByChannel, id)
+	delete(m.syncStates, id)
+	return nil
+}
+
+func (m *MemoryStore) ListChannels(ctx context.Context) ([]*Channel, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	channels := make([]*Channel, 0, len(m.channels))
+	for _, ch := range m.channels {
+		channels = append(channels, ch)
+	}
+	return channels, nil
+}
+
+// --- VideoStore ---
+
+func (m *MemoryStore) CreateVideo(ctx context.Context, video *Video) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if video.ID == "" {
+		video.ID = m.ids("video", video.YouTubeID)
+	}
+	if _, exists := m.videos[video.ID]; exists {
+		return &StorageError{Op: "create", Entity: "video", ID: video.ID, Err: ErrAlreadyExists}
+	}
+	if _, exists := m.videosByYouTubeID[video.YouTubeID]; exists {
+		return &StorageError{Op: "create", Entity: "video", ID: video.YouTubeID, Err: ErrAlreadyExists}
+	}
+
+	now := time.Now()
+	video.CreatedAt = now
+	video.UpdatedAt = now
+	m.videos[video.ID] = video
+	m.videosByYouTubeID[video.YouTubeID] = video.ID
+	m.videosByChannel[video.ChannelID] = append(m.videosByChannel[video.ChannelID], video.ID)
+	return nil
+}
+
+func (m *MemoryStore) GetVideo(ctx context.Context, id string) (*Video, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	video, exists := m.videos[id]
+	if !exists {
+		return nil, notFound("read", "video", id)
+	}
+	return video, nil
+}
+
+func (m *MemoryStore) GetVideoByYouTubeID(ctx context.Context, youtubeID string) (*Video, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	id, exists := m.videosByYouTubeID[youtubeID]
+	if !exists {
+		return nil, notFound("read", "video", youtubeID)
+	}
+	return m.videos[id], nil
+}
+
+func (m *MemoryStore) UpdateVideo(ctx context.Context, video *Video) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.videos[video.ID]
+	if !exists {
+		return notFound("update", "video", video.ID)
+	}
+	if existing.YouTubeID != video.YouTubeID {
+		delete(m.videosByYouTubeID, existing.YouTubeID)
+		m.videosByYouTubeID[video.YouTubeID] = video.ID
+	}
+	video.UpdatedAt = time.Now()
+	m.videos[video.ID] = video
+	return nil
+}
+
+func (m *MemoryStore) DeleteVideo(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	video, exists := m.videos[id]
+	if !exists {
+		return notFound("delete", "video", id)
+	}
+	delete(m.videos, id)
+	delete(m.videosByYouTubeID, video.YouTubeID)
+	delete(m.transcripts, id)
+
+	ids := m.videosByChannel[video.ChannelID]
+	for i, vid := range ids {
+		if vid == id {
+			m.videosByChannel[video.ChannelID] = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) ListVideosByChannel(ctx context.Context, channelID string) ([]*Video, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := m.videosByChannel[channelID]
+	videos := make([]*Video, 0, len(ids))
+	for _, id := range ids {
+		if video, exists := m.videos[id]; exists {
+			videos = append(videos, video)
+		}
+	}
+	return videos, nil
+}
+
+func (m *MemoryStore) ListVideosNeedingTranscript(ctx context.Context) ([]*Video, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var videos []*Video
+	for _, video := range m.videos {
+		if !video.HasTranscript {
+			videos = append(videos, video)
+		}
+	}
+	return videos, nil
+}
+
+// --- TranscriptStore ---
+
+// ordered returns the transcripts of a video oldest first, by language for
+// ties; the first is the primary transcript.
+func (m *MemoryStore) ordered(videoID string) []*Transcript {
+	transcripts := make([]*Transcript, 0, len(m.transcripts[videoID]))
+	for _, t := range m.transcripts[videoID] {
+		transcripts = append(transcripts, t)
+	}
+	sort.Slice(transcripts, func(i, j int) bool {
+		if !transcripts[i].CreatedAt.Equal(transcripts[j].CreatedAt) {
+			return transcripts[i].CreatedAt.Before(transcripts[j].CreatedAt)
+		}
+		return transcripts[i].Language < transcripts[j].Language
+	})
+	return transcripts
+}
+
+func (m *MemoryStore) CreateTranscript(ctx context.Context, transcript *Transcript) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.transcripts[transcript.VideoID][transcript.Language]; exists {
+		return &StorageError{Op: "create", Entity: "transcript", ID: transcriptKey(transcript.VideoID, transcript.Language), Err: ErrAlreadyExists}
+	}
+
+	transcript.normalizeSegments()
+	now := time.Now()
+	transcript.CreatedAt = now
+	transcript.UpdatedAt = now
+	if m.transcripts[transcript.VideoID] == nil {
+		m.transcripts[transcript.VideoID] = make(map[string]*Transcript)
+	}
+	m.transcripts[transcript.VideoID][transcript.Language] = transcript
+
+	if video, exists := m.videos[transcript.VideoID]; exists {
+		video.HasTranscript = true
+		video.UpdatedAt = now
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetTranscript(ctx context.Context, videoID string) (*Transcript, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	transcripts := m.ordered(videoID)
+	if len(transcripts) == 0 {
+		return nil, notFound("read", "transcript", videoID)
+	}
+	return transcripts[0], nil
+}
+
+func (m *MemoryStore) GetTranscriptByLanguage(ctx context.Context, videoID, language string) (*Transcript, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	transcript, exists := m.transcripts[videoID][language]
+	if !exists {
+		return nil, notFound("read", "transcript", transcriptKey(videoID, language))
+	}
+	return transcript, nil
+}
+
+func (m *MemoryStore) ListTranscriptLanguages(ctx context.Context, videoID string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	transcripts := m.ordered(videoID)
+	languages := make([]string, len(transcripts))
+	for i, t := range transcripts {
+		languages[i] = t.Language
+	}
+	return languages, nil
+}
+
+func (m *MemoryStore) UpdateTranscript(ctx context.Context, transcript *Transcript) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.transcripts[transcript.VideoID][transcript.Language]; !exists {
+		return notFound("update", "transcript", transcriptKey(transcript.VideoID, transcript.Language))
+	}
+	transcript.normalizeSegments()
+	transcript.UpdatedAt = time.Now()
+	m.transcripts[transcript.VideoID][transcript.Language] = transcript
+	return nil
+}
+
+func (m *MemoryStore) DeleteTranscript(ctx context.Context, videoID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.transcripts[videoID]; !exists {
+		return notFound("delete", "transcript", videoID)
+	}
+	delete(m.transcripts, videoID)
+	m.clearTranscriptFlag(videoID)
+	return nil
+}
+
+func (m *MemoryStore) DeleteTranscriptByLanguage(ctx context.Context, videoID, language string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.transcripts[videoID][language]; !exists {
+		return notFound("delete", "transcript", transcriptKey(videoID, language))
+	}
+	delete(m.transcripts[videoID], language)
+	if len(m.transcripts[videoID]) == 0 {
+		delete(m.transcripts, videoID)
+		m.clearTranscriptFlag(videoID)
+	}
+	return nil
+}
+
+// clearTranscriptFlag marks a video as having no transcript. Callers must
+// hold m.mu.
+func (m *MemoryStore) clearTranscriptFlag(videoID string) {
+	if video, exists := m.videos[videoID]; exists {
+		video.HasTranscript = false
+		video.UpdatedAt = time.Now()
+	}
+}
+
+func (m *MemoryStore) ListTranscriptsByChannel(ctx context.Context, channelID string) ([]*Transcript, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var transcripts []*Transcript
+	for _, videoID := range m.videosByChannel[channelID] {
+		transcripts = append(transcripts, m.ordered(videoID)...)
+	}
+	return transcripts, nil
+}
+
+// --- SyncStateStore ---
+
+func (m *MemoryStore) GetSyncState(ctx context.Context, channelID string) (*SyncState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state, exists := m.syncStates[channelID]
+	if !exists {
+		return nil, notFound("read", "sync_state", channelID)
+	}
+	return state, nil
+}
+
+func (m *MemoryStore) UpdateSyncState(ctx context.Context, state *SyncState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.syncStates[state.ChannelID] = state
+	return nil
+}
+
+func (m *MemoryStore) GetLastSync(ctx context.Context, channelID string) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state, exists := m.syncStates[channelID]
+	if !exists {
+		return time.Time{}, notFound("read", "sync_state", channelID)
+	}
+	return state.LastSyncAt, nil
+}
+
+// --- SyncReportStore ---
+
+// SaveSyncReport appends report to its channel's history.
+func (m *MemoryStore) SaveSyncReport(ctx context.Context, report *SyncReport) error {
+	if report == nil || report.ChannelID == "" {
+		return &StorageError{Op: "create", Entity: "sync_report", Err: ErrInvalidInput}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reports[report.ChannelID] = append(m.reports[report.ChannelID], report)
+	return nil
+}
+
+// ListSyncReports returns the reports saved for channelID, oldest first.
+func (m *MemoryStore) ListSyncReports(ctx context.Context, channelID string) ([]*SyncReport, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]*SyncReport{}, m.reports[channelID]...), nil
+}
+
+// --- ChannelAliasStore ---
+
+func (m *MemoryStore) SaveChannelAlias(ctx context.Context, alias *ChannelAlias) error {
+	if alias == nil || alias.YouTubeID == "" {
+		return &StorageError{Op: "update", Entity: "channel_alias", Err: ErrInvalidInput}
+	}
+	key, kind, ok := NormalizeChannelAlias(alias.Alias)
+	if !ok {
+		return &StorageError{Op: "update", Entity: "channel_alias", ID: alias.Alias, Err: ErrInvalidInput}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	existing := m.aliases[key]
+	switch {
+	case existing == nil:
+		existing = &ChannelAlias{Alias: key, Kind: kind, YouTubeID: alias.YouTubeID, ResolvedAt: now}
+		m.aliases[key] = existing
+	case existing.YouTubeID != alias.YouTubeID:
+		existing.Previous = append(existing.Previous, AliasTarget{
+			YouTubeID: existing.YouTubeID,
+			From:      existing.ResolvedAt,
+			Until:     existing.LastSeenAt,
+		})
+		existing.YouTubeID = alias.YouTubeID
+		existing.ResolvedAt = now
+	}
+	existing.LastSeenAt = now
+	*alias = *copyChannelAlias(existing)
+	return nil
+}
+
+func (m *MemoryStore) GetChannelAlias(ctx context.Context, alias string) (*ChannelAlias, error) {
+	key, _, ok := NormalizeChannelAlias(alias)
+	if !ok {
+		return nil, &StorageError{Op: "read", Entity: "channel_alias", ID: alias, Err: ErrInvalidInput}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mapping, exists := m.aliases[key]
+	if !exists {
+		return nil, notFound("read", "channel_alias", alias)
+	}
+	return copyChannelAlias(mapping), nil
+}
+
+func (m *MemoryStore) ListChannelAliases(ctx context.Context, youtubeID string) ([]*ChannelAlias, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var aliases []*ChannelAlias
+	for _, mapping := range m.aliases {
+		if mapping.YouTubeID == youtubeID {
+			aliases = append(aliases, copyChannelAlias(mapping))
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		if !aliases[i].ResolvedAt.Equal(aliases[j].ResolvedAt) {
+			return aliases[i].ResolvedAt.Before(aliases[j].ResolvedAt)
+		}
+		return aliases[i].Alias < aliases[j].Alias
+	})
+	return aliases, nil
+}
+
+var (
+	_ Store             = (*MemoryStore)(nil)
+	_ SyncReportStore   = (*MemoryStore)(nil)
+	_ ChannelAliasStore = (*MemoryStore)(nil)
+)
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testStoreSemantics checks the behavior every Store must share with
+// MemoryStore.
+func testStoreSemantics(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	channel := &Channel{YouTubeID: "UCsemantics", Name: "Semantics"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	if channel.ID == "" || channel.CreatedAt.IsZero() {
+		t.Errorf("CreateChannel() did not assign an ID and timestamps: %+v", channel)
+	}
+	if err := store.CreateChannel(ctx, &Channel{YouTubeID: "UCsemantics"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("CreateChannel(duplicate YouTube ID) error = %v, want ErrAlreadyExists", err)
+	}
+	if _, err := store.GetChannel(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetChannel(missing) error = %v, want ErrNotFound", err)
+	}
+	if err := store.UpdateChannel(ctx, &Channel{ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateChannel(missing) error = %v, want ErrNotFound", err)
+	}
+
+	// Changing the YouTube ID moves the index entry. The update is a copy,
+	// since stores hand out the records they hold.
+	renamed := *channel
+	renamed.YouTubeID = "UCrenamed"
+	channel = &renamed
+	if err := store.UpdateChannel(ctx, channel); err != nil {
+		t.Fatalf("UpdateChannel() error = %v", err)
+	}
+	if _, err := store.GetChannelByYouTubeID(ctx, "UCsemantics"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetChannelByYouTubeID(old) error = %v, want ErrNotFound", err)
+	}
+	if got, err := store.GetChannelByYouTubeID(ctx, "UCrenamed"); err != nil || got.ID != channel.ID {
+		t.Errorf("GetChannelByYouTubeID(new) = %+v, %v", got, err)
+	}
+
+	for _, id := range []string{"vid00000001", "vid00000002"} {
+		if err := store.CreateVideo(ctx, &Video{YouTubeID: id, ChannelID: channel.ID}); err != nil {
+			t.Fatalf("CreateVideo(%s) error = %v", id, err)
+		}
+	}
+	if err := store.CreateVideo(ctx, &Video{YouTubeID: "vid00000001"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("CreateVideo(duplicate YouTube ID) error = %v, want ErrAlreadyExists", err)
+	}
+	videos, err := store.ListVideosByChannel(ctx, channel.ID)
+	if err != nil || len(videos) != 2 || videos[0].YouTubeID != "vid00000001" {
+		t.Fatalf("ListVideosByChannel() = %v, %v; want both videos in creation order", videos, err)
+	}
+	first := videos[0]
+
+	transcript := &Transcript{VideoID: first.ID, Language: "en", Segments: []Segment{{Start: 2, Text: "world"}, {Start: 1, Text: "hello"}}}
+	if err := store.CreateTranscript(ctx, transcript); err != nil {
+		t.Fatalf("CreateTranscript() error = %v", err)
+	}
+	if err := store.CreateTranscript(ctx, &Transcript{VideoID: first.ID, Language: "en"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("CreateTranscript(duplicate language) error = %v, want ErrAlreadyExists", err)
+	}
+	if err := store.CreateTranscript(ctx, &Transcript{VideoID: first.ID, Language: "de", Content: "hallo"}); err != nil {
+		t.Fatalf("CreateTranscript(de) error = %v", err)
+	}
+	if got, err := store.GetTranscript(ctx, first.ID); err != nil || got.Language != "en" || got.Content != "hello world" {
+		t.Errorf("GetTranscript() = %+v, %v; want the first transcript, segments in order", got, err)
+	}
+	if langs, _ := store.ListTranscriptLanguages(ctx, first.ID); !reflect.DeepEqual(langs, []string{"en", "de"}) {
+		t.Errorf("ListTranscriptLanguages() = %q, want [en de]", langs)
+	}
+	if got, _ := store.GetVideo(ctx, first.ID); !got.HasTranscript {
+		t.Error("CreateTranscript() did not set HasTranscript")
+	}
+	if pending, _ := store.ListVideosNeedingTranscript(ctx); len(pending) != 1 || pending[0].YouTubeID != "vid00000002" {
+		t.Errorf("ListVideosNeedingTranscript() = %v, want only vid00000002", pending)
+	}
+	if all, _ := store.ListTranscriptsByChannel(ctx, channel.ID); len(all) != 2 {
+		t.Errorf("ListTranscriptsByChannel() = %d transcripts, want 2", len(all))
+	}
+
+	if err := store.DeleteTranscriptByLanguage(ctx, first.ID, "en"); err != nil {
+		t.Fatalf("DeleteTranscriptByLanguage() error = %v", err)
+	}
+	if got, _ := store.GetVideo(ctx, first.ID); !got.HasTranscript {
+		t.Error("deleting one of two transcripts cleared HasTranscript")
+	}
+	if err := store.DeleteTranscript(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteTranscript() error = %v", err)
+	}
+	if got, _ := store.GetVideo(ctx, first.ID); got.HasTranscript {
+		t.Error("DeleteTranscript() did not clear HasTranscript")
+	}
+	if err := store.DeleteTranscript(ctx, first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteTranscript(again) error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := store.GetSyncState(ctx, channel.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSyncState(none) error = %v, want ErrNotFound", err)
+	}
+	state := NewSyncState(channel.ID)
+	state.CompleteSync()
+	if err := store.UpdateSyncState(ctx, state); err != nil {
+		t.Fatalf("UpdateSyncState() error = %v", err)
+	}
+	if last, err := store.GetLastSync(ctx, channel.ID); err != nil || !last.Equal(state.LastSyncAt) {
+		t.Errorf("GetLastSync() = %v, %v; want %v", last, err, state.LastSyncAt)
+	}
+
+	if err := store.DeleteVideo(ctx, first.ID); err != nil {
+		t.Fatalf("DeleteVideo() error = %v", err)
+	}
+	if _, err := store.GetVideoByYouTubeID(ctx, "vid00000001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetVideoByYouTubeID(deleted) error = %v, want ErrNotFound", err)
+	}
+	if videos, _ := store.ListVideosByChannel(ctx, channel.ID); len(videos) != 1 {
+		t.Errorf("ListVideosByChannel() after delete = %d videos, want 1", len(videos))
+	}
+	if err := store.DeleteChannel(ctx, channel.ID); err != nil {
+		t.Fatalf("DeleteChannel() error = %v", err)
+	}
+	if _, err := store.GetSyncState(ctx, channel.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSyncState(deleted channel) error = %v, want ErrNotFound", err)
+	}
+	if channels, _ := store.ListChannels(ctx); len(channels) != 0 {
+		t.Errorf("ListChannels() = %d channels, want 0", len(channels))
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStoreSemantics(t, NewMemoryStore())
+}
+
+func TestJSONStore_MatchesMemoryStore(t *testing.T) {
+	store, err := NewJSONStore(filepath.Join(t.TempDir(), "test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testStoreSemantics(t, store)
+}
@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"ytsync/errcode"
+)
+
+// ErrSchemaVersion is returned when opening a store whose schema version
+// cannot be migrated to the current one, such as a store written by a newer
+// version of ytsync.
+var ErrSchemaVersion = errcode.New(errcode.InvalidInput, "storage: unsupported schema version")
+
+// migration upgrades the store document from one schema version to the
+// next. It works on the raw top-level JSON object, since an old document
+// need not unmarshal into the current storeData.
+type migration struct {
+	from, to    string
+	description string
+	apply       func(doc map[string]json.RawMessage) error
+}
+
+// migrations holds the registered steps, keyed by the version they
+// upgrade from.
+var migrations = make(map[string]migration)
+
+// registerMigration adds a step upgrading documents at schema version from
+// to version to. Steps are chained until schemaVersion is reached.
+func registerMigration(from, to, description string, apply func(doc map[string]json.RawMessage) error) {
+	if _, exists := migrations[from]; exists {
+		panic("storage: duplicate migration from schema " + from)
+	}
+	migrations[from] = migration{from: from, to: to, description: description, apply: apply}
+}
+
+func init() {
+	registerMigration("", "1.0", "add version and rebuild lookup indexes", migrateUnversioned)
+	registerMigration("1.0", "1.1", "key transcripts by video and language", migrateTranscriptLanguages)
+}
+
+// documentVersion returns the schema version recorded in a store document.
+func documentVersion(data []byte) (string, error) {
+	var header struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return "", ErrStorageCorrupt
+	}
+	return header.Version, nil
+}
+
+// migrateDocument upgrades a store document to schemaVersion, applying
+// each registered step in turn. Nothing is written: if any step fails, the
+// caller still has the original document.
+func migrateDocument(data []byte) ([]byte, error) {
+	version, err := documentVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version == schemaVersion {
+		return data, nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, ErrStorageCorrupt
+	}
+	for steps := 0; version != schemaVersion; steps++ {
+		m, ok := migrations[version]
+		if !ok || steps > len(migrations) {
+			return nil, fmt.Errorf("%w: %q (this version reads %q)", ErrSchemaVersion, version, schemaVersion)
+		}
+		if err := m.apply(doc); err != nil {
+			return nil, fmt.Errorf("migrate schema %q to %q (%s): %w", m.from, m.to, m.description, err)
+		}
+		to, err := json.Marshal(m.to)
+		if err != nil {
+			return nil, err
+		}
+		doc["version"] = to
+		version = m.to
+	}
+	return json.Marshal(doc)
+}
+
+// migrationBackupPath returns where a store file at path is kept before it
+// is migrated from schema version.
+func migrationBackupPath(path, version string) string {
+	if version == "" {
+		version = "unversioned"
+	}
+	return fmt.Sprintf("%s.schema-%s.bak", path, version)
+}
+
+// migrateUnversioned upgrades stores written before the schema was
+// versioned, which lack the version field and may lack the lookup indexes.
+func migrateUnversioned(doc map[string]json.RawMessage) error {
+	if _, ok := doc["indexes"]; ok && string(doc["indexes"]) != "null" {
+		return nil
+	}
+
+	var records struct {
+		Channels map[string]struct {
+			YouTubeID string `json:"youtube_id"`
+		} `json:"channels"`
+		Videos map[string]struct {
+			YouTubeID string `json:"youtube_id"`
+			ChannelID string `json:"channel_id"`
+		} `json:"videos"`
+	}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, &records); err != nil {
+		return err
+	}
+
+	idx := newIndexes()
+	for id, channel := range records.Channels {
+		idx.YouTubeChannelID[channel.YouTubeID] = id
+	}
+	videoIDs := make([]string, 0, len(records.Videos))
+	for id := range records.Videos {
+		videoIDs = append(videoIDs, id)
+	}
+	sort.Strings(videoIDs)
+	for _, id := range videoIDs {
+		video := records.Videos[id]
+		idx.YouTubeVideoID[video.YouTubeID] = id
+		idx.VideosByChannel[video.ChannelID] = append(idx.VideosByChannel[video.ChannelID], id)
+	}
+	encoded, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	doc["indexes"] = encoded
+	return nil
+}
+
+// migrateTranscriptLanguages nests the transcripts of schema 1.0, keyed by
+// video ID alone, under their language, so a video can have one per
+// language.
+func migrateTranscriptLanguages(doc map[string]json.RawMessage) error {
+	raw, ok := doc["transcripts"]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+	var byVideo map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &byVideo); err != nil {
+		return err
+	}
+
+	nested := make(map[string]map[string]json.RawMessage, len(byVideo))
+	for videoID, record := range byVideo {
+		var header struct {
+			Language string `json:"language"`
+		}
+		if err := json.Unmarshal(record, &header); err != nil {
+			return fmt.Errorf("transcript %s: %w", videoID, err)
+		}
+		nested[videoID] = map[string]json.RawMessage{header.Language: record}
+	}
+	encoded, err := json.Marshal(nested)
+	if err != nil {
+		return err
+	}
+	doc["transcripts"] = encoded
+	return nil
+}
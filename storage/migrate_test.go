@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONStore_MigratesUnversionedStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	legacy := []byte(`{
+  "channels": {"c1": {"id": "c1", "youtube_id": "UClegacy", "name": "Legacy"}},
+  "videos": {
+    "v2": {"id": "v2", "youtube_id": "vid2", "channel_id": "c1", "title": "Second"},
+    "v1": {"id": "v1", "youtube_id": "vid1", "channel_id": "c1", "title": "First"}
+  },
+  "transcripts": {},
+  "sync_states": {}
+}`)
+	if err := os.WriteFile(path, legacy, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	channel, err := store.GetChannelByYouTubeID(ctx, "UClegacy")
+	if err != nil || channel.ID != "c1" {
+		t.Fatalf("GetChannelByYouTubeID() = %+v, %v", channel, err)
+	}
+	if videos, err := store.ListVideosByChannel(ctx, "c1"); err != nil || len(videos) != 2 {
+		t.Errorf("ListVideosByChannel() = %d videos, %v; want 2", len(videos), err)
+	}
+
+	raw, _ := os.ReadFile(path)
+	if version, _ := documentVersion(raw); version != schemaVersion {
+		t.Errorf("migrated store version = %q, want %q", version, schemaVersion)
+	}
+	backup, err := os.ReadFile(migrationBackupPath(path, ""))
+	if err != nil || !bytes.Equal(backup, legacy) {
+		t.Errorf("pre-migration backup = %q, %v; want the original file", backup, err)
+	}
+}
+
+func TestJSONStore_MigratesTranscriptsToLanguages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	v10 := []byte(`{
+  "version": "1.0",
+  "channels": {"c1": {"id": "c1", "youtube_id": "UC1"}},
+  "videos": {"v1": {"id": "v1", "youtube_id": "vid1", "channel_id": "c1", "has_transcript": true}},
+  "transcripts": {"v1": {"video_id": "v1", "language": "de", "content": "Hallo"}},
+  "sync_states": {},
+  "indexes": {"youtube_channel_id": {"UC1": "c1"}, "youtube_video_id": {"vid1": "v1"}, "videos_by_channel": {"c1": ["v1"]}}
+}`)
+	if err := os.WriteFile(path, v10, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewJSONStore(path)
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	got, err := store.GetTranscriptByLanguage(ctx, "v1", "de")
+	if err != nil || got.Content != "Hallo" {
+		t.Fatalf("GetTranscriptByLanguage(de) = %+v, %v", got, err)
+	}
+	if err := store.CreateTranscript(ctx, &Transcript{VideoID: "v1", Language: "en", Content: "Hello"}); err != nil {
+		t.Errorf("CreateTranscript(en) after migration error = %v", err)
+	}
+	if _, err := os.Stat(migrationBackupPath(path, "1.0")); err != nil {
+		t.Errorf("pre-migration backup: %v", err)
+	}
+}
+
+func TestJSONStore_RejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	newer := []byte(`{"version": "99.0", "channels": {}}`)
+	if err := os.WriteFile(path, newer, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewJSONStore(path); !errors.Is(err, ErrSchemaVersion) {
+		t.Fatalf("NewJSONStore() error = %v, want ErrSchemaVersion", err)
+	}
+	if raw, _ := os.ReadFile(path); !bytes.Equal(raw, newer) {
+		t.Error("store file changed after a refused open")
+	}
+}
+
+func TestJSONStore_FailedMigrationLeavesStore(t *testing.T) {
+	failing := errors.New("step failed")
+	migrations["0.9"] = migration{from: "0.9", to: schemaVersion, description: "test", apply: func(map[string]json.RawMessage) error {
+		return failing
+	}}
+	defer delete(migrations, "0.9")
+
+	path := filepath.Join(t.TempDir(), "store.json")
+	old := []byte(`{"version": "0.9", "channels": {}}`)
+	if err := os.WriteFile(path, old, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewJSONStore(path); !errors.Is(err, failing) {
+		t.Fatalf("NewJSONStore() error = %v, want the migration error", err)
+	}
+	if raw, _ := os.ReadFile(path); !bytes.Equal(raw, old) {
+		t.Error("store file changed by a failed migration")
+	}
+	if _, err := os.Stat(migrationBackupPath(path, "0.9")); !os.IsNotExist(err) {
+		t.Errorf("backup written for a failed migration: %v", err)
+	}
+}
@@ -1,6 +1,12 @@
 package storage
 
-import "time"
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"ytsync/errcode"
+)
 
 // Channel represents a YouTube channel being tracked.
 // It stores references to a YouTube channel and metadata for synchronization.
@@ -15,12 +21,162 @@ type Channel struct {
 	Description string `json:"description,omitempty"`
 	// URL is the full URL to the YouTube channel.
 	URL string `json:"url"`
+	// Handle is the channel's @handle, if known.
+	Handle string `json:"handle,omitempty"`
+	// Policy controls how the channel is archived. Nil means the defaults.
+	Policy *SyncPolicy `json:"policy,omitempty"`
+	// Avatar is the channel's profile picture, if it has been downloaded.
+	Avatar *ChannelArt `json:"avatar,omitempty"`
+	// Banner is the channel's header image, if it has been downloaded.
+	Banner *ChannelArt `json:"banner,omitempty"`
 	// CreatedAt is when this channel was first added to ytsync.
 	CreatedAt time.Time `json:"created_at"`
 	// UpdatedAt is when this channel record was last modified.
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Art returns the channel's image of the given kind, or nil if it has not
+// been downloaded.
+func (c *Channel) Art(kind ChannelArtKind) *ChannelArt {
+	switch kind {
+	case ChannelAvatar:
+		return c.Avatar
+	case ChannelBanner:
+		return c.Banner
+	}
+	return nil
+}
+
+// ChannelArtKind names one of a channel's images.
+type ChannelArtKind string
+
+const (
+	// ChannelAvatar is the channel's profile picture.
+	ChannelAvatar ChannelArtKind = "avatar"
+	// ChannelBanner is the header image shown across the channel page.
+	ChannelBanner ChannelArtKind = "banner"
+)
+
+// ChannelArt records a downloaded channel image. The image itself is kept
+// in the blob store when one is configured and inline otherwise; read it
+// with JSONStore.GetChannelArt.
+type ChannelArt struct {
+	// URL is the address the image was downloaded from.
+	URL string `json:"url"`
+	// ContentType is the image's MIME type, such as "image/jpeg".
+	ContentType string `json:"content_type,omitempty"`
+	// Digest is the hex SHA-256 of the image.
+	Digest string `json:"digest"`
+	// Size is the image's size in bytes.
+	Size int64 `json:"size"`
+	// Data is the image when no blob store is configured.
+	Data []byte `json:"data,omitempty"`
+	// Blob references the image in the blob store, if it is kept there.
+	Blob *BlobRef `json:"blob,omitempty"`
+	// FetchedAt is when the image was last downloaded.
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// SyncPolicy records how a tracked channel should be synced.
+type SyncPolicy struct {
+	// ContentType is "videos", "streams", or "both". Empty means videos.
+	ContentType string `json:"content_type,omitempty"`
+	// MaxVideos caps the number of videos listed per sync (0 = all).
+	MaxVideos int `json:"max_videos,omitempty"`
+	// Transcripts fetches transcripts for newly discovered videos.
+	Transcripts bool `json:"transcripts,omitempty"`
+	// Metadata fetches full metadata for newly discovered videos.
+	Metadata bool `json:"metadata,omitempty"`
+	// Paused excludes the channel from syncs until it is resumed.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// AliasKind identifies the form of a ChannelAlias.
+type AliasKind string
+
+const (
+	// AliasHandle is an @handle, e.g. "@fireship".
+	AliasHandle AliasKind = "handle"
+	// AliasCustomURL is a legacy custom URL, e.g. "c/fireship".
+	AliasCustomURL AliasKind = "custom_url"
+	// AliasUser is a legacy username URL, e.g. "user/fireship".
+	AliasUser AliasKind = "user"
+)
+
+// ChannelAlias maps a handle or custom URL to the YouTube channel ID it
+// resolved to, so later lookups can skip network resolution.
+type ChannelAlias struct {
+	// Alias is the normalized alias (see NormalizeChannelAlias).
+	Alias string `json:"alias"`
+	// Kind is the form of the alias.
+	Kind AliasKind `json:"kind"`
+	// YouTubeID is the channel ID the alias currently resolves to.
+	YouTubeID string `json:"youtube_id"`
+	// ResolvedAt is when the alias was first seen pointing at YouTubeID.
+	ResolvedAt time.Time `json:"resolved_at"`
+	// LastSeenAt is when the mapping was last confirmed.
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Previous lists channels the alias pointed to before, oldest first,
+	// for handles that have been released and claimed by another channel.
+	Previous []AliasTarget `json:"previous,omitempty"`
+}
+
+// AliasTarget is a channel an alias pointed to for a period of time.
+type AliasTarget struct {
+	// YouTubeID is the channel the alias resolved to.
+	YouTubeID string `json:"youtube_id"`
+	// From and Until bound when the alias was seen pointing at YouTubeID.
+	From  time.Time `json:"from"`
+	Until time.Time `json:"until"`
+}
+
+// channelIDPattern matches a channel ID, alone or in a /channel/ URL.
+var channelIDPattern = regexp.MustCompile(`^(?:(?:https?://)?(?:www\.|m\.)?youtube\.com/channel/)?(UC[a-zA-Z0-9_-]{22})(?:[/?#].*)?$`)
+
+// ChannelIDFromInput returns the channel ID if input is a channel ID or a
+// /channel/ URL, or "" otherwise.
+func ChannelIDFromInput(input string) string {
+	if m := channelIDPattern.FindStringSubmatch(strings.TrimSpace(input)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// NormalizeChannelAlias reduces the ways users type a handle or custom URL
+// ("@Name", "Name", "youtube.com/@Name/videos", "https://www.youtube.com/c/Name")
+// to a canonical, lowercase alias such as "@name" or "c/name". ok is false
+// if input is not a handle or custom URL.
+func NormalizeChannelAlias(input string) (alias string, kind AliasKind, ok bool) {
+	s := strings.TrimSpace(input)
+	for _, prefix := range []string{"https://", "http://"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	for _, prefix := range []string{"www.", "m."} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	s = strings.TrimPrefix(s, "youtube.com/")
+	if i := strings.IndexAny(s, "?#"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.Trim(s, "/")
+
+	parts := strings.Split(s, "/")
+	switch {
+	case len(parts) >= 2 && (parts[0] == "c" || parts[0] == "user") && parts[1] != "":
+		kind = AliasCustomURL
+		if parts[0] == "user" {
+			kind = AliasUser
+		}
+		return parts[0] + "/" + strings.ToLower(parts[1]), kind, true
+	case strings.HasPrefix(parts[0], "@") && len(parts[0]) > 1:
+		return strings.ToLower(parts[0]), AliasHandle, true
+	case len(parts) == 1 && parts[0] != "" && !strings.Contains(parts[0], ".") && ChannelIDFromInput(parts[0]) == "":
+		// A bare name is taken to be a handle without the @
+		return "@" + strings.ToLower(parts[0]), AliasHandle, true
+	}
+	return "", "", false
+}
+
 // Video represents a YouTube video.
 // It stores references to a video and tracks whether transcripts have been processed.
 type Video struct {
@@ -40,6 +196,12 @@ type Video struct {
 	Duration int `json:"duration"`
 	// HasTranscript indicates whether a transcript has been successfully fetched.
 	HasTranscript bool `json:"has_transcript"`
+	// TranscriptFailure is why the last attempt to fetch a transcript
+	// failed. Nil once one has been fetched.
+	TranscriptFailure *TranscriptFailure `json:"transcript_failure,omitempty"`
+	// SkipSegments are community-submitted sponsor, intro, and outro ranges
+	// (from SponsorBlock), if they have been fetched.
+	SkipSegments []SkipSegment `json:"skip_segments,omitempty"`
 	// CreatedAt is when this video was first added to ytsync.
 	CreatedAt time.Time `json:"created_at"`
 	// UpdatedAt is when this video record was last modified.
@@ -57,8 +219,17 @@ type Transcript struct {
 	Content string `json:"content"`
 	// Segments contains timed segments if available.
 	Segments []Segment `json:"segments,omitempty"`
+	// Chapters contains the transcript split by video chapter, if the video has chapters.
+	Chapters []Chapter `json:"chapters,omitempty"`
 	// Source indicates where the transcript came from ("youtube", "whisper", etc.).
 	Source string `json:"source"`
+	// AutoGenerated reports whether the transcript is from YouTube's
+	// automatic captions rather than captions the uploader provided.
+	AutoGenerated bool `json:"auto_generated,omitempty"`
+	// Blob references the stored Content, Segments, and Chapters when the
+	// store keeps transcript text in a BlobStore. Stores fill the text back
+	// in on read, so callers only see it set alongside the text.
+	Blob *BlobRef `json:"blob,omitempty"`
 	// CreatedAt is when this transcript was first added.
 	CreatedAt time.Time `json:"created_at"`
 	// UpdatedAt is when this transcript was last modified.
@@ -75,6 +246,85 @@ type Segment struct {
 	Text string `json:"text"`
 }
 
+// TextAt returns the text of the segment playing at offset seconds, or an
+// empty string if no segment covers it. When captions overlap, the segment
+// that started most recently wins.
+// Segments are expected in start-time order, as stored by the store.
+func (t *Transcript) TextAt(offset float64) string {
+	// Find the last segment starting at or before offset
+	i := sort.Search(len(t.Segments), func(i int) bool {
+		return t.Segments[i].Start > offset
+	}) - 1
+	for ; i >= 0; i-- {
+		if offset < t.Segments[i].End {
+			return t.Segments[i].Text
+		}
+	}
+	return ""
+}
+
+// Window returns the segments that overlap the time range [start, end) in
+// seconds, in start-time order.
+func (t *Transcript) Window(start, end float64) []Segment {
+	var out []Segment
+	for _, seg := range t.Segments {
+		if seg.Start >= end {
+			break
+		}
+		if seg.Start >= start || seg.End > start {
+			out = append(out, seg)
+		}
+	}
+	return out
+}
+
+// normalizeSegments sorts segments by start time and derives Content from
+// the segments when it is empty.
+func (t *Transcript) normalizeSegments() {
+	sort.SliceStable(t.Segments, func(i, j int) bool {
+		return t.Segments[i].Start < t.Segments[j].Start
+	})
+	if t.Content == "" && len(t.Segments) > 0 {
+		parts := make([]string, 0, len(t.Segments))
+		for _, seg := range t.Segments {
+			if text := strings.TrimSpace(seg.Text); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		t.Content = strings.Join(parts, " ")
+	}
+}
+
+// Chapter is the portion of a transcript that falls within a single video chapter.
+// Chapters let consumers treat each section of a long video as its own document.
+type Chapter struct {
+	// Index is the zero-based position of the chapter in the video.
+	Index int `json:"index"`
+	// Title is the chapter title.
+	Title string `json:"title"`
+	// Start is the chapter start time in seconds.
+	Start float64 `json:"start"`
+	// End is the chapter end time in seconds.
+	End float64 `json:"end"`
+	// Content is the plain text transcript for the chapter.
+	Content string `json:"content"`
+	// Segments contains the timed segments within the chapter.
+	Segments []Segment `json:"segments,omitempty"`
+}
+
+// SkipSegment is a time range of a video that is not part of its main content,
+// such as a sponsor read or intro.
+type SkipSegment struct {
+	// UUID is the source's segment ID.
+	UUID string `json:"uuid,omitempty"`
+	// Category is the segment category ("sponsor", "intro", "outro", etc.).
+	Category string `json:"category"`
+	// Start is the start time in seconds.
+	Start float64 `json:"start"`
+	// End is the end time in seconds.
+	End float64 `json:"end"`
+}
+
 // PaginationStrategy indicates which video listing strategy is being used.
 type PaginationStrategy string
 
@@ -101,7 +351,9 @@ type SyncState struct {
 	LastVideoID string `json:"last_video_id,omitempty"`
 	// VideosProcessed is the count of videos processed in the current sync.
 	VideosProcessed int `json:"videos_processed"`
-	// TotalVideos is the total number of videos on the channel.
+	// TotalVideos is the total number of videos on the channel as of the
+	// last sync, or 0 if it is not known. SyncManager compares it with the
+	// current count to skip unneeded full syncs.
 	TotalVideos int `json:"total_videos"`
 	// Status indicates the current sync state ("idle", "syncing", "error").
 	Status string `json:"status"`
@@ -325,3 +577,311 @@ func NewSyncState(channelID string) *SyncState {
 		Status:    SyncStatusIdle,
 	}
 }
+
+// SyncReport is an auditable summary of one channel sync run.
+type SyncReport struct {
+	// ChannelID is the YouTube channel ID that was synced.
+	ChannelID string `json:"channel_id"`
+	// ChannelURL is the URL or identifier the sync was requested with.
+	ChannelURL string `json:"channel_url,omitempty"`
+	// RunID identifies the sync run in the tags of its logs, request
+	// traces, and errors.
+	RunID string `json:"run_id,omitempty"`
+	// StartedAt is when the sync began.
+	StartedAt time.Time `json:"started_at"`
+	// FinishedAt is when the sync ended, successfully or not.
+	FinishedAt time.Time `json:"finished_at"`
+	// Duration is the total wall time of the sync.
+	Duration time.Duration `json:"duration"`
+	// Strategy is the listing strategy that produced the final result.
+	Strategy PaginationStrategy `json:"strategy,omitempty"`
+	// Incremental is true if the result came from an incremental RSS sync.
+	Incremental bool `json:"incremental"`
+	// GapDetected is true if the RSS feed had a gap and a full sync was run.
+	GapDetected bool `json:"gap_detected,omitempty"`
+	// ListingSkipped is true if the channel's video count showed that the
+	// RSS feed held every new video, so the full sync was skipped.
+	ListingSkipped bool `json:"listing_skipped,omitempty"`
+	// VideosSeen is the number of videos returned by the listing.
+	VideosSeen int `json:"videos_seen"`
+	// NewVideos holds the YouTube IDs of videos not previously stored.
+	NewVideos []string `json:"new_videos,omitempty"`
+	// UpdatedVideos holds the YouTube IDs of videos that were already stored.
+	UpdatedVideos []string `json:"updated_videos,omitempty"`
+	// TranscriptsFetched is the number of transcripts fetched successfully.
+	TranscriptsFetched int `json:"transcripts_fetched"`
+	// TranscriptFailures lists the videos whose transcript fetch failed.
+	TranscriptFailures []TranscriptFailure `json:"transcript_failures,omitempty"`
+	// TranscriptsPolled is the number of earlier videos whose transcript
+	// was asked for again because they are fresh uploads that had none.
+	// Their outcomes count towards TranscriptsFetched and
+	// TranscriptFailures.
+	TranscriptsPolled int `json:"transcripts_polled,omitempty"`
+	// StageFailures lists other per-video enrichment failures, such as
+	// metadata fetches or persistence.
+	StageFailures []StageFailure `json:"stage_failures,omitempty"`
+	// QuotaUsed is the YouTube Data API quota consumed, if the API was used.
+	QuotaUsed int `json:"quota_used,omitempty"`
+	// Requests is the number of listing requests made.
+	Requests int `json:"requests"`
+	// Phases records how long each phase of the sync took, in order.
+	Phases []PhaseTiming `json:"phases,omitempty"`
+	// Error is the error message if the sync failed.
+	Error string `json:"error,omitempty"`
+}
+
+// TranscriptFailure records why a transcript could not be fetched.
+type TranscriptFailure struct {
+	// VideoID is the YouTube video ID.
+	VideoID string `json:"video_id"`
+	// Reason is the error message.
+	Reason string `json:"reason"`
+	// Code is the errcode classification of the error.
+	Code string `json:"code,omitempty"`
+	// At is when the fetch failed, if recorded.
+	At time.Time `json:"at,omitzero"`
+}
+
+// NewTranscriptFailure returns the failure of the transcript fetch for the
+// video with YouTube ID videoID with err, at the current time.
+func NewTranscriptFailure(videoID string, err error) *TranscriptFailure {
+	return &TranscriptFailure{
+		VideoID: videoID,
+		Reason:  err.Error(),
+		Code:    errcode.Of(err).String(),
+		At:      time.Now(),
+	}
+}
+
+// StageFailure records a per-video enrichment stage that failed.
+type StageFailure struct {
+	// VideoID is the YouTube video ID.
+	VideoID string `json:"video_id"`
+	// Stage is the name of the stage that failed.
+	Stage string `json:"stage"`
+	// Reason is the error message.
+	Reason string `json:"reason"`
+	// Code is the errcode classification of the error.
+	Code string `json:"code,omitempty"`
+}
+
+// PhaseTiming is the duration of one named sync phase.
+type PhaseTiming struct {
+	// Phase is the phase name (e.g. "rss", "full", "persist").
+	Phase string `json:"phase"`
+	// Duration is how long the phase took.
+	Duration time.Duration `json:"duration"`
+}
+
+// NewSyncReport creates a report for a sync of channelID starting now.
+func NewSyncReport(channelID, channelURL string) *SyncReport {
+	return &SyncReport{
+		ChannelID:  channelID,
+		ChannelURL: channelURL,
+		StartedAt:  time.Now(),
+	}
+}
+
+// AddPhase records that phase ran since start.
+func (r *SyncReport) AddPhase(phase string, start time.Time) {
+	if r == nil {
+		return
+	}
+	r.Phases = append(r.Phases, PhaseTiming{Phase: phase, Duration: time.Since(start)})
+}
+
+// RecordTranscript records the outcome of a transcript fetch for videoID.
+func (r *SyncReport) RecordTranscript(videoID string, err error) {
+	if r == nil {
+		return
+	}
+	if err == nil {
+		r.TranscriptsFetched++
+		return
+	}
+	r.TranscriptFailures = append(r.TranscriptFailures, TranscriptFailure{
+		VideoID: videoID,
+		Reason:  err.Error(),
+		Code:    errcode.Of(err).String(),
+	})
+}
+
+// RecordStageFailure records that stage failed for videoID.
+func (r *SyncReport) RecordStageFailure(videoID, stage string, err error) {
+	if r == nil || err == nil {
+		return
+	}
+	r.StageFailures = append(r.StageFailures, StageFailure{
+		VideoID: videoID,
+		Stage:   stage,
+		Reason:  err.Error(),
+		Code:    errcode.Of(err).String(),
+	})
+}
+
+// Finish stamps the end time and duration, and records err if non-nil.
+func (r *SyncReport) Finish(err error) {
+	if r == nil {
+		return
+	}
+	r.FinishedAt = time.Now()
+	r.Duration = r.FinishedAt.Sub(r.StartedAt)
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// QuotaUsage is the YouTube Data API quota consumed by one API project on
+// one quota day. Quota days run midnight to midnight Pacific time.
+type QuotaUsage struct {
+	// Key identifies the API project, typically a digest of the API key.
+	Key string `json:"key"`
+	// Day is the quota day in YYYY-MM-DD form.
+	Day string `json:"day"`
+	// Used is the total units consumed on Day.
+	Used int `json:"used"`
+	// ByMethod breaks Used down by API method (e.g. "search.list").
+	ByMethod map[string]int `json:"by_method,omitempty"`
+	// UpdatedAt is when usage was last recorded.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// VideoStats is one sample of a video's public engagement counters.
+type VideoStats struct {
+	// VideoID is a foreign key reference to Video.ID.
+	VideoID string `json:"video_id"`
+	// SampledAt is when the counters were read.
+	SampledAt time.Time `json:"sampled_at"`
+	// ViewCount is the total number of views.
+	ViewCount int64 `json:"view_count"`
+	// LikeCount is the number of likes. Zero if hidden or unknown.
+	LikeCount int64 `json:"like_count"`
+	// CommentCount is the number of comments. Zero if disabled or unknown.
+	CommentCount int64 `json:"comment_count"`
+}
+
+// TranscriptOutcome is the result of a transcript extraction attempt.
+type TranscriptOutcome string
+
+const (
+	// TranscriptSucceeded means a transcript was fetched.
+	TranscriptSucceeded TranscriptOutcome = "success"
+	// TranscriptFailed means no transcript could be fetched.
+	TranscriptFailed TranscriptOutcome = "failure"
+)
+
+// TranscriptAttempt records one attempt to extract a video's transcript.
+type TranscriptAttempt struct {
+	// VideoID is a foreign key reference to Video.ID.
+	VideoID string `json:"video_id"`
+	// At is when the attempt was made.
+	At time.Time `json:"at"`
+	// Language is the language of the transcript fetched, or the languages
+	// asked for, comma-separated, if none was. Empty if any would do.
+	Language string `json:"language,omitempty"`
+	// Outcome is whether a transcript was fetched.
+	Outcome TranscriptOutcome `json:"outcome"`
+	// Code is the errcode classification of a failure.
+	Code string `json:"code,omitempty"`
+	// Error is the error message of a failure.
+	Error string `json:"error,omitempty"`
+}
+
+// TranscriptRetryPolicy decides when a video whose transcript could not be
+// fetched is tried again, so videos that will never have captions are not
+// asked for them on every sync.
+type TranscriptRetryPolicy struct {
+	// RetryAfter is how long to wait after a failed attempt before the
+	// next. Zero retries at once.
+	RetryAfter time.Duration `json:"retry_after"`
+	// MaxAttempts gives up on a video after this many failed attempts in a
+	// row. Zero never gives up.
+	MaxAttempts int `json:"max_attempts"`
+	// PollSchedule is how long to wait after each failed attempt for a
+	// video published less than PollWindow ago, whose automatic captions
+	// may not have been generated yet: the nth failure in a row waits
+	// PollSchedule[n-1], and the last wait repeats. Fresh videos are not
+	// given up on under MaxAttempts. Empty treats every video alike.
+	PollSchedule []time.Duration `json:"poll_schedule,omitempty"`
+	// PollWindow is how long after its publication a video follows
+	// PollSchedule.
+	PollWindow time.Duration `json:"poll_window,omitempty"`
+}
+
+// Due reports whether a video with the given attempts, oldest first, should
+// be tried again at now. A video never tried, or whose last attempt
+// succeeded, is due. It is DueFor with an unknown publish time, so
+// PollSchedule does not apply.
+func (p *TranscriptRetryPolicy) Due(attempts []*TranscriptAttempt, now time.Time) bool {
+	return p.DueFor(time.Time{}, attempts, now)
+}
+
+// DueFor reports whether a video published at published with the given
+// attempts, oldest first, should be tried again at now. Videos whose last
+// failure came while they were polling (see Polling) wait as PollSchedule
+// says; others wait RetryAfter and are given up on after MaxAttempts.
+func (p *TranscriptRetryPolicy) DueFor(published time.Time, attempts []*TranscriptAttempt, now time.Time) bool {
+	failures := 0
+	for i := len(attempts) - 1; i >= 0 && attempts[i].Outcome == TranscriptFailed; i-- {
+		failures++
+	}
+	if p == nil || failures == 0 {
+		return true
+	}
+	last := attempts[len(attempts)-1].At
+	if p.Polling(published, last) {
+		wait := p.PollSchedule[min(failures, len(p.PollSchedule))-1]
+		return !now.Before(last.Add(wait))
+	}
+	if p.MaxAttempts > 0 && failures >= p.MaxAttempts {
+		return false
+	}
+	return !now.Before(last.Add(p.RetryAfter))
+}
+
+// Polling reports whether a video published at published is still within
+// PollWindow at now, so its failed attempts follow PollSchedule. A zero
+// publish time is never polling.
+func (p *TranscriptRetryPolicy) Polling(published, now time.Time) bool {
+	return p != nil && len(p.PollSchedule) > 0 && !published.IsZero() &&
+		now.Before(published.Add(p.PollWindow))
+}
+
+// Keyword is a term that characterizes a transcript or a channel.
+type Keyword struct {
+	// Term is the lowercased word.
+	Term string `json:"term"`
+	// Score is the term's TF-IDF weight; higher is more characteristic.
+	Score float64 `json:"score"`
+	// Count is how often the term occurs: in the transcript for a video's
+	// keywords, or in all of the channel's transcripts for a topic.
+	Count int `json:"count"`
+	// Videos is the number of videos the term is a keyword of. Only set
+	// for channel topics.
+	Videos int `json:"videos,omitempty"`
+}
+
+// ChannelKeywords summarizes the topics of a channel's transcripts.
+type ChannelKeywords struct {
+	// ChannelID is a foreign key reference to Channel.ID.
+	ChannelID string `json:"channel_id"`
+	// Topics are the channel's most characteristic terms, best first.
+	Topics []Keyword `json:"topics"`
+	// Videos maps Video.ID to that video's keywords, best first.
+	Videos map[string][]Keyword `json:"videos,omitempty"`
+	// Transcripts is the number of transcripts the summary was built from.
+	Transcripts int `json:"transcripts"`
+	// ComputedAt is when the summary was built.
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// ArchivedDownload records a video that has been downloaded, like a line
+// of yt-dlp's --download-archive file.
+type ArchivedDownload struct {
+	// VideoID is the YouTube video ID.
+	VideoID string `json:"video_id"`
+	// Path is the downloaded file, if known.
+	Path string `json:"path,omitempty"`
+	// DownloadedAt is when the download finished.
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
@@ -3,23 +3,23 @@ package storage
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
+	"ytsync/errcode"
 )
 
 // Sentinel errors for common storage conditions.
 var (
 	// ErrNotFound indicates the requested entity was not found.
-	ErrNotFound = errors.New("storage: not found")
+	ErrNotFound = errcode.New(errcode.NotFound, "storage: not found")
 	// ErrAlreadyExists indicates the entity already exists in storage.
-	ErrAlreadyExists = errors.New("storage: already exists")
+	ErrAlreadyExists = errcode.New(errcode.AlreadyExists, "storage: already exists")
 	// ErrInvalidInput indicates invalid or malformed input was provided.
-	ErrInvalidInput = errors.New("storage: invalid input")
+	ErrInvalidInput = errcode.New(errcode.InvalidInput, "storage: invalid input")
 	// ErrStorageCorrupt indicates data corruption was detected.
-	ErrStorageCorrupt = errors.New("storage: data corruption detected")
+	ErrStorageCorrupt = errcode.New(errcode.Corrupt, "storage: data corruption detected")
 	// ErrLockTimeout indicates a timeout acquiring a file lock.
-	ErrLockTimeout = errors.New("storage: lock acquisition timeout")
+	ErrLockTimeout = errcode.New(errcode.Timeout, "storage: lock acquisition timeout")
 )
 
 // StorageError wraps storage errors with operation and entity context.
@@ -71,6 +71,10 @@ type ChannelStore interface {
 	GetChannel(ctx context.Context, id string) (*Channel, error)
 	// GetChannelByYouTubeID retrieves a channel by its YouTube ID.
 	GetChannelByYouTubeID(ctx context.Context, youtubeID string) (*Channel, error)
+	// GetChannelByHandle retrieves a channel by a handle, custom URL, or
+	// channel ID in any form accepted by NormalizeChannelAlias, using the
+	// recorded channel aliases.
+	GetChannelByHandle(ctx context.Context, handle string) (*Channel, error)
 	// UpdateChannel updates an existing channel record.
 	UpdateChannel(ctx context.Context, channel *Channel) error
 	// DeleteChannel removes a channel from storage.
@@ -97,17 +101,29 @@ type VideoStore interface {
 	ListVideosNeedingTranscript(ctx context.Context) ([]*Video, error)
 }
 
-// TranscriptStore handles transcript CRUD operations.
+// TranscriptStore handles transcript CRUD operations. A video has at most
+// one transcript per language.
 type TranscriptStore interface {
-	// CreateTranscript saves a new transcript to storage.
+	// CreateTranscript saves a new transcript to storage. It fails with
+	// ErrAlreadyExists if the video has a transcript in that language.
 	CreateTranscript(ctx context.Context, transcript *Transcript) error
-	// GetTranscript retrieves a transcript for a specific video.
+	// GetTranscript retrieves the primary transcript of a video: the
+	// first one stored, whatever its language.
 	GetTranscript(ctx context.Context, videoID string) (*Transcript, error)
-	// UpdateTranscript updates an existing transcript record.
+	// GetTranscriptByLanguage retrieves a video's transcript in language.
+	GetTranscriptByLanguage(ctx context.Context, videoID, language string) (*Transcript, error)
+	// ListTranscriptLanguages returns the languages a video has transcripts
+	// in, the primary transcript's first.
+	ListTranscriptLanguages(ctx context.Context, videoID string) ([]string, error)
+	// UpdateTranscript updates the existing transcript of the video in the
+	// transcript's language.
 	UpdateTranscript(ctx context.Context, transcript *Transcript) error
-	// DeleteTranscript removes a transcript from storage.
+	// DeleteTranscript removes every transcript of a video.
 	DeleteTranscript(ctx context.Context, videoID string) error
-	// ListTranscriptsByChannel retrieves all transcripts for videos in a channel.
+	// DeleteTranscriptByLanguage removes a video's transcript in language.
+	DeleteTranscriptByLanguage(ctx context.Context, videoID, language string) error
+	// ListTranscriptsByChannel retrieves all transcripts, in every
+	// language, for videos in a channel.
 	ListTranscriptsByChannel(ctx context.Context, channelID string) ([]*Transcript, error)
 }
 
@@ -120,3 +136,81 @@ type SyncStateStore interface {
 	// GetLastSync returns the timestamp of the last successful sync for a channel.
 	GetLastSync(ctx context.Context, channelID string) (time.Time, error)
 }
+
+// SyncReportStore keeps a history of sync reports per channel.
+type SyncReportStore interface {
+	// SaveSyncReport appends a report to the channel's sync history.
+	SaveSyncReport(ctx context.Context, report *SyncReport) error
+	// ListSyncReports returns a channel's sync reports, oldest first.
+	ListSyncReports(ctx context.Context, channelID string) ([]*SyncReport, error)
+}
+
+// QuotaStore persists API quota usage so that several processes sharing an
+// API key also share one daily budget.
+type QuotaStore interface {
+	// AddQuotaUsage adds units spent on method to the usage of key on day and
+	// returns the updated usage. Implementations must apply the addition
+	// atomically.
+	AddQuotaUsage(ctx context.Context, key, day, method string, units int) (*QuotaUsage, error)
+	// GetQuotaUsage returns the usage of key on day.
+	GetQuotaUsage(ctx context.Context, key, day string) (*QuotaUsage, error)
+}
+
+// ChannelAliasStore records which channel each handle and custom URL
+// resolved to, so resolution can skip the network.
+type ChannelAliasStore interface {
+	// SaveChannelAlias records that alias.Alias resolves to alias.YouTubeID.
+	// If the alias pointed to a different channel before, that mapping is
+	// kept in Previous.
+	SaveChannelAlias(ctx context.Context, alias *ChannelAlias) error
+	// GetChannelAlias returns the mapping for a handle or custom URL in any
+	// form accepted by NormalizeChannelAlias.
+	GetChannelAlias(ctx context.Context, alias string) (*ChannelAlias, error)
+	// ListChannelAliases returns every alias currently resolving to the
+	// channel with YouTube ID youtubeID, oldest first.
+	ListChannelAliases(ctx context.Context, youtubeID string) ([]*ChannelAlias, error)
+}
+
+// VideoStatsStore keeps a time series of view, like, and comment counts
+// per video, so growth can be charted instead of only the latest count.
+type VideoStatsStore interface {
+	// RecordVideoStats appends a sample to the video's history.
+	RecordVideoStats(ctx context.Context, stats *VideoStats) error
+	// GetStatsHistory returns the samples for the video with internal ID
+	// videoID taken in [since, until], oldest first. A zero since or until
+	// leaves that end of the range open.
+	GetStatsHistory(ctx context.Context, videoID string, since, until time.Time) ([]*VideoStats, error)
+}
+
+// TranscriptAttemptStore keeps a history of transcript extraction attempts
+// per video, so retries can follow a TranscriptRetryPolicy.
+type TranscriptAttemptStore interface {
+	// RecordTranscriptAttempt appends an attempt to the video's history,
+	// dropping the oldest beyond the store's retention limit.
+	RecordTranscriptAttempt(ctx context.Context, attempt *TranscriptAttempt) error
+	// ListTranscriptAttempts returns the recorded attempts for the video
+	// with internal ID videoID, oldest first.
+	ListTranscriptAttempts(ctx context.Context, videoID string) ([]*TranscriptAttempt, error)
+}
+
+// KeywordStore keeps the keyword summary of each channel, so archives can
+// be tagged and browsed by topic without recomputing it.
+type KeywordStore interface {
+	// SaveChannelKeywords replaces the summary of keywords.ChannelID.
+	SaveChannelKeywords(ctx context.Context, keywords *ChannelKeywords) error
+	// GetChannelKeywords returns the summary last saved for channelID.
+	GetChannelKeywords(ctx context.Context, channelID string) (*ChannelKeywords, error)
+}
+
+// DownloadArchiveStore records which videos have been downloaded, so batch
+// downloads of a channel or playlist skip them on the next run.
+type DownloadArchiveStore interface {
+	// RecordDownload adds a video to the archive, replacing any earlier
+	// record of it.
+	RecordDownload(ctx context.Context, download *ArchivedDownload) error
+	// IsDownloaded reports whether the video with YouTube ID videoID is in
+	// the archive.
+	IsDownloaded(ctx context.Context, videoID string) (bool, error)
+	// ListDownloads returns the archive, oldest download first.
+	ListDownloads(ctx context.Context) ([]*ArchivedDownload, error)
+}
@@ -0,0 +1,190 @@
+// Package tags attaches identifying tags to a context, such as the channel
+// being synced, the operation, and the run it belongs to. The HTTP client,
+// listers, and subprocess wrappers copy the tags of the context they are
+// given into their logs, request traces, and errors, so that requests made
+// by concurrent syncs of several channels can be told apart.
+//
+//	ctx = tags.WithRun(ctx, tags.NewRunID())
+//	ctx = tags.WithChannel(ctx, "UCxxxxx")
+//	tags.Logf(ctx, "ytsync: listing") // ytsync: listing [run=9f2c41d07ab35e18 channel=UCxxxxx]
+package tags
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Well-known tag keys.
+const (
+	// Channel is the YouTube channel ID an operation works on.
+	Channel = "channel"
+	// Video is the YouTube video ID an operation works on.
+	Video = "video"
+	// Operation names the operation, such as "rss", "full", or "enrich".
+	Operation = "op"
+	// Run identifies one run of a sync or other top-level operation.
+	Run = "run"
+)
+
+// Tag is a key and its value.
+type Tag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Tags are the tags of a context, in the order their keys were first set.
+type Tags []Tag
+
+type contextKey struct{}
+
+// From returns the tags of ctx, or nil if it has none.
+func From(ctx context.Context) Tags {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(contextKey{}).(Tags)
+	return t
+}
+
+// With returns a copy of ctx tagged with key set to value. A key that is
+// already set keeps its position and takes the new value; an empty value
+// removes it.
+func With(ctx context.Context, key, value string) context.Context {
+	old := From(ctx)
+	t := make(Tags, 0, len(old)+1)
+	found := false
+	for _, tag := range old {
+		if tag.Key == key {
+			found = true
+			if value == "" {
+				continue
+			}
+			tag.Value = value
+		}
+		t = append(t, tag)
+	}
+	if !found && value != "" {
+		t = append(t, Tag{Key: key, Value: value})
+	}
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// Default returns ctx tagged with key set to value unless key is already
+// set. Listers use it to tag requests with the channel they list without
+// overriding the caller's tags.
+func Default(ctx context.Context, key, value string) context.Context {
+	if From(ctx).Get(key) != "" {
+		return ctx
+	}
+	return With(ctx, key, value)
+}
+
+// WithChannel returns a copy of ctx tagged with the channel ID id.
+func WithChannel(ctx context.Context, id string) context.Context {
+	return With(ctx, Channel, id)
+}
+
+// WithVideo returns a copy of ctx tagged with the video ID id.
+func WithVideo(ctx context.Context, id string) context.Context {
+	return With(ctx, Video, id)
+}
+
+// WithOperation returns a copy of ctx tagged with the operation name.
+func WithOperation(ctx context.Context, name string) context.Context {
+	return With(ctx, Operation, name)
+}
+
+// WithRun returns a copy of ctx tagged with the run ID id.
+func WithRun(ctx context.Context, id string) context.Context {
+	return With(ctx, Run, id)
+}
+
+// NewRunID returns a random 16-character hex run ID.
+func NewRunID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Get returns the value of key, or "" if it is not set.
+func (t Tags) Get(key string) string {
+	for _, tag := range t {
+		if tag.Key == key {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+// Map returns the tags as a map, or nil if there are none.
+func (t Tags) Map() map[string]string {
+	if len(t) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(t))
+	for _, tag := range t {
+		m[tag.Key] = tag.Value
+	}
+	return m
+}
+
+// String formats the tags as space-separated key=value pairs.
+func (t Tags) String() string {
+	parts := make([]string, len(t))
+	for i, tag := range t {
+		parts[i] = tag.Key + "=" + tag.Value
+	}
+	return strings.Join(parts, " ")
+}
+
+// Logf logs like log.Printf, followed by the tags of ctx in brackets.
+func Logf(ctx context.Context, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if t := From(ctx); len(t) > 0 {
+		msg += " [" + t.String() + "]"
+	}
+	log.Output(2, msg)
+}
+
+// Error is an error annotated with the tags of the context of the
+// operation that returned it. Its message ends with the tags in brackets.
+type Error struct {
+	// Tags are the tags of the operation's context.
+	Tags Tags
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the underlying error's message followed by the tags.
+func (e *Error) Error() string {
+	return e.Err.Error() + " [" + e.Tags.String() + "]"
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap annotates err with the tags of ctx. It returns err unchanged if err
+// is nil, ctx has no tags, or err already carries tags.
+func Wrap(ctx context.Context, err error) error {
+	t := From(ctx)
+	if err == nil || len(t) == 0 || Of(err) != nil {
+		return err
+	}
+	return &Error{Tags: t, Err: err}
+}
+
+// Of returns the tags err was annotated with by Wrap, or nil if it has none.
+func Of(err error) Tags {
+	var tagged *Error
+	if errors.As(err, &tagged) {
+		return tagged.Tags
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package tags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	ctx := WithRun(context.Background(), "r1")
+	ctx = WithChannel(ctx, "UCa")
+	parent := WithOperation(ctx, "rss")
+	child := WithOperation(WithRun(parent, "r2"), "full")
+
+	if got := From(parent).String(); got != "run=r1 channel=UCa op=rss" {
+		t.Errorf("parent tags = %q", got)
+	}
+	// Setting a key again keeps its position and leaves the parent alone
+	if got := From(child).String(); got != "run=r2 channel=UCa op=full" {
+		t.Errorf("child tags = %q", got)
+	}
+	if got := From(With(child, Channel, "")).String(); got != "run=r2 op=full" {
+		t.Errorf("tags after removing channel = %q", got)
+	}
+	if got := From(Default(child, Run, "r3")).Get(Run); got != "r2" {
+		t.Errorf("Default() replaced run %q", got)
+	}
+	if got := From(Default(child, Video, "v1")).Get(Video); got != "v1" {
+		t.Errorf("Default() video = %q, want v1", got)
+	}
+	if From(context.Background()) != nil {
+		t.Error("untagged context has tags")
+	}
+	if id := NewRunID(); len(id) != 16 || id == NewRunID() {
+		t.Errorf("NewRunID() = %q, want 16 random hex characters", id)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	base := errors.New("boom")
+	if err := Wrap(context.Background(), base); err != base {
+		t.Errorf("Wrap() without tags = %v, want the error unchanged", err)
+	}
+	if Wrap(WithChannel(context.Background(), "UCa"), nil) != nil {
+		t.Error("Wrap(nil) != nil")
+	}
+
+	ctx := WithOperation(WithChannel(context.Background(), "UCa"), "rss")
+	err := Wrap(ctx, base)
+	if err.Error() != "boom [channel=UCa op=rss]" {
+		t.Errorf("Error() = %q", err)
+	}
+	if !errors.Is(err, base) {
+		t.Error("wrapped error does not match the original")
+	}
+	// An error that already carries tags is not wrapped again
+	if again := Wrap(WithVideo(ctx, "v1"), err); again != err {
+		t.Errorf("Wrap() of a tagged error = %q", again)
+	}
+	if got := Of(err).Get(Operation); got != "rss" {
+		t.Errorf("Of() op = %q, want rss", got)
+	}
+}
+
+func TestLogf(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	flags := log.Flags()
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	}()
+
+	Logf(WithChannel(context.Background(), "UCa"), "listed %d videos", 3)
+	Logf(context.Background(), "untagged")
+	if got := strings.TrimSpace(buf.String()); got != "listed 3 videos [channel=UCa]\nuntagged" {
+		t.Errorf("log output = %q", got)
+	}
+}
@@ -0,0 +1,542 @@
+// Package websub watches YouTube channels for new uploads with WebSub
+// (PubSubHubbub) push notifications.
+//
+// A Subscriber asks YouTube's hub to push a channel's feed to a callback
+// URL, serves that URL as an http.Handler to verify subscriptions and
+// receive notifications, and renews each subscription before its lease
+// expires. Received notifications are passed to a Handler; EnrichHandler
+// feeds them into the same enrichment pipeline a channel sync uses.
+//
+// The callback URL must be reachable by the hub from the internet.
+package websub
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"ytsync/errcode"
+	"ytsync/youtube"
+)
+
+// DefaultHubURL is YouTube's WebSub hub.
+const DefaultHubURL = "https://pubsubhubbub.appspot.com/subscribe"
+
+// DefaultLease is the subscription lease requested when Config.Lease is
+// zero. The hub may grant a shorter one.
+const DefaultLease = 5 * 24 * time.Hour
+
+// DefaultRenewBefore is how long before a lease expires it is renewed when
+// Config.RenewBefore is zero.
+const DefaultRenewBefore = 12 * time.Hour
+
+// DefaultQueueSize is the number of notifications buffered for the Handler
+// when Config.QueueSize is zero.
+const DefaultQueueSize = 100
+
+// verifyTimeout is how long a subscription may wait for the hub's
+// verification request before it is requested again.
+const verifyTimeout = 5 * time.Minute
+
+// checkInterval is how often Run looks for leases to renew.
+const checkInterval = time.Minute
+
+// maxNotificationSize limits the body of a notification request.
+const maxNotificationSize = 1 << 20
+
+var (
+	// ErrNoCallback is returned by NewSubscriber without a callback URL.
+	ErrNoCallback = errcode.New(errcode.InvalidInput, "websub: callback URL is required")
+	// ErrHubRejected is returned when the hub refuses a subscription request.
+	ErrHubRejected = errcode.New(errcode.Unavailable, "websub: hub rejected request")
+)
+
+// TopicURL returns the feed URL YouTube publishes a channel's uploads to.
+func TopicURL(channelID string) string {
+	return "https://www.youtube.com/xml/feeds/videos.xml?channel_id=" + url.QueryEscape(channelID)
+}
+
+// Notification is a pushed change to a channel's feed. YouTube sends one
+// for a new upload and again when a video's title or description changes,
+// so handlers should tolerate repeats.
+type Notification struct {
+	VideoID     string
+	ChannelID   string
+	ChannelName string
+	Title       string
+	URL         string
+	Published   time.Time
+	Updated     time.Time
+	// Deleted is set when the video was deleted or made private. Only
+	// VideoID, ChannelID, and Updated are known.
+	Deleted bool
+}
+
+// VideoInfo converts n to the form listers return.
+func (n *Notification) VideoInfo() youtube.VideoInfo {
+	return youtube.VideoInfo{
+		ID:          n.VideoID,
+		Title:       n.Title,
+		ChannelID:   n.ChannelID,
+		ChannelName: n.ChannelName,
+		Published:   n.Published,
+	}
+}
+
+// Handler processes a notification. Handlers run one at a time in the
+// order notifications were received.
+type Handler func(ctx context.Context, n *Notification)
+
+// State is the state of a subscription.
+type State string
+
+const (
+	// StatePending subscriptions were requested and await verification.
+	StatePending State = "pending"
+	// StateActive subscriptions were verified and receive notifications.
+	StateActive State = "active"
+	// StateDenied subscriptions were refused by the hub. They are retried
+	// at the next renewal check.
+	StateDenied State = "denied"
+	// StateUnsubscribing subscriptions await verification of an
+	// unsubscribe request.
+	StateUnsubscribing State = "unsubscribing"
+)
+
+// Subscription is the status of one channel subscription.
+type Subscription struct {
+	ChannelID string
+	Topic     string
+	State     State
+	// RequestedAt is when the subscription was last requested from the hub.
+	RequestedAt time.Time
+	// Expires is when the lease ends, once active.
+	Expires time.Time
+	// Error is the hub's reason for a denial or the last request failure.
+	Error string
+}
+
+// Config configures a Subscriber.
+type Config struct {
+	// CallbackURL is the public URL the Subscriber is served at. Required.
+	CallbackURL string
+	// HubURL defaults to DefaultHubURL.
+	HubURL string
+	// Secret, if set, is shared with the hub to sign notifications;
+	// notifications without a valid signature are ignored.
+	Secret string
+	// Lease is the requested subscription lease. Defaults to DefaultLease.
+	Lease time.Duration
+	// RenewBefore is how long before expiry a lease is renewed.
+	// Defaults to DefaultRenewBefore.
+	RenewBefore time.Duration
+	// QueueSize is the number of notifications buffered for Handler. When
+	// the queue is full the hub is asked to retry later.
+	// Defaults to DefaultQueueSize.
+	QueueSize int
+	// HTTPClient sends subscription requests. Defaults to a client with a
+	// 30 second timeout.
+	HTTPClient *http.Client
+	// Handler receives notifications while Run is running.
+	Handler Handler
+}
+
+// Subscriber manages WebSub subscriptions to YouTube channel feeds. It is
+// an http.Handler for the callback URL.
+type Subscriber struct {
+	callbackURL string
+	hubURL      string
+	secret      string
+	lease       time.Duration
+	renewBefore time.Duration
+	client      *http.Client
+	handler     Handler
+
+	queue chan *Notification
+	now   func() time.Time
+
+	mu   sync.Mutex
+	subs map[string]*Subscription // by topic
+}
+
+// NewSubscriber creates a Subscriber from cfg.
+func NewSubscriber(cfg Config) (*Subscriber, error) {
+	if cfg.CallbackURL == "" {
+		return nil, ErrNoCallback
+	}
+	s := &Subscriber{
+		callbackURL: cfg.CallbackURL,
+		hubURL:      cfg.HubURL,
+		secret:      cfg.Secret,
+		lease:       cfg.Lease,
+		renewBefore: cfg.RenewBefore,
+		client:      cfg.HTTPClient,
+		handler:     cfg.Handler,
+		now:         time.Now,
+		subs:        make(map[string]*Subscription),
+	}
+	if s.hubURL == "" {
+		s.hubURL = DefaultHubURL
+	}
+	if s.lease <= 0 {
+		s.lease = DefaultLease
+	}
+	if s.renewBefore <= 0 {
+		s.renewBefore = DefaultRenewBefore
+	}
+	if s.client == nil {
+		s.client = &http.Client{Timeout: 30 * time.Second}
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	s.queue = make(chan *Notification, queueSize)
+	return s, nil
+}
+
+// Subscribe asks the hub to push the channel's uploads to the callback
+// URL. The hub verifies the request asynchronously, so the subscription
+// is pending until the hub calls back; it is requested again if that does
+// not happen.
+func (s *Subscriber) Subscribe(ctx context.Context, channelID string) error {
+	topic := TopicURL(channelID)
+	s.mu.Lock()
+	sub, ok := s.subs[topic]
+	if !ok {
+		sub = &Subscription{ChannelID: channelID, Topic: topic}
+		s.subs[topic] = sub
+	}
+	if sub.State != StateActive {
+		sub.State = StatePending
+	}
+	sub.RequestedAt = s.now()
+	s.mu.Unlock()
+
+	err := s.request(ctx, "subscribe", topic)
+	s.mu.Lock()
+	if err != nil {
+		sub.Error = err.Error()
+	} else {
+		sub.Error = ""
+	}
+	s.mu.Unlock()
+	return err
+}
+
+// Unsubscribe asks the hub to stop pushing the channel's uploads. The
+// subscription is forgotten once the hub verifies the request.
+func (s *Subscriber) Unsubscribe(ctx context.Context, channelID string) error {
+	topic := TopicURL(channelID)
+	s.mu.Lock()
+	sub, ok := s.subs[topic]
+	if !ok {
+		s.mu.Unlock()
+		return nil
+	}
+	sub.State = StateUnsubscribing
+	sub.RequestedAt = s.now()
+	s.mu.Unlock()
+
+	return s.request(ctx, "unsubscribe", topic)
+}
+
+// Subscriptions returns the status of every subscription.
+func (s *Subscriber) Subscriptions() []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := make([]Subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, *sub)
+	}
+	return subs
+}
+
+// request sends a subscribe or unsubscribe request to the hub.
+func (s *Subscriber) request(ctx context.Context, mode, topic string) error {
+	form := url.Values{
+		"hub.callback":      {s.callbackURL},
+		"hub.mode":          {mode},
+		"hub.topic":         {topic},
+		"hub.verify":        {"async"},
+		"hub.lease_seconds": {strconv.Itoa(int(s.lease.Seconds()))},
+	}
+	if s.secret != "" {
+		form.Set("hub.secret", s.secret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.hubURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("websub: %s %s: %w", mode, topic, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("websub: %s %s: %w", mode, topic, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s %s: HTTP %d: %s", ErrHubRejected, mode, topic, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// ServeHTTP handles the hub's verification requests (GET) and
+// notifications (POST).
+func (s *Subscriber) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.verify(w, r)
+	case http.MethodPost:
+		s.receive(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// verify confirms a subscription or unsubscription the Subscriber asked
+// for by echoing the hub's challenge, and records a denial of a pending
+// subscription. Denials are unauthenticated, so one for an active
+// subscription is refused rather than letting anyone cancel it.
+func (s *Subscriber) verify(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	topic := q.Get("hub.topic")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.subs[topic]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch q.Get("hub.mode") {
+	case "subscribe":
+		if sub.State == StateUnsubscribing {
+			http.NotFound(w, r)
+			return
+		}
+		lease := s.lease
+		if seconds, err := strconv.Atoi(q.Get("hub.lease_seconds")); err == nil && seconds > 0 {
+			lease = time.Duration(seconds) * time.Second
+		}
+		sub.State = StateActive
+		sub.Expires = s.now().Add(lease)
+		sub.Error = ""
+	case "unsubscribe":
+		if sub.State != StateUnsubscribing {
+			http.NotFound(w, r)
+			return
+		}
+		delete(s.subs, topic)
+	case "denied":
+		if sub.State != StatePending {
+			http.NotFound(w, r)
+			return
+		}
+		sub.State = StateDenied
+		sub.Error = q.Get("hub.reason")
+		w.WriteHeader(http.StatusOK)
+		return
+	default:
+		http.Error(w, "unknown hub.mode", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, q.Get("hub.challenge"))
+}
+
+// receive parses a notification and queues it for the Handler.
+// Notifications with a bad signature are acknowledged but dropped, as the
+// WebSub spec requires, so a forger cannot tell whether they were accepted.
+func (s *Subscriber) receive(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxNotificationSize))
+	if err != nil {
+		http.Error(w, "read body", http.StatusBadRequest)
+		return
+	}
+	if s.secret != "" && !validSignature(s.secret, r.Header.Get("X-Hub-Signature"), body) {
+		log.Printf("websub: dropped notification with invalid signature")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	notifications, err := parseNotifications(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, n := range notifications {
+		select {
+		case s.queue <- n:
+		default:
+			// Ask the hub to redeliver rather than lose the notification
+			http.Error(w, "notification queue full", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validSignature checks an X-Hub-Signature header of the form
+// "sha1=<hex HMAC of body>".
+func validSignature(secret, header string, body []byte) bool {
+	algo, sig, ok := strings.Cut(header, "=")
+	if !ok || algo != "sha1" {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// Run passes queued notifications to the Handler and renews leases that
+// are about to expire, until ctx is cancelled.
+func (s *Subscriber) Run(ctx context.Context) error {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n := <-s.queue:
+			if s.handler != nil {
+				s.handler(ctx, n)
+			}
+		case <-ticker.C:
+			s.renew(ctx)
+		}
+	}
+}
+
+// renew requests again every subscription whose lease is about to expire,
+// whose verification never arrived, or that was denied.
+func (s *Subscriber) renew(ctx context.Context) {
+	now := s.now()
+	var due []string
+	s.mu.Lock()
+	for _, sub := range s.subs {
+		switch sub.State {
+		case StateActive:
+			if now.Add(s.renewBefore).After(sub.Expires) {
+				due = append(due, sub.ChannelID)
+			}
+		case StatePending, StateDenied:
+			if now.Sub(sub.RequestedAt) >= verifyTimeout {
+				due = append(due, sub.ChannelID)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	for _, channelID := range due {
+		if err := s.Subscribe(ctx, channelID); err != nil {
+			log.Printf("websub: renew %s: %v", channelID, err)
+		}
+	}
+}
+
+// notificationFeed is the Atom document the hub pushes. Deletions use the
+// Atom tombstones extension.
+type notificationFeed struct {
+	Entries []struct {
+		VideoID   string `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+		ChannelID string `xml:"http://www.youtube.com/xml/schemas/2015 channelId"`
+		Title     string `xml:"title"`
+		Link      struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Author struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+		Published time.Time `xml:"published"`
+		Updated   time.Time `xml:"updated"`
+	} `xml:"entry"`
+	Deleted []struct {
+		Ref  string    `xml:"ref,attr"`
+		When time.Time `xml:"when,attr"`
+		By   struct {
+			URI string `xml:"uri"`
+		} `xml:"by"`
+	} `xml:"http://purl.org/atompub/tombstones/1.0 deleted-entry"`
+}
+
+// parseNotifications parses a pushed Atom feed.
+func parseNotifications(body []byte) ([]*Notification, error) {
+	var feed notificationFeed
+	if err := xml.NewDecoder(bytes.NewReader(body)).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("websub: parse notification: %w", err)
+	}
+
+	var notifications []*Notification
+	for _, e := range feed.Entries {
+		if e.VideoID == "" {
+			continue
+		}
+		notifications = append(notifications, &Notification{
+			VideoID:     e.VideoID,
+			ChannelID:   e.ChannelID,
+			ChannelName: e.Author.Name,
+			Title:       e.Title,
+			URL:         e.Link.Href,
+			Published:   e.Published,
+			Updated:     e.Updated,
+		})
+	}
+	for _, d := range feed.Deleted {
+		videoID := strings.TrimPrefix(d.Ref, "yt:video:")
+		if videoID == "" || videoID == d.Ref {
+			continue
+		}
+		channelID := ""
+		if i := strings.LastIndex(d.By.URI, "/channel/"); i >= 0 {
+			channelID = d.By.URI[i+len("/channel/"):]
+		}
+		notifications = append(notifications, &Notification{
+			VideoID:   videoID,
+			ChannelID: channelID,
+			Updated:   d.When,
+			Deleted:   true,
+		})
+	}
+	return notifications, nil
+}
+
+// EnrichHandler returns a Handler that fetches metadata and transcripts for
+// each notified video and persists it, as a channel sync does for newly
+// listed videos. Deletions are ignored.
+func EnrichHandler(opts *youtube.EnrichOptions) Handler {
+	return func(ctx context.Context, n *Notification) {
+		if n.Deleted {
+			return
+		}
+		results, err := youtube.Enrich(ctx, n.ChannelID, []youtube.VideoInfo{n.VideoInfo()}, opts)
+		if err != nil {
+			log.Printf("websub: enrich %s: %v", n.VideoID, err)
+			return
+		}
+		for _, r := range results {
+			for stage, err := range r.Errors {
+				log.Printf("websub: enrich %s: %s: %v", r.VideoID, stage, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package websub
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testChannelID = "UCsBjURrPoezykLs9EqgamOA"
+
+const testNotification = `<?xml version='1.0' encoding='UTF-8'?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <link rel="hub" href="https://pubsubhubbub.appspot.com"/>
+  <title>YouTube video feed</title>
+  <entry>
+    <id>yt:video:dQw4w9WgXcQ</id>
+    <yt:videoId>dQw4w9WgXcQ</yt:videoId>
+    <yt:channelId>UCsBjURrPoezykLs9EqgamOA</yt:channelId>
+    <title>New Upload</title>
+    <link rel="alternate" href="https://www.youtube.com/watch?v=dQw4w9WgXcQ"/>
+    <author>
+      <name>Fireship</name>
+      <uri>https://www.youtube.com/channel/UCsBjURrPoezykLs9EqgamOA</uri>
+    </author>
+    <published>2024-03-01T12:00:00+00:00</published>
+    <updated>2024-03-01T12:00:05+00:00</updated>
+  </entry>
+</feed>`
+
+const testDeletion = `<?xml version='1.0' encoding='UTF-8'?>
+<feed xmlns:at="http://purl.org/atompub/tombstones/1.0" xmlns="http://www.w3.org/2005/Atom">
+  <at:deleted-entry ref="yt:video:dQw4w9WgXcQ" when="2024-03-02T08:00:00+00:00">
+    <link href="https://www.youtube.com/watch?v=dQw4w9WgXcQ"/>
+    <at:by>
+      <name>Fireship</name>
+      <uri>https://www.youtube.com/channel/UCsBjURrPoezykLs9EqgamOA</uri>
+    </at:by>
+  </at:deleted-entry>
+</feed>`
+
+// fakeHub records subscription requests and, like the real hub, verifies
+// each one by calling the callback with a challenge.
+type fakeHub struct {
+	mu       sync.Mutex
+	requests []url.Values
+	verified []string // challenge responses from the callback
+	status   int
+	lease    string
+}
+
+func (h *fakeHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	h.mu.Lock()
+	h.requests = append(h.requests, r.PostForm)
+	status := h.status
+	h.mu.Unlock()
+	if status != 0 {
+		http.Error(w, "nope", status)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+
+	q := url.Values{
+		"hub.mode":      {r.PostForm.Get("hub.mode")},
+		"hub.topic":     {r.PostForm.Get("hub.topic")},
+		"hub.challenge": {"challenge-123"},
+	}
+	if h.lease != "" {
+		q.Set("hub.lease_seconds", h.lease)
+	}
+	go func() {
+		resp, err := http.Get(r.PostForm.Get("hub.callback") + "?" + q.Encode())
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+		buf := make([]byte, 64)
+		n, _ := resp.Body.Read(buf)
+		h.mu.Lock()
+		h.verified = append(h.verified, string(buf[:n]))
+		h.mu.Unlock()
+	}()
+}
+
+func newTestSubscriber(t *testing.T, cfg Config) (*Subscriber, *fakeHub) {
+	t.Helper()
+	hub := &fakeHub{}
+	hubServer := httptest.NewServer(hub)
+	t.Cleanup(hubServer.Close)
+
+	var sub *Subscriber
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sub.ServeHTTP(w, r)
+	}))
+	t.Cleanup(callback.Close)
+
+	cfg.CallbackURL = callback.URL
+	cfg.HubURL = hubServer.URL
+	sub, err := NewSubscriber(cfg)
+	if err != nil {
+		t.Fatalf("NewSubscriber() error = %v", err)
+	}
+	return sub, hub
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func subscriptionState(s *Subscriber) State {
+	subs := s.Subscriptions()
+	if len(subs) == 0 {
+		return ""
+	}
+	return subs[0].State
+}
+
+func TestNewSubscriberRequiresCallback(t *testing.T) {
+	if _, err := NewSubscriber(Config{}); !errors.Is(err, ErrNoCallback) {
+		t.Errorf("NewSubscriber() error = %v, want ErrNoCallback", err)
+	}
+}
+
+func TestSubscribeVerification(t *testing.T) {
+	sub, hub := newTestSubscriber(t, Config{Secret: "s3cret", Lease: time.Hour})
+	hub.lease = "600"
+
+	if err := sub.Subscribe(context.Background(), testChannelID); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	waitFor(t, "verification", func() bool { return subscriptionState(sub) == StateActive })
+
+	hub.mu.Lock()
+	form := hub.requests[0]
+	hub.mu.Unlock()
+	if form.Get("hub.mode") != "subscribe" || form.Get("hub.topic") != TopicURL(testChannelID) {
+		t.Errorf("request = %v", form)
+	}
+	if form.Get("hub.lease_seconds") != "3600" || form.Get("hub.secret") != "s3cret" {
+		t.Errorf("lease/secret = %q/%q", form.Get("hub.lease_seconds"), form.Get("hub.secret"))
+	}
+	waitFor(t, "challenge echo", func() bool {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		return len(hub.verified) == 1 && hub.verified[0] == "challenge-123"
+	})
+
+	// The hub's granted lease wins over the requested one
+	got := sub.Subscriptions()[0]
+	if remaining := time.Until(got.Expires); remaining > 11*time.Minute || remaining < 9*time.Minute {
+		t.Errorf("Expires in %v, want about 10m", remaining)
+	}
+}
+
+func TestVerifyUnknownTopic(t *testing.T) {
+	sub, _ := newTestSubscriber(t, Config{})
+	req := httptest.NewRequest(http.MethodGet, "/?hub.mode=subscribe&hub.challenge=x&hub.topic="+url.QueryEscape(TopicURL(testChannelID)), nil)
+	rec := httptest.NewRecorder()
+	sub.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 for a topic never requested", rec.Code)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	sub, _ := newTestSubscriber(t, Config{})
+	ctx := context.Background()
+	if err := sub.Subscribe(ctx, testChannelID); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	waitFor(t, "subscribe", func() bool { return subscriptionState(sub) == StateActive })
+
+	if err := sub.Unsubscribe(ctx, testChannelID); err != nil {
+		t.Fatalf("Unsubscribe() error = %v", err)
+	}
+	waitFor(t, "unsubscribe", func() bool { return len(sub.Subscriptions()) == 0 })
+}
+
+func TestSubscribeHubRejected(t *testing.T) {
+	sub, hub := newTestSubscriber(t, Config{})
+	hub.status = http.StatusBadRequest
+
+	err := sub.Subscribe(context.Background(), testChannelID)
+	if !errors.Is(err, ErrHubRejected) {
+		t.Fatalf("Subscribe() error = %v, want ErrHubRejected", err)
+	}
+	if got := sub.Subscriptions()[0]; got.State != StatePending || got.Error == "" {
+		t.Errorf("subscription = %+v, want pending with error", got)
+	}
+}
+
+func TestVerifyDenied(t *testing.T) {
+	sub, _ := newTestSubscriber(t, Config{})
+	sub.mu.Lock()
+	topic := TopicURL(testChannelID)
+	sub.subs[topic] = &Subscription{ChannelID: testChannelID, Topic: topic, State: StatePending}
+	sub.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/?hub.mode=denied&hub.reason=banned&hub.topic="+url.QueryEscape(topic), nil)
+	rec := httptest.NewRecorder()
+	sub.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+	if got := sub.Subscriptions()[0]; got.State != StateDenied || got.Error != "banned" {
+		t.Errorf("subscription = %+v, want denied: banned", got)
+	}
+}
+
+func TestVerifyDeniedActive(t *testing.T) {
+	sub, _ := newTestSubscriber(t, Config{})
+	sub.mu.Lock()
+	topic := TopicURL(testChannelID)
+	sub.subs[topic] = &Subscription{ChannelID: testChannelID, Topic: topic, State: StateActive}
+	sub.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/?hub.mode=denied&hub.reason=forged&hub.topic="+url.QueryEscape(topic), nil)
+	rec := httptest.NewRecorder()
+	sub.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if got := sub.Subscriptions()[0]; got.State != StateActive || got.Error != "" {
+		t.Errorf("subscription = %+v, want it still active", got)
+	}
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestReceiveNotification(t *testing.T) {
+	received := make(chan *Notification, 1)
+	sub, _ := newTestSubscriber(t, Config{
+		Secret:  "s3cret",
+		Handler: func(ctx context.Context, n *Notification) { received <- n },
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sub.Run(ctx)
+
+	// A forged notification is acknowledged but not delivered
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testNotification))
+	req.Header.Set("X-Hub-Signature", sign("wrong", testNotification))
+	rec := httptest.NewRecorder()
+	sub.ServeHTTP(rec, req)
+	if rec.Code/100 != 2 {
+		t.Errorf("forged status = %d, want 2xx", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testNotification))
+	req.Header.Set("X-Hub-Signature", sign("s3cret", testNotification))
+	rec = httptest.NewRecorder()
+	sub.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", rec.Code)
+	}
+
+	select {
+	case n := <-received:
+		if n.VideoID != "dQw4w9WgXcQ" || n.ChannelID != testChannelID || n.Title != "New Upload" || n.ChannelName != "Fireship" {
+			t.Errorf("notification = %+v", n)
+		}
+		if n.Published.IsZero() || n.Deleted {
+			t.Errorf("notification = %+v", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification not delivered")
+	}
+	select {
+	case n := <-received:
+		t.Errorf("forged notification delivered: %+v", n)
+	default:
+	}
+}
+
+func TestReceiveQueueFull(t *testing.T) {
+	sub, _ := newTestSubscriber(t, Config{QueueSize: 1})
+	post := func() int {
+		rec := httptest.NewRecorder()
+		sub.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testNotification)))
+		return rec.Code
+	}
+	if code := post(); code != http.StatusNoContent {
+		t.Fatalf("first status = %d, want 204", code)
+	}
+	if code := post(); code != http.StatusServiceUnavailable {
+		t.Errorf("second status = %d, want 503 so the hub retries", code)
+	}
+}
+
+func TestParseDeletion(t *testing.T) {
+	notifications, err := parseNotifications([]byte(testDeletion))
+	if err != nil {
+		t.Fatalf("parseNotifications() error = %v", err)
+	}
+	if len(notifications) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(notifications))
+	}
+	n := notifications[0]
+	if !n.Deleted || n.VideoID != "dQw4w9WgXcQ" || n.ChannelID != testChannelID || n.Updated.IsZero() {
+		t.Errorf("notification = %+v", n)
+	}
+}
+
+func TestRenew(t *testing.T) {
+	sub, hub := newTestSubscriber(t, Config{RenewBefore: time.Hour})
+	ctx := context.Background()
+	if err := sub.Subscribe(ctx, testChannelID); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	waitFor(t, "subscribe", func() bool { return subscriptionState(sub) == StateActive })
+	requests := func() int {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		return len(hub.requests)
+	}
+
+	sub.renew(ctx)
+	if got := requests(); got != 1 {
+		t.Fatalf("renewed a fresh lease: %d requests", got)
+	}
+
+	expires := sub.Subscriptions()[0].Expires
+	sub.now = func() time.Time { return expires.Add(-30 * time.Minute) }
+	sub.renew(ctx)
+	if got := requests(); got != 2 {
+		t.Errorf("got %d requests, want a renewal within RenewBefore of expiry", got)
+	}
+}
@@ -5,11 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"ytsync/errcode"
+	ythttp "ytsync/http"
 	"ytsync/retry"
+	"ytsync/storage"
+	"ytsync/tags"
 
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
 )
@@ -23,15 +30,30 @@ type APILister struct {
 
 	// Quota tracking
 	mu              sync.Mutex
-	estimatedQuota  int // Estimated remaining quota units
-	lastQuotaReset  time.Time
-	quotaExhausted  bool
+	quota           *QuotaTracker
+	keys            *APIKeyPool // if set, used instead of service and quota
 	fallbackLister  VideoLister // Fallback lister (e.g., yt-dlp)
 	RetryConfig     *retry.Config
+
+	// PrefetchPages is how many playlist pages to fetch ahead of the page
+	// being processed (0 = strictly sequential).
+	PrefetchPages int
+	// ConcurrentPages is the maximum number of pages fetched in parallel once
+	// the playlist size is known from the first page (0 or 1 = disabled).
+	// Parallel fetching relies on the undocumented page token format and
+	// falls back to sequential fetching if the API rejects a token.
+	ConcurrentPages int
+	// RateLimiter, if set, is waited on before every page request.
+	// Recommended when PrefetchPages or ConcurrentPages is used.
+	RateLimiter *ythttp.RateLimiter
+	// Aliases, if set, is consulted before resolving a handle or custom URL
+	// with a 100-unit search, and records each search result.
+	Aliases storage.ChannelAliasStore
 }
 
 // NewAPILister creates a new YouTube Data API v3-based video lister.
 // quotaReserve specifies the minimum quota units to keep in reserve (default 0).
+// Quota usage is tracked in memory; use SetQuotaTracker to persist it.
 func NewAPILister(apiKey string, quotaReserve int) (*APILister, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("api key required")
@@ -47,12 +69,32 @@ func NewAPILister(apiKey string, quotaReserve int) (*APILister, error) {
 		service:      service,
 		apiKey:       apiKey,
 		quotaReserve: quotaReserve,
-		estimatedQuota: 10000, // Default daily quota
-		lastQuotaReset: time.Now(),
-		RetryConfig:    &cfg,
+		quota:        NewQuotaTracker(nil, QuotaKey(apiKey), DefaultDailyQuota, quotaReserve),
+		RetryConfig:  &cfg,
+	}, nil
+}
+
+// NewAPIListerWithKeys creates a Data API lister that pools the quota of
+// several API keys, rotating to the next key when one runs out (see
+// APIKeyPool). quotaReserve applies to each key.
+func NewAPIListerWithKeys(apiKeys []string, quotaReserve int) (*APILister, error) {
+	pool, err := NewAPIKeyPool(apiKeys, quotaReserve)
+	if err != nil {
+		return nil, err
+	}
+	cfg := retry.DefaultConfig()
+	return &APILister{
+		quotaReserve: quotaReserve,
+		keys:         pool,
+		RetryConfig:  &cfg,
 	}, nil
 }
 
+// Keys returns the lister's key pool, or nil if it uses a single key.
+func (a *APILister) Keys() *APIKeyPool {
+	return a.keys
+}
+
 // SetFallbackLister sets the fallback lister to use when quota is exhausted.
 func (a *APILister) SetFallbackLister(lister VideoLister) {
 	a.mu.Lock()
@@ -60,6 +102,14 @@ func (a *APILister) SetFallbackLister(lister VideoLister) {
 	a.fallbackLister = lister
 }
 
+// SetQuotaTracker replaces the lister's in-memory quota accounting, typically
+// with a tracker backed by a shared storage.QuotaStore.
+func (a *APILister) SetQuotaTracker(q *QuotaTracker) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.quota = q
+}
+
 // ListVideos fetches videos from the specified channel using YouTube Data API v3.
 // It gracefully falls back to the fallback lister if quota is exhausted.
 //
@@ -68,26 +118,29 @@ func (a *APILister) SetFallbackLister(lister VideoLister) {
 //   - ResumePlaylistID: uploads playlist ID (skips lookup, saves quota)
 //   - OnProgress: callback for persisting pagination state
 func (a *APILister) ListVideos(ctx context.Context, channelURL string, opts *ListOptions) ([]VideoInfo, error) {
-	a.mu.Lock()
-	if a.quotaExhausted && a.fallbackLister != nil {
-		a.mu.Unlock()
-		log.Printf("youtube: API quota exhausted, falling back to %T", a.fallbackLister)
-		return a.fallbackLister.ListVideos(ctx, channelURL, opts)
+	_, fallback := a.tracker()
+	if fallback != nil && a.exhausted(ctx) {
+		tags.Logf(ctx, "youtube: API quota exhausted, falling back to %T", fallback)
+		return fallback.ListVideos(ctx, channelURL, opts)
 	}
-	a.mu.Unlock()
 
 	// Resolve channel ID
 	channelID, err := a.resolveChannelID(ctx, channelURL)
+	if errors.Is(err, ErrQuotaInsufficient) && fallback != nil {
+		tags.Logf(ctx, "youtube: %v, falling back to %T", err, fallback)
+		return fallback.ListVideos(ctx, channelURL, opts)
+	}
 	if err != nil {
 		return nil, &ListerError{Source: "api", Channel: channelURL, Err: err}
 	}
+	ctx = tags.Default(ctx, tags.Channel, channelID)
 
 	// Get uploads playlist ID (or use cached one for resume)
 	var uploadsPlaylistID, channelName string
 	if opts != nil && opts.ResumePlaylistID != "" {
 		// Resume from cached playlist ID (saves 1 quota unit)
 		uploadsPlaylistID = opts.ResumePlaylistID
-		log.Printf("youtube: resuming with cached playlist ID %s", uploadsPlaylistID)
+		tags.Logf(ctx, "youtube: resuming with cached playlist ID %s", uploadsPlaylistID)
 	} else {
 		uploadsPlaylistID, channelName, err = a.getUploadsPlaylistID(ctx, channelID)
 		if err != nil {
@@ -109,6 +162,118 @@ func (a *APILister) SupportsFullHistory() bool {
 	return true
 }
 
+// PaginationStrategy reports that API listings page with pageToken, which
+// SyncManager checkpoints so interrupted syncs can resume.
+func (a *APILister) PaginationStrategy() storage.PaginationStrategy {
+	return storage.StrategyAPI
+}
+
+// VideoCount returns the channel's video count from its statistics, which
+// costs one quota unit. When the quota is exhausted it returns
+// ErrQuotaInsufficient rather than falling back, since counting is only an
+// optimization.
+func (a *APILister) VideoCount(ctx context.Context, channelURL string) (int, error) {
+	if a.exhausted(ctx) {
+		return 0, &ListerError{Source: "api", Channel: channelURL, Err: ErrQuotaInsufficient}
+	}
+	channelID, err := a.resolveChannelID(ctx, channelURL)
+	if err != nil {
+		return 0, &ListerError{Source: "api", Channel: channelURL, Err: err}
+	}
+
+	cfg := a.RetryConfig
+	if cfg == nil {
+		defaultCfg := retry.DefaultConfig()
+		cfg = &defaultCfg
+	}
+
+	var count int
+	err = retry.Do(ctx, *cfg, apiErrorClassifier, func(ctx context.Context) error {
+		service, quota := a.client(ctx)
+		resp, err := service.Channels.List([]string{"statistics"}).
+			Id(channelID).
+			Context(ctx).
+			Do()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ErrNetworkTimeout
+			}
+			return a.classifyError(service, err)
+		}
+		a.recordQuota(ctx, quota, "channels.list")
+
+		if len(resp.Items) == 0 {
+			return ErrChannelNotFound
+		}
+		if resp.Items[0].Statistics == nil {
+			return ErrVideoCountUnavailable
+		}
+		count = int(resp.Items[0].Statistics.VideoCount)
+		return nil
+	})
+	if err != nil {
+		return 0, &ListerError{Source: "api", Channel: channelURL, Err: err}
+	}
+	return count, nil
+}
+
+// PublishDates returns the publish times of videoIDs from videos.list,
+// which costs one quota unit per 50 videos. Videos the API does not return
+// (deleted or private ones) are missing from the map. When the quota is
+// exhausted it returns ErrQuotaInsufficient with the dates fetched so far.
+func (a *APILister) PublishDates(ctx context.Context, videoIDs []string) (map[string]time.Time, error) {
+	cfg := a.RetryConfig
+	if cfg == nil {
+		defaultCfg := retry.DefaultConfig()
+		cfg = &defaultCfg
+	}
+
+	dates := make(map[string]time.Time, len(videoIDs))
+	for start := 0; start < len(videoIDs); start += apiPageSize {
+		if a.exhausted(ctx) {
+			return dates, ErrQuotaInsufficient
+		}
+		batch := videoIDs[start:min(start+apiPageSize, len(videoIDs))]
+		err := retry.Do(ctx, *cfg, apiErrorClassifier, func(ctx context.Context) error {
+			if a.RateLimiter != nil {
+				if err := a.RateLimiter.Wait(ctx, dataAPIVideosURL); err != nil {
+					return err
+				}
+			}
+			service, quota := a.client(ctx)
+			resp, err := service.Videos.List([]string{"snippet"}).
+				Id(batch...).
+				MaxResults(apiPageSize).
+				Context(ctx).
+				Do()
+			if err != nil {
+				if ctx.Err() != nil {
+					return ErrNetworkTimeout
+				}
+				return a.classifyError(service, err)
+			}
+			a.recordQuota(ctx, quota, "videos.list")
+
+			for _, item := range resp.Items {
+				if item.Snippet == nil {
+					continue
+				}
+				if t, err := time.Parse(time.RFC3339, item.Snippet.PublishedAt); err == nil {
+					dates[item.Id] = t
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return dates, err
+		}
+	}
+	return dates, nil
+}
+
+// dataAPIVideosURL is the URL used to key the rate limiter for videos.list.
+const dataAPIVideosURL = "https://www.googleapis.com/youtube/v3/videos"
+
 // resolveChannelID converts a channel URL, handle, or ID to a channel ID.
 func (a *APILister) resolveChannelID(ctx context.Context, input string) (string, error) {
 	// Check if it's already a channel ID
@@ -116,9 +281,17 @@ func (a *APILister) resolveChannelID(ctx context.Context, input string) (string,
 		return channelIDRegex.FindString(input), nil
 	}
 
+	if id, ok := lookupAlias(ctx, a.Aliases, input); ok {
+		return id, nil
+	}
+
 	// If it's a handle (@username), search for it
 	if strings.HasPrefix(input, "@") {
-		return a.searchChannelByHandle(ctx, input)
+		id, err := a.searchChannelByHandle(ctx, input)
+		if err == nil {
+			rememberAlias(ctx, a.Aliases, input, id)
+		}
+		return id, err
 	}
 
 	// If it contains /channel/ or /c/, try to resolve it
@@ -132,7 +305,11 @@ func (a *APILister) resolveChannelID(ctx context.Context, input string) (string,
 		parts := strings.Split(input, "youtube.com/c/")
 		if len(parts) > 1 {
 			customURL := strings.Split(parts[1], "/")[0]
-			return a.searchChannelByCustomURL(ctx, customURL)
+			id, err := a.searchChannelByCustomURL(ctx, customURL)
+			if err == nil {
+				rememberAlias(ctx, a.Aliases, input, id)
+			}
+			return id, err
 		}
 	}
 
@@ -159,6 +336,11 @@ func (a *APILister) searchChannelByHandle(ctx context.Context, handle string) (s
 	// Remove @ prefix if present
 	handle = strings.TrimPrefix(handle, "@")
 
+	_, quota := a.client(ctx)
+	if err := quota.PreflightCheck(ctx, QuotaCost("search.list")); err != nil {
+		return "", err
+	}
+
 	var channelID string
 	cfg := a.RetryConfig
 	if cfg == nil {
@@ -167,7 +349,8 @@ func (a *APILister) searchChannelByHandle(ctx context.Context, handle string) (s
 	}
 
 	err := retry.Do(ctx, *cfg, apiErrorClassifier, func(ctx context.Context) error {
-		call := a.service.Search.List([]string{"id"}).
+		service, quota := a.client(ctx)
+		call := service.Search.List([]string{"id"}).
 			Q(handle).
 			Type("channel").
 			MaxResults(1).
@@ -178,7 +361,7 @@ func (a *APILister) searchChannelByHandle(ctx context.Context, handle string) (s
 			if ctx.Err() != nil {
 				return ErrNetworkTimeout
 			}
-			return err
+			return a.classifyError(service, err)
 		}
 
 		if len(resp.Items) == 0 {
@@ -186,7 +369,7 @@ func (a *APILister) searchChannelByHandle(ctx context.Context, handle string) (s
 		}
 
 		channelID = resp.Items[0].Id.ChannelId
-		a.trackQuotaUsage(100) // Search uses 100 units
+		a.recordQuota(ctx, quota, "search.list")
 		return nil
 	})
 
@@ -199,6 +382,11 @@ func (a *APILister) searchChannelByHandle(ctx context.Context, handle string) (s
 
 // searchChannelByCustomURL searches for a channel by its custom URL.
 func (a *APILister) searchChannelByCustomURL(ctx context.Context, customURL string) (string, error) {
+	_, quota := a.client(ctx)
+	if err := quota.PreflightCheck(ctx, QuotaCost("search.list")); err != nil {
+		return "", err
+	}
+
 	var channelID string
 	cfg := a.RetryConfig
 	if cfg == nil {
@@ -207,7 +395,8 @@ func (a *APILister) searchChannelByCustomURL(ctx context.Context, customURL stri
 	}
 
 	err := retry.Do(ctx, *cfg, apiErrorClassifier, func(ctx context.Context) error {
-		call := a.service.Search.List([]string{"id"}).
+		service, quota := a.client(ctx)
+		call := service.Search.List([]string{"id"}).
 			Q(customURL).
 			Type("channel").
 			MaxResults(1).
@@ -218,7 +407,7 @@ func (a *APILister) searchChannelByCustomURL(ctx context.Context, customURL stri
 			if ctx.Err() != nil {
 				return ErrNetworkTimeout
 			}
-			return err
+			return a.classifyError(service, err)
 		}
 
 		if len(resp.Items) == 0 {
@@ -226,7 +415,7 @@ func (a *APILister) searchChannelByCustomURL(ctx context.Context, customURL stri
 		}
 
 		channelID = resp.Items[0].Id.ChannelId
-		a.trackQuotaUsage(100) // Search uses 100 units
+		a.recordQuota(ctx, quota, "search.list")
 		return nil
 	})
 
@@ -249,7 +438,8 @@ func (a *APILister) getUploadsPlaylistID(ctx context.Context, channelID string)
 	}
 
 	err := retry.Do(ctx, *cfg, apiErrorClassifier, func(ctx context.Context) error {
-		call := a.service.Channels.List([]string{"contentDetails", "snippet"}).
+		service, quota := a.client(ctx)
+		call := service.Channels.List([]string{"contentDetails", "snippet"}).
 			Id(channelID).
 			Context(ctx)
 
@@ -258,7 +448,7 @@ func (a *APILister) getUploadsPlaylistID(ctx context.Context, channelID string)
 			if ctx.Err() != nil {
 				return ErrNetworkTimeout
 			}
-			return err
+			return a.classifyError(service, err)
 		}
 
 		if len(resp.Items) == 0 {
@@ -271,7 +461,7 @@ func (a *APILister) getUploadsPlaylistID(ctx context.Context, channelID string)
 			channelName = channel.Snippet.Title
 		}
 
-		a.trackQuotaUsage(1) // channels.list uses 1 unit
+		a.recordQuota(ctx, quota, "channels.list")
 		return nil
 	})
 
@@ -286,7 +476,9 @@ func (a *APILister) getUploadsPlaylistID(ctx context.Context, channelID string)
 // Supports resuming from a saved pageToken and reports progress via callback.
 func (a *APILister) listPlaylistVideos(ctx context.Context, playlistID, channelID, channelName string, opts *ListOptions) ([]VideoInfo, error) {
 	var allVideos []VideoInfo
-	var quotaUsedThisSync int
+	// Pages may be fetched ahead of the loop below, so quota is counted
+	// as pages are fetched rather than as they are used
+	var pagesFetched atomic.Int64
 
 	cfg := a.RetryConfig
 	if cfg == nil {
@@ -298,9 +490,35 @@ func (a *APILister) listPlaylistVideos(ctx context.Context, playlistID, channelI
 	pageToken := ""
 	if opts != nil && opts.ResumeToken != "" {
 		pageToken = opts.ResumeToken
-		log.Printf("youtube: resuming pagination from token")
+		tags.Logf(ctx, "youtube: resuming pagination from token")
 	}
 
+	fetch := func(ctx context.Context, token string) (*playlistPage, error) {
+		page, err := a.fetchPlaylistPage(ctx, *cfg, playlistID, channelID, channelName, token)
+		if err == nil {
+			pagesFetched.Add(1)
+		}
+		return page, err
+	}
+	// Concurrent fetching needs item offsets, which are only known when
+	// starting from the beginning of the playlist
+	concurrent := a.ConcurrentPages > 1 && pageToken == ""
+	var pages pageIterator = &sequentialPages{ctx: ctx, fetch: fetch, token: pageToken}
+	if a.PrefetchPages > 0 && !concurrent {
+		// Stop fetching ahead at the page that ends the loop below
+		matched := 0
+		pages = newPrefetchedPages(ctx, fetch, pageToken, a.PrefetchPages, func(page *playlistPage) bool {
+			matched += len(matchingVideos(page.videos, opts))
+			if opts != nil && opts.MaxResults > 0 && matched >= opts.MaxResults {
+				return true
+			}
+			n := len(page.videos)
+			return n > 0 && opts.BeforeRange(page.videos[n-1])
+		})
+	}
+	defer func() { pages.close() }()
+	firstPage := true
+
 	for {
 		// Check if we should stop
 		if opts != nil && opts.MaxResults > 0 && len(allVideos) >= opts.MaxResults {
@@ -311,52 +529,7 @@ func (a *APILister) listPlaylistVideos(ctx context.Context, playlistID, channelI
 		var lastVideoID string
 
 		// Fetch a page of results
-		err := retry.Do(ctx, *cfg, apiErrorClassifier, func(ctx context.Context) error {
-			call := a.service.PlaylistItems.List([]string{"snippet", "contentDetails"}).
-				PlaylistId(playlistID).
-				MaxResults(50).
-				PageToken(pageToken).
-				Context(ctx)
-
-			resp, err := call.Do()
-			if err != nil {
-				if ctx.Err() != nil {
-					return ErrNetworkTimeout
-				}
-				return err
-			}
-
-			// Convert playlist items to VideoInfo
-			for _, item := range resp.Items {
-				video := VideoInfo{
-					ID:          item.ContentDetails.VideoId,
-					ChannelID:   channelID,
-					ChannelName: channelName,
-				}
-
-				if item.Snippet != nil {
-					video.Title = item.Snippet.Title
-					video.Description = item.Snippet.Description
-					if item.Snippet.Thumbnails != nil && item.Snippet.Thumbnails.Default != nil {
-						video.Thumbnail = item.Snippet.Thumbnails.Default.Url
-					}
-					// Parse RFC3339 published date
-					if t, err := time.Parse(time.RFC3339, item.Snippet.PublishedAt); err == nil {
-						video.Published = t
-					}
-				}
-
-				allVideos = append(allVideos, video)
-				lastVideoID = video.ID
-			}
-
-			pageToken = resp.NextPageToken
-			a.trackQuotaUsage(1) // playlistItems.list uses 1 unit per page
-			quotaUsedThisSync++
-
-			return nil
-		})
-
+		page, err := pages.next()
 		if err != nil {
 			// Report error via progress callback if available
 			if opts != nil && opts.OnProgress != nil {
@@ -365,7 +538,7 @@ func (a *APILister) listPlaylistVideos(ctx context.Context, playlistID, channelI
 					PlaylistID:      playlistID,
 					VideosRetrieved: len(allVideos),
 					LastVideoID:     lastVideoID,
-					QuotaUsed:       quotaUsedThisSync,
+					QuotaUsed:       int(pagesFetched.Load()),
 					Complete:        false,
 					Error:           err,
 				})
@@ -373,6 +546,33 @@ func (a *APILister) listPlaylistVideos(ctx context.Context, playlistID, channelI
 			return nil, err
 		}
 
+		// Filter per page so MaxResults counts matching videos
+		allVideos = append(allVideos, matchingVideos(page.videos, opts)...)
+		if n := len(page.videos); n > 0 {
+			lastVideoID = page.videos[n-1].ID
+		}
+		pageToken = page.nextToken
+
+		// Uploads are listed newest first, so once a page reaches videos
+		// older than the requested range no later page can match
+		if n := len(page.videos); n > 0 && opts.BeforeRange(page.videos[n-1]) {
+			pageToken = ""
+		}
+
+		// Once the total is known, fetch the remaining pages in parallel
+		if firstPage && concurrent && pageToken != "" {
+			maxResults := 0
+			if opts != nil && !opts.hasContentFilters() {
+				// With content filters, fewer videos than fetched will match
+				maxResults = opts.MaxResults
+			}
+			if offsets := plannedPageOffsets(page.total, maxResults); len(offsets) > 1 {
+				pages.close()
+				pages = newConcurrentPages(ctx, fetch, offsets, a.ConcurrentPages, pageToken)
+			}
+		}
+		firstPage = false
+
 		// Report progress via callback
 		if opts != nil && opts.OnProgress != nil {
 			progress := &PaginationProgress{
@@ -380,12 +580,12 @@ func (a *APILister) listPlaylistVideos(ctx context.Context, playlistID, channelI
 				PlaylistID:      playlistID,
 				VideosRetrieved: len(allVideos),
 				LastVideoID:     lastVideoID,
-				QuotaUsed:       quotaUsedThisSync,
+				QuotaUsed:       int(pagesFetched.Load()),
 				Complete:        pageToken == "",
 			}
 			if err := opts.OnProgress(progress); err != nil {
 				// Callback requested stop - return what we have
-				log.Printf("youtube: pagination stopped by callback: %v", err)
+				tags.Logf(ctx, "youtube: pagination stopped by callback: %v", err)
 				return allVideos, nil
 			}
 		}
@@ -396,10 +596,8 @@ func (a *APILister) listPlaylistVideos(ctx context.Context, playlistID, channelI
 		}
 
 		// Check quota and potentially fallback
-		a.mu.Lock()
-		if a.quotaExhausted && a.fallbackLister != nil {
-			a.mu.Unlock()
-			log.Printf("youtube: API quota exhausted during pagination, falling back to %T", a.fallbackLister)
+		if _, fallback := a.tracker(); fallback != nil && a.exhausted(ctx) {
+			tags.Logf(ctx, "youtube: API quota exhausted during pagination, falling back to %T", fallback)
 			// Fallback to alternate lister for remaining videos
 			remainingOpts := &ListOptions{}
 			if opts != nil {
@@ -411,14 +609,13 @@ func (a *APILister) listPlaylistVideos(ctx context.Context, playlistID, channelI
 			// Clear resume options for fallback
 			remainingOpts.ResumeToken = ""
 			remainingOpts.ResumePlaylistID = ""
-			fallbackVideos, err := a.fallbackLister.ListVideos(ctx, "https://www.youtube.com/channel/"+channelID, remainingOpts)
+			fallbackVideos, err := fallback.ListVideos(ctx, "https://www.youtube.com/channel/"+channelID, remainingOpts)
 			if err != nil {
 				return allVideos, nil // Return what we got
 			}
 			allVideos = append(allVideos, fallbackVideos...)
 			break
 		}
-		a.mu.Unlock()
 	}
 
 	// Apply filters
@@ -429,43 +626,121 @@ func (a *APILister) listPlaylistVideos(ctx context.Context, playlistID, channelI
 	return allVideos, nil
 }
 
-// trackQuotaUsage updates the estimated quota and checks if we've exhausted it.
-func (a *APILister) trackQuotaUsage(units int) {
+// tracker returns the quota tracker and fallback lister.
+func (a *APILister) tracker() (*QuotaTracker, VideoLister) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	return a.quota, a.fallbackLister
+}
+
+// client returns the service to call and the quota tracker to charge: the
+// current key's when the lister has a key pool.
+func (a *APILister) client(ctx context.Context) (*youtube.Service, *QuotaTracker) {
+	if a.keys != nil {
+		return a.keys.client(ctx)
+	}
+	quota, _ := a.tracker()
+	return a.service, quota
+}
 
-	// Reset quota if a day has passed
-	if time.Since(a.lastQuotaReset) > 24*time.Hour {
-		a.estimatedQuota = 10000
-		a.lastQuotaReset = time.Now()
-		a.quotaExhausted = false
-		log.Printf("youtube: quota reset (new day)")
+// exhausted reports whether no quota is left, on any key of a pool.
+func (a *APILister) exhausted(ctx context.Context) bool {
+	if a.keys != nil {
+		return a.keys.Exhausted(ctx)
 	}
+	quota, _ := a.tracker()
+	return quota.Exhausted(ctx)
+}
 
-	a.estimatedQuota -= units
+// classifyError classifies an error from a call made with service. A quota
+// error starts the cooldown of the pooled key that made the call, so a
+// retry uses the next key.
+func (a *APILister) classifyError(service *youtube.Service, err error) error {
+	err = classifyAPIError(err)
+	if a.keys != nil && errcode.Of(err) == errcode.QuotaExceeded {
+		a.keys.markExhausted(service)
+	}
+	return err
+}
+
+// trackQuotaUsage records the cost of one call to method and logs when the
+// quota falls below the reserve.
+func (a *APILister) trackQuotaUsage(ctx context.Context, method string) {
+	_, quota := a.client(ctx)
+	a.recordQuota(ctx, quota, method)
+}
+
+// recordQuota charges quota for one call to method.
+func (a *APILister) recordQuota(ctx context.Context, quota *QuotaTracker, method string) {
+	wasExhausted := quota.Exhausted(ctx)
+	remaining, err := quota.Record(ctx, method)
+	if err != nil {
+		tags.Logf(ctx, "youtube: %v", err)
+		return
+	}
 
-	if a.estimatedQuota < a.quotaReserve {
-		if !a.quotaExhausted {
-			log.Printf("youtube: quota exhausted (remaining: %d, reserve: %d)", a.estimatedQuota, a.quotaReserve)
-			a.quotaExhausted = true
+	if remaining < a.quotaReserve {
+		if !wasExhausted {
+			tags.Logf(ctx, "youtube: quota exhausted (remaining: %d, reserve: %d)", remaining, a.quotaReserve)
 		}
 	} else {
-		log.Printf("youtube: quota usage - remaining: %d units", a.estimatedQuota)
+		tags.Logf(ctx, "youtube: quota usage - remaining: %d units", remaining)
 	}
 }
 
-// GetEstimatedQuota returns the estimated remaining quota units.
+// GetEstimatedQuota returns the estimated remaining quota units for today.
 func (a *APILister) GetEstimatedQuota() int {
-	a.mu.Lock()
-	defer a.mu.Unlock()
-	return a.estimatedQuota
+	if a.keys != nil {
+		remaining, err := a.keys.remaining(context.Background())
+		if err != nil {
+			log.Printf("youtube: read quota usage: %v", err)
+		}
+		return remaining
+	}
+	quota, _ := a.tracker()
+	remaining, err := quota.Remaining(context.Background())
+	if err != nil {
+		log.Printf("youtube: read quota usage: %v", err)
+		return quota.Limit()
+	}
+	return remaining
 }
 
 // GetQuotaExhausted returns whether the quota has been exhausted.
 func (a *APILister) GetQuotaExhausted() bool {
-	a.mu.Lock()
-	defer a.mu.Unlock()
-	return a.quotaExhausted
+	return a.exhausted(context.Background())
+}
+
+// classifyAPIError attaches an error code to a Data API error based on its
+// HTTP status and reason. The original message is preserved.
+func classifyAPIError(err error) error {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	for _, item := range apiErr.Errors {
+		switch item.Reason {
+		case "quotaExceeded", "dailyLimitExceeded":
+			return errcode.Wrap(errcode.QuotaExceeded, "", err)
+		case "rateLimitExceeded", "userRateLimitExceeded":
+			return errcode.Wrap(errcode.RateLimited, "", err)
+		}
+	}
+
+	switch {
+	case apiErr.Code == http.StatusNotFound:
+		return errcode.Wrap(errcode.NotFound, "", err)
+	case apiErr.Code == http.StatusTooManyRequests:
+		return errcode.Wrap(errcode.RateLimited, "", err)
+	case apiErr.Code == http.StatusBadRequest:
+		return errcode.Wrap(errcode.InvalidInput, "", err)
+	case apiErr.Code >= 500:
+		return errcode.Wrap(errcode.Unavailable, "", err)
+	case strings.Contains(apiErr.Message, "quota"):
+		return errcode.Wrap(errcode.QuotaExceeded, "", err)
+	}
+	return err
 }
 
 // apiErrorClassifier determines if an API error is retryable.
@@ -476,7 +751,7 @@ func apiErrorClassifier(err error) bool {
 
 	// Don't retry specific sentinel errors
 	switch err {
-	case ErrChannelNotFound, ErrInvalidURL:
+	case ErrChannelNotFound, ErrInvalidURL, ErrVideoCountUnavailable:
 		return false
 	}
 
@@ -0,0 +1,201 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"ytsync/storage"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtube/v3"
+)
+
+// DefaultKeyCooldown is how long an API key that the Data API rejected for
+// exceeding its quota is skipped before it is tried again.
+const DefaultKeyCooldown = time.Hour
+
+// APIKeyPool pools the Data API quota of several API keys, typically from
+// different Google Cloud projects. Keys are used in the order given: the
+// first key with quota left serves every request until its tracked usage
+// reaches the reserve or the API reports quotaExceeded for it, then the
+// next key takes over. A key the API rejected is skipped for Cooldown even
+// if its tracked usage says it has quota left.
+//
+// Each key's usage is tracked separately, under its QuotaKey.
+type APIKeyPool struct {
+	// Cooldown is how long a key rejected for quota is skipped (default
+	// DefaultKeyCooldown).
+	Cooldown time.Duration
+
+	reserve int
+	keys    []*pooledKey
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// pooledKey is one key of an APIKeyPool.
+type pooledKey struct {
+	id        string // QuotaKey of the key
+	service   *youtube.Service
+	quota     *QuotaTracker
+	coolUntil time.Time
+}
+
+// KeyUsage is the quota state of one key in an APIKeyPool.
+type KeyUsage struct {
+	// Key identifies the key as QuotaKey does; the key itself is never
+	// exposed.
+	Key string
+	// Used and Remaining are today's tracked usage and remaining quota.
+	Used      int
+	Remaining int
+	// CoolingDownUntil is when a key rejected for quota may be tried
+	// again, or zero.
+	CoolingDownUntil time.Time
+}
+
+// NewAPIKeyPool creates a pool of the given API keys, each with its own
+// in-memory quota tracker keeping quotaReserve units in reserve. Duplicate
+// keys are ignored. Use SetQuotaStore to persist usage.
+func NewAPIKeyPool(apiKeys []string, quotaReserve int) (*APIKeyPool, error) {
+	return newAPIKeyPool(apiKeys, quotaReserve, func(apiKey string) (*youtube.Service, error) {
+		return youtube.NewService(context.Background(), option.WithAPIKey(apiKey))
+	})
+}
+
+func newAPIKeyPool(apiKeys []string, quotaReserve int, newService func(apiKey string) (*youtube.Service, error)) (*APIKeyPool, error) {
+	p := &APIKeyPool{Cooldown: DefaultKeyCooldown, reserve: quotaReserve, now: time.Now}
+	seen := make(map[string]bool)
+	for _, apiKey := range apiKeys {
+		if apiKey == "" || seen[apiKey] {
+			continue
+		}
+		seen[apiKey] = true
+		service, err := newService(apiKey)
+		if err != nil {
+			return nil, fmt.Errorf("create youtube service: %w", err)
+		}
+		id := QuotaKey(apiKey)
+		p.keys = append(p.keys, &pooledKey{
+			id:      id,
+			service: service,
+			quota:   NewQuotaTracker(nil, id, DefaultDailyQuota, quotaReserve),
+		})
+	}
+	if len(p.keys) == 0 {
+		return nil, fmt.Errorf("api key required")
+	}
+	return p, nil
+}
+
+// Len returns the number of keys in the pool.
+func (p *APIKeyPool) Len() int {
+	return len(p.keys)
+}
+
+// SetQuotaStore persists every key's usage in store, shared with other
+// processes using the same store and keys.
+func (p *APIKeyPool) SetQuotaStore(store storage.QuotaStore) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, k := range p.keys {
+		k.quota = NewQuotaTracker(store, k.id, DefaultDailyQuota, p.reserve)
+	}
+}
+
+// current returns a copy of the first key that is not cooling down and has
+// quota left, or false if there is none.
+func (p *APIKeyPool) current(ctx context.Context) (pooledKey, bool) {
+	p.mu.Lock()
+	keys := make([]pooledKey, 0, len(p.keys))
+	now := p.now()
+	for _, k := range p.keys {
+		if !now.Before(k.coolUntil) {
+			keys = append(keys, *k)
+		}
+	}
+	p.mu.Unlock()
+
+	// Tracked usage may live in a store, so read it without holding p.mu
+	for _, k := range keys {
+		if !k.quota.Exhausted(ctx) {
+			return k, true
+		}
+	}
+	return pooledKey{}, false
+}
+
+// client returns the service and quota tracker of the current key. If every
+// key is exhausted, the first is returned so the API can have the last word.
+func (p *APIKeyPool) client(ctx context.Context) (*youtube.Service, *QuotaTracker) {
+	if k, ok := p.current(ctx); ok {
+		return k.service, k.quota
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.keys[0].service, p.keys[0].quota
+}
+
+// Exhausted reports whether no key has quota left.
+func (p *APIKeyPool) Exhausted(ctx context.Context) bool {
+	_, ok := p.current(ctx)
+	return !ok
+}
+
+// markExhausted starts the cooldown of the key owning service after the API
+// rejected it for quota.
+func (p *APIKeyPool) markExhausted(service *youtube.Service) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cooldown := p.Cooldown
+	if cooldown <= 0 {
+		cooldown = DefaultKeyCooldown
+	}
+	for i, k := range p.keys {
+		if k.service == service {
+			k.coolUntil = p.now().Add(cooldown)
+			log.Printf("youtube: API key %d of %d (%s) exceeded its quota, skipping it for %v", i+1, len(p.keys), k.id, cooldown)
+			return
+		}
+	}
+}
+
+// Usage returns the quota state of every key, in pool order.
+func (p *APIKeyPool) Usage(ctx context.Context) ([]KeyUsage, error) {
+	p.mu.Lock()
+	keys := make([]pooledKey, len(p.keys))
+	for i, k := range p.keys {
+		keys[i] = *k
+	}
+	p.mu.Unlock()
+
+	usage := make([]KeyUsage, len(keys))
+	for i, k := range keys {
+		u, err := k.quota.Usage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		usage[i] = KeyUsage{Key: k.id, Used: u.Used, Remaining: k.quota.Limit() - u.Used, CoolingDownUntil: k.coolUntil}
+	}
+	return usage, nil
+}
+
+// remaining returns the quota left across keys that are not cooling down.
+func (p *APIKeyPool) remaining(ctx context.Context) (int, error) {
+	usage, err := p.Usage(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := p.now()
+	total := 0
+	for _, u := range usage {
+		if now.Before(u.CoolingDownUntil) {
+			continue
+		}
+		total += max(u.Remaining, 0)
+	}
+	return total, nil
+}
@@ -0,0 +1,156 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"ytsync/retry"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtube/v3"
+)
+
+// keyTransport adds the API key to requests, which option.WithAPIKey does
+// not do for a custom HTTP client.
+type keyTransport struct {
+	key  string
+	base http.RoundTripper
+}
+
+func (t *keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	q := req.URL.Query()
+	q.Set("key", t.key)
+	req.URL.RawQuery = q.Encode()
+	return t.base.RoundTrip(req)
+}
+
+// newFakeKeyPool creates a pool whose services all call server.
+func newFakeKeyPool(t *testing.T, server *httptest.Server, keys ...string) *APIKeyPool {
+	t.Helper()
+	pool, err := newAPIKeyPool(keys, 0, func(apiKey string) (*youtube.Service, error) {
+		client := &http.Client{Transport: &keyTransport{key: apiKey, base: server.Client().Transport}}
+		return youtube.NewService(context.Background(),
+			option.WithEndpoint(server.URL+"/"),
+			option.WithHTTPClient(client),
+		)
+	})
+	if err != nil {
+		t.Fatalf("newAPIKeyPool() error = %v", err)
+	}
+	return pool
+}
+
+func TestAPIKeyPool_RotatesOnQuotaExceeded(t *testing.T) {
+	var keysUsed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		keysUsed = append(keysUsed, key)
+		if key == "first" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"code":403,"message":"quota","errors":[{"reason":"quotaExceeded"}]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"items":[{"contentDetails":{"videoId":"vid1"}}]}`)
+	}))
+	defer server.Close()
+
+	pool := newFakeKeyPool(t, server, "first", "second")
+	cfg := retry.Config{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2}
+	lister := &APILister{keys: pool, RetryConfig: &cfg}
+
+	videos, err := lister.listPlaylistVideos(context.Background(), "UUtest", "UCtest", "Test", nil)
+	if err != nil {
+		t.Fatalf("listPlaylistVideos() error = %v", err)
+	}
+	if len(videos) != 1 || fmt.Sprint(keysUsed) != "[first second]" {
+		t.Errorf("got %d videos using keys %v, want 1 video using [first second]", len(videos), keysUsed)
+	}
+
+	usage, err := pool.Usage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage[0].Key != QuotaKey("first") || usage[0].CoolingDownUntil.IsZero() || usage[0].Used != 0 {
+		t.Errorf("first key usage = %+v, want cooling down with nothing charged", usage[0])
+	}
+	if usage[1].Used != QuotaCost("playlistItems.list") || !usage[1].CoolingDownUntil.IsZero() {
+		t.Errorf("second key usage = %+v, want one call charged", usage[1])
+	}
+}
+
+func TestAPIKeyPool_OrderAndCooldown(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	ctx := context.Background()
+
+	pool := newFakeKeyPool(t, server, "a", "b", "a", "c")
+	if pool.Len() != 3 {
+		t.Fatalf("Len() = %d, want duplicates dropped", pool.Len())
+	}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	pool.now = func() time.Time { return now }
+	current := func() string {
+		k, ok := pool.current(ctx)
+		if !ok {
+			return ""
+		}
+		return k.id
+	}
+
+	if got := current(); got != QuotaKey("a") {
+		t.Errorf("current = %s, want the first key", got)
+	}
+
+	// Tracked usage exhausts a key until the quota resets
+	pool.keys[0].quota.Spend(ctx, "search.list", DefaultDailyQuota)
+	if got := current(); got != QuotaKey("b") {
+		t.Errorf("current = %s, want the second key", got)
+	}
+
+	// An API rejection skips a key for the cooldown only
+	pool.markExhausted(pool.keys[1].service)
+	if got := current(); got != QuotaKey("c") {
+		t.Errorf("current = %s, want the third key", got)
+	}
+	pool.markExhausted(pool.keys[2].service)
+	if !pool.Exhausted(ctx) {
+		t.Error("Exhausted() = false with every key used up")
+	}
+	now = now.Add(DefaultKeyCooldown)
+	if got := current(); got != QuotaKey("b") {
+		t.Errorf("current after cooldown = %s, want the second key again", got)
+	}
+}
+
+func TestAPIListerWithKeys_Fallback(t *testing.T) {
+	lister, err := NewAPIListerWithKeys([]string{"k1", "k2"}, 0)
+	if err != nil {
+		t.Fatalf("NewAPIListerWithKeys() error = %v", err)
+	}
+	if got := lister.GetEstimatedQuota(); got != 2*DefaultDailyQuota {
+		t.Errorf("GetEstimatedQuota() = %d, want the pooled quota", got)
+	}
+
+	ctx := context.Background()
+	for _, k := range lister.Keys().keys {
+		k.quota.Spend(ctx, "search.list", DefaultDailyQuota)
+	}
+	if !lister.GetQuotaExhausted() {
+		t.Fatal("GetQuotaExhausted() = false with every key used up")
+	}
+
+	fallback := &MockVideoLister{videos: []VideoInfo{{ID: "fallback1"}}}
+	lister.SetFallbackLister(fallback)
+	videos, err := lister.ListVideos(ctx, "UCuAXFkgsw1L7xaCfnd5JJOw", &ListOptions{})
+	if err != nil || len(videos) != 1 || videos[0].ID != "fallback1" {
+		t.Errorf("ListVideos() = %v, %v; want the fallback's videos", videos, err)
+	}
+
+	if _, err := NewAPIListerWithKeys([]string{"", ""}, 0); err == nil {
+		t.Error("NewAPIListerWithKeys(no keys) succeeded")
+	}
+}
@@ -0,0 +1,299 @@
+package youtube
+
+import (
+	"context"
+	"encoding/base64"
+	"sync"
+	"time"
+	"ytsync/retry"
+	"ytsync/tags"
+)
+
+// apiPageSize is the maximum page size for playlistItems.list.
+const apiPageSize = 50
+
+// playlistPage is one page of playlistItems.list results.
+type playlistPage struct {
+	videos    []VideoInfo
+	nextToken string
+	total     int
+}
+
+// pageIterator yields playlist pages in order.
+type pageIterator interface {
+	// next returns the next page. The iterator must not be used after an error.
+	next() (*playlistPage, error)
+	// close stops any background fetches.
+	close()
+}
+
+// fetchPlaylistPage fetches a single page of the playlist, with retries.
+// It waits on the lister's rate limiter (if any) before each attempt.
+func (a *APILister) fetchPlaylistPage(ctx context.Context, cfg retry.Config, playlistID, channelID, channelName, pageToken string) (*playlistPage, error) {
+	var page *playlistPage
+
+	err := retry.Do(ctx, cfg, apiErrorClassifier, func(ctx context.Context) error {
+		if a.RateLimiter != nil {
+			if err := a.RateLimiter.Wait(ctx, dataAPIPlaylistItemsURL); err != nil {
+				return err
+			}
+		}
+
+		service, quota := a.client(ctx)
+		call := service.PlaylistItems.List([]string{"snippet", "contentDetails"}).
+			PlaylistId(playlistID).
+			MaxResults(apiPageSize).
+			PageToken(pageToken).
+			Context(ctx)
+
+		resp, err := call.Do()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ErrNetworkTimeout
+			}
+			return a.classifyError(service, err)
+		}
+
+		page = &playlistPage{nextToken: resp.NextPageToken}
+		if resp.PageInfo != nil {
+			page.total = int(resp.PageInfo.TotalResults)
+		}
+
+		// Convert playlist items to VideoInfo
+		for _, item := range resp.Items {
+			video := VideoInfo{
+				ID:          item.ContentDetails.VideoId,
+				ChannelID:   channelID,
+				ChannelName: channelName,
+			}
+
+			if item.Snippet != nil {
+				video.Title = item.Snippet.Title
+				video.Description = item.Snippet.Description
+				if item.Snippet.Thumbnails != nil && item.Snippet.Thumbnails.Default != nil {
+					video.Thumbnail = item.Snippet.Thumbnails.Default.Url
+				}
+				// Parse RFC3339 published date
+				if t, err := time.Parse(time.RFC3339, item.Snippet.PublishedAt); err == nil {
+					video.Published = t
+				}
+			}
+
+			page.videos = append(page.videos, video)
+		}
+
+		a.recordQuota(ctx, quota, "playlistItems.list")
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// dataAPIPlaylistItemsURL is the URL used to key the rate limiter for page fetches.
+const dataAPIPlaylistItemsURL = "https://www.googleapis.com/youtube/v3/playlistItems"
+
+// pageFetcher fetches the page at pageToken.
+type pageFetcher func(ctx context.Context, pageToken string) (*playlistPage, error)
+
+// sequentialPages fetches each page on demand, following next-page tokens.
+type sequentialPages struct {
+	ctx   context.Context
+	fetch pageFetcher
+	token string
+}
+
+func (s *sequentialPages) next() (*playlistPage, error) {
+	page, err := s.fetch(s.ctx, s.token)
+	if err != nil {
+		return nil, err
+	}
+	s.token = page.nextToken
+	return page, nil
+}
+
+func (s *sequentialPages) close() {}
+
+// prefetchedPages fetches pages in a background goroutine, staying up to
+// depth pages ahead of the consumer. It stops after the page for which last
+// reports true, so pages the consumer would discard cost no quota.
+type prefetchedPages struct {
+	results chan pageResult
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+type pageResult struct {
+	page *playlistPage
+	err  error
+}
+
+func newPrefetchedPages(ctx context.Context, fetch pageFetcher, startToken string, depth int, last func(*playlistPage) bool) *prefetchedPages {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &prefetchedPages{
+		results: make(chan pageResult, depth),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(p.done)
+		defer close(p.results)
+		token := startToken
+		for {
+			page, err := fetch(ctx, token)
+			select {
+			case p.results <- pageResult{page, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil || page.nextToken == "" || last(page) {
+				return
+			}
+			token = page.nextToken
+		}
+	}()
+
+	return p
+}
+
+func (p *prefetchedPages) next() (*playlistPage, error) {
+	r, ok := <-p.results
+	if !ok {
+		return &playlistPage{}, nil
+	}
+	return r.page, r.err
+}
+
+func (p *prefetchedPages) close() {
+	p.cancel()
+	<-p.done
+}
+
+// concurrentPages fetches a known range of pages in parallel using
+// synthesized page tokens, then yields them in order. If any synthesized
+// page fails, it falls back to sequential fetching from the last real
+// next-page token it delivered.
+type concurrentPages struct {
+	ctx     context.Context
+	fetch   pageFetcher
+	results []chan pageResult
+	index   int
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	lastToken string
+	fallback  *sequentialPages
+}
+
+// newConcurrentPages starts fetching pages for the given item offsets with
+// at most workers requests in flight. firstNextToken is the real token
+// returned by the page preceding offsets[0].
+func newConcurrentPages(ctx context.Context, fetch pageFetcher, offsets []int, workers int, firstNextToken string) *concurrentPages {
+	workCtx, cancel := context.WithCancel(ctx)
+	c := &concurrentPages{
+		ctx:       ctx,
+		fetch:     fetch,
+		results:   make([]chan pageResult, len(offsets)),
+		cancel:    cancel,
+		lastToken: firstNextToken,
+	}
+
+	sem := make(chan struct{}, workers)
+	for i, offset := range offsets {
+		c.results[i] = make(chan pageResult, 1)
+		token := firstNextToken
+		if i > 0 {
+			token = playlistPageToken(offset)
+		}
+		c.wg.Add(1)
+		go func(ch chan pageResult, token string) {
+			defer c.wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-workCtx.Done():
+				ch <- pageResult{err: workCtx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+			page, err := fetch(workCtx, token)
+			ch <- pageResult{page, err}
+		}(c.results[i], token)
+	}
+
+	return c
+}
+
+func (c *concurrentPages) next() (*playlistPage, error) {
+	if c.fallback != nil {
+		return c.fallback.next()
+	}
+	if c.index >= len(c.results) {
+		// All planned pages delivered; continue with any remaining real tokens
+		c.fallback = &sequentialPages{ctx: c.ctx, fetch: c.fetch, token: c.lastToken}
+		if c.lastToken == "" {
+			return &playlistPage{}, nil
+		}
+		return c.fallback.next()
+	}
+
+	r := <-c.results[c.index]
+	if r.err != nil {
+		if c.index == 0 || c.ctx.Err() != nil {
+			// The first page used a real token, so the error is genuine
+			return nil, r.err
+		}
+		tags.Logf(c.ctx, "youtube: concurrent page fetch failed (%v), continuing sequentially", r.err)
+		c.cancel()
+		c.fallback = &sequentialPages{ctx: c.ctx, fetch: c.fetch, token: c.lastToken}
+		return c.fallback.next()
+	}
+
+	c.index++
+	c.lastToken = r.page.nextToken
+	return r.page, nil
+}
+
+func (c *concurrentPages) close() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+// playlistPageToken synthesizes a Data API page token for the given item
+// offset. The format is undocumented: base64 of a protobuf message whose
+// field 3 is "PT:" followed by base64 of a message holding the offset.
+// Callers must handle the API rejecting the token.
+func playlistPageToken(offset int) string {
+	inner := append([]byte{0x08}, protoVarint(uint64(offset))...)
+	marker := "PT:" + base64.RawStdEncoding.EncodeToString(inner)
+
+	outer := []byte{0x10, 0x00, 0x1a}
+	outer = append(outer, protoVarint(uint64(len(marker)))...)
+	outer = append(outer, marker...)
+	return base64.RawStdEncoding.EncodeToString(outer)
+}
+
+// protoVarint encodes v as a protobuf base-128 varint.
+func protoVarint(v uint64) []byte {
+	var b []byte
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// plannedPageOffsets returns the item offsets of the pages after the first,
+// given the total playlist size and an optional result limit.
+func plannedPageOffsets(total, maxResults int) []int {
+	if maxResults > 0 && maxResults < total {
+		total = maxResults
+	}
+	var offsets []int
+	for offset := apiPageSize; offset < total; offset += apiPageSize {
+		offsets = append(offsets, offset)
+	}
+	return offsets
+}
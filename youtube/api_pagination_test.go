@@ -0,0 +1,263 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"ytsync/retry"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtube/v3"
+)
+
+// fakePlaylistServer serves playlistItems.list for a playlist of total items.
+// If acceptSynthesized is false, only tokens the server issued are accepted.
+type fakePlaylistServer struct {
+	total             int
+	acceptSynthesized bool
+
+	mu       sync.Mutex
+	issued   map[string]int
+	requests int
+}
+
+func (f *fakePlaylistServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests++
+	token := r.URL.Query().Get("pageToken")
+	offset, ok := 0, token == ""
+	if !ok {
+		offset, ok = f.issued[token]
+	}
+	if !ok && f.acceptSynthesized {
+		for o := 0; o < f.total; o += apiPageSize {
+			if playlistPageToken(o) == token {
+				offset, ok = o, true
+			}
+		}
+	}
+	f.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"code":400,"message":"invalidPageToken"}}`)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"pageInfo": map[string]int{"totalResults": f.total, "resultsPerPage": apiPageSize},
+	}
+	var items []map[string]interface{}
+	for i := offset; i < offset+apiPageSize && i < f.total; i++ {
+		items = append(items, map[string]interface{}{
+			"contentDetails": map[string]string{"videoId": fmt.Sprintf("vid%03d", i)},
+			"snippet": map[string]string{
+				"title":       fmt.Sprintf("Video %d", i),
+				"publishedAt": fakePlaylistPublished(i).Format(time.RFC3339),
+			},
+		})
+	}
+	resp["items"] = items
+	if next := offset + apiPageSize; next < f.total {
+		nextToken := fmt.Sprintf("real-%d", next)
+		f.mu.Lock()
+		f.issued[nextToken] = next
+		f.mu.Unlock()
+		resp["nextPageToken"] = nextToken
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// fakePlaylistPublished is the publish time of item i; items are newest first,
+// one day apart.
+func fakePlaylistPublished(i int) time.Time {
+	return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -i)
+}
+
+func newFakeAPILister(t *testing.T, server *httptest.Server) *APILister {
+	t.Helper()
+	service, err := youtube.NewService(context.Background(),
+		option.WithAPIKey("test-key"),
+		option.WithEndpoint(server.URL+"/"),
+		option.WithHTTPClient(server.Client()),
+	)
+	if err != nil {
+		t.Fatalf("NewService() error = %v", err)
+	}
+	cfg := retry.Config{MaxRetries: 0, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2}
+	return &APILister{
+		service:     service,
+		quota:       NewQuotaTracker(nil, "test", 0, 0),
+		RetryConfig: &cfg,
+	}
+}
+
+func TestAPILister_PaginationModes(t *testing.T) {
+	tests := []struct {
+		name              string
+		prefetch          int
+		concurrent        int
+		acceptSynthesized bool
+		maxResults        int
+		wantCount         int
+		slowConsumer      bool // give prefetching time to run ahead
+		wantRequests      int  // pages fetched, if checked
+	}{
+		{name: "sequential", wantCount: 237},
+		{name: "prefetch", prefetch: 2, wantCount: 237, wantRequests: 5},
+		{name: "prefetch with max results", prefetch: 3, maxResults: 60, slowConsumer: true, wantCount: 60, wantRequests: 2},
+		{name: "concurrent", concurrent: 3, acceptSynthesized: true, wantCount: 237},
+		{name: "concurrent falls back on rejected tokens", concurrent: 3, wantCount: 237},
+		{name: "concurrent with max results", concurrent: 3, acceptSynthesized: true, maxResults: 120, wantCount: 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakePlaylistServer{total: 237, acceptSynthesized: tt.acceptSynthesized, issued: map[string]int{}}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			lister := newFakeAPILister(t, server)
+			lister.PrefetchPages = tt.prefetch
+			lister.ConcurrentPages = tt.concurrent
+
+			var progressCalls, quotaUsed int
+			opts := &ListOptions{
+				MaxResults: tt.maxResults,
+				OnProgress: func(p *PaginationProgress) error {
+					progressCalls++
+					quotaUsed = p.QuotaUsed
+					if tt.slowConsumer {
+						time.Sleep(50 * time.Millisecond)
+					}
+					return nil
+				},
+			}
+			videos, err := lister.listPlaylistVideos(context.Background(), "UUtest", "UCtest", "Test", opts)
+			if err != nil {
+				t.Fatalf("listPlaylistVideos() error = %v", err)
+			}
+			if len(videos) != tt.wantCount {
+				t.Fatalf("listPlaylistVideos() len = %d, want %d", len(videos), tt.wantCount)
+			}
+			for i, v := range videos {
+				if want := fmt.Sprintf("vid%03d", i); v.ID != want {
+					t.Fatalf("videos[%d].ID = %q, want %q", i, v.ID, want)
+				}
+			}
+			if progressCalls == 0 {
+				t.Error("OnProgress was not called")
+			}
+			if tt.wantRequests > 0 {
+				fake.mu.Lock()
+				requests := fake.requests
+				fake.mu.Unlock()
+				if requests != tt.wantRequests || quotaUsed != tt.wantRequests {
+					t.Errorf("fetched %d pages, reported QuotaUsed %d, want %d", requests, quotaUsed, tt.wantRequests)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaylistPageToken(t *testing.T) {
+	tests := []struct {
+		offset int
+		want   string
+	}{
+		// Known tokens returned by the Data API
+		{50, "EAAaBlBUOkNESQ"},
+		{100, "EAAaBlBUOkNHUQ"},
+	}
+	for _, tt := range tests {
+		if got := playlistPageToken(tt.offset); got != tt.want {
+			t.Errorf("playlistPageToken(%d) = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestPlannedPageOffsets(t *testing.T) {
+	if got := plannedPageOffsets(237, 0); len(got) != 4 || got[0] != 50 || got[3] != 200 {
+		t.Errorf("plannedPageOffsets(237, 0) = %v, want [50 100 150 200]", got)
+	}
+	if got := plannedPageOffsets(237, 120); len(got) != 2 {
+		t.Errorf("plannedPageOffsets(237, 120) = %v, want [50 100]", got)
+	}
+	if got := plannedPageOffsets(30, 0); len(got) != 0 {
+		t.Errorf("plannedPageOffsets(30, 0) = %v, want []", got)
+	}
+}
+
+func TestAPILister_DateRangeStopsEarly(t *testing.T) {
+	fake := &fakePlaylistServer{total: 237, issued: map[string]int{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	lister := newFakeAPILister(t, server)
+	opts := &ListOptions{
+		PublishedAfter:  fakePlaylistPublished(61),
+		PublishedBefore: fakePlaylistPublished(10),
+	}
+	videos, err := lister.listPlaylistVideos(context.Background(), "UUtest", "UCtest", "Test", opts)
+	if err != nil {
+		t.Fatalf("listPlaylistVideos() error = %v", err)
+	}
+
+	if len(videos) != 50 || videos[0].ID != "vid011" || videos[len(videos)-1].ID != "vid060" {
+		t.Errorf("listPlaylistVideos() = %d videos, want vid011..vid060", len(videos))
+	}
+	if fake.requests != 2 {
+		t.Errorf("requests = %d, want 2 (pagination should stop once past PublishedAfter)", fake.requests)
+	}
+}
+
+func TestAPILister_PublishDates(t *testing.T) {
+	var requested [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/youtube/v3/videos" {
+			t.Errorf("request path = %s, want videos.list", r.URL.Path)
+		}
+		ids := strings.Split(strings.Join(r.URL.Query()["id"], ","), ",")
+		requested = append(requested, ids)
+		var items []map[string]interface{}
+		for _, id := range ids {
+			if id == "deleted" {
+				continue
+			}
+			items = append(items, map[string]interface{}{
+				"id":      id,
+				"snippet": map[string]string{"publishedAt": "2020-02-03T04:05:06Z"},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	defer server.Close()
+	lister := newFakeAPILister(t, server)
+
+	ids := []string{"deleted"}
+	for i := 0; i < apiPageSize; i++ {
+		ids = append(ids, fmt.Sprintf("vid%03d", i))
+	}
+	dates, err := lister.PublishDates(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("PublishDates() error = %v", err)
+	}
+	if len(requested) != 2 || len(requested[0]) != apiPageSize || len(requested[1]) != 1 {
+		t.Errorf("requested %d batches, want %d videos and then 1", len(requested), apiPageSize)
+	}
+	if _, ok := dates["deleted"]; ok || len(dates) != apiPageSize {
+		t.Errorf("PublishDates() returned %d dates, want %d without the deleted video", len(dates), apiPageSize)
+	}
+	if want := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC); !dates["vid000"].Equal(want) {
+		t.Errorf("dates[vid000] = %v, want %v", dates["vid000"], want)
+	}
+}
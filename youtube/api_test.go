@@ -5,6 +5,9 @@ import (
 	"errors"
 	"testing"
 	"time"
+	"ytsync/errcode"
+
+	"google.golang.org/api/googleapi"
 )
 
 // MockVideoLister is a mock implementation for testing fallback behavior.
@@ -119,7 +122,8 @@ func TestAPIListerQuotaTracking(t *testing.T) {
 	}
 
 	// Track some usage
-	lister.trackQuotaUsage(1000)
+	ctx := context.Background()
+	lister.quota.Spend(ctx, "search.list", 1000)
 	if quota := lister.GetEstimatedQuota(); quota != 9000 {
 		t.Errorf("after 1000 units usage, quota = %d, want 9000", quota)
 	}
@@ -130,7 +134,7 @@ func TestAPIListerQuotaTracking(t *testing.T) {
 	}
 
 	// Track usage to reach reserve threshold
-	lister.trackQuotaUsage(8200)
+	lister.quota.Spend(ctx, "search.list", 8200)
 	if quota := lister.GetEstimatedQuota(); quota != 800 {
 		t.Errorf("after 8200 units usage, quota = %d, want 800", quota)
 	}
@@ -147,19 +151,20 @@ func TestAPIListerQuotaReset(t *testing.T) {
 		t.Fatalf("NewAPILister() failed: %v", err)
 	}
 
+	// Quota resets at midnight Pacific time
+	now := time.Date(2024, 6, 1, 23, 30, 0, 0, pacific)
+	lister.quota.now = func() time.Time { return now }
+
 	// Exhaust quota by using more than available
-	lister.trackQuotaUsage(11000)
+	ctx := context.Background()
+	lister.quota.Spend(ctx, "search.list", 11000)
 	if !lister.GetQuotaExhausted() {
 		t.Error("quota should be exhausted after using more than 10000 units")
 	}
 
-	// Manually set reset time to past to simulate day change
-	lister.mu.Lock()
-	lister.lastQuotaReset = time.Now().Add(-25 * time.Hour)
-	lister.mu.Unlock()
-
-	// Track minimal usage to trigger reset
-	lister.trackQuotaUsage(1)
+	// Simulate the day change
+	now = now.Add(time.Hour)
+	lister.trackQuotaUsage(ctx, "playlistItems.list")
 
 	// Quota should be reset
 	if lister.GetQuotaExhausted() {
@@ -185,9 +190,7 @@ func TestAPIListerFallback(t *testing.T) {
 	lister.SetFallbackLister(mockFallback)
 
 	// Exhaust quota
-	lister.mu.Lock()
-	lister.quotaExhausted = true
-	lister.mu.Unlock()
+	lister.quota.Spend(context.Background(), "search.list", DefaultDailyQuota)
 
 	// ListVideos should use fallback
 	_, err = lister.ListVideos(context.Background(), "UCtest", &ListOptions{})
@@ -337,3 +340,26 @@ func TestListOptionsOnProgressStop(t *testing.T) {
 		}
 	}
 }
+
+func TestClassifyAPIError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want errcode.Code
+	}{
+		{"quota", &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "quotaExceeded"}}}, errcode.QuotaExceeded},
+		{"rate limit", &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}}, errcode.RateLimited},
+		{"not found", &googleapi.Error{Code: 404}, errcode.NotFound},
+		{"backend", &googleapi.Error{Code: 503}, errcode.Unavailable},
+		{"other", errors.New("boom"), errcode.Unknown},
+	}
+	for _, tt := range tests {
+		err := classifyAPIError(tt.err)
+		if got := errcode.Of(err); got != tt.want {
+			t.Errorf("%s: errcode.Of() = %q, want %q", tt.name, got, tt.want)
+		}
+		if err.Error() != tt.err.Error() {
+			t.Errorf("%s: message changed to %q", tt.name, err.Error())
+		}
+	}
+}
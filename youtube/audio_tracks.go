@@ -0,0 +1,140 @@
+package youtube
+
+import (
+	"sort"
+	"strings"
+)
+
+// AudioTrack is one of the audio tracks of a video. Videos with dubbed
+// versions have one per language, with the original language marked.
+type AudioTrack struct {
+	// Language is the track's language code, e.g. "en-US" or "de".
+	Language string `json:"language"`
+	// Name is YouTube's name for the track, e.g. "German (Germany)" or
+	// "English (United States) original". Empty for single-track videos.
+	Name string `json:"name,omitempty"`
+	// Default reports whether YouTube plays the track by default.
+	Default bool `json:"default,omitempty"`
+	// Original reports whether the track is in the video's original
+	// language rather than dubbed.
+	Original bool `json:"original,omitempty"`
+	// FormatIDs are the formats carrying the track.
+	FormatIDs []string `json:"format_ids,omitempty"`
+}
+
+// OriginalAudio is the AudioLanguage that picks a video's original-language
+// audio track.
+const OriginalAudio = "original"
+
+// AudioTracks groups the formats that carry audio by their track. Tracks
+// are ordered original first, then the default, then by language. It
+// returns nil if the formats report no audio languages. A single track is
+// marked both default and original.
+func AudioTracks(formats []MediaFormat) []AudioTrack {
+	var tracks []AudioTrack
+	index := make(map[string]int)
+	for i := range formats {
+		f := &formats[i]
+		if !f.HasAudio() || f.Language == "" {
+			continue
+		}
+		key := strings.ToLower(f.Language) + "\x00" + f.AudioTrack
+		j, ok := index[key]
+		if !ok {
+			j = len(tracks)
+			index[key] = j
+			tracks = append(tracks, AudioTrack{Language: f.Language, Name: f.AudioTrack})
+		}
+		t := &tracks[j]
+		t.Default = t.Default || f.DefaultAudio
+		t.Original = t.Original || f.OriginalAudio
+		t.FormatIDs = append(t.FormatIDs, f.ID)
+	}
+	if len(tracks) == 1 {
+		tracks[0].Default, tracks[0].Original = true, true
+	}
+	sort.SliceStable(tracks, func(i, j int) bool {
+		a, b := tracks[i], tracks[j]
+		if a.Original != b.Original {
+			return a.Original
+		}
+		if a.Default != b.Default {
+			return a.Default
+		}
+		return a.Language < b.Language
+	})
+	return tracks
+}
+
+// FindAudioTrack returns the track in tracks matching language, or nil if
+// there is none. language is OriginalAudio or a language code; "en"
+// matches "en" and regional variants such as "en-US", preferring the
+// original and default tracks as AudioTracks orders them.
+func FindAudioTrack(tracks []AudioTrack, language string) *AudioTrack {
+	if strings.EqualFold(language, OriginalAudio) {
+		for i := range tracks {
+			if tracks[i].Original {
+				return &tracks[i]
+			}
+		}
+		return nil
+	}
+	for i := range tracks {
+		if strings.EqualFold(tracks[i].Language, language) {
+			return &tracks[i]
+		}
+	}
+	for i := range tracks {
+		primary, _, _ := strings.Cut(tracks[i].Language, "-")
+		if strings.EqualFold(primary, language) {
+			return &tracks[i]
+		}
+	}
+	return nil
+}
+
+// PreferAudioLanguage rewrites a yt-dlp format specification to try audio
+// in language first: each alternative using bestaudio is preceded by a
+// copy restricted to that language, so the unrestricted alternatives
+// remain as fallbacks. language should be a track's Language.
+func PreferAudioLanguage(format, language string) string {
+	if language == "" || strings.ContainsAny(language, "[]/+,") {
+		return format
+	}
+	var out []string
+	for _, alt := range strings.Split(format, "/") {
+		if strings.Contains(alt, "bestaudio") && !strings.Contains(alt, "[language") {
+			out = append(out, strings.Replace(alt, "bestaudio", "bestaudio[language="+language+"]", 1))
+		}
+		out = append(out, alt)
+	}
+	return strings.Join(out, "/")
+}
+
+// formatsInLanguage returns the formats without audio and those whose
+// audio is in language, for choosing an audio track with a FormatSelector.
+func formatsInLanguage(formats []MediaFormat, language string) []MediaFormat {
+	var kept []MediaFormat
+	for _, f := range formats {
+		if !f.HasAudio() || strings.EqualFold(f.Language, language) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// parseAudioTrackNote splits the audio track from a yt-dlp format note
+// such as "English (United States) original (default), medium, DRC". It
+// returns the track name and whether the note marks it as the default.
+func parseAudioTrackNote(note string) (name string, isDefault bool) {
+	note = strings.TrimSuffix(note, ", DRC")
+	i := strings.LastIndex(note, ", ")
+	if i < 0 {
+		return "", false
+	}
+	name = note[:i]
+	if trimmed, ok := strings.CutSuffix(name, " (default)"); ok {
+		return trimmed, true
+	}
+	return name, false
+}
@@ -0,0 +1,78 @@
+package youtube
+
+import "testing"
+
+func TestAudioTracks(t *testing.T) {
+	data := []byte(`{"id":"abc","title":"T","formats":[
+		{"format_id":"140-0","ext":"m4a","vcodec":"none","acodec":"mp4a.40.2","format_note":"German (Germany), medium","language":"de-DE","language_preference":-1},
+		{"format_id":"140-1","ext":"m4a","vcodec":"none","acodec":"mp4a.40.2","format_note":"English (United States) original (default), medium","language":"en-US","language_preference":10},
+		{"format_id":"251-1","ext":"webm","vcodec":"none","acodec":"opus","format_note":"English (United States) original (default), medium, DRC","language":"en-US","language_preference":10},
+		{"format_id":"140-2","ext":"m4a","vcodec":"none","acodec":"mp4a.40.2","format_note":"Spanish (Spain), medium","language":"es-ES","language_preference":-1},
+		{"format_id":"137","ext":"mp4","vcodec":"avc1.640028","acodec":"none","height":1080}
+	]}`)
+	metadata, err := parseMetadata(data)
+	if err != nil {
+		t.Fatalf("parseMetadata() error = %v", err)
+	}
+	tracks := metadata.AudioTracks
+	if len(tracks) != 3 {
+		t.Fatalf("got %d tracks, want 3: %+v", len(tracks), tracks)
+	}
+	original := tracks[0]
+	if original.Language != "en-US" || original.Name != "English (United States) original" || !original.Default || !original.Original || len(original.FormatIDs) != 2 {
+		t.Errorf("original track = %+v", original)
+	}
+	if tracks[1].Language != "de-DE" || tracks[1].Name != "German (Germany)" || tracks[1].Default || tracks[1].Original {
+		t.Errorf("dubbed track = %+v", tracks[1])
+	}
+
+	for language, want := range map[string]string{
+		OriginalAudio: "en-US",
+		"es":          "es-ES",
+		"DE-de":       "de-DE",
+		"fr":          "",
+	} {
+		got := ""
+		if track := FindAudioTrack(tracks, language); track != nil {
+			got = track.Language
+		}
+		if got != want {
+			t.Errorf("FindAudioTrack(%q) = %q, want %q", language, got, want)
+		}
+	}
+
+	kept := formatsInLanguage(metadata.Formats, "de-DE")
+	if len(kept) != 2 || kept[0].ID != "140-0" || kept[1].ID != "137" {
+		t.Errorf("formatsInLanguage() = %+v", kept)
+	}
+}
+
+func TestAudioTracksSingle(t *testing.T) {
+	tracks := AudioTracks([]MediaFormat{
+		{ID: "140", AudioCodec: "mp4a.40.2", Note: "medium", Language: "en"},
+		{ID: "137", VideoCodec: "avc1.640028"},
+	})
+	if len(tracks) != 1 || !tracks[0].Default || !tracks[0].Original || tracks[0].Name != "" {
+		t.Errorf("tracks = %+v, want one default original track", tracks)
+	}
+	if tracks := AudioTracks([]MediaFormat{{ID: "140", AudioCodec: "mp4a.40.2"}}); tracks != nil {
+		t.Errorf("tracks without languages = %+v, want nil", tracks)
+	}
+}
+
+func TestPreferAudioLanguage(t *testing.T) {
+	tests := []struct {
+		format, language, want string
+	}{
+		{"bestaudio/best", "en-US", "bestaudio[language=en-US]/bestaudio/best"},
+		{"bestvideo[height<=1080]+bestaudio/best", "de", "bestvideo[height<=1080]+bestaudio[language=de]/bestvideo[height<=1080]+bestaudio/best"},
+		{"137+140", "de", "137+140"},
+		{"bestaudio/best", "", "bestaudio/best"},
+		{"bestaudio", "en]/worst", "bestaudio"},
+	}
+	for _, tt := range tests {
+		if got := PreferAudioLanguage(tt.format, tt.language); got != tt.want {
+			t.Errorf("PreferAudioLanguage(%q, %q) = %q, want %q", tt.format, tt.language, got, tt.want)
+		}
+	}
+}
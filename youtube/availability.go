@@ -0,0 +1,61 @@
+package youtube
+
+import "time"
+
+// AvailabilityState summarizes whether and how a video can be watched.
+type AvailabilityState string
+
+const (
+	// AvailabilityPublic means the video is publicly playable.
+	AvailabilityPublic AvailabilityState = "public"
+	// AvailabilityUnlisted means the video is playable by anyone with the link.
+	AvailabilityUnlisted AvailabilityState = "unlisted"
+	// AvailabilityPrivate means the uploader made the video private.
+	AvailabilityPrivate AvailabilityState = "private"
+	// AvailabilityDeleted means the video was removed or never existed.
+	AvailabilityDeleted AvailabilityState = "deleted"
+	// AvailabilityAgeRestricted means the video requires a signed-in adult account.
+	AvailabilityAgeRestricted AvailabilityState = "age_restricted"
+	// AvailabilityMembersOnly means the video requires a channel membership.
+	AvailabilityMembersOnly AvailabilityState = "members_only"
+	// AvailabilityRegionBlocked means the video is not available in the requesting region.
+	AvailabilityRegionBlocked AvailabilityState = "region_blocked"
+	// AvailabilityLive means the video is a stream that is live now.
+	AvailabilityLive AvailabilityState = "live"
+	// AvailabilityUpcoming means the video is a scheduled stream or premiere.
+	AvailabilityUpcoming AvailabilityState = "upcoming"
+	// AvailabilityUnknown means the status could not be classified.
+	AvailabilityUnknown AvailabilityState = "unknown"
+)
+
+// VideoAvailability is the result of an availability probe. The
+// innertube package's Client.Availability produces it.
+type VideoAvailability struct {
+	// VideoID is the YouTube video ID.
+	VideoID string `json:"video_id"`
+	// State is the classified availability.
+	State AvailabilityState `json:"state"`
+	// Playable reports whether YouTube would play the video for an anonymous viewer.
+	Playable bool `json:"playable"`
+	// Unlisted reports whether the video is unlisted (only known for playable videos).
+	Unlisted bool `json:"unlisted,omitempty"`
+	// ScheduledStart is the scheduled start time of an upcoming stream or premiere.
+	ScheduledStart time.Time `json:"scheduled_start,omitempty"`
+	// AvailableCountries lists the ISO country codes the video is available in, if restricted.
+	AvailableCountries []string `json:"available_countries,omitempty"`
+	// Status is the raw playability status reported by YouTube (e.g. "OK", "LOGIN_REQUIRED").
+	Status string `json:"status"`
+	// Reason is YouTube's human-readable explanation when the video is not playable.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Available reports whether the video exists and can be watched without
+// special access, now or (for upcoming streams) once it starts.
+func (a *VideoAvailability) Available() bool {
+	switch a.State {
+	case AvailabilityPublic, AvailabilityUnlisted, AvailabilityLive, AvailabilityUpcoming:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,141 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"ytsync/storage"
+)
+
+// DefaultBackfillBatchSize is the number of videos BackfillPublished looks
+// up at a time: one videos.list call of the Data API.
+const DefaultBackfillBatchSize = 50
+
+// PublishDateFetcher returns the publish times of a batch of videos, keyed
+// by YouTube ID. Videos it found no date for are left out of the map. It
+// may return the dates it found along with an error for the rest.
+type PublishDateFetcher func(ctx context.Context, videoIDs []string) (map[string]time.Time, error)
+
+// BackfillOptions configures BackfillPublished.
+type BackfillOptions struct {
+	// Store holds the videos to repair.
+	Store storage.Store
+	// Fetch looks up the publish dates, such as APILister.PublishDates or
+	// innertube's Client.PublishDates.
+	Fetch PublishDateFetcher
+	// ChannelIDs limits the backfill to the stored channels with these
+	// YouTube IDs. Empty checks every stored channel.
+	ChannelIDs []string
+	// BatchSize is the number of videos passed to Fetch at a time.
+	// Defaults to DefaultBackfillBatchSize.
+	BatchSize int
+	// OnProgress, if set, is called after each batch.
+	OnProgress func(BackfillProgress)
+}
+
+// BackfillProgress reports how far a BackfillPublished run has come.
+type BackfillProgress struct {
+	// Done is the number of videos looked up so far, of Total.
+	Done  int
+	Total int
+	// Updated is the number of videos given a publish date so far.
+	Updated int
+}
+
+// BackfillResult is the outcome of a BackfillPublished run.
+type BackfillResult struct {
+	// Scanned is the number of stored videos checked.
+	Scanned int
+	// Missing is the number of them without a publish date.
+	Missing int
+	// Updated is the number of videos given a publish date.
+	Updated int
+	// Unresolved lists the YouTube IDs of videos no date was found for,
+	// typically because they were deleted or made private.
+	Unresolved []string
+	// Errors maps the YouTube ID of each video that could not be looked up
+	// or saved to its error.
+	Errors map[string]error
+}
+
+// BackfillPublished finds the stored videos with a zero PublishedAt, looks
+// up their publish dates in batches, and saves them. Stores that implement
+// storage.Batcher save once per batch. Per-video failures are reported in
+// the result; the error is non-nil only if the videos could not be listed
+// or ctx is done.
+func BackfillPublished(ctx context.Context, opts *BackfillOptions) (*BackfillResult, error) {
+	if opts == nil || opts.Store == nil || opts.Fetch == nil {
+		return nil, fmt.Errorf("backfill published: store and fetcher are required")
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBackfillBatchSize
+	}
+
+	result := &BackfillResult{Errors: make(map[string]error)}
+	var missing []*storage.Video
+	err := eachStoredVideo(ctx, opts.Store, opts.ChannelIDs, func(video *storage.Video) {
+		result.Scanned++
+		if video.PublishedAt.IsZero() {
+			missing = append(missing, video)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Missing = len(missing)
+
+	for start := 0; start < len(missing); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		batch := missing[start:min(start+batchSize, len(missing))]
+		if err := backfillBatch(ctx, opts, batch, result); err != nil {
+			return result, err
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(BackfillProgress{Done: start + len(batch), Total: len(missing), Updated: result.Updated})
+		}
+	}
+	return result, nil
+}
+
+// backfillBatch looks up and saves the publish dates of batch.
+func backfillBatch(ctx context.Context, opts *BackfillOptions, batch []*storage.Video, result *BackfillResult) error {
+	ids := make([]string, len(batch))
+	for i, video := range batch {
+		ids[i] = video.YouTubeID
+	}
+	dates, fetchErr := opts.Fetch(ctx, ids)
+
+	save := func(store storage.Store) error {
+		for _, video := range batch {
+			published, ok := dates[video.YouTubeID]
+			switch {
+			case ok && !published.IsZero():
+			case fetchErr != nil:
+				result.Errors[video.YouTubeID] = fetchErr
+				continue
+			default:
+				result.Unresolved = append(result.Unresolved, video.YouTubeID)
+				continue
+			}
+			updated := *video
+			updated.PublishedAt = published
+			if err := store.UpdateVideo(ctx, &updated); err != nil {
+				result.Errors[video.YouTubeID] = err
+				continue
+			}
+			result.Updated++
+		}
+		return nil
+	}
+	if b, ok := opts.Store.(storage.Batcher); ok {
+		if err := b.Batch(ctx, save); err != nil {
+			return fmt.Errorf("save publish dates: %w", err)
+		}
+	} else {
+		save(opts.Store)
+	}
+	return ctx.Err()
+}
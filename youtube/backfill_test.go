@@ -0,0 +1,87 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+	"ytsync/storage"
+)
+
+func TestBackfillPublished(t *testing.T) {
+	store := newEnrichTestStore(t)
+	ctx := context.Background()
+
+	channel := &storage.Channel{YouTubeID: "UCtest", Name: "Test"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatal(err)
+	}
+	dated := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, v := range []struct {
+		id        string
+		published time.Time
+	}{{"dated", dated}, {"missing1", time.Time{}}, {"missing2", time.Time{}}, {"deleted", time.Time{}}, {"flaky", time.Time{}}} {
+		if err := store.CreateVideo(ctx, &storage.Video{YouTubeID: v.id, ChannelID: channel.ID, PublishedAt: v.published}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found := time.Date(2019, 5, 6, 7, 8, 9, 0, time.UTC)
+	var batches [][]string
+	var progress []BackfillProgress
+	result, err := BackfillPublished(ctx, &BackfillOptions{
+		Store: store,
+		Fetch: func(ctx context.Context, ids []string) (map[string]time.Time, error) {
+			batches = append(batches, ids)
+			dates := make(map[string]time.Time)
+			for _, id := range ids {
+				if id == "missing1" || id == "missing2" {
+					dates[id] = found
+				}
+			}
+			if ids[0] == "flaky" {
+				return dates, ErrNetworkTimeout
+			}
+			return dates, nil
+		},
+		BatchSize:  3,
+		OnProgress: func(p BackfillProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatalf("BackfillPublished() error = %v", err)
+	}
+
+	if result.Scanned != 5 || result.Missing != 4 || result.Updated != 2 {
+		t.Errorf("result = %+v, want 5 scanned, 4 missing, 2 updated", result)
+	}
+	if !reflect.DeepEqual(result.Unresolved, []string{"deleted"}) {
+		t.Errorf("Unresolved = %q, want [deleted]", result.Unresolved)
+	}
+	if len(result.Errors) != 1 || !errors.Is(result.Errors["flaky"], ErrNetworkTimeout) {
+		t.Errorf("Errors = %v, want flaky failed", result.Errors)
+	}
+	if len(batches) != 2 || len(batches[0]) != 3 || len(batches[1]) != 1 {
+		t.Errorf("batches = %q, want 3 videos and then 1", batches)
+	}
+	if want := []BackfillProgress{{Done: 3, Total: 4, Updated: 2}, {Done: 4, Total: 4, Updated: 2}}; !reflect.DeepEqual(progress, want) {
+		t.Errorf("progress = %+v, want %+v", progress, want)
+	}
+
+	var still []string
+	videos, _ := store.ListVideosByChannel(ctx, channel.ID)
+	for _, v := range videos {
+		switch {
+		case v.PublishedAt.IsZero():
+			still = append(still, v.YouTubeID)
+		case v.YouTubeID == "dated" && !v.PublishedAt.Equal(dated),
+			v.YouTubeID != "dated" && !v.PublishedAt.Equal(found):
+			t.Errorf("%s PublishedAt = %v", v.YouTubeID, v.PublishedAt)
+		}
+	}
+	sort.Strings(still)
+	if !reflect.DeepEqual(still, []string{"deleted", "flaky"}) {
+		t.Errorf("videos still without a date = %q, want [deleted flaky]", still)
+	}
+}
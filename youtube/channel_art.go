@@ -0,0 +1,132 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	ythttp "ytsync/http"
+	"ytsync/storage"
+)
+
+// maxChannelArtSize bounds the images ChannelArtFetcher downloads.
+const maxChannelArtSize = 10 << 20
+
+// ChannelArtStore is where ChannelArtFetcher.Sync records channel images.
+// *storage.JSONStore implements it.
+type ChannelArtStore interface {
+	// SetChannelArt records data, downloaded from url, as the channel's
+	// image of the given kind, and reports whether the URL or image changed.
+	SetChannelArt(ctx context.Context, channelID string, kind storage.ChannelArtKind, url, contentType string, data []byte) (bool, error)
+}
+
+// ChannelArtFetcher downloads channel avatars and banners.
+type ChannelArtFetcher struct {
+	// HTTPClient is the HTTP client to use for requests.
+	// If nil, a default client with 30-second timeout is used.
+	HTTPClient HTTPDoer
+	// RateLimiter, if set, paces image requests.
+	RateLimiter *ythttp.RateLimiter
+	// MaxAge downloads an image again once it is older than this even if
+	// its URL has not changed, so an image replaced at the same URL is
+	// noticed by its digest. Zero downloads only when the URL changes.
+	MaxAge time.Duration
+}
+
+// NewChannelArtFetcher creates a channel art fetcher.
+func NewChannelArtFetcher() *ChannelArtFetcher {
+	return &ChannelArtFetcher{}
+}
+
+// Fetch downloads the image at url and returns it with its content type.
+func (f *ChannelArtFetcher) Fetch(ctx context.Context, url string) ([]byte, string, error) {
+	client := f.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if err := f.RateLimiter.Wait(ctx, url); err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", channelPageHeaders["User-Agent"])
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, "", ErrNetworkTimeout
+		}
+		return nil, "", fmt.Errorf("fetch channel art: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, "", rateLimited(f.RateLimiter, url, resp)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetch channel art: HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.HasPrefix(contentType, "image/") {
+		return nil, "", fmt.Errorf("fetch channel art: %s is %s, not an image", url, contentType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxChannelArtSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("read response: %w", err)
+	}
+	if len(data) > maxChannelArtSize {
+		return nil, "", fmt.Errorf("fetch channel art: %s is larger than %d bytes", url, maxChannelArtSize)
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
+
+// Sync brings the avatar and banner of ch up to date with the URLs in info.
+// An image is downloaded when the channel has none, when its URL changed,
+// or when it is older than MaxAge, and is recorded in store under ch.ID.
+// Images info does not name are left as they are. It returns the kinds of
+// image that changed; an error for one image does not stop the other.
+func (f *ChannelArtFetcher) Sync(ctx context.Context, store ChannelArtStore, ch *storage.Channel, info *ChannelInfo) ([]storage.ChannelArtKind, error) {
+	var changed []storage.ChannelArtKind
+	var firstErr error
+	for _, image := range []struct {
+		kind storage.ChannelArtKind
+		url  string
+	}{
+		{storage.ChannelAvatar, info.AvatarURL},
+		{storage.ChannelBanner, info.BannerURL},
+	} {
+		if image.url == "" || !f.due(ch.Art(image.kind), image.url) {
+			continue
+		}
+		data, contentType, err := f.Fetch(ctx, image.url)
+		if err == nil {
+			var updated bool
+			updated, err = store.SetChannelArt(ctx, ch.ID, image.kind, image.url, contentType, data)
+			if updated {
+				changed = append(changed, image.kind)
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("channel %s: %w", image.kind, err)
+		}
+	}
+	return changed, firstErr
+}
+
+// due reports whether the image art, last fetched from its URL, should be
+// downloaded from url.
+func (f *ChannelArtFetcher) due(art *storage.ChannelArt, url string) bool {
+	if art == nil || art.URL != url {
+		return true
+	}
+	return f.MaxAge > 0 && time.Since(art.FetchedAt) > f.MaxAge
+}
@@ -0,0 +1,90 @@
+package youtube
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"ytsync/storage"
+)
+
+func TestChannelArtFetcher_Sync(t *testing.T) {
+	var hits atomic.Int32
+	images := map[string]string{"/avatar1": "avatar one", "/avatar2": "avatar one", "/banner": "banner"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		body, ok := images[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	store := newEnrichTestStore(t)
+	ctx := context.Background()
+	ch := &storage.Channel{YouTubeID: "UC123"}
+	if err := store.CreateChannel(ctx, ch); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+
+	fetcher := NewChannelArtFetcher()
+	info := &ChannelInfo{AvatarURL: server.URL + "/avatar1", BannerURL: server.URL + "/banner"}
+	changed, err := fetcher.Sync(ctx, store, ch, info)
+	if err != nil || len(changed) != 2 {
+		t.Fatalf("Sync() = %v, %v, want both images", changed, err)
+	}
+	if _, data, err := store.GetChannelArt(ctx, ch.ID, storage.ChannelBanner); err != nil || string(data) != "banner" {
+		t.Errorf("GetChannelArt(banner) = %q, %v", data, err)
+	}
+
+	// Nothing is downloaded while the URLs are unchanged
+	before := hits.Load()
+	if changed, err := fetcher.Sync(ctx, store, ch, info); err != nil || len(changed) != 0 {
+		t.Errorf("Sync() unchanged = %v, %v", changed, err)
+	}
+	if hits.Load() != before {
+		t.Errorf("Sync() with unchanged URLs made %d requests", hits.Load()-before)
+	}
+
+	// A new URL is downloaded and recorded even when the image is the same
+	info.AvatarURL = server.URL + "/avatar2"
+	if changed, err := fetcher.Sync(ctx, store, ch, info); err != nil || len(changed) != 1 || changed[0] != storage.ChannelAvatar {
+		t.Errorf("Sync() new avatar URL = %v, %v, want avatar", changed, err)
+	}
+	if ch.Avatar.URL != info.AvatarURL {
+		t.Errorf("Avatar.URL = %q, want %q", ch.Avatar.URL, info.AvatarURL)
+	}
+
+	// Past MaxAge the image is downloaded again and replaced if it changed
+	fetcher.MaxAge = time.Nanosecond
+	images["/banner"] = "new banner"
+	if changed, err := fetcher.Sync(ctx, store, ch, info); err != nil || len(changed) != 1 || changed[0] != storage.ChannelBanner {
+		t.Errorf("Sync() after MaxAge = %v, %v, want banner", changed, err)
+	}
+
+	// A failed download leaves the stored image in place
+	info.BannerURL = server.URL + "/missing"
+	if _, err := fetcher.Sync(ctx, store, ch, info); err == nil {
+		t.Error("Sync() with a missing banner succeeded")
+	}
+	if _, data, err := store.GetChannelArt(ctx, ch.ID, storage.ChannelBanner); err != nil || string(data) != "new banner" {
+		t.Errorf("GetChannelArt(banner) after a failed download = %q, %v", data, err)
+	}
+}
+
+func TestChannelArtFetcher_RejectsNonImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>consent</html>"))
+	}))
+	defer server.Close()
+
+	if _, _, err := NewChannelArtFetcher().Fetch(context.Background(), server.URL); err == nil {
+		t.Error("Fetch() of an HTML page succeeded")
+	}
+}
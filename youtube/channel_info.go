@@ -0,0 +1,173 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ChannelInfo is the basic metadata shown on a channel's page.
+type ChannelInfo struct {
+	// ID is the channel ID (UC...).
+	ID string
+	// Name is the channel's display name.
+	Name string
+	// Description is the channel description.
+	Description string
+	// Handle is the channel's @handle, if it has one.
+	Handle string
+	// URL is the canonical channel URL.
+	URL string
+	// AvatarURL is the channel's profile picture, if the page names one.
+	AvatarURL string
+	// BannerURL is the widest version of the channel's header image, if it
+	// has one.
+	BannerURL string
+	// About is the data of the channel's about tab. FetchChannelInfo
+	// leaves it nil; innertube.Client.About fetches it.
+	About *ChannelAbout
+}
+
+// ChannelAbout is the data of a channel's about tab.
+type ChannelAbout struct {
+	// ChannelID is the channel ID (UC...).
+	ChannelID string `json:"channel_id"`
+	// Description is the full channel description.
+	Description string `json:"description,omitempty"`
+	// Country is the country the channel lists, e.g. "United States".
+	Country string `json:"country,omitempty"`
+	// JoinedAt is the day the channel was created. Zero if unknown.
+	JoinedAt time.Time `json:"joined_at,omitempty"`
+	// ViewCount is the channel's total view count. Zero if unknown.
+	ViewCount int64 `json:"view_count,omitempty"`
+	// VideoCount is the number of videos the channel shows. Zero if
+	// unknown or only shown abbreviated.
+	VideoCount int `json:"video_count,omitempty"`
+	// SubscriberCount is the subscriber count as shown, e.g. "1.2M
+	// subscribers", since YouTube only shows it abbreviated.
+	SubscriberCount string `json:"subscriber_count,omitempty"`
+	// Links are the links the channel lists, in order.
+	Links []ChannelLink `json:"links,omitempty"`
+	// HasBusinessEmail reports whether the channel offers an email address
+	// for business inquiries. YouTube only reveals the address to signed-in
+	// users who solve a captcha, so it is not fetched.
+	HasBusinessEmail bool `json:"has_business_email,omitempty"`
+	// Emails are the email addresses written in the description.
+	Emails []string `json:"emails,omitempty"`
+	// FetchedAt is when the about tab was read.
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// ChannelLink is a link on a channel's about tab.
+type ChannelLink struct {
+	// Title is the link's label, e.g. "Twitter".
+	Title string `json:"title"`
+	// URL is the link target, with YouTube's redirect removed.
+	URL string `json:"url"`
+}
+
+var (
+	ogTitleRegex       = regexp.MustCompile(`<meta property="og:title" content="([^"]*)"`)
+	ogDescriptionRegex = regexp.MustCompile(`<meta property="og:description" content="([^"]*)"`)
+	vanityURLRegex     = regexp.MustCompile(`"vanityChannelUrl":"https?://www\.youtube\.com/(@[^"/?]+)"`)
+	ogImageRegex       = regexp.MustCompile(`<meta property="og:image" content="([^"]*)"`)
+	// bannerRegexes match the banner's image list in the older c4 header
+	// and in the page header that replaced it.
+	bannerRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`"banner":\{"thumbnails":(\[[^\]]*\])`),
+		regexp.MustCompile(`"imageBannerViewModel":\{"image":\{"sources":(\[[^\]]*\])`),
+	}
+)
+
+// FetchChannelInfo resolves input (a channel ID, URL, handle, or custom URL)
+// and reads the channel's name, description, and handle from its page.
+// A handle found on the page is recorded in Aliases.
+func (r *ChannelResolver) FetchChannelInfo(ctx context.Context, input string) (*ChannelInfo, error) {
+	input = strings.TrimSpace(input)
+
+	pageURL := toFetchableURL(input)
+	if id := extractChannelIDDirect(input); id != "" {
+		pageURL = "https://www.youtube.com/channel/" + id
+	}
+	if pageURL == "" {
+		return nil, fmt.Errorf("%w: cannot parse %q", ErrInvalidURL, input)
+	}
+
+	body, err := r.fetchChannelPage(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	info := parseChannelInfo(string(body))
+	if info.ID == "" {
+		return nil, fmt.Errorf("%w: could not find channel ID in page", ErrInvalidURL)
+	}
+
+	rememberAlias(ctx, r.Aliases, input, info.ID)
+	if info.Handle != "" {
+		rememberAlias(ctx, r.Aliases, info.Handle, info.ID)
+	}
+	return info, nil
+}
+
+// parseChannelInfo extracts channel metadata from channel page HTML.
+func parseChannelInfo(page string) *ChannelInfo {
+	info := &ChannelInfo{ID: extractChannelIDFromHTML(page)}
+	if m := ogTitleRegex.FindStringSubmatch(page); m != nil {
+		info.Name = html.UnescapeString(m[1])
+	}
+	if m := ogDescriptionRegex.FindStringSubmatch(page); m != nil {
+		info.Description = html.UnescapeString(m[1])
+	}
+	if m := vanityURLRegex.FindStringSubmatch(page); m != nil {
+		info.Handle = m[1]
+	}
+	if m := ogImageRegex.FindStringSubmatch(page); m != nil {
+		info.AvatarURL = absoluteImageURL(html.UnescapeString(m[1]))
+	}
+	info.BannerURL = parseBannerURL(page)
+	if info.ID != "" {
+		info.URL = "https://www.youtube.com/channel/" + info.ID
+	}
+	return info
+}
+
+// parseBannerURL returns the widest banner image listed in page, or "" if
+// the channel has no banner.
+func parseBannerURL(page string) string {
+	for _, re := range bannerRegexes {
+		m := re.FindStringSubmatch(page)
+		if m == nil {
+			continue
+		}
+		var images []struct {
+			URL   string `json:"url"`
+			Width int    `json:"width"`
+		}
+		if err := json.Unmarshal([]byte(m[1]), &images); err != nil {
+			continue
+		}
+		best := -1
+		for i, img := range images {
+			if img.URL != "" && (best < 0 || img.Width > images[best].Width) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			return absoluteImageURL(images[best].URL)
+		}
+	}
+	return ""
+}
+
+// absoluteImageURL adds the scheme to the protocol-relative image URLs
+// YouTube sometimes serves.
+func absoluteImageURL(u string) string {
+	if strings.HasPrefix(u, "//") {
+		return "https:" + u
+	}
+	return u
+}
@@ -0,0 +1,84 @@
+package youtube
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+const sampleChannelPage = `<html><head>
+<meta property="og:title" content="Fireship &amp; Friends">
+<meta property="og:description" content="High-intensity code tutorials">
+<link rel="canonical" href="https://www.youtube.com/channel/UCsBjURrPoezykLs9EqgamOA">
+</head><body><script>var ytInitialData = {"metadata":{"channelMetadataRenderer":{"externalId":"UCsBjURrPoezykLs9EqgamOA","vanityChannelUrl":"http://www.youtube.com/@Fireship"}}};</script></body></html>`
+
+func TestChannelResolverFetchChannelInfo(t *testing.T) {
+	store := newEnrichTestStore(t)
+	resolver := &ChannelResolver{
+		HTTPClient: newMockHTTPClient(http.StatusOK, sampleChannelPage),
+		Aliases:    store,
+	}
+
+	info, err := resolver.FetchChannelInfo(context.Background(), "https://www.youtube.com/c/fireship")
+	if err != nil {
+		t.Fatalf("FetchChannelInfo() error = %v", err)
+	}
+	want := ChannelInfo{
+		ID:          "UCsBjURrPoezykLs9EqgamOA",
+		Name:        "Fireship & Friends",
+		Description: "High-intensity code tutorials",
+		Handle:      "@Fireship",
+		URL:         "https://www.youtube.com/channel/UCsBjURrPoezykLs9EqgamOA",
+	}
+	if *info != want {
+		t.Errorf("FetchChannelInfo() = %+v, want %+v", *info, want)
+	}
+
+	for _, alias := range []string{"c/fireship", "@fireship"} {
+		if mapping, err := store.GetChannelAlias(context.Background(), alias); err != nil || mapping.YouTubeID != want.ID {
+			t.Errorf("alias %s = %+v, %v; want %s", alias, mapping, err, want.ID)
+		}
+	}
+
+	resolver.HTTPClient = newMockHTTPClient(http.StatusNotFound, "")
+	if _, err := resolver.FetchChannelInfo(context.Background(), "@missing"); err != ErrChannelNotFound {
+		t.Errorf("FetchChannelInfo() missing channel error = %v, want ErrChannelNotFound", err)
+	}
+}
+
+func TestParseChannelInfo_Art(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       string
+		wantAvatar string
+		wantBanner string
+	}{
+		{
+			name: "c4 header",
+			page: `<meta property="og:image" content="https://yt3.googleusercontent.com/a=s900-c-k-c0x00ffffff-no-rj?x=1&amp;y=2">` +
+				`"banner":{"thumbnails":[{"url":"https://yt3.googleusercontent.com/b=w1060","width":1060,"height":175},{"url":"https://yt3.googleusercontent.com/b=w2560","width":2560,"height":424},{"url":"https://yt3.googleusercontent.com/b=w1707","width":1707,"height":283}]}`,
+			wantAvatar: "https://yt3.googleusercontent.com/a=s900-c-k-c0x00ffffff-no-rj?x=1&y=2",
+			wantBanner: "https://yt3.googleusercontent.com/b=w2560",
+		},
+		{
+			name:       "page header",
+			page:       `"imageBannerViewModel":{"image":{"sources":[{"url":"//yt3.googleusercontent.com/b=w1060","width":1060},{"url":"//yt3.googleusercontent.com/b=w1440","width":1440}]}}`,
+			wantBanner: "https://yt3.googleusercontent.com/b=w1440",
+		},
+		{
+			name: "no art",
+			page: sampleChannelPage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := parseChannelInfo(tt.page)
+			if info.AvatarURL != tt.wantAvatar {
+				t.Errorf("AvatarURL = %q, want %q", info.AvatarURL, tt.wantAvatar)
+			}
+			if info.BannerURL != tt.wantBanner {
+				t.Errorf("BannerURL = %q, want %q", info.BannerURL, tt.wantBanner)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package youtube
+
+import (
+	"sort"
+	"ytsync/errcode"
+	"ytsync/storage"
+)
+
+// ErrNoChapters indicates the video metadata does not define any chapters.
+var ErrNoChapters = errcode.New(errcode.NotFound, "youtube: video has no chapters")
+
+// Chapter is an uploader-defined section of a video.
+type Chapter struct {
+	// Title is the chapter title as shown on YouTube.
+	Title string `json:"title"`
+	// StartTime is the chapter start offset in seconds.
+	StartTime float64 `json:"start_time"`
+	// EndTime is the chapter end offset in seconds.
+	EndTime float64 `json:"end_time"`
+}
+
+// ChapterTranscript is the portion of a transcript that falls within one chapter.
+type ChapterTranscript struct {
+	// VideoID is the YouTube video ID.
+	VideoID string `json:"video_id"`
+	// Index is the zero-based position of the chapter in the video.
+	Index int `json:"index"`
+	// Title is the chapter title.
+	Title string `json:"title"`
+	// Start is the chapter start offset in seconds.
+	Start float64 `json:"start"`
+	// End is the chapter end offset in seconds.
+	End float64 `json:"end"`
+	// Language is the transcript language code.
+	Language string `json:"language"`
+	// Entries are the transcript entries that start within this chapter.
+	Entries []TranscriptEntry `json:"entries"`
+}
+
+// Text returns the chapter's transcript entries joined into plain text.
+func (c *ChapterTranscript) Text() string {
+	return joinEntryText(c.Entries)
+}
+
+// SegmentByChapters splits a transcript into per-chapter transcripts using the
+// chapters from the video metadata, or the chapters derived from its
+// description if it has none. Each entry is assigned to the chapter in
+// which it starts. Chapters with no entries are still returned so that chapter
+// indexes stay aligned with the video.
+//
+// Returns ErrNoChapters if the metadata does not define any chapters.
+func SegmentByChapters(metadata *VideoMetadata, transcript *Transcript) ([]ChapterTranscript, error) {
+	if metadata == nil {
+		return nil, ErrNoChapters
+	}
+	source := metadata.Chapters
+	if len(source) == 0 {
+		source = metadata.DerivedChapters
+	}
+	if len(source) == 0 {
+		return nil, ErrNoChapters
+	}
+	if transcript == nil {
+		return nil, ErrNoTranscript
+	}
+
+	chapters := make([]Chapter, len(source))
+	copy(chapters, source)
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].StartTime < chapters[j].StartTime
+	})
+
+	result := make([]ChapterTranscript, len(chapters))
+	for i, ch := range chapters {
+		end := ch.EndTime
+		// Fill in missing end times from the next chapter or the video duration
+		if end <= ch.StartTime {
+			if i+1 < len(chapters) {
+				end = chapters[i+1].StartTime
+			} else if metadata.Duration > 0 {
+				end = float64(metadata.Duration)
+			}
+		}
+		result[i] = ChapterTranscript{
+			VideoID:  transcript.VideoID,
+			Index:    i,
+			Title:    ch.Title,
+			Start:    ch.StartTime,
+			End:      end,
+			Language: transcript.Language,
+		}
+	}
+
+	for _, entry := range transcript.Entries {
+		idx := chapterIndexAt(chapters, entry.Start)
+		result[idx].Entries = append(result[idx].Entries, entry)
+	}
+
+	return result, nil
+}
+
+// chapterIndexAt returns the index of the chapter containing offset t.
+// Offsets before the first chapter belong to the first chapter.
+func chapterIndexAt(chapters []Chapter, t float64) int {
+	idx := sort.Search(len(chapters), func(i int) bool {
+		return chapters[i].StartTime > t
+	}) - 1
+	if idx < 0 {
+		return 0
+	}
+	return idx
+}
+
+// ChaptersToStorage converts chapter transcripts to their storage representation.
+func ChaptersToStorage(chapters []ChapterTranscript) []storage.Chapter {
+	out := make([]storage.Chapter, 0, len(chapters))
+	for _, ch := range chapters {
+		out = append(out, storage.Chapter{
+			Index:    ch.Index,
+			Title:    ch.Title,
+			Start:    ch.Start,
+			End:      ch.End,
+			Content:  ch.Text(),
+			Segments: entriesToSegments(ch.Entries),
+		})
+	}
+	return out
+}
+
+// parseChapters converts yt-dlp's raw "chapters" array into Chapter values,
+// sorted by start time.
+func parseChapters(raw []interface{}) []Chapter {
+	chapters := make([]Chapter, 0, len(raw))
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var ch Chapter
+		if title, ok := m["title"].(string); ok {
+			ch.Title = title
+		}
+		if start, ok := m["start_time"].(float64); ok {
+			ch.StartTime = start
+		}
+		if end, ok := m["end_time"].(float64); ok {
+			ch.EndTime = end
+		}
+		chapters = append(chapters, ch)
+	}
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].StartTime < chapters[j].StartTime
+	})
+	return chapters
+}
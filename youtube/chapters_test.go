@@ -0,0 +1,110 @@
+package youtube
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMetadata_Chapters(t *testing.T) {
+	data := []byte(`{
+  "id": "dQw4w9WgXcQ",
+  "title": "Chaptered Video",
+  "duration": 300,
+  "chapters": [
+    {"start_time": 60.0, "end_time": 300.0, "title": "Main"},
+    {"start_time": 0.0, "end_time": 60.0, "title": "Intro"}
+  ]
+}`)
+
+	metadata, err := parseMetadata(data)
+	if err != nil {
+		t.Fatalf("parseMetadata() error = %v", err)
+	}
+	if len(metadata.Chapters) != 2 {
+		t.Fatalf("len(Chapters) = %d, want 2", len(metadata.Chapters))
+	}
+	if metadata.Chapters[1].Title != "Main" {
+		t.Errorf("Chapters[1].Title = %q, want %q", metadata.Chapters[1].Title, "Main")
+	}
+	if metadata.Chapters[1].StartTime != 60 || metadata.Chapters[1].EndTime != 300 {
+		t.Errorf("Chapters[1] = %+v, want start 60 end 300", metadata.Chapters[1])
+	}
+}
+
+func TestSegmentByChapters(t *testing.T) {
+	metadata := &VideoMetadata{
+		ID:       "dQw4w9WgXcQ",
+		Duration: 300,
+		Chapters: []Chapter{
+			{Title: "Main", StartTime: 60, EndTime: 200},
+			{Title: "Intro", StartTime: 0, EndTime: 60},
+			{Title: "Outro", StartTime: 200},
+		},
+	}
+	transcript := &Transcript{
+		VideoID:  "dQw4w9WgXcQ",
+		Language: "en",
+		Entries: []TranscriptEntry{
+			{Start: 0, Duration: 5, Text: "hello"},
+			{Start: 30, Duration: 5, Text: "welcome"},
+			{Start: 60, Duration: 5, Text: "main topic"},
+			{Start: 250, Duration: 5, Text: "goodbye"},
+		},
+	}
+
+	chapters, err := SegmentByChapters(metadata, transcript)
+	if err != nil {
+		t.Fatalf("SegmentByChapters() error = %v", err)
+	}
+	if len(chapters) != 3 {
+		t.Fatalf("len(chapters) = %d, want 3", len(chapters))
+	}
+
+	tests := []struct {
+		title   string
+		start   float64
+		end     float64
+		entries int
+		text    string
+	}{
+		{"Intro", 0, 60, 2, "hello welcome"},
+		{"Main", 60, 200, 1, "main topic"},
+		{"Outro", 200, 300, 1, "goodbye"},
+	}
+	for i, tt := range tests {
+		ch := chapters[i]
+		if ch.Index != i {
+			t.Errorf("chapters[%d].Index = %d, want %d", i, ch.Index, i)
+		}
+		if ch.Title != tt.title {
+			t.Errorf("chapters[%d].Title = %q, want %q", i, ch.Title, tt.title)
+		}
+		if ch.Start != tt.start || ch.End != tt.end {
+			t.Errorf("chapters[%d] = [%v, %v], want [%v, %v]", i, ch.Start, ch.End, tt.start, tt.end)
+		}
+		if len(ch.Entries) != tt.entries {
+			t.Errorf("chapters[%d] entries = %d, want %d", i, len(ch.Entries), tt.entries)
+		}
+		if got := ch.Text(); got != tt.text {
+			t.Errorf("chapters[%d].Text() = %q, want %q", i, got, tt.text)
+		}
+	}
+
+	stored := ChaptersToStorage(chapters)
+	if len(stored) != 3 {
+		t.Fatalf("ChaptersToStorage() len = %d, want 3", len(stored))
+	}
+	if stored[0].Content != "hello welcome" {
+		t.Errorf("stored[0].Content = %q, want %q", stored[0].Content, "hello welcome")
+	}
+	if stored[0].Segments[1].End != 35 {
+		t.Errorf("stored[0].Segments[1].End = %v, want 35", stored[0].Segments[1].End)
+	}
+}
+
+func TestSegmentByChapters_NoChapters(t *testing.T) {
+	_, err := SegmentByChapters(&VideoMetadata{ID: "abc"}, &Transcript{})
+	if !errors.Is(err, ErrNoChapters) {
+		t.Errorf("SegmentByChapters() error = %v, want ErrNoChapters", err)
+	}
+}
@@ -0,0 +1,137 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"ytsync/storage"
+)
+
+// continuationTTL is how long a checkpointed Innertube continuation token is
+// trusted. It matches innertube.DefaultTokenTTL.
+const continuationTTL = 2 * time.Hour
+
+// paginationStrategist is implemented by listers whose pagination can be
+// checkpointed in SyncState and resumed with ListOptions.ResumeToken.
+// Listers that do not implement it are treated as StrategyYtdlp, which
+// cannot resume.
+type paginationStrategist interface {
+	PaginationStrategy() storage.PaginationStrategy
+}
+
+// listerStrategy returns the pagination strategy of lister.
+func listerStrategy(lister VideoLister) storage.PaginationStrategy {
+	if s, ok := lister.(paginationStrategist); ok {
+		return s.PaginationStrategy()
+	}
+	return storage.StrategyYtdlp
+}
+
+// Checkpoint is the pagination position saved when a sync is interrupted.
+// The next SyncChannelVideos call for the channel resumes from it.
+type Checkpoint struct {
+	ChannelID string
+	Strategy  storage.PaginationStrategy
+	// Token is the page token (Data API) or continuation token (Innertube)
+	// of the next page to fetch. Empty if no page had been fetched.
+	Token string
+	// PlaylistID is the uploads playlist being paged (Data API only).
+	PlaylistID string
+	// VideosProcessed is the number of videos listed before the interruption.
+	VideosProcessed int
+	// LastVideoID is the last video listed before the interruption.
+	LastVideoID string
+}
+
+// Resumable reports whether the checkpoint holds a token to resume from.
+func (c *Checkpoint) Resumable() bool {
+	return c != nil && c.Token != ""
+}
+
+// checkpointOf returns the checkpoint recorded in state.
+func checkpointOf(state *storage.SyncState) *Checkpoint {
+	cp := &Checkpoint{
+		ChannelID:       state.ChannelID,
+		Strategy:        state.Strategy,
+		VideosProcessed: state.VideosProcessed,
+		LastVideoID:     state.LastVideoID,
+	}
+	switch state.Strategy {
+	case storage.StrategyAPI:
+		cp.Token = state.APIPageToken
+		cp.PlaylistID = state.APIPlaylistID
+	case storage.StrategyInnertube:
+		cp.Token = state.ContinuationToken
+	}
+	return cp
+}
+
+// ResumableError is returned when a sync is interrupted by context
+// cancellation after its checkpoint has been persisted. Err is the
+// cancellation cause, so errors.Is(err, context.Canceled) still holds.
+type ResumableError struct {
+	Checkpoint *Checkpoint
+	// Videos holds the videos listed before the interruption, if the
+	// lister returned any.
+	Videos []VideoInfo
+	Err    error
+}
+
+func (e *ResumableError) Error() string {
+	if e.Checkpoint.Resumable() {
+		return fmt.Sprintf("sync of %s interrupted after %d videos (resumable): %v",
+			e.Checkpoint.ChannelID, e.Checkpoint.VideosProcessed, e.Err)
+	}
+	return fmt.Sprintf("sync of %s interrupted: %v", e.Checkpoint.ChannelID, e.Err)
+}
+
+func (e *ResumableError) Unwrap() error {
+	return e.Err
+}
+
+// IsResumable reports whether err is an interrupted sync whose checkpoint
+// can be resumed.
+func IsResumable(err error) bool {
+	var re *ResumableError
+	return errors.As(err, &re) && re.Checkpoint.Resumable()
+}
+
+// checkpointOptions returns a copy of opts whose OnProgress callback also
+// records each page's token and counts in state, so an interrupted sync can
+// persist where it stopped. Counts continue from those already in state, so
+// a resumed sync keeps its running total.
+func checkpointOptions(opts *ListOptions, state *storage.SyncState) *ListOptions {
+	tracked := ListOptions{}
+	if opts != nil {
+		tracked = *opts
+	}
+	base := state.VideosProcessed
+	quotaBase := state.APIQuotaUsed
+	next := tracked.OnProgress
+	tracked.OnProgress = func(p *PaginationProgress) error {
+		switch state.Strategy {
+		case storage.StrategyAPI:
+			// On error Token is the page that failed, which is where to resume
+			state.UpdateAPIPageToken(p.Token, p.PlaylistID, 0)
+			state.APIQuotaUsed = quotaBase + p.QuotaUsed
+		case storage.StrategyInnertube:
+			state.UpdateInnertubeToken(p.Token, continuationTTL)
+		}
+		state.VideosProcessed = base + p.VideosRetrieved
+		if p.LastVideoID != "" {
+			state.LastVideoID = p.LastVideoID
+		}
+		if next != nil {
+			return next(p)
+		}
+		return nil
+	}
+	return &tracked
+}
+
+// interrupted reports whether err is the result of ctx being cancelled or
+// timing out.
+func interrupted(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() != nil
+}
@@ -0,0 +1,220 @@
+package youtube
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Rules YouTube applies before showing description timestamps as chapters.
+const (
+	// minDerivedChapters is the fewest timestamps that make chapters.
+	minDerivedChapters = 3
+	// minDerivedChapterLength is the shortest chapter, in seconds.
+	minDerivedChapterLength = 10
+)
+
+var (
+	descriptionURLPattern       = regexp.MustCompile(`https?://[^\s<>"]+`)
+	descriptionHashtagPattern   = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/#])#([\p{L}\p{N}_]+)`)
+	descriptionTimestampPattern = regexp.MustCompile(`(?:(\d{1,2}):)?(\d{1,2}):(\d{2})`)
+)
+
+// DescriptionLinks holds what a video description links to.
+type DescriptionLinks struct {
+	// URLs are the web links, in order of first appearance, each once.
+	URLs []string `json:"urls,omitempty"`
+	// Hashtags are the hashtags without their "#", in order of first
+	// appearance, each once regardless of case.
+	Hashtags []string `json:"hashtags,omitempty"`
+	// Timestamps are the links into the video, in order of appearance.
+	Timestamps []DescriptionTimestamp `json:"timestamps,omitempty"`
+}
+
+// DescriptionTimestamp is a description line that links into the video,
+// such as "12:34 Topic".
+type DescriptionTimestamp struct {
+	// Time is the offset linked to, in seconds.
+	Time float64 `json:"time"`
+	// Text is the timestamp as written, such as "12:34".
+	Text string `json:"text"`
+	// Label is the rest of the line, without the separators around the
+	// timestamp.
+	Label string `json:"label,omitempty"`
+	// Line is the zero-based line of the description the timestamp is on.
+	Line int `json:"line"`
+}
+
+// IsEmpty reports whether the description links to nothing.
+func (l *DescriptionLinks) IsEmpty() bool {
+	return l == nil || (len(l.URLs) == 0 && len(l.Hashtags) == 0 && len(l.Timestamps) == 0)
+}
+
+// ParseDescription extracts the URLs, hashtags, and timestamps of a video
+// description. Timestamps past duration, in seconds, are dropped; a zero
+// duration keeps them all. It returns nil for a description without any.
+func ParseDescription(description string, duration int) *DescriptionLinks {
+	links := &DescriptionLinks{}
+
+	seenURL := make(map[string]bool)
+	for _, u := range descriptionURLPattern.FindAllString(description, -1) {
+		u = trimURL(u)
+		if !seenURL[u] {
+			seenURL[u] = true
+			links.URLs = append(links.URLs, u)
+		}
+	}
+
+	// Hashtags are matched outside URLs, whose fragments look like them
+	text := descriptionURLPattern.ReplaceAllString(description, " ")
+	seenTag := make(map[string]bool)
+	for _, m := range descriptionHashtagPattern.FindAllStringSubmatch(text, -1) {
+		tag := m[1]
+		if !strings.ContainsFunc(tag, unicode.IsLetter) || seenTag[strings.ToLower(tag)] {
+			continue
+		}
+		seenTag[strings.ToLower(tag)] = true
+		links.Hashtags = append(links.Hashtags, tag)
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		links.Timestamps = append(links.Timestamps, parseTimestampLine(line, i, duration)...)
+	}
+
+	if links.IsEmpty() {
+		return nil
+	}
+	return links
+}
+
+// DeriveChapters returns the chapters a description's timestamps define,
+// following the rules YouTube applies before showing them as chapters: the
+// first timestamp is 0:00, there are at least three, they ascend, and each
+// chapter is at least ten seconds long. Each chapter ends where the next
+// starts, and the last at duration in seconds, or is left open if duration
+// is zero. It returns nil if the timestamps do not make chapters.
+func DeriveChapters(links *DescriptionLinks, duration int) []Chapter {
+	if links == nil {
+		return nil
+	}
+	// A line may hold several timestamps; only its first can start a chapter
+	var starts []DescriptionTimestamp
+	for i, ts := range links.Timestamps {
+		if i == 0 || ts.Line != links.Timestamps[i-1].Line {
+			starts = append(starts, ts)
+		}
+	}
+	if len(starts) < minDerivedChapters || starts[0].Time != 0 {
+		return nil
+	}
+
+	chapters := make([]Chapter, len(starts))
+	for i, ts := range starts {
+		chapters[i] = Chapter{Title: ts.Label, StartTime: ts.Time}
+		if i > 0 {
+			if ts.Time-chapters[i-1].StartTime < minDerivedChapterLength {
+				return nil
+			}
+			chapters[i-1].EndTime = ts.Time
+		}
+	}
+	if duration > 0 {
+		last := &chapters[len(chapters)-1]
+		if float64(duration)-last.StartTime < minDerivedChapterLength {
+			return nil
+		}
+		last.EndTime = float64(duration)
+	}
+	return chapters
+}
+
+// parseTimestampLine returns the timestamps in line n of a description.
+func parseTimestampLine(line string, n, duration int) []DescriptionTimestamp {
+	var matches [][]int
+	for _, m := range descriptionTimestampPattern.FindAllStringSubmatchIndex(line, -1) {
+		// Skip parts of longer numbers and clock times such as "10:30am"
+		if m[0] > 0 && isTimestampNeighbor(line[m[0]-1]) {
+			continue
+		}
+		if m[1] < len(line) && (isTimestampNeighbor(line[m[1]]) || unicode.IsLetter(rune(line[m[1]]))) {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	// The label is the line without its timestamps
+	var label strings.Builder
+	last := 0
+	for _, m := range matches {
+		label.WriteString(line[last:m[0]])
+		label.WriteString(" ")
+		last = m[1]
+	}
+	label.WriteString(line[last:])
+	title := cleanTimestampLabel(label.String())
+
+	var out []DescriptionTimestamp
+	for _, m := range matches {
+		seconds, ok := timestampSeconds(line, m)
+		if !ok || (duration > 0 && seconds > float64(duration)) {
+			continue
+		}
+		out = append(out, DescriptionTimestamp{Time: seconds, Text: line[m[0]:m[1]], Label: title, Line: n})
+	}
+	return out
+}
+
+// isTimestampNeighbor reports whether c next to a timestamp makes it part
+// of something longer.
+func isTimestampNeighbor(c byte) bool {
+	return c >= '0' && c <= '9' || c == ':'
+}
+
+// timestampSeconds converts the timestamp submatches m of line to seconds.
+func timestampSeconds(line string, m []int) (float64, bool) {
+	group := func(i int) int {
+		if m[2*i] < 0 {
+			return 0
+		}
+		n, _ := strconv.Atoi(line[m[2*i]:m[2*i+1]])
+		return n
+	}
+	hours, minutes, seconds := group(1), group(2), group(3)
+	if seconds >= 60 || (m[2] >= 0 && minutes >= 60) {
+		return 0, false
+	}
+	return float64(hours*3600 + minutes*60 + seconds), true
+}
+
+// cleanTimestampLabel trims the separators and brackets left around a
+// removed timestamp, such as in "(12:34) - Topic".
+func cleanTimestampLabel(s string) string {
+	s = strings.NewReplacer("()", "", "[]", "", "( )", "", "[ ]", "").Replace(s)
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("-–—|:•·>", r)
+	})
+}
+
+// trimURL drops punctuation that ends the sentence around a URL rather than
+// the URL, keeping a closing parenthesis the URL opened.
+func trimURL(u string) string {
+	for len(u) > 0 {
+		last := u[len(u)-1]
+		switch {
+		case strings.IndexByte(".,;:!?'\"", last) >= 0:
+			u = u[:len(u)-1]
+		case last == ')' && strings.Count(u, "(") < strings.Count(u, ")"):
+			u = u[:len(u)-1]
+		case last == ']' && strings.Count(u, "[") < strings.Count(u, "]"):
+			u = u[:len(u)-1]
+		default:
+			return u
+		}
+	}
+	return u
+}
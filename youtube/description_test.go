@@ -0,0 +1,116 @@
+package youtube
+
+import (
+	"reflect"
+	"testing"
+)
+
+const chapteredDescription = `New video on building a workbench! #woodworking #DIY #Woodworking
+Plans: https://example.com/plans?id=42. Tools (affiliate): https://example.com/tools_(list)
+
+0:00 Intro
+(1:05) - Cutting the legs
+[12:30] Glue-up
+1:02:03 – Finishing | sanding
+Outro at 1:10:00 then bloopers at 1:12:15
+
+Made at 10:30am with 16:9 footage, track #1 on https://example.com/#music`
+
+func TestParseDescription(t *testing.T) {
+	links := ParseDescription(chapteredDescription, 4500)
+	if links == nil {
+		t.Fatal("ParseDescription() = nil")
+	}
+
+	wantURLs := []string{"https://example.com/plans?id=42", "https://example.com/tools_(list)", "https://example.com/#music"}
+	if !reflect.DeepEqual(links.URLs, wantURLs) {
+		t.Errorf("URLs = %q, want %q", links.URLs, wantURLs)
+	}
+	wantTags := []string{"woodworking", "DIY"}
+	if !reflect.DeepEqual(links.Hashtags, wantTags) {
+		t.Errorf("Hashtags = %q, want %q", links.Hashtags, wantTags)
+	}
+
+	want := []DescriptionTimestamp{
+		{Time: 0, Text: "0:00", Label: "Intro", Line: 3},
+		{Time: 65, Text: "1:05", Label: "Cutting the legs", Line: 4},
+		{Time: 750, Text: "12:30", Label: "Glue-up", Line: 5},
+		{Time: 3723, Text: "1:02:03", Label: "Finishing | sanding", Line: 6},
+		{Time: 4200, Text: "1:10:00", Label: "Outro at then bloopers at", Line: 7},
+		{Time: 4335, Text: "1:12:15", Label: "Outro at then bloopers at", Line: 7},
+	}
+	if !reflect.DeepEqual(links.Timestamps, want) {
+		t.Errorf("Timestamps =\n%+v\nwant\n%+v", links.Timestamps, want)
+	}
+
+	// Timestamps past the end of the video are not links into it
+	short := ParseDescription(chapteredDescription, 3600)
+	if n := len(short.Timestamps); n != 3 {
+		t.Errorf("timestamps within a one-hour video = %d, want 3", n)
+	}
+
+	if got := ParseDescription("No links here. Score was 3-2.", 0); got != nil {
+		t.Errorf("ParseDescription() of plain text = %+v, want nil", got)
+	}
+}
+
+func TestDeriveChapters(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		duration    int
+		want        []Chapter
+	}{
+		{
+			name:        "description chapters",
+			description: "0:00 Intro\n0:45 Setup\n2:10 Demo",
+			duration:    300,
+			want: []Chapter{
+				{Title: "Intro", StartTime: 0, EndTime: 45},
+				{Title: "Setup", StartTime: 45, EndTime: 130},
+				{Title: "Demo", StartTime: 130, EndTime: 300},
+			},
+		},
+		{
+			name:        "unknown duration leaves the last chapter open",
+			description: "00:00 Intro\n01:00 Middle\n02:00 End",
+			want: []Chapter{
+				{Title: "Intro", StartTime: 0, EndTime: 60},
+				{Title: "Middle", StartTime: 60, EndTime: 120},
+				{Title: "End", StartTime: 120},
+			},
+		},
+		{name: "not starting at zero", description: "0:05 Intro\n1:00 Middle\n2:00 End", duration: 300},
+		{name: "too few", description: "0:00 Intro\n1:00 End", duration: 300},
+		{name: "chapter too short", description: "0:00 Intro\n0:05 Middle\n2:00 End", duration: 300},
+		{name: "out of order", description: "0:00 Intro\n2:00 Middle\n1:00 End", duration: 300},
+		{name: "last chapter too short", description: "0:00 Intro\n1:00 Middle\n4:55 End", duration: 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DeriveChapters(ParseDescription(tt.description, tt.duration), tt.duration)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeriveChapters() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmentByChapters_DerivedChapters(t *testing.T) {
+	metadata := &VideoMetadata{
+		Duration:        300,
+		DerivedChapters: DeriveChapters(ParseDescription("0:00 Intro\n1:00 Middle\n2:00 End", 300), 300),
+	}
+	transcript := &Transcript{VideoID: "vid", Entries: []TranscriptEntry{
+		{Start: 10, Text: "hello"},
+		{Start: 150, Text: "bye"},
+	}}
+
+	chapters, err := SegmentByChapters(metadata, transcript)
+	if err != nil {
+		t.Fatalf("SegmentByChapters() error = %v", err)
+	}
+	if len(chapters) != 3 || chapters[0].Text() != "hello" || chapters[2].Text() != "bye" {
+		t.Errorf("SegmentByChapters() = %+v", chapters)
+	}
+}
@@ -1,14 +1,15 @@
 package youtube
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"ytsync/media"
 )
 
 // DownloadOptions configures video download behavior.
@@ -19,20 +20,51 @@ type DownloadOptions struct {
 	// Format specifies the video format: "best", "mp4", "webm", or a yt-dlp format string.
 	// Defaults to "best" which selects the best quality up to 1080p.
 	Format string
+	// Selector, if set, chooses the format from the formats the video offers
+	// and overrides Format. With AudioOnly, the selector should be an audio
+	// rule such as "best audio m4a".
+	Selector *FormatSelector
 	// AudioOnly extracts audio as MP3 instead of downloading video.
 	AudioOnly bool
 	// AudioQuality specifies the audio quality in kbps when AudioOnly is true.
 	// Defaults to 192 if not specified.
 	AudioQuality int
+	// AudioLanguage picks the audio track of videos with several, such as
+	// dubbed versions: a language code like "en" or "de-DE", or
+	// OriginalAudio for the video's original language. If the video has no
+	// such track, the default one is downloaded.
+	AudioLanguage string
 	// IncludeMetadata saves video metadata to a JSON file alongside the video.
 	IncludeMetadata bool
 	// Filename specifies a custom output filename (without extension).
 	// If empty, defaults to the sanitized video title.
 	// When provided, this takes precedence over title-based naming.
 	Filename string
+	// OutputTemplate renders the output path relative to OutputDir from video
+	// metadata. If set, it overrides Filename; the metadata JSON file is written
+	// next to the video using the same template with the "json" extension.
+	OutputTemplate *OutputTemplate
+	// Collision controls what happens when the templated output path already
+	// exists. Defaults to CollisionOverwrite. Only used with OutputTemplate.
+	Collision CollisionPolicy
 	// YtdlpPath is the path to the yt-dlp executable.
 	// If empty, uses "yt-dlp" from PATH.
 	YtdlpPath string
+	// Resume continues an interrupted download from its .part file instead of
+	// starting over, even if the yt-dlp configuration says otherwise. yt-dlp
+	// resumes by default, so only Restart changes the default behavior.
+	Resume bool
+	// Restart discards any partial file and starts the download over.
+	// Ignored if Resume is set.
+	Restart bool
+	// Subtitles, if set, downloads subtitles and embeds or burns them into
+	// the video. Ignored when AudioOnly is true.
+	Subtitles *SubtitleOptions
+	// Verify checks the completed file against the size yt-dlp expects for
+	// the formats it selected and the duration from the video metadata.
+	// Verification failures are returned as a *VerifyError alongside the
+	// result, whose Verification lists the problems.
+	Verify bool
 	// Progress callback for download progress updates (optional).
 	// The callback receives the raw yt-dlp output line.
 	OnProgress func(line string)
@@ -40,13 +72,23 @@ type DownloadOptions struct {
 
 // DownloadResult contains information about a completed download.
 type DownloadResult struct {
+	// VideoID is the ID of the downloaded video.
+	VideoID string
 	// VideoPath is the path to the downloaded video/audio file.
 	// Note: The exact filename is determined by yt-dlp based on video title.
 	VideoPath string
 	// MetadataPath is the path to the metadata JSON file (if IncludeMetadata was true).
 	MetadataPath string
-	// Metadata contains the parsed video metadata (if IncludeMetadata was true).
+	// Metadata contains the parsed video metadata (if IncludeMetadata or Verify was true).
 	Metadata *VideoMetadata
+	// Verification contains the integrity check result (if Verify was true).
+	Verification *VerifyResult
+	// Skipped is true if the download was skipped because the output already
+	// existed and Collision was CollisionSkip. VideoPath is the existing file.
+	Skipped bool
+	// Checksums maps algorithm names to the hex checksums of the file
+	// computed by a ChecksumProcessor.
+	Checksums map[string]string
 }
 
 // Downloader handles video downloads using yt-dlp.
@@ -56,6 +98,39 @@ type Downloader struct {
 	// Timeout is the maximum duration for the download.
 	// Note: Large videos may need longer timeouts.
 	Timeout int
+	// FFprobePath is the path to the ffprobe executable used to verify media
+	// duration. If empty, uses "ffprobe" from PATH; verification skips the
+	// duration check when ffprobe is unavailable.
+	FFprobePath string
+	// FFmpegPath is the path to the ffmpeg executable used to convert audio
+	// for transcription. If empty, uses "ffmpeg" from PATH.
+	FFmpegPath string
+	// Media runs ffmpeg/ffprobe operations. If nil, an ffmpeg-backed tool
+	// using FFmpegPath and FFprobePath is used. Tests can set a fake.
+	Media media.Tool
+	// PostProcessors are run in order on each completed download. They are
+	// not run for skipped downloads or files that failed verification.
+	PostProcessors []PostProcessor
+	// Metadata, if set, fetches video metadata in place of running yt-dlp
+	// directly, for example a MetadataCache's Get.
+	Metadata MetadataFetcher
+}
+
+// mediaTool returns the media tool used for verification and conversion.
+func (d *Downloader) mediaTool() media.Tool {
+	if d.Media != nil {
+		return d.Media
+	}
+	return media.New(d.FFmpegPath, d.FFprobePath)
+}
+
+// fetchMetadata fetches the metadata of videoID with d.Metadata, or else
+// with yt-dlp at ytdlpPath.
+func (d *Downloader) fetchMetadata(ctx context.Context, videoID, ytdlpPath string) (*VideoMetadata, error) {
+	if d.Metadata != nil {
+		return d.Metadata(ctx, videoID)
+	}
+	return FetchMetadata(ctx, videoID, ytdlpPath)
 }
 
 // NewDownloader creates a new Downloader with default settings.
@@ -90,12 +165,20 @@ func (d *Downloader) Download(ctx context.Context, videoID string, opts *Downloa
 		return nil, fmt.Errorf("create output directory: %w", err)
 	}
 
-	result := &DownloadResult{}
+	result := &DownloadResult{VideoID: videoID}
 
-	// Fetch metadata first if requested
-	if opts.IncludeMetadata {
-		metadata, err := FetchMetadata(ctx, videoID, ytdlpPath)
+	// Fetch metadata first if requested or post-processors may need it
+	if opts.IncludeMetadata || opts.Verify || opts.OutputTemplate != nil || opts.Selector != nil || opts.AudioLanguage != "" || len(d.PostProcessors) > 0 {
+		metadata, err := d.fetchMetadata(ctx, videoID, ytdlpPath)
 		if err != nil {
+			// The output template cannot be rendered without metadata
+			if opts.OutputTemplate != nil {
+				return nil, fmt.Errorf("fetch metadata for output template: %w", err)
+			}
+			// Nor can formats be selected
+			if opts.Selector != nil {
+				return nil, fmt.Errorf("fetch metadata for format selection: %w", err)
+			}
 			// Non-fatal: continue with download even if metadata fails
 			// but don't set metadata in result
 		} else {
@@ -103,11 +186,53 @@ func (d *Downloader) Download(ctx context.Context, videoID string, opts *Downloa
 		}
 	}
 
+	// Find the audio track in the requested language
+	var audioLanguage string
+	if opts.AudioLanguage != "" && result.Metadata != nil {
+		if track := FindAudioTrack(result.Metadata.AudioTracks, opts.AudioLanguage); track != nil {
+			audioLanguage = track.Language
+		}
+	}
+
+	// Choose exact formats up front so yt-dlp is not left to interpret the rule
+	var selected string
+	if opts.Selector != nil {
+		var selection *FormatSelection
+		var err error
+		if audioLanguage != "" {
+			selection, _ = opts.Selector.Select(formatsInLanguage(result.Metadata.Formats, audioLanguage))
+		}
+		if selection == nil {
+			selection, err = opts.Selector.Select(result.Metadata.Formats)
+		}
+		if err != nil {
+			return nil, err
+		}
+		selected = selection.FormatID()
+	}
+
 	// Build yt-dlp arguments
 	// Use a template that outputs the final filename
 	// If custom Filename is provided, use it; otherwise use video title
 	var outputTemplate string
-	if opts.Filename != "" {
+	var templatedBase string
+	if opts.OutputTemplate != nil {
+		base, err := resolveTemplatedOutput(outputDir, opts.OutputTemplate, result.Metadata, opts.Collision)
+		if errors.Is(err, ErrOutputExists) {
+			result.VideoPath = base
+			result.Skipped = true
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		templatedBase = base
+		if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+			return nil, fmt.Errorf("create output directory: %w", err)
+		}
+		// Escape yt-dlp's template syntax in rendered values
+		outputTemplate = strings.ReplaceAll(strings.ReplaceAll(base, "%", "%%"), extPlaceholder, "%(ext)s")
+	} else if opts.Filename != "" {
 		// Sanitize the custom filename to remove invalid characters
 		outputTemplate = filepath.Join(outputDir, sanitizeFilename(opts.Filename)+".%(ext)s")
 	} else {
@@ -116,16 +241,31 @@ func (d *Downloader) Download(ctx context.Context, videoID string, opts *Downloa
 	ytdlpArgs := []string{
 		"-o", outputTemplate,
 		"--no-warnings",
+		"--print", "before_dl:" + downloadSizePrefix + "%(filesize,filesize_approx)s", // Expected size of the selected formats
 		"--print", "after_move:filepath", // Print final path after download
 	}
 
+	if opts.OutputTemplate != nil && opts.Collision != CollisionSkip {
+		ytdlpArgs = append(ytdlpArgs, "--force-overwrites")
+	}
+
+	if opts.Resume {
+		ytdlpArgs = append(ytdlpArgs, "--continue", "--part")
+	} else if opts.Restart {
+		ytdlpArgs = append(ytdlpArgs, "--no-continue")
+	}
+
 	if opts.AudioOnly {
 		audioQuality := opts.AudioQuality
 		if audioQuality <= 0 {
 			audioQuality = 192
 		}
+		format := PreferAudioLanguage("bestaudio/best", audioLanguage)
+		if selected != "" {
+			format = selected
+		}
 		ytdlpArgs = append(ytdlpArgs,
-			"-f", "bestaudio/best",
+			"-f", format,
 			"-x",
 			"--audio-format", "mp3",
 			"--audio-quality", fmt.Sprintf("%d", audioQuality),
@@ -133,33 +273,34 @@ func (d *Downloader) Download(ctx context.Context, videoID string, opts *Downloa
 	} else {
 		// Video download with format selection
 		format := opts.Format
-		if format == "" || format == "best" {
+		if selected != "" {
+			format = selected
+		} else if format == "" || format == "best" {
 			// Use a more robust format selection that falls back gracefully
 			format = "bestvideo[height<=1080]+bestaudio/best[height<=1080]/best"
 		}
+		if selected == "" {
+			format = PreferAudioLanguage(format, audioLanguage)
+		}
 		ytdlpArgs = append(ytdlpArgs, "-f", format)
 	}
 
+	addSubs := opts.Subtitles != nil && len(opts.Subtitles.Languages) > 0 && !opts.AudioOnly
+	if addSubs {
+		ytdlpArgs = append(ytdlpArgs, opts.Subtitles.ytdlpArgs()...)
+	}
+
 	ytdlpArgs = append(ytdlpArgs, videoID)
 
 	// Execute yt-dlp
-	cmd := exec.CommandContext(ctx, ytdlpPath, ytdlpArgs...)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		stderrStr := stderr.String()
-		if stderrStr != "" {
-			return nil, fmt.Errorf("download video: %w: %s", err, stderrStr)
-		}
+	stdout, err := runYtdlp(ctx, ytdlpPath, ytdlpArgs...)
+	if err != nil {
 		return nil, fmt.Errorf("download video: %w", err)
 	}
 
 	// Parse the output to get the final filepath
 	// yt-dlp with --print after_move:filepath outputs the path
-	outputPath := strings.TrimSpace(stdout.String())
+	outputPath := strings.TrimSpace(string(stdout))
 	if outputPath != "" {
 		// The output may contain multiple lines; the filepath is the last non-empty line
 		lines := strings.Split(outputPath, "\n")
@@ -178,9 +319,38 @@ func (d *Downloader) Download(ctx context.Context, videoID string, opts *Downloa
 		result.VideoPath = outputDir // At least return the directory
 	}
 
+	// Verify the completed file. Audio extracted to MP3 no longer has the
+	// size of the downloaded format, so only its duration is checked.
+	if opts.Verify && result.Metadata != nil && result.VideoPath != outputDir {
+		var expectedSize int64
+		if !opts.AudioOnly {
+			expectedSize = parseDownloadSize(string(stdout))
+		}
+		verification, err := d.verifyFile(ctx, result.VideoPath, result.Metadata, expectedSize)
+		if err != nil {
+			return result, err
+		}
+		result.Verification = verification
+		if !verification.OK() {
+			return result, &VerifyError{Result: verification}
+		}
+	}
+
+	// Add subtitles after verification, which checks the file as downloaded
+	if addSubs && result.VideoPath != outputDir {
+		path, err := d.addSubtitles(ctx, result.VideoPath, opts.Subtitles)
+		result.VideoPath = path
+		if err != nil {
+			return result, err
+		}
+	}
+
 	// Save metadata if we have it
 	if result.Metadata != nil && opts.IncludeMetadata {
 		metadataPath := filepath.Join(outputDir, sanitizeFilename(result.Metadata.Title)+".json")
+		if templatedBase != "" {
+			metadataPath = strings.ReplaceAll(templatedBase, extPlaceholder, "json")
+		}
 		if err := saveMetadataToFile(result.Metadata, metadataPath); err != nil {
 			// Non-fatal: metadata save failure shouldn't fail the download
 		} else {
@@ -188,9 +358,109 @@ func (d *Downloader) Download(ctx context.Context, videoID string, opts *Downloa
 		}
 	}
 
+	if result.VideoPath != outputDir {
+		if err := d.postProcess(ctx, result); err != nil {
+			return result, err
+		}
+	}
+
 	return result, nil
 }
 
+// downloadSizePrefix marks the line on which yt-dlp prints the expected
+// size of the formats it selected.
+const downloadSizePrefix = "ytsync-size:"
+
+// parseDownloadSize returns the size yt-dlp printed after
+// downloadSizePrefix in output, or 0 if it did not know it.
+func parseDownloadSize(output string) int64 {
+	for _, line := range strings.Split(output, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), downloadSizePrefix); ok {
+			size, err := strconv.ParseFloat(v, 64)
+			if err != nil || size < 0 {
+				return 0
+			}
+			return int64(size)
+		}
+	}
+	return 0
+}
+
+// extPlaceholder stands in for the file extension when rendering an output
+// template before yt-dlp has chosen the final container format.
+const extPlaceholder = "ytsync-ext-placeholder"
+
+// sidecarExts are extensions that don't count as an existing download when
+// checking for collisions.
+var sidecarExts = map[string]bool{".part": true, ".ytdl": true, ".json": true, ".temp": true}
+
+// resolveTemplatedOutput renders tmpl for a download and applies the collision
+// policy. The returned path contains extPlaceholder in place of the extension.
+// Since the final extension is unknown, any non-sidecar file with the same
+// name counts as a collision.
+func resolveTemplatedOutput(outputDir string, tmpl *OutputTemplate, metadata *VideoMetadata, policy CollisionPolicy) (string, error) {
+	rel, err := tmpl.Render(TemplateDataFromMetadata(metadata, extPlaceholder))
+	if err != nil {
+		return "", err
+	}
+	base := filepath.Join(outputDir, rel)
+
+	existing := existingDownload(base)
+	if existing == "" {
+		return base, nil
+	}
+
+	switch policy {
+	case CollisionSkip:
+		return existing, ErrOutputExists
+	case CollisionSuffix:
+		for i := 1; ; i++ {
+			candidate := strings.Replace(base, "."+extPlaceholder, fmt.Sprintf("-%d.%s", i, extPlaceholder), 1)
+			if candidate == base {
+				candidate = fmt.Sprintf("%s-%d", base, i)
+			}
+			if existingDownload(candidate) == "" {
+				return candidate, nil
+			}
+		}
+	case CollisionOverwrite, "":
+		return base, nil
+	default:
+		return "", fmt.Errorf("unknown collision policy %q", policy)
+	}
+}
+
+// existingDownload returns the path of a file matching base with any
+// extension substituted for extPlaceholder, or "" if none exists.
+func existingDownload(base string) string {
+	dir, name := filepath.Split(base)
+	prefix, suffix, found := strings.Cut(name, extPlaceholder)
+	if !found {
+		if fileExists(base) {
+			return base
+		}
+		return ""
+	}
+
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		n := e.Name()
+		if e.IsDir() || len(n) <= len(prefix)+len(suffix) {
+			continue
+		}
+		if strings.HasPrefix(n, prefix) && strings.HasSuffix(n, suffix) && !sidecarExts[filepath.Ext(n)] {
+			ext := n[len(prefix) : len(n)-len(suffix)]
+			if !strings.Contains(ext, ".") {
+				return filepath.Join(dir, n)
+			}
+		}
+	}
+	return ""
+}
+
 // sanitizeFilename removes/replaces characters that are invalid in filenames.
 func sanitizeFilename(s string) string {
 	replacements := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|"}
@@ -0,0 +1,135 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"ytsync/errcode"
+	"ytsync/media"
+)
+
+// ErrCorruptDownload indicates a downloaded file failed integrity verification.
+var ErrCorruptDownload = errcode.New(errcode.Corrupt, "youtube: downloaded file is truncated or corrupted")
+
+// Verification tolerances. Container overhead and merged audio/video streams
+// mean the final file rarely matches the metadata exactly.
+const (
+	// verifySizeTolerance is the fraction of the expected size a file may fall short by.
+	verifySizeTolerance = 0.10
+	// verifyDurationTolerance is the number of seconds a file may fall short by.
+	verifyDurationTolerance = 2.0
+)
+
+// VerifyResult describes the outcome of checking a downloaded file.
+type VerifyResult struct {
+	// Path is the file that was checked.
+	Path string
+	// VideoID is the YouTube video ID the file was checked against.
+	VideoID string
+	// Size is the actual file size in bytes.
+	Size int64
+	// ExpectedSize is the size yt-dlp expected for the downloaded formats
+	// (0 if unknown).
+	ExpectedSize int64
+	// Duration is the media duration in seconds reported by ffprobe
+	// (0 if ffprobe was unavailable).
+	Duration float64
+	// ExpectedDuration is the video duration from metadata in seconds.
+	ExpectedDuration int
+	// PartialFile is true if a leftover .part file was found next to the file.
+	PartialFile bool
+	// Problems lists each integrity problem found. Empty if the file is OK.
+	Problems []string
+}
+
+// OK returns true if no integrity problems were found.
+func (r *VerifyResult) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// VerifyError is returned when a downloaded file fails verification.
+type VerifyError struct {
+	Result *VerifyResult
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("verify %s (%s): %s", e.Result.Path, e.Result.VideoID, strings.Join(e.Result.Problems, "; "))
+}
+
+func (e *VerifyError) Unwrap() error {
+	return ErrCorruptDownload
+}
+
+// Verify checks a downloaded file against the duration from the video's
+// metadata, flagging truncated or corrupted files. The format of the file
+// is not known, so its size is only checked to be non-zero; Download with
+// DownloadOptions.Verify also checks it against the size of the formats
+// yt-dlp selected. It returns an error only if the check itself could not
+// be performed; integrity problems are reported in the result.
+func (d *Downloader) Verify(ctx context.Context, path string, videoID string) (*VerifyResult, error) {
+	ytdlpPath := d.YtdlpPath
+	if ytdlpPath == "" {
+		ytdlpPath = "yt-dlp"
+	}
+
+	metadata, err := d.fetchMetadata(ctx, videoID, ytdlpPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.verifyFile(ctx, path, metadata, 0)
+}
+
+// verifyFile checks path against metadata and, if it is positive,
+// expectedSize.
+func (d *Downloader) verifyFile(ctx context.Context, path string, metadata *VideoMetadata, expectedSize int64) (*VerifyResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("verify download: %w", err)
+	}
+
+	result := &VerifyResult{
+		Path:             path,
+		VideoID:          metadata.ID,
+		Size:             info.Size(),
+		ExpectedSize:     expectedSize,
+		ExpectedDuration: metadata.Duration,
+	}
+
+	if result.Size == 0 {
+		result.Problems = append(result.Problems, "file is empty")
+	}
+
+	if _, err := os.Stat(path + ".part"); err == nil {
+		result.PartialFile = true
+		result.Problems = append(result.Problems, "partial download file present")
+	}
+
+	if result.ExpectedSize > 0 && float64(result.Size) < float64(result.ExpectedSize)*(1-verifySizeTolerance) {
+		result.Problems = append(result.Problems,
+			fmt.Sprintf("file size %d bytes is smaller than expected %d bytes", result.Size, result.ExpectedSize))
+	}
+
+	duration, err := d.mediaTool().Duration(ctx, path)
+	switch {
+	case errors.Is(err, media.ErrNotInstalled):
+		// ffprobe/ffmpeg not installed: skip the duration check
+	case err != nil:
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		result.Problems = append(result.Problems, fmt.Sprintf("media could not be read: %v", err))
+	default:
+		result.Duration = duration
+		expected := float64(result.ExpectedDuration)
+		if expected > 0 && duration < expected-math.Max(verifyDurationTolerance, expected*0.02) {
+			result.Problems = append(result.Problems,
+				fmt.Sprintf("media duration %.1fs is shorter than expected %ds", duration, result.ExpectedDuration))
+		}
+	}
+
+	return result, nil
+}
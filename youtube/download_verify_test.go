@@ -0,0 +1,265 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeMockFFprobe creates a script that prints the given duration.
+func writeMockFFprobe(t *testing.T, dir, output string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("mock ffprobe requires a POSIX shell")
+	}
+	path := filepath.Join(dir, "ffprobe")
+	script := "#!/bin/sh\necho \"" + output + "\"\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to create mock ffprobe: %v", err)
+	}
+	return path
+}
+
+func TestDownloader_VerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	videoPath := filepath.Join(dir, "video.mp4")
+	if err := os.WriteFile(videoPath, make([]byte, 1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		ffprobe      string
+		metadata     *VideoMetadata
+		expectedSize int64
+		partFile     bool
+		wantOK       bool
+	}{
+		{
+			name:         "complete file",
+			ffprobe:      "120.0",
+			metadata:     &VideoMetadata{ID: "abc", Duration: 120},
+			expectedSize: 1000,
+			wantOK:       true,
+		},
+		{
+			name:         "size within tolerance",
+			ffprobe:      "119.5",
+			metadata:     &VideoMetadata{ID: "abc", Duration: 120},
+			expectedSize: 1050,
+			wantOK:       true,
+		},
+		{
+			name:         "truncated size",
+			ffprobe:      "120.0",
+			metadata:     &VideoMetadata{ID: "abc", Duration: 120},
+			expectedSize: 5000,
+			wantOK:       false,
+		},
+		{
+			name:     "metadata size ignored",
+			ffprobe:  "120.0",
+			metadata: &VideoMetadata{ID: "abc", Duration: 120, FileSize: 5000},
+			wantOK:   true,
+		},
+		{
+			name:     "truncated duration",
+			ffprobe:  "60.0",
+			metadata: &VideoMetadata{ID: "abc", Duration: 120},
+			wantOK:   false,
+		},
+		{
+			name:     "unreadable media",
+			ffprobe:  "N/A",
+			metadata: &VideoMetadata{ID: "abc", Duration: 120},
+			wantOK:   false,
+		},
+		{
+			name:     "leftover part file",
+			ffprobe:  "120.0",
+			metadata: &VideoMetadata{ID: "abc", Duration: 120},
+			partFile: true,
+			wantOK:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Downloader{FFprobePath: writeMockFFprobe(t, t.TempDir(), tt.ffprobe)}
+
+			partPath := videoPath + ".part"
+			os.Remove(partPath)
+			if tt.partFile {
+				if err := os.WriteFile(partPath, []byte("x"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				defer os.Remove(partPath)
+			}
+
+			result, err := d.verifyFile(context.Background(), videoPath, tt.metadata, tt.expectedSize)
+			if err != nil {
+				t.Fatalf("verifyFile() error = %v", err)
+			}
+			if result.OK() != tt.wantOK {
+				t.Errorf("verifyFile() OK = %v, want %v (problems: %v)", result.OK(), tt.wantOK, result.Problems)
+			}
+		})
+	}
+}
+
+func TestDownloader_VerifyFile_NoFFprobe(t *testing.T) {
+	dir := t.TempDir()
+	videoPath := filepath.Join(dir, "video.mp4")
+	if err := os.WriteFile(videoPath, make([]byte, 1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &Downloader{
+		FFprobePath: filepath.Join(dir, "missing-ffprobe"),
+		FFmpegPath:  filepath.Join(dir, "missing-ffmpeg"),
+	}
+	result, err := d.verifyFile(context.Background(), videoPath, &VideoMetadata{ID: "abc", Duration: 120}, 0)
+	if err != nil {
+		t.Fatalf("verifyFile() error = %v", err)
+	}
+	if !result.OK() {
+		t.Errorf("verifyFile() without ffprobe problems = %v, want none", result.Problems)
+	}
+}
+
+func TestDownloader_Verify_MetadataFetcher(t *testing.T) {
+	dir := t.TempDir()
+	videoPath := filepath.Join(dir, "video.mp4")
+	if err := os.WriteFile(videoPath, make([]byte, 1000), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var fetched string
+	d := &Downloader{
+		// yt-dlp is never run when Metadata is set
+		YtdlpPath:   filepath.Join(dir, "missing-yt-dlp"),
+		FFprobePath: filepath.Join(dir, "missing-ffprobe"),
+		FFmpegPath:  filepath.Join(dir, "missing-ffmpeg"),
+		Metadata: func(ctx context.Context, videoID string) (*VideoMetadata, error) {
+			fetched = videoID
+			return &VideoMetadata{ID: videoID, Duration: 120}, nil
+		},
+	}
+	result, err := d.Verify(context.Background(), videoPath, "abc")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if fetched != "abc" || result.VideoID != "abc" {
+		t.Errorf("Verify() fetched %q, result %+v, want metadata of abc from Metadata", fetched, result)
+	}
+}
+
+func TestDownloader_VerifyFile_Missing(t *testing.T) {
+	d := NewDownloader()
+	_, err := d.verifyFile(context.Background(), filepath.Join(t.TempDir(), "nope.mp4"), &VideoMetadata{ID: "abc"}, 0)
+	if err == nil {
+		t.Error("verifyFile() on missing file error = nil, want error")
+	}
+}
+
+func TestDownloader_Download_Verify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mock yt-dlp requires a POSIX shell")
+	}
+
+	tests := []struct {
+		name        string
+		printedSize string
+		restart     bool
+		wantOK      bool
+		wantSize    int64
+	}{
+		{name: "size of selected formats", printedSize: "1000", wantOK: true, wantSize: 1000},
+		{name: "truncated", printedSize: "5000", wantOK: false, wantSize: 5000},
+		{name: "unknown size", printedSize: "NA", restart: true, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			outputDir := filepath.Join(dir, "output")
+			argsPath := filepath.Join(dir, "args")
+			// The metadata's filesize is that of yt-dlp's default format,
+			// not the one downloaded, and must not be compared against
+			script := `#!/bin/sh
+for arg in "$@"; do
+    if [ "$arg" = "-J" ]; then
+        echo '{"id": "abc", "title": "Video", "duration": 120, "filesize": 999999}'
+        exit 0
+    fi
+done
+echo "$@" > "` + argsPath + `"
+mkdir -p "` + outputDir + `"
+head -c 1000 /dev/zero > "` + outputDir + `/Video.mp4"
+echo "` + downloadSizePrefix + tt.printedSize + `"
+echo "` + outputDir + `/Video.mp4"
+`
+			ytdlp := filepath.Join(dir, "yt-dlp")
+			if err := os.WriteFile(ytdlp, []byte(script), 0755); err != nil {
+				t.Fatal(err)
+			}
+			d := &Downloader{YtdlpPath: ytdlp, FFprobePath: writeMockFFprobe(t, dir, "120.0")}
+
+			result, err := d.Download(context.Background(), "abc", &DownloadOptions{OutputDir: outputDir, Verify: true, Restart: tt.restart})
+			if tt.wantOK && err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+			if !tt.wantOK && !errors.Is(err, ErrCorruptDownload) {
+				t.Fatalf("Download() error = %v, want ErrCorruptDownload", err)
+			}
+			if result == nil || result.Verification == nil {
+				t.Fatalf("Download() result = %+v, want one with Verification", result)
+			}
+			if result.Verification.ExpectedSize != tt.wantSize {
+				t.Errorf("ExpectedSize = %d, want %d", result.Verification.ExpectedSize, tt.wantSize)
+			}
+
+			args, err := os.ReadFile(argsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(args), "--no-continue"); got != tt.restart {
+				t.Errorf("yt-dlp args %q: --no-continue = %v, want %v", args, got, tt.restart)
+			}
+		})
+	}
+}
+
+func TestVerifyError_Unwrap(t *testing.T) {
+	err := &VerifyError{Result: &VerifyResult{Path: "a.mp4", VideoID: "abc", Problems: []string{"file is empty"}}}
+	if !errors.Is(err, ErrCorruptDownload) {
+		t.Error("errors.Is(VerifyError, ErrCorruptDownload) = false, want true")
+	}
+}
+
+func TestParseMetadata_FileSize(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want int64
+	}{
+		{"exact", `{"id":"a","title":"t","filesize":1234}`, 1234},
+		{"approx", `{"id":"a","title":"t","filesize_approx":5678}`, 5678},
+		{"exact preferred", `{"id":"a","title":"t","filesize":1234,"filesize_approx":5678}`, 1234},
+		{"unknown", `{"id":"a","title":"t"}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := parseMetadata([]byte(tt.json))
+			if err != nil {
+				t.Fatalf("parseMetadata() error = %v", err)
+			}
+			if m.FileSize != tt.want {
+				t.Errorf("FileSize = %d, want %d", m.FileSize, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"ytsync/errcode"
+	ythttp "ytsync/http"
+	"ytsync/storage"
+	"ytsync/tags"
+)
+
+// Enrichment stage names, used as keys in EnrichResult.Errors.
+const (
+	// StageMetadata fetches full video metadata.
+	StageMetadata = "metadata"
+	// StageTranscript fetches the video transcript.
+	StageTranscript = "transcript"
+	// StageSegments fetches the video's SponsorBlock skip segments.
+	StageSegments = "segments"
+	// StagePersist writes the video and its transcript to the store.
+	StagePersist = "persist"
+)
+
+// ErrTranscriptDeferred is the transcript stage error for a video whose
+// earlier failures put its next attempt off under
+// EnrichOptions.TranscriptRetry.
+var ErrTranscriptDeferred = errcode.New(errcode.Unavailable, "youtube: transcript retry not due yet")
+
+// DefaultEnrichConcurrency is the number of videos enriched in parallel
+// when EnrichOptions.Concurrency is zero.
+const DefaultEnrichConcurrency = 4
+
+// TranscriptFetcher retrieves the transcript for a single video.
+type TranscriptFetcher func(ctx context.Context, videoID string) (*Transcript, error)
+
+// SegmentFetcher retrieves the skip segments of a single video, such as
+// SponsorBlockClient.FetchSegments.
+type SegmentFetcher func(ctx context.Context, videoID string) ([]SkipSegment, error)
+
+// EnrichOptions configures the enrichment stage that runs on newly listed
+// videos. Metadata and transcripts are fetched concurrently; a failure in
+// one does not prevent the other from being fetched or persisted.
+type EnrichOptions struct {
+	// Metadata fetches video metadata. Nil skips the metadata stage.
+	Metadata MetadataFetcher
+	// Transcripts fetches transcripts. Nil skips the transcript stage.
+	Transcripts TranscriptFetcher
+	// Segments fetches skip segments, which are stored with the video. Nil
+	// skips the segments stage. It does not wait on RateLimiter, since
+	// segments do not come from YouTube.
+	Segments SegmentFetcher
+	// Store receives the enriched videos and transcripts. Nil skips persistence.
+	Store storage.Store
+	// Concurrency is the number of videos enriched in parallel.
+	// Defaults to DefaultEnrichConcurrency.
+	Concurrency int
+	// RateLimiter, if set, is waited on before every metadata and transcript
+	// fetch, so all workers share one request budget.
+	RateLimiter *ythttp.RateLimiter
+	// RecordStats appends the fetched view, like, and comment counts to the
+	// video's stats history when Store implements storage.VideoStatsStore.
+	RecordStats bool
+	// TranscriptRetry, if set and Store implements
+	// storage.TranscriptAttemptStore, skips the transcript stage with
+	// ErrTranscriptDeferred for videos whose earlier attempts failed and
+	// are not due again. Every attempt is recorded in such a store either
+	// way.
+	TranscriptRetry *storage.TranscriptRetryPolicy
+	// TranscriptLanguages are the languages Transcripts asks for, recorded
+	// with failed attempts.
+	TranscriptLanguages []string
+	// OnResult, if set, is called with each video's result as soon as the
+	// video is done, from the goroutine that enriched it, so calls may be
+	// concurrent.
+	OnResult func(*EnrichResult)
+}
+
+// EnrichResult is the outcome of enriching one video.
+type EnrichResult struct {
+	// VideoID is the YouTube video ID.
+	VideoID string
+	// Metadata is the fetched metadata, or nil if the stage failed or was not configured.
+	Metadata *VideoMetadata
+	// Transcript is the fetched transcript, or nil if the stage failed or was not configured.
+	Transcript *Transcript
+	// Segments are the fetched skip segments, or nil if the stage failed or was not configured.
+	Segments []SkipSegment
+	// Errors maps the name of each stage that failed or was skipped to its error.
+	Errors map[string]error
+}
+
+// enrichJob carries one video through the enrichment pipeline. Each stage
+// writes only its own field of result.
+type enrichJob struct {
+	video         VideoInfo
+	channelID     string // internal storage channel ID
+	result        *EnrichResult
+	transcriptErr error // why the transcript stage failed, for persisting
+}
+
+// Enrich fetches metadata and transcripts for videos of the channel with
+// YouTube ID channelID and, if opts.Store is set, persists them. Per-video
+// failures are reported in each EnrichResult rather than returned; the
+// error is non-nil only if enrichment could not start. Each stage runs on
+// ctx tagged with the video ID and the stage name.
+func Enrich(ctx context.Context, channelID string, videos []VideoInfo, opts *EnrichOptions) ([]*EnrichResult, error) {
+	if opts == nil {
+		opts = &EnrichOptions{}
+	}
+
+	p, err := newPipeline(opts.stages()...)
+	if err != nil {
+		return nil, err
+	}
+
+	var channelRecordID string
+	if opts.Store != nil && len(videos) > 0 {
+		channelRecordID, err = ensureChannelRecord(ctx, opts.Store, channelID, videos[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultEnrichConcurrency
+	}
+
+	results := make([]*EnrichResult, len(videos))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, v := range videos {
+		results[i] = &EnrichResult{VideoID: v.ID}
+		job := &enrichJob{video: v, channelID: channelRecordID, result: results[i]}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if opts.OnResult != nil {
+				defer opts.OnResult(job.result)
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				job.result.Errors = map[string]error{StagePersist: ctx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+
+			if errs := p.run(tags.WithVideo(ctx, job.video.ID), job); len(errs) > 0 {
+				job.result.Errors = errs
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// stages builds the enrichment stages for the configured fetchers.
+func (o *EnrichOptions) stages() []stage {
+	var stages []stage
+	var fetched []string
+
+	if o.Metadata != nil {
+		stages = append(stages, stage{
+			name: StageMetadata,
+			run: func(ctx context.Context, job *enrichJob) error {
+				if err := o.wait(ctx, job.video.ID); err != nil {
+					return err
+				}
+				md, err := o.Metadata(ctx, job.video.ID)
+				if err != nil {
+					return err
+				}
+				job.result.Metadata = md
+				return nil
+			},
+		})
+		fetched = append(fetched, StageMetadata)
+	}
+
+	if o.Transcripts != nil {
+		stages = append(stages, stage{
+			name: StageTranscript,
+			run: func(ctx context.Context, job *enrichJob) error {
+				// Not-yet-aired premieres and streams have no captions
+				if job.video.IsUpcoming() {
+					job.transcriptErr = ErrNotYetAired
+					return job.transcriptErr
+				}
+				if job.video.LacksCaptions() {
+					job.transcriptErr = &TranscriptError{VideoID: job.video.ID, Err: ErrNoTranscript}
+					return job.transcriptErr
+				}
+				if due, err := o.transcriptDue(ctx, job.video); err != nil {
+					return err
+				} else if !due {
+					return ErrTranscriptDeferred
+				}
+				if err := o.wait(ctx, job.video.ID); err != nil {
+					return err
+				}
+				transcript, err := o.Transcripts(ctx, job.video.ID)
+				if err != nil {
+					job.transcriptErr = err
+					return err
+				}
+				job.result.Transcript = transcript
+				return nil
+			},
+		})
+		fetched = append(fetched, StageTranscript)
+	}
+
+	if o.Segments != nil {
+		stages = append(stages, stage{
+			name: StageSegments,
+			run: func(ctx context.Context, job *enrichJob) error {
+				segments, err := o.Segments(ctx, job.video.ID)
+				if err != nil {
+					return err
+				}
+				if segments == nil {
+					segments = []SkipSegment{}
+				}
+				job.result.Segments = segments
+				return nil
+			},
+		})
+		fetched = append(fetched, StageSegments)
+	}
+
+	if o.Store != nil {
+		// Persist whatever was fetched, so a metadata failure does not
+		// block storing the transcript (or vice versa)
+		stages = append(stages, stage{
+			name:  StagePersist,
+			after: fetched,
+			run: func(ctx context.Context, job *enrichJob) error {
+				return o.persist(ctx, job)
+			},
+		})
+	}
+
+	return stages
+}
+
+// transcriptDue reports whether info is due a transcript attempt under
+// o.TranscriptRetry.
+func (o *EnrichOptions) transcriptDue(ctx context.Context, info VideoInfo) (bool, error) {
+	attempts, ok := o.Store.(storage.TranscriptAttemptStore)
+	if o.TranscriptRetry == nil || !ok {
+		return true, nil
+	}
+	video, err := o.Store.GetVideoByYouTubeID(ctx, info.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("look up video %s: %w", info.ID, err)
+	}
+	history, err := attempts.ListTranscriptAttempts(ctx, video.ID)
+	if err != nil {
+		return false, fmt.Errorf("list transcript attempts for %s: %w", info.ID, err)
+	}
+	published := video.PublishedAt
+	if published.IsZero() {
+		published = info.Published
+	}
+	return o.TranscriptRetry.DueFor(published, history, time.Now()), nil
+}
+
+// wait blocks on the shared rate limiter, if any, before a fetch for videoID.
+func (o *EnrichOptions) wait(ctx context.Context, videoID string) error {
+	if o.RateLimiter == nil {
+		return nil
+	}
+	return o.RateLimiter.Wait(ctx, "https://www.youtube.com/watch?v="+videoID)
+}
+
+// ensureChannelRecord returns the internal ID of the stored channel with
+// YouTube ID channelID, creating the record from sample if it is missing.
+func ensureChannelRecord(ctx context.Context, store storage.ChannelStore, channelID string, sample VideoInfo) (string, error) {
+	channel, err := store.GetChannelByYouTubeID(ctx, channelID)
+	if err == nil {
+		return channel.ID, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return "", fmt.Errorf("look up channel %s: %w", channelID, err)
+	}
+
+	channel = &storage.Channel{
+		YouTubeID: channelID,
+		Name:      sample.ChannelName,
+		URL:       "https://www.youtube.com/channel/" + channelID,
+	}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		return "", fmt.Errorf("create channel %s: %w", channelID, err)
+	}
+	return channel.ID, nil
+}
+
+// persist creates or updates the video record from the listing and any
+// fetched metadata and skip segments, then stores the transcript if one was
+// fetched. With
+// RecordStats set, the metadata's counters are also added to the video's
+// stats history, and a store that keeps transcript attempts records this
+// one.
+func (o *EnrichOptions) persist(ctx context.Context, job *enrichJob) error {
+	store := o.Store
+	info := job.video
+	video := &storage.Video{YouTubeID: info.ID, ChannelID: job.channelID}
+	existing, err := store.GetVideoByYouTubeID(ctx, info.ID)
+	switch {
+	case err == nil:
+		copied := *existing
+		video = &copied
+	case !errors.Is(err, storage.ErrNotFound):
+		return fmt.Errorf("look up video %s: %w", info.ID, err)
+	}
+
+	video.Title = info.Title
+	video.Description = info.Description
+	video.PublishedAt = info.Published
+	video.Duration = int(info.Duration.Seconds())
+	if md := job.result.Metadata; md != nil {
+		video.Title = md.Title
+		video.Description = md.Description
+		video.Duration = md.Duration
+	}
+	if job.result.Segments != nil {
+		video.SkipSegments = SegmentsToStorage(job.result.Segments)
+	}
+	switch {
+	case job.result.Transcript != nil:
+		video.TranscriptFailure = nil
+	case job.transcriptErr != nil && ctx.Err() == nil:
+		video.TranscriptFailure = storage.NewTranscriptFailure(info.ID, job.transcriptErr)
+	}
+
+	if existing == nil {
+		err = store.CreateVideo(ctx, video)
+	} else {
+		err = store.UpdateVideo(ctx, video)
+	}
+	if err != nil {
+		return fmt.Errorf("save video %s: %w", info.ID, err)
+	}
+
+	if statsStore, ok := store.(storage.VideoStatsStore); ok && o.RecordStats && job.result.Metadata != nil {
+		if err := statsStore.RecordVideoStats(ctx, StatsFromMetadata(video.ID, job.result.Metadata)); err != nil {
+			return fmt.Errorf("save stats for %s: %w", info.ID, err)
+		}
+	}
+
+	if attempt := o.transcriptAttempt(ctx, video.ID, job); attempt != nil {
+		if attemptStore, ok := store.(storage.TranscriptAttemptStore); ok {
+			if err := attemptStore.RecordTranscriptAttempt(ctx, attempt); err != nil {
+				return fmt.Errorf("save transcript attempt for %s: %w", info.ID, err)
+			}
+		}
+	}
+
+	if job.result.Transcript == nil {
+		return nil
+	}
+	transcript := job.result.Transcript.ToStorage(video.ID)
+	err = store.CreateTranscript(ctx, transcript)
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		err = store.UpdateTranscript(ctx, transcript)
+	}
+	if err != nil {
+		return fmt.Errorf("save transcript for %s: %w", info.ID, err)
+	}
+	return nil
+}
+
+// transcriptAttempt returns the record of the job's transcript attempt for
+// the stored video with internal ID videoID, or nil if no attempt was made.
+func (o *EnrichOptions) transcriptAttempt(ctx context.Context, videoID string, job *enrichJob) *storage.TranscriptAttempt {
+	if t := job.result.Transcript; t != nil {
+		return &storage.TranscriptAttempt{
+			VideoID:  videoID,
+			At:       time.Now(),
+			Language: t.Language,
+			Outcome:  storage.TranscriptSucceeded,
+		}
+	}
+	err := job.transcriptErr
+	// A video that has not aired yet has not failed to have captions
+	if err == nil || errors.Is(err, ErrTranscriptDeferred) || errors.Is(err, ErrNotYetAired) || ctx.Err() != nil {
+		return nil
+	}
+	return &storage.TranscriptAttempt{
+		VideoID:  videoID,
+		At:       time.Now(),
+		Language: strings.Join(o.TranscriptLanguages, ","),
+		Outcome:  storage.TranscriptFailed,
+		Code:     errcode.Of(err).String(),
+		Error:    err.Error(),
+	}
+}
@@ -0,0 +1,241 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+	"ytsync/storage"
+)
+
+func newEnrichTestStore(t *testing.T) *storage.JSONStore {
+	t.Helper()
+	store, err := storage.NewJSONStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestEnrich_FailSoft(t *testing.T) {
+	store := newEnrichTestStore(t)
+	ctx := context.Background()
+
+	videos := []VideoInfo{
+		{ID: "vid1", Title: "Listed 1", ChannelName: "Test Channel"},
+		{ID: "vid2", Title: "Listed 2"},
+	}
+	opts := &EnrichOptions{
+		Metadata: func(ctx context.Context, videoID string) (*VideoMetadata, error) {
+			if videoID == "vid1" {
+				return nil, ErrNetworkTimeout
+			}
+			return &VideoMetadata{ID: videoID, Title: "Full Title", Duration: 120}, nil
+		},
+		Transcripts: func(ctx context.Context, videoID string) (*Transcript, error) {
+			if videoID == "vid2" {
+				return nil, ErrNoTranscript
+			}
+			return &Transcript{VideoID: videoID, Language: "en", Entries: []TranscriptEntry{{Text: "hello"}}}, nil
+		},
+		Store: store,
+	}
+
+	results, err := Enrich(ctx, "UCtest", videos, opts)
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Enrich() returned %d results, want 2", len(results))
+	}
+
+	// vid1: metadata failed, transcript still persisted
+	if !errors.Is(results[0].Errors[StageMetadata], ErrNetworkTimeout) {
+		t.Errorf("vid1 metadata error = %v, want ErrNetworkTimeout", results[0].Errors[StageMetadata])
+	}
+	if results[0].Errors[StagePersist] != nil {
+		t.Errorf("vid1 persist error = %v", results[0].Errors[StagePersist])
+	}
+	v1, err := store.GetVideoByYouTubeID(ctx, "vid1")
+	if err != nil {
+		t.Fatalf("GetVideoByYouTubeID(vid1) error = %v", err)
+	}
+	if v1.Title != "Listed 1" {
+		t.Errorf("vid1 title = %q, want listing title", v1.Title)
+	}
+	if tr, err := store.GetTranscript(ctx, v1.ID); err != nil || tr.Content != "hello" {
+		t.Errorf("vid1 transcript = %+v, %v, want stored", tr, err)
+	}
+
+	// vid2: transcript failed, metadata still persisted
+	if !errors.Is(results[1].Errors[StageTranscript], ErrNoTranscript) {
+		t.Errorf("vid2 transcript error = %v, want ErrNoTranscript", results[1].Errors[StageTranscript])
+	}
+	v2, err := store.GetVideoByYouTubeID(ctx, "vid2")
+	if err != nil {
+		t.Fatalf("GetVideoByYouTubeID(vid2) error = %v", err)
+	}
+	if v2.Title != "Full Title" || v2.Duration != 120 || v2.HasTranscript {
+		t.Errorf("vid2 = %+v, want metadata title and no transcript", v2)
+	}
+	if f := v2.TranscriptFailure; f == nil || f.Code != "not_found" || f.At.IsZero() {
+		t.Errorf("vid2 transcript failure = %+v, want a recorded not_found", f)
+	}
+	if v1.TranscriptFailure != nil {
+		t.Errorf("vid1 transcript failure = %+v, want none", v1.TranscriptFailure)
+	}
+
+	channel, err := store.GetChannelByYouTubeID(ctx, "UCtest")
+	if err != nil {
+		t.Fatalf("GetChannelByYouTubeID() error = %v", err)
+	}
+	if channel.Name != "Test Channel" || v1.ChannelID != channel.ID {
+		t.Errorf("channel = %+v, video channel = %q", channel, v1.ChannelID)
+	}
+}
+
+func TestEnrich_Segments(t *testing.T) {
+	store := newEnrichTestStore(t)
+	ctx := context.Background()
+
+	videos := []VideoInfo{{ID: "vid1", Title: "Sponsored"}, {ID: "vid2", Title: "Unsponsored"}}
+	results, err := Enrich(ctx, "UCtest", videos, &EnrichOptions{
+		Segments: func(ctx context.Context, videoID string) ([]SkipSegment, error) {
+			if videoID == "vid2" {
+				return []SkipSegment{}, nil
+			}
+			return []SkipSegment{{UUID: "a", Category: SegmentSponsor, Start: 30, End: 75}}, nil
+		},
+		Store: store,
+	})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	for _, r := range results {
+		if len(r.Errors) > 0 {
+			t.Errorf("%s errors = %v", r.VideoID, r.Errors)
+		}
+	}
+
+	v1, err := store.GetVideoByYouTubeID(ctx, "vid1")
+	if err != nil {
+		t.Fatalf("GetVideoByYouTubeID(vid1) error = %v", err)
+	}
+	if len(v1.SkipSegments) != 1 || v1.SkipSegments[0].Category != "sponsor" || v1.SkipSegments[0].End != 75 {
+		t.Errorf("vid1 skip segments = %+v, want the sponsor segment", v1.SkipSegments)
+	}
+	v2, err := store.GetVideoByYouTubeID(ctx, "vid2")
+	if err != nil {
+		t.Fatalf("GetVideoByYouTubeID(vid2) error = %v", err)
+	}
+	if len(v2.SkipSegments) != 0 {
+		t.Errorf("vid2 skip segments = %+v, want none", v2.SkipSegments)
+	}
+}
+
+func TestEnrich_Concurrency(t *testing.T) {
+	var active, peak int32
+	fetch := func(ctx context.Context, videoID string) (*VideoMetadata, error) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return &VideoMetadata{ID: videoID}, nil
+	}
+
+	videos := make([]VideoInfo, 8)
+	for i := range videos {
+		videos[i] = VideoInfo{ID: string(rune('a' + i))}
+	}
+
+	results, err := Enrich(context.Background(), "UCtest", videos, &EnrichOptions{Metadata: fetch, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	for _, r := range results {
+		if r.Metadata == nil || len(r.Errors) != 0 {
+			t.Errorf("result %s = %+v, want metadata and no errors", r.VideoID, r)
+		}
+	}
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", peak)
+	}
+}
+
+func TestEnrich_SkipsVideosWithoutCaptions(t *testing.T) {
+	var fetched []string
+	opts := &EnrichOptions{
+		Transcripts: func(ctx context.Context, videoID string) (*Transcript, error) {
+			fetched = append(fetched, videoID)
+			return &Transcript{VideoID: videoID}, nil
+		},
+		Concurrency: 1,
+	}
+	videos := []VideoInfo{
+		{ID: "captioned", CaptionsProbed: true, HasCaptions: true, CaptionLanguages: []string{"en"}},
+		{ID: "uncaptioned", CaptionsProbed: true},
+		{ID: "unprobed"},
+	}
+
+	results, err := Enrich(context.Background(), "UCtest", videos, opts)
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if !errors.Is(results[1].Errors[StageTranscript], ErrNoTranscript) {
+		t.Errorf("uncaptioned transcript error = %v, want ErrNoTranscript", results[1].Errors[StageTranscript])
+	}
+	if len(fetched) != 2 || fetched[0] == "uncaptioned" || fetched[1] == "uncaptioned" {
+		t.Errorf("fetched transcripts of %v, want the captioned and unprobed videos", fetched)
+	}
+}
+
+func TestEnrich_TranscriptRetry(t *testing.T) {
+	store := newEnrichTestStore(t)
+	ctx := context.Background()
+
+	var fetches int
+	opts := &EnrichOptions{
+		Transcripts: func(ctx context.Context, videoID string) (*Transcript, error) {
+			fetches++
+			return nil, &TranscriptError{VideoID: videoID, Err: ErrNoTranscript}
+		},
+		Store:               store,
+		TranscriptRetry:     &storage.TranscriptRetryPolicy{RetryAfter: time.Hour, MaxAttempts: 2},
+		TranscriptLanguages: []string{"de", "en"},
+	}
+	videos := []VideoInfo{{ID: "vid1", Title: "Video"}}
+
+	if _, err := Enrich(ctx, "UCtest", videos, opts); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	results, err := Enrich(ctx, "UCtest", videos, opts)
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if !errors.Is(results[0].Errors[StageTranscript], ErrTranscriptDeferred) {
+		t.Errorf("second transcript error = %v, want ErrTranscriptDeferred", results[0].Errors[StageTranscript])
+	}
+	if fetches != 1 {
+		t.Errorf("transcript fetched %d times, want once before the retry is due", fetches)
+	}
+
+	video, err := store.GetVideoByYouTubeID(ctx, "vid1")
+	if err != nil {
+		t.Fatalf("GetVideoByYouTubeID() error = %v", err)
+	}
+	attempts, err := store.ListTranscriptAttempts(ctx, video.ID)
+	if err != nil {
+		t.Fatalf("ListTranscriptAttempts() error = %v", err)
+	}
+	if len(attempts) != 1 || attempts[0].Outcome != storage.TranscriptFailed || attempts[0].Code != "not_found" || attempts[0].Language != "de,en" {
+		t.Errorf("attempts = %+v, want one not_found failure for de,en", attempts)
+	}
+}
@@ -0,0 +1,438 @@
+package youtube
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"ytsync/errcode"
+)
+
+var (
+	// ErrInvalidFormatRule is returned when a format selector rule cannot be parsed.
+	ErrInvalidFormatRule = errcode.New(errcode.InvalidInput, "youtube: invalid format rule")
+	// ErrNoMatchingFormat is returned when no available format satisfies a selector.
+	ErrNoMatchingFormat = errcode.New(errcode.NotFound, "youtube: no format matches selector")
+)
+
+// MediaFormat is one audio/video format offered for a video.
+type MediaFormat struct {
+	// ID is the yt-dlp format ID, which is the itag for YouTube formats.
+	ID string `json:"id"`
+	// Ext is the container extension, e.g. "mp4", "m4a", or "webm".
+	Ext string `json:"ext"`
+	// Width and Height are the video dimensions; zero for audio-only formats.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// FPS is the video frame rate; zero for audio-only formats.
+	FPS float64 `json:"fps,omitempty"`
+	// VideoCodec is the video codec, e.g. "avc1.640028"; empty if the format has no video.
+	VideoCodec string `json:"vcodec,omitempty"`
+	// AudioCodec is the audio codec, e.g. "opus"; empty if the format has no audio.
+	AudioCodec string `json:"acodec,omitempty"`
+	// Bitrate is the total bitrate in kbit/s.
+	Bitrate float64 `json:"tbr,omitempty"`
+	// Filesize is the size in bytes, exact or approximate. Zero if unknown.
+	Filesize int64 `json:"filesize,omitempty"`
+	// Note is yt-dlp's description of the format, e.g. "1080p" or "medium".
+	Note string `json:"note,omitempty"`
+	// Language is the language of the format's audio, e.g. "en-US", if
+	// known.
+	Language string `json:"language,omitempty"`
+	// AudioTrack is the name of the format's audio track in videos with
+	// several, e.g. "English (United States) original".
+	AudioTrack string `json:"audio_track,omitempty"`
+	// DefaultAudio reports whether the audio track is the one YouTube
+	// plays by default.
+	DefaultAudio bool `json:"default_audio,omitempty"`
+	// OriginalAudio reports whether the audio track is in the video's
+	// original language rather than dubbed.
+	OriginalAudio bool `json:"original_audio,omitempty"`
+}
+
+// HasVideo reports whether the format contains video.
+func (f *MediaFormat) HasVideo() bool { return f.VideoCodec != "" }
+
+// HasAudio reports whether the format contains audio.
+func (f *MediaFormat) HasAudio() bool { return f.AudioCodec != "" }
+
+// AudioOnly reports whether the format contains audio and no video.
+func (f *MediaFormat) AudioOnly() bool { return f.HasAudio() && !f.HasVideo() }
+
+// VideoOnly reports whether the format contains video and no audio.
+func (f *MediaFormat) VideoOnly() bool { return f.HasVideo() && !f.HasAudio() }
+
+// MediaFormat converts a resolved stream to a MediaFormat, so streams from
+// innertube.StreamResolver can be chosen with a FormatSelector.
+func (f *StreamFormat) MediaFormat() MediaFormat {
+	mf := MediaFormat{
+		ID:       strconv.Itoa(f.Itag),
+		Width:    f.Width,
+		Height:   f.Height,
+		FPS:      float64(f.FPS),
+		Bitrate:  float64(f.Bitrate) / 1000,
+		Filesize: f.ContentLength,
+		Note:     f.QualityLabel,
+
+		Language:      f.AudioLanguage,
+		AudioTrack:    f.AudioTrackName,
+		DefaultAudio:  f.DefaultAudio,
+		OriginalAudio: f.OriginalAudio,
+	}
+	_, mf.Ext, _ = strings.Cut(f.MimeType, "/")
+	if f.MimeType == "audio/mp4" {
+		mf.Ext = "m4a"
+	}
+
+	codecs := strings.Split(f.Codecs, ",")
+	for i := range codecs {
+		codecs[i] = strings.TrimSpace(codecs[i])
+	}
+	switch {
+	case !f.Adaptive && len(codecs) == 2:
+		mf.VideoCodec, mf.AudioCodec = codecs[0], codecs[1]
+	case f.HasVideo():
+		mf.VideoCodec = codecs[0]
+	default:
+		mf.AudioCodec = codecs[0]
+	}
+	return mf
+}
+
+// parseMediaFormats converts the formats list of yt-dlp's -J output.
+// Formats with neither audio nor video, such as storyboards, are skipped.
+func parseMediaFormats(raw []interface{}) []MediaFormat {
+	formats := make([]MediaFormat, 0, len(raw))
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		str := func(key string) string {
+			s, _ := m[key].(string)
+			if s == "none" {
+				return ""
+			}
+			return s
+		}
+		num := func(key string) float64 {
+			n, _ := m[key].(float64)
+			return n
+		}
+
+		f := MediaFormat{
+			ID:         str("format_id"),
+			Ext:        str("ext"),
+			Width:      int(num("width")),
+			Height:     int(num("height")),
+			FPS:        num("fps"),
+			VideoCodec: str("vcodec"),
+			AudioCodec: str("acodec"),
+			Bitrate:    num("tbr"),
+			Note:       str("format_note"),
+			Language:   str("language"),
+		}
+		// yt-dlp ranks the original track 10 and the default 5, and names
+		// the track in the note of videos with several
+		if f.Language != "" {
+			var noteDefault bool
+			f.AudioTrack, noteDefault = parseAudioTrackNote(f.Note)
+			preference := num("language_preference")
+			f.OriginalAudio = preference >= 10 || strings.Contains(f.AudioTrack, "original")
+			f.DefaultAudio = preference > 0 || noteDefault
+		}
+		if size := num("filesize"); size > 0 {
+			f.Filesize = int64(size)
+		} else {
+			f.Filesize = int64(num("filesize_approx"))
+		}
+		if f.ID == "" || (!f.HasVideo() && !f.HasAudio()) {
+			continue
+		}
+		formats = append(formats, f)
+	}
+	return formats
+}
+
+// formatTerm is one condition of a format rule.
+type formatTerm struct {
+	// audio reports whether the term applies to the audio stream rather
+	// than the video stream.
+	audio bool
+	match func(f *MediaFormat) bool
+}
+
+// FormatSelector picks formats with a rule string of space-separated terms:
+//
+//	best, worst        pick the highest (default) or lowest quality
+//	audio              pick an audio-only format instead of video
+//	mp4, m4a, webm     require a container
+//	<=1080p, >=720p    bound the resolution; 720p requires it exactly
+//	60fps, <=30fps     bound the frame rate
+//	avc1, vp9, av01    require a video codec (h264 and av1 are aliases)
+//	opus, mp4a         require an audio codec (aac is an alias)
+//
+// A term followed by "preferred" is a preference instead of a
+// requirement: formats meeting more preferences rank higher, but formats
+// meeting none are still eligible. For example "best audio m4a" picks the
+// best M4A audio, and "<=1080p avc1 preferred" picks the best format up to
+// 1080p, favoring H.264.
+//
+// Video rules pick a video format and, if it has no audio, the best audio
+// format to merge with it, favoring a matching container. If there is no
+// audio to merge with, the best format carrying both is picked instead.
+type FormatSelector struct {
+	rule      string
+	worst     bool
+	audioOnly bool
+	required  []formatTerm
+	preferred []formatTerm
+}
+
+// videoCodecs and audioCodecs map codec names accepted in rules to the
+// codec string prefix yt-dlp reports.
+var (
+	videoCodecs = map[string]string{"avc1": "avc1", "h264": "avc1", "vp9": "vp", "vp09": "vp", "av01": "av01", "av1": "av01"}
+	audioCodecs = map[string]string{"opus": "opus", "mp4a": "mp4a", "aac": "mp4a"}
+	containers  = map[string]bool{"mp4": true, "m4a": true, "webm": true, "3gp": true}
+)
+
+// ParseFormatSelector parses a format rule. An empty rule selects the best
+// video format.
+func ParseFormatSelector(rule string) (*FormatSelector, error) {
+	s := &FormatSelector{rule: strings.TrimSpace(rule)}
+	fields := strings.Fields(strings.ToLower(rule))
+	for i := 0; i < len(fields); i++ {
+		word := fields[i]
+		switch word {
+		case "best":
+			s.worst = false
+			continue
+		case "worst":
+			s.worst = true
+			continue
+		case "audio":
+			s.audioOnly = true
+			continue
+		case "video":
+			s.audioOnly = false
+			continue
+		case "preferred":
+			return nil, fmt.Errorf("%w: %q must follow a term", ErrInvalidFormatRule, word)
+		}
+
+		term, err := parseFormatTerm(word)
+		if err != nil {
+			return nil, err
+		}
+		if i+1 < len(fields) && fields[i+1] == "preferred" {
+			s.preferred = append(s.preferred, term)
+			i++
+		} else {
+			s.required = append(s.required, term)
+		}
+	}
+	return s, nil
+}
+
+// parseFormatTerm parses a container, codec, resolution, or frame rate term.
+func parseFormatTerm(word string) (formatTerm, error) {
+	var t formatTerm
+	switch {
+	case containers[word]:
+		t.audio = word == "m4a"
+		t.match = func(f *MediaFormat) bool { return f.Ext == word }
+		return t, nil
+	case videoCodecs[word] != "":
+		prefix := videoCodecs[word]
+		t.match = func(f *MediaFormat) bool { return strings.HasPrefix(f.VideoCodec, prefix) }
+		return t, nil
+	case audioCodecs[word] != "":
+		prefix := audioCodecs[word]
+		t.audio = true
+		t.match = func(f *MediaFormat) bool { return strings.HasPrefix(f.AudioCodec, prefix) }
+		return t, nil
+	}
+
+	op, rest := "=", word
+	for _, candidate := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(word, candidate) {
+			op, rest = candidate, word[len(candidate):]
+			break
+		}
+	}
+	var field func(f *MediaFormat) float64
+	switch {
+	case strings.HasSuffix(rest, "fps"):
+		rest = strings.TrimSuffix(rest, "fps")
+		field = func(f *MediaFormat) float64 { return f.FPS }
+	case strings.HasSuffix(rest, "p"):
+		rest = strings.TrimSuffix(rest, "p")
+		field = func(f *MediaFormat) float64 { return float64(f.Height) }
+	default:
+		return t, fmt.Errorf("%w: unknown term %q", ErrInvalidFormatRule, word)
+	}
+	limit, err := strconv.ParseFloat(rest, 64)
+	if err != nil || limit <= 0 {
+		return t, fmt.Errorf("%w: bad number in %q", ErrInvalidFormatRule, word)
+	}
+
+	t.match = func(f *MediaFormat) bool {
+		v := field(f)
+		switch op {
+		case "<=":
+			return v <= limit
+		case ">=":
+			return v >= limit
+		case "<":
+			return v < limit
+		case ">":
+			return v > limit
+		}
+		return v == limit
+	}
+	return t, nil
+}
+
+// String returns the rule the selector was parsed from.
+func (s *FormatSelector) String() string {
+	return s.rule
+}
+
+// FormatSelection is the result of a FormatSelector.
+type FormatSelection struct {
+	// Video is the chosen video format, or nil for audio rules. It may
+	// carry audio itself.
+	Video *MediaFormat
+	// Audio is the chosen audio-only format, or nil if Video already
+	// carries audio.
+	Audio *MediaFormat
+}
+
+// FormatID returns the yt-dlp format specification for the selection, such
+// as "137+140" for merged formats or "18" for a single one.
+func (s *FormatSelection) FormatID() string {
+	switch {
+	case s.Video != nil && s.Audio != nil:
+		return s.Video.ID + "+" + s.Audio.ID
+	case s.Video != nil:
+		return s.Video.ID
+	case s.Audio != nil:
+		return s.Audio.ID
+	}
+	return ""
+}
+
+// Select picks formats from formats according to the rule. It returns
+// ErrNoMatchingFormat if no format meets the rule's requirements.
+func (s *FormatSelector) Select(formats []MediaFormat) (*FormatSelection, error) {
+	if s.audioOnly {
+		audio := s.pick(formats, true, (*MediaFormat).AudioOnly, "")
+		if audio == nil {
+			return nil, fmt.Errorf("%w: %q", ErrNoMatchingFormat, s.rule)
+		}
+		return &FormatSelection{Audio: audio}, nil
+	}
+
+	video := s.pick(formats, false, (*MediaFormat).HasVideo, "")
+	if video == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNoMatchingFormat, s.rule)
+	}
+	sel := &FormatSelection{Video: video}
+	if video.HasAudio() {
+		return sel, nil
+	}
+
+	// Merge with audio in a matching container when there is a choice
+	container := "webm"
+	if video.Ext == "mp4" {
+		container = "m4a"
+	}
+	if sel.Audio = s.pick(formats, true, (*MediaFormat).AudioOnly, container); sel.Audio != nil {
+		return sel, nil
+	}
+
+	// Nothing to merge with; fall back to formats that carry both
+	hasBoth := func(f *MediaFormat) bool { return f.HasVideo() && f.HasAudio() }
+	if muxed := s.pick(formats, false, hasBoth, ""); muxed != nil {
+		return &FormatSelection{Video: muxed}, nil
+	}
+	return nil, fmt.Errorf("%w: %q: no audio to merge with format %s", ErrNoMatchingFormat, s.rule, video.ID)
+}
+
+// pick returns the highest-ranked format accepted by kind that meets the
+// rule's requirements for the audio or video stream. Formats meeting more
+// preferences rank first, then formats in the container ext if given,
+// then by quality.
+func (s *FormatSelector) pick(formats []MediaFormat, audio bool, kind func(*MediaFormat) bool, ext string) *MediaFormat {
+	applies := func(t formatTerm) bool {
+		// Audio rules have a single stream, which every term constrains
+		return s.audioOnly || t.audio == audio
+	}
+
+	type candidate struct {
+		f       *MediaFormat
+		prefs   int
+		sameExt bool
+	}
+	var candidates []candidate
+outer:
+	for i := range formats {
+		f := &formats[i]
+		if !kind(f) {
+			continue
+		}
+		for _, t := range s.required {
+			if applies(t) && !t.match(f) {
+				continue outer
+			}
+		}
+		c := candidate{f: f}
+		for _, t := range s.preferred {
+			if applies(t) && t.match(f) {
+				c.prefs++
+			}
+		}
+		c.sameExt = ext != "" && f.Ext == ext
+		candidates = append(candidates, c)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.prefs != b.prefs {
+			return a.prefs > b.prefs
+		}
+		if a.sameExt != b.sameExt {
+			return a.sameExt
+		}
+		better := compareQuality(a.f, b.f)
+		if s.worst {
+			return better < 0
+		}
+		return better > 0
+	})
+	return candidates[0].f
+}
+
+// compareQuality orders formats by resolution, frame rate, then bitrate.
+// It returns a positive number if a is better than b.
+func compareQuality(a, b *MediaFormat) int {
+	switch {
+	case a.Height != b.Height:
+		return a.Height - b.Height
+	case a.FPS != b.FPS:
+		if a.FPS > b.FPS {
+			return 1
+		}
+		return -1
+	case a.Bitrate != b.Bitrate:
+		if a.Bitrate > b.Bitrate {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
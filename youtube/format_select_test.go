@@ -0,0 +1,112 @@
+package youtube
+
+import (
+	"errors"
+	"testing"
+)
+
+// testFormats is a typical YouTube format matrix as listed by yt-dlp.
+var testFormats = []MediaFormat{
+	{ID: "139", Ext: "m4a", AudioCodec: "mp4a.40.5", Bitrate: 49},
+	{ID: "140", Ext: "m4a", AudioCodec: "mp4a.40.2", Bitrate: 129},
+	{ID: "251", Ext: "webm", AudioCodec: "opus", Bitrate: 135},
+	{ID: "18", Ext: "mp4", Width: 640, Height: 360, FPS: 30, VideoCodec: "avc1.42001E", AudioCodec: "mp4a.40.2", Bitrate: 500},
+	{ID: "136", Ext: "mp4", Width: 1280, Height: 720, FPS: 30, VideoCodec: "avc1.4d401f", Bitrate: 1300},
+	{ID: "247", Ext: "webm", Width: 1280, Height: 720, FPS: 30, VideoCodec: "vp9", Bitrate: 1100},
+	{ID: "137", Ext: "mp4", Width: 1920, Height: 1080, FPS: 30, VideoCodec: "avc1.640028", Bitrate: 4300},
+	{ID: "248", Ext: "webm", Width: 1920, Height: 1080, FPS: 30, VideoCodec: "vp9", Bitrate: 2600},
+	{ID: "303", Ext: "webm", Width: 1920, Height: 1080, FPS: 60, VideoCodec: "vp9", Bitrate: 4400},
+	{ID: "313", Ext: "webm", Width: 3840, Height: 2160, FPS: 30, VideoCodec: "vp9", Bitrate: 17000},
+}
+
+func TestFormatSelectorSelect(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"", "313+251"},
+		{"best", "313+251"},
+		{"best audio m4a", "140"},
+		{"worst audio", "139"},
+		{"audio opus", "251"},
+		{"<=1080p", "303+251"},
+		{"<=1080p avc1 preferred", "137+140"},
+		{"<=1080p avc1 preferred 30fps", "137+140"},
+		{"<=1080p vp9", "303+251"},
+		{"<=720p mp4", "136+140"},
+		{"720p webm aac preferred", "247+140"},
+		{"worst", "18"},
+		{"360p", "18"},
+		{">=1080p 60fps", "303+251"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			s, err := ParseFormatSelector(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseFormatSelector() error = %v", err)
+			}
+			sel, err := s.Select(testFormats)
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			if got := sel.FormatID(); got != tt.want {
+				t.Errorf("FormatID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSelectorNoMatch(t *testing.T) {
+	s, _ := ParseFormatSelector(">=1440p avc1")
+	if _, err := s.Select(testFormats); !errors.Is(err, ErrNoMatchingFormat) {
+		t.Errorf("Select() error = %v, want ErrNoMatchingFormat", err)
+	}
+	s, _ = ParseFormatSelector("mp4")
+	if _, err := s.Select(testFormats[3:]); err != nil {
+		t.Errorf("Select() with muxed fallback error = %v", err)
+	}
+	if _, err := s.Select(testFormats[4:5]); !errors.Is(err, ErrNoMatchingFormat) {
+		t.Errorf("Select() without audio error = %v, want ErrNoMatchingFormat", err)
+	}
+}
+
+func TestParseFormatSelectorInvalid(t *testing.T) {
+	for _, rule := range []string{"preferred mp4", "1080i", "<=p", "flac", "<=-5p"} {
+		if _, err := ParseFormatSelector(rule); !errors.Is(err, ErrInvalidFormatRule) {
+			t.Errorf("ParseFormatSelector(%q) error = %v, want ErrInvalidFormatRule", rule, err)
+		}
+	}
+}
+
+func TestParseMetadataFormats(t *testing.T) {
+	data := []byte(`{"id":"abc","title":"T","formats":[
+		{"format_id":"sb0","ext":"mhtml","vcodec":"none","acodec":"none"},
+		{"format_id":"140","ext":"m4a","vcodec":"none","acodec":"mp4a.40.2","tbr":129.5,"filesize":3400000,"format_note":"medium"},
+		{"format_id":"137","ext":"mp4","vcodec":"avc1.640028","acodec":"none","width":1920,"height":1080,"fps":30,"filesize_approx":52000000}
+	]}`)
+	metadata, err := parseMetadata(data)
+	if err != nil {
+		t.Fatalf("parseMetadata() error = %v", err)
+	}
+	if len(metadata.Formats) != 2 {
+		t.Fatalf("got %d formats, want 2 (storyboard skipped)", len(metadata.Formats))
+	}
+	audio, video := metadata.Formats[0], metadata.Formats[1]
+	if !audio.AudioOnly() || audio.Filesize != 3400000 || audio.Bitrate != 129.5 || audio.Note != "medium" {
+		t.Errorf("audio format = %+v", audio)
+	}
+	if !video.VideoOnly() || video.Height != 1080 || video.Filesize != 52000000 {
+		t.Errorf("video format = %+v", video)
+	}
+}
+
+func TestStreamFormatMediaFormat(t *testing.T) {
+	muxed := (&StreamFormat{Itag: 18, MimeType: "video/mp4", Codecs: "avc1.42001E, mp4a.40.2", Height: 360, Bitrate: 500000}).MediaFormat()
+	if muxed.ID != "18" || muxed.Ext != "mp4" || muxed.VideoCodec != "avc1.42001E" || muxed.AudioCodec != "mp4a.40.2" || muxed.Bitrate != 500 {
+		t.Errorf("muxed = %+v", muxed)
+	}
+	audio := (&StreamFormat{Itag: 140, MimeType: "audio/mp4", Codecs: "mp4a.40.2", Adaptive: true}).MediaFormat()
+	if audio.Ext != "m4a" || !audio.AudioOnly() {
+		t.Errorf("audio = %+v", audio)
+	}
+}
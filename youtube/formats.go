@@ -2,6 +2,7 @@ package youtube
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"html"
 	"regexp"
@@ -234,6 +235,8 @@ func ParseFormat(content string, format Format) ([]TranscriptEntry, error) {
 		return parseSRT(content)
 	case FormatTTML:
 		return parseTTML(content)
+	case FormatSRT3:
+		return parseSRV3(content)
 	case FormatPlainText:
 		return parsePlainText(content)
 	default:
@@ -241,12 +244,13 @@ func ParseFormat(content string, format Format) ([]TranscriptEntry, error) {
 	}
 }
 
-// parseJSON3 parses YouTube's JSON3 format.
+// parseJSON3 parses YouTube's JSON3 format. YouTube sends times as numbers;
+// quoted numbers, as written by ToFormat, are accepted too.
 func parseJSON3(content string) ([]TranscriptEntry, error) {
 	var result struct {
 		Events []struct {
-			TStartMs  string `json:"tStartMs"`
-			DDuration string `json:"dDurationMs"`
+			TStartMs  json.Number `json:"tStartMs"`
+			DDuration json.Number `json:"dDurationMs"`
 			Segs      []struct {
 				UTF8 string `json:"utf8"`
 			} `json:"segs"`
@@ -259,9 +263,8 @@ func parseJSON3(content string) ([]TranscriptEntry, error) {
 
 	var entries []TranscriptEntry
 	for _, event := range result.Events {
-		var startMs, durationMs int64
-		fmt.Sscanf(event.TStartMs, "%d", &startMs)
-		fmt.Sscanf(event.DDuration, "%d", &durationMs)
+		startMs, _ := event.TStartMs.Int64()
+		durationMs, _ := event.DDuration.Int64()
 
 		var text strings.Builder
 		for _, seg := range event.Segs {
@@ -311,19 +314,25 @@ func parseVTT(content string) ([]TranscriptEntry, error) {
 				continue
 			}
 
-			end, err := parseVTTTimestamp(strings.TrimSpace(parts[1]))
+			// Cue settings such as "align:start" may follow the end time
+			endFields := strings.Fields(parts[1])
+			if len(endFields) == 0 {
+				continue
+			}
+			end, err := parseVTTTimestamp(endFields[0])
 			if err != nil {
 				continue
 			}
 
-			// Collect text lines until empty line
+			// Collect text lines until empty line, dropping inline
+			// timestamp and style tags
 			var text strings.Builder
 			i++
 			for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
 				if text.Len() > 0 {
 					text.WriteString(" ")
 				}
-				text.WriteString(strings.TrimSpace(lines[i]))
+				text.WriteString(strings.TrimSpace(html.UnescapeString(vttTagRegex.ReplaceAllString(lines[i], ""))))
 				i++
 			}
 
@@ -338,6 +347,39 @@ func parseVTT(content string) ([]TranscriptEntry, error) {
 	return entries, nil
 }
 
+// vttTagRegex matches WebVTT inline tags, e.g. <00:00:01.200> and <c>.
+var vttTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// parseSRV3 parses YouTube's srv3 XML format. Times are in milliseconds.
+// Auto-generated captions split each line into word <s> elements and add
+// empty paragraphs for line breaks, which are skipped.
+func parseSRV3(content string) ([]TranscriptEntry, error) {
+	var doc struct {
+		Paragraphs []struct {
+			Start    int64  `xml:"t,attr"`
+			Duration int64  `xml:"d,attr"`
+			Inner    string `xml:",innerxml"`
+		} `xml:"body>p"`
+	}
+	if err := xml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("parse srv3: %w", err)
+	}
+
+	var entries []TranscriptEntry
+	for _, p := range doc.Paragraphs {
+		text := strings.TrimSpace(html.UnescapeString(vttTagRegex.ReplaceAllString(p.Inner, "")))
+		if text == "" {
+			continue
+		}
+		entries = append(entries, TranscriptEntry{
+			Start:    float64(p.Start) / 1000.0,
+			Duration: float64(p.Duration) / 1000.0,
+			Text:     text,
+		})
+	}
+	return entries, nil
+}
+
 // parseSRT parses SubRip (SRT) format.
 func parseSRT(content string) ([]TranscriptEntry, error) {
 	// SRT format is similar to VTT, just use comma instead of period
@@ -0,0 +1,223 @@
+package innertube
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"ytsync/tags"
+	"ytsync/youtube"
+)
+
+// aboutParams are the browse params that open a channel's about tab.
+const aboutParams = "EgVhYm91dPIGBAoCEgA="
+
+// AboutResponse is the response to an about tab browse. The tab's data
+// arrives as a continuation item rather than in the page contents.
+type AboutResponse struct {
+	OnResponseReceivedEndpoints []AboutEndpoint  `json:"onResponseReceivedEndpoints,omitempty"`
+	Metadata                    *ChannelMetadata `json:"metadata,omitempty"`
+	Alerts                      []Alert          `json:"alerts,omitempty"`
+}
+
+// AboutEndpoint is one of the actions of an about tab response.
+type AboutEndpoint struct {
+	AppendContinuationItemsAction *struct {
+		ContinuationItems []struct {
+			AboutChannelRenderer *struct {
+				Metadata *struct {
+					AboutChannelViewModel *AboutChannelViewModel `json:"aboutChannelViewModel,omitempty"`
+				} `json:"metadata,omitempty"`
+			} `json:"aboutChannelRenderer,omitempty"`
+		} `json:"continuationItems,omitempty"`
+	} `json:"appendContinuationItemsAction,omitempty"`
+}
+
+// AboutChannelViewModel holds the fields of the about tab.
+type AboutChannelViewModel struct {
+	ChannelID           string          `json:"channelId,omitempty"`
+	Description         string          `json:"description,omitempty"`
+	Country             string          `json:"country,omitempty"`
+	SubscriberCountText string          `json:"subscriberCountText,omitempty"`
+	ViewCountText       string          `json:"viewCountText,omitempty"`
+	VideoCountText      string          `json:"videoCountText,omitempty"`
+	JoinedDateText      *AttributedText `json:"joinedDateText,omitempty"`
+	CanonicalChannelURL string          `json:"canonicalChannelUrl,omitempty"`
+	Links               []struct {
+		ChannelExternalLinkViewModel *ChannelExternalLinkViewModel `json:"channelExternalLinkViewModel,omitempty"`
+	} `json:"links,omitempty"`
+	// SignInForBusinessEmail is shown to signed-out users of channels with
+	// a business email; BusinessEmailRevealButton to signed-in users.
+	SignInForBusinessEmail    *AttributedText `json:"signInForBusinessEmail,omitempty"`
+	BusinessEmailRevealButton any             `json:"businessEmailRevealButton,omitempty"`
+}
+
+// ChannelExternalLinkViewModel is a link on the about tab.
+type ChannelExternalLinkViewModel struct {
+	Title *AttributedText `json:"title,omitempty"`
+	Link  *AttributedText `json:"link,omitempty"`
+}
+
+// AttributedText is view model text, with commands attached to parts of it.
+type AttributedText struct {
+	Content     string `json:"content,omitempty"`
+	CommandRuns []struct {
+		OnTap *struct {
+			InnertubeCommand *struct {
+				URLEndpoint *struct {
+					URL string `json:"url,omitempty"`
+				} `json:"urlEndpoint,omitempty"`
+			} `json:"innertubeCommand,omitempty"`
+		} `json:"onTap,omitempty"`
+	} `json:"commandRuns,omitempty"`
+}
+
+// url returns the target of the first URL command in t, or "".
+func (t *AttributedText) url() string {
+	if t == nil {
+		return ""
+	}
+	for _, run := range t.CommandRuns {
+		if run.OnTap != nil && run.OnTap.InnertubeCommand != nil && run.OnTap.InnertubeCommand.URLEndpoint != nil {
+			return run.OnTap.InnertubeCommand.URLEndpoint.URL
+		}
+	}
+	return ""
+}
+
+// About fetches the about tab of a channel: its links, country, join date,
+// view count, and whether it has a business email. A response without the
+// about data fails with an error matching youtube.ErrSchemaChanged, and one
+// with an error alert with the error AboutResponse.Err returns.
+func (c *Client) About(ctx context.Context, channelID string) (*youtube.ChannelAbout, error) {
+	req := &BrowseRequest{Context: webContext(), BrowseID: channelID, Params: aboutParams}
+	var resp *AboutResponse
+	if err := c.post(tags.Default(ctx, tags.Channel, channelID), browseEndpoint, "browse", req, &resp); err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	about := ExtractAbout(resp)
+	if about == nil {
+		return nil, fmt.Errorf("%w: no about tab data for %s", youtube.ErrSchemaChanged, channelID)
+	}
+	if about.ChannelID == "" {
+		about.ChannelID = channelID
+	}
+	return about, nil
+}
+
+// About resolves channelURL to a channel ID and fetches its about tab with
+// Client.About.
+func (l *Lister) About(ctx context.Context, channelURL string) (*youtube.ChannelAbout, error) {
+	channelID, err := l.resolveChannelID(channelURL)
+	if err != nil {
+		return nil, err
+	}
+	return l.client.About(ctx, channelID)
+}
+
+// ExtractAbout converts the about tab data in resp, or returns nil if it
+// has none.
+func ExtractAbout(resp *AboutResponse) *youtube.ChannelAbout {
+	if resp == nil {
+		return nil
+	}
+	var vm *AboutChannelViewModel
+	for _, ep := range resp.OnResponseReceivedEndpoints {
+		if ep.AppendContinuationItemsAction == nil {
+			continue
+		}
+		for _, item := range ep.AppendContinuationItemsAction.ContinuationItems {
+			if r := item.AboutChannelRenderer; r != nil && r.Metadata != nil && r.Metadata.AboutChannelViewModel != nil {
+				vm = r.Metadata.AboutChannelViewModel
+			}
+		}
+	}
+	if vm == nil {
+		return nil
+	}
+
+	about := &youtube.ChannelAbout{
+		ChannelID:        vm.ChannelID,
+		Description:      vm.Description,
+		Country:          vm.Country,
+		ViewCount:        parseViewCount(vm.ViewCountText),
+		SubscriberCount:  vm.SubscriberCountText,
+		HasBusinessEmail: vm.SignInForBusinessEmail != nil || vm.BusinessEmailRevealButton != nil,
+		Emails:           extractEmails(vm.Description),
+		FetchedAt:        time.Now().UTC(),
+	}
+	if about.ChannelID == "" && resp.Metadata != nil && resp.Metadata.ChannelMetadataRenderer != nil {
+		about.ChannelID = resp.Metadata.ChannelMetadataRenderer.ExternalID
+	}
+	about.VideoCount, _ = parseVideoCount(vm.VideoCountText)
+	if vm.JoinedDateText != nil {
+		about.JoinedAt = parseJoinedDate(vm.JoinedDateText.Content)
+	}
+	for _, l := range vm.Links {
+		link := l.ChannelExternalLinkViewModel
+		if link == nil || link.Link == nil {
+			continue
+		}
+		target := unwrapRedirect(link.Link.url())
+		if target == "" {
+			target = link.Link.Content
+			if target != "" && !strings.Contains(target, "://") {
+				target = "https://" + target
+			}
+		}
+		title := ""
+		if link.Title != nil {
+			title = link.Title.Content
+		}
+		if target != "" {
+			about.Links = append(about.Links, youtube.ChannelLink{Title: title, URL: target})
+		}
+	}
+	return about
+}
+
+// parseJoinedDate parses "Joined Jan 2, 2006", or returns the zero time.
+func parseJoinedDate(s string) time.Time {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "Joined"))
+	t, err := time.Parse("Jan 2, 2006", s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// unwrapRedirect returns the target of a youtube.com/redirect link, or u
+// unchanged.
+func unwrapRedirect(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || !strings.HasSuffix(parsed.Host, "youtube.com") || parsed.Path != "/redirect" {
+		return u
+	}
+	if q := parsed.Query().Get("q"); q != "" {
+		return q
+	}
+	return u
+}
+
+// emailRegex matches email addresses in free text.
+var emailRegex = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+
+// extractEmails returns the distinct email addresses in text, in order.
+func extractEmails(text string) []string {
+	var emails []string
+	seen := make(map[string]bool)
+	for _, e := range emailRegex.FindAllString(text, -1) {
+		key := strings.ToLower(e)
+		if !seen[key] {
+			seen[key] = true
+			emails = append(emails, e)
+		}
+	}
+	return emails
+}
@@ -0,0 +1,127 @@
+package innertube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	ythttp "ytsync/http"
+	"ytsync/youtube"
+)
+
+const testAboutResponse = `{
+  "onResponseReceivedEndpoints": [{"appendContinuationItemsAction": {"continuationItems": [
+    {"aboutChannelRenderer": {"metadata": {"aboutChannelViewModel": {
+      "description": "Weekly builds. Sponsorships: deals@example.com\nFan mail: Deals@Example.com or fans@mail.example.org",
+      "country": "Canada",
+      "subscriberCountText": "1.2M subscribers",
+      "viewCountText": "123,456,789 views",
+      "videoCountText": "1,024 videos",
+      "joinedDateText": {"content": "Joined Mar 4, 2011"},
+      "canonicalChannelUrl": "http://www.youtube.com/@builds",
+      "signInForBusinessEmail": {"content": "Sign in to see email address"},
+      "links": [
+        {"channelExternalLinkViewModel": {
+          "title": {"content": "Twitter"},
+          "link": {"content": "twitter.com/builds", "commandRuns": [{"onTap": {"innertubeCommand": {"urlEndpoint": {"url": "https://www.youtube.com/redirect?event=channel_description&q=https%3A%2F%2Ftwitter.com%2Fbuilds"}}}}]}
+        }},
+        {"channelExternalLinkViewModel": {"title": {"content": "Shop"}, "link": {"content": "shop.example.com"}}},
+        {"channelExternalLinkViewModel": {"title": {"content": "Empty"}}}
+      ]
+    }}}}
+  ]}}],
+  "metadata": {"channelMetadataRenderer": {"externalId": "UCbuilds"}}
+}`
+
+func TestExtractAbout(t *testing.T) {
+	var resp AboutResponse
+	if err := json.Unmarshal([]byte(testAboutResponse), &resp); err != nil {
+		t.Fatal(err)
+	}
+	about := ExtractAbout(&resp)
+	if about == nil {
+		t.Fatal("ExtractAbout() = nil")
+	}
+	if about.ChannelID != "UCbuilds" || about.Country != "Canada" || about.SubscriberCount != "1.2M subscribers" {
+		t.Errorf("about = %+v", about)
+	}
+	if about.ViewCount != 123456789 || about.VideoCount != 1024 {
+		t.Errorf("ViewCount, VideoCount = %d, %d; want 123456789, 1024", about.ViewCount, about.VideoCount)
+	}
+	if want := time.Date(2011, 3, 4, 0, 0, 0, 0, time.UTC); !about.JoinedAt.Equal(want) {
+		t.Errorf("JoinedAt = %v, want %v", about.JoinedAt, want)
+	}
+	if !about.HasBusinessEmail {
+		t.Error("HasBusinessEmail = false")
+	}
+	if want := []string{"deals@example.com", "fans@mail.example.org"}; !reflect.DeepEqual(about.Emails, want) {
+		t.Errorf("Emails = %q, want %q", about.Emails, want)
+	}
+	wantLinks := []youtube.ChannelLink{
+		{Title: "Twitter", URL: "https://twitter.com/builds"},
+		{Title: "Shop", URL: "https://shop.example.com"},
+	}
+	if !reflect.DeepEqual(about.Links, wantLinks) {
+		t.Errorf("Links = %+v, want %+v", about.Links, wantLinks)
+	}
+
+	if ExtractAbout(&AboutResponse{}) != nil || ExtractAbout(nil) != nil {
+		t.Error("ExtractAbout() of a response without about data is not nil")
+	}
+}
+
+func TestParseJoinedDate(t *testing.T) {
+	if got := parseJoinedDate("Joined Dec 31, 2019"); !got.Equal(time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseJoinedDate() = %v", got)
+	}
+	if got := parseJoinedDate("Beigetreten am 31.12.2019"); !got.IsZero() {
+		t.Errorf("parseJoinedDate(localized) = %v, want zero", got)
+	}
+}
+
+func TestClientAbout(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{name: "about tab", body: testAboutResponse},
+		{name: "no about data", body: `{"contents": {}}`, wantErr: youtube.ErrSchemaChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got BrowseRequest
+			cfg := ythttp.DefaultConfig()
+			cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(req.Body)
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Errorf("request body %s: %v", body, err)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(tt.body)),
+					Request:    req,
+				}, nil
+			})
+			about, err := NewClient(ythttp.New(cfg)).About(context.Background(), "UCbuilds")
+			if got.BrowseID != "UCbuilds" || got.Params != aboutParams {
+				t.Errorf("request = %+v", got)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("About() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || about.Country != "Canada" {
+				t.Errorf("About() = %+v, %v", about, err)
+			}
+		})
+	}
+}
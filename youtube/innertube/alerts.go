@@ -0,0 +1,73 @@
+package innertube
+
+import (
+	"fmt"
+	"strings"
+
+	"ytsync/youtube"
+)
+
+// Alert is a message YouTube shows at the top of a page, such as "This
+// channel does not exist." Responses carrying an error alert come back with
+// status 200 and no contents.
+type Alert struct {
+	AlertRenderer           *AlertRenderer `json:"alertRenderer,omitempty"`
+	AlertWithButtonRenderer *AlertRenderer `json:"alertWithButtonRenderer,omitempty"`
+}
+
+// AlertRenderer holds the kind and text of an alert.
+type AlertRenderer struct {
+	Type string    `json:"type,omitempty"` // ERROR, WARNING, INFO
+	Text *TextRuns `json:"text,omitempty"`
+}
+
+// renderer returns whichever renderer the alert has, or nil.
+func (a *Alert) renderer() *AlertRenderer {
+	if a.AlertRenderer != nil {
+		return a.AlertRenderer
+	}
+	return a.AlertWithButtonRenderer
+}
+
+// Err returns the error the response's first error alert describes, or nil
+// if it has none.
+func (r *BrowseResponse) Err() error {
+	return alertsError(r.Alerts)
+}
+
+// Err returns the error the response's first error alert describes, or nil
+// if it has none.
+func (r *AboutResponse) Err() error {
+	return alertsError(r.Alerts)
+}
+
+// alertsError maps the first error alert of alerts to an error matching
+// youtube.ErrAgeRestricted, youtube.ErrLoginRequired, or
+// youtube.ErrChannelTerminated. Warnings and notices are ignored.
+func alertsError(alerts []Alert) error {
+	for i := range alerts {
+		alert := alerts[i].renderer()
+		if alert == nil || alert.Type != "ERROR" {
+			continue
+		}
+		text := alert.Text.GetText()
+		reason := strings.ToLower(text)
+		switch {
+		case isAgeGate(reason):
+			return fmt.Errorf("%w: %s", youtube.ErrAgeRestricted, text)
+		case strings.Contains(reason, "sign in"):
+			return fmt.Errorf("%w: %s", youtube.ErrLoginRequired, text)
+		}
+		return fmt.Errorf("%w: %s", youtube.ErrChannelTerminated, text)
+	}
+	return nil
+}
+
+// isAgeGate reports whether a lowercased reason describes an age
+// restriction.
+func isAgeGate(reason string) bool {
+	return strings.Contains(reason, "confirm your age") ||
+		strings.Contains(reason, "age-restricted") ||
+		strings.Contains(reason, "age restricted") ||
+		strings.Contains(reason, "inappropriate for some users")
+}
@@ -0,0 +1,127 @@
+package innertube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	ythttp "ytsync/http"
+	"ytsync/youtube"
+)
+
+func TestBrowseResponseErr(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{
+			name: "terminated channel",
+			body: `{"alerts": [{"alertRenderer": {"type": "ERROR", "text": {"simpleText": "This account has been terminated for violating YouTube's Community Guidelines."}}}]}`,
+			want: youtube.ErrChannelTerminated,
+		},
+		{
+			name: "missing channel",
+			body: `{"alerts": [{"alertWithButtonRenderer": {"type": "ERROR", "text": {"runs": [{"text": "This channel does not exist."}]}}}]}`,
+			want: youtube.ErrChannelTerminated,
+		},
+		{
+			name: "age gate",
+			body: `{"alerts": [{"alertRenderer": {"type": "ERROR", "text": {"simpleText": "Sign in to confirm your age"}}}]}`,
+			want: youtube.ErrAgeRestricted,
+		},
+		{
+			name: "sign in",
+			body: `{"alerts": [{"alertRenderer": {"type": "ERROR", "text": {"simpleText": "Sign in to view this channel"}}}]}`,
+			want: youtube.ErrLoginRequired,
+		},
+		{
+			name: "warning only",
+			body: `{"alerts": [{"alertRenderer": {"type": "INFO", "text": {"simpleText": "Some videos are hidden"}}}], "contents": {}}`,
+		},
+		{name: "no alerts", body: `{"contents": {}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp BrowseResponse
+			if err := json.Unmarshal([]byte(tt.body), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			err := resp.Err()
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Err() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Err() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlayerResponseErr(t *testing.T) {
+	tests := []struct {
+		status string
+		reason string
+		want   error
+	}{
+		{status: "OK"},
+		{status: "LOGIN_REQUIRED", reason: "Sign in to confirm your age", want: youtube.ErrAgeRestricted},
+		{status: "LOGIN_REQUIRED", reason: "This video is private", want: youtube.ErrLoginRequired},
+		{status: "LOGIN_REQUIRED", reason: "Sign in to confirm you’re not a bot", want: youtube.ErrBotDetected},
+		{status: "AGE_VERIFICATION_REQUIRED", want: youtube.ErrAgeRestricted},
+		{status: "LIVE_STREAM_OFFLINE", reason: "Premieres in 2 hours", want: youtube.ErrNotYetAired},
+		{status: "ERROR", reason: "Video unavailable", want: youtube.ErrVideoUnavailable},
+		{status: "UNPLAYABLE", reason: "This video may be inappropriate for some users.", want: youtube.ErrAgeRestricted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status+" "+tt.reason, func(t *testing.T) {
+			resp := &PlayerResponse{PlayabilityStatus: &PlayabilityStatus{Status: tt.status, Reason: tt.reason}}
+			err := resp.Err()
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Err() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Err() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := (&PlayerResponse{}).Err(); !errors.Is(err, youtube.ErrSchemaChanged) {
+		t.Errorf("Err() without a status = %v, want ErrSchemaChanged", err)
+	}
+}
+
+func TestListVideos_TerminatedChannel(t *testing.T) {
+	requests := 0
+	cfg := ythttp.DefaultConfig()
+	cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"alerts": [{"alertRenderer": {"type": "ERROR", "text": {"simpleText": "This channel does not exist."}}}]}`)),
+			Request:    req,
+		}, nil
+	})
+
+	videos, err := NewLister(ythttp.New(cfg)).ListVideos(context.Background(), "UCsXVk37bltHxD1rDPwtNM8Q", nil)
+	if !errors.Is(err, youtube.ErrChannelTerminated) {
+		t.Fatalf("ListVideos() error = %v, want ErrChannelTerminated", err)
+	}
+	var listerErr *youtube.ListerError
+	if !errors.As(err, &listerErr) || listerErr.Source != "innertube" {
+		t.Errorf("ListVideos() error = %#v, want a ListerError", err)
+	}
+	if len(videos) != 0 || requests != 1 {
+		t.Errorf("ListVideos() = %d videos after %d requests, want none after 1", len(videos), requests)
+	}
+}
@@ -0,0 +1,182 @@
+package innertube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"ytsync/youtube"
+)
+
+// Availability probes whether a video is public, unlisted, private,
+// deleted, age-restricted, members-only, region-blocked, live, or
+// upcoming with one player request, which is much cheaper than fetching
+// full metadata with yt-dlp.
+//
+// Returns an error matching youtube.ErrBotDetected if YouTube refuses the
+// request as automated traffic, since the video's real status cannot be
+// determined in that case.
+func (c *Client) Availability(ctx context.Context, videoID string) (*youtube.VideoAvailability, error) {
+	if videoID == "" {
+		return nil, fmt.Errorf("%w: video ID is required", youtube.ErrInvalidURL)
+	}
+	resp, err := c.Player(ctx, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("check availability of %s: %w", videoID, err)
+	}
+	if err := resp.Err(); errors.Is(err, youtube.ErrBotDetected) {
+		return nil, fmt.Errorf("check availability of %s: %w", videoID, err)
+	}
+	return resp.Availability(videoID), nil
+}
+
+// Availability classifies the response as the availability of videoID. A
+// response without a playability status is youtube.AvailabilityUnknown.
+func (r *PlayerResponse) Availability(videoID string) *youtube.VideoAvailability {
+	result := &youtube.VideoAvailability{
+		VideoID: videoID,
+		State:   r.availabilityState(),
+	}
+	status := r.PlayabilityStatus
+	if status == nil {
+		return result
+	}
+	result.Status = status.Status
+	result.Reason = status.Reason
+	result.Playable = status.Status == "OK"
+
+	if mf := r.microformat(); mf != nil {
+		result.Unlisted = mf.IsUnlisted
+		result.AvailableCountries = mf.AvailableCountries
+		if lb := mf.LiveBroadcastDetails; lb != nil && lb.EndTimestamp == "" {
+			if t, err := time.Parse(time.RFC3339, lb.StartTimestamp); err == nil {
+				result.ScheduledStart = t
+			}
+		}
+	}
+	if ls := status.LiveStreamability; ls != nil && ls.LiveStreamabilityRenderer.OfflineSlate != nil {
+		raw := ls.LiveStreamabilityRenderer.OfflineSlate.LiveStreamOfflineSlateRenderer.ScheduledStartTime
+		if secs, err := strconv.ParseInt(raw, 10, 64); err == nil && secs > 0 {
+			result.ScheduledStart = time.Unix(secs, 0).UTC()
+		}
+	}
+	return result
+}
+
+// Err returns why YouTube will not play the video, or nil if it will: an
+// error matching youtube.ErrBotDetected, youtube.ErrNotYetAired for a
+// stream that has not started, youtube.ErrAgeRestricted,
+// youtube.ErrLoginRequired for private and members-only videos, or
+// youtube.ErrVideoUnavailable. A response without a playability status
+// matches youtube.ErrSchemaChanged.
+func (r *PlayerResponse) Err() error {
+	status := r.PlayabilityStatus
+	if status == nil {
+		return fmt.Errorf("%w: player response has no playability status", youtube.ErrSchemaChanged)
+	}
+	if status.Status == "OK" {
+		return nil
+	}
+	detail := status.Reason
+	if detail == "" {
+		detail = status.Status
+	}
+
+	var err error
+	switch r.availabilityState() {
+	case youtube.AvailabilityUpcoming:
+		err = youtube.ErrNotYetAired
+	case youtube.AvailabilityAgeRestricted:
+		err = youtube.ErrAgeRestricted
+	case youtube.AvailabilityPrivate, youtube.AvailabilityMembersOnly:
+		err = youtube.ErrLoginRequired
+	default:
+		switch {
+		case status.Status == "LOGIN_REQUIRED" && youtube.IsBotDetectionMessage(status.Reason):
+			err = youtube.ErrBotDetected
+		case status.Status == "LOGIN_REQUIRED":
+			err = youtube.ErrLoginRequired
+		default:
+			// Removed, region-blocked, or a status YouTube added since
+			err = youtube.ErrVideoUnavailable
+		}
+	}
+	return fmt.Errorf("%w: %s", err, detail)
+}
+
+// availabilityState classifies the playability status. Both Availability
+// and Err are built on it, so they always agree.
+func (r *PlayerResponse) availabilityState() youtube.AvailabilityState {
+	status := r.PlayabilityStatus
+	if status == nil {
+		return youtube.AvailabilityUnknown
+	}
+	reason := strings.ToLower(status.Reason)
+	details := r.VideoDetails
+
+	switch status.Status {
+	case "OK":
+		switch {
+		case details != nil && details.IsLive:
+			return youtube.AvailabilityLive
+		case details != nil && details.IsUpcoming:
+			return youtube.AvailabilityUpcoming
+		case r.microformat() != nil && r.microformat().IsUnlisted:
+			return youtube.AvailabilityUnlisted
+		}
+		return youtube.AvailabilityPublic
+
+	case "LIVE_STREAM_OFFLINE":
+		return youtube.AvailabilityUpcoming
+
+	case "AGE_CHECK_REQUIRED", "AGE_VERIFICATION_REQUIRED", "CONTENT_CHECK_REQUIRED":
+		return youtube.AvailabilityAgeRestricted
+
+	case "LOGIN_REQUIRED":
+		switch {
+		case strings.Contains(reason, "private"):
+			return youtube.AvailabilityPrivate
+		case isAgeGate(reason):
+			return youtube.AvailabilityAgeRestricted
+		case strings.Contains(reason, "member"):
+			return youtube.AvailabilityMembersOnly
+		}
+
+	case "UNPLAYABLE", "ERROR":
+		switch {
+		case r.isMembersOnly(reason):
+			return youtube.AvailabilityMembersOnly
+		case strings.Contains(reason, "country") || strings.Contains(reason, "region") || strings.Contains(reason, "location"):
+			return youtube.AvailabilityRegionBlocked
+		case strings.Contains(reason, "private"):
+			return youtube.AvailabilityPrivate
+		case isAgeGate(reason):
+			return youtube.AvailabilityAgeRestricted
+		case status.Status == "ERROR":
+			// "Video unavailable", "This video has been removed by the uploader", etc.
+			return youtube.AvailabilityDeleted
+		}
+	}
+	return youtube.AvailabilityUnknown
+}
+
+// isMembersOnly reports whether an unplayable response is a membership
+// gate. reason is the lowercased playability reason.
+func (r *PlayerResponse) isMembersOnly(reason string) bool {
+	if es := r.PlayabilityStatus.ErrorScreen; es != nil {
+		if es.PlayerLegacyDesktopYpcOfferRenderer != nil || es.YpcTrailerRenderer != nil {
+			return true
+		}
+	}
+	return strings.Contains(reason, "members") || strings.Contains(reason, "join this channel")
+}
+
+// microformat returns the response's microformat renderer, or nil.
+func (r *PlayerResponse) microformat() *PlayerMicroformat {
+	if r.Microformat == nil {
+		return nil
+	}
+	return r.Microformat.PlayerMicroformatRenderer
+}
@@ -0,0 +1,176 @@
+package innertube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	ythttp "ytsync/http"
+	"ytsync/youtube"
+)
+
+func TestPlayerResponseAvailability(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     string
+		wantState    youtube.AvailabilityState
+		wantPlayable bool
+		wantErr      error
+	}{
+		{
+			name:         "public",
+			response:     `{"playabilityStatus":{"status":"OK"},"videoDetails":{"videoId":"abc"},"microformat":{"playerMicroformatRenderer":{"isUnlisted":false}}}`,
+			wantState:    youtube.AvailabilityPublic,
+			wantPlayable: true,
+		},
+		{
+			name:         "unlisted",
+			response:     `{"playabilityStatus":{"status":"OK"},"microformat":{"playerMicroformatRenderer":{"isUnlisted":true}}}`,
+			wantState:    youtube.AvailabilityUnlisted,
+			wantPlayable: true,
+		},
+		{
+			name:         "live",
+			response:     `{"playabilityStatus":{"status":"OK"},"videoDetails":{"isLive":true}}`,
+			wantState:    youtube.AvailabilityLive,
+			wantPlayable: true,
+		},
+		{
+			name:      "private",
+			response:  `{"playabilityStatus":{"status":"LOGIN_REQUIRED","reason":"This video is private"}}`,
+			wantState: youtube.AvailabilityPrivate,
+			wantErr:   youtube.ErrLoginRequired,
+		},
+		{
+			name:      "deleted",
+			response:  `{"playabilityStatus":{"status":"ERROR","reason":"This video has been removed by the uploader"}}`,
+			wantState: youtube.AvailabilityDeleted,
+			wantErr:   youtube.ErrVideoUnavailable,
+		},
+		{
+			name:      "age restricted",
+			response:  `{"playabilityStatus":{"status":"LOGIN_REQUIRED","reason":"Sign in to confirm your age"}}`,
+			wantState: youtube.AvailabilityAgeRestricted,
+			wantErr:   youtube.ErrAgeRestricted,
+		},
+		{
+			name:      "members only",
+			response:  `{"playabilityStatus":{"status":"UNPLAYABLE","reason":"Join this channel to get access to members-only content like this video, and other exclusive perks.","errorScreen":{"playerLegacyDesktopYpcOfferRenderer":{}}}}`,
+			wantState: youtube.AvailabilityMembersOnly,
+			wantErr:   youtube.ErrLoginRequired,
+		},
+		{
+			name:      "region blocked",
+			response:  `{"playabilityStatus":{"status":"UNPLAYABLE","reason":"The uploader has not made this video available in your country"}}`,
+			wantState: youtube.AvailabilityRegionBlocked,
+			wantErr:   youtube.ErrVideoUnavailable,
+		},
+		{
+			name:      "bot check",
+			response:  `{"playabilityStatus":{"status":"LOGIN_REQUIRED","reason":"Sign in to confirm you're not a bot"}}`,
+			wantState: youtube.AvailabilityUnknown,
+			wantErr:   youtube.ErrBotDetected,
+		},
+		{
+			name:      "unrecognized",
+			response:  `{"playabilityStatus":{"status":"SOMETHING_NEW"}}`,
+			wantState: youtube.AvailabilityUnknown,
+			wantErr:   youtube.ErrVideoUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp PlayerResponse
+			if err := json.Unmarshal([]byte(tt.response), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			got := resp.Availability("abc")
+			if got.State != tt.wantState {
+				t.Errorf("State = %q, want %q", got.State, tt.wantState)
+			}
+			if got.Playable != tt.wantPlayable {
+				t.Errorf("Playable = %v, want %v", got.Playable, tt.wantPlayable)
+			}
+			// Err must agree with the classified state
+			if err := resp.Err(); (tt.wantErr == nil && err != nil) || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Err() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlayerResponseAvailability_Upcoming(t *testing.T) {
+	response := `{
+		"playabilityStatus": {
+			"status": "LIVE_STREAM_OFFLINE",
+			"reason": "Premieres in 2 hours",
+			"liveStreamability": {"liveStreamabilityRenderer": {"offlineSlate": {
+				"liveStreamOfflineSlateRenderer": {"scheduledStartTime": "1893456000"}
+			}}}
+		},
+		"videoDetails": {"isUpcoming": true}
+	}`
+	var resp PlayerResponse
+	if err := json.Unmarshal([]byte(response), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	got := resp.Availability("abc")
+	if got.State != youtube.AvailabilityUpcoming {
+		t.Errorf("State = %q, want %q", got.State, youtube.AvailabilityUpcoming)
+	}
+	if want := time.Unix(1893456000, 0).UTC(); !got.ScheduledStart.Equal(want) {
+		t.Errorf("ScheduledStart = %v, want %v", got.ScheduledStart, want)
+	}
+	if !got.Available() {
+		t.Error("Available() = false for upcoming stream")
+	}
+	if err := resp.Err(); !errors.Is(err, youtube.ErrNotYetAired) {
+		t.Errorf("Err() = %v, want ErrNotYetAired", err)
+	}
+}
+
+func TestClientAvailability(t *testing.T) {
+	responses := map[string]string{
+		"dQw4w9WgXcQ": `{"playabilityStatus":{"status":"OK"},"microformat":{"playerMicroformatRenderer":{"availableCountries":["US","CA"]}}}`,
+		"botcheck":    `{"playabilityStatus":{"status":"LOGIN_REQUIRED","reason":"Sign in to confirm you're not a bot"}}`,
+	}
+	var requested []string
+	cfg := ythttp.DefaultConfig()
+	cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body PlayerRequest
+		data, _ := io.ReadAll(req.Body)
+		json.Unmarshal(data, &body)
+		requested = append(requested, body.VideoID)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(responses[body.VideoID])),
+			Request:    req,
+		}, nil
+	})
+	client := NewClient(ythttp.New(cfg))
+
+	result, err := client.Availability(context.Background(), "dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatalf("Availability() error = %v", err)
+	}
+	if result.State != youtube.AvailabilityPublic || len(result.AvailableCountries) != 2 {
+		t.Errorf("Availability() = %+v", result)
+	}
+
+	if _, err := client.Availability(context.Background(), "botcheck"); !errors.Is(err, youtube.ErrBotDetected) {
+		t.Errorf("Availability(botcheck) error = %v, want ErrBotDetected", err)
+	}
+	if _, err := client.Availability(context.Background(), ""); !errors.Is(err, youtube.ErrInvalidURL) {
+		t.Errorf("Availability(\"\") error = %v, want ErrInvalidURL", err)
+	}
+	if strings.Join(requested, ",") != "dQw4w9WgXcQ,botcheck" {
+		t.Errorf("requested %v, want one player request per probed video", requested)
+	}
+}
@@ -3,6 +3,7 @@ package innertube
 import (
 	"encoding/base64"
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -30,6 +31,9 @@ type ContinuationState struct {
 	// ExpiresAt is when this continuation token is expected to expire.
 	// Innertube tokens typically expire after a few hours.
 	ExpiresAt time.Time `json:"expires_at,omitempty"`
+
+	// Tab is the channel tab being paginated. Empty means TabVideos.
+	Tab ChannelTab `json:"tab,omitempty"`
 }
 
 const (
@@ -69,6 +73,15 @@ func (s *ContinuationState) IncrementVideos(count int) {
 	s.UpdatedAt = time.Now()
 }
 
+// tab returns the tab being paginated, defaulting to TabVideos for states
+// saved before tabs were recorded.
+func (s *ContinuationState) tab() ChannelTab {
+	if s.Tab == "" {
+		return TabVideos
+	}
+	return s.Tab
+}
+
 // HasMore returns true if there are more pages to fetch.
 func (s *ContinuationState) HasMore() bool {
 	return s.Token != ""
@@ -258,6 +271,37 @@ func ExtractVideos(resp *BrowseResponse, channelID, channelName string) []VideoD
 	return videos
 }
 
+// contentItemCount returns the number of grid and continuation items in
+// resp other than continuation markers. Item sections are not counted, as
+// they also carry messages such as "This channel has no videos".
+func contentItemCount(resp *BrowseResponse) int {
+	if resp == nil {
+		return 0
+	}
+	n := 0
+	for _, action := range resp.OnResponseReceived {
+		if action.AppendContinuationItemsAction != nil {
+			for _, item := range action.AppendContinuationItemsAction.ContinuationItems {
+				if item.ContinuationItemRenderer == nil {
+					n++
+				}
+			}
+		}
+	}
+	if resp.Contents != nil && resp.Contents.TwoColumnBrowseResultsRenderer != nil {
+		for _, tab := range resp.Contents.TwoColumnBrowseResultsRenderer.Tabs {
+			if tab.TabRenderer != nil && tab.TabRenderer.Content != nil && tab.TabRenderer.Content.RichGridRenderer != nil {
+				for _, content := range tab.TabRenderer.Content.RichGridRenderer.Contents {
+					if content.ContinuationItemRenderer == nil {
+						n++
+					}
+				}
+			}
+		}
+	}
+	return n
+}
+
 // VideoData represents extracted video information.
 type VideoData struct {
 	VideoID     string
@@ -269,14 +313,42 @@ type VideoData struct {
 	ViewCount   string
 	ChannelID   string
 	ChannelName string
+	// BadgeStyles are the styles of the video's badges.
+	BadgeStyles []string
+	// OverlayStyle is the thumbnail time status overlay style.
+	OverlayStyle string
+	// StartTime is the scheduled start of an upcoming video, in Unix seconds.
+	StartTime string
+	// UpcomingText is the label of an upcoming video, such as
+	// "Premieres 10/20/26, 5:00 PM".
+	UpcomingText string
+	// IsShort is set for videos listed on the Shorts tab.
+	IsShort bool
+}
+
+// setLiveData copies the badge, overlay, and upcoming event fields shared
+// by video renderers.
+func (d *VideoData) setLiveData(badges []Badge, upcoming *UpcomingEvent, overlays []Overlay) {
+	for _, b := range badges {
+		if b.MetadataBadgeRenderer != nil && b.MetadataBadgeRenderer.Style != "" {
+			d.BadgeStyles = append(d.BadgeStyles, b.MetadataBadgeRenderer.Style)
+		}
+	}
+	for _, o := range overlays {
+		if o.ThumbnailOverlayTimeStatusRenderer != nil {
+			d.OverlayStyle = o.ThumbnailOverlayTimeStatusRenderer.Style
+		}
+	}
+	if upcoming != nil {
+		d.StartTime = upcoming.StartTime
+		d.UpcomingText = upcoming.UpcomingEventText.GetText()
+	}
 }
 
 // extractVideoFromContinuationItem extracts video data from a continuation item.
 func extractVideoFromContinuationItem(item *ContinuationItem, channelID, channelName string) *VideoData {
-	if item.RichItemRenderer != nil && item.RichItemRenderer.Content != nil {
-		if item.RichItemRenderer.Content.VideoRenderer != nil {
-			return videoRendererToData(item.RichItemRenderer.Content.VideoRenderer, channelID, channelName)
-		}
+	if item.RichItemRenderer != nil {
+		return richItemToData(item.RichItemRenderer, channelID, channelName)
 	}
 	if item.GridVideoRenderer != nil {
 		return gridVideoRendererToData(item.GridVideoRenderer, channelID, channelName)
@@ -286,10 +358,25 @@ func extractVideoFromContinuationItem(item *ContinuationItem, channelID, channel
 
 // extractVideoFromRichGridContent extracts video data from rich grid content.
 func extractVideoFromRichGridContent(content *RichGridContent, channelID, channelName string) *VideoData {
-	if content.RichItemRenderer != nil && content.RichItemRenderer.Content != nil {
-		if content.RichItemRenderer.Content.VideoRenderer != nil {
-			return videoRendererToData(content.RichItemRenderer.Content.VideoRenderer, channelID, channelName)
-		}
+	if content.RichItemRenderer != nil {
+		return richItemToData(content.RichItemRenderer, channelID, channelName)
+	}
+	return nil
+}
+
+// richItemToData extracts video data from a rich grid item, which holds a
+// video on the Videos and Live tabs and a Short on the Shorts tab.
+func richItemToData(item *RichItemRenderer, channelID, channelName string) *VideoData {
+	if item.Content == nil {
+		return nil
+	}
+	switch {
+	case item.Content.VideoRenderer != nil:
+		return videoRendererToData(item.Content.VideoRenderer, channelID, channelName)
+	case item.Content.ReelItemRenderer != nil:
+		return reelItemRendererToData(item.Content.ReelItemRenderer, channelID, channelName)
+	case item.Content.ShortsLockupViewModel != nil:
+		return shortsLockupToData(item.Content.ShortsLockupViewModel, channelID, channelName)
 	}
 	return nil
 }
@@ -333,6 +420,7 @@ func videoRendererToData(v *VideoRenderer, channelID, channelName string) *Video
 	if v.ViewCountText != nil {
 		data.ViewCount = v.ViewCountText.SimpleText
 	}
+	data.setLiveData(v.Badges, v.UpcomingEventData, v.ThumbnailOverlays)
 
 	return data
 }
@@ -359,6 +447,57 @@ func gridVideoRendererToData(v *GridVideoRenderer, channelID, channelName string
 	if v.ViewCountText != nil {
 		data.ViewCount = v.ViewCountText.SimpleText
 	}
+	data.setLiveData(v.Badges, v.UpcomingEventData, v.ThumbnailOverlays)
+
+	return data
+}
+
+// reelItemRendererToData converts a ReelItemRenderer to VideoData.
+func reelItemRendererToData(v *ReelItemRenderer, channelID, channelName string) *VideoData {
+	if v == nil || v.VideoID == "" {
+		return nil
+	}
+
+	data := &VideoData{
+		VideoID:     v.VideoID,
+		Title:       v.Headline.GetText(),
+		ViewCount:   v.ViewCountText.GetText(),
+		ChannelID:   channelID,
+		ChannelName: channelName,
+		IsShort:     true,
+	}
+	if v.Thumbnail != nil && len(v.Thumbnail.Thumbnails) > 0 {
+		data.Thumbnail = v.Thumbnail.Thumbnails[0].URL
+	}
+
+	return data
+}
+
+// shortsLockupToData converts a ShortsLockupViewModel to VideoData.
+func shortsLockupToData(v *ShortsLockupViewModel, channelID, channelName string) *VideoData {
+	if v == nil || v.OnTap == nil || v.OnTap.InnertubeCommand == nil ||
+		v.OnTap.InnertubeCommand.ReelWatchEndpoint == nil ||
+		v.OnTap.InnertubeCommand.ReelWatchEndpoint.VideoID == "" {
+		return nil
+	}
+
+	data := &VideoData{
+		VideoID:     v.OnTap.InnertubeCommand.ReelWatchEndpoint.VideoID,
+		ChannelID:   channelID,
+		ChannelName: channelName,
+		IsShort:     true,
+	}
+	if md := v.OverlayMetadata; md != nil {
+		if md.PrimaryText != nil {
+			data.Title = md.PrimaryText.Content
+		}
+		if md.SecondaryText != nil {
+			data.ViewCount = md.SecondaryText.Content
+		}
+	}
+	if v.Thumbnail != nil && len(v.Thumbnail.Sources) > 0 {
+		data.Thumbnail = v.Thumbnail.Sources[0].URL
+	}
 
 	return data
 }
@@ -394,6 +533,37 @@ func extractChannelID(resp *BrowseResponse) string {
 	return ""
 }
 
+// extractVideoCountText gets the video count shown in the channel header,
+// such as "1,234 videos" or "1.2K videos".
+func extractVideoCountText(resp *BrowseResponse) string {
+	if resp.Header == nil {
+		return ""
+	}
+	if h := resp.Header.C4TabbedHeaderRenderer; h != nil && h.VideosCountText != nil {
+		return h.VideosCountText.GetText()
+	}
+	if resp.Header.PageHeaderRenderer == nil ||
+		resp.Header.PageHeaderRenderer.Content == nil ||
+		resp.Header.PageHeaderRenderer.Content.PageHeaderViewModel == nil ||
+		resp.Header.PageHeaderRenderer.Content.PageHeaderViewModel.Metadata == nil ||
+		resp.Header.PageHeaderRenderer.Content.PageHeaderViewModel.Metadata.ContentMetadataViewModel == nil {
+		return ""
+	}
+	// The handle, subscriber count, and video count share the rows, so the
+	// count is told apart by its unit
+	for _, row := range resp.Header.PageHeaderRenderer.Content.PageHeaderViewModel.Metadata.ContentMetadataViewModel.MetadataRows {
+		for _, part := range row.MetadataParts {
+			if part.Text == nil {
+				continue
+			}
+			if text := strings.ToLower(part.Text.Content); strings.HasSuffix(text, " video") || strings.HasSuffix(text, " videos") {
+				return part.Text.Content
+			}
+		}
+	}
+	return ""
+}
+
 // IsValidContinuationToken performs basic validation on a continuation token.
 // Tokens are base64-encoded protobuf messages.
 func IsValidContinuationToken(token string) bool {
@@ -1,6 +1,7 @@
 package innertube
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -279,6 +280,38 @@ func TestExtractVideos(t *testing.T) {
 	}
 }
 
+func TestExtractVideos_Shorts(t *testing.T) {
+	page := `{
+		"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"richGridRenderer": {"contents": [
+			{"richItemRenderer": {"content": {"reelItemRenderer": {
+				"videoId": "short1",
+				"headline": {"simpleText": "Reel Short"},
+				"viewCountText": {"simpleText": "1.2M views"}
+			}}}},
+			{"richItemRenderer": {"content": {"shortsLockupViewModel": {
+				"onTap": {"innertubeCommand": {"reelWatchEndpoint": {"videoId": "short2"}}},
+				"overlayMetadata": {"primaryText": {"content": "Lockup Short"}, "secondaryText": {"content": "300K views"}},
+				"thumbnail": {"sources": [{"url": "https://i.ytimg.com/vi/short2/oardefault.jpg"}]}
+			}}}}
+		]}}}}]}}
+	}`
+	var resp BrowseResponse
+	if err := json.Unmarshal([]byte(page), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	videos := ExtractVideos(&resp, "UCtest123", "Test Channel")
+	if len(videos) != 2 {
+		t.Fatalf("expected 2 shorts, got %d", len(videos))
+	}
+	if v := videos[0]; v.VideoID != "short1" || v.Title != "Reel Short" || v.ViewCount != "1.2M views" || !v.IsShort {
+		t.Errorf("reel item = %+v", v)
+	}
+	if v := videos[1]; v.VideoID != "short2" || v.Title != "Lockup Short" || v.ViewCount != "300K views" || v.Thumbnail == "" || !v.IsShort {
+		t.Errorf("shorts lockup = %+v", v)
+	}
+}
+
 func TestIsValidContinuationToken(t *testing.T) {
 	tests := []struct {
 		name  string
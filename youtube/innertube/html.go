@@ -0,0 +1,222 @@
+package innertube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	ythttp "ytsync/http"
+	"ytsync/tags"
+	"ytsync/youtube"
+)
+
+// DefaultBaseURL is the site HTMLLister loads channel pages from.
+const DefaultBaseURL = "https://www.youtube.com"
+
+// initialDataMarker matches the assignment of ytInitialData in a page,
+// either as a variable or as a window property.
+var initialDataMarker = regexp.MustCompile(`(?:var\s+ytInitialData|window\["ytInitialData"\])\s*=\s*`)
+
+// htmlPageHeaders are browser-like headers for loading channel pages.
+var htmlPageHeaders = map[string]string{
+	"User-Agent":      defaultUserAgent,
+	"Accept":          "text/html,application/xhtml+xml",
+	"Accept-Language": "en-US,en;q=0.9",
+}
+
+// HTMLLister implements youtube.VideoLister by loading a channel tab as a
+// web page and reading the ytInitialData JSON embedded in it, which holds
+// the same data as the first browse response. It is a fallback for when
+// the browse endpoint is blocked but plain page loads still work, and only
+// lists the first page (about 30 videos) of each tab. The continuation
+// token returned by FetchPage can be passed to a Lister as
+// ListOptions.ResumeToken to list the rest once the endpoint works again.
+type HTMLLister struct {
+	httpClient *ythttp.Client
+
+	// BaseURL is the site pages are loaded from (default DefaultBaseURL).
+	BaseURL string
+
+	// Extraction selects how videos and continuation tokens are read from
+	// the page (default ExtractAuto).
+	Extraction ExtractionMode
+
+	// Schema holds the JSON paths used by ExtractAuto and ExtractPaths
+	// (default DefaultSchema).
+	Schema *Schema
+}
+
+// NewHTMLLister creates a lister that reads channel pages with httpClient.
+func NewHTMLLister(httpClient *ythttp.Client) *HTMLLister {
+	return &HTMLLister{httpClient: httpClient, BaseURL: DefaultBaseURL}
+}
+
+// HTMLPage is the first page of a channel tab read by HTMLLister.
+type HTMLPage struct {
+	// ChannelID is the channel's ID as given in the page.
+	ChannelID string
+	// ChannelName is the channel's title.
+	ChannelName string
+	// Videos are the videos on the page, in page order.
+	Videos []youtube.VideoInfo
+	// ContinuationToken leads to the next page through the browse
+	// endpoint, or is empty if the tab has no more videos.
+	ContinuationToken string
+}
+
+// ListVideos returns the first page of the channel's Videos tab, its Live
+// tab, or both, as opts.ContentType selects, filtered by opts.
+func (h *HTMLLister) ListVideos(ctx context.Context, channelURL string, opts *youtube.ListOptions) ([]youtube.VideoInfo, error) {
+	var videos []youtube.VideoInfo
+	for _, tab := range tabsFor(opts) {
+		page, err := h.FetchPage(ctx, channelURL, tab)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range page.Videos {
+			if opts.BeforeRange(v) {
+				break
+			}
+			videos = append(videos, v)
+		}
+	}
+	return filterAndSortVideos(videos, opts), nil
+}
+
+// FetchPage loads a tab of the channel at channelURL, which may be a
+// channel ID, an @handle, or a channel URL, and reads its first page of
+// videos and continuation token.
+func (h *HTMLLister) FetchPage(ctx context.Context, channelURL string, tab ChannelTab) (*HTMLPage, error) {
+	data, err := h.fetchInitialData(ctx, channelURL, tab)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &HTMLPage{ChannelID: extractChannelID(data), ChannelName: extractChannelName(data)}
+	extract := &Lister{Extraction: h.Extraction, Schema: h.Schema}
+	items := extract.extractVideos(data, page.ChannelID, page.ChannelName)
+	if len(items) == 0 && contentItemCount(data) > 0 {
+		return nil, &youtube.ListerError{
+			Source:  "html",
+			Channel: channelURL,
+			Err:     fmt.Errorf("%w: %s tab page had items but no videos", youtube.ErrSchemaChanged, tab),
+		}
+	}
+	for _, v := range items {
+		page.Videos = append(page.Videos, videoDataToInfo(v))
+	}
+	page.ContinuationToken = extract.extractContinuationToken(data)
+	return page, nil
+}
+
+// VideoCount reads the channel's video count from the header of its Videos
+// tab page, like Lister.VideoCount.
+func (h *HTMLLister) VideoCount(ctx context.Context, channelURL string) (int, error) {
+	data, err := h.fetchInitialData(ctx, channelURL, TabVideos)
+	if err != nil {
+		return 0, err
+	}
+	text := extractVideoCountText(data)
+	count, ok := parseVideoCount(text)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s header shows %q", youtube.ErrVideoCountUnavailable, channelURL, text)
+	}
+	return count, nil
+}
+
+// fetchInitialData loads a channel tab page and returns its ytInitialData.
+func (h *HTMLLister) fetchInitialData(ctx context.Context, channelURL string, tab ChannelTab) (*BrowseResponse, error) {
+	fail := func(err error) (*BrowseResponse, error) {
+		return nil, &youtube.ListerError{Source: "html", Channel: channelURL, Err: err}
+	}
+	pageURL, err := h.pageURL(channelURL, tab)
+	if err != nil {
+		return fail(err)
+	}
+	if id := channelIDRegex.FindString(pageURL); id != "" {
+		ctx = tags.Default(ctx, tags.Channel, id)
+	}
+
+	resp, err := h.httpClient.Do(ctx, http.MethodGet, pageURL, nil, htmlPageHeaders)
+	if err != nil {
+		return fail(fmt.Errorf("fetch channel page: %w", err))
+	}
+	data, err := parseInitialData(string(resp.Body))
+	if err != nil {
+		return fail(err)
+	}
+	if err := data.Err(); err != nil {
+		return fail(err)
+	}
+	return data, nil
+}
+
+// pageURL returns the URL of a channel tab page.
+func (h *HTMLLister) pageURL(channelURL string, tab ChannelTab) (string, error) {
+	if _, ok := tabParams[tab]; !ok {
+		return "", fmt.Errorf("unknown channel tab %q", tab)
+	}
+	base := strings.TrimSuffix(h.BaseURL, "/")
+	if base == "" {
+		base = DefaultBaseURL
+	}
+
+	input := strings.TrimSpace(channelURL)
+	var path string
+	switch {
+	case channelIDRegex.MatchString(input) && len(input) == 24:
+		path = "/channel/" + input
+	case strings.HasPrefix(input, "@"):
+		path = "/" + input
+	case strings.Contains(input, "youtube.com/"):
+		if !strings.Contains(input, "://") {
+			input = "https://" + input
+		}
+		u, err := url.Parse(input)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", youtube.ErrInvalidURL, err)
+		}
+		path = channelPath(u.Path)
+	}
+	if path == "" {
+		return "", fmt.Errorf("%w: cannot find a channel in %q", youtube.ErrInvalidURL, channelURL)
+	}
+	return base + path + "/" + string(tab), nil
+}
+
+// channelPath returns the channel part of a channel URL's path, such as
+// "/@handle", "/channel/UC...", or "/c/name", dropping any tab after it.
+func channelPath(p string) string {
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	switch {
+	case len(parts) >= 1 && strings.HasPrefix(parts[0], "@"):
+		return "/" + parts[0]
+	case len(parts) >= 2 && (parts[0] == "channel" || parts[0] == "c" || parts[0] == "user"):
+		return "/" + parts[0] + "/" + parts[1]
+	}
+	return ""
+}
+
+// parseInitialData extracts ytInitialData from a page. The decoder stops
+// at the end of the object, so the script that follows it is ignored.
+func parseInitialData(page string) (*BrowseResponse, error) {
+	loc := initialDataMarker.FindStringIndex(page)
+	if loc == nil {
+		return nil, fmt.Errorf("%w: ytInitialData not found in channel page", youtube.ErrSchemaChanged)
+	}
+	var data BrowseResponse
+	if err := json.NewDecoder(strings.NewReader(page[loc[1]:])).Decode(&data); err != nil {
+		return nil, fmt.Errorf("%w: parse ytInitialData: %v", youtube.ErrSchemaChanged, err)
+	}
+	return &data, nil
+}
+
+// SupportsFullHistory returns false: only the first page of each tab is
+// listed.
+func (h *HTMLLister) SupportsFullHistory() bool {
+	return false
+}
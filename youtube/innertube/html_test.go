@@ -0,0 +1,113 @@
+package innertube
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	ythttp "ytsync/http"
+	"ytsync/youtube"
+)
+
+// channelPage is a channel Videos tab page with two videos and a
+// continuation token.
+const channelPage = `<!DOCTYPE html><html><head><title>Test Channel - YouTube</title></head><body>
+<script nonce="abc">var ytInitialData = {
+	"metadata": {"channelMetadataRenderer": {"title": "Test Channel", "externalId": "UCsXVk37bltHxD1rDPwtNM8Q"}},
+	"header": {"c4TabbedHeaderRenderer": {"channelId": "UCsXVk37bltHxD1rDPwtNM8Q", "videosCountText": {"runs": [{"text": "42"}, {"text": " videos"}]}}},
+	"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"richGridRenderer": {"contents": [
+		{"richItemRenderer": {"content": {"videoRenderer": {"videoId": "dQw4w9WgXcQ", "title": {"runs": [{"text": "First Video"}]}, "lengthText": {"simpleText": "3:32"}}}}},
+		{"richItemRenderer": {"content": {"videoRenderer": {"videoId": "xQw4w9WgXcZ", "title": {"runs": [{"text": "Second Video"}]}}}}},
+		{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "4qmFsgJhEhhVQ3NYVms"}}}}
+	]}}}}]}}
+};</script><script>var other = {"x": 1};</script></body></html>`
+
+func htmlTestLister(t *testing.T, body string, urls *[]string) *HTMLLister {
+	t.Helper()
+	cfg := ythttp.DefaultConfig()
+	cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*urls = append(*urls, req.URL.String())
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"text/html"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	return NewHTMLLister(ythttp.New(cfg))
+}
+
+func TestHTMLListerFetchPage(t *testing.T) {
+	var urls []string
+	lister := htmlTestLister(t, channelPage, &urls)
+
+	page, err := lister.FetchPage(context.Background(), "https://www.youtube.com/@TestChannel/featured?si=x", TabVideos)
+	if err != nil {
+		t.Fatalf("FetchPage() error = %v", err)
+	}
+	if len(urls) != 1 || urls[0] != "https://www.youtube.com/@TestChannel/videos" {
+		t.Errorf("requested %v", urls)
+	}
+	if page.ChannelID != "UCsXVk37bltHxD1rDPwtNM8Q" || page.ChannelName != "Test Channel" {
+		t.Errorf("channel = %q %q", page.ChannelID, page.ChannelName)
+	}
+	if len(page.Videos) != 2 || page.Videos[0].ID != "dQw4w9WgXcQ" || page.Videos[0].Title != "First Video" || page.Videos[1].ChannelID != "UCsXVk37bltHxD1rDPwtNM8Q" {
+		t.Errorf("videos = %+v", page.Videos)
+	}
+	if page.ContinuationToken != "4qmFsgJhEhhVQ3NYVms" {
+		t.Errorf("ContinuationToken = %q", page.ContinuationToken)
+	}
+
+	count, err := lister.VideoCount(context.Background(), "UCsXVk37bltHxD1rDPwtNM8Q")
+	if err != nil || count != 42 {
+		t.Errorf("VideoCount() = %d, %v; want 42", count, err)
+	}
+}
+
+func TestHTMLListerListVideos(t *testing.T) {
+	var urls []string
+	lister := htmlTestLister(t, channelPage, &urls)
+
+	videos, err := lister.ListVideos(context.Background(), "UCsXVk37bltHxD1rDPwtNM8Q", &youtube.ListOptions{MaxResults: 1, ContentType: youtube.ContentTypeBoth})
+	if err != nil {
+		t.Fatalf("ListVideos() error = %v", err)
+	}
+	if len(videos) != 1 || videos[0].ID != "dQw4w9WgXcQ" {
+		t.Errorf("ListVideos() = %+v", videos)
+	}
+	want := []string{
+		"https://www.youtube.com/channel/UCsXVk37bltHxD1rDPwtNM8Q/videos",
+		"https://www.youtube.com/channel/UCsXVk37bltHxD1rDPwtNM8Q/streams",
+	}
+	if strings.Join(urls, " ") != strings.Join(want, " ") {
+		t.Errorf("requested %v, want %v", urls, want)
+	}
+	if lister.SupportsFullHistory() {
+		t.Error("SupportsFullHistory() = true")
+	}
+}
+
+func TestHTMLListerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel string
+		body    string
+		want    error
+	}{
+		{name: "no initial data", channel: "@TestChannel", body: `<html><body>Before you continue to YouTube</body></html>`, want: youtube.ErrSchemaChanged},
+		{name: "truncated initial data", channel: "@TestChannel", body: `<script>window["ytInitialData"] = {"contents": </script>`, want: youtube.ErrSchemaChanged},
+		{name: "not a channel", channel: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", body: channelPage, want: youtube.ErrInvalidURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var urls []string
+			_, err := htmlTestLister(t, tt.body, &urls).FetchPage(context.Background(), tt.channel, TabVideos)
+			var listerErr *youtube.ListerError
+			if !errors.Is(err, tt.want) || !errors.As(err, &listerErr) || listerErr.Source != "html" {
+				t.Errorf("FetchPage() error = %v, want a ListerError matching %v", err, tt.want)
+			}
+		})
+	}
+}
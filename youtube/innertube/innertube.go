@@ -1,5 +1,6 @@
 // Package innertube provides access to YouTube's internal Innertube API
-// for fetching channel video lists with continuation token-based pagination.
+// for fetching channel video lists with continuation token-based pagination
+// and per-video watch page details.
 package innertube
 
 import (
@@ -28,6 +29,25 @@ const (
 	defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
 )
 
+// ChannelTab selects the channel tab a browse request lists.
+type ChannelTab string
+
+const (
+	// TabVideos is the Videos tab of regular uploads and premieres.
+	TabVideos ChannelTab = "videos"
+	// TabShorts is the Shorts tab.
+	TabShorts ChannelTab = "shorts"
+	// TabLive is the Live tab of streams and stream recordings.
+	TabLive ChannelTab = "streams"
+)
+
+// tabParams are the protobuf-encoded browse params that open each tab.
+var tabParams = map[ChannelTab]string{
+	TabVideos: "EgZ2aWRlb3PyBgQKAjoA",
+	TabShorts: "EgZzaG9ydHPyBgUKA5oBAA==",
+	TabLive:   "EgdzdHJlYW1z8gYECgJ6AA==",
+}
+
 // Client handles Innertube API interactions with rate limiting and retry logic.
 type Client struct {
 	httpClient  *ythttp.Client
@@ -85,6 +105,32 @@ type BrowseResponse struct {
 	OnResponseReceived []OnResponseAction `json:"onResponseReceivedActions,omitempty"`
 	Header             *ChannelHeader     `json:"header,omitempty"`
 	Metadata           *ChannelMetadata   `json:"metadata,omitempty"`
+	Alerts             []Alert            `json:"alerts,omitempty"`
+
+	// raw is the response as received, kept for extraction by Schema paths
+	// when the typed fields miss renamed parts of it.
+	raw json.RawMessage
+}
+
+// UnmarshalJSON decodes a browse response and keeps its raw JSON.
+func (r *BrowseResponse) UnmarshalJSON(data []byte) error {
+	type plain BrowseResponse
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	r.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// Raw returns the response's JSON as received, or the JSON of its typed
+// fields if it was not decoded from JSON.
+func (r *BrowseResponse) Raw() json.RawMessage {
+	if r.raw != nil {
+		return r.raw
+	}
+	type plain BrowseResponse
+	data, _ := json.Marshal((*plain)(r))
+	return data
 }
 
 // Contents holds the main content structure.
@@ -136,13 +182,17 @@ type SectionContent struct {
 // ItemSectionRenderer renders a section of items.
 type ItemSectionRenderer struct {
 	Contents []ItemContent `json:"contents,omitempty"`
+	// SectionIdentifier names the section, such as "comment-item-section"
+	// on a watch page.
+	SectionIdentifier string `json:"sectionIdentifier,omitempty"`
 }
 
 // ItemContent can be various content types.
 type ItemContent struct {
-	GridVideoRenderer     *GridVideoRenderer     `json:"gridVideoRenderer,omitempty"`
-	VideoRenderer         *VideoRenderer         `json:"videoRenderer,omitempty"`
-	PlaylistVideoRenderer *PlaylistVideoRenderer `json:"playlistVideoRenderer,omitempty"`
+	GridVideoRenderer        *GridVideoRenderer        `json:"gridVideoRenderer,omitempty"`
+	VideoRenderer            *VideoRenderer            `json:"videoRenderer,omitempty"`
+	PlaylistVideoRenderer    *PlaylistVideoRenderer    `json:"playlistVideoRenderer,omitempty"`
+	ContinuationItemRenderer *ContinuationItemRenderer `json:"continuationItemRenderer,omitempty"`
 }
 
 // RichGridContent holds grid items.
@@ -156,9 +206,53 @@ type RichItemRenderer struct {
 	Content *RichItemContent `json:"content,omitempty"`
 }
 
-// RichItemContent holds the actual video renderer.
+// RichItemContent holds the actual video renderer. Shorts tabs use
+// ReelItemRenderer, or ShortsLockupViewModel in newer layouts.
 type RichItemContent struct {
-	VideoRenderer *VideoRenderer `json:"videoRenderer,omitempty"`
+	VideoRenderer         *VideoRenderer         `json:"videoRenderer,omitempty"`
+	ReelItemRenderer      *ReelItemRenderer      `json:"reelItemRenderer,omitempty"`
+	ShortsLockupViewModel *ShortsLockupViewModel `json:"shortsLockupViewModel,omitempty"`
+}
+
+// ReelItemRenderer is a Short on a channel's Shorts tab.
+type ReelItemRenderer struct {
+	VideoID       string         `json:"videoId,omitempty"`
+	Headline      *TextRuns      `json:"headline,omitempty"`
+	Thumbnail     *ThumbnailList `json:"thumbnail,omitempty"`
+	ViewCountText *TextRuns      `json:"viewCountText,omitempty"`
+}
+
+// ShortsLockupViewModel is a Short in the view-model layout of the Shorts
+// tab.
+type ShortsLockupViewModel struct {
+	OnTap           *ShortsOnTap           `json:"onTap,omitempty"`
+	OverlayMetadata *ShortsOverlayMetadata `json:"overlayMetadata,omitempty"`
+	Thumbnail       *ShortsThumbnail       `json:"thumbnail,omitempty"`
+}
+
+// ShortsOnTap holds the command that opens a Short.
+type ShortsOnTap struct {
+	InnertubeCommand *struct {
+		ReelWatchEndpoint *struct {
+			VideoID string `json:"videoId,omitempty"`
+		} `json:"reelWatchEndpoint,omitempty"`
+	} `json:"innertubeCommand,omitempty"`
+}
+
+// ShortsOverlayMetadata holds the title and view count drawn over a Short.
+type ShortsOverlayMetadata struct {
+	PrimaryText   *ViewModelText `json:"primaryText,omitempty"`
+	SecondaryText *ViewModelText `json:"secondaryText,omitempty"`
+}
+
+// ViewModelText is the text of a view model.
+type ViewModelText struct {
+	Content string `json:"content,omitempty"`
+}
+
+// ShortsThumbnail holds a Short's thumbnail images.
+type ShortsThumbnail struct {
+	Sources []Thumbnail `json:"sources,omitempty"`
 }
 
 // ContinuationItemRenderer provides pagination tokens.
@@ -214,6 +308,9 @@ type VideoRenderer struct {
 	LengthText         *SimpleText    `json:"lengthText,omitempty"`
 	ViewCountText      *SimpleText    `json:"viewCountText,omitempty"`
 	OwnerText          *TextRuns      `json:"ownerText,omitempty"`
+	Badges             []Badge        `json:"badges,omitempty"`
+	UpcomingEventData  *UpcomingEvent `json:"upcomingEventData,omitempty"`
+	ThumbnailOverlays  []Overlay      `json:"thumbnailOverlays,omitempty"`
 }
 
 // GridVideoRenderer is similar to VideoRenderer but used in grid layouts.
@@ -223,6 +320,36 @@ type GridVideoRenderer struct {
 	Thumbnail         *ThumbnailList `json:"thumbnail,omitempty"`
 	PublishedTimeText *SimpleText    `json:"publishedTimeText,omitempty"`
 	ViewCountText     *SimpleText    `json:"viewCountText,omitempty"`
+	Badges            []Badge        `json:"badges,omitempty"`
+	UpcomingEventData *UpcomingEvent `json:"upcomingEventData,omitempty"`
+	ThumbnailOverlays []Overlay      `json:"thumbnailOverlays,omitempty"`
+}
+
+// Badge is a label shown on a video, such as "Members only" or "LIVE".
+type Badge struct {
+	MetadataBadgeRenderer *MetadataBadgeRenderer `json:"metadataBadgeRenderer,omitempty"`
+}
+
+// MetadataBadgeRenderer holds a badge's style and label.
+type MetadataBadgeRenderer struct {
+	Style string `json:"style,omitempty"` // e.g. BADGE_STYLE_TYPE_MEMBERS_ONLY
+	Label string `json:"label,omitempty"`
+}
+
+// UpcomingEvent describes a scheduled stream or premiere.
+type UpcomingEvent struct {
+	StartTime         string    `json:"startTime,omitempty"` // Unix seconds
+	UpcomingEventText *TextRuns `json:"upcomingEventText,omitempty"`
+}
+
+// Overlay is a label drawn over a video thumbnail.
+type Overlay struct {
+	ThumbnailOverlayTimeStatusRenderer *TimeStatusOverlay `json:"thumbnailOverlayTimeStatusRenderer,omitempty"`
+}
+
+// TimeStatusOverlay is the duration or live status overlay of a thumbnail.
+type TimeStatusOverlay struct {
+	Style string `json:"style,omitempty"` // DEFAULT, LIVE, UPCOMING, or SHORTS
 }
 
 // PlaylistVideoRenderer represents a video in a playlist.
@@ -240,9 +367,11 @@ type TextRuns struct {
 	SimpleText string    `json:"simpleText,omitempty"`
 }
 
-// TextRun is a segment of text.
+// TextRun is a segment of text. Runs that link somewhere, such as a
+// channel name, carry the link's endpoint.
 type TextRun struct {
-	Text string `json:"text,omitempty"`
+	Text               string    `json:"text,omitempty"`
+	NavigationEndpoint *Endpoint `json:"navigationEndpoint,omitempty"`
 }
 
 // SimpleText holds a simple text value.
@@ -270,9 +399,10 @@ type ChannelHeader struct {
 
 // C4TabbedHeaderRenderer contains channel info in the header.
 type C4TabbedHeaderRenderer struct {
-	ChannelID string         `json:"channelId,omitempty"`
-	Title     string         `json:"title,omitempty"`
-	Avatar    *ThumbnailList `json:"avatar,omitempty"`
+	ChannelID       string         `json:"channelId,omitempty"`
+	Title           string         `json:"title,omitempty"`
+	Avatar          *ThumbnailList `json:"avatar,omitempty"`
+	VideosCountText *TextRuns      `json:"videosCountText,omitempty"`
 }
 
 // PageHeaderRenderer is an alternative header structure.
@@ -287,7 +417,29 @@ type PageHeaderContent struct {
 
 // PageHeaderViewModel contains the view model for page headers.
 type PageHeaderViewModel struct {
-	Title *TitleWrapper `json:"title,omitempty"`
+	Title    *TitleWrapper       `json:"title,omitempty"`
+	Metadata *PageHeaderMetadata `json:"metadata,omitempty"`
+}
+
+// PageHeaderMetadata holds the rows under the page header title, such as
+// the handle, subscriber count, and video count.
+type PageHeaderMetadata struct {
+	ContentMetadataViewModel *ContentMetadataViewModel `json:"contentMetadataViewModel,omitempty"`
+}
+
+// ContentMetadataViewModel lists rows of metadata text.
+type ContentMetadataViewModel struct {
+	MetadataRows []MetadataRow `json:"metadataRows,omitempty"`
+}
+
+// MetadataRow is one row of a ContentMetadataViewModel.
+type MetadataRow struct {
+	MetadataParts []MetadataPart `json:"metadataParts,omitempty"`
+}
+
+// MetadataPart is one piece of text in a MetadataRow.
+type MetadataPart struct {
+	Text *ViewModelText `json:"text,omitempty"`
 }
 
 // TitleWrapper wraps dynamic text.
@@ -338,29 +490,58 @@ func (t *TextRuns) GetText() string {
 	return strings.Join(parts, "")
 }
 
-// Browse fetches content from a channel or continuation token.
+// Browse fetches the Videos tab of a channel, or the page a continuation
+// token points to.
 func (c *Client) Browse(ctx context.Context, channelID string, continuation string) (*BrowseResponse, error) {
-	req := &BrowseRequest{
-		Context: ClientContext{
-			Client: InnertubeClient{
-				ClientName:    defaultClientName,
-				ClientVersion: defaultClientVersion,
-				HL:            "en",
-				GL:            "US",
-			},
-		},
+	return c.BrowseTab(ctx, channelID, TabVideos, continuation)
+}
+
+// BrowseTab fetches a tab of a channel, or the page a continuation token
+// points to. Continuation tokens belong to the tab they were returned for,
+// so tab is ignored when continuation is set. A response with an error
+// alert, such as for a terminated channel, fails with the error
+// BrowseResponse.Err returns.
+func (c *Client) BrowseTab(ctx context.Context, channelID string, tab ChannelTab, continuation string) (*BrowseResponse, error) {
+	params, ok := tabParams[tab]
+	if !ok {
+		return nil, fmt.Errorf("unknown channel tab %q", tab)
 	}
 
+	req := &BrowseRequest{Context: webContext()}
+
 	if continuation != "" {
 		req.Continuation = continuation
 	} else {
 		req.BrowseID = channelID
-		// Params for the Videos tab
-		req.Params = "EgZ2aWRlb3PyBgQKAjoA"
+		req.Params = params
 	}
 
 	var resp *BrowseResponse
-	err := retry.Do(ctx, c.retryConfig, innertubeErrorClassifier, func(ctx context.Context) error {
+	if err := c.post(ctx, browseEndpoint, "browse", req, &resp); err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// webContext returns the client context of web requests.
+func webContext() ClientContext {
+	return ClientContext{
+		Client: InnertubeClient{
+			ClientName:    defaultClientName,
+			ClientVersion: defaultClientVersion,
+			HL:            "en",
+			GL:            "US",
+		},
+	}
+}
+
+// post sends req as JSON to an Innertube endpoint and decodes the response
+// into resp, retrying transient failures. name labels request errors.
+func (c *Client) post(ctx context.Context, endpoint, name string, req, resp any) error {
+	return retry.Do(ctx, c.retryConfig, innertubeErrorClassifier, func(ctx context.Context) error {
 		body, err := json.Marshal(req)
 		if err != nil {
 			return fmt.Errorf("marshal request: %w", err)
@@ -373,23 +554,17 @@ func (c *Client) Browse(ctx context.Context, channelID string, continuation stri
 			"Referer":      "https://www.youtube.com/",
 		}
 
-		httpResp, err := c.httpClient.Do(ctx, http.MethodPost, browseEndpoint, bytes.NewReader(body), headers)
+		httpResp, err := c.httpClient.Do(ctx, http.MethodPost, endpoint, bytes.NewReader(body), headers)
 		if err != nil {
-			return fmt.Errorf("browse request: %w", err)
+			return fmt.Errorf("%s request: %w", name, err)
 		}
 
-		if err := json.Unmarshal(httpResp.Body, &resp); err != nil {
+		if err := json.Unmarshal(httpResp.Body, resp); err != nil {
 			return fmt.Errorf("unmarshal response: %w", err)
 		}
 
 		return nil
 	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	return resp, nil
 }
 
 // innertubeErrorClassifier determines if an Innertube error is retryable.
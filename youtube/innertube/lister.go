@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	ythttp "ytsync/http"
 	"ytsync/retry"
+	"ytsync/storage"
+	"ytsync/tags"
 	"ytsync/youtube"
 )
 
@@ -25,11 +28,42 @@ type Lister struct {
 	// ContinuationState allows callers to resume pagination.
 	// Set this before calling ListVideos to resume from a previous state.
 	ContinuationState *ContinuationState
+
+	// ProbeConcurrency is the number of player requests sent at a time for
+	// ListOptions.ProbeCaptions (default DefaultProbeConcurrency).
+	ProbeConcurrency int
+
+	// SchemaFailureThreshold is the number of responses in a row that may
+	// hold items but no recognizable videos before listing fails with
+	// youtube.ErrSchemaChanged (default DefaultSchemaFailureThreshold).
+	// Each such response is requested again until then.
+	SchemaFailureThreshold int
+
+	// Extraction selects how videos and continuation tokens are read from
+	// responses (default ExtractAuto).
+	Extraction ExtractionMode
+
+	// Schema holds the JSON paths used by ExtractAuto and ExtractPaths
+	// (default DefaultSchema).
+	Schema *Schema
 }
 
+// DefaultSchemaFailureThreshold is the default Lister.SchemaFailureThreshold.
+const DefaultSchemaFailureThreshold = 3
+
 // ListerOption configures the Innertube lister.
 type ListerOption func(*Lister)
 
+// WithExtraction sets how videos and continuation tokens are read from
+// responses, and the JSON paths used when they are read by path. A nil
+// schema uses DefaultSchema.
+func WithExtraction(mode ExtractionMode, schema *Schema) ListerOption {
+	return func(l *Lister) {
+		l.Extraction = mode
+		l.Schema = schema
+	}
+}
+
 // WithContinuationState sets initial continuation state for resuming.
 func WithContinuationState(state *ContinuationState) ListerOption {
 	return func(l *Lister) {
@@ -65,6 +99,11 @@ func NewListerWithRetry(httpClient *ythttp.Client, retryCfg retry.Config, opts .
 
 // ListVideos fetches videos from the specified channel using the Innertube API.
 // It handles pagination automatically and respects MaxResults from options.
+// ContentTypeStreams lists the Live tab and ContentTypeBoth lists the Videos
+// tab and then the Live tab. A ResumeToken is only applied when a single tab
+// is listed, since tokens do not record which tab they belong to. With
+// ProbeCaptions set, the caption tracks of the listed videos are looked up
+// once listing is done.
 func (l *Lister) ListVideos(ctx context.Context, channelURL string, opts *youtube.ListOptions) ([]youtube.VideoInfo, error) {
 	// Resolve channel ID from URL
 	channelID, err := l.resolveChannelID(channelURL)
@@ -75,10 +114,54 @@ func (l *Lister) ListVideos(ctx context.Context, channelURL string, opts *youtub
 			Err:     err,
 		}
 	}
+	ctx = tags.Default(ctx, tags.Channel, channelID)
+
+	tabs := tabsFor(opts)
+	var allVideos []youtube.VideoInfo
+	for i, tab := range tabs {
+		videos, stopped, err := l.listTab(ctx, channelURL, channelID, tab, opts, len(tabs) == 1, i == len(tabs)-1)
+		if err != nil {
+			if videos == nil {
+				return nil, err
+			}
+			return append(allVideos, videos...), err
+		}
+		allVideos = append(allVideos, videos...)
+		if stopped {
+			break
+		}
+	}
+
+	videos := filterAndSortVideos(allVideos, opts)
+	if opts != nil && opts.ProbeCaptions {
+		if err := l.client.ProbeCaptions(ctx, videos, l.ProbeConcurrency); err != nil {
+			return videos, err
+		}
+	}
+	return videos, nil
+}
+
+// tabsFor returns the channel tabs to list for opts' content type.
+func tabsFor(opts *youtube.ListOptions) []ChannelTab {
+	if opts == nil {
+		return []ChannelTab{TabVideos}
+	}
+	switch opts.ContentType {
+	case youtube.ContentTypeStreams:
+		return []ChannelTab{TabLive}
+	case youtube.ContentTypeBoth:
+		return []ChannelTab{TabVideos, TabLive}
+	}
+	return []ChannelTab{TabVideos}
+}
 
+// listTab pages through one tab of a channel and returns the matching
+// videos. stopped reports that the progress callback asked to stop. On
+// cancellation it returns the videos listed so far with ctx's error.
+func (l *Lister) listTab(ctx context.Context, channelURL, channelID string, tab ChannelTab, opts *youtube.ListOptions, resume, final bool) (videos []youtube.VideoInfo, stopped bool, err error) {
 	// Initialize or use existing continuation state
 	var state *ContinuationState
-	if l.ContinuationState != nil && l.ContinuationState.ChannelID == channelID {
+	if l.ContinuationState != nil && l.ContinuationState.ChannelID == channelID && l.ContinuationState.tab() == tab {
 		state = l.ContinuationState
 		// Check if token is expired
 		if state.IsExpired() {
@@ -86,10 +169,16 @@ func (l *Lister) ListVideos(ctx context.Context, channelURL string, opts *youtub
 		}
 	} else {
 		state = NewContinuationState(channelID)
+		state.Tab = tab
+	}
+	if resume && opts != nil && opts.ResumeToken != "" && state.Token == "" {
+		// Resume from a token checkpointed by the caller
+		state.Token = opts.ResumeToken
 	}
 
 	var allVideos []youtube.VideoInfo
 	var channelName string
+	unparsed := 0
 	maxResults := 0
 	if opts != nil {
 		maxResults = opts.MaxResults
@@ -101,7 +190,8 @@ func (l *Lister) ListVideos(ctx context.Context, channelURL string, opts *youtub
 		if ctx.Err() != nil {
 			// Save state for potential resume
 			l.ContinuationState = state
-			return allVideos, ctx.Err()
+			l.reportTabProgress(opts, state, ctx.Err(), final)
+			return allVideos, false, ctx.Err()
 		}
 
 		// Check if we've reached the requested limit
@@ -110,11 +200,12 @@ func (l *Lister) ListVideos(ctx context.Context, channelURL string, opts *youtub
 		}
 
 		// Fetch a page
-		resp, err := l.client.Browse(ctx, channelID, state.Token)
+		resp, err := l.client.BrowseTab(ctx, channelID, tab, state.Token)
 		if err != nil {
 			// Save state for potential resume
 			l.ContinuationState = state
-			return nil, &youtube.ListerError{
+			l.reportTabProgress(opts, state, err, final)
+			return nil, false, &youtube.ListerError{
 				Source:  "innertube",
 				Channel: channelURL,
 				Err:     fmt.Errorf("browse request: %w", err),
@@ -127,21 +218,37 @@ func (l *Lister) ListVideos(ctx context.Context, channelURL string, opts *youtub
 		}
 
 		// Extract videos from response
-		videos := ExtractVideos(resp, channelID, channelName)
+		videos := l.extractVideos(resp, channelID, channelName)
+		if len(videos) == 0 && contentItemCount(resp) > 0 {
+			// Items came back but none could be read. Ask again in case
+			// the response was an odd one before blaming the layout.
+			unparsed++
+			if unparsed < l.schemaFailureThreshold() {
+				continue
+			}
+			l.ContinuationState = state
+			err := &youtube.ListerError{
+				Source:  "innertube",
+				Channel: channelURL,
+				Err:     fmt.Errorf("%w: %d %s tab responses in a row had items but no videos", youtube.ErrSchemaChanged, unparsed, tab),
+			}
+			l.reportTabProgress(opts, state, err, final)
+			return allVideos, false, err
+		}
+		unparsed = 0
 		for _, v := range videos {
 			info := videoDataToInfo(v)
 
-			// Apply published filter if specified
-			if opts != nil && !opts.PublishedAfter.IsZero() {
-				if info.Published.Before(opts.PublishedAfter) {
-					// We've gone past the filter date, stop pagination
-					// (videos are typically sorted by date, newest first)
-					l.ContinuationState = state
-					return filterAndSortVideos(allVideos, opts), nil
-				}
+			// Stop once we've gone past the start of the date range
+			// (videos are typically sorted by date, newest first)
+			if opts.BeforeRange(info) {
+				l.ContinuationState = state
+				return allVideos, false, nil
 			}
 
-			allVideos = append(allVideos, info)
+			if opts.Matches(info) {
+				allVideos = append(allVideos, info)
+			}
 
 			// Update state with last video
 			if len(videos) > 0 {
@@ -152,8 +259,17 @@ func (l *Lister) ListVideos(ctx context.Context, channelURL string, opts *youtub
 		state.IncrementVideos(len(videos))
 
 		// Get next continuation token
-		nextToken := ExtractContinuationToken(resp)
+		nextToken := l.extractContinuationToken(resp)
+		if nextToken == state.Token {
+			// A token that leads back to this page would never end
+			nextToken = ""
+		}
 		state.UpdateToken(nextToken, state.LastVideoID)
+		if err := l.reportTabProgress(opts, state, nil, final); err != nil {
+			// Callback requested stop - return what we have
+			l.ContinuationState = state
+			return allVideos, true, nil
+		}
 
 		// No more pages
 		if !state.HasMore() {
@@ -164,7 +280,44 @@ func (l *Lister) ListVideos(ctx context.Context, channelURL string, opts *youtub
 	// Save final state
 	l.ContinuationState = state
 
-	return filterAndSortVideos(allVideos, opts), nil
+	return allVideos, false, nil
+}
+
+// extractVideos reads the videos of resp as l.Extraction selects.
+func (l *Lister) extractVideos(resp *BrowseResponse, channelID, channelName string) []VideoData {
+	switch l.Extraction {
+	case ExtractTyped:
+		return ExtractVideos(resp, channelID, channelName)
+	case ExtractPaths:
+		return ExtractVideosWithSchema(resp, l.Schema, channelID, channelName)
+	}
+	if videos := ExtractVideos(resp, channelID, channelName); len(videos) > 0 {
+		return videos
+	}
+	return ExtractVideosWithSchema(resp, l.Schema, channelID, channelName)
+}
+
+// extractContinuationToken reads the next page's token from resp as
+// l.Extraction selects.
+func (l *Lister) extractContinuationToken(resp *BrowseResponse) string {
+	switch l.Extraction {
+	case ExtractTyped:
+		return ExtractContinuationToken(resp)
+	case ExtractPaths:
+		return ExtractContinuationTokenWithSchema(resp, l.Schema)
+	}
+	if token := ExtractContinuationToken(resp); token != "" {
+		return token
+	}
+	return ExtractContinuationTokenWithSchema(resp, l.Schema)
+}
+
+// schemaFailureThreshold returns SchemaFailureThreshold or its default.
+func (l *Lister) schemaFailureThreshold() int {
+	if l.SchemaFailureThreshold > 0 {
+		return l.SchemaFailureThreshold
+	}
+	return DefaultSchemaFailureThreshold
 }
 
 // SupportsFullHistory returns true - Innertube API can retrieve all videos.
@@ -172,6 +325,55 @@ func (l *Lister) SupportsFullHistory() bool {
 	return true
 }
 
+// PaginationStrategy reports that Innertube listings page with
+// continuation tokens, which SyncManager checkpoints so interrupted syncs
+// can resume.
+func (l *Lister) PaginationStrategy() storage.PaginationStrategy {
+	return storage.StrategyInnertube
+}
+
+// VideoCount reads the channel's video count from the header of its
+// Videos tab. Channels with many videos may only show an abbreviated count,
+// such as "1.2K videos", for which it returns an error wrapping
+// youtube.ErrVideoCountUnavailable.
+func (l *Lister) VideoCount(ctx context.Context, channelURL string) (int, error) {
+	channelID, err := l.resolveChannelID(channelURL)
+	if err != nil {
+		return 0, &youtube.ListerError{Source: "innertube", Channel: channelURL, Err: err}
+	}
+	resp, err := l.client.BrowseTab(tags.Default(ctx, tags.Channel, channelID), channelID, TabVideos, "")
+	if err != nil {
+		return 0, &youtube.ListerError{Source: "innertube", Channel: channelURL, Err: err}
+	}
+	text := extractVideoCountText(resp)
+	count, ok := parseVideoCount(text)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s header shows %q", youtube.ErrVideoCountUnavailable, channelID, text)
+	}
+	return count, nil
+}
+
+// reportProgress passes the pagination state to opts.OnProgress, if set,
+// and returns the callback's error.
+func (l *Lister) reportProgress(opts *youtube.ListOptions, state *ContinuationState, err error) error {
+	return l.reportTabProgress(opts, state, err, true)
+}
+
+// reportTabProgress is reportProgress for one of several tabs listed in
+// turn; the listing is only complete once the final tab is.
+func (l *Lister) reportTabProgress(opts *youtube.ListOptions, state *ContinuationState, err error, final bool) error {
+	if opts == nil || opts.OnProgress == nil {
+		return nil
+	}
+	return opts.OnProgress(&youtube.PaginationProgress{
+		Token:           state.Token,
+		VideosRetrieved: state.VideosRetrieved,
+		LastVideoID:     state.LastVideoID,
+		Complete:        err == nil && !state.HasMore() && final,
+		Error:           err,
+	})
+}
+
 // GetContinuationState returns the current continuation state for persistence.
 func (l *Lister) GetContinuationState() *ContinuationState {
 	return l.ContinuationState
@@ -240,17 +442,67 @@ func videoDataToInfo(v VideoData) youtube.VideoInfo {
 		info.ViewCount = parseViewCount(v.ViewCount)
 	}
 
+	switch {
+	case v.IsShort || v.OverlayStyle == "SHORTS":
+		info.Type = youtube.VideoTypeShort
+	case isStreamData(v):
+		info.Type = youtube.VideoTypeStream
+	}
+
+	for _, style := range v.BadgeStyles {
+		if style == "BADGE_STYLE_TYPE_MEMBERS_ONLY" {
+			info.IsMembersOnly = true
+		}
+	}
+	info.LiveStatus = liveStatusFromData(v)
+	if info.LiveStatus == youtube.LiveStatusUpcoming {
+		if secs, err := strconv.ParseInt(v.StartTime, 10, 64); err == nil && secs > 0 {
+			info.ScheduledStartTime = time.Unix(secs, 0).UTC()
+		}
+	}
+	info.IsPremiere = strings.HasPrefix(strings.ToLower(v.UpcomingText), "premiere") ||
+		strings.HasPrefix(strings.ToLower(v.Published), "premiered")
+
 	return info
 }
 
+// liveStatusFromData classifies a video's live state from its badges,
+// thumbnail overlay, and upcoming event data.
+func liveStatusFromData(v VideoData) youtube.LiveStatus {
+	for _, style := range v.BadgeStyles {
+		if style == "BADGE_STYLE_TYPE_LIVE_NOW" {
+			return youtube.LiveStatusLive
+		}
+	}
+	switch {
+	case v.OverlayStyle == "LIVE" || strings.Contains(strings.ToLower(v.ViewCount), "watching"):
+		return youtube.LiveStatusLive
+	case v.OverlayStyle == "UPCOMING" || v.StartTime != "":
+		return youtube.LiveStatusUpcoming
+	}
+	published := strings.ToLower(v.Published)
+	if strings.HasPrefix(published, "streamed") || strings.HasPrefix(published, "premiered") {
+		return youtube.LiveStatusEnded
+	}
+	return youtube.LiveStatusNone
+}
+
+// isStreamData reports whether renderer text marks the video as a live
+// stream ("12 watching") or a stream recording ("Streamed 2 days ago").
+func isStreamData(v VideoData) bool {
+	return strings.HasPrefix(strings.ToLower(v.Published), "streamed") ||
+		strings.Contains(strings.ToLower(v.ViewCount), "watching")
+}
+
 // parseRelativeTime converts relative time strings to absolute time.
 func parseRelativeTime(s string) time.Time {
 	s = strings.ToLower(strings.TrimSpace(s))
 
 	now := time.Now()
 
-	// Handle "Streamed X ago" format
+	// Handle "Streamed X ago" and "Premiered X ago" formats
 	s = strings.TrimPrefix(s, "streamed ")
+	s = strings.TrimPrefix(s, "premiered ")
 
 	// Common patterns
 	patterns := []struct {
@@ -341,22 +593,42 @@ func parseViewCount(s string) int64 {
 	return 0
 }
 
+// parseVideoCount converts an exact video count such as "1,234 videos" or
+// "No videos" to an int. It reports false for abbreviated counts such as
+// "1.2K videos", which are too coarse to tell whether videos were added.
+func parseVideoCount(s string) (int, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	number, ok := strings.CutSuffix(s, " videos")
+	if !ok {
+		number, ok = strings.CutSuffix(s, " video")
+	}
+	if !ok {
+		return 0, false
+	}
+	if number == "no" {
+		return 0, true
+	}
+	count, err := strconv.Atoi(strings.ReplaceAll(number, ",", ""))
+	if err != nil || count < 0 {
+		return 0, false
+	}
+	return count, true
+}
+
 // filterAndSortVideos applies filters and sorting from ListOptions.
 func filterAndSortVideos(videos []youtube.VideoInfo, opts *youtube.ListOptions) []youtube.VideoInfo {
 	if opts == nil {
 		return videos
 	}
 
-	// Apply PublishedAfter filter
-	if !opts.PublishedAfter.IsZero() {
-		filtered := make([]youtube.VideoInfo, 0, len(videos))
-		for _, v := range videos {
-			if !v.Published.IsZero() && v.Published.After(opts.PublishedAfter) {
-				filtered = append(filtered, v)
-			}
+	// Apply date range and content filters
+	filtered := make([]youtube.VideoInfo, 0, len(videos))
+	for _, v := range videos {
+		if opts.Matches(v) {
+			filtered = append(filtered, v)
 		}
-		videos = filtered
 	}
+	videos = filtered
 
 	// Apply MaxResults limit
 	if opts.MaxResults > 0 && len(videos) > opts.MaxResults {
@@ -1,8 +1,17 @@
 package innertube
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
+	ythttp "ytsync/http"
+	"ytsync/youtube"
 )
 
 func TestResolveChannelID(t *testing.T) {
@@ -171,6 +180,81 @@ func TestParseViewCount(t *testing.T) {
 	}
 }
 
+func TestParseVideoCount(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+		ok    bool
+	}{
+		{"1,234 videos", 1234, true},
+		{"523 videos", 523, true},
+		{"1 video", 1, true},
+		{"No videos", 0, true},
+		{"1.2K videos", 0, false},
+		{"12K videos", 0, false},
+		{"1.5M subscribers", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseVideoCount(tt.input)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseVideoCount(%q) = %d, %v; want %d, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestListerVideoCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "c4 header",
+			body: `{"header": {"c4TabbedHeaderRenderer": {"channelId": "UCsXVk37bltHxD1rDPwtNM8Q", "videosCountText": {"runs": [{"text": "1,234"}, {"text": " videos"}]}}}}`,
+			want: 1234,
+		},
+		{
+			name: "page header",
+			body: `{"header": {"pageHeaderRenderer": {"content": {"pageHeaderViewModel": {"metadata": {"contentMetadataViewModel": {"metadataRows": [
+				{"metadataParts": [{"text": {"content": "@channel"}}]},
+				{"metadataParts": [{"text": {"content": "12K subscribers"}}, {"text": {"content": "87 videos"}}]}
+			]}}}}}}}`,
+			want: 87,
+		},
+		{
+			name:    "abbreviated count",
+			body:    `{"header": {"pageHeaderRenderer": {"content": {"pageHeaderViewModel": {"metadata": {"contentMetadataViewModel": {"metadataRows": [{"metadataParts": [{"text": {"content": "1.2K videos"}}]}]}}}}}}}`,
+			wantErr: true,
+		},
+		{name: "no header", body: `{}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ythttp.DefaultConfig()
+			cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(tt.body)),
+					Request:    req,
+				}, nil
+			})
+			got, err := NewLister(ythttp.New(cfg)).VideoCount(context.Background(), "UCsXVk37bltHxD1rDPwtNM8Q")
+			if tt.wantErr {
+				if !errors.Is(err, youtube.ErrVideoCountUnavailable) {
+					t.Fatalf("VideoCount() error = %v, want ErrVideoCountUnavailable", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("VideoCount() = %d, %v; want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
 func TestVideoDataToInfo(t *testing.T) {
 	data := VideoData{
 		VideoID:     "abc123",
@@ -213,6 +297,89 @@ func TestVideoDataToInfo(t *testing.T) {
 	if info.Published.IsZero() {
 		t.Error("Published should not be zero")
 	}
+	if info.Type != "" {
+		t.Errorf("Type = %q, want empty for a regular upload", info.Type)
+	}
+
+	for _, stream := range []VideoData{
+		{VideoID: "rec", Published: "Streamed 3 days ago"},
+		{VideoID: "live", ViewCount: "1,234 watching"},
+	} {
+		if got := videoDataToInfo(stream).Type; got != youtube.VideoTypeStream {
+			t.Errorf("videoDataToInfo(%s).Type = %q, want %q", stream.VideoID, got, youtube.VideoTypeStream)
+		}
+	}
+}
+
+func TestVideoRendererLiveData(t *testing.T) {
+	var renderer VideoRenderer
+	if err := json.Unmarshal([]byte(`{
+		"videoId": "prem",
+		"title": {"runs": [{"text": "Launch"}]},
+		"badges": [{"metadataBadgeRenderer": {"style": "BADGE_STYLE_TYPE_MEMBERS_ONLY", "label": "Members only"}}],
+		"upcomingEventData": {"startTime": "1792515600", "upcomingEventText": {"runs": [{"text": "Premieres "}, {"text": "10/20/26, 5:00 PM"}]}},
+		"thumbnailOverlays": [{"thumbnailOverlayTimeStatusRenderer": {"style": "UPCOMING"}}]
+	}`), &renderer); err != nil {
+		t.Fatal(err)
+	}
+
+	info := videoDataToInfo(*videoRendererToData(&renderer, "UCtest", "Test"))
+	if !info.IsMembersOnly || !info.IsPremiere || info.LiveStatus != youtube.LiveStatusUpcoming {
+		t.Errorf("info = %+v, want an upcoming members-only premiere", info)
+	}
+	if !info.ScheduledStartTime.Equal(time.Unix(1792515600, 0)) {
+		t.Errorf("ScheduledStartTime = %v", info.ScheduledStartTime)
+	}
+
+	for _, tt := range []struct {
+		data VideoData
+		want youtube.LiveStatus
+	}{
+		{VideoData{BadgeStyles: []string{"BADGE_STYLE_TYPE_LIVE_NOW"}}, youtube.LiveStatusLive},
+		{VideoData{OverlayStyle: "LIVE"}, youtube.LiveStatusLive},
+		{VideoData{Published: "Streamed 2 days ago"}, youtube.LiveStatusEnded},
+		{VideoData{Published: "Premiered 1 day ago"}, youtube.LiveStatusEnded},
+		{VideoData{Published: "2 days ago", OverlayStyle: "DEFAULT"}, youtube.LiveStatusNone},
+	} {
+		if got := liveStatusFromData(tt.data); got != tt.want {
+			t.Errorf("liveStatusFromData(%+v) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+
+	premiered := videoDataToInfo(VideoData{VideoID: "p", Published: "Premiered 1 day ago"})
+	if !premiered.IsPremiere || premiered.Published.IsZero() {
+		t.Errorf("premiered = %+v, want IsPremiere with a publish time", premiered)
+	}
+}
+
+func TestTabsFor(t *testing.T) {
+	tests := []struct {
+		contentType youtube.ContentType
+		want        []ChannelTab
+	}{
+		{youtube.ContentTypeVideos, []ChannelTab{TabVideos}},
+		{youtube.ContentTypeStreams, []ChannelTab{TabLive}},
+		{youtube.ContentTypeBoth, []ChannelTab{TabVideos, TabLive}},
+	}
+	for _, tt := range tests {
+		got := tabsFor(&youtube.ListOptions{ContentType: tt.contentType})
+		if len(got) != len(tt.want) || got[0] != tt.want[0] || got[len(got)-1] != tt.want[len(tt.want)-1] {
+			t.Errorf("tabsFor(%v) = %v, want %v", tt.contentType, got, tt.want)
+		}
+	}
+	for tab, params := range tabParams {
+		decoded, err := base64.StdEncoding.DecodeString(params)
+		if err != nil || !strings.Contains(string(decoded), string(tab)) {
+			t.Errorf("params for %s = %q do not encode the tab name", tab, params)
+		}
+	}
+}
+
+func TestVideoDataToInfo_Short(t *testing.T) {
+	info := videoDataToInfo(VideoData{VideoID: "short1", ViewCount: "1.2M views", IsShort: true})
+	if info.Type != youtube.VideoTypeShort || info.ViewCount != 1200000 {
+		t.Errorf("videoDataToInfo() = %+v, want a short with 1.2M views", info)
+	}
 }
 
 func TestListerSupportsFullHistory(t *testing.T) {
@@ -221,3 +388,73 @@ func TestListerSupportsFullHistory(t *testing.T) {
 		t.Error("Innertube lister should support full history")
 	}
 }
+
+func TestListerReportProgress(t *testing.T) {
+	lister := &Lister{}
+	state := NewContinuationState("UCuAXFkgsw1L7xaCfnd5JJOw")
+	state.IncrementVideos(30)
+	state.UpdateToken("next-token", "abc123")
+
+	var got *youtube.PaginationProgress
+	opts := &youtube.ListOptions{OnProgress: func(p *youtube.PaginationProgress) error {
+		got = p
+		return nil
+	}}
+	if err := lister.reportProgress(opts, state, nil); err != nil {
+		t.Fatalf("reportProgress() error = %v", err)
+	}
+	if got.Token != "next-token" || got.VideosRetrieved != 30 || got.LastVideoID != "abc123" || got.Complete {
+		t.Errorf("progress = %+v", got)
+	}
+	if lister.reportProgress(nil, state, nil) != nil {
+		t.Error("reportProgress() without options returned an error")
+	}
+}
+
+func TestListVideos_SchemaChanged(t *testing.T) {
+	const changedLayout = `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"richGridRenderer": {"contents": [
+		{"richItemRenderer": {"content": {"videoLockupViewModel": {"id": "abc"}}}},
+		{"richItemRenderer": {"content": {"videoLockupViewModel": {"id": "def"}}}}
+	]}}}}]}}}`
+	const noVideos = `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"sectionListRenderer": {"contents": [
+		{"itemSectionRenderer": {"contents": [{"messageRenderer": {"text": {"simpleText": "This channel has no videos"}}}]}}
+	]}}}}]}}}`
+
+	tests := []struct {
+		name         string
+		body         string
+		wantErr      bool
+		wantRequests int
+	}{
+		{name: "unrecognized items", body: changedLayout, wantErr: true, wantRequests: 2},
+		{name: "empty channel", body: noVideos, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			cfg := ythttp.DefaultConfig()
+			cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				requests++
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(tt.body)),
+					Request:    req,
+				}, nil
+			})
+			lister := NewLister(ythttp.New(cfg))
+			lister.SchemaFailureThreshold = 2
+
+			videos, err := lister.ListVideos(context.Background(), "UCsXVk37bltHxD1rDPwtNM8Q", nil)
+			if got := errors.Is(err, youtube.ErrSchemaChanged); got != tt.wantErr {
+				t.Fatalf("ListVideos() error = %v, want ErrSchemaChanged %v", err, tt.wantErr)
+			}
+			if len(videos) != 0 {
+				t.Errorf("ListVideos() = %d videos, want none", len(videos))
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
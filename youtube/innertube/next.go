@@ -0,0 +1,497 @@
+package innertube
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"ytsync/youtube"
+)
+
+const (
+	// nextEndpoint is the Innertube API endpoint behind a video's watch
+	// page.
+	nextEndpoint = "https://www.youtube.com/youtubei/v1/next"
+
+	// chaptersPanelID identifies the engagement panel listing the
+	// chapters defined in a video's description.
+	chaptersPanelID = "engagement-panel-macro-markers-description-chapters"
+	// chaptersMarkerKey is the player bar markers map key of description
+	// chapters.
+	chaptersMarkerKey = "DESCRIPTION_CHAPTERS"
+	// commentsSectionID identifies the watch page section holding the
+	// comments continuation.
+	commentsSectionID = "comment-item-section"
+)
+
+// NextRequest represents a request to the next endpoint.
+type NextRequest struct {
+	Context      ClientContext `json:"context"`
+	VideoID      string        `json:"videoId,omitempty"`
+	Continuation string        `json:"continuation,omitempty"`
+}
+
+// NextResponse represents the response from the next endpoint: the watch
+// page of a video without its player.
+type NextResponse struct {
+	Contents         *WatchContents    `json:"contents,omitempty"`
+	PlayerOverlays   *PlayerOverlays   `json:"playerOverlays,omitempty"`
+	EngagementPanels []EngagementPanel `json:"engagementPanels,omitempty"`
+}
+
+// WatchContents holds the watch page layout.
+type WatchContents struct {
+	TwoColumnWatchNextResults *TwoColumnWatchNextResults `json:"twoColumnWatchNextResults,omitempty"`
+}
+
+// TwoColumnWatchNextResults holds the main column below the player and
+// the column of related videos.
+type TwoColumnWatchNextResults struct {
+	Results          *WatchResults     `json:"results,omitempty"`
+	SecondaryResults *SecondaryResults `json:"secondaryResults,omitempty"`
+}
+
+// WatchResults wraps the main column.
+type WatchResults struct {
+	Results *WatchResultsList `json:"results,omitempty"`
+}
+
+// WatchResultsList holds the sections of the main column.
+type WatchResultsList struct {
+	Contents []WatchContent `json:"contents,omitempty"`
+}
+
+// WatchContent is a section of the main column.
+type WatchContent struct {
+	VideoPrimaryInfoRenderer   *VideoPrimaryInfoRenderer   `json:"videoPrimaryInfoRenderer,omitempty"`
+	VideoSecondaryInfoRenderer *VideoSecondaryInfoRenderer `json:"videoSecondaryInfoRenderer,omitempty"`
+	ItemSectionRenderer        *ItemSectionRenderer        `json:"itemSectionRenderer,omitempty"`
+}
+
+// VideoPrimaryInfoRenderer holds the title, view count, upload date, and
+// like button of a video.
+type VideoPrimaryInfoRenderer struct {
+	Title        *TextRuns     `json:"title,omitempty"`
+	ViewCount    *ViewCount    `json:"viewCount,omitempty"`
+	DateText     *SimpleText   `json:"dateText,omitempty"`
+	VideoActions *VideoActions `json:"videoActions,omitempty"`
+}
+
+// ViewCount wraps the view count of a video.
+type ViewCount struct {
+	VideoViewCountRenderer *struct {
+		ViewCount         *SimpleText `json:"viewCount,omitempty"` // e.g. "1,234 views"
+		OriginalViewCount string      `json:"originalViewCount,omitempty"`
+	} `json:"videoViewCountRenderer,omitempty"`
+}
+
+// VideoActions holds the buttons below a video.
+type VideoActions struct {
+	MenuRenderer *struct {
+		TopLevelButtons []TopLevelButton `json:"topLevelButtons,omitempty"`
+	} `json:"menuRenderer,omitempty"`
+}
+
+// TopLevelButton is a button below a video. Only the like button is
+// decoded.
+type TopLevelButton struct {
+	SegmentedLikeDislikeButtonViewModel *struct {
+		LikeButtonViewModel *struct {
+			LikeButtonViewModel *struct {
+				ToggleButtonViewModel *struct {
+					ToggleButtonViewModel *struct {
+						DefaultButtonViewModel *struct {
+							ButtonViewModel *ButtonViewModel `json:"buttonViewModel,omitempty"`
+						} `json:"defaultButtonViewModel,omitempty"`
+					} `json:"toggleButtonViewModel,omitempty"`
+				} `json:"toggleButtonViewModel,omitempty"`
+			} `json:"likeButtonViewModel,omitempty"`
+		} `json:"likeButtonViewModel,omitempty"`
+	} `json:"segmentedLikeDislikeButtonViewModel,omitempty"`
+}
+
+// ButtonViewModel is the label of a button.
+type ButtonViewModel struct {
+	Title             string `json:"title,omitempty"`             // e.g. "12K"
+	AccessibilityText string `json:"accessibilityText,omitempty"` // e.g. "like this video along with 12,345 other people"
+}
+
+// VideoSecondaryInfoRenderer holds the owner and description of a video.
+type VideoSecondaryInfoRenderer struct {
+	Owner *struct {
+		VideoOwnerRenderer *struct {
+			Title              *TextRuns `json:"title,omitempty"`
+			NavigationEndpoint *Endpoint `json:"navigationEndpoint,omitempty"`
+		} `json:"videoOwnerRenderer,omitempty"`
+	} `json:"owner,omitempty"`
+	// AttributedDescription is the full description in current layouts.
+	AttributedDescription *ViewModelText `json:"attributedDescription,omitempty"`
+	// Description is the full description in older layouts.
+	Description *TextRuns `json:"description,omitempty"`
+}
+
+// SecondaryResults holds the related videos column.
+type SecondaryResults struct {
+	SecondaryResults *struct {
+		Results []SecondaryResult `json:"results,omitempty"`
+	} `json:"secondaryResults,omitempty"`
+}
+
+// SecondaryResult is an entry of the related videos column.
+type SecondaryResult struct {
+	CompactVideoRenderer *CompactVideoRenderer `json:"compactVideoRenderer,omitempty"`
+	LockupViewModel      *LockupViewModel      `json:"lockupViewModel,omitempty"`
+}
+
+// CompactVideoRenderer is a related video.
+type CompactVideoRenderer struct {
+	VideoID           string         `json:"videoId,omitempty"`
+	Title             *TextRuns      `json:"title,omitempty"`
+	Thumbnail         *ThumbnailList `json:"thumbnail,omitempty"`
+	LongBylineText    *TextRuns      `json:"longBylineText,omitempty"`
+	PublishedTimeText *SimpleText    `json:"publishedTimeText,omitempty"`
+	LengthText        *SimpleText    `json:"lengthText,omitempty"`
+	ViewCountText     *SimpleText    `json:"viewCountText,omitempty"`
+}
+
+// LockupViewModel is a related item in the view-model layout. Only video
+// lockups are decoded.
+type LockupViewModel struct {
+	ContentID   string `json:"contentId,omitempty"`
+	ContentType string `json:"contentType,omitempty"` // e.g. LOCKUP_CONTENT_TYPE_VIDEO
+	Metadata    *struct {
+		LockupMetadataViewModel *struct {
+			Title *ViewModelText `json:"title,omitempty"`
+		} `json:"lockupMetadataViewModel,omitempty"`
+	} `json:"metadata,omitempty"`
+}
+
+// PlayerOverlays holds the overlays of the player, including the chapter
+// markers of its progress bar.
+type PlayerOverlays struct {
+	PlayerOverlayRenderer *struct {
+		DecoratedPlayerBarRenderer *struct {
+			DecoratedPlayerBarRenderer *struct {
+				PlayerBar *struct {
+					MultiMarkersPlayerBarRenderer *struct {
+						MarkersMap []MarkersMapEntry `json:"markersMap,omitempty"`
+					} `json:"multiMarkersPlayerBarRenderer,omitempty"`
+				} `json:"playerBar,omitempty"`
+			} `json:"decoratedPlayerBarRenderer,omitempty"`
+		} `json:"decoratedPlayerBarRenderer,omitempty"`
+	} `json:"playerOverlayRenderer,omitempty"`
+}
+
+// MarkersMapEntry is a set of progress bar markers, keyed by kind.
+type MarkersMapEntry struct {
+	Key   string `json:"key,omitempty"` // e.g. DESCRIPTION_CHAPTERS
+	Value *struct {
+		Chapters []struct {
+			ChapterRenderer *struct {
+				Title                *SimpleText `json:"title,omitempty"`
+				TimeRangeStartMillis int64       `json:"timeRangeStartMillis"`
+			} `json:"chapterRenderer,omitempty"`
+		} `json:"chapters,omitempty"`
+	} `json:"value,omitempty"`
+}
+
+// EngagementPanel is a side panel of the watch page, such as the chapter
+// list.
+type EngagementPanel struct {
+	EngagementPanelSectionListRenderer *struct {
+		PanelIdentifier string `json:"panelIdentifier,omitempty"`
+		Content         *struct {
+			MacroMarkersListRenderer *struct {
+				Contents []MacroMarkersListItem `json:"contents,omitempty"`
+			} `json:"macroMarkersListRenderer,omitempty"`
+		} `json:"content,omitempty"`
+	} `json:"engagementPanelSectionListRenderer,omitempty"`
+}
+
+// MacroMarkersListItem is an entry of a macro markers panel: a chapter or
+// key moment.
+type MacroMarkersListItem struct {
+	MacroMarkersListItemRenderer *struct {
+		Title           *SimpleText `json:"title,omitempty"`
+		TimeDescription *SimpleText `json:"timeDescription,omitempty"` // e.g. "1:05"
+		OnTap           *struct {
+			WatchEndpoint *struct {
+				StartTimeSeconds float64 `json:"startTimeSeconds"`
+			} `json:"watchEndpoint,omitempty"`
+		} `json:"onTap,omitempty"`
+	} `json:"macroMarkersListItemRenderer,omitempty"`
+}
+
+// WatchDetails is the per-video data of a watch page that channel browse
+// pages do not include.
+type WatchDetails struct {
+	Title       string
+	Description string
+	ChannelID   string
+	ChannelName string
+	// DateText is the upload date as displayed, e.g. "Jan 2, 2024".
+	DateText  string
+	ViewCount int64
+	// LikeCount is zero when likes are hidden.
+	LikeCount int64
+	// Chapters are the description chapters. The last chapter's EndTime
+	// is zero, since the next endpoint does not report the video length.
+	Chapters []youtube.Chapter
+	// CommentsContinuation is the token that loads the first page of
+	// comments from the next endpoint, or empty if comments are off.
+	CommentsContinuation string
+	// Related are the videos of the related column.
+	Related []VideoData
+}
+
+// Next fetches the watch page data of a video.
+func (c *Client) Next(ctx context.Context, videoID string) (*NextResponse, error) {
+	req := &NextRequest{Context: webContext(), VideoID: videoID}
+
+	var resp *NextResponse
+	if err := c.post(ctx, nextEndpoint, "next", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ExtractWatchDetails collects the details of a video from a next
+// response. Fields missing from the response are left empty.
+func ExtractWatchDetails(resp *NextResponse) *WatchDetails {
+	details := &WatchDetails{}
+	if resp == nil {
+		return details
+	}
+
+	for _, content := range watchContents(resp) {
+		switch {
+		case content.VideoPrimaryInfoRenderer != nil:
+			primary := content.VideoPrimaryInfoRenderer
+			details.Title = primary.Title.GetText()
+			if primary.DateText != nil {
+				details.DateText = primary.DateText.SimpleText
+			}
+			if vc := primary.ViewCount; vc != nil && vc.VideoViewCountRenderer != nil && vc.VideoViewCountRenderer.ViewCount != nil {
+				details.ViewCount = parseViewCount(vc.VideoViewCountRenderer.ViewCount.SimpleText)
+			}
+			details.LikeCount = extractLikeCount(primary.VideoActions)
+		case content.VideoSecondaryInfoRenderer != nil:
+			secondary := content.VideoSecondaryInfoRenderer
+			details.Description = extractDescription(secondary)
+			if owner := secondary.Owner; owner != nil && owner.VideoOwnerRenderer != nil {
+				details.ChannelName = owner.VideoOwnerRenderer.Title.GetText()
+				if ep := owner.VideoOwnerRenderer.NavigationEndpoint; ep != nil && ep.BrowseEndpoint != nil {
+					details.ChannelID = ep.BrowseEndpoint.BrowseID
+				}
+			}
+		case content.ItemSectionRenderer != nil:
+			if token := extractCommentsContinuation(content.ItemSectionRenderer); token != "" {
+				details.CommentsContinuation = token
+			}
+		}
+	}
+
+	details.Chapters = extractWatchChapters(resp)
+	details.Related = extractRelated(resp)
+	return details
+}
+
+// watchContents returns the sections of the main column of a watch page.
+func watchContents(resp *NextResponse) []WatchContent {
+	if resp.Contents == nil || resp.Contents.TwoColumnWatchNextResults == nil {
+		return nil
+	}
+	results := resp.Contents.TwoColumnWatchNextResults.Results
+	if results == nil || results.Results == nil {
+		return nil
+	}
+	return results.Results.Contents
+}
+
+// extractDescription returns the full description, preferring the current
+// attributed layout.
+func extractDescription(r *VideoSecondaryInfoRenderer) string {
+	if r.AttributedDescription != nil && r.AttributedDescription.Content != "" {
+		return r.AttributedDescription.Content
+	}
+	return r.Description.GetText()
+}
+
+// likeCountPattern finds the exact count in the like button's accessibility
+// text.
+var likeCountPattern = regexp.MustCompile(`\d[\d,]*`)
+
+// extractLikeCount returns the like count from the like button, preferring
+// the exact count of its accessibility text over the abbreviated title.
+func extractLikeCount(actions *VideoActions) int64 {
+	if actions == nil || actions.MenuRenderer == nil {
+		return 0
+	}
+	for _, b := range actions.MenuRenderer.TopLevelButtons {
+		button := likeButton(b)
+		if button == nil {
+			continue
+		}
+		if m := likeCountPattern.FindString(button.AccessibilityText); m != "" {
+			if n, err := strconv.ParseInt(strings.ReplaceAll(m, ",", ""), 10, 64); err == nil {
+				return n
+			}
+		}
+		return parseViewCount(button.Title)
+	}
+	return 0
+}
+
+// likeButton returns the label of a segmented like button, or nil for any
+// other button.
+func likeButton(b TopLevelButton) *ButtonViewModel {
+	seg := b.SegmentedLikeDislikeButtonViewModel
+	if seg == nil || seg.LikeButtonViewModel == nil || seg.LikeButtonViewModel.LikeButtonViewModel == nil {
+		return nil
+	}
+	toggle := seg.LikeButtonViewModel.LikeButtonViewModel.ToggleButtonViewModel
+	if toggle == nil || toggle.ToggleButtonViewModel == nil || toggle.ToggleButtonViewModel.DefaultButtonViewModel == nil {
+		return nil
+	}
+	return toggle.ToggleButtonViewModel.DefaultButtonViewModel.ButtonViewModel
+}
+
+// extractCommentsContinuation returns the comments token of the comments
+// section, or empty for any other section.
+func extractCommentsContinuation(section *ItemSectionRenderer) string {
+	if section.SectionIdentifier != commentsSectionID {
+		return ""
+	}
+	for _, item := range section.Contents {
+		if item.ContinuationItemRenderer == nil {
+			continue
+		}
+		if token := extractTokenFromContinuationRenderer(item.ContinuationItemRenderer); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// extractWatchChapters returns the description chapters from the chapters
+// panel, or from the player bar markers if the panel is missing.
+func extractWatchChapters(resp *NextResponse) []youtube.Chapter {
+	var chapters []youtube.Chapter
+	for _, panel := range resp.EngagementPanels {
+		p := panel.EngagementPanelSectionListRenderer
+		if p == nil || p.PanelIdentifier != chaptersPanelID || p.Content == nil || p.Content.MacroMarkersListRenderer == nil {
+			continue
+		}
+		for _, item := range p.Content.MacroMarkersListRenderer.Contents {
+			marker := item.MacroMarkersListItemRenderer
+			if marker == nil || marker.Title == nil {
+				continue
+			}
+			var start float64
+			if marker.OnTap != nil && marker.OnTap.WatchEndpoint != nil {
+				start = marker.OnTap.WatchEndpoint.StartTimeSeconds
+			} else if marker.TimeDescription != nil {
+				start = parseDuration(marker.TimeDescription.SimpleText).Seconds()
+			}
+			chapters = append(chapters, youtube.Chapter{Title: marker.Title.SimpleText, StartTime: start})
+		}
+		break
+	}
+
+	if len(chapters) == 0 {
+		chapters = playerBarChapters(resp.PlayerOverlays)
+	}
+	for i := 0; i+1 < len(chapters); i++ {
+		chapters[i].EndTime = chapters[i+1].StartTime
+	}
+	return chapters
+}
+
+// playerBarChapters returns the description chapters marked on the player
+// progress bar.
+func playerBarChapters(overlays *PlayerOverlays) []youtube.Chapter {
+	if overlays == nil || overlays.PlayerOverlayRenderer == nil {
+		return nil
+	}
+	outer := overlays.PlayerOverlayRenderer.DecoratedPlayerBarRenderer
+	if outer == nil || outer.DecoratedPlayerBarRenderer == nil || outer.DecoratedPlayerBarRenderer.PlayerBar == nil {
+		return nil
+	}
+	bar := outer.DecoratedPlayerBarRenderer.PlayerBar.MultiMarkersPlayerBarRenderer
+	if bar == nil {
+		return nil
+	}
+
+	var chapters []youtube.Chapter
+	for _, entry := range bar.MarkersMap {
+		if entry.Key != chaptersMarkerKey || entry.Value == nil {
+			continue
+		}
+		for _, c := range entry.Value.Chapters {
+			if c.ChapterRenderer == nil || c.ChapterRenderer.Title == nil {
+				continue
+			}
+			chapters = append(chapters, youtube.Chapter{
+				Title:     c.ChapterRenderer.Title.SimpleText,
+				StartTime: float64(c.ChapterRenderer.TimeRangeStartMillis) / 1000,
+			})
+		}
+	}
+	return chapters
+}
+
+// extractRelated returns the videos of the related column.
+func extractRelated(resp *NextResponse) []VideoData {
+	if resp.Contents == nil || resp.Contents.TwoColumnWatchNextResults == nil {
+		return nil
+	}
+	secondary := resp.Contents.TwoColumnWatchNextResults.SecondaryResults
+	if secondary == nil || secondary.SecondaryResults == nil {
+		return nil
+	}
+
+	var videos []VideoData
+	for _, r := range secondary.SecondaryResults.Results {
+		switch {
+		case r.CompactVideoRenderer != nil && r.CompactVideoRenderer.VideoID != "":
+			videos = append(videos, compactVideoToData(r.CompactVideoRenderer))
+		case r.LockupViewModel != nil && r.LockupViewModel.ContentID != "" && r.LockupViewModel.ContentType == "LOCKUP_CONTENT_TYPE_VIDEO":
+			data := VideoData{VideoID: r.LockupViewModel.ContentID}
+			if m := r.LockupViewModel.Metadata; m != nil && m.LockupMetadataViewModel != nil && m.LockupMetadataViewModel.Title != nil {
+				data.Title = m.LockupMetadataViewModel.Title.Content
+			}
+			videos = append(videos, data)
+		}
+	}
+	return videos
+}
+
+// compactVideoToData converts a related video to VideoData.
+func compactVideoToData(v *CompactVideoRenderer) VideoData {
+	data := VideoData{
+		VideoID:     v.VideoID,
+		Title:       v.Title.GetText(),
+		ChannelName: v.LongBylineText.GetText(),
+	}
+	if v.LongBylineText != nil {
+		for _, run := range v.LongBylineText.Runs {
+			if run.NavigationEndpoint != nil && run.NavigationEndpoint.BrowseEndpoint != nil {
+				data.ChannelID = run.NavigationEndpoint.BrowseEndpoint.BrowseID
+				break
+			}
+		}
+	}
+	if v.Thumbnail != nil && len(v.Thumbnail.Thumbnails) > 0 {
+		data.Thumbnail = v.Thumbnail.Thumbnails[0].URL
+	}
+	if v.PublishedTimeText != nil {
+		data.Published = v.PublishedTimeText.SimpleText
+	}
+	if v.LengthText != nil {
+		data.Duration = v.LengthText.SimpleText
+	}
+	if v.ViewCountText != nil {
+		data.ViewCount = v.ViewCountText.SimpleText
+	}
+	return data
+}
@@ -0,0 +1,147 @@
+package innertube
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	ythttp "ytsync/http"
+	"ytsync/youtube"
+)
+
+const testNextResponse = `{
+  "contents": {"twoColumnWatchNextResults": {
+    "results": {"results": {"contents": [
+      {"videoPrimaryInfoRenderer": {
+        "title": {"runs": [{"text": "Test Video"}]},
+        "viewCount": {"videoViewCountRenderer": {"viewCount": {"simpleText": "1,234,567 views"}}},
+        "dateText": {"simpleText": "Jan 2, 2024"},
+        "videoActions": {"menuRenderer": {"topLevelButtons": [
+          {"segmentedLikeDislikeButtonViewModel": {"likeButtonViewModel": {"likeButtonViewModel": {"toggleButtonViewModel": {"toggleButtonViewModel": {"defaultButtonViewModel": {"buttonViewModel": {"title": "12K", "accessibilityText": "like this video along with 12,345 other people"}}}}}}}}
+        ]}}
+      }},
+      {"videoSecondaryInfoRenderer": {
+        "owner": {"videoOwnerRenderer": {"title": {"runs": [{"text": "Test Channel"}]}, "navigationEndpoint": {"browseEndpoint": {"browseId": "UCtest"}}}},
+        "attributedDescription": {"content": "Full description\n0:00 Intro\n1:05 Main"}
+      }},
+      {"itemSectionRenderer": {"sectionIdentifier": "comment-item-section", "contents": [
+        {"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "comments-token"}}}}
+      ]}}
+    ]}},
+    "secondaryResults": {"secondaryResults": {"results": [
+      {"compactVideoRenderer": {"videoId": "rel1", "title": {"simpleText": "Related One"}, "longBylineText": {"runs": [{"text": "Other", "navigationEndpoint": {"browseEndpoint": {"browseId": "UCother"}}}]}, "lengthText": {"simpleText": "4:20"}}},
+      {"lockupViewModel": {"contentId": "rel2", "contentType": "LOCKUP_CONTENT_TYPE_VIDEO", "metadata": {"lockupMetadataViewModel": {"title": {"content": "Related Two"}}}}},
+      {"lockupViewModel": {"contentId": "PLlist", "contentType": "LOCKUP_CONTENT_TYPE_PLAYLIST"}}
+    ]}}
+  }},
+  "engagementPanels": [
+    {"engagementPanelSectionListRenderer": {"panelIdentifier": "engagement-panel-structured-description"}},
+    {"engagementPanelSectionListRenderer": {"panelIdentifier": "engagement-panel-macro-markers-description-chapters", "content": {"macroMarkersListRenderer": {"contents": [
+      {"macroMarkersListItemRenderer": {"title": {"simpleText": "Intro"}, "timeDescription": {"simpleText": "0:00"}, "onTap": {"watchEndpoint": {"startTimeSeconds": 0}}}},
+      {"macroMarkersListItemRenderer": {"title": {"simpleText": "Main"}, "timeDescription": {"simpleText": "1:05"}}}
+    ]}}}}
+  ]
+}`
+
+func TestExtractWatchDetails(t *testing.T) {
+	var resp NextResponse
+	if err := json.Unmarshal([]byte(testNextResponse), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	d := ExtractWatchDetails(&resp)
+	if d.Title != "Test Video" || d.ChannelName != "Test Channel" || d.ChannelID != "UCtest" || d.DateText != "Jan 2, 2024" {
+		t.Errorf("details = %+v", d)
+	}
+	if !strings.HasPrefix(d.Description, "Full description\n") {
+		t.Errorf("Description = %q", d.Description)
+	}
+	if d.ViewCount != 1234567 {
+		t.Errorf("ViewCount = %d, want 1234567", d.ViewCount)
+	}
+	if d.LikeCount != 12345 {
+		t.Errorf("LikeCount = %d, want 12345", d.LikeCount)
+	}
+	if d.CommentsContinuation != "comments-token" {
+		t.Errorf("CommentsContinuation = %q", d.CommentsContinuation)
+	}
+
+	wantChapters := []youtube.Chapter{{Title: "Intro", StartTime: 0, EndTime: 65}, {Title: "Main", StartTime: 65}}
+	if len(d.Chapters) != len(wantChapters) {
+		t.Fatalf("Chapters = %+v, want %+v", d.Chapters, wantChapters)
+	}
+	for i := range wantChapters {
+		if d.Chapters[i] != wantChapters[i] {
+			t.Errorf("Chapters[%d] = %+v, want %+v", i, d.Chapters[i], wantChapters[i])
+		}
+	}
+
+	if len(d.Related) != 2 {
+		t.Fatalf("Related = %+v, want 2 videos", d.Related)
+	}
+	if r := d.Related[0]; r.VideoID != "rel1" || r.Title != "Related One" || r.ChannelID != "UCother" || r.Duration != "4:20" {
+		t.Errorf("Related[0] = %+v", r)
+	}
+	if r := d.Related[1]; r.VideoID != "rel2" || r.Title != "Related Two" {
+		t.Errorf("Related[1] = %+v", r)
+	}
+}
+
+func TestExtractWatchDetails_PlayerBarChapters(t *testing.T) {
+	var resp NextResponse
+	body := `{"playerOverlays": {"playerOverlayRenderer": {"decoratedPlayerBarRenderer": {"decoratedPlayerBarRenderer": {"playerBar": {"multiMarkersPlayerBarRenderer": {"markersMap": [
+	  {"key": "DESCRIPTION_CHAPTERS", "value": {"chapters": [
+	    {"chapterRenderer": {"title": {"simpleText": "Start"}, "timeRangeStartMillis": 0}},
+	    {"chapterRenderer": {"title": {"simpleText": "End"}, "timeRangeStartMillis": 90500}}
+	  ]}}
+	]}}}}}}}`
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	d := ExtractWatchDetails(&resp)
+	if len(d.Chapters) != 2 || d.Chapters[0].EndTime != 90.5 || d.Chapters[1].StartTime != 90.5 {
+		t.Errorf("Chapters = %+v", d.Chapters)
+	}
+	if d.Title != "" || d.LikeCount != 0 || d.Related != nil {
+		t.Errorf("details of an empty page = %+v", d)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestClientNext(t *testing.T) {
+	var got NextRequest
+	cfg := ythttp.DefaultConfig()
+	cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != nextEndpoint {
+			t.Errorf("request URL = %s, want %s", req.URL, nextEndpoint)
+		}
+		body, _ := io.ReadAll(req.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("request body %s: %v", body, err)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(testNextResponse)),
+			Request:    req,
+		}, nil
+	})
+	client := NewClient(ythttp.New(cfg))
+
+	resp, err := client.Next(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if got.VideoID != "abc123" || got.Context.Client.ClientName != defaultClientName {
+		t.Errorf("request = %+v", got)
+	}
+	if title := ExtractWatchDetails(resp).Title; title != "Test Video" {
+		t.Errorf("Title = %q, want Test Video", title)
+	}
+}
@@ -0,0 +1,385 @@
+package innertube
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+	"ytsync/youtube"
+)
+
+// playerEndpoint is the Innertube API endpoint that returns a video's
+// details, caption tracks, and streams.
+const playerEndpoint = "https://www.youtube.com/youtubei/v1/player"
+
+// DefaultProbeConcurrency is the number of player requests ProbeCaptions
+// sends at a time when concurrency is zero.
+const DefaultProbeConcurrency = 4
+
+// PlayerRequest represents a request to the player endpoint.
+type PlayerRequest struct {
+	Context ClientContext `json:"context"`
+	VideoID string        `json:"videoId"`
+	// ContentCheckOK and RacyCheckOK skip the interstitials of videos
+	// flagged as sensitive, as the web player does once the viewer
+	// confirms.
+	ContentCheckOK bool `json:"contentCheckOk,omitempty"`
+	RacyCheckOK    bool `json:"racyCheckOk,omitempty"`
+	// PlaybackContext carries the signature timestamp of the player script
+	// that will decipher the response's stream URLs.
+	PlaybackContext *PlaybackContext `json:"playbackContext,omitempty"`
+}
+
+// PlaybackContext describes the player that will play the response.
+type PlaybackContext struct {
+	ContentPlaybackContext struct {
+		SignatureTimestamp int `json:"signatureTimestamp,omitempty"`
+	} `json:"contentPlaybackContext"`
+}
+
+// PlayerResponse represents the response from the player endpoint.
+type PlayerResponse struct {
+	PlayabilityStatus *PlayabilityStatus `json:"playabilityStatus,omitempty"`
+	VideoDetails      *VideoDetails      `json:"videoDetails,omitempty"`
+	Captions          *Captions          `json:"captions,omitempty"`
+	StreamingData     *StreamingData     `json:"streamingData,omitempty"`
+	Microformat       *Microformat       `json:"microformat,omitempty"`
+}
+
+// Microformat wraps the structured data of the watch page.
+type Microformat struct {
+	PlayerMicroformatRenderer *PlayerMicroformat `json:"playerMicroformatRenderer,omitempty"`
+}
+
+// PlayerMicroformat holds the watch page fields the player response
+// carries outside VideoDetails.
+type PlayerMicroformat struct {
+	// PublishDate and UploadDate are a date ("2024-01-15") or, in newer
+	// responses, an RFC 3339 time.
+	PublishDate          string                `json:"publishDate,omitempty"`
+	UploadDate           string                `json:"uploadDate,omitempty"`
+	IsUnlisted           bool                  `json:"isUnlisted,omitempty"`
+	AvailableCountries   []string              `json:"availableCountries,omitempty"`
+	LiveBroadcastDetails *LiveBroadcastDetails `json:"liveBroadcastDetails,omitempty"`
+}
+
+// LiveBroadcastDetails holds the schedule of a stream or premiere.
+// Timestamps are RFC 3339; EndTimestamp is empty until the stream ends.
+type LiveBroadcastDetails struct {
+	IsLiveNow      bool   `json:"isLiveNow,omitempty"`
+	StartTimestamp string `json:"startTimestamp,omitempty"`
+	EndTimestamp   string `json:"endTimestamp,omitempty"`
+}
+
+// PlayabilityStatus reports whether YouTube will play the video.
+type PlayabilityStatus struct {
+	Status string `json:"status,omitempty"` // OK, LOGIN_REQUIRED, UNPLAYABLE, ERROR, LIVE_STREAM_OFFLINE
+	Reason string `json:"reason,omitempty"`
+	// LiveStreamability is set for streams and premieres that have not
+	// started.
+	LiveStreamability *LiveStreamability `json:"liveStreamability,omitempty"`
+	// ErrorScreen is the screen the player shows instead of the video.
+	ErrorScreen *ErrorScreen `json:"errorScreen,omitempty"`
+}
+
+// LiveStreamability wraps the offline slate of an upcoming stream.
+type LiveStreamability struct {
+	LiveStreamabilityRenderer struct {
+		OfflineSlate *struct {
+			LiveStreamOfflineSlateRenderer struct {
+				// ScheduledStartTime is a Unix time in seconds.
+				ScheduledStartTime string `json:"scheduledStartTime,omitempty"`
+			} `json:"liveStreamOfflineSlateRenderer"`
+		} `json:"offlineSlate,omitempty"`
+	} `json:"liveStreamabilityRenderer"`
+}
+
+// ErrorScreen is the screen shown for an unplayable video. Membership
+// offers are only recorded as present.
+type ErrorScreen struct {
+	PlayerLegacyDesktopYpcOfferRenderer *json.RawMessage `json:"playerLegacyDesktopYpcOfferRenderer,omitempty"`
+	YpcTrailerRenderer                  *json.RawMessage `json:"ypcTrailerRenderer,omitempty"`
+}
+
+// VideoDetails holds a video's basic metadata.
+type VideoDetails struct {
+	VideoID          string         `json:"videoId,omitempty"`
+	Title            string         `json:"title,omitempty"`
+	LengthSeconds    string         `json:"lengthSeconds,omitempty"`
+	Keywords         []string       `json:"keywords,omitempty"`
+	ChannelID        string         `json:"channelId,omitempty"`
+	ShortDescription string         `json:"shortDescription,omitempty"`
+	Thumbnail        *ThumbnailList `json:"thumbnail,omitempty"`
+	ViewCount        string         `json:"viewCount,omitempty"`
+	Author           string         `json:"author,omitempty"`
+	IsLiveContent    bool           `json:"isLiveContent,omitempty"`
+	IsLive           bool           `json:"isLive,omitempty"`
+	IsUpcoming       bool           `json:"isUpcoming,omitempty"`
+	IsPrivate        bool           `json:"isPrivate,omitempty"`
+}
+
+// Captions wraps the caption track list.
+type Captions struct {
+	PlayerCaptionsTracklistRenderer *CaptionTracklist `json:"playerCaptionsTracklistRenderer,omitempty"`
+}
+
+// CaptionTracklist lists a video's caption tracks and the languages they
+// can be machine translated to.
+type CaptionTracklist struct {
+	CaptionTracks        []CaptionTrack        `json:"captionTracks,omitempty"`
+	TranslationLanguages []TranslationLanguage `json:"translationLanguages,omitempty"`
+}
+
+// CaptionTrack is a caption track of a video.
+type CaptionTrack struct {
+	// BaseURL is the timedtext URL of the track. It returns the srv3 XML
+	// format unless an fmt parameter is added; see URL.
+	BaseURL      string    `json:"baseUrl,omitempty"`
+	Name         *TextRuns `json:"name,omitempty"`
+	VssID        string    `json:"vssId,omitempty"` // e.g. ".en" or "a.en"
+	LanguageCode string    `json:"languageCode,omitempty"`
+	// Kind is "asr" for automatic captions and empty for uploaded ones.
+	Kind           string `json:"kind,omitempty"`
+	IsTranslatable bool   `json:"isTranslatable,omitempty"`
+}
+
+// TranslationLanguage is a language caption tracks can be translated to.
+type TranslationLanguage struct {
+	LanguageCode string    `json:"languageCode,omitempty"`
+	LanguageName *TextRuns `json:"languageName,omitempty"`
+}
+
+// StreamingData lists the streams of a playable video.
+type StreamingData struct {
+	ExpiresInSeconds string   `json:"expiresInSeconds,omitempty"`
+	Formats          []Format `json:"formats,omitempty"`
+	AdaptiveFormats  []Format `json:"adaptiveFormats,omitempty"`
+	HLSManifestURL   string   `json:"hlsManifestUrl,omitempty"`
+	DASHManifestURL  string   `json:"dashManifestUrl,omitempty"`
+}
+
+// Format is a stream of a video. Formats has muxed audio and video;
+// AdaptiveFormats has audio-only and video-only streams. Protected streams
+// have SignatureCipher instead of URL.
+type Format struct {
+	Itag             int    `json:"itag"`
+	URL              string `json:"url,omitempty"`
+	SignatureCipher  string `json:"signatureCipher,omitempty"`
+	MimeType         string `json:"mimeType,omitempty"`
+	Bitrate          int    `json:"bitrate,omitempty"`
+	Width            int    `json:"width,omitempty"`
+	Height           int    `json:"height,omitempty"`
+	FPS              int    `json:"fps,omitempty"`
+	Quality          string `json:"quality,omitempty"`
+	QualityLabel     string `json:"qualityLabel,omitempty"`
+	ContentLength    string `json:"contentLength,omitempty"`
+	ApproxDurationMs string `json:"approxDurationMs,omitempty"`
+	AudioQuality     string `json:"audioQuality,omitempty"`
+	AudioSampleRate  string `json:"audioSampleRate,omitempty"`
+	AudioChannels    int    `json:"audioChannels,omitempty"`
+	// AudioTrack is set in videos with several audio tracks.
+	AudioTrack *AudioTrack `json:"audioTrack,omitempty"`
+}
+
+// AudioTrack identifies the audio track of a format.
+type AudioTrack struct {
+	// ID is the language and a variant, e.g. "en-US.4".
+	ID             string `json:"id,omitempty"`
+	DisplayName    string `json:"displayName,omitempty"`
+	AudioIsDefault bool   `json:"audioIsDefault,omitempty"`
+}
+
+// Player fetches the details, caption tracks, and streams of a video. A
+// video YouTube will not play is not an error; check
+// PlayerResponse.Playable, or PlayerResponse.Err for the reason.
+func (c *Client) Player(ctx context.Context, videoID string) (*PlayerResponse, error) {
+	return c.player(ctx, videoID, 0)
+}
+
+// player is Player with the signature timestamp sts of the player script
+// that will decipher the stream URLs, if nonzero.
+func (c *Client) player(ctx context.Context, videoID string, sts int) (*PlayerResponse, error) {
+	req := &PlayerRequest{
+		Context:        webContext(),
+		VideoID:        videoID,
+		ContentCheckOK: true,
+		RacyCheckOK:    true,
+	}
+	if sts != 0 {
+		req.PlaybackContext = &PlaybackContext{}
+		req.PlaybackContext.ContentPlaybackContext.SignatureTimestamp = sts
+	}
+
+	var resp *PlayerResponse
+	if err := c.post(ctx, playerEndpoint, "player", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Playable reports whether YouTube will play the video.
+func (r *PlayerResponse) Playable() bool {
+	return r.PlayabilityStatus != nil && r.PlayabilityStatus.Status == "OK"
+}
+
+// CaptionTracks returns the video's caption tracks, or nil if it has none.
+func (r *PlayerResponse) CaptionTracks() []CaptionTrack {
+	if r.Captions == nil || r.Captions.PlayerCaptionsTracklistRenderer == nil {
+		return nil
+	}
+	return r.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks
+}
+
+// FindCaptionTrack returns the track for languageCode, preferring an
+// uploaded track over automatic captions, or nil if there is none.
+func (r *PlayerResponse) FindCaptionTrack(languageCode string) *CaptionTrack {
+	var auto *CaptionTrack
+	tracks := r.CaptionTracks()
+	for i := range tracks {
+		if tracks[i].LanguageCode != languageCode {
+			continue
+		}
+		if !tracks[i].IsAutoGenerated() {
+			return &tracks[i]
+		}
+		if auto == nil {
+			auto = &tracks[i]
+		}
+	}
+	return auto
+}
+
+// PublishDate returns when the video was published, or the zero time if
+// the response does not say.
+func (r *PlayerResponse) PublishDate() time.Time {
+	if r.Microformat == nil || r.Microformat.PlayerMicroformatRenderer == nil {
+		return time.Time{}
+	}
+	mf := r.Microformat.PlayerMicroformatRenderer
+	for _, s := range []string{mf.PublishDate, mf.UploadDate} {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t.UTC()
+		}
+		if t, err := time.Parse(time.DateOnly, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Duration returns the video length in seconds, or zero if unknown.
+func (d *VideoDetails) Duration() int {
+	n, _ := strconv.Atoi(d.LengthSeconds)
+	return n
+}
+
+// IsAutoGenerated reports whether the track is automatic speech
+// recognition captions.
+func (t *CaptionTrack) IsAutoGenerated() bool {
+	return t.Kind == "asr"
+}
+
+// URL returns the track's URL in the given timedtext format, such as
+// "json3", "srv3", or "vtt". An empty format returns BaseURL.
+func (t *CaptionTrack) URL(format string) string {
+	if format == "" {
+		return t.BaseURL
+	}
+	u, err := url.Parse(t.BaseURL)
+	if err != nil {
+		return t.BaseURL
+	}
+	q := u.Query()
+	q.Set("fmt", format)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// CaptionLanguages returns the language codes of the video's caption
+// tracks in track order, without duplicates.
+func (r *PlayerResponse) CaptionLanguages() []string {
+	var langs []string
+	seen := make(map[string]bool)
+	for _, t := range r.CaptionTracks() {
+		if !seen[t.LanguageCode] {
+			seen[t.LanguageCode] = true
+			langs = append(langs, t.LanguageCode)
+		}
+	}
+	return langs
+}
+
+// ProbeCaptions looks up the caption tracks of videos with player
+// requests, up to concurrency at a time (default DefaultProbeConcurrency),
+// and sets their CaptionsProbed, HasCaptions, and CaptionLanguages fields.
+// Videos whose request fails or that YouTube will not play are left
+// unprobed, since their captions are unknown. The error is non-nil only if
+// ctx is done.
+func (c *Client) ProbeCaptions(ctx context.Context, videos []youtube.VideoInfo, concurrency int) error {
+	if concurrency <= 0 {
+		concurrency = DefaultProbeConcurrency
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range videos {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func(v *youtube.VideoInfo) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := c.Player(ctx, v.ID)
+			if err != nil || !resp.Playable() {
+				return
+			}
+			v.CaptionLanguages = resp.CaptionLanguages()
+			v.HasCaptions = len(v.CaptionLanguages) > 0
+			v.CaptionsProbed = true
+		}(&videos[i])
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// PublishDates returns the publish times of videoIDs from player requests,
+// up to DefaultProbeConcurrency at a time. It fits
+// youtube.PublishDateFetcher and costs no Data API quota. Videos whose
+// request fails or whose response has no date are left out of the map.
+// The error is non-nil only if ctx is done.
+func (c *Client) PublishDates(ctx context.Context, videoIDs []string) (map[string]time.Time, error) {
+	dates := make(map[string]time.Time, len(videoIDs))
+	var mu sync.Mutex
+	sem := make(chan struct{}, DefaultProbeConcurrency)
+	var wg sync.WaitGroup
+	for _, id := range videoIDs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return dates, ctx.Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := c.Player(ctx, id)
+			if err != nil {
+				return
+			}
+			if t := resp.PublishDate(); !t.IsZero() {
+				mu.Lock()
+				dates[id] = t
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return dates, ctx.Err()
+}
@@ -0,0 +1,185 @@
+package innertube
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	ythttp "ytsync/http"
+	"ytsync/youtube"
+)
+
+const testPlayerResponse = `{
+  "playabilityStatus": {"status": "OK"},
+  "videoDetails": {"videoId": "abc123", "title": "Test Video", "lengthSeconds": "212", "channelId": "UCtest", "author": "Test Channel", "viewCount": "1000", "shortDescription": "desc"},
+  "captions": {"playerCaptionsTracklistRenderer": {
+    "captionTracks": [
+      {"baseUrl": "https://www.youtube.com/api/timedtext?v=abc123&lang=en&kind=asr", "name": {"simpleText": "English (auto-generated)"}, "vssId": "a.en", "languageCode": "en", "kind": "asr", "isTranslatable": true},
+      {"baseUrl": "https://www.youtube.com/api/timedtext?v=abc123&lang=en", "name": {"simpleText": "English"}, "vssId": ".en", "languageCode": "en", "isTranslatable": true},
+      {"baseUrl": "https://www.youtube.com/api/timedtext?v=abc123&lang=de&kind=asr", "vssId": "a.de", "languageCode": "de", "kind": "asr"}
+    ],
+    "translationLanguages": [{"languageCode": "fr", "languageName": {"simpleText": "French"}}]
+  }},
+  "streamingData": {"expiresInSeconds": "21540", "formats": [{"itag": 18, "url": "https://rr1.googlevideo.com/videoplayback?itag=18", "mimeType": "video/mp4; codecs=\"avc1.42001E, mp4a.40.2\"", "qualityLabel": "360p"}],
+    "adaptiveFormats": [{"itag": 140, "signatureCipher": "s=abc&url=https%3A%2F%2Frr1.googlevideo.com", "mimeType": "audio/mp4; codecs=\"mp4a.40.2\"", "audioQuality": "AUDIO_QUALITY_MEDIUM"}]}
+}`
+
+func TestPlayerResponse(t *testing.T) {
+	var resp PlayerResponse
+	if err := json.Unmarshal([]byte(testPlayerResponse), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if !resp.Playable() {
+		t.Error("Playable() = false, want true")
+	}
+	if resp.VideoDetails.Duration() != 212 || resp.VideoDetails.ChannelID != "UCtest" {
+		t.Errorf("VideoDetails = %+v", resp.VideoDetails)
+	}
+	if got := len(resp.CaptionTracks()); got != 3 {
+		t.Errorf("len(CaptionTracks()) = %d, want 3", got)
+	}
+
+	en := resp.FindCaptionTrack("en")
+	if en == nil || en.IsAutoGenerated() || en.VssID != ".en" {
+		t.Errorf("FindCaptionTrack(en) = %+v, want the uploaded track", en)
+	}
+	de := resp.FindCaptionTrack("de")
+	if de == nil || !de.IsAutoGenerated() {
+		t.Errorf("FindCaptionTrack(de) = %+v, want the automatic track", de)
+	}
+	if fr := resp.FindCaptionTrack("fr"); fr != nil {
+		t.Errorf("FindCaptionTrack(fr) = %+v, want nil", fr)
+	}
+
+	if got, want := en.URL("json3"), "https://www.youtube.com/api/timedtext?fmt=json3&lang=en&v=abc123"; got != want {
+		t.Errorf("URL(json3) = %s, want %s", got, want)
+	}
+	if en.URL("") != en.BaseURL {
+		t.Errorf("URL(\"\") = %s, want BaseURL", en.URL(""))
+	}
+
+	if sd := resp.StreamingData; sd == nil || len(sd.Formats) != 1 || sd.AdaptiveFormats[0].SignatureCipher == "" {
+		t.Errorf("StreamingData = %+v", sd)
+	}
+}
+
+func TestPlayerResponse_Unplayable(t *testing.T) {
+	var resp PlayerResponse
+	body := `{"playabilityStatus": {"status": "LOGIN_REQUIRED", "reason": "Sign in to confirm your age"}}`
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Playable() || resp.CaptionTracks() != nil || resp.FindCaptionTrack("en") != nil {
+		t.Errorf("unplayable response = %+v", resp)
+	}
+}
+
+func TestClientPlayer(t *testing.T) {
+	var got PlayerRequest
+	cfg := ythttp.DefaultConfig()
+	cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != playerEndpoint {
+			t.Errorf("request URL = %s, want %s", req.URL, playerEndpoint)
+		}
+		body, _ := io.ReadAll(req.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("request body %s: %v", body, err)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(testPlayerResponse)),
+			Request:    req,
+		}, nil
+	})
+	client := NewClient(ythttp.New(cfg))
+
+	resp, err := client.Player(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("Player() error = %v", err)
+	}
+	if got.VideoID != "abc123" || !got.ContentCheckOK || !got.RacyCheckOK {
+		t.Errorf("request = %+v", got)
+	}
+	if resp.VideoDetails == nil || resp.VideoDetails.Title != "Test Video" {
+		t.Errorf("VideoDetails = %+v", resp.VideoDetails)
+	}
+}
+
+func TestClientProbeCaptions(t *testing.T) {
+	responses := map[string]string{
+		"abc123":    testPlayerResponse,
+		"nocaption": `{"playabilityStatus": {"status": "OK"}, "videoDetails": {"videoId": "nocaption"}}`,
+		"private":   `{"playabilityStatus": {"status": "LOGIN_REQUIRED"}}`,
+	}
+	cfg := ythttp.DefaultConfig()
+	cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body PlayerRequest
+		data, _ := io.ReadAll(req.Body)
+		json.Unmarshal(data, &body)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(responses[body.VideoID])),
+			Request:    req,
+		}, nil
+	})
+	client := NewClient(ythttp.New(cfg))
+
+	videos := []youtube.VideoInfo{{ID: "abc123"}, {ID: "nocaption"}, {ID: "private"}}
+	if err := client.ProbeCaptions(context.Background(), videos, 2); err != nil {
+		t.Fatalf("ProbeCaptions() error = %v", err)
+	}
+
+	if v := videos[0]; !v.CaptionsProbed || !v.HasCaptions || strings.Join(v.CaptionLanguages, ",") != "en,de" {
+		t.Errorf("captioned video = %+v, want en and de captions", v)
+	}
+	if v := videos[1]; !v.LacksCaptions() {
+		t.Errorf("uncaptioned video = %+v, want LacksCaptions", v)
+	}
+	if v := videos[2]; v.CaptionsProbed || v.LacksCaptions() {
+		t.Errorf("unplayable video = %+v, want it left unprobed", v)
+	}
+}
+
+func TestClientPublishDates(t *testing.T) {
+	responses := map[string]string{
+		"dated":  `{"microformat": {"playerMicroformatRenderer": {"publishDate": "2021-06-01T08:30:00-07:00", "uploadDate": "2021-05-30T10:00:00-07:00"}}}`,
+		"legacy": `{"microformat": {"playerMicroformatRenderer": {"publishDate": "2015-02-03"}}}`,
+		"gone":   `{"playabilityStatus": {"status": "ERROR", "reason": "Video unavailable"}}`,
+	}
+	cfg := ythttp.DefaultConfig()
+	cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body PlayerRequest
+		data, _ := io.ReadAll(req.Body)
+		json.Unmarshal(data, &body)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(responses[body.VideoID])),
+			Request:    req,
+		}, nil
+	})
+	client := NewClient(ythttp.New(cfg))
+
+	dates, err := client.PublishDates(context.Background(), []string{"dated", "legacy", "gone"})
+	if err != nil {
+		t.Fatalf("PublishDates() error = %v", err)
+	}
+	want := map[string]time.Time{
+		"dated":  time.Date(2021, 6, 1, 15, 30, 0, 0, time.UTC),
+		"legacy": time.Date(2015, 2, 3, 0, 0, 0, 0, time.UTC),
+	}
+	if len(dates) != len(want) {
+		t.Fatalf("PublishDates() = %v, want %v", dates, want)
+	}
+	for id, w := range want {
+		if !dates[id].Equal(w) {
+			t.Errorf("PublishDates()[%s] = %v, want %v", id, dates[id], w)
+		}
+	}
+}
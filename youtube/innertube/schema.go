@@ -0,0 +1,329 @@
+package innertube
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExtractionMode selects how the Lister reads videos and continuation
+// tokens from browse responses.
+type ExtractionMode string
+
+const (
+	// ExtractAuto reads responses with the typed structs and falls back to
+	// the Schema's JSON paths for a page where they find no videos or no
+	// continuation token. It is the default.
+	ExtractAuto ExtractionMode = ""
+	// ExtractTyped reads responses with the typed structs only.
+	ExtractTyped ExtractionMode = "typed"
+	// ExtractPaths reads responses with the Schema's JSON paths only.
+	ExtractPaths ExtractionMode = "paths"
+)
+
+// Schema lists the JSON paths that resilient extraction reads browse
+// responses with. Items are looked for under their renderer key at any
+// depth, so a renamed wrapper around them does not hide them from it.
+//
+// A path is object keys separated by dots. A number indexes an array, and
+// -1 is its last element. A path that ends at a text object (simpleText,
+// runs, or content) yields its text.
+type Schema struct {
+	// Items describes each kind of video item, in order of preference when
+	// one object holds several.
+	Items []ItemPaths
+	// Continuations are paths to the next page's continuation token. The
+	// first key of each is looked for at any depth and the rest followed
+	// from there; the first path that yields a token wins.
+	Continuations []string
+}
+
+// ItemPaths lists where the fields of one kind of video item are found.
+// Each field lists paths relative to the item, tried in order; the first
+// that holds a non-empty value wins.
+type ItemPaths struct {
+	// Renderer is the key items of this kind are stored under, such as
+	// "videoRenderer".
+	Renderer string
+	// VideoID is required; items without one are skipped.
+	VideoID      []string
+	Title        []string
+	Description  []string
+	Thumbnail    []string
+	Published    []string
+	Duration     []string
+	ViewCount    []string
+	OverlayStyle []string
+	StartTime    []string
+	// Short marks items of this kind as Shorts.
+	Short bool
+}
+
+// DefaultSchema returns the paths of the browse response layouts seen
+// since the grid layout of 2019: grid and rich grid videos, reel items, and
+// the view-model Shorts, with tokens from continuation items and from the
+// older nextContinuationData.
+func DefaultSchema() *Schema {
+	overlay := []string{"thumbnailOverlays.0.thumbnailOverlayTimeStatusRenderer.style"}
+	start := []string{"upcomingEventData.startTime"}
+	return &Schema{
+		Items: []ItemPaths{
+			{
+				Renderer:     "videoRenderer",
+				VideoID:      []string{"videoId"},
+				Title:        []string{"title"},
+				Description:  []string{"descriptionSnippet"},
+				Thumbnail:    []string{"thumbnail.thumbnails.0.url"},
+				Published:    []string{"publishedTimeText"},
+				Duration:     []string{"lengthText"},
+				ViewCount:    []string{"viewCountText", "shortViewCountText"},
+				OverlayStyle: overlay,
+				StartTime:    start,
+			},
+			{
+				Renderer:     "gridVideoRenderer",
+				VideoID:      []string{"videoId"},
+				Title:        []string{"title"},
+				Thumbnail:    []string{"thumbnail.thumbnails.0.url"},
+				Published:    []string{"publishedTimeText"},
+				Duration:     []string{"thumbnailOverlays.0.thumbnailOverlayTimeStatusRenderer.text"},
+				ViewCount:    []string{"viewCountText", "shortViewCountText"},
+				OverlayStyle: overlay,
+				StartTime:    start,
+			},
+			{
+				Renderer:  "reelItemRenderer",
+				VideoID:   []string{"videoId"},
+				Title:     []string{"headline"},
+				Thumbnail: []string{"thumbnail.thumbnails.0.url"},
+				ViewCount: []string{"viewCountText"},
+				Short:     true,
+			},
+			{
+				Renderer:  "shortsLockupViewModel",
+				VideoID:   []string{"onTap.innertubeCommand.reelWatchEndpoint.videoId", "entityId"},
+				Title:     []string{"overlayMetadata.primaryText", "accessibilityText"},
+				Thumbnail: []string{"thumbnail.sources.0.url"},
+				ViewCount: []string{"overlayMetadata.secondaryText"},
+				Short:     true,
+			},
+		},
+		// Sort chips in the grid header also carry continuation commands,
+		// so only those of continuation items are taken
+		Continuations: []string{
+			"continuationItemRenderer.continuationEndpoint.continuationCommand.token",
+			"nextContinuationData.continuation",
+			"reloadContinuationData.continuation",
+		},
+	}
+}
+
+// ExtractVideosWithSchema reads videos from resp using the paths of schema,
+// or of DefaultSchema if schema is nil. Videos are returned in document
+// order, each once. The channel ID and name are filled in as by
+// ExtractVideos.
+func ExtractVideosWithSchema(resp *BrowseResponse, schema *Schema, channelID, channelName string) []VideoData {
+	if resp == nil {
+		return nil
+	}
+	if schema == nil {
+		schema = DefaultSchema()
+	}
+	doc, ok := decodeDocument(resp.Raw())
+	if !ok {
+		return nil
+	}
+	if channelName == "" {
+		channelName = extractChannelName(resp)
+	}
+	if channelID == "" {
+		channelID = extractChannelID(resp)
+	}
+
+	renderers := make(map[string]*ItemPaths, len(schema.Items))
+	for i := range schema.Items {
+		if _, dup := renderers[schema.Items[i].Renderer]; !dup {
+			renderers[schema.Items[i].Renderer] = &schema.Items[i]
+		}
+	}
+
+	var videos []VideoData
+	seen := make(map[string]bool)
+	walkDocument(doc, func(key string, value any) bool {
+		paths, ok := renderers[key]
+		if !ok {
+			return true
+		}
+		data := paths.extract(value)
+		if data == nil || seen[data.VideoID] {
+			return false
+		}
+		seen[data.VideoID] = true
+		data.ChannelID = channelID
+		data.ChannelName = channelName
+		videos = append(videos, *data)
+		return false
+	})
+	return videos
+}
+
+// ExtractContinuationTokenWithSchema reads the next page's continuation
+// token from resp using the paths of schema, or of DefaultSchema if schema
+// is nil. It returns "" on the last page.
+func ExtractContinuationTokenWithSchema(resp *BrowseResponse, schema *Schema) string {
+	if resp == nil {
+		return ""
+	}
+	if schema == nil {
+		schema = DefaultSchema()
+	}
+	doc, ok := decodeDocument(resp.Raw())
+	if !ok {
+		return ""
+	}
+	for _, path := range schema.Continuations {
+		anchor, rest, _ := strings.Cut(path, ".")
+		var token string
+		walkDocument(doc, func(key string, value any) bool {
+			if token != "" {
+				return false
+			}
+			if key != anchor {
+				return true
+			}
+			if t := textAt(value, rest); t != "" {
+				token = t
+				return false
+			}
+			return true
+		})
+		if token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// extract returns the video item v holds, or nil if it has no video ID.
+func (p *ItemPaths) extract(v any) *VideoData {
+	id := firstText(v, p.VideoID)
+	if id == "" {
+		return nil
+	}
+	return &VideoData{
+		VideoID:      id,
+		Title:        firstText(v, p.Title),
+		Description:  firstText(v, p.Description),
+		Thumbnail:    firstText(v, p.Thumbnail),
+		Published:    firstText(v, p.Published),
+		Duration:     firstText(v, p.Duration),
+		ViewCount:    firstText(v, p.ViewCount),
+		OverlayStyle: firstText(v, p.OverlayStyle),
+		StartTime:    firstText(v, p.StartTime),
+		IsShort:      p.Short,
+	}
+}
+
+// decodeDocument decodes raw JSON for path lookups, keeping numbers as
+// written.
+func decodeDocument(raw []byte) (any, bool) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+// walkDocument calls visit for every object member of v, depth first, in
+// array order and sorted key order. The member's value is descended into
+// only if visit returns true.
+func walkDocument(v any, visit func(key string, value any) bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if visit(k, v[k]) {
+				walkDocument(v[k], visit)
+			}
+		}
+	case []any:
+		for _, elem := range v {
+			walkDocument(elem, visit)
+		}
+	}
+}
+
+// firstText returns the text at the first of paths that has any.
+func firstText(v any, paths []string) string {
+	for _, path := range paths {
+		if t := textAt(v, path); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// textAt follows path from v and returns the text found there, or "".
+func textAt(v any, path string) string {
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch node := v.(type) {
+			case map[string]any:
+				v = node[key]
+			case []any:
+				i, err := strconv.Atoi(key)
+				if err != nil || len(node) == 0 {
+					return ""
+				}
+				if i < 0 {
+					i += len(node)
+				}
+				if i < 0 || i >= len(node) {
+					return ""
+				}
+				v = node[i]
+			default:
+				return ""
+			}
+		}
+	}
+	return textOf(v)
+}
+
+// textOf returns v as text: a string or number as written, or the text of
+// a simpleText, runs, or content object.
+func textOf(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case map[string]any:
+		if s, ok := v["simpleText"].(string); ok {
+			return s
+		}
+		if runs, ok := v["runs"].([]any); ok {
+			var b strings.Builder
+			for _, run := range runs {
+				if r, ok := run.(map[string]any); ok {
+					if s, ok := r["text"].(string); ok {
+						b.WriteString(s)
+					}
+				}
+			}
+			return b.String()
+		}
+		if s, ok := v["content"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
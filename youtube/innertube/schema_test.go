@@ -0,0 +1,233 @@
+package innertube
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	ythttp "ytsync/http"
+)
+
+// Browse responses in the layouts YouTube has served over the years,
+// trimmed to the parts extraction reads.
+const (
+	// gridLayout2019 is the Videos tab before the rich grid: a grid inside
+	// an item section, paged with nextContinuationData.
+	gridLayout2019 = `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"sectionListRenderer": {"contents": [
+		{"itemSectionRenderer": {"contents": [{"gridRenderer": {
+			"items": [
+				{"gridVideoRenderer": {"videoId": "grid1", "title": {"simpleText": "Grid One"}, "publishedTimeText": {"simpleText": "2 years ago"}, "viewCountText": {"simpleText": "1,024 views"},
+					"thumbnail": {"thumbnails": [{"url": "https://i.ytimg.com/vi/grid1/hqdefault.jpg"}]},
+					"thumbnailOverlays": [{"thumbnailOverlayTimeStatusRenderer": {"text": {"simpleText": "4:01"}, "style": "DEFAULT"}}]}},
+				{"gridVideoRenderer": {"videoId": "grid2", "title": {"runs": [{"text": "Grid "}, {"text": "Two"}]}}}
+			],
+			"continuations": [{"nextContinuationData": {"continuation": "grid-token"}}]
+		}}]}}
+	]}}}}]}}}`
+
+	// richGridLayout2022 is the rich grid of the Videos tab, with sort
+	// chips in its header.
+	richGridLayout2022 = `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"richGridRenderer": {
+		"contents": [
+			{"richItemRenderer": {"content": {"videoRenderer": {"videoId": "rich1", "title": {"runs": [{"text": "Rich One"}]}, "lengthText": {"simpleText": "10:30"}, "viewCountText": {"simpleText": "5,000 views"}, "publishedTimeText": {"simpleText": "3 days ago"}}}}},
+			{"richItemRenderer": {"content": {"videoRenderer": {"videoId": "rich2", "title": {"runs": [{"text": "Rich Two"}]}}}}},
+			{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "rich-token"}}}}
+		],
+		"header": {"feedFilterChipBarRenderer": {"contents": [
+			{"chipCloudChipRenderer": {"text": {"simpleText": "Popular"}, "navigationEndpoint": {"continuationCommand": {"token": "sort-popular"}}}}
+		]}}
+	}}}}]}}}`
+
+	// continuationLayout2023 is a later page of the rich grid. It has no
+	// continuation item, so it is the last.
+	continuationLayout2023 = `{"onResponseReceivedActions": [{"appendContinuationItemsAction": {"continuationItems": [
+		{"richItemRenderer": {"content": {"videoRenderer": {"videoId": "page1", "title": {"runs": [{"text": "Page One"}]}}}}}
+	]}}]}`
+
+	// shortsLayout2024 is the Shorts tab in its view-model layout.
+	shortsLayout2024 = `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"richGridRenderer": {"contents": [
+		{"richItemRenderer": {"content": {"shortsLockupViewModel": {
+			"onTap": {"innertubeCommand": {"reelWatchEndpoint": {"videoId": "short1"}}},
+			"overlayMetadata": {"primaryText": {"content": "Short One"}, "secondaryText": {"content": "1.2M views"}},
+			"thumbnail": {"sources": [{"url": "https://i.ytimg.com/vi/short1/oardefault.jpg"}]}
+		}}}}
+	]}}}}]}}}`
+
+	// renamedWrapperLayout is the rich grid with its grid and item wrappers
+	// renamed, as a layout change might ship them.
+	renamedWrapperLayout = `{"contents": {"twoColumnBrowseResultsRenderer": {"tabs": [{"tabRenderer": {"content": {"richGridRendererV2": {"contents": [
+		{"richItemRendererV2": {"content": {"videoRenderer": {"videoId": "renamed1", "title": {"runs": [{"text": "Renamed One"}]}}}}},
+		{"richItemRendererV2": {"content": {"videoRenderer": {"videoId": "renamed2", "title": {"runs": [{"text": "Renamed Two"}]}}}}},
+		{"continuationItemRenderer": {"continuationEndpoint": {"continuationCommand": {"token": "renamed-token"}}}}
+	]}}}}]}}}`
+)
+
+func decodeBrowseResponse(t *testing.T, body string) *BrowseResponse {
+	t.Helper()
+	var resp BrowseResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+	return &resp
+}
+
+func videoIDs(videos []VideoData) []string {
+	var ids []string
+	for _, v := range videos {
+		ids = append(ids, v.VideoID)
+	}
+	return ids
+}
+
+func TestExtractWithSchema_HistoricalLayouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantIDs   []string
+		wantToken string
+		// typedMisses is set for layouts the typed structs cannot read.
+		typedMisses bool
+	}{
+		{name: "grid 2019", body: gridLayout2019, wantIDs: []string{"grid1", "grid2"}, wantToken: "grid-token"},
+		{name: "rich grid 2022", body: richGridLayout2022, wantIDs: []string{"rich1", "rich2"}, wantToken: "rich-token"},
+		{name: "continuation 2023", body: continuationLayout2023, wantIDs: []string{"page1"}},
+		{name: "shorts 2024", body: shortsLayout2024, wantIDs: []string{"short1"}},
+		{name: "renamed wrappers", body: renamedWrapperLayout, wantIDs: []string{"renamed1", "renamed2"}, wantToken: "renamed-token", typedMisses: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := decodeBrowseResponse(t, tt.body)
+
+			videos := ExtractVideosWithSchema(resp, nil, "UCtest", "Test")
+			if got := videoIDs(videos); !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Errorf("ExtractVideosWithSchema() IDs = %v, want %v", got, tt.wantIDs)
+			}
+			if token := ExtractContinuationTokenWithSchema(resp, nil); token != tt.wantToken {
+				t.Errorf("ExtractContinuationTokenWithSchema() = %q, want %q", token, tt.wantToken)
+			}
+
+			typed := ExtractVideos(resp, "UCtest", "Test")
+			if tt.typedMisses {
+				if len(typed) != 0 {
+					t.Errorf("ExtractVideos() = %v, want none from a renamed layout", videoIDs(typed))
+				}
+				return
+			}
+			// Where the typed structs read the layout, paths agree with them
+			if len(typed) > 0 && !reflect.DeepEqual(videoIDs(typed), videoIDs(videos)) {
+				t.Errorf("ExtractVideos() IDs = %v, paths found %v", videoIDs(typed), videoIDs(videos))
+			}
+		})
+	}
+}
+
+func TestExtractWithSchema_Fields(t *testing.T) {
+	videos := ExtractVideosWithSchema(decodeBrowseResponse(t, gridLayout2019), nil, "UCtest", "Test")
+	want := VideoData{
+		VideoID:      "grid1",
+		Title:        "Grid One",
+		Thumbnail:    "https://i.ytimg.com/vi/grid1/hqdefault.jpg",
+		Published:    "2 years ago",
+		Duration:     "4:01",
+		ViewCount:    "1,024 views",
+		ChannelID:    "UCtest",
+		ChannelName:  "Test",
+		OverlayStyle: "DEFAULT",
+	}
+	if len(videos) == 0 || !reflect.DeepEqual(videos[0], want) {
+		t.Fatalf("ExtractVideosWithSchema() = %+v, want %+v first", videos, want)
+	}
+	if videos[1].Title != "Grid Two" {
+		t.Errorf("title from runs = %q, want Grid Two", videos[1].Title)
+	}
+
+	shorts := ExtractVideosWithSchema(decodeBrowseResponse(t, shortsLayout2024), nil, "", "")
+	if len(shorts) != 1 || !shorts[0].IsShort || shorts[0].Title != "Short One" || shorts[0].ViewCount != "1.2M views" {
+		t.Errorf("shorts = %+v", shorts)
+	}
+}
+
+func TestExtractWithSchema_CustomPaths(t *testing.T) {
+	const body = `{"contents": {"feed": [
+		{"videoCardViewModel": {"ids": {"video": "custom1"}, "headline": {"content": "Custom One"}}},
+		{"videoCardViewModel": {"headline": {"content": "No ID"}}}
+	], "next": {"pageToken": {"value": "custom-token"}}}}`
+	schema := &Schema{
+		Items: []ItemPaths{{
+			Renderer: "videoCardViewModel",
+			VideoID:  []string{"ids.video"},
+			Title:    []string{"headline"},
+		}},
+		Continuations: []string{"pageToken.value"},
+	}
+	resp := decodeBrowseResponse(t, body)
+
+	videos := ExtractVideosWithSchema(resp, schema, "", "")
+	if len(videos) != 1 || videos[0].VideoID != "custom1" || videos[0].Title != "Custom One" {
+		t.Errorf("ExtractVideosWithSchema() = %+v", videos)
+	}
+	if token := ExtractContinuationTokenWithSchema(resp, schema); token != "custom-token" {
+		t.Errorf("ExtractContinuationTokenWithSchema() = %q, want custom-token", token)
+	}
+}
+
+func TestTextAt(t *testing.T) {
+	doc, _ := decodeDocument([]byte(`{"a": {"list": [{"v": "first"}, {"v": 42}, {"v": {"runs": [{"text": "la"}, {"text": "st"}]}}]}}`))
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"a.list.0.v", "first"},
+		{"a.list.1.v", "42"},
+		{"a.list.-1.v", "last"},
+		{"a.list.3.v", ""},
+		{"a.missing", ""},
+		{"a.list.x", ""},
+	}
+	for _, tt := range tests {
+		if got := textAt(doc, tt.path); got != tt.want {
+			t.Errorf("textAt(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestListVideos_ExtractionModes(t *testing.T) {
+	tests := []struct {
+		mode ExtractionMode
+		want int
+	}{
+		{ExtractAuto, 2},
+		{ExtractPaths, 2},
+		{ExtractTyped, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			cfg := ythttp.DefaultConfig()
+			cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(req.Body)
+				page := renamedWrapperLayout
+				if strings.Contains(string(body), "renamed-token") {
+					page = `{}`
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(page)),
+					Request:    req,
+				}, nil
+			})
+			lister := NewLister(ythttp.New(cfg), WithExtraction(tt.mode, nil))
+
+			videos, err := lister.ListVideos(context.Background(), "UCsXVk37bltHxD1rDPwtNM8Q", nil)
+			if err != nil {
+				t.Fatalf("ListVideos() error = %v", err)
+			}
+			if len(videos) != tt.want {
+				t.Errorf("ListVideos() = %d videos, want %d", len(videos), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package innertube
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"ytsync/youtube"
+)
+
+// cipherOp is one step of a signature transform.
+type cipherOp struct {
+	kind string // "reverse", "splice", or "swap"
+	arg  int
+}
+
+// playerCipher holds the transforms extracted from one player script.
+type playerCipher struct {
+	// ops is the translated signature routine.
+	ops []cipherOp
+	// nFunc is the JavaScript source of the n parameter transform, or empty
+	// if it could not be found.
+	nFunc string
+	// sts is the script's signature timestamp, or 0 if it could not be
+	// found. Player requests carry it so YouTube scrambles signatures the
+	// way this script unscrambles them.
+	sts int
+}
+
+var (
+	// sigFuncRegex matches the signature routine: split the string, apply a
+	// series of helper calls, join it back.
+	sigFuncRegex = regexp.MustCompile(`function(?:\s+[\w$]+)?\(\s*([\w$]+)\s*\)\s*\{\s*[\w$]+=[\w$]+\.split\(""\);((?:[\w$]+(?:\.[\w$]+|\["[\w$]+"\])\([\w$]+,\d+\);)+)return [\w$]+\.join\(""\)\s*\}`)
+	sigCallRegex = regexp.MustCompile(`([\w$]+)(?:\.([\w$]+)|\["([\w$]+)"\])\([\w$]+,(\d+)\)`)
+	// helperMethodRegex matches one method of the helper object. Bodies are
+	// short and contain no braces.
+	helperMethodRegex = regexp.MustCompile(`"?([\w$]+)"?:function\([\w$]+(?:,[\w$]+)?\)\{([^}]*)\}`)
+	// nCallRegex matches where the n parameter is read and transformed. The
+	// function may be referenced through an array.
+	nCallRegex = regexp.MustCompile(`\.get\("n"\)\)&&\([\w$]+=([\w$]+)(?:\[(\d+)\])?\([\w$]+\)`)
+	// stsRegex matches the signature timestamp in the script's config.
+	stsRegex = regexp.MustCompile(`(?:signatureTimestamp|sts)\s*:\s*(\d+)`)
+)
+
+// parsePlayerCipher extracts the signature transform and the n transform
+// source from a player script.
+//
+// The signature routine is translated into Go rather than executed: it is
+// always a sequence of reverse, splice, and swap operations on the
+// signature's characters. The n transform has no such fixed shape, so only
+// its source is extracted; see StreamResolver.EvalN.
+func parsePlayerCipher(js string) (*playerCipher, error) {
+	m := sigFuncRegex.FindStringSubmatch(js)
+	if m == nil {
+		return nil, fmt.Errorf("%w: signature function", youtube.ErrCipherNotFound)
+	}
+	calls := sigCallRegex.FindAllStringSubmatch(m[2], -1)
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("%w: signature function is empty", youtube.ErrCipherNotFound)
+	}
+
+	helper := calls[0][1]
+	methods, err := parseCipherHelper(js, helper)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &playerCipher{}
+	for _, call := range calls {
+		name := call[2]
+		if name == "" {
+			name = call[3]
+		}
+		kind, ok := methods[name]
+		if !ok || call[1] != helper {
+			return nil, fmt.Errorf("%w: unknown helper %s.%s", youtube.ErrCipherNotFound, call[1], name)
+		}
+		arg, _ := strconv.Atoi(call[4])
+		c.ops = append(c.ops, cipherOp{kind: kind, arg: arg})
+	}
+
+	c.nFunc = extractNFunction(js)
+	if m := stsRegex.FindStringSubmatch(js); m != nil {
+		c.sts, _ = strconv.Atoi(m[1])
+	}
+	return c, nil
+}
+
+// parseCipherHelper finds the object the signature routine calls into and
+// classifies each of its methods.
+func parseCipherHelper(js, name string) (map[string]string, error) {
+	start := regexp.MustCompile(`(?:var |[;,\s])` + regexp.QuoteMeta(name) + `=\{`).FindStringIndex(js)
+	if start == nil {
+		return nil, fmt.Errorf("%w: helper object %s", youtube.ErrCipherNotFound, name)
+	}
+	body := matchBraces(js[start[1]-1:])
+	if body == "" {
+		return nil, fmt.Errorf("%w: helper object %s is unterminated", youtube.ErrCipherNotFound, name)
+	}
+
+	methods := make(map[string]string)
+	for _, m := range helperMethodRegex.FindAllStringSubmatch(body, -1) {
+		switch {
+		case strings.Contains(m[2], "reverse()"):
+			methods[m[1]] = "reverse"
+		case strings.Contains(m[2], "splice("):
+			methods[m[1]] = "splice"
+		case strings.Contains(m[2], "%"):
+			methods[m[1]] = "swap"
+		}
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("%w: helper object %s has no known methods", youtube.ErrCipherNotFound, name)
+	}
+	return methods, nil
+}
+
+// extractNFunction returns the source of the n parameter transform as a
+// function expression, or "" if it cannot be found.
+func extractNFunction(js string) string {
+	m := nCallRegex.FindStringSubmatch(js)
+	if m == nil {
+		return ""
+	}
+	name := m[1]
+	if m[2] != "" {
+		// var Xy=[fn]; the transform is referenced as Xy[0]
+		idx, _ := strconv.Atoi(m[2])
+		arr := regexp.MustCompile(`var ` + regexp.QuoteMeta(name) + `=\[([^\]]+)\]`).FindStringSubmatch(js)
+		if arr == nil {
+			return ""
+		}
+		names := strings.Split(arr[1], ",")
+		if idx >= len(names) {
+			return ""
+		}
+		name = strings.TrimSpace(names[idx])
+	}
+
+	loc := regexp.MustCompile(`(?:^|[;,\s])` + regexp.QuoteMeta(name) + `=function\(`).FindStringIndex(js)
+	if loc == nil {
+		return ""
+	}
+	fn := js[loc[1]-len("function("):]
+	open := strings.IndexByte(fn, '{')
+	if open < 0 {
+		return ""
+	}
+	body := matchBraces(fn[open:])
+	if body == "" {
+		return ""
+	}
+	return fn[:open] + body
+}
+
+// matchBraces returns the prefix of s, which must start with '{', up to and
+// including the matching '}'. String literals are skipped; regular
+// expression literals are not, so a brace inside one can cut the match
+// short. It returns "" if the braces are unbalanced.
+func matchBraces(s string) string {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[:i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// decipher applies the signature transform to s.
+func (c *playerCipher) decipher(s string) string {
+	b := []byte(s)
+	for _, op := range c.ops {
+		switch op.kind {
+		case "reverse":
+			for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+				b[i], b[j] = b[j], b[i]
+			}
+		case "splice":
+			if op.arg < len(b) {
+				b = b[op.arg:]
+			} else {
+				b = b[:0]
+			}
+		case "swap":
+			if len(b) > 0 {
+				k := op.arg % len(b)
+				b[0], b[k] = b[k], b[0]
+			}
+		}
+	}
+	return string(b)
+}
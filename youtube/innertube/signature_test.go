@@ -0,0 +1,66 @@
+package innertube
+
+import (
+	"errors"
+	"testing"
+	"ytsync/youtube"
+)
+
+// testPlayerJS mimics the shape of the signature and n routines in a real
+// player script.
+const testPlayerJS = `var cfg={signatureTimestamp:19876};
+var zx={Kp:function(a,b){a.splice(0,b)},
+"Xq":function(a){a.reverse()},
+t$:function(a,b){var c=a[0];a[0]=a[b%a.length];a[b%a.length]=c}};
+Gya=function(a){a=a.split("");zx.t$(a,3);zx["Xq"](a,12);zx.Kp(a,2);zx.t$(a,41);return a.join("")};
+var Yu=[Wpa];
+(b=a.get("n"))&&(b=Yu[0](b),a.set("n",b));
+Wpa=function(a){var b=a.split(""),c="}{";b.reverse();return b.join("")};`
+
+func TestParsePlayerCipher(t *testing.T) {
+	c, err := parsePlayerCipher(testPlayerJS)
+	if err != nil {
+		t.Fatalf("parsePlayerCipher() error = %v", err)
+	}
+	want := []cipherOp{{"swap", 3}, {"reverse", 12}, {"splice", 2}, {"swap", 41}}
+	if len(c.ops) != len(want) {
+		t.Fatalf("ops = %v, want %v", c.ops, want)
+	}
+	for i := range want {
+		if c.ops[i] != want[i] {
+			t.Errorf("ops[%d] = %v, want %v", i, c.ops[i], want[i])
+		}
+	}
+
+	wantN := `function(a){var b=a.split(""),c="}{";b.reverse();return b.join("")}`
+	if c.nFunc != wantN {
+		t.Errorf("nFunc = %q, want %q", c.nFunc, wantN)
+	}
+	if c.sts != 19876 {
+		t.Errorf("sts = %d, want 19876", c.sts)
+	}
+}
+
+func TestPlayerCipherDecipher(t *testing.T) {
+	c := &playerCipher{ops: []cipherOp{{"swap", 3}, {"reverse", 0}, {"splice", 2}, {"swap", 9}}}
+	// abcdefghij -> dbcaefghij -> jihgfeacbd -> hgfeacbd -> ghfeacbd
+	if got := c.decipher("abcdefghij"); got != "ghfeacbd" {
+		t.Errorf("decipher() = %q, want %q", got, "ghfeacbd")
+	}
+	if got := (&playerCipher{ops: []cipherOp{{"splice", 5}}}).decipher("abc"); got != "" {
+		t.Errorf("decipher() past end = %q, want empty", got)
+	}
+}
+
+func TestParsePlayerCipherNotFound(t *testing.T) {
+	tests := []string{
+		`var x=1;`,
+		`Gya=function(a){a=a.split("");zx.t$(a,3);return a.join("")};`,
+		`var zx={Kp:function(a,b){a.push(b)}};Gya=function(a){a=a.split("");zx.Kp(a,3);return a.join("")};`,
+	}
+	for _, js := range tests {
+		if _, err := parsePlayerCipher(js); !errors.Is(err, youtube.ErrCipherNotFound) {
+			t.Errorf("parsePlayerCipher(%q) error = %v, want ErrCipherNotFound", js, err)
+		}
+	}
+}
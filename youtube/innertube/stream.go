@@ -0,0 +1,310 @@
+package innertube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	ythttp "ytsync/http"
+	"ytsync/youtube"
+)
+
+// jsURLRegex matches the player script URL in a watch page.
+var jsURLRegex = regexp.MustCompile(`"(?:jsUrl|PLAYER_JS_URL)":"([^"]+base\.js)"`)
+
+// StreamResolver resolves direct stream URLs in pure Go, for callers that
+// need audio or video streams but cannot install yt-dlp. It reads the
+// streams from a player request and decodes signature-protected URLs with
+// the transform extracted from the player script.
+//
+// YouTube also scrambles the n query parameter of stream URLs. Decoding it
+// needs a JavaScript interpreter, which the standard library lacks, so
+// unless EvalN is set, Resolve leaves the parameter as-is and marks the
+// formats Throttled: their URLs still work, but YouTube serves them at
+// roughly real-time speed. Set RequireNTransform to fail instead.
+type StreamResolver struct {
+	// EvalN, if set, evaluates the player's n transform. source is a
+	// JavaScript function expression taking one string argument; EvalN
+	// returns the result of calling it with n. A JavaScript engine such as
+	// goja can implement it in a few lines.
+	EvalN func(source, n string) (string, error)
+
+	// RequireNTransform makes Resolve fail with
+	// youtube.ErrNTransformRequired when EvalN is nil, instead of
+	// returning throttled URLs with an undecoded n parameter.
+	RequireNTransform bool
+
+	// BaseURL is the site watch pages and player scripts are loaded from
+	// (default DefaultBaseURL).
+	BaseURL string
+
+	client *Client
+
+	mu      sync.Mutex
+	ciphers map[string]*playerCipher // by player script URL
+	sts     int                      // signature timestamp of the last script
+}
+
+// NewStreamResolver creates a stream resolver that sends player requests
+// with httpClient. If httpClient is nil, a client with default settings is
+// created.
+func NewStreamResolver(httpClient *ythttp.Client) *StreamResolver {
+	if httpClient == nil {
+		httpClient = ythttp.New(ythttp.DefaultConfig())
+	}
+	return &StreamResolver{
+		BaseURL: DefaultBaseURL,
+		client:  NewClient(httpClient),
+		ciphers: make(map[string]*playerCipher),
+	}
+}
+
+// Resolve returns the streams YouTube offers for videoID, muxed formats
+// first, then adaptive ones, in the order YouTube lists them.
+//
+// Returns an error matching youtube.ErrNoStreams, and the reason from
+// PlayerResponse.Err, if the video is not playable; youtube.ErrBotDetected
+// if YouTube refuses the request as automated traffic;
+// youtube.ErrCipherNotFound if signature-protected URLs cannot be decoded;
+// and, with RequireNTransform, youtube.ErrNTransformRequired if a URL's n
+// parameter needs EvalN.
+func (r *StreamResolver) Resolve(ctx context.Context, videoID string) ([]youtube.StreamFormat, error) {
+	if videoID == "" {
+		return nil, fmt.Errorf("%w: video ID is required", youtube.ErrInvalidURL)
+	}
+
+	r.mu.Lock()
+	sts := r.sts
+	r.mu.Unlock()
+	resp, err := r.player(ctx, videoID, sts)
+	if err != nil {
+		return nil, err
+	}
+
+	var cipher *playerCipher
+	if r.needsCipher(resp.StreamingData) {
+		if cipher, err = r.playerCipher(ctx, videoID); err != nil {
+			return nil, fmt.Errorf("resolve streams for %s: %w", videoID, err)
+		}
+		// Signatures only decode with the script whose timestamp the
+		// request carried, so ask again after a player update
+		if cipher.sts != 0 && cipher.sts != sts {
+			if resp, err = r.player(ctx, videoID, cipher.sts); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	raw := make([]Format, 0, len(resp.StreamingData.Formats)+len(resp.StreamingData.AdaptiveFormats))
+	raw = append(raw, resp.StreamingData.Formats...)
+	muxed := len(raw)
+	raw = append(raw, resp.StreamingData.AdaptiveFormats...)
+
+	nCache := make(map[string]string)
+	formats := make([]youtube.StreamFormat, 0, len(raw))
+	for i := range raw {
+		rf := &raw[i]
+		f := newStreamFormat(rf, i >= muxed)
+
+		if f.URL == "" && rf.SignatureCipher != "" {
+			if f.URL, err = decipherURL(cipher, rf.SignatureCipher); err != nil {
+				return nil, fmt.Errorf("resolve streams for %s: itag %d: %w", videoID, f.Itag, err)
+			}
+		}
+		if f.URL == "" {
+			continue
+		}
+
+		switch {
+		case r.EvalN != nil:
+			if f.URL, err = r.transformN(cipher, f.URL, nCache); err != nil {
+				return nil, fmt.Errorf("resolve streams for %s: itag %d: %w", videoID, f.Itag, err)
+			}
+		case hasN(f.URL):
+			if r.RequireNTransform {
+				return nil, fmt.Errorf("resolve streams for %s: itag %d: %w; set EvalN to decode it",
+					videoID, f.Itag, youtube.ErrNTransformRequired)
+			}
+			f.Throttled = true
+		}
+		formats = append(formats, f)
+	}
+
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("%w for %s: player response lists no formats", youtube.ErrNoStreams, videoID)
+	}
+	return formats, nil
+}
+
+// player sends a player request for videoID with signature timestamp sts
+// and checks that the response has streams.
+func (r *StreamResolver) player(ctx context.Context, videoID string, sts int) (*PlayerResponse, error) {
+	resp, err := r.client.player(ctx, videoID, sts)
+	if err != nil {
+		return nil, fmt.Errorf("resolve streams for %s: %w", videoID, err)
+	}
+	if err := resp.Err(); err != nil {
+		if errors.Is(err, youtube.ErrBotDetected) {
+			return nil, fmt.Errorf("resolve streams for %s: %w", videoID, err)
+		}
+		return nil, fmt.Errorf("%w for %s: %w", youtube.ErrNoStreams, videoID, err)
+	}
+	if resp.StreamingData == nil {
+		return nil, fmt.Errorf("%w for %s: player response has no streaming data", youtube.ErrNoStreams, videoID)
+	}
+	return resp, nil
+}
+
+// needsCipher reports whether decoding the streams of sd needs the player
+// script: some are signature-protected, or EvalN is set.
+func (r *StreamResolver) needsCipher(sd *StreamingData) bool {
+	if r.EvalN != nil {
+		return true
+	}
+	for _, formats := range [][]Format{sd.Formats, sd.AdaptiveFormats} {
+		for i := range formats {
+			if formats[i].URL == "" && formats[i].SignatureCipher != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// playerCipher returns the cipher of the player script the watch page of
+// videoID references, fetching and parsing the script the first time it
+// is seen.
+func (r *StreamResolver) playerCipher(ctx context.Context, videoID string) (*playerCipher, error) {
+	resp, err := r.client.httpClient.Do(ctx, http.MethodGet, r.BaseURL+"/watch?v="+url.QueryEscape(videoID)+"&hl=en", nil, htmlPageHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("fetch watch page: %w", err)
+	}
+	m := jsURLRegex.FindSubmatch(resp.Body)
+	if m == nil {
+		return nil, fmt.Errorf("%w: player script URL not found in page", youtube.ErrCipherNotFound)
+	}
+	jsURL := strings.ReplaceAll(string(m[1]), `\/`, "/")
+	if strings.HasPrefix(jsURL, "/") {
+		jsURL = r.BaseURL + jsURL
+	}
+
+	r.mu.Lock()
+	cached := r.ciphers[jsURL]
+	r.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	resp, err = r.client.httpClient.Do(ctx, http.MethodGet, jsURL, nil, htmlPageHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("fetch player script: %w", err)
+	}
+	cipher, err := parsePlayerCipher(string(resp.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.ciphers[jsURL] = cipher
+	if cipher.sts != 0 {
+		r.sts = cipher.sts
+	}
+	r.mu.Unlock()
+	return cipher, nil
+}
+
+// decipherURL builds a stream URL from a signatureCipher value: the
+// scrambled signature s is decoded and added to url under the parameter
+// named by sp.
+func decipherURL(cipher *playerCipher, signatureCipher string) (string, error) {
+	params, err := url.ParseQuery(signatureCipher)
+	if err != nil {
+		return "", fmt.Errorf("parse signatureCipher: %w", err)
+	}
+	streamURL, err := url.Parse(params.Get("url"))
+	if err != nil || params.Get("url") == "" || params.Get("s") == "" {
+		return "", fmt.Errorf("%w: malformed signatureCipher", youtube.ErrCipherNotFound)
+	}
+	sp := params.Get("sp")
+	if sp == "" {
+		sp = "signature"
+	}
+	q := streamURL.Query()
+	q.Set(sp, cipher.decipher(params.Get("s")))
+	streamURL.RawQuery = q.Encode()
+	return streamURL.String(), nil
+}
+
+// transformN replaces the n parameter of streamURL with its decoded value.
+// Every format of a video shares one n value, so results are cached.
+func (r *StreamResolver) transformN(cipher *playerCipher, streamURL string, cache map[string]string) (string, error) {
+	u, err := url.Parse(streamURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	n := q.Get("n")
+	if n == "" {
+		return streamURL, nil
+	}
+	if cipher.nFunc == "" {
+		return "", fmt.Errorf("%w: n transform", youtube.ErrCipherNotFound)
+	}
+
+	decoded, ok := cache[n]
+	if !ok {
+		if decoded, err = r.EvalN(cipher.nFunc, n); err != nil {
+			return "", fmt.Errorf("evaluate n transform: %w", err)
+		}
+		cache[n] = decoded
+	}
+	q.Set("n", decoded)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// hasN reports whether streamURL has an n parameter to decode.
+func hasN(streamURL string) bool {
+	u, err := url.Parse(streamURL)
+	return err == nil && u.Query().Get("n") != ""
+}
+
+// newStreamFormat converts a streamingData format. The URL is left empty
+// for signature-protected formats.
+func newStreamFormat(rf *Format, adaptive bool) youtube.StreamFormat {
+	f := youtube.StreamFormat{
+		Itag:          rf.Itag,
+		URL:           rf.URL,
+		MimeType:      rf.MimeType,
+		Quality:       rf.Quality,
+		QualityLabel:  rf.QualityLabel,
+		Width:         rf.Width,
+		Height:        rf.Height,
+		FPS:           rf.FPS,
+		Bitrate:       rf.Bitrate,
+		AudioChannels: rf.AudioChannels,
+		Adaptive:      adaptive,
+	}
+	// mimeType is `video/mp4; codecs="avc1.640028"`
+	if mime, params, ok := strings.Cut(rf.MimeType, ";"); ok {
+		f.MimeType = strings.TrimSpace(mime)
+		if _, codecs, ok := strings.Cut(params, "codecs="); ok {
+			f.Codecs = strings.Trim(strings.TrimSpace(codecs), `"`)
+		}
+	}
+	f.ContentLength, _ = strconv.ParseInt(rf.ContentLength, 10, 64)
+	f.AudioSampleRate, _ = strconv.Atoi(rf.AudioSampleRate)
+	// The track ID is the language and a variant, e.g. "en-US.4"
+	if track := rf.AudioTrack; track != nil {
+		f.AudioLanguage, _, _ = strings.Cut(track.ID, ".")
+		f.AudioTrackName = track.DisplayName
+		f.DefaultAudio = track.AudioIsDefault
+		f.OriginalAudio = strings.Contains(track.DisplayName, "original")
+	}
+	return f
+}
@@ -0,0 +1,224 @@
+package innertube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	ythttp "ytsync/http"
+	"ytsync/youtube"
+)
+
+const testWatchPage = `<html><script>var ytcfg={"PLAYER_JS_URL":"\/s\/player\/abc\/base.js"};</script></html>`
+
+const testStreamsResponse = `{"playabilityStatus":{"status":"OK"},"streamingData":{
+"formats":[{"itag":18,"url":"https://rr1.example/videoplayback?itag=18&n=slow","mimeType":"video/mp4; codecs=\"avc1.42001E, mp4a.40.2\"","quality":"medium","qualityLabel":"360p","width":640,"height":360,"fps":30,"bitrate":500000,"contentLength":"1234","audioSampleRate":"44100","audioChannels":2}],
+"adaptiveFormats":[{"itag":251,"signatureCipher":"s=abcdefghij&sp=sig&url=https%3A%2F%2Frr1.example%2Fvideoplayback%3Fitag%3D251%26n%3Dslow","mimeType":"audio/webm; codecs=\"opus\"","quality":"tiny","bitrate":160000,"audioSampleRate":"48000","audioChannels":2}]
+}}`
+
+// streamTestServer answers player requests with the response for each
+// video ID, and watch pages and the player script from testWatchPage and
+// testPlayerJS.
+type streamTestServer struct {
+	responses     map[string]string
+	page          string
+	players       []PlayerRequest
+	scriptFetches int32
+}
+
+func (s *streamTestServer) client(t *testing.T) *ythttp.Client {
+	t.Helper()
+	cfg := ythttp.DefaultConfig()
+	cfg.RoundTripper = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body string
+		switch {
+		case req.URL.String() == playerEndpoint:
+			var pr PlayerRequest
+			data, _ := io.ReadAll(req.Body)
+			if err := json.Unmarshal(data, &pr); err != nil {
+				t.Errorf("player request %s: %v", data, err)
+			}
+			s.players = append(s.players, pr)
+			body = s.responses[pr.VideoID]
+		case req.URL.Path == "/watch":
+			body = s.page
+		case req.URL.Path == "/s/player/abc/base.js":
+			atomic.AddInt32(&s.scriptFetches, 1)
+			body = testPlayerJS
+		default:
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	return ythttp.New(cfg)
+}
+
+// signatureTimestamp returns the signature timestamp of a player request.
+func signatureTimestamp(pr PlayerRequest) int {
+	if pr.PlaybackContext == nil {
+		return 0
+	}
+	return pr.PlaybackContext.ContentPlaybackContext.SignatureTimestamp
+}
+
+func TestStreamResolverResolve(t *testing.T) {
+	server := &streamTestServer{
+		responses: map[string]string{"dQw4w9WgXcQ": testStreamsResponse, "other": testStreamsResponse},
+		page:      testWatchPage,
+	}
+	r := NewStreamResolver(server.client(t))
+
+	formats, err := r.Resolve(context.Background(), "dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(formats) != 2 {
+		t.Fatalf("got %d formats, want 2", len(formats))
+	}
+
+	muxed := formats[0]
+	if muxed.Itag != 18 || muxed.MimeType != "video/mp4" || muxed.Codecs != "avc1.42001E, mp4a.40.2" ||
+		muxed.Height != 360 || muxed.ContentLength != 1234 || muxed.AudioSampleRate != 44100 {
+		t.Errorf("muxed format = %+v", muxed)
+	}
+	if !muxed.HasVideo() || !muxed.HasAudio() || muxed.Adaptive {
+		t.Errorf("muxed format should carry audio and video")
+	}
+
+	audio := formats[1]
+	if !audio.Adaptive || audio.HasVideo() || !audio.HasAudio() || audio.Codecs != "opus" {
+		t.Errorf("audio format = %+v", audio)
+	}
+	u, err := url.Parse(audio.URL)
+	if err != nil {
+		t.Fatalf("parse deciphered URL: %v", err)
+	}
+	// testPlayerJS swaps 3, reverses, splices 2, and swaps 41
+	if got := u.Query().Get("sig"); got != "ghfeacbd" {
+		t.Errorf("sig = %q, want %q", got, "ghfeacbd")
+	}
+	if u.Query().Get("n") != "slow" || !audio.Throttled {
+		t.Errorf("n = %q, Throttled = %v, want it left untouched without EvalN", u.Query().Get("n"), audio.Throttled)
+	}
+
+	// The first request learns the script's signature timestamp and is
+	// repeated with it; later requests carry it from the start
+	if _, err := r.Resolve(context.Background(), "other"); err != nil {
+		t.Fatalf("Resolve() second call error = %v", err)
+	}
+	var got []int
+	for _, pr := range server.players {
+		got = append(got, signatureTimestamp(pr))
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 19876 || got[2] != 19876 {
+		t.Errorf("player request signature timestamps = %v, want [0 19876 19876]", got)
+	}
+	// The player script is cached across videos
+	if server.scriptFetches != 1 {
+		t.Errorf("player script fetched %d times, want 1", server.scriptFetches)
+	}
+}
+
+func TestStreamResolverEvalN(t *testing.T) {
+	server := &streamTestServer{
+		responses: map[string]string{"dQw4w9WgXcQ": testStreamsResponse},
+		page:      testWatchPage,
+	}
+	r := NewStreamResolver(server.client(t))
+
+	var calls int
+	r.EvalN = func(source, n string) (string, error) {
+		calls++
+		if !strings.HasPrefix(source, "function(a)") {
+			t.Errorf("EvalN source = %q", source)
+		}
+		return "fast-" + n, nil
+	}
+
+	formats, err := r.Resolve(context.Background(), "dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	for _, f := range formats {
+		u, _ := url.Parse(f.URL)
+		if got := u.Query().Get("n"); got != "fast-slow" || f.Throttled {
+			t.Errorf("itag %d n = %q, Throttled = %v, want fast-slow", f.Itag, got, f.Throttled)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("EvalN called %d times, want 1 for a shared n value", calls)
+	}
+}
+
+func TestStreamResolverUnplayable(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		page     string
+		requireN bool
+		want     []error
+	}{
+		{
+			name:     "private",
+			response: `{"playabilityStatus":{"status":"LOGIN_REQUIRED","reason":"This video is private"}}`,
+			want:     []error{youtube.ErrNoStreams, youtube.ErrLoginRequired},
+		},
+		{
+			name:     "bot check",
+			response: `{"playabilityStatus":{"status":"LOGIN_REQUIRED","reason":"Sign in to confirm you're not a bot"}}`,
+			want:     []error{youtube.ErrBotDetected},
+		},
+		{
+			name:     "n without EvalN",
+			response: testStreamsResponse,
+			page:     testWatchPage,
+			requireN: true,
+			want:     []error{youtube.ErrNTransformRequired},
+		},
+		{
+			name:     "missing player script",
+			response: `{"playabilityStatus":{"status":"OK"},"streamingData":{"adaptiveFormats":[{"itag":251,"signatureCipher":"s=abc&url=https%3A%2F%2Fx"}]}}`,
+			page:     `<html></html>`,
+			want:     []error{youtube.ErrCipherNotFound},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &streamTestServer{responses: map[string]string{"abc": tt.response}, page: tt.page}
+			r := NewStreamResolver(server.client(t))
+			r.RequireNTransform = tt.requireN
+
+			_, err := r.Resolve(context.Background(), "abc")
+			for _, want := range tt.want {
+				if !errors.Is(err, want) {
+					t.Errorf("Resolve() error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamFormatAudioTrack(t *testing.T) {
+	var rf Format
+	if err := json.Unmarshal([]byte(`{"itag":140,"mimeType":"audio/mp4; codecs=\"mp4a.40.2\"",
+		"audioTrack":{"displayName":"English (United States) original","id":"en-US.4","audioIsDefault":true}}`), &rf); err != nil {
+		t.Fatal(err)
+	}
+	f := newStreamFormat(&rf, true)
+	if f.AudioLanguage != "en-US" || !f.DefaultAudio || !f.OriginalAudio || f.AudioTrackName != "English (United States) original" {
+		t.Errorf("format = %+v", f)
+	}
+	mf := f.MediaFormat()
+	if mf.Language != "en-US" || !mf.OriginalAudio || mf.AudioTrack != f.AudioTrackName {
+		t.Errorf("MediaFormat() = %+v", mf)
+	}
+}
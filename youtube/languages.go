@@ -31,6 +31,16 @@ func DefaultLanguagePreference() LanguagePreference {
 	}
 }
 
+// PreferenceForLanguages returns DefaultLanguagePreference with languages as
+// the preferred order. An empty list keeps the default (English).
+func PreferenceForLanguages(languages []string) LanguagePreference {
+	pref := DefaultLanguagePreference()
+	if len(languages) > 0 {
+		pref.PreferredLanguages = append([]string(nil), languages...)
+	}
+	return pref
+}
+
 // LanguageAvailability describes available languages for a video.
 type LanguageAvailability struct {
 	VideoID          string
@@ -1,6 +1,7 @@
 package youtube
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -317,3 +318,75 @@ func TestLanguageInfoConstants(t *testing.T) {
 		}
 	}
 }
+
+func TestPreferenceForLanguages(t *testing.T) {
+	pref := PreferenceForLanguages([]string{"de", "fr"})
+	if len(pref.PreferredLanguages) != 2 || pref.PreferredLanguages[0] != "de" {
+		t.Errorf("PreferredLanguages = %v, want [de fr]", pref.PreferredLanguages)
+	}
+	if !pref.AllowEnglishFallback || !pref.PreferManualCaptions {
+		t.Error("PreferenceForLanguages should keep default fallback behavior")
+	}
+
+	if got := PreferenceForLanguages(nil).PreferredLanguages; len(got) != 1 || got[0] != "en" {
+		t.Errorf("PreferenceForLanguages(nil).PreferredLanguages = %v, want [en]", got)
+	}
+}
+
+func TestExtractTranscript_Preference(t *testing.T) {
+	vtt := []subtitleFormat{{URL: "https://example.com/subs.vtt", Ext: "vtt"}}
+	info := &ytdlpVideoInfo{
+		ID:                "abc",
+		Subtitles:         map[string][]subtitleFormat{"fr": vtt, "en": vtt},
+		AutomaticCaptions: map[string][]subtitleFormat{"de": vtt, "en": vtt},
+	}
+	te := NewTranscriptExtractor()
+
+	tests := []struct {
+		name     string
+		langs    []string
+		skipAuto bool
+		wantLang string
+		wantAuto bool
+	}{
+		{name: "first preferred manual", langs: []string{"fr", "en"}, wantLang: "fr"},
+		{name: "auto-generated in preferred language", langs: []string{"de", "fr"}, wantLang: "de", wantAuto: true},
+		{name: "skip auto moves to next language", langs: []string{"de", "fr"}, skipAuto: true, wantLang: "fr"},
+		{name: "english fallback", langs: []string{"ja"}, wantLang: "en"},
+		{name: "default prefers english", wantLang: "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pref := PreferenceForLanguages(tt.langs)
+			got, err := te.extractTranscript(info, "abc", &ExtractOptions{Preference: &pref, SkipAutoGenerated: tt.skipAuto})
+			if err != nil {
+				t.Fatalf("extractTranscript() error = %v", err)
+			}
+			if got.Language != tt.wantLang || got.IsAutoGenerated != tt.wantAuto {
+				t.Errorf("selected %s (auto=%v), want %s (auto=%v)", got.Language, got.IsAutoGenerated, tt.wantLang, tt.wantAuto)
+			}
+		})
+	}
+}
+
+func TestExtractTranscript_PreferenceAnyTrack(t *testing.T) {
+	vtt := []subtitleFormat{{URL: "https://example.com/subs.vtt", Ext: "vtt"}}
+	info := &ytdlpVideoInfo{
+		ID:                "abc",
+		AutomaticCaptions: map[string][]subtitleFormat{"pt": vtt, "es": vtt},
+	}
+	pref := PreferenceForLanguages([]string{"ja"})
+
+	got, err := NewTranscriptExtractor().extractTranscript(info, "abc", &ExtractOptions{Preference: &pref})
+	if err != nil {
+		t.Fatalf("extractTranscript() error = %v", err)
+	}
+	// Falls back to the first track by code order
+	if got.Language != "es" || !got.IsAutoGenerated {
+		t.Errorf("selected %s (auto=%v), want es (auto=true)", got.Language, got.IsAutoGenerated)
+	}
+
+	if _, err := NewTranscriptExtractor().extractTranscript(info, "abc", &ExtractOptions{Preference: &pref, SkipAutoGenerated: true}); !errors.Is(err, ErrNoTranscript) {
+		t.Errorf("extractTranscript() with only auto captions and SkipAutoGenerated error = %v, want ErrNoTranscript", err)
+	}
+}
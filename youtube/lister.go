@@ -6,21 +6,54 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"regexp"
 	"strings"
 	"time"
+	"ytsync/errcode"
+	ythttp "ytsync/http"
+	"ytsync/storage"
 )
 
 // Sentinel errors for video listing operations.
 var (
-	ErrChannelNotFound   = errors.New("youtube: channel not found")
-	ErrRateLimited       = errors.New("youtube: rate limited")
-	ErrNetworkTimeout    = errors.New("youtube: network timeout")
-	ErrInvalidURL        = errors.New("youtube: invalid URL")
-	ErrYtdlpNotInstalled = errors.New("youtube: yt-dlp not installed")
+	ErrChannelNotFound   = errcode.New(errcode.NotFound, "youtube: channel not found")
+	ErrRateLimited       = errcode.New(errcode.RateLimited, "youtube: rate limited")
+	ErrNetworkTimeout    = errcode.New(errcode.Timeout, "youtube: network timeout")
+	ErrInvalidURL        = errcode.New(errcode.InvalidInput, "youtube: invalid URL")
+	ErrYtdlpNotInstalled = errcode.New(errcode.SubprocessFailure, "youtube: yt-dlp not installed")
+	ErrBotDetected       = errcode.New(errcode.BotDetected, "youtube: bot detection triggered")
+	// ErrSchemaChanged is returned when responses hold items but no video
+	// can be read from them, which usually means YouTube changed the
+	// response layout.
+	ErrSchemaChanged = errcode.New(errcode.ParseFailure, "youtube: unrecognized response layout")
+	// ErrVideoCountUnavailable is returned by a VideoCounter that cannot
+	// read an exact video count for a channel.
+	ErrVideoCountUnavailable = errcode.New(errcode.NotFound, "youtube: channel video count unavailable")
+	// ErrChannelTerminated is returned when YouTube answers a channel
+	// request with an alert that the channel does not exist or was
+	// terminated.
+	ErrChannelTerminated = errcode.New(errcode.NotFound, "youtube: channel terminated or does not exist")
+	// ErrAgeRestricted is returned when YouTube will only show a video or
+	// channel to a signed-in viewer who confirmed their age.
+	ErrAgeRestricted = errcode.New(errcode.Unavailable, "youtube: age-restricted")
+	// ErrLoginRequired is returned when YouTube will only show a video or
+	// channel to a signed-in viewer, such as a private video.
+	ErrLoginRequired = errcode.New(errcode.Unavailable, "youtube: sign-in required")
+	// ErrVideoUnavailable is returned when YouTube will not play a video
+	// for another reason, such as its removal or a region block.
+	ErrVideoUnavailable = errcode.New(errcode.Unavailable, "youtube: video unavailable")
 )
 
+// IsBotDetectionMessage reports whether yt-dlp stderr output or a player
+// response's playability reason indicates YouTube is blocking requests as
+// automated traffic.
+func IsBotDetectionMessage(msg string) bool {
+	return strings.Contains(msg, "confirm you're not a bot") ||
+		strings.Contains(msg, "confirm you’re not a bot")
+}
+
 // VideoLister defines the interface for fetching video lists from YouTube channels.
 // Different implementations may use different strategies (RSS, yt-dlp, API).
 type VideoLister interface {
@@ -33,6 +66,17 @@ type VideoLister interface {
 	SupportsFullHistory() bool
 }
 
+// VideoCounter is implemented by listers that can read a channel's total
+// video count without listing its videos. SyncManager compares the count
+// with the one saved at the last sync to skip full listings that would
+// find nothing new.
+type VideoCounter interface {
+	// VideoCount returns the number of public videos on the channel. It
+	// returns an error wrapping ErrVideoCountUnavailable if only an
+	// abbreviated count such as "1.2K videos" is shown.
+	VideoCount(ctx context.Context, channelURL string) (int, error)
+}
+
 // ListOptions configures video listing behavior.
 type ListOptions struct {
 	// MaxResults limits the number of videos returned. 0 means no limit.
@@ -42,6 +86,41 @@ type ListOptions struct {
 	// Zero time means no filter.
 	PublishedAfter time.Time
 
+	// PublishedBefore filters videos to only those published before this time.
+	// Combined with PublishedAfter it selects a date range, e.g. to backfill
+	// a historical window. Zero time means no filter.
+	PublishedBefore time.Time
+
+	// --- Content Filters ---
+
+	// MinDuration excludes videos shorter than this. Videos whose duration
+	// is unknown (zero, e.g. from RSS) are kept. Zero means no minimum.
+	MinDuration time.Duration
+
+	// MaxDuration excludes videos longer than this. Videos whose duration
+	// is unknown are kept. Zero means no maximum.
+	MaxDuration time.Duration
+
+	// TitleMatch, if set, keeps only videos whose title matches.
+	TitleMatch *regexp.Regexp
+
+	// MinViews excludes videos with fewer views than this. Zero means no minimum.
+	MinViews int64
+
+	// ExcludeLive excludes live streams and stream VODs.
+	ExcludeLive bool
+
+	// ExcludeMembersOnly excludes videos restricted to channel members.
+	ExcludeMembersOnly bool
+
+	// ExcludeUpcoming excludes scheduled streams and premieres that have not
+	// started yet, which have no media or transcript to fetch.
+	ExcludeUpcoming bool
+
+	// Rules, if set, skips the videos the rule set denies, after the other
+	// filters. See LoadStoreRules.
+	Rules *RuleSet
+
 	// SortOrder specifies how videos should be sorted.
 	// Default is SortByDate (newest first).
 	SortOrder SortOrder
@@ -50,6 +129,19 @@ type ListOptions struct {
 	// Default is ContentTypeVideos.
 	ContentType ContentType
 
+	// KnownVideoIDs are videos an earlier sync already has, newest first.
+	// Listers that walk a channel newest first may stop at the first of
+	// them instead of listing the whole channel (see
+	// YtdlpLister.Incremental). Only the first few are used.
+	KnownVideoIDs []string
+
+	// ProbeCaptions asks the lister to look up the caption tracks of each
+	// listed video and fill in VideoInfo.HasCaptions and CaptionLanguages,
+	// so transcript fetches that would certainly fail can be skipped. The
+	// Innertube lister probes with one player request per video; listers
+	// that cannot probe ignore it.
+	ProbeCaptions bool
+
 	// --- Resumable Pagination Options ---
 
 	// ResumeToken is an opaque token for resuming pagination.
@@ -67,6 +159,60 @@ type ListOptions struct {
 	OnProgress func(state *PaginationProgress) error
 }
 
+// Matches reports whether v passes the date range and content filters.
+// A nil ListOptions matches every video.
+func (o *ListOptions) Matches(v VideoInfo) bool {
+	if o == nil {
+		return true
+	}
+	if !o.PublishedAfter.IsZero() && !v.Published.After(o.PublishedAfter) {
+		return false
+	}
+	if !o.PublishedBefore.IsZero() && !v.Published.Before(o.PublishedBefore) {
+		return false
+	}
+	if v.Duration > 0 {
+		if o.MinDuration > 0 && v.Duration < o.MinDuration {
+			return false
+		}
+		if o.MaxDuration > 0 && v.Duration > o.MaxDuration {
+			return false
+		}
+	}
+	if o.TitleMatch != nil && !o.TitleMatch.MatchString(v.Title) {
+		return false
+	}
+	if o.MinViews > 0 && v.ViewCount < o.MinViews {
+		return false
+	}
+	if o.ExcludeLive && v.Type == VideoTypeStream {
+		return false
+	}
+	if o.ExcludeMembersOnly && v.IsMembersOnly {
+		return false
+	}
+	if o.ExcludeUpcoming && v.IsUpcoming() {
+		return false
+	}
+	return o.Rules.Allows(v)
+}
+
+// BeforeRange reports whether v was published at or before PublishedAfter.
+// Date-sorted listings are returned newest first, so once a lister reaches
+// such a video no later one can match and pagination can stop. It is always
+// false for other sort orders and for videos with an unknown publish time.
+func (o *ListOptions) BeforeRange(v VideoInfo) bool {
+	return o != nil && o.SortOrder == SortByDate && !o.PublishedAfter.IsZero() &&
+		!v.Published.IsZero() && !v.Published.After(o.PublishedAfter)
+}
+
+// hasContentFilters reports whether any filter other than the date range is
+// set, meaning fewer videos than were fetched may match.
+func (o *ListOptions) hasContentFilters() bool {
+	return o != nil && (o.MinDuration > 0 || o.MaxDuration > 0 || o.TitleMatch != nil ||
+		o.MinViews > 0 || o.ExcludeLive || o.ExcludeMembersOnly || o.ExcludeUpcoming || o.Rules != nil)
+}
+
 // PaginationProgress reports the current state of paginated listing.
 // This is passed to the OnProgress callback for state persistence.
 type PaginationProgress struct {
@@ -139,11 +285,54 @@ type VideoInfo struct {
 
 	// Type indicates whether this is a video or live stream.
 	Type string `json:"type,omitempty"`
+
+	// IsMembersOnly reports whether the video is restricted to channel members.
+	IsMembersOnly bool `json:"is_members_only,omitempty"`
+
+	// IsPremiere reports whether the video is a premiere: a pre-recorded
+	// upload first shown at a scheduled time.
+	IsPremiere bool `json:"is_premiere,omitempty"`
+
+	// ScheduledStartTime is when an upcoming stream or premiere is scheduled
+	// to start. Zero if not scheduled or unknown.
+	ScheduledStartTime time.Time `json:"scheduled_start_time,omitempty"`
+
+	// LiveStatus is the live state of a stream or premiere. Empty for
+	// regular uploads.
+	LiveStatus LiveStatus `json:"live_status,omitempty"`
+
+	// CaptionsProbed reports whether the lister looked up the video's
+	// caption tracks (see ListOptions.ProbeCaptions). HasCaptions and
+	// CaptionLanguages are only meaningful when it is set.
+	CaptionsProbed bool `json:"captions_probed,omitempty"`
+
+	// HasCaptions reports whether the video has any caption track,
+	// uploaded or automatic.
+	HasCaptions bool `json:"has_captions,omitempty"`
+
+	// CaptionLanguages are the language codes of the caption tracks.
+	CaptionLanguages []string `json:"caption_languages,omitempty"`
 }
 
+// LacksCaptions reports whether a caption probe found no caption tracks,
+// so a transcript fetch would certainly fail.
+func (v VideoInfo) LacksCaptions() bool {
+	return v.CaptionsProbed && !v.HasCaptions
+}
+
+// Values of VideoInfo.Type.
+const (
+	// VideoTypeVideo is a regular upload.
+	VideoTypeVideo = "video"
+	// VideoTypeStream is a live stream or the recording of one.
+	VideoTypeStream = "stream"
+	// VideoTypeShort is a YouTube Short.
+	VideoTypeShort = "short"
+)
+
 // VideoURL returns the full YouTube URL for this video.
 func (v VideoInfo) VideoURL() string {
-	return "https://www.youtube.com/watch?v=" + v.ID
+	return WatchURL(v.ID)
 }
 
 // ChannelURL returns the full YouTube URL for this video's channel.
@@ -180,6 +369,15 @@ type ChannelResolver struct {
 	// HTTPClient is the HTTP client to use for requests.
 	// If nil, a default client with 30-second timeout is used.
 	HTTPClient HTTPDoer
+	// RateLimiter, if set, paces channel page requests.
+	RateLimiter *ythttp.RateLimiter
+	// Client, if set, fetches channel pages through the resilient ythttp
+	// client instead of HTTPClient, with its rate limiting, circuit breaker,
+	// and retries. RateLimiter is not used in that case.
+	Client *ythttp.Client
+	// Aliases, if set, is consulted before fetching a channel page and
+	// records each handle and custom URL resolved over the network.
+	Aliases storage.ChannelAliasStore
 }
 
 // HTTPDoer is an interface for making HTTP requests.
@@ -206,13 +404,54 @@ func (r *ChannelResolver) ResolveChannelID(ctx context.Context, input string) (s
 		return id, nil
 	}
 
+	if id, ok := lookupAlias(ctx, r.Aliases, input); ok {
+		return id, nil
+	}
+
 	// Need to fetch the page to resolve handles/custom URLs
 	pageURL := toFetchableURL(input)
 	if pageURL == "" {
 		return "", fmt.Errorf("%w: cannot parse %q", ErrInvalidURL, input)
 	}
 
-	return r.fetchChannelID(ctx, pageURL)
+	id, err := r.fetchChannelID(ctx, pageURL)
+	if err != nil {
+		return "", err
+	}
+	rememberAlias(ctx, r.Aliases, input, id)
+	return id, nil
+}
+
+// lookupAlias returns the channel ID recorded for a handle or custom URL.
+func lookupAlias(ctx context.Context, aliases storage.ChannelAliasStore, input string) (string, bool) {
+	if aliases == nil {
+		return "", false
+	}
+	if _, _, ok := storage.NormalizeChannelAlias(input); !ok {
+		return "", false
+	}
+	mapping, err := aliases.GetChannelAlias(ctx, input)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("youtube: look up channel alias %q: %v", input, err)
+		}
+		return "", false
+	}
+	return mapping.YouTubeID, true
+}
+
+// rememberAlias records that a handle or custom URL resolved to channelID.
+// Failures are logged; resolution has already succeeded.
+func rememberAlias(ctx context.Context, aliases storage.ChannelAliasStore, input, channelID string) {
+	if aliases == nil {
+		return
+	}
+	if _, _, ok := storage.NormalizeChannelAlias(input); !ok {
+		return
+	}
+	if err := aliases.SaveChannelAlias(ctx, &storage.ChannelAlias{Alias: input, YouTubeID: channelID}); err != nil {
+		log.Printf("youtube: save channel alias %q: %v", input, err)
+	}
 }
 
 // extractChannelIDDirect extracts channel ID without making HTTP requests.
@@ -260,55 +499,121 @@ func toFetchableURL(input string) string {
 	return ""
 }
 
+// channelPageHeaders are browser-like headers that keep channel page
+// requests from being blocked.
+var channelPageHeaders = map[string]string{
+	"User-Agent":      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+	"Accept":          "text/html,application/xhtml+xml",
+	"Accept-Language": "en-US,en;q=0.9",
+}
+
+// maxChannelPageSize limits how much of a channel page is read; the
+// channel ID appears well before it.
+const maxChannelPageSize = 1 << 20
+
 // fetchChannelID fetches a channel page and extracts the channel ID.
 func (r *ChannelResolver) fetchChannelID(ctx context.Context, pageURL string) (string, error) {
+	body, err := r.fetchChannelPage(ctx, pageURL)
+	if err != nil {
+		return "", err
+	}
+
+	// Extract channel ID from various locations in the HTML
+	channelID := extractChannelIDFromHTML(string(body))
+	if channelID == "" {
+		return "", fmt.Errorf("%w: could not find channel ID in page", ErrInvalidURL)
+	}
+
+	return channelID, nil
+}
+
+// fetchChannelPage returns the HTML of a channel page.
+func (r *ChannelResolver) fetchChannelPage(ctx context.Context, pageURL string) ([]byte, error) {
+	if r.Client != nil {
+		resp, err := r.Client.Do(ythttp.WithBodyLimit(ctx, maxChannelPageSize), http.MethodGet, pageURL, nil, channelPageHeaders)
+		if err != nil {
+			return nil, fromHTTPClientError(ctx, err)
+		}
+		return resp.Body, nil
+	}
+
 	client := r.HTTPClient
 	if client == nil {
 		client = &http.Client{Timeout: 30 * time.Second}
 	}
 
+	if err := r.RateLimiter.Wait(ctx, pageURL); err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	// Set browser-like headers to avoid being blocked
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	for k, v := range channelPageHeaders {
+		req.Header.Set(k, v)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
 		if ctx.Err() != nil {
-			return "", ErrNetworkTimeout
+			return nil, ErrNetworkTimeout
 		}
-		return "", fmt.Errorf("fetch channel page: %w", err)
+		return nil, fmt.Errorf("fetch channel page: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return "", ErrChannelNotFound
+		return nil, ErrChannelNotFound
 	}
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return "", ErrRateLimited
+		return nil, rateLimited(r.RateLimiter, pageURL, resp)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 	}
 
-	// Read the body (limit to 1MB to avoid memory issues)
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	// Read the body (limited to avoid memory issues)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxChannelPageSize))
 	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
+		return nil, fmt.Errorf("read response: %w", err)
 	}
+	return body, nil
+}
 
-	// Extract channel ID from various locations in the HTML
-	channelID := extractChannelIDFromHTML(string(body))
-	if channelID == "" {
-		return "", fmt.Errorf("%w: could not find channel ID in page", ErrInvalidURL)
+// rateLimited records a 429 response to urlStr with limiter, which may be
+// nil, and returns ErrRateLimited wrapping a *ythttp.RateLimitError with the
+// server's Retry-After, as the ythttp client reports it.
+func rateLimited(limiter *ythttp.RateLimiter, urlStr string, resp *http.Response) error {
+	serverRetryAfter := ythttp.DefaultRetryAfterConfig().FromHeader(resp.Header)
+	retryAfter := limiter.RecordRateLimitError(urlStr, serverRetryAfter)
+	if serverRetryAfter > retryAfter {
+		retryAfter = serverRetryAfter
 	}
+	return fmt.Errorf("%w: %w", ErrRateLimited, &ythttp.RateLimitError{
+		StatusCode:       resp.StatusCode,
+		RetryAfter:       retryAfter,
+		ServerRetryAfter: serverRetryAfter,
+	})
+}
 
-	return channelID, nil
+// fromHTTPClientError maps errors from the ythttp client onto the listing
+// sentinels, so callers see the same errors whichever HTTP stack was used.
+func fromHTTPClientError(ctx context.Context, err error) error {
+	var rateErr *ythttp.RateLimitError
+	var httpErr *ythttp.HTTPError
+	switch {
+	case errors.As(err, &rateErr) && rateErr.IsBotDetection:
+		return fmt.Errorf("%w: %v", ErrBotDetected, err)
+	case errors.As(err, &rateErr):
+		return fmt.Errorf("%w: %v", ErrRateLimited, err)
+	case errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusGone):
+		return ErrChannelNotFound
+	case ctx.Err() != nil:
+		return ErrNetworkTimeout
+	}
+	return err
 }
 
 // extractChannelIDFromHTML extracts the channel ID from YouTube HTML.
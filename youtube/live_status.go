@@ -0,0 +1,58 @@
+package youtube
+
+import (
+	"time"
+	"ytsync/errcode"
+)
+
+// ErrNotYetAired is returned for transcript requests on a scheduled stream
+// or premiere that has not started, which has no captions yet.
+var ErrNotYetAired = errcode.New(errcode.Unavailable, "youtube: video has not aired yet")
+
+// LiveStatus is the live or scheduled state of a stream or premiere.
+type LiveStatus string
+
+const (
+	// LiveStatusNone is a regular upload that was never live.
+	LiveStatusNone LiveStatus = ""
+	// LiveStatusUpcoming is a scheduled stream or premiere that has not started.
+	LiveStatusUpcoming LiveStatus = "upcoming"
+	// LiveStatusLive is a stream or premiere that is airing now.
+	LiveStatusLive LiveStatus = "live"
+	// LiveStatusEnded is a stream or premiere that has finished airing.
+	LiveStatusEnded LiveStatus = "ended"
+)
+
+// IsUpcoming reports whether the video is a scheduled stream or premiere
+// that has not started, so it has no media or captions yet.
+func (v VideoInfo) IsUpcoming() bool {
+	return v.LiveStatus == LiveStatusUpcoming
+}
+
+// ytdlpLiveStatus maps yt-dlp's live_status field to a LiveStatus.
+func ytdlpLiveStatus(status string) LiveStatus {
+	switch status {
+	case "is_upcoming":
+		return LiveStatusUpcoming
+	case "is_live":
+		return LiveStatusLive
+	case "was_live", "post_live":
+		return LiveStatusEnded
+	}
+	return LiveStatusNone
+}
+
+// ytdlpMembersOnly reports whether yt-dlp's availability field marks a
+// video as restricted to channel members.
+func ytdlpMembersOnly(availability string) bool {
+	return availability == "subscriber_only"
+}
+
+// ytdlpScheduledStart returns the scheduled start of an upcoming video from
+// yt-dlp's release_timestamp, or the zero time.
+func ytdlpScheduledStart(status LiveStatus, releaseTimestamp int64) time.Time {
+	if status != LiveStatusUpcoming || releaseTimestamp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(releaseTimestamp, 0).UTC()
+}
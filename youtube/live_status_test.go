@@ -0,0 +1,86 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseYtdlpOutput_LiveStatus(t *testing.T) {
+	data := []byte(`{"channel_id": "UCtest", "entries": [
+		{"id": "prem", "title": "Premiere", "live_status": "is_upcoming", "release_timestamp": 1792515600},
+		{"id": "members", "title": "Members", "availability": "subscriber_only"},
+		{"id": "vod", "title": "VOD", "live_status": "was_live"}
+	]}`)
+
+	videos, err := parseYtdlpOutput(data, ContentTypeVideos)
+	if err != nil {
+		t.Fatalf("parseYtdlpOutput() error = %v", err)
+	}
+	if v := videos[0]; !v.IsPremiere || !v.IsUpcoming() || !v.ScheduledStartTime.Equal(time.Unix(1792515600, 0)) {
+		t.Errorf("videos[0] = %+v, want an upcoming premiere", v)
+	}
+	if v := videos[1]; !v.IsMembersOnly || v.LiveStatus != LiveStatusNone {
+		t.Errorf("videos[1] = %+v, want members-only", v)
+	}
+	if v := videos[2]; v.LiveStatus != LiveStatusEnded || v.IsPremiere {
+		t.Errorf("videos[2] = %+v, want an ended stream", v)
+	}
+
+	// On the Live tab an upcoming entry is a scheduled stream
+	videos, _ = parseYtdlpOutput(data, ContentTypeStreams)
+	if videos[0].IsPremiere || !videos[0].IsUpcoming() {
+		t.Errorf("stream tab entry = %+v, want an upcoming stream", videos[0])
+	}
+}
+
+func TestParseMetadata_LiveStatus(t *testing.T) {
+	md, err := parseMetadata([]byte(`{"id": "x", "title": "T", "live_status": "is_upcoming",
+		"release_timestamp": 1792515600, "availability": "subscriber_only", "media_type": "video"}`))
+	if err != nil {
+		t.Fatalf("parseMetadata() error = %v", err)
+	}
+	if md.LiveStatus != LiveStatusUpcoming || !md.IsPremiere || !md.IsMembersOnly || md.ScheduledStartTime.IsZero() {
+		t.Errorf("metadata = %+v", md)
+	}
+
+	md, _ = parseMetadata([]byte(`{"id": "x", "title": "T", "live_status": "is_live", "media_type": "livestream"}`))
+	if md.LiveStatus != LiveStatusLive || md.IsPremiere {
+		t.Errorf("stream metadata = %+v, want live and not a premiere", md)
+	}
+}
+
+func TestListOptions_MatchesLiveFilters(t *testing.T) {
+	upcoming := VideoInfo{ID: "a", LiveStatus: LiveStatusUpcoming, IsPremiere: true}
+	members := VideoInfo{ID: "b", IsMembersOnly: true}
+
+	opts := &ListOptions{}
+	if !opts.Matches(upcoming) || !opts.Matches(members) {
+		t.Error("videos excluded without filters")
+	}
+	opts = &ListOptions{ExcludeUpcoming: true, ExcludeMembersOnly: true}
+	if opts.Matches(upcoming) || opts.Matches(members) {
+		t.Error("filters did not exclude upcoming or members-only videos")
+	}
+	if !opts.Matches(VideoInfo{ID: "c", LiveStatus: LiveStatusEnded}) {
+		t.Error("ended stream excluded by ExcludeUpcoming")
+	}
+}
+
+func TestEnrich_SkipsUpcomingTranscripts(t *testing.T) {
+	called := false
+	opts := &EnrichOptions{
+		Transcripts: func(ctx context.Context, videoID string) (*Transcript, error) {
+			called = true
+			return nil, ErrNoTranscript
+		},
+	}
+	results, err := Enrich(context.Background(), "UCtest", []VideoInfo{{ID: "prem", LiveStatus: LiveStatusUpcoming}}, opts)
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if called || !errors.Is(results[0].Errors[StageTranscript], ErrNotYetAired) {
+		t.Errorf("transcript fetched = %v, error = %v; want ErrNotYetAired without a fetch", called, results[0].Errors[StageTranscript])
+	}
+}
@@ -1,11 +1,9 @@
 package youtube
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"time"
 )
 
@@ -23,6 +21,10 @@ type VideoMetadata struct {
 	Duration int `json:"duration"`
 	// ViewCount is the total number of views.
 	ViewCount int64 `json:"view_count"`
+	// LikeCount is the number of likes. Zero if hidden or unavailable.
+	LikeCount int64 `json:"like_count,omitempty"`
+	// CommentCount is the number of comments. Zero if disabled or unavailable.
+	CommentCount int64 `json:"comment_count,omitempty"`
 	// UploadDate is when the video was uploaded in YYYYMMDD format.
 	UploadDate string `json:"upload_date"`
 	// Uploader is the channel name/display name.
@@ -39,6 +41,33 @@ type VideoMetadata struct {
 	Tags []string `json:"tags"`
 	// IsLiveContent indicates whether this is a live stream or premiere.
 	IsLiveContent bool `json:"is_live_content"`
+	// IsMembersOnly reports whether the video is restricted to channel members.
+	IsMembersOnly bool `json:"is_members_only,omitempty"`
+	// IsPremiere reports whether the video is a premiere rather than a
+	// live stream. Only known while it is upcoming or airing.
+	IsPremiere bool `json:"is_premiere,omitempty"`
+	// ScheduledStartTime is when an upcoming stream or premiere starts.
+	ScheduledStartTime time.Time `json:"scheduled_start_time,omitempty"`
+	// LiveStatus is the live state of a stream or premiere.
+	LiveStatus LiveStatus `json:"live_status,omitempty"`
+	// FileSize is the expected size in bytes of the requested format, using
+	// yt-dlp's approximate size when the exact size is unknown. Zero if unknown.
+	FileSize int64 `json:"filesize,omitempty"`
+	// Chapters are the uploader-defined chapters, in start-time order.
+	// Empty if the video has no chapters.
+	Chapters []Chapter `json:"chapters,omitempty"`
+	// DescriptionLinks are the URLs, hashtags, and timestamps in the
+	// description. Nil if it has none.
+	DescriptionLinks *DescriptionLinks `json:"description_links,omitempty"`
+	// DerivedChapters are the chapters the description's timestamps define,
+	// for channels that publish chapters only there. Empty if they define
+	// none.
+	DerivedChapters []Chapter `json:"derived_chapters,omitempty"`
+	// Formats lists the audio and video formats available for download.
+	Formats []MediaFormat `json:"formats,omitempty"`
+	// AudioTracks lists the video's audio tracks, such as dubbed versions,
+	// original language first. Empty if the formats report no languages.
+	AudioTracks []AudioTrack `json:"audio_tracks,omitempty"`
 	// FetchedAt is the timestamp when this metadata was retrieved.
 	FetchedAt time.Time `json:"fetched_at"`
 }
@@ -48,19 +77,19 @@ type VideoMetadata struct {
 // The provided context is used to enforce timeouts and handle cancellation.
 func FetchMetadata(ctx context.Context, videoID string, ytdlpPath string) (*VideoMetadata, error) {
 	// Run yt-dlp to get JSON metadata
-	cmd := exec.CommandContext(ctx, ytdlpPath, "-J", "--no-warnings", videoID)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
+	stdout, err := runYtdlp(ctx, ytdlpPath, "-J", "--no-warnings", videoID)
+	if err != nil {
 		return nil, fmt.Errorf("fetch metadata: %w", err)
 	}
 
+	return parseMetadata(stdout)
+}
+
+// parseMetadata parses yt-dlp's -J output into a VideoMetadata struct.
+func parseMetadata(data []byte) (*VideoMetadata, error) {
 	// Parse the JSON output from yt-dlp
 	var rawData map[string]interface{}
-	if err := json.Unmarshal(stdout.Bytes(), &rawData); err != nil {
+	if err := json.Unmarshal(data, &rawData); err != nil {
 		return nil, fmt.Errorf("parse metadata JSON: %w", err)
 	}
 
@@ -95,6 +124,14 @@ func FetchMetadata(ctx context.Context, videoID string, ytdlpPath string) (*Vide
 		metadata.ViewCount = int64(views)
 	}
 
+	if likes, ok := rawData["like_count"].(float64); ok {
+		metadata.LikeCount = int64(likes)
+	}
+
+	if comments, ok := rawData["comment_count"].(float64); ok {
+		metadata.CommentCount = int64(comments)
+	}
+
 	if date, ok := rawData["upload_date"].(string); ok {
 		metadata.UploadDate = date
 	}
@@ -141,6 +178,43 @@ func FetchMetadata(ctx context.Context, videoID string, ytdlpPath string) (*Vide
 		metadata.IsLiveContent = live
 	}
 
+	// Live, premiere, and membership state
+	if status, ok := rawData["live_status"].(string); ok {
+		metadata.LiveStatus = ytdlpLiveStatus(status)
+	}
+	if availability, ok := rawData["availability"].(string); ok {
+		metadata.IsMembersOnly = ytdlpMembersOnly(availability)
+	}
+	if release, ok := rawData["release_timestamp"].(float64); ok {
+		metadata.ScheduledStartTime = ytdlpScheduledStart(metadata.LiveStatus, int64(release))
+	}
+	// yt-dlp marks streams, but not premieres, with media_type "livestream"
+	if mediaType, ok := rawData["media_type"].(string); ok && mediaType != "livestream" {
+		metadata.IsPremiere = metadata.LiveStatus == LiveStatusUpcoming || metadata.LiveStatus == LiveStatusLive
+	}
+
+	// Expected file size
+	if size, ok := rawData["filesize"].(float64); ok && size > 0 {
+		metadata.FileSize = int64(size)
+	} else if size, ok := rawData["filesize_approx"].(float64); ok && size > 0 {
+		metadata.FileSize = int64(size)
+	}
+
+	// Chapters
+	if chapters, ok := rawData["chapters"].([]interface{}); ok {
+		metadata.Chapters = parseChapters(chapters)
+	}
+
+	// Links and chapters in the description
+	metadata.DescriptionLinks = ParseDescription(metadata.Description, metadata.Duration)
+	metadata.DerivedChapters = DeriveChapters(metadata.DescriptionLinks, metadata.Duration)
+
+	// Available formats
+	if formats, ok := rawData["formats"].([]interface{}); ok {
+		metadata.Formats = parseMediaFormats(formats)
+		metadata.AudioTracks = AudioTracks(metadata.Formats)
+	}
+
 	// Validate we have at least the required fields
 	if metadata.ID == "" || metadata.Title == "" {
 		return nil, fmt.Errorf("invalid metadata: required fields missing")
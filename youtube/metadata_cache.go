@@ -0,0 +1,260 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"ytsync/storage"
+)
+
+// Default metadata cache settings.
+const (
+	// DefaultMetadataCacheTTL is how long cached metadata is considered fresh.
+	DefaultMetadataCacheTTL = 1 * time.Hour
+	// DefaultMetadataCacheStaleTTL is how long past the TTL stale metadata may
+	// still be served while it is refreshed in the background.
+	DefaultMetadataCacheStaleTTL = 24 * time.Hour
+	// DefaultMetadataRevalidateTimeout bounds a background refresh.
+	DefaultMetadataRevalidateTimeout = 2 * time.Minute
+)
+
+// MetadataFetcher retrieves metadata for a single video.
+type MetadataFetcher func(ctx context.Context, videoID string) (*VideoMetadata, error)
+
+// MetadataCacheStore is the backing store for a MetadataCache.
+// The age of an entry is derived from VideoMetadata.FetchedAt.
+type MetadataCacheStore interface {
+	// Get returns the cached metadata for a video, or false if not cached.
+	Get(videoID string) (*VideoMetadata, bool)
+	// Put stores metadata, replacing any existing entry for the video.
+	Put(metadata *VideoMetadata) error
+	// Delete removes the entry for a video. Deleting a missing entry is not an error.
+	Delete(videoID string) error
+}
+
+// MetadataCache caches video metadata keyed by video ID so that repeated
+// lookups don't each spawn a yt-dlp process.
+//
+// Entries younger than TTL are returned directly. Entries older than TTL but
+// younger than TTL+StaleTTL are returned immediately while a single background
+// refresh updates the cache (stale-while-revalidate). Older entries are
+// fetched synchronously.
+type MetadataCache struct {
+	// TTL is how long an entry is considered fresh.
+	TTL time.Duration
+	// StaleTTL is how long past TTL an entry may be served while revalidating.
+	// Zero disables stale-while-revalidate.
+	StaleTTL time.Duration
+	// RevalidateTimeout bounds each background refresh.
+	RevalidateTimeout time.Duration
+	// Store holds cached entries.
+	Store MetadataCacheStore
+	// Fetch retrieves metadata on a cache miss or refresh.
+	Fetch MetadataFetcher
+
+	mu           sync.Mutex
+	revalidating map[string]bool
+	now          func() time.Time
+}
+
+// NewMetadataCache creates an in-memory metadata cache that fetches with yt-dlp
+// at ytdlpPath, using the default TTL and stale window.
+func NewMetadataCache(ytdlpPath string) *MetadataCache {
+	return &MetadataCache{
+		TTL:               DefaultMetadataCacheTTL,
+		StaleTTL:          DefaultMetadataCacheStaleTTL,
+		RevalidateTimeout: DefaultMetadataRevalidateTimeout,
+		Store:             NewMemoryMetadataStore(),
+		Fetch: func(ctx context.Context, videoID string) (*VideoMetadata, error) {
+			return FetchMetadata(ctx, videoID, ytdlpPath)
+		},
+	}
+}
+
+// Get returns metadata for a video, serving from the cache when possible.
+// The result is the caller's own copy, which it may change.
+func (c *MetadataCache) Get(ctx context.Context, videoID string) (*VideoMetadata, error) {
+	if cached, ok := c.Store.Get(videoID); ok {
+		age := c.timeNow().Sub(cached.FetchedAt)
+		if age < c.TTL {
+			return cloneMetadata(cached)
+		}
+		if age < c.TTL+c.StaleTTL {
+			c.revalidate(ctx, videoID)
+			return cloneMetadata(cached)
+		}
+	}
+
+	return c.refresh(ctx, videoID)
+}
+
+// Invalidate removes a video's entry from the cache so the next Get fetches it.
+func (c *MetadataCache) Invalidate(videoID string) error {
+	return c.Store.Delete(videoID)
+}
+
+// refresh fetches metadata and stores it in the cache.
+func (c *MetadataCache) refresh(ctx context.Context, videoID string) (*VideoMetadata, error) {
+	metadata, err := c.Fetch(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := cloneMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Store.Put(entry); err != nil {
+		return nil, fmt.Errorf("cache metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// cloneMetadata returns a deep copy of m, so changes to metadata handed
+// out do not reach the cached entry. It copies through JSON, the form
+// FileMetadataStore keeps entries in, so new fields are copied too.
+func cloneMetadata(m *VideoMetadata) (*VideoMetadata, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("copy metadata: %w", err)
+	}
+	var c VideoMetadata
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("copy metadata: %w", err)
+	}
+	return &c, nil
+}
+
+// revalidate starts a background refresh unless one is already running.
+// Errors are ignored; the stale entry remains until it expires.
+func (c *MetadataCache) revalidate(ctx context.Context, videoID string) {
+	c.mu.Lock()
+	if c.revalidating == nil {
+		c.revalidating = make(map[string]bool)
+	}
+	if c.revalidating[videoID] {
+		c.mu.Unlock()
+		return
+	}
+	c.revalidating[videoID] = true
+	c.mu.Unlock()
+
+	timeout := c.RevalidateTimeout
+	if timeout <= 0 {
+		timeout = DefaultMetadataRevalidateTimeout
+	}
+	// Detach from the caller's cancellation so the refresh outlives the request
+	bgCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+
+	go func() {
+		defer cancel()
+		defer func() {
+			c.mu.Lock()
+			delete(c.revalidating, videoID)
+			c.mu.Unlock()
+		}()
+		c.refresh(bgCtx, videoID)
+	}()
+}
+
+func (c *MetadataCache) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// MemoryMetadataStore is an in-memory MetadataCacheStore.
+type MemoryMetadataStore struct {
+	mu      sync.RWMutex
+	entries map[string]*VideoMetadata
+}
+
+// NewMemoryMetadataStore creates an empty in-memory metadata store.
+func NewMemoryMetadataStore() *MemoryMetadataStore {
+	return &MemoryMetadataStore{entries: make(map[string]*VideoMetadata)}
+}
+
+// Get returns the cached metadata for a video.
+func (s *MemoryMetadataStore) Get(videoID string) (*VideoMetadata, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m, ok := s.entries[videoID]
+	return m, ok
+}
+
+// Put stores metadata for a video.
+func (s *MemoryMetadataStore) Put(metadata *VideoMetadata) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[metadata.ID] = metadata
+	return nil
+}
+
+// Delete removes the entry for a video.
+func (s *MemoryMetadataStore) Delete(videoID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, videoID)
+	return nil
+}
+
+// FileMetadataStore is a MetadataCacheStore that persists each entry as a
+// JSON file in a directory, so the cache survives process restarts.
+type FileMetadataStore struct {
+	dir string
+}
+
+// NewFileMetadataStore creates a file-backed metadata store rooted at dir.
+// The directory is created if it does not exist.
+func NewFileMetadataStore(dir string) (*FileMetadataStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create metadata cache directory: %w", err)
+	}
+	return &FileMetadataStore{dir: dir}, nil
+}
+
+// Get reads the cached metadata for a video. Unreadable entries are treated as misses.
+func (s *FileMetadataStore) Get(videoID string) (*VideoMetadata, bool) {
+	data, err := os.ReadFile(s.path(videoID))
+	if err != nil {
+		return nil, false
+	}
+	var m VideoMetadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, false
+	}
+	return &m, true
+}
+
+// Put atomically writes the metadata for a video.
+func (s *FileMetadataStore) Put(metadata *VideoMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+	w, err := storage.NewAtomicWriter(s.path(metadata.ID))
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Abort()
+		return fmt.Errorf("write metadata: %w", err)
+	}
+	return w.Commit()
+}
+
+// Delete removes the cached file for a video.
+func (s *FileMetadataStore) Delete(videoID string) error {
+	if err := os.Remove(s.path(videoID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileMetadataStore) path(videoID string) string {
+	return filepath.Join(s.dir, filepath.Base(videoID)+".json")
+}
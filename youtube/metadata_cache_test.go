@@ -0,0 +1,149 @@
+package youtube
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestMetadataCache(fetches *int32) *MetadataCache {
+	cache := NewMetadataCache("yt-dlp")
+	cache.TTL = time.Hour
+	cache.StaleTTL = time.Hour
+	cache.Fetch = func(ctx context.Context, videoID string) (*VideoMetadata, error) {
+		n := atomic.AddInt32(fetches, 1)
+		return &VideoMetadata{ID: videoID, ViewCount: int64(n), FetchedAt: cache.timeNow()}, nil
+	}
+	return cache
+}
+
+func TestMetadataCache_Fresh(t *testing.T) {
+	var fetches int32
+	cache := newTestMetadataCache(&fetches)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Get(ctx, "abc"); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Errorf("fetches = %d, want 1", got)
+	}
+}
+
+func TestMetadataCache_Copies(t *testing.T) {
+	var fetches int32
+	cache := newTestMetadataCache(&fetches)
+	ctx := context.Background()
+
+	first, err := cache.Get(ctx, "abc")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	first.Title = "changed"
+	first.Tags = append(first.Tags, "changed")
+
+	second, err := cache.Get(ctx, "abc")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	second.ViewCount = 99
+	third, err := cache.Get(ctx, "abc")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if second.Title != "" || len(second.Tags) != 0 || third.ViewCount != 1 {
+		t.Errorf("Get() after changes to earlier results = %+v, %+v, want the cached entry unchanged", second, third)
+	}
+}
+
+func TestMetadataCache_StaleWhileRevalidate(t *testing.T) {
+	var fetches int32
+	cache := newTestMetadataCache(&fetches)
+	ctx := context.Background()
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	if _, err := cache.Get(ctx, "abc"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	// Past TTL but within the stale window: stale value served immediately
+	now = now.Add(90 * time.Minute)
+	got, err := cache.Get(ctx, "abc")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.ViewCount != 1 {
+		t.Errorf("Get() ViewCount = %d, want stale value 1", got.ViewCount)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&fetches) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&fetches); got != 2 {
+		t.Fatalf("fetches = %d, want 2 after background refresh", got)
+	}
+}
+
+func TestMetadataCache_Expired(t *testing.T) {
+	var fetches int32
+	cache := newTestMetadataCache(&fetches)
+	ctx := context.Background()
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	cache.Get(ctx, "abc")
+
+	// Past TTL and stale window: fetched synchronously
+	now = now.Add(3 * time.Hour)
+	got, err := cache.Get(ctx, "abc")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.ViewCount != 2 {
+		t.Errorf("Get() ViewCount = %d, want fresh value 2", got.ViewCount)
+	}
+}
+
+func TestMetadataCache_Invalidate(t *testing.T) {
+	var fetches int32
+	cache := newTestMetadataCache(&fetches)
+	ctx := context.Background()
+
+	cache.Get(ctx, "abc")
+	if err := cache.Invalidate("abc"); err != nil {
+		t.Fatalf("Invalidate() error = %v", err)
+	}
+	cache.Get(ctx, "abc")
+	if got := atomic.LoadInt32(&fetches); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+}
+
+func TestFileMetadataStore(t *testing.T) {
+	store, err := NewFileMetadataStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileMetadataStore() error = %v", err)
+	}
+
+	if _, ok := store.Get("abc"); ok {
+		t.Error("Get() on empty store ok = true, want false")
+	}
+	if err := store.Put(&VideoMetadata{ID: "abc", Title: "Test"}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, ok := store.Get("abc")
+	if !ok || got.Title != "Test" {
+		t.Errorf("Get() = %+v, %v, want Title %q", got, ok, "Test")
+	}
+	if err := store.Delete("abc"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete("abc"); err != nil {
+		t.Errorf("Delete() missing entry error = %v, want nil", err)
+	}
+}
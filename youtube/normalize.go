@@ -0,0 +1,115 @@
+package youtube
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// noiseTagRegex matches the sound and music annotations YouTube's speech
+// recognition inserts, such as [Music] and [Applause], and music note
+// symbols.
+var noiseTagRegex = regexp.MustCompile(`(?i)\[\s*(?:music|music playing|background music|applause|laughter|laughs|cheering|cheers|noise|silence|inaudible|crosstalk)\s*\]|[♪♫]+`)
+
+// normalizeMaxGap is the largest gap, in seconds, between two entries
+// whose text is still compared for rolling overlap. ASR events that repeat
+// text follow each other directly.
+const normalizeMaxGap = 1.0
+
+// Normalize cleans up transcript entries, especially auto-generated ones.
+// It strips noise tags like [Music], drops entries left empty, sorts
+// entries by start time, and merges the rolling windows of YouTube ASR
+// captions: text an entry repeats from the end of the previous entry is
+// removed, an entry repeating the previous line is merged into it, and
+// overlapping entries are clipped so each ends where the next starts.
+//
+// The input is not modified. Manual captions normally pass through
+// unchanged apart from whitespace and noise tags.
+func Normalize(entries []TranscriptEntry) []TranscriptEntry {
+	cleaned := make([]TranscriptEntry, 0, len(entries))
+	for _, entry := range entries {
+		text := noiseTagRegex.ReplaceAllString(entry.Text, " ")
+		entry.Text = strings.Join(strings.Fields(text), " ")
+		if entry.Text == "" {
+			continue
+		}
+		if entry.Duration < 0 {
+			entry.Duration = 0
+		}
+		cleaned = append(cleaned, entry)
+	}
+	sort.SliceStable(cleaned, func(i, j int) bool {
+		return cleaned[i].Start < cleaned[j].Start
+	})
+
+	out := make([]TranscriptEntry, 0, len(cleaned))
+	for _, entry := range cleaned {
+		if len(out) == 0 {
+			out = append(out, entry)
+			continue
+		}
+		prev := &out[len(out)-1]
+		end := entryEnd(entry)
+
+		// A repeated line extends the previous entry
+		if strings.EqualFold(entry.Text, prev.Text) {
+			if end > entryEnd(*prev) {
+				prev.Duration = end - prev.Start
+			}
+			continue
+		}
+
+		if entry.Start-entryEnd(*prev) <= normalizeMaxGap {
+			words := strings.Fields(entry.Text)
+			if n := wordOverlap(strings.Fields(prev.Text), words); n >= 2 || (n > 0 && n == len(words)) {
+				if n == len(words) {
+					// Nothing new: the entry only repeats the previous tail
+					if end > entryEnd(*prev) {
+						prev.Duration = end - prev.Start
+					}
+					continue
+				}
+				entry.Text = strings.Join(words[n:], " ")
+			}
+		}
+
+		if entry.Start < entryEnd(*prev) {
+			prev.Duration = entry.Start - prev.Start
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// entryEnd returns the time an entry stops being shown.
+func entryEnd(e TranscriptEntry) float64 {
+	return e.Start + e.Duration
+}
+
+// wordOverlap returns the length of the longest run of words that ends
+// prev and starts next, ignoring case and surrounding punctuation.
+func wordOverlap(prev, next []string) int {
+	max := len(prev)
+	if len(next) < max {
+		max = len(next)
+	}
+	for n := max; n > 0; n-- {
+		match := true
+		for i := 0; i < n; i++ {
+			if !sameWord(prev[len(prev)-n+i], next[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return n
+		}
+	}
+	return 0
+}
+
+// sameWord compares two words ignoring case and surrounding punctuation.
+func sameWord(a, b string) bool {
+	const punct = `.,!?;:"'()-`
+	return strings.EqualFold(strings.Trim(a, punct), strings.Trim(b, punct))
+}
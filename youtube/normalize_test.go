@@ -0,0 +1,105 @@
+package youtube
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []TranscriptEntry
+		want    []TranscriptEntry
+	}{
+		{
+			name: "rolling ASR windows",
+			entries: []TranscriptEntry{
+				{Start: 0, Duration: 4, Text: "so today we're going"},
+				{Start: 2, Duration: 4, Text: "we're going to look at"},
+				{Start: 4, Duration: 4, Text: "look at channels"},
+			},
+			want: []TranscriptEntry{
+				{Start: 0, Duration: 2, Text: "so today we're going"},
+				{Start: 2, Duration: 2, Text: "to look at"},
+				{Start: 4, Duration: 4, Text: "channels"},
+			},
+		},
+		{
+			name: "noise tags and empty entries",
+			entries: []TranscriptEntry{
+				{Start: 0, Duration: 2, Text: "[Music]"},
+				{Start: 2, Duration: 2, Text: "♪ hello [Applause] there ♪"},
+			},
+			want: []TranscriptEntry{
+				{Start: 2, Duration: 2, Text: "hello there"},
+			},
+		},
+		{
+			name: "duplicate lines merge",
+			entries: []TranscriptEntry{
+				{Start: 0, Duration: 2, Text: "thank you"},
+				{Start: 2, Duration: 2, Text: "Thank you"},
+				{Start: 5, Duration: 1, Text: "bye"},
+			},
+			want: []TranscriptEntry{
+				{Start: 0, Duration: 4, Text: "thank you"},
+				{Start: 5, Duration: 1, Text: "bye"},
+			},
+		},
+		{
+			name: "out of order timestamps",
+			entries: []TranscriptEntry{
+				{Start: 3, Duration: 1, Text: "second"},
+				{Start: 1, Duration: 1, Text: "first"},
+			},
+			want: []TranscriptEntry{
+				{Start: 1, Duration: 1, Text: "first"},
+				{Start: 3, Duration: 1, Text: "second"},
+			},
+		},
+		{
+			name: "repeated tail is dropped",
+			entries: []TranscriptEntry{
+				{Start: 0, Duration: 3, Text: "the quick brown fox"},
+				{Start: 3, Duration: 1, Text: "brown fox"},
+			},
+			want: []TranscriptEntry{
+				{Start: 0, Duration: 4, Text: "the quick brown fox"},
+			},
+		},
+		{
+			name: "single shared word is kept",
+			entries: []TranscriptEntry{
+				{Start: 0, Duration: 2, Text: "over to the"},
+				{Start: 2, Duration: 2, Text: "the next part"},
+			},
+			want: []TranscriptEntry{
+				{Start: 0, Duration: 2, Text: "over to the"},
+				{Start: 2, Duration: 2, Text: "the next part"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Normalize(tt.entries)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Normalize() = %+v, want %+v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Normalize()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeDoesNotModifyInput(t *testing.T) {
+	entries := []TranscriptEntry{
+		{Start: 0, Duration: 4, Text: "one two three"},
+		{Start: 2, Duration: 2, Text: "two three four"},
+	}
+	Normalize(entries)
+	if entries[0].Duration != 4 || entries[1].Text != "two three four" {
+		t.Errorf("input modified: %+v", entries)
+	}
+}
@@ -0,0 +1,132 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ytsync/tags"
+)
+
+// errStageSkipped is recorded for a stage that did not run because a stage
+// it requires failed or was skipped.
+var errStageSkipped = errors.New("stage skipped: required stage failed")
+
+// stage is one step of per-video work. Stages with no ordering between them
+// run concurrently.
+type stage struct {
+	// name identifies the stage in dependency lists and results.
+	name string
+	// requires lists stages that must succeed before this stage runs.
+	// If any of them fails, this stage is skipped.
+	requires []string
+	// after lists stages that must finish, successfully or not, before this
+	// stage runs. Use it for stages that can make use of an earlier result
+	// but do not need it.
+	after []string
+	// run performs the stage for one job.
+	run func(ctx context.Context, job *enrichJob) error
+}
+
+// pipeline is a validated dependency graph of stages.
+type pipeline struct {
+	stages []stage
+}
+
+// newPipeline checks that every dependency names a known stage and that the
+// graph has no cycles.
+func newPipeline(stages ...stage) (*pipeline, error) {
+	index := make(map[string]int, len(stages))
+	for i, s := range stages {
+		if _, dup := index[s.name]; dup {
+			return nil, fmt.Errorf("pipeline: duplicate stage %q", s.name)
+		}
+		index[s.name] = i
+	}
+	for _, s := range stages {
+		for _, dep := range s.deps() {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("pipeline: stage %q depends on unknown stage %q", s.name, dep)
+			}
+		}
+	}
+
+	// Depth-first search for cycles
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(stages))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("pipeline: dependency cycle through stage %q", stages[i].name)
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		for _, dep := range stages[i].deps() {
+			if err := visit(index[dep]); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		return nil
+	}
+	for i := range stages {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return &pipeline{stages: stages}, nil
+}
+
+// deps returns every stage this stage waits for.
+func (s stage) deps() []string {
+	return append(append([]string(nil), s.requires...), s.after...)
+}
+
+// run executes all stages for job, each as soon as its dependencies have
+// finished, and returns the error of every stage that failed or was skipped.
+func (p *pipeline) run(ctx context.Context, job *enrichJob) map[string]error {
+	done := make(map[string]chan struct{}, len(p.stages))
+	for _, s := range p.stages {
+		done[s.name] = make(chan struct{})
+	}
+	// Each stage writes only its own slot, before closing its done channel
+	results := make(map[string]*error, len(p.stages))
+	for _, s := range p.stages {
+		results[s.name] = new(error)
+	}
+
+	for _, s := range p.stages {
+		go func(s stage) {
+			defer close(done[s.name])
+			for _, dep := range s.deps() {
+				<-done[dep]
+			}
+			for _, dep := range s.requires {
+				if *results[dep] != nil {
+					*results[s.name] = errStageSkipped
+					return
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				*results[s.name] = err
+				return
+			}
+			*results[s.name] = s.run(tags.WithOperation(ctx, s.name), job)
+		}(s)
+	}
+
+	errs := make(map[string]error)
+	for _, s := range p.stages {
+		<-done[s.name]
+		if err := *results[s.name]; err != nil {
+			errs[s.name] = err
+		}
+	}
+	return errs
+}
@@ -0,0 +1,79 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestPipeline_Validation(t *testing.T) {
+	noop := func(ctx context.Context, job *enrichJob) error { return nil }
+
+	tests := []struct {
+		name   string
+		stages []stage
+	}{
+		{
+			name:   "unknown dependency",
+			stages: []stage{{name: "a", requires: []string{"missing"}, run: noop}},
+		},
+		{
+			name:   "duplicate stage",
+			stages: []stage{{name: "a", run: noop}, {name: "a", run: noop}},
+		},
+		{
+			name: "cycle",
+			stages: []stage{
+				{name: "a", requires: []string{"b"}, run: noop},
+				{name: "b", after: []string{"a"}, run: noop},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newPipeline(tt.stages...); err == nil {
+				t.Error("newPipeline() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestPipeline_DependencyOrdering(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string, err error) func(ctx context.Context, job *enrichJob) error {
+		return func(ctx context.Context, job *enrichJob) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return err
+		}
+	}
+
+	fetchErr := errors.New("fetch failed")
+	p, err := newPipeline(
+		stage{name: "persist", after: []string{"fetch", "thumbnail"}, run: record("persist", nil)},
+		stage{name: "thumbnail", requires: []string{"fetch"}, run: record("thumbnail", nil)},
+		stage{name: "fetch", run: record("fetch", fetchErr)},
+	)
+	if err != nil {
+		t.Fatalf("newPipeline() error = %v", err)
+	}
+
+	errs := p.run(context.Background(), &enrichJob{result: &EnrichResult{}})
+
+	if !errors.Is(errs["fetch"], fetchErr) {
+		t.Errorf("fetch error = %v, want %v", errs["fetch"], fetchErr)
+	}
+	if !errors.Is(errs["thumbnail"], errStageSkipped) {
+		t.Errorf("thumbnail error = %v, want errStageSkipped", errs["thumbnail"])
+	}
+	if errs["persist"] != nil {
+		t.Errorf("persist error = %v, want nil (ordering-only dependency)", errs["persist"])
+	}
+	if len(order) != 2 || order[0] != "fetch" || order[1] != "persist" {
+		t.Errorf("run order = %v, want [fetch persist]", order)
+	}
+}
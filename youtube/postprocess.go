@@ -0,0 +1,179 @@
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"ytsync/proc"
+)
+
+// PostProcessor is run by a Downloader on each completed download, so
+// integrations learn that a file is finished without polling directories.
+// It receives the result with the final file path and, if it could be
+// fetched, the video metadata, and may update the result, for example when
+// it moves the file. Downloader.PostProcessors run in order; the first
+// error stops the chain.
+type PostProcessor interface {
+	PostProcess(ctx context.Context, result *DownloadResult) error
+}
+
+// PostProcessorFunc adapts a function to PostProcessor.
+type PostProcessorFunc func(ctx context.Context, result *DownloadResult) error
+
+// PostProcess calls f.
+func (f PostProcessorFunc) PostProcess(ctx context.Context, result *DownloadResult) error {
+	return f(ctx, result)
+}
+
+// ErrNoMetadata indicates a post-processor needs the video metadata, which
+// could not be fetched for the download.
+var ErrNoMetadata = errors.New("youtube: download has no metadata")
+
+// ChecksumProcessor computes a checksum of the downloaded file and stores
+// it in DownloadResult.Checksums.
+type ChecksumProcessor struct {
+	// Algorithm is "sha256" (default), "sha1", or "md5".
+	Algorithm string
+	// WriteFile also writes the checksum next to the file, as
+	// "<file>.<algorithm>" in the format sha256sum and similar tools read.
+	WriteFile bool
+}
+
+// PostProcess computes the checksum of result.VideoPath.
+func (p *ChecksumProcessor) PostProcess(ctx context.Context, result *DownloadResult) error {
+	algorithm := p.Algorithm
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	var h hash.Hash
+	switch algorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha1":
+		h = sha1.New()
+	case "md5":
+		h = md5.New()
+	default:
+		return fmt.Errorf("unknown checksum algorithm %q", algorithm)
+	}
+
+	f, err := os.Open(result.VideoPath)
+	if err != nil {
+		return fmt.Errorf("checksum: %w", err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("checksum %s: %w", result.VideoPath, err)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	if result.Checksums == nil {
+		result.Checksums = make(map[string]string)
+	}
+	result.Checksums[algorithm] = sum
+	if p.WriteFile {
+		line := sum + "  " + filepath.Base(result.VideoPath) + "\n"
+		if err := os.WriteFile(result.VideoPath+"."+algorithm, []byte(line), 0644); err != nil {
+			return fmt.Errorf("write checksum file: %w", err)
+		}
+	}
+	return nil
+}
+
+// SidecarProcessor writes the video metadata as JSON next to the
+// downloaded file and sets DownloadResult.MetadataPath.
+type SidecarProcessor struct {
+	// Suffix replaces the file's extension in the sidecar's name. Default:
+	// ".info.json".
+	Suffix string
+}
+
+// PostProcess writes the metadata sidecar of result.VideoPath.
+func (p *SidecarProcessor) PostProcess(ctx context.Context, result *DownloadResult) error {
+	if result.Metadata == nil {
+		return fmt.Errorf("metadata sidecar: %w", ErrNoMetadata)
+	}
+	suffix := p.Suffix
+	if suffix == "" {
+		suffix = ".info.json"
+	}
+	path := strings.TrimSuffix(result.VideoPath, filepath.Ext(result.VideoPath)) + suffix
+	if err := saveMetadataToFile(result.Metadata, path); err != nil {
+		return err
+	}
+	result.MetadataPath = path
+	return nil
+}
+
+// CommandProcessor runs a program on each download. Arguments equal to
+// "{}" are replaced by the file path. The program also gets the download
+// in its environment:
+//
+//	YTSYNC_FILE           the downloaded file
+//	YTSYNC_VIDEO_ID       the video ID
+//	YTSYNC_TITLE          the video title, if metadata was fetched
+//	YTSYNC_METADATA_FILE  the metadata sidecar, if one was written
+//	YTSYNC_SHA256 (etc.)  each checksum computed by a ChecksumProcessor
+//
+// A non-zero exit status fails the chain with the program's output.
+type CommandProcessor struct {
+	// Path is the program to run.
+	Path string
+	// Args are the program's arguments.
+	Args []string
+}
+
+// PostProcess runs the program for result.
+func (p *CommandProcessor) PostProcess(ctx context.Context, result *DownloadResult) error {
+	args := make([]string, len(p.Args))
+	for i, arg := range p.Args {
+		if arg == "{}" {
+			arg = result.VideoPath
+		}
+		args[i] = arg
+	}
+
+	cmd := proc.Command(ctx, p.Path, args...)
+	cmd.Env = append(os.Environ(),
+		"YTSYNC_FILE="+result.VideoPath,
+		"YTSYNC_VIDEO_ID="+result.VideoID,
+		"YTSYNC_METADATA_FILE="+result.MetadataPath,
+	)
+	if result.Metadata != nil {
+		cmd.Env = append(cmd.Env, "YTSYNC_TITLE="+result.Metadata.Title)
+	}
+	for algorithm, sum := range result.Checksums {
+		cmd.Env = append(cmd.Env, "YTSYNC_"+strings.ToUpper(algorithm)+"="+sum)
+	}
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return fmt.Errorf("run %s: %w: %s", p.Path, err, msg)
+		}
+		return fmt.Errorf("run %s: %w", p.Path, err)
+	}
+	return nil
+}
+
+// postProcess runs d.PostProcessors on result.
+func (d *Downloader) postProcess(ctx context.Context, result *DownloadResult) error {
+	for _, p := range d.PostProcessors {
+		if err := p.PostProcess(ctx, result); err != nil {
+			return fmt.Errorf("post-process %s: %w", result.VideoID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPostProcessors(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	videoPath := filepath.Join(dir, "Test Video.mp4")
+	if err := os.WriteFile(videoPath, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result := &DownloadResult{
+		VideoID:   "test1234567",
+		VideoPath: videoPath,
+		Metadata:  &VideoMetadata{ID: "test1234567", Title: "Test Video"},
+	}
+
+	if err := (&ChecksumProcessor{WriteFile: true}).PostProcess(ctx, result); err != nil {
+		t.Fatalf("ChecksumProcessor: %v", err)
+	}
+	const want = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	if result.Checksums["sha256"] != want {
+		t.Errorf("sha256 = %q, want %q", result.Checksums["sha256"], want)
+	}
+	data, _ := os.ReadFile(videoPath + ".sha256")
+	if string(data) != want+"  Test Video.mp4\n" {
+		t.Errorf("checksum file = %q", data)
+	}
+	if err := (&ChecksumProcessor{Algorithm: "md5"}).PostProcess(ctx, result); err != nil {
+		t.Fatalf("ChecksumProcessor(md5): %v", err)
+	}
+	if result.Checksums["md5"] != "b1946ac92492d2347c6235b4d2611184" || len(result.Checksums) != 2 {
+		t.Errorf("checksums = %v", result.Checksums)
+	}
+	if err := (&ChecksumProcessor{Algorithm: "crc32"}).PostProcess(ctx, result); err == nil {
+		t.Error("unknown algorithm accepted")
+	}
+
+	if err := (&SidecarProcessor{}).PostProcess(ctx, result); err != nil {
+		t.Fatalf("SidecarProcessor: %v", err)
+	}
+	if result.MetadataPath != filepath.Join(dir, "Test Video.info.json") {
+		t.Errorf("MetadataPath = %q", result.MetadataPath)
+	}
+	var meta VideoMetadata
+	data, _ = os.ReadFile(result.MetadataPath)
+	if err := json.Unmarshal(data, &meta); err != nil || meta.ID != "test1234567" {
+		t.Errorf("sidecar = %s (%v)", data, err)
+	}
+	if err := (&SidecarProcessor{}).PostProcess(ctx, &DownloadResult{VideoPath: videoPath}); !errors.Is(err, ErrNoMetadata) {
+		t.Errorf("SidecarProcessor without metadata = %v, want ErrNoMetadata", err)
+	}
+}
+
+func TestCommandProcessor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test command requires a POSIX shell")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.txt")
+	result := &DownloadResult{
+		VideoID:   "test1234567",
+		VideoPath: filepath.Join(dir, "video.mp4"),
+		Metadata:  &VideoMetadata{Title: "Test Video"},
+		Checksums: map[string]string{"sha256": "abc"},
+	}
+
+	p := &CommandProcessor{Path: "sh", Args: []string{"-c", `echo "$1|$YTSYNC_VIDEO_ID|$YTSYNC_TITLE|$YTSYNC_SHA256" > "` + out + `"`, "sh", "{}"}}
+	if err := p.PostProcess(ctx, result); err != nil {
+		t.Fatalf("PostProcess() error = %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if got, want := strings.TrimSpace(string(data)), result.VideoPath+"|test1234567|Test Video|abc"; got != want {
+		t.Errorf("command saw %q, want %q", got, want)
+	}
+
+	p = &CommandProcessor{Path: "sh", Args: []string{"-c", "echo disk full; exit 3"}}
+	if err := p.PostProcess(ctx, result); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("failing command error = %v, want its output", err)
+	}
+}
+
+func TestDownloader_PostProcessors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("mock yt-dlp requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	mockPath := filepath.Join(dir, "yt-dlp")
+	outputDir := filepath.Join(dir, "output")
+	script := `#!/bin/sh
+for arg in "$@"; do
+    if [ "$arg" = "-J" ]; then
+        echo '{"id": "test1234567", "title": "Test Video", "uploader_id": "UCtest123"}'
+        exit 0
+    fi
+done
+mkdir -p "` + outputDir + `"
+echo data > "` + outputDir + `/Test Video.mp4"
+echo "` + outputDir + `/Test Video.mp4"
+`
+	if err := os.WriteFile(mockPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []string
+	record := func(name string, err error) PostProcessor {
+		return PostProcessorFunc(func(ctx context.Context, result *DownloadResult) error {
+			calls = append(calls, name+":"+filepath.Base(result.VideoPath))
+			if result.Metadata == nil || result.Metadata.ID != "test1234567" {
+				t.Errorf("%s got metadata %+v", name, result.Metadata)
+			}
+			return err
+		})
+	}
+	errHook := errors.New("hook failed")
+	d := &Downloader{YtdlpPath: mockPath, PostProcessors: []PostProcessor{record("first", nil), record("second", errHook), record("third", nil)}}
+
+	result, err := d.Download(context.Background(), "test1234567", &DownloadOptions{OutputDir: outputDir})
+	if !errors.Is(err, errHook) {
+		t.Fatalf("Download() error = %v, want the hook's error", err)
+	}
+	if result == nil || result.VideoID != "test1234567" {
+		t.Fatalf("result = %+v", result)
+	}
+	if strings.Join(calls, ",") != "first:Test Video.mp4,second:Test Video.mp4" {
+		t.Errorf("calls = %v", calls)
+	}
+}
@@ -0,0 +1,206 @@
+package youtube
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	_ "time/tzdata"
+	"ytsync/errcode"
+	"ytsync/storage"
+)
+
+// DefaultDailyQuota is the default daily Data API quota of a Google Cloud project.
+const DefaultDailyQuota = 10000
+
+// QuotaCosts is the Data API cost in quota units of each method this
+// package uses, from https://developers.google.com/youtube/v3/determine_quota_cost.
+var QuotaCosts = map[string]int{
+	"activities.list":     1,
+	"captions.list":       50,
+	"captions.download":   200,
+	"channels.list":       1,
+	"commentThreads.list": 1,
+	"playlistItems.list":  1,
+	"playlists.list":      1,
+	"search.list":         100,
+	"subscriptions.list":  1,
+	"videos.list":         1,
+}
+
+// QuotaCost returns the cost of method in quota units. Methods missing from
+// QuotaCosts are assumed to cost 1 unit, the cost of most read requests.
+func QuotaCost(method string) int {
+	if cost, ok := QuotaCosts[method]; ok {
+		return cost
+	}
+	return 1
+}
+
+// ErrQuotaInsufficient is returned by QuotaTracker.PreflightCheck when a
+// request would dip into the reserve or exceed the daily quota.
+var ErrQuotaInsufficient = errcode.New(errcode.QuotaExceeded, "insufficient api quota")
+
+// pacific is the time zone in which the Data API quota resets. The tz
+// database is embedded so the reset time is correct on hosts without one.
+var pacific, _ = time.LoadLocation("America/Los_Angeles")
+
+// QuotaDay returns the quota day containing t, as YYYY-MM-DD in Pacific time.
+func QuotaDay(t time.Time) string {
+	return t.In(pacific).Format("2006-01-02")
+}
+
+// NextQuotaReset returns the first quota reset after t: the next midnight
+// Pacific time.
+func NextQuotaReset(t time.Time) time.Time {
+	p := t.In(pacific)
+	return time.Date(p.Year(), p.Month(), p.Day()+1, 0, 0, 0, 0, pacific)
+}
+
+// QuotaKey returns the identifier under which usage for apiKey is stored.
+// The key itself is never persisted.
+func QuotaKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "api-" + hex.EncodeToString(sum[:8])
+}
+
+// QuotaTracker accounts for Data API quota usage against a daily limit.
+// With a store, usage is persisted and shared with every process using the
+// same store and key, so restarts and parallel syncs do not lose track of
+// what has been spent. Without one, usage is tracked in memory.
+type QuotaTracker struct {
+	store   storage.QuotaStore
+	key     string
+	limit   int
+	reserve int
+
+	mu   sync.Mutex
+	day  string
+	used map[string]int // in-memory usage by method, when store is nil
+	now  func() time.Time
+}
+
+// NewQuotaTracker creates a tracker for the project identified by key (see
+// QuotaKey) with a daily limit and a reserve of units that PreflightCheck
+// refuses to spend. A limit of zero uses DefaultDailyQuota. store may be nil.
+func NewQuotaTracker(store storage.QuotaStore, key string, limit, reserve int) *QuotaTracker {
+	if limit <= 0 {
+		limit = DefaultDailyQuota
+	}
+	return &QuotaTracker{
+		store:   store,
+		key:     key,
+		limit:   limit,
+		reserve: reserve,
+		now:     time.Now,
+	}
+}
+
+// Limit returns the daily quota limit.
+func (q *QuotaTracker) Limit() int {
+	return q.limit
+}
+
+// Record spends the cost of one call to method.
+func (q *QuotaTracker) Record(ctx context.Context, method string) (remaining int, err error) {
+	return q.Spend(ctx, method, QuotaCost(method))
+}
+
+// Spend records units spent on method and returns the quota remaining today.
+func (q *QuotaTracker) Spend(ctx context.Context, method string, units int) (remaining int, err error) {
+	day := QuotaDay(q.now())
+	if q.store != nil {
+		usage, err := q.store.AddQuotaUsage(ctx, q.key, day, method, units)
+		if err != nil {
+			return 0, fmt.Errorf("record quota usage: %w", err)
+		}
+		return q.limit - usage.Used, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rollover(day)
+	q.used[method] += units
+	return q.limit - q.sumLocked(), nil
+}
+
+// Usage returns today's usage.
+func (q *QuotaTracker) Usage(ctx context.Context) (*storage.QuotaUsage, error) {
+	day := QuotaDay(q.now())
+	if q.store != nil {
+		usage, err := q.store.GetQuotaUsage(ctx, q.key, day)
+		if errors.Is(err, storage.ErrNotFound) {
+			return &storage.QuotaUsage{Key: q.key, Day: day}, nil
+		}
+		return usage, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rollover(day)
+	usage := &storage.QuotaUsage{Key: q.key, Day: day, ByMethod: make(map[string]int, len(q.used))}
+	for method, units := range q.used {
+		usage.ByMethod[method] = units
+		usage.Used += units
+	}
+	return usage, nil
+}
+
+// Remaining returns the quota left today, including the reserve.
+func (q *QuotaTracker) Remaining(ctx context.Context) (int, error) {
+	usage, err := q.Usage(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return q.limit - usage.Used, nil
+}
+
+// Exhausted reports whether today's quota is used up or has fallen below
+// the reserve. Errors reading the store are logged and treated as not exhausted,
+// leaving the API itself to reject requests over quota.
+func (q *QuotaTracker) Exhausted(ctx context.Context) bool {
+	remaining, err := q.Remaining(ctx)
+	if err != nil {
+		log.Printf("youtube: read quota usage: %v", err)
+		return false
+	}
+	return remaining <= 0 || remaining < q.reserve
+}
+
+// PreflightCheck returns ErrQuotaInsufficient if spending cost units now
+// would leave less than the reserve for the rest of the quota day.
+func (q *QuotaTracker) PreflightCheck(ctx context.Context, cost int) error {
+	remaining, err := q.Remaining(ctx)
+	if err != nil {
+		return err
+	}
+	if remaining-cost < q.reserve {
+		return fmt.Errorf("%w: need %d units, %d remaining with %d reserved, resets at %s",
+			ErrQuotaInsufficient, cost, remaining, q.reserve, NextQuotaReset(q.now()).Format(time.RFC3339))
+	}
+	return nil
+}
+
+// rollover discards in-memory usage from an earlier quota day. Callers must
+// hold q.mu.
+func (q *QuotaTracker) rollover(day string) {
+	if q.day != day || q.used == nil {
+		if q.day != "" && q.day != day {
+			log.Printf("youtube: quota reset (new day %s)", day)
+		}
+		q.day = day
+		q.used = make(map[string]int)
+	}
+}
+
+func (q *QuotaTracker) sumLocked() int {
+	total := 0
+	for _, units := range q.used {
+		total += units
+	}
+	return total
+}
@@ -0,0 +1,106 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"ytsync/errcode"
+	"ytsync/storage"
+)
+
+func TestQuotaDay(t *testing.T) {
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"before midnight PDT", time.Date(2024, 6, 2, 6, 59, 0, 0, time.UTC), "2024-06-01"},
+		{"after midnight PDT", time.Date(2024, 6, 2, 7, 0, 0, 0, time.UTC), "2024-06-02"},
+		{"before midnight PST", time.Date(2024, 12, 2, 7, 59, 0, 0, time.UTC), "2024-12-01"},
+		{"after midnight PST", time.Date(2024, 12, 2, 8, 0, 0, 0, time.UTC), "2024-12-02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuotaDay(tt.t); got != tt.want {
+				t.Errorf("QuotaDay(%v) = %q, want %q", tt.t, got, tt.want)
+			}
+		})
+	}
+
+	// The reset on the day daylight saving ends is 25 hours after the previous one
+	reset := NextQuotaReset(time.Date(2024, 11, 3, 12, 0, 0, 0, time.UTC))
+	if want := time.Date(2024, 11, 4, 8, 0, 0, 0, time.UTC); !reset.Equal(want) {
+		t.Errorf("NextQuotaReset() = %v, want %v", reset.UTC(), want)
+	}
+}
+
+func TestQuotaTracker_SharedStore(t *testing.T) {
+	store, err := storage.NewJSONStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("NewJSONStore() error = %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	// Two trackers for the same key, as in two processes sharing a store
+	key := QuotaKey("secret-key")
+	a := NewQuotaTracker(store, key, 1000, 100)
+	b := NewQuotaTracker(store, key, 1000, 100)
+
+	if _, err := a.Record(ctx, "search.list"); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	remaining, err := b.Spend(ctx, "playlistItems.list", 750)
+	if err != nil {
+		t.Fatalf("Spend() error = %v", err)
+	}
+	if remaining != 150 {
+		t.Errorf("Spend() remaining = %d, want 150", remaining)
+	}
+
+	usage, err := a.Usage(ctx)
+	if err != nil {
+		t.Fatalf("Usage() error = %v", err)
+	}
+	if usage.Used != 850 || usage.ByMethod["search.list"] != 100 {
+		t.Errorf("Usage() = %+v, want 850 used with 100 on search.list", usage)
+	}
+
+	// 150 remaining with 100 reserved: a search no longer fits
+	if err := a.PreflightCheck(ctx, QuotaCost("playlistItems.list")); err != nil {
+		t.Errorf("PreflightCheck(1) error = %v", err)
+	}
+	err = a.PreflightCheck(ctx, QuotaCost("search.list"))
+	if !errors.Is(err, ErrQuotaInsufficient) || !errcode.Is(err, errcode.QuotaExceeded) {
+		t.Errorf("PreflightCheck(100) error = %v, want ErrQuotaInsufficient", err)
+	}
+	if a.Exhausted(ctx) {
+		t.Error("Exhausted() = true with 150 remaining and 100 reserved")
+	}
+}
+
+func TestQuotaTracker_InMemoryReset(t *testing.T) {
+	now := time.Date(2024, 6, 1, 23, 59, 0, 0, pacific)
+	q := NewQuotaTracker(nil, "k", 0, 0)
+	q.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	q.Spend(ctx, "search.list", DefaultDailyQuota)
+	if !q.Exhausted(ctx) {
+		t.Fatal("Exhausted() = false after spending the daily quota")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if remaining, _ := q.Remaining(ctx); remaining != DefaultDailyQuota {
+		t.Errorf("Remaining() after midnight Pacific = %d, want %d", remaining, DefaultDailyQuota)
+	}
+}
+
+func TestQuotaKey(t *testing.T) {
+	key := QuotaKey("AIzaSecret")
+	if key == "" || key == "AIzaSecret" || key != QuotaKey("AIzaSecret") || key == QuotaKey("other") {
+		t.Errorf("QuotaKey() = %q, want a stable digest distinct from the key", key)
+	}
+}
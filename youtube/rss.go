@@ -9,7 +9,10 @@ import (
 	"net/http"
 	"regexp"
 	"time"
+	ythttp "ytsync/http"
 	"ytsync/retry"
+	"ytsync/storage"
+	"ytsync/tags"
 )
 
 const (
@@ -21,9 +24,23 @@ const (
 // RSS feeds only return the 15 most recent videos, so this is best
 // suited for incremental sync after an initial full sync.
 type RSSLister struct {
-	client      *http.Client
+	client *http.Client
+	// Client, if set, sends feed and channel page requests through the
+	// resilient ythttp client, so they share its rate limiting, circuit
+	// breaker, retries, User-Agent, and tracing. RetryConfig and RateLimiter
+	// are not used in that case; configure the client instead.
+	Client      *ythttp.Client
 	RetryConfig *retry.Config
-	resolver    *ChannelResolver
+	// RateLimiter, if set, paces feed and channel page requests so RSS
+	// traffic shares a budget with ythttp Clients using the same limiter
+	// (see ythttp.Client.RateLimiter).
+	RateLimiter *ythttp.RateLimiter
+	// Aliases, if set, caches handle and custom URL resolutions (see
+	// ChannelResolver.Aliases).
+	Aliases  storage.ChannelAliasStore
+	resolver *ChannelResolver
+	// feedURLTemplate overrides rssFeedURLTemplate in tests.
+	feedURLTemplate string
 }
 
 // NewRSSLister creates a new RSS-based video lister.
@@ -42,6 +59,19 @@ func NewRSSListerWithClient(client *http.Client) *RSSLister {
 	return &RSSLister{client: client}
 }
 
+// NewResilientRSSLister creates an RSS lister that sends all requests,
+// including handle resolution, through the given ythttp client. If client
+// is nil, a client with default settings is created.
+func NewResilientRSSLister(client *ythttp.Client) *RSSLister {
+	if client == nil {
+		client = ythttp.New(ythttp.DefaultConfig())
+	}
+	return &RSSLister{
+		Client:   client,
+		resolver: &ChannelResolver{Client: client},
+	}
+}
+
 // ListVideos fetches videos from the YouTube RSS feed.
 // Supports channel IDs, channel URLs, and @handles (handles are resolved automatically).
 func (r *RSSLister) ListVideos(ctx context.Context, channelURL string, opts *ListOptions) ([]VideoInfo, error) {
@@ -49,6 +79,37 @@ func (r *RSSLister) ListVideos(ctx context.Context, channelURL string, opts *Lis
 	if err != nil {
 		return nil, &ListerError{Source: "rss", Channel: channelURL, Err: err}
 	}
+	ctx = tags.Default(ctx, tags.Channel, channelID)
+
+	videos, err := r.fetchFeed(ctx, channelURL, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply filters
+	if opts != nil {
+		videos = filterVideos(videos, opts)
+	}
+
+	return videos, nil
+}
+
+// fetchFeed downloads and parses a channel's RSS feed.
+func (r *RSSLister) fetchFeed(ctx context.Context, channelURL, channelID string) ([]VideoInfo, error) {
+	template := r.feedURLTemplate
+	if template == "" {
+		template = rssFeedURLTemplate
+	}
+	feedURL := fmt.Sprintf(template, channelID)
+
+	// The resilient client applies its own retry policy
+	if r.Client != nil {
+		resp, err := r.Client.Get(ctx, feedURL)
+		if err != nil {
+			return nil, &ListerError{Source: "rss", Channel: channelURL, Err: fromHTTPClientError(ctx, err)}
+		}
+		return parseFeedVideos(resp.Body, channelURL, channelID)
+	}
 
 	var videos []VideoInfo
 	cfg := r.RetryConfig
@@ -57,8 +118,10 @@ func (r *RSSLister) ListVideos(ctx context.Context, channelURL string, opts *Lis
 		cfg = &defaultCfg
 	}
 
-	err = retry.Do(ctx, *cfg, rssErrorClassifier, func(ctx context.Context) error {
-		feedURL := fmt.Sprintf(rssFeedURLTemplate, channelID)
+	err := retry.Do(ctx, *cfg, rssErrorClassifier, func(ctx context.Context) error {
+		if err := r.RateLimiter.Wait(ctx, feedURL); err != nil {
+			return &ListerError{Source: "rss", Channel: channelURL, Err: err}
+		}
 
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
 		if err != nil {
@@ -78,7 +141,7 @@ func (r *RSSLister) ListVideos(ctx context.Context, channelURL string, opts *Lis
 			return &ListerError{Source: "rss", Channel: channelURL, Err: ErrChannelNotFound}
 		}
 		if resp.StatusCode == http.StatusTooManyRequests {
-			return &ListerError{Source: "rss", Channel: channelURL, Err: ErrRateLimited}
+			return &ListerError{Source: "rss", Channel: channelURL, Err: rateLimited(r.RateLimiter, feedURL, resp)}
 		}
 		if resp.StatusCode != http.StatusOK {
 			return &ListerError{Source: "rss", Channel: channelURL,
@@ -90,25 +153,23 @@ func (r *RSSLister) ListVideos(ctx context.Context, channelURL string, opts *Lis
 			return &ListerError{Source: "rss", Channel: channelURL, Err: err}
 		}
 
-		feed, err := parseAtomFeed(body)
-		if err != nil {
-			return &ListerError{Source: "rss", Channel: channelURL, Err: err}
-		}
-
-		videos = feedToVideoInfo(feed, channelID)
-		return nil
+		videos, err = parseFeedVideos(body, channelURL, channelID)
+		return err
 	})
 
 	if err != nil {
 		return nil, err
 	}
+	return videos, nil
+}
 
-	// Apply filters
-	if opts != nil {
-		videos = filterVideos(videos, opts)
+// parseFeedVideos parses an Atom feed body into videos.
+func parseFeedVideos(body []byte, channelURL, channelID string) ([]VideoInfo, error) {
+	feed, err := parseAtomFeed(body)
+	if err != nil {
+		return nil, &ListerError{Source: "rss", Channel: channelURL, Err: err}
 	}
-
-	return videos, nil
+	return feedToVideoInfo(feed, channelID), nil
 }
 
 // SupportsFullHistory returns false - RSS only provides the 15 most recent videos.
@@ -131,6 +192,9 @@ type RSSIncrementalResult struct {
 	TotalInFeed int
 	// NewVideosCount is the number of videos newer than the last sync time.
 	NewVideosCount int
+	// NewInFeed is the number of videos in the feed newer than the last sync
+	// time, before the ListOptions filters are applied.
+	NewInFeed int
 }
 
 // ListVideosIncremental performs an incremental sync using the RSS feed.
@@ -145,56 +209,9 @@ func (r *RSSLister) ListVideosIncremental(ctx context.Context, channelURL string
 	if err != nil {
 		return nil, &ListerError{Source: "rss", Channel: channelURL, Err: err}
 	}
+	ctx = tags.Default(ctx, tags.Channel, channelID)
 
-	var videos []VideoInfo
-	cfg := r.RetryConfig
-	if cfg == nil {
-		defaultCfg := retry.DefaultConfig()
-		cfg = &defaultCfg
-	}
-
-	err = retry.Do(ctx, *cfg, rssErrorClassifier, func(ctx context.Context) error {
-		feedURL := fmt.Sprintf(rssFeedURLTemplate, channelID)
-
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
-		if err != nil {
-			return &ListerError{Source: "rss", Channel: channelURL, Err: err}
-		}
-
-		resp, err := r.client.Do(req)
-		if err != nil {
-			if ctx.Err() != nil {
-				return &ListerError{Source: "rss", Channel: channelURL, Err: ErrNetworkTimeout}
-			}
-			return &ListerError{Source: "rss", Channel: channelURL, Err: err}
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusNotFound {
-			return &ListerError{Source: "rss", Channel: channelURL, Err: ErrChannelNotFound}
-		}
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return &ListerError{Source: "rss", Channel: channelURL, Err: ErrRateLimited}
-		}
-		if resp.StatusCode != http.StatusOK {
-			return &ListerError{Source: "rss", Channel: channelURL,
-				Err: fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)}
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return &ListerError{Source: "rss", Channel: channelURL, Err: err}
-		}
-
-		feed, err := parseAtomFeed(body)
-		if err != nil {
-			return &ListerError{Source: "rss", Channel: channelURL, Err: err}
-		}
-
-		videos = feedToVideoInfo(feed, channelID)
-		return nil
-	})
-
+	videos, err := r.fetchFeed(ctx, channelURL, channelID)
 	if err != nil {
 		return nil, err
 	}
@@ -240,6 +257,8 @@ func (r *RSSLister) ListVideosIncremental(ctx context.Context, channelURL string
 		newVideos = videos
 	}
 
+	newInFeed := len(newVideos)
+
 	// Apply additional filters from opts
 	if opts != nil {
 		newVideos = filterVideos(newVideos, opts)
@@ -252,6 +271,7 @@ func (r *RSSLister) ListVideosIncremental(ctx context.Context, channelURL string
 		GapDetected:     gapDetected,
 		TotalInFeed:     totalInFeed,
 		NewVideosCount:  len(newVideos),
+		NewInFeed:       newInFeed,
 	}, nil
 }
 
@@ -341,16 +361,8 @@ func filterVideos(videos []VideoInfo, opts *ListOptions) []VideoInfo {
 		return videos
 	}
 
-	// Filter by PublishedAfter
-	if !opts.PublishedAfter.IsZero() {
-		filtered := make([]VideoInfo, 0, len(videos))
-		for _, v := range videos {
-			if v.Published.After(opts.PublishedAfter) {
-				filtered = append(filtered, v)
-			}
-		}
-		videos = filtered
-	}
+	// Filter by date range and content filters
+	videos = matchingVideos(videos, opts)
 
 	// Apply MaxResults
 	if opts.MaxResults > 0 && len(videos) > opts.MaxResults {
@@ -360,6 +372,20 @@ func filterVideos(videos []VideoInfo, opts *ListOptions) []VideoInfo {
 	return videos
 }
 
+// matchingVideos returns the videos that pass opts' filters, ignoring MaxResults.
+func matchingVideos(videos []VideoInfo, opts *ListOptions) []VideoInfo {
+	if opts == nil {
+		return videos
+	}
+	filtered := make([]VideoInfo, 0, len(videos))
+	for _, v := range videos {
+		if opts.Matches(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
 // channelIDRegex matches YouTube channel IDs (UC followed by 22 base64 chars).
 var channelIDRegex = regexp.MustCompile(`UC[a-zA-Z0-9_-]{22}`)
 
@@ -372,8 +398,21 @@ func (r *RSSLister) resolveChannelID(ctx context.Context, input string) (string,
 	}
 
 	// Need to resolve handle or custom URL
-	if r.resolver != nil {
-		return r.resolver.ResolveChannelID(ctx, input)
+	if r.resolver != nil || r.Client != nil {
+		var resolver ChannelResolver
+		if r.resolver != nil {
+			resolver = *r.resolver
+		}
+		if resolver.Client == nil {
+			resolver.Client = r.Client
+		}
+		if resolver.RateLimiter == nil {
+			resolver.RateLimiter = r.RateLimiter
+		}
+		if resolver.Aliases == nil {
+			resolver.Aliases = r.Aliases
+		}
+		return resolver.ResolveChannelID(ctx, input)
 	}
 
 	// Fallback: can't resolve without resolver
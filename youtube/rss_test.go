@@ -2,11 +2,16 @@ package youtube
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
+	ythttp "ytsync/http"
+	"ytsync/retry"
 )
 
 // MockHTTPClient is a mock HTTP client for testing.
@@ -229,6 +234,21 @@ func TestFilterVideos(t *testing.T) {
 			wantCount: 1,
 			wantIDs:   []string{"video2"},
 		},
+		{
+			name:      "published before",
+			opts:      &ListOptions{PublishedBefore: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)},
+			wantCount: 2,
+			wantIDs:   []string{"video1", "video2"},
+		},
+		{
+			name: "date range",
+			opts: &ListOptions{
+				PublishedAfter:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				PublishedBefore: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC),
+			},
+			wantCount: 1,
+			wantIDs:   []string{"video2"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -247,6 +267,88 @@ func TestFilterVideos(t *testing.T) {
 	}
 }
 
+func TestListOptions_BeforeRange(t *testing.T) {
+	after := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	opts := &ListOptions{PublishedAfter: after}
+
+	if !opts.BeforeRange(VideoInfo{Published: after.Add(-time.Hour)}) {
+		t.Error("BeforeRange() = false for older video, want true")
+	}
+	if opts.BeforeRange(VideoInfo{Published: after.Add(time.Hour)}) {
+		t.Error("BeforeRange() = true for newer video, want false")
+	}
+	if opts.BeforeRange(VideoInfo{}) {
+		t.Error("BeforeRange() = true for unknown publish time, want false")
+	}
+	popular := &ListOptions{PublishedAfter: after, SortOrder: SortByPopularity}
+	if popular.BeforeRange(VideoInfo{Published: after.Add(-time.Hour)}) {
+		t.Error("BeforeRange() = true for popularity sort, want false")
+	}
+	var nilOpts *ListOptions
+	if nilOpts.BeforeRange(VideoInfo{Published: after}) {
+		t.Error("BeforeRange() = true for nil options, want false")
+	}
+}
+
+func TestFilterVideos_ContentFilters(t *testing.T) {
+	videos := []VideoInfo{
+		{ID: "clip", Title: "Quick tip", Duration: 30 * time.Second, ViewCount: 5000, Type: VideoTypeVideo},
+		{ID: "long", Title: "Full tutorial: Go generics", Duration: 25 * time.Minute, ViewCount: 800, Type: VideoTypeVideo},
+		{ID: "stream", Title: "Live coding session", Duration: 2 * time.Hour, ViewCount: 12000, Type: VideoTypeStream},
+		{ID: "rss", Title: "Tutorial from RSS", ViewCount: 100},
+	}
+
+	tests := []struct {
+		name    string
+		opts    *ListOptions
+		wantIDs []string
+	}{
+		{
+			name:    "min duration keeps unknown durations",
+			opts:    &ListOptions{MinDuration: time.Minute},
+			wantIDs: []string{"long", "stream", "rss"},
+		},
+		{
+			name:    "max duration",
+			opts:    &ListOptions{MaxDuration: time.Hour},
+			wantIDs: []string{"clip", "long", "rss"},
+		},
+		{
+			name:    "title match",
+			opts:    &ListOptions{TitleMatch: regexp.MustCompile(`(?i)tutorial`)},
+			wantIDs: []string{"long", "rss"},
+		},
+		{
+			name:    "min views",
+			opts:    &ListOptions{MinViews: 1000},
+			wantIDs: []string{"clip", "stream"},
+		},
+		{
+			name:    "exclude live",
+			opts:    &ListOptions{ExcludeLive: true},
+			wantIDs: []string{"clip", "long", "rss"},
+		},
+		{
+			name:    "combined with max results",
+			opts:    &ListOptions{MinDuration: time.Minute, ExcludeLive: true, MaxResults: 1},
+			wantIDs: []string{"long"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := filterVideos(videos, tt.opts)
+			var got []string
+			for _, v := range filtered {
+				got = append(got, v.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("filterVideos() = %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+}
+
 func TestRSSListerListVideosIncremental(t *testing.T) {
 	client := newMockHTTPClient(http.StatusOK, SampleAtomFeed)
 	lister := NewRSSListerWithClient(client)
@@ -410,3 +512,154 @@ func TestRSSListerIncrementalInvalidChannel(t *testing.T) {
 		t.Error("ListVideosIncremental() should return error for invalid channel URL")
 	}
 }
+
+func TestResilientRSSLister(t *testing.T) {
+	var gotUA string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		switch r.URL.Query().Get("channel_id") {
+		case "UCmissingmissingmissing1":
+			http.NotFound(w, r)
+		case "UCblockedblockedblocked1":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.Header().Set("Content-Type", "application/atom+xml")
+			w.Write([]byte(SampleAtomFeed))
+		}
+	}))
+	defer server.Close()
+
+	cfg := ythttp.DefaultConfig()
+	cfg.UserAgent = "ytsync-test/1.0"
+	cfg.Retry.MaxRetries = 0
+	lister := NewResilientRSSLister(ythttp.New(cfg))
+	lister.feedURLTemplate = server.URL + "/feeds/videos.xml?channel_id=%s"
+	ctx := context.Background()
+
+	videos, err := lister.ListVideos(ctx, "UCuAXFkgsw1L7xaCfnd5JJOw", nil)
+	if err != nil {
+		t.Fatalf("ListVideos() error = %v", err)
+	}
+	if len(videos) != 2 || videos[0].ID != "dQw4w9WgXcQ" {
+		t.Errorf("ListVideos() = %+v, want 2 videos from sample feed", videos)
+	}
+	if gotUA != "ytsync-test/1.0" {
+		t.Errorf("User-Agent = %q, want the ythttp client's", gotUA)
+	}
+
+	result, err := lister.ListVideosIncremental(ctx, "UCuAXFkgsw1L7xaCfnd5JJOw", time.Time{}, nil)
+	if err != nil {
+		t.Fatalf("ListVideosIncremental() error = %v", err)
+	}
+	if result.TotalInFeed != 2 {
+		t.Errorf("TotalInFeed = %d, want 2", result.TotalInFeed)
+	}
+
+	if _, err := lister.ListVideos(ctx, "UCmissingmissingmissing1", nil); !errors.Is(err, ErrChannelNotFound) {
+		t.Errorf("ListVideos() on 404 error = %v, want ErrChannelNotFound", err)
+	}
+	if _, err := lister.ListVideos(ctx, "UCblockedblockedblocked1", nil); !errors.Is(err, ErrBotDetected) {
+		t.Errorf("ListVideos() on 403 error = %v, want ErrBotDetected", err)
+	}
+}
+
+func TestChannelResolver_ResilientClient(t *testing.T) {
+	var gotAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAccept = r.Header.Get("Accept")
+		switch r.URL.Path {
+		case "/@missing":
+			http.NotFound(w, r)
+			return
+		case "/@huge":
+			w.Write([]byte(strings.Repeat(" ", 2*maxChannelPageSize)))
+			return
+		}
+		w.Write([]byte(`<html><meta itemprop="channelId" content="UCuAXFkgsw1L7xaCfnd5JJOw"></html>`))
+	}))
+	defer server.Close()
+
+	cfg := ythttp.DefaultConfig()
+	cfg.Retry.MaxRetries = 0
+	resolver := &ChannelResolver{Client: ythttp.New(cfg)}
+	ctx := context.Background()
+
+	id, err := resolver.fetchChannelID(ctx, server.URL+"/@handle")
+	if err != nil {
+		t.Fatalf("fetchChannelID() error = %v", err)
+	}
+	if id != "UCuAXFkgsw1L7xaCfnd5JJOw" {
+		t.Errorf("fetchChannelID() = %q", id)
+	}
+	if gotAccept != channelPageHeaders["Accept"] {
+		t.Errorf("Accept header = %q, want browser-like headers", gotAccept)
+	}
+
+	if _, err := resolver.fetchChannelID(ctx, server.URL+"/@missing"); !errors.Is(err, ErrChannelNotFound) {
+		t.Errorf("fetchChannelID() on 404 error = %v, want ErrChannelNotFound", err)
+	}
+	if body, err := resolver.fetchChannelPage(ctx, server.URL+"/@huge"); err != nil || len(body) != maxChannelPageSize {
+		t.Errorf("fetchChannelPage() of a huge page = %d bytes, %v; want %d bytes", len(body), err, maxChannelPageSize)
+	}
+}
+
+func TestChannelResolver_Aliases(t *testing.T) {
+	var requests int
+	client := &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`<meta itemprop="channelId" content="UCuAXFkgsw1L7xaCfnd5JJOw">`)),
+		}, nil
+	}}
+	store := newEnrichTestStore(t)
+	resolver := &ChannelResolver{HTTPClient: client, Aliases: store}
+	ctx := context.Background()
+
+	// The first lookup resolves over the network; later ones, however the
+	// handle is typed, come from the alias table
+	for _, input := range []string{"@Fireship", "https://www.youtube.com/@fireship/videos", "@FIRESHIP"} {
+		id, err := resolver.ResolveChannelID(ctx, input)
+		if err != nil {
+			t.Fatalf("ResolveChannelID(%q) error = %v", input, err)
+		}
+		if id != "UCuAXFkgsw1L7xaCfnd5JJOw" {
+			t.Errorf("ResolveChannelID(%q) = %q", input, id)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("channel page requests = %d, want 1", requests)
+	}
+
+	mapping, err := store.GetChannelAlias(ctx, "@fireship")
+	if err != nil || mapping.YouTubeID != "UCuAXFkgsw1L7xaCfnd5JJOw" {
+		t.Errorf("GetChannelAlias() = %+v, %v, want recorded mapping", mapping, err)
+	}
+}
+
+func TestRSSListerRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	limiterCfg := ythttp.DefaultRateLimiterConfig()
+	limiterCfg.EnableDynamicBackoff = true
+	lister := NewRSSListerWithClient(server.Client())
+	lister.feedURLTemplate = server.URL + "/feeds/videos.xml?channel_id=%s"
+	lister.RetryConfig = &retry.Config{MaxRetries: 0}
+	lister.RateLimiter = ythttp.NewRateLimiter(limiterCfg)
+
+	_, err := lister.ListVideos(context.Background(), "UCuAXFkgsw1L7xaCfnd5JJOw", nil)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("ListVideos() error = %v, want ErrRateLimited", err)
+	}
+	var rateErr *ythttp.RateLimitError
+	if !errors.As(err, &rateErr) || rateErr.ServerRetryAfter != 7*time.Second || rateErr.RetryAfter < 7*time.Second {
+		t.Errorf("RateLimitError = %+v, want a 7s server Retry-After", rateErr)
+	}
+	if state := lister.RateLimiter.GetBackoffState(server.URL); state == nil || state.CurrentBackoff != 7*time.Second {
+		t.Errorf("backoff state = %+v, want the Retry-After recorded", state)
+	}
+}
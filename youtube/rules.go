@@ -0,0 +1,384 @@
+package youtube
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"ytsync/errcode"
+)
+
+// ErrInvalidRules is returned when a rules file cannot be parsed or one of
+// its rules is invalid.
+var ErrInvalidRules = errcode.New(errcode.InvalidInput, "youtube: invalid rules")
+
+// RuleAction is what a matching rule does with a video.
+type RuleAction string
+
+const (
+	// RuleAllow includes the video.
+	RuleAllow RuleAction = "allow"
+	// RuleDeny skips the video.
+	RuleDeny RuleAction = "deny"
+)
+
+// Rule is one entry of a rules file. A rule matches a video when all of
+// its conditions hold; a rule without conditions matches every video.
+// Videos whose duration or publish time is unknown, as in RSS listings, do
+// not match conditions on it.
+type Rule struct {
+	// Name identifies the rule in explanations. Optional.
+	Name string `json:"name,omitempty"`
+	// Action is RuleAllow or RuleDeny.
+	Action RuleAction `json:"action"`
+	// Channels are channel IDs or names, compared case-insensitively. The
+	// video's channel must be one of them.
+	Channels []string `json:"channels,omitempty"`
+	// Title is a regular expression the title must match, e.g.
+	// "(?i)#shorts".
+	Title string `json:"title,omitempty"`
+	// MinDuration and MaxDuration bound the video length, e.g. "90s" or
+	// "1h30m".
+	MinDuration string `json:"min_duration,omitempty"`
+	MaxDuration string `json:"max_duration,omitempty"`
+	// PublishedAfter and PublishedBefore bound the publish time, as a date
+	// like "2024-01-31" (midnight UTC) or an RFC 3339 time.
+	PublishedAfter  string `json:"published_after,omitempty"`
+	PublishedBefore string `json:"published_before,omitempty"`
+}
+
+// label names the rule at index i in explanations.
+func (r *Rule) label(i int) string {
+	if r.Name != "" {
+		return fmt.Sprintf("rule %d %q", i+1, r.Name)
+	}
+	return fmt.Sprintf("rule %d", i+1)
+}
+
+// compiledRule is a Rule with its conditions parsed.
+type compiledRule struct {
+	title                           *regexp.Regexp
+	minDuration, maxDuration        time.Duration
+	publishedAfter, publishedBefore time.Time
+}
+
+// RuleSet is an ordered allow/deny policy deciding which videos syncs and
+// downloads include, so large archives can keep their policy as data. The
+// first rule matching a video decides; videos no rule matches get the
+// default action. Build one with NewRuleSet, ParseRules, or LoadRules. A
+// nil RuleSet allows every video.
+type RuleSet struct {
+	// Default is the action for videos no rule matches.
+	Default RuleAction
+	// Rules are evaluated in order.
+	Rules []Rule
+
+	compiled []compiledRule
+}
+
+// NewRuleSet validates rules and returns a rule set evaluating them in
+// order. An empty def means RuleAllow.
+func NewRuleSet(def RuleAction, rules []Rule) (*RuleSet, error) {
+	if def == "" {
+		def = RuleAllow
+	}
+	if def != RuleAllow && def != RuleDeny {
+		return nil, fmt.Errorf("%w: default action %q is not allow or deny", ErrInvalidRules, def)
+	}
+	s := &RuleSet{Default: def, Rules: rules, compiled: make([]compiledRule, len(rules))}
+	for i := range rules {
+		if err := compileRule(&rules[i], &s.compiled[i]); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRules, rules[i].label(i), err)
+		}
+	}
+	return s, nil
+}
+
+// compileRule validates r and parses its conditions into c.
+func compileRule(r *Rule, c *compiledRule) error {
+	if r.Action != RuleAllow && r.Action != RuleDeny {
+		return fmt.Errorf("action %q is not allow or deny", r.Action)
+	}
+	var err error
+	if r.Title != "" {
+		if c.title, err = regexp.Compile(r.Title); err != nil {
+			return fmt.Errorf("title: %v", err)
+		}
+	}
+	if c.minDuration, err = parseRuleDuration(r.MinDuration); err != nil {
+		return fmt.Errorf("min_duration: %v", err)
+	}
+	if c.maxDuration, err = parseRuleDuration(r.MaxDuration); err != nil {
+		return fmt.Errorf("max_duration: %v", err)
+	}
+	if c.publishedAfter, err = parseRuleTime(r.PublishedAfter); err != nil {
+		return fmt.Errorf("published_after: %v", err)
+	}
+	if c.publishedBefore, err = parseRuleTime(r.PublishedBefore); err != nil {
+		return fmt.Errorf("published_before: %v", err)
+	}
+	return nil
+}
+
+func parseRuleDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func parseRuleTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// rulesFile is the format of a rules file.
+type rulesFile struct {
+	Default RuleAction `json:"default,omitempty"`
+	Rules   []Rule     `json:"rules"`
+}
+
+// ParseRules parses a rules file in JSON or YAML:
+//
+//	default: allow
+//	rules:
+//	  - name: no-shorts
+//	    action: deny
+//	    max_duration: 60s
+//	  - action: deny
+//	    title: "(?i)live ?stream"
+//	    published_before: 2020-01-01
+//
+// YAML support covers block mappings and sequences, flow sequences such as
+// [a, b], quoted and plain scalars, and comments, which is all a rules file
+// needs. Unknown fields are an error, so misspelled conditions are not
+// silently ignored.
+func ParseRules(data []byte) (*RuleSet, error) {
+	if trimmed := strings.TrimSpace(string(data)); !strings.HasPrefix(trimmed, "{") {
+		doc, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+		}
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	var file rulesFile
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+	return NewRuleSet(file.Default, file.Rules)
+}
+
+// LoadRules reads and parses the rules file at path.
+func LoadRules(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// StoreRulesPath returns the rules file kept next to the store at
+// storePath: for "ytsync.json", the first of "ytsync.rules.json",
+// "ytsync.rules.yaml", and "ytsync.rules.yml" that exists. It returns ""
+// if there is none.
+func StoreRulesPath(storePath string) string {
+	base := strings.TrimSuffix(storePath, filepath.Ext(storePath))
+	for _, ext := range []string{".rules.json", ".rules.yaml", ".rules.yml"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext
+		}
+	}
+	return ""
+}
+
+// LoadStoreRules loads the rules file kept next to the store at storePath
+// (see StoreRulesPath). It returns nil, which allows every video, if the
+// store has no rules file.
+func LoadStoreRules(storePath string) (*RuleSet, error) {
+	path := StoreRulesPath(storePath)
+	if path == "" {
+		return nil, nil
+	}
+	rules, err := LoadRules(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return rules, err
+}
+
+// RuleCheck is the outcome of one rule for a video.
+type RuleCheck struct {
+	// Rule is the rule checked.
+	Rule *Rule
+	// Index is the rule's position in the rule set, from zero.
+	Index int
+	// Matched reports whether all of the rule's conditions held.
+	Matched bool
+	// Reasons are the conditions that held if the rule matched, or the
+	// first that did not.
+	Reasons []string
+}
+
+// String describes the check, e.g. `rule 1 "no-shorts" (deny): no match:
+// duration 3m33s is over 1m0s`.
+func (c RuleCheck) String() string {
+	result := "no match"
+	if c.Matched {
+		result = "matched"
+	}
+	s := fmt.Sprintf("%s (%s): %s", c.Rule.label(c.Index), c.Rule.Action, result)
+	if len(c.Reasons) > 0 {
+		s += ": " + strings.Join(c.Reasons, "; ")
+	}
+	return s
+}
+
+// RuleDecision explains whether a rule set includes a video.
+type RuleDecision struct {
+	// Include reports whether the video is included.
+	Include bool
+	// Rule is the rule that decided, or nil if the default action did.
+	Rule *Rule
+	// Checks are the rules checked in order, ending with the deciding one.
+	Checks []RuleCheck
+}
+
+// String summarizes the decision, e.g. `skipped by rule 1 "no-shorts"`.
+func (d *RuleDecision) String() string {
+	verdict := "skipped"
+	if d.Include {
+		verdict = "included"
+	}
+	if d.Rule == nil {
+		return verdict + " by default"
+	}
+	last := d.Checks[len(d.Checks)-1]
+	return verdict + " by " + last.Rule.label(last.Index)
+}
+
+// Allows reports whether the rule set includes v. A nil rule set includes
+// every video.
+func (s *RuleSet) Allows(v VideoInfo) bool {
+	if s == nil {
+		return true
+	}
+	for i := range s.compiled {
+		if ok, _ := s.compiled[i].match(&s.Rules[i], v, false); ok {
+			return s.Rules[i].Action == RuleAllow
+		}
+	}
+	return s.Default != RuleDeny
+}
+
+// Evaluate decides whether the rule set includes v and explains why.
+func (s *RuleSet) Evaluate(v VideoInfo) *RuleDecision {
+	if s == nil {
+		return &RuleDecision{Include: true}
+	}
+	d := &RuleDecision{}
+	for i := range s.compiled {
+		r := &s.Rules[i]
+		ok, reasons := s.compiled[i].match(r, v, true)
+		d.Checks = append(d.Checks, RuleCheck{Rule: r, Index: i, Matched: ok, Reasons: reasons})
+		if ok {
+			d.Include = r.Action == RuleAllow
+			d.Rule = r
+			return d
+		}
+	}
+	d.Include = s.Default != RuleDeny
+	return d
+}
+
+// match reports whether v meets every condition of r. With explain set it
+// also returns the conditions that held, or the one that did not.
+func (c *compiledRule) match(r *Rule, v VideoInfo, explain bool) (bool, []string) {
+	var held []string
+	fail := func(format string, args ...interface{}) (bool, []string) {
+		if !explain {
+			return false, nil
+		}
+		return false, []string{fmt.Sprintf(format, args...)}
+	}
+	note := func(format string, args ...interface{}) {
+		if explain {
+			held = append(held, fmt.Sprintf(format, args...))
+		}
+	}
+
+	if len(r.Channels) > 0 {
+		found := false
+		for _, ch := range r.Channels {
+			if strings.EqualFold(ch, v.ChannelID) || (v.ChannelName != "" && strings.EqualFold(ch, v.ChannelName)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fail("channel %s is not listed", channelLabel(v))
+		}
+		note("channel %s is listed", channelLabel(v))
+	}
+	if c.title != nil {
+		if !c.title.MatchString(v.Title) {
+			return fail("title does not match %q", r.Title)
+		}
+		note("title matches %q", r.Title)
+	}
+	if c.minDuration > 0 || c.maxDuration > 0 {
+		switch {
+		case v.Duration <= 0:
+			return fail("duration is unknown")
+		case c.minDuration > 0 && v.Duration < c.minDuration:
+			return fail("duration %s is under %s", v.Duration, c.minDuration)
+		case c.maxDuration > 0 && v.Duration > c.maxDuration:
+			return fail("duration %s is over %s", v.Duration, c.maxDuration)
+		}
+		note("duration %s is in range", v.Duration)
+	}
+	if !c.publishedAfter.IsZero() || !c.publishedBefore.IsZero() {
+		published := v.Published.UTC().Format(time.RFC3339)
+		switch {
+		case v.Published.IsZero():
+			return fail("publish time is unknown")
+		case !c.publishedAfter.IsZero() && !v.Published.After(c.publishedAfter):
+			return fail("published %s, not after %s", published, r.PublishedAfter)
+		case !c.publishedBefore.IsZero() && !v.Published.Before(c.publishedBefore):
+			return fail("published %s, not before %s", published, r.PublishedBefore)
+		}
+		note("published %s is in range", published)
+	}
+	return true, held
+}
+
+// channelLabel names v's channel in explanations.
+func channelLabel(v VideoInfo) string {
+	if v.ChannelName != "" && v.ChannelID != "" {
+		return fmt.Sprintf("%s (%s)", v.ChannelName, v.ChannelID)
+	}
+	if v.ChannelID != "" {
+		return v.ChannelID
+	}
+	if v.ChannelName != "" {
+		return v.ChannelName
+	}
+	return "(unknown)"
+}
@@ -0,0 +1,258 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRulesYAML = `# Archive policy
+default: allow
+rules:
+  - name: no-shorts
+    action: deny
+    max_duration: 60s   # shorts are at most a minute
+  - name: old-podcasts
+    action: deny
+    channels: [UCpodcast, "Talk Show"]
+    title: "(?i)episode #\\d+"
+    published_before: 2020-01-01
+  - action: allow
+    channels:
+    - UCkeep
+`
+
+const testRulesJSON = `{
+  "default": "allow",
+  "rules": [
+    {"name": "no-shorts", "action": "deny", "max_duration": "60s"},
+    {"name": "old-podcasts", "action": "deny", "channels": ["UCpodcast", "Talk Show"],
+     "title": "(?i)episode #\\d+", "published_before": "2020-01-01"},
+    {"action": "allow", "channels": ["UCkeep"]}
+  ]
+}`
+
+func TestParseRules(t *testing.T) {
+	fromYAML, err := ParseRules([]byte(testRulesYAML))
+	if err != nil {
+		t.Fatalf("ParseRules(YAML) error = %v", err)
+	}
+	fromJSON, err := ParseRules([]byte(testRulesJSON))
+	if err != nil {
+		t.Fatalf("ParseRules(JSON) error = %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML.Rules, fromJSON.Rules) || fromYAML.Default != RuleAllow {
+		t.Errorf("YAML rules = %+v\nJSON rules = %+v", fromYAML.Rules, fromJSON.Rules)
+	}
+	if got := fromYAML.Rules[1].Title; got != `(?i)episode #\d+` {
+		t.Errorf("title = %q", got)
+	}
+
+	for name, data := range map[string]string{
+		"unknown field":   "rules:\n  - action: deny\n    max_length: 60s\n",
+		"bad action":      "rules:\n  - action: block\n",
+		"bad default":     "default: maybe\n",
+		"bad regexp":      "rules:\n  - action: deny\n    title: \"(\"\n",
+		"bad duration":    "rules:\n  - action: deny\n    max_duration: 60\n",
+		"bad date":        "rules:\n  - action: deny\n    published_after: yesterday\n",
+		"bad indentation": "rules:\n  - action: deny\n   title: x\n",
+		"tab":             "rules:\n\t- action: deny\n",
+		"bad JSON":        `{"rules": [}`,
+	} {
+		if _, err := ParseRules([]byte(data)); !errors.Is(err, ErrInvalidRules) {
+			t.Errorf("%s: error = %v, want ErrInvalidRules", name, err)
+		}
+	}
+}
+
+func TestRuleSetEvaluate(t *testing.T) {
+	rules, err := ParseRules([]byte(testRulesYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	published := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		video   VideoInfo
+		include bool
+		summary string
+		reasons []string
+	}{
+		{
+			name:    "short",
+			video:   VideoInfo{ID: "a", ChannelID: "UCkeep", Duration: 45 * time.Second},
+			summary: `skipped by rule 1 "no-shorts"`,
+			reasons: []string{"duration 45s is in range"},
+		},
+		{
+			name:    "old episode",
+			video:   VideoInfo{ID: "b", ChannelName: "talk show", Title: "Episode #12", Duration: time.Hour, Published: published},
+			summary: `skipped by rule 2 "old-podcasts"`,
+			reasons: []string{"channel talk show is listed", `title matches "(?i)episode #\\d+"`, "published 2019-06-01T00:00:00Z is in range"},
+		},
+		{
+			name:    "new episode",
+			video:   VideoInfo{ID: "c", ChannelID: "UCpodcast", Title: "Episode #300", Duration: time.Hour, Published: published.AddDate(2, 0, 0)},
+			include: true,
+			summary: "included by default",
+		},
+		{
+			name:    "unknown duration",
+			video:   VideoInfo{ID: "d", ChannelID: "UCkeep"},
+			include: true,
+			summary: "included by rule 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := rules.Evaluate(tt.video)
+			if d.Include != tt.include || d.String() != tt.summary {
+				t.Errorf("Evaluate() = %v (%s), want %v (%s)", d.Include, d, tt.include, tt.summary)
+			}
+			if rules.Allows(tt.video) != tt.include {
+				t.Errorf("Allows() = %v, want %v", !tt.include, tt.include)
+			}
+			if tt.reasons != nil {
+				last := d.Checks[len(d.Checks)-1]
+				if !reflect.DeepEqual(last.Reasons, tt.reasons) {
+					t.Errorf("reasons = %q, want %q", last.Reasons, tt.reasons)
+				}
+			}
+		})
+	}
+
+	d := rules.Evaluate(tests[2].video)
+	if len(d.Checks) != 3 {
+		t.Fatalf("got %d checks, want 3", len(d.Checks))
+	}
+	if got, want := d.Checks[1].String(), `rule 2 "old-podcasts" (deny): no match: published 2021-06-01T00:00:00Z, not before 2020-01-01`; got != want {
+		t.Errorf("check = %q, want %q", got, want)
+	}
+	if got, want := d.Checks[2].String(), "rule 3 (allow): no match: channel UCpodcast is not listed"; got != want {
+		t.Errorf("check = %q, want %q", got, want)
+	}
+
+	var none *RuleSet
+	if !none.Allows(VideoInfo{}) || !none.Evaluate(VideoInfo{}).Include {
+		t.Error("nil rule set denied a video")
+	}
+	deny, _ := NewRuleSet(RuleDeny, nil)
+	if deny.Allows(VideoInfo{}) {
+		t.Error("default deny allowed a video")
+	}
+}
+
+func TestListOptionsMatchesRules(t *testing.T) {
+	rules, err := NewRuleSet(RuleAllow, []Rule{{Action: RuleDeny, Title: "(?i)#shorts"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	videos := []VideoInfo{{ID: "a", Title: "Tour #shorts"}, {ID: "b", Title: "Full tour"}}
+	got := filterVideos(videos, &ListOptions{Rules: rules})
+	if len(got) != 1 || got[0].ID != "b" {
+		t.Errorf("filterVideos() = %+v, want only b", got)
+	}
+	if !(&ListOptions{Rules: rules}).hasContentFilters() {
+		t.Error("hasContentFilters() = false with rules")
+	}
+}
+
+func TestLoadStoreRules(t *testing.T) {
+	dir := t.TempDir()
+	store := filepath.Join(dir, "archive.json")
+	if rules, err := LoadStoreRules(store); rules != nil || err != nil {
+		t.Fatalf("LoadStoreRules() without a file = %v, %v", rules, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "archive.rules.yaml"), []byte(testRulesYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := StoreRulesPath(store); got != filepath.Join(dir, "archive.rules.yaml") {
+		t.Errorf("StoreRulesPath() = %q", got)
+	}
+	rules, err := LoadStoreRules(store)
+	if err != nil || len(rules.Rules) != 3 {
+		t.Fatalf("LoadStoreRules() = %v, %v", rules, err)
+	}
+
+	// JSON is preferred when both exist
+	if err := os.WriteFile(filepath.Join(dir, "archive.rules.json"), []byte(`{"rules": [{"action": "bogus"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadStoreRules(store); !errors.Is(err, ErrInvalidRules) || !strings.Contains(err.Error(), "archive.rules.json") {
+		t.Errorf("LoadStoreRules() error = %v, want ErrInvalidRules naming the file", err)
+	}
+}
+
+func TestParseYAML(t *testing.T) {
+	doc, err := parseYAML([]byte(`---
+a: plain value # comment
+b: 'it''s # not a comment'
+c:
+  - x
+  - [1, "two, three", '4']
+  -
+    d: ~
+e: {}
+"f g": "tab\there"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"a":   "plain value",
+		"b":   "it's # not a comment",
+		"c":   []interface{}{"x", []interface{}{"1", "two, three", "4"}, map[string]interface{}{"d": nil}},
+		"e":   map[string]interface{}{},
+		"f g": "tab\there",
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("parseYAML() = %#v\nwant %#v", doc, want)
+	}
+
+	for _, bad := range []string{"a: 1\na: 2\n", "a: [1, 2\n", "a: {b: 1}\n", "just text\n", "a:\n  - x\n  y: 1\n"} {
+		if _, err := parseYAML([]byte(bad)); err == nil {
+			t.Errorf("parseYAML(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestYtdlpListerFetchVideo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script stub")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "yt-dlp")
+	body := `#!/bin/sh
+echo "$@" > "` + filepath.Join(dir, "args") + `"
+cat <<'EOF'
+{"id": "dQw4w9WgXcQ", "title": "Never", "duration": 212, "uploader": "Rick", "channel_id": "UCrick", "timestamp": 1256453853, "live_status": "not_live"}
+EOF
+`
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	lister := &YtdlpLister{Path: script}
+	video, err := lister.FetchVideo(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatalf("FetchVideo() error = %v", err)
+	}
+	if video.ID != "dQw4w9WgXcQ" || video.ChannelID != "UCrick" || video.ChannelName != "Rick" ||
+		video.Duration != 212*time.Second || video.Published.Year() != 2009 || video.Type != VideoTypeVideo {
+		t.Errorf("FetchVideo() = %+v", video)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if got := strings.TrimSpace(string(args)); got != "-J --no-warnings -- dQw4w9WgXcQ" {
+		t.Errorf("args = %q", got)
+	}
+
+	if _, err := lister.FetchVideo(context.Background(), "not a video"); !errors.Is(err, ErrInvalidVideoID) {
+		t.Errorf("invalid ID error = %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+	"ytsync/storage"
+)
+
+// SchemaParseFailure records a channel whose listing failed with
+// ErrSchemaChanged and was handed to the fallback lister.
+type SchemaParseFailure struct {
+	// Channel is the channel URL or ID being listed.
+	Channel string
+	// Err is the error the primary lister returned.
+	Err error
+	// Time is when the failure happened.
+	Time time.Time
+}
+
+// SchemaFallbackLister lists channels with a primary lister, such as the
+// Innertube lister, and switches a channel to a fallback lister, such as
+// yt-dlp, for the rest of its lifetime once the primary fails for it with
+// ErrSchemaChanged. The failed listing is retried with the fallback at
+// once, so a layout change degrades the sync instead of reporting no
+// videos. It is safe for concurrent use.
+type SchemaFallbackLister struct {
+	primary  VideoLister
+	fallback VideoLister
+
+	// OnSchemaFailure, if set, is called for each channel switched to the
+	// fallback lister.
+	OnSchemaFailure func(SchemaParseFailure)
+
+	mu       sync.Mutex
+	degraded map[string]SchemaParseFailure
+	failures int64
+}
+
+// NewSchemaFallbackLister creates a lister that uses primary until it
+// fails to parse a channel's responses, and fallback for that channel after.
+func NewSchemaFallbackLister(primary, fallback VideoLister) *SchemaFallbackLister {
+	return &SchemaFallbackLister{
+		primary:  primary,
+		fallback: fallback,
+		degraded: make(map[string]SchemaParseFailure),
+	}
+}
+
+// ListVideos lists the channel with the primary lister, or with the
+// fallback lister if the channel has been degraded. A ResumeToken or
+// ResumePlaylistID is not passed to the fallback lister, since it belongs
+// to the primary's pagination.
+func (l *SchemaFallbackLister) ListVideos(ctx context.Context, channelURL string, opts *ListOptions) ([]VideoInfo, error) {
+	if l.Degraded(channelURL) {
+		return l.fallback.ListVideos(ctx, channelURL, withoutResume(opts))
+	}
+
+	videos, err := l.primary.ListVideos(ctx, channelURL, opts)
+	if !errors.Is(err, ErrSchemaChanged) {
+		return videos, err
+	}
+
+	failure := SchemaParseFailure{Channel: channelURL, Err: err, Time: time.Now()}
+	l.mu.Lock()
+	l.degraded[channelURL] = failure
+	l.failures++
+	l.mu.Unlock()
+	log.Printf("ytsync: %v; listing %s with the fallback lister for the rest of the session", err, channelURL)
+	if l.OnSchemaFailure != nil {
+		l.OnSchemaFailure(failure)
+	}
+	return l.fallback.ListVideos(ctx, channelURL, withoutResume(opts))
+}
+
+// SupportsFullHistory reports whether both listers can retrieve all videos.
+func (l *SchemaFallbackLister) SupportsFullHistory() bool {
+	return l.primary.SupportsFullHistory() && l.fallback.SupportsFullHistory()
+}
+
+// PaginationStrategy reports the primary lister's strategy, which its
+// checkpoints are saved under.
+func (l *SchemaFallbackLister) PaginationStrategy() storage.PaginationStrategy {
+	return listerStrategy(l.primary)
+}
+
+// Degraded reports whether channelURL is listed with the fallback lister.
+func (l *SchemaFallbackLister) Degraded(channelURL string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.degraded[channelURL]
+	return ok
+}
+
+// SchemaFailures returns the number of schema parse failures seen so far.
+func (l *SchemaFallbackLister) SchemaFailures() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.failures
+}
+
+// Reset returns every channel to the primary lister, for example after
+// the primary has been updated for a new layout.
+func (l *SchemaFallbackLister) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.degraded = make(map[string]SchemaParseFailure)
+}
+
+// withoutResume returns opts without its resume position.
+func withoutResume(opts *ListOptions) *ListOptions {
+	if opts == nil || (opts.ResumeToken == "" && opts.ResumePlaylistID == "") {
+		return opts
+	}
+	stripped := *opts
+	stripped.ResumeToken = ""
+	stripped.ResumePlaylistID = ""
+	return &stripped
+}
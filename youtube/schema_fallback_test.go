@@ -0,0 +1,68 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// countingLister records the calls made to it.
+type countingLister struct {
+	mockVideoLister
+	calls int
+	opts  *ListOptions
+}
+
+func (m *countingLister) ListVideos(ctx context.Context, channelURL string, opts *ListOptions) ([]VideoInfo, error) {
+	m.calls++
+	m.opts = opts
+	return m.mockVideoLister.ListVideos(ctx, channelURL, opts)
+}
+
+func TestSchemaFallbackLister(t *testing.T) {
+	ctx := context.Background()
+	primary := &countingLister{mockVideoLister: mockVideoLister{
+		err: &ListerError{Source: "innertube", Channel: "UCbroken", Err: fmt.Errorf("%w: no videos", ErrSchemaChanged)},
+	}}
+	fallback := &countingLister{mockVideoLister: mockVideoLister{videos: []VideoInfo{{ID: "v1"}}}}
+	lister := NewSchemaFallbackLister(primary, fallback)
+	var events []SchemaParseFailure
+	lister.OnSchemaFailure = func(f SchemaParseFailure) { events = append(events, f) }
+
+	videos, err := lister.ListVideos(ctx, "UCbroken", &ListOptions{ResumeToken: "innertube-token"})
+	if err != nil || len(videos) != 1 {
+		t.Fatalf("ListVideos() = %v, %v, want the fallback's videos", videos, err)
+	}
+	if fallback.opts.ResumeToken != "" {
+		t.Errorf("fallback got ResumeToken %q, want none", fallback.opts.ResumeToken)
+	}
+	if len(events) != 1 || events[0].Channel != "UCbroken" || !errors.Is(events[0].Err, ErrSchemaChanged) {
+		t.Errorf("OnSchemaFailure events = %+v", events)
+	}
+
+	// The channel stays on the fallback without asking the primary again
+	if _, err := lister.ListVideos(ctx, "UCbroken", nil); err != nil {
+		t.Fatalf("ListVideos() error = %v", err)
+	}
+	if primary.calls != 1 || fallback.calls != 2 {
+		t.Errorf("calls = primary %d, fallback %d, want 1 and 2", primary.calls, fallback.calls)
+	}
+	if !lister.Degraded("UCbroken") || lister.SchemaFailures() != 1 {
+		t.Errorf("Degraded() = %v, SchemaFailures() = %d", lister.Degraded("UCbroken"), lister.SchemaFailures())
+	}
+
+	// Other errors are returned as they are
+	primary.err = ErrRateLimited
+	if _, err := lister.ListVideos(ctx, "UCother", nil); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("ListVideos() error = %v, want ErrRateLimited", err)
+	}
+	if lister.Degraded("UCother") {
+		t.Error("channel degraded after a rate limit")
+	}
+
+	lister.Reset()
+	if lister.Degraded("UCbroken") {
+		t.Error("channel still degraded after Reset()")
+	}
+}
@@ -0,0 +1,208 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	ythttp "ytsync/http"
+	"ytsync/storage"
+)
+
+// sponsorBlockEndpoint is the public SponsorBlock API base URL.
+const sponsorBlockEndpoint = "https://sponsor.ajay.app"
+
+// SegmentCategory is a SponsorBlock segment category.
+type SegmentCategory string
+
+const (
+	// SegmentSponsor is a paid promotion or sponsor read.
+	SegmentSponsor SegmentCategory = "sponsor"
+	// SegmentSelfPromo is unpaid promotion of the creator's own products or channels.
+	SegmentSelfPromo SegmentCategory = "selfpromo"
+	// SegmentInteraction is a reminder to like, subscribe, or follow.
+	SegmentInteraction SegmentCategory = "interaction"
+	// SegmentIntro is an intermission or intro animation without content.
+	SegmentIntro SegmentCategory = "intro"
+	// SegmentOutro is an end card or credits.
+	SegmentOutro SegmentCategory = "outro"
+	// SegmentPreview is a recap or preview of other content.
+	SegmentPreview SegmentCategory = "preview"
+	// SegmentFiller is a tangent or joke not needed to understand the video.
+	SegmentFiller SegmentCategory = "filler"
+	// SegmentMusicOffTopic is non-music content in a music video.
+	SegmentMusicOffTopic SegmentCategory = "music_offtopic"
+)
+
+// DefaultSegmentCategories are the categories fetched when none are given:
+// sponsor reads, intros, and outros.
+var DefaultSegmentCategories = []SegmentCategory{SegmentSponsor, SegmentIntro, SegmentOutro}
+
+// SkipSegment is a community-submitted time range from SponsorBlock.
+type SkipSegment struct {
+	// UUID is the SponsorBlock segment ID.
+	UUID string `json:"uuid"`
+	// Category is the segment category.
+	Category SegmentCategory `json:"category"`
+	// ActionType is what players should do with the segment ("skip", "mute", "full", "poi").
+	ActionType string `json:"action_type"`
+	// Start is the segment start offset in seconds.
+	Start float64 `json:"start"`
+	// End is the segment end offset in seconds.
+	End float64 `json:"end"`
+	// Votes is the community vote score.
+	Votes int `json:"votes"`
+}
+
+// Contains reports whether offset t (in seconds) falls within the segment.
+func (s SkipSegment) Contains(t float64) bool {
+	return t >= s.Start && t < s.End
+}
+
+// SponsorBlockClient fetches skip segments from the SponsorBlock API.
+type SponsorBlockClient struct {
+	// Categories selects the segment categories to fetch.
+	// Defaults to DefaultSegmentCategories.
+	Categories []SegmentCategory
+
+	httpClient *ythttp.Client
+	endpoint   string
+}
+
+// NewSponsorBlockClient creates a SponsorBlock client. If httpClient is nil,
+// a client with ythttp.DefaultConfig is created, whose circuit breaker does
+// not count the 404s SponsorBlock answers for videos without segments.
+func NewSponsorBlockClient(httpClient *ythttp.Client) *SponsorBlockClient {
+	if httpClient == nil {
+		httpClient = ythttp.New(ythttp.DefaultConfig())
+	}
+	return &SponsorBlockClient{
+		httpClient: httpClient,
+		endpoint:   sponsorBlockEndpoint,
+	}
+}
+
+var (
+	defaultSponsorBlockOnce   sync.Once
+	defaultSponsorBlockClient *SponsorBlockClient
+)
+
+// FetchSegments fetches sponsor, intro, and outro segments for a video using
+// a shared default client. See SponsorBlockClient.FetchSegments.
+func FetchSegments(ctx context.Context, videoID string) ([]SkipSegment, error) {
+	defaultSponsorBlockOnce.Do(func() {
+		defaultSponsorBlockClient = NewSponsorBlockClient(nil)
+	})
+	return defaultSponsorBlockClient.FetchSegments(ctx, videoID)
+}
+
+// sponsorBlockSegment is one element of the skipSegments API response.
+type sponsorBlockSegment struct {
+	UUID       string     `json:"UUID"`
+	Category   string     `json:"category"`
+	ActionType string     `json:"actionType"`
+	Segment    [2]float64 `json:"segment"`
+	Votes      int        `json:"votes"`
+}
+
+// FetchSegments returns the video's skip segments sorted by start time.
+// A video with no submitted segments returns an empty slice and no error.
+func (c *SponsorBlockClient) FetchSegments(ctx context.Context, videoID string) ([]SkipSegment, error) {
+	if videoID == "" {
+		return nil, fmt.Errorf("%w: video ID is required", ErrInvalidURL)
+	}
+
+	categories := c.Categories
+	if len(categories) == 0 {
+		categories = DefaultSegmentCategories
+	}
+	categoryJSON, err := json.Marshal(categories)
+	if err != nil {
+		return nil, fmt.Errorf("marshal categories: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("videoID", videoID)
+	query.Set("categories", string(categoryJSON))
+	reqURL := c.endpoint + "/api/skipSegments?" + query.Encode()
+
+	resp, err := c.httpClient.Do(ctx, http.MethodGet, reqURL, nil, map[string]string{"Accept": "application/json"})
+	if err != nil {
+		// SponsorBlock answers 404 when no segments have been submitted
+		var httpErr *ythttp.HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+			return []SkipSegment{}, nil
+		}
+		return nil, fmt.Errorf("fetch sponsorblock segments for %s: %w", videoID, err)
+	}
+
+	var raw []sponsorBlockSegment
+	if err := json.Unmarshal(resp.Body, &raw); err != nil {
+		return nil, fmt.Errorf("parse sponsorblock response for %s: %w", videoID, err)
+	}
+
+	segments := make([]SkipSegment, 0, len(raw))
+	for _, r := range raw {
+		if r.Segment[1] <= r.Segment[0] {
+			// Points of interest have no length and cannot be skipped
+			continue
+		}
+		segments = append(segments, SkipSegment{
+			UUID:       r.UUID,
+			Category:   SegmentCategory(r.Category),
+			ActionType: r.ActionType,
+			Start:      r.Segment[0],
+			End:        r.Segment[1],
+			Votes:      r.Votes,
+		})
+	}
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].Start < segments[j].Start
+	})
+	return segments, nil
+}
+
+// StripSegments returns a copy of transcript without the entries whose
+// midpoint falls inside any of the segments, removing sponsor reads and
+// similar content before the text is used for training or retrieval.
+func StripSegments(transcript *Transcript, segments []SkipSegment) *Transcript {
+	if transcript == nil {
+		return nil
+	}
+	stripped := *transcript
+	stripped.Entries = make([]TranscriptEntry, 0, len(transcript.Entries))
+	for _, entry := range transcript.Entries {
+		if !inSegment(segments, entry.Start+entry.Duration/2) {
+			stripped.Entries = append(stripped.Entries, entry)
+		}
+	}
+	return &stripped
+}
+
+// inSegment reports whether offset t falls inside any segment.
+func inSegment(segments []SkipSegment, t float64) bool {
+	for _, s := range segments {
+		if s.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// SegmentsToStorage converts skip segments to their storage representation.
+func SegmentsToStorage(segments []SkipSegment) []storage.SkipSegment {
+	out := make([]storage.SkipSegment, 0, len(segments))
+	for _, s := range segments {
+		out = append(out, storage.SkipSegment{
+			UUID:     s.UUID,
+			Category: string(s.Category),
+			Start:    s.Start,
+			End:      s.End,
+		})
+	}
+	return out
+}
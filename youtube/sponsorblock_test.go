@@ -0,0 +1,158 @@
+package youtube
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	ythttp "ytsync/http"
+)
+
+func TestSponsorBlockClient_FetchSegments(t *testing.T) {
+	var gotVideoID, gotCategories string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/skipSegments" {
+			t.Errorf("request path = %q, want /api/skipSegments", r.URL.Path)
+		}
+		gotVideoID = r.URL.Query().Get("videoID")
+		gotCategories = r.URL.Query().Get("categories")
+
+		if gotVideoID == "nosegments" {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"category":"outro","actionType":"skip","segment":[590.0,600.0],"UUID":"c","votes":2},
+			{"category":"sponsor","actionType":"skip","segment":[30.5,75.25],"UUID":"a","votes":10},
+			{"category":"intro","actionType":"poi","segment":[5.0,5.0],"UUID":"b","votes":0}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewSponsorBlockClient(ythttp.New(ythttp.DefaultConfig()))
+	client.endpoint = server.URL
+
+	segments, err := client.FetchSegments(context.Background(), "dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatalf("FetchSegments() error = %v", err)
+	}
+	if gotVideoID != "dQw4w9WgXcQ" {
+		t.Errorf("request videoID = %q", gotVideoID)
+	}
+	if want := `["sponsor","intro","outro"]`; gotCategories != want {
+		t.Errorf("request categories = %q, want %q", gotCategories, want)
+	}
+
+	if len(segments) != 2 {
+		t.Fatalf("FetchSegments() returned %d segments, want 2 (zero-length dropped): %+v", len(segments), segments)
+	}
+	if segments[0].UUID != "a" || segments[0].Category != SegmentSponsor || segments[0].Start != 30.5 || segments[0].End != 75.25 {
+		t.Errorf("segments[0] = %+v, want sponsor 30.5-75.25", segments[0])
+	}
+	if segments[1].Category != SegmentOutro {
+		t.Errorf("segments[1].Category = %q, want outro", segments[1].Category)
+	}
+
+	none, err := client.FetchSegments(context.Background(), "nosegments")
+	if err != nil {
+		t.Fatalf("FetchSegments() for video without segments error = %v", err)
+	}
+	if none == nil || len(none) != 0 {
+		t.Errorf("FetchSegments() = %v, want empty slice", none)
+	}
+}
+
+func TestSponsorBlockClient_RepeatedNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// Most videos have no segments, which must not open the circuit after
+	// its threshold of 5 failures
+	client := NewSponsorBlockClient(nil)
+	client.endpoint = server.URL
+	for i := 0; i < 6; i++ {
+		segments, err := client.FetchSegments(context.Background(), "nosegments")
+		if err != nil {
+			t.Fatalf("FetchSegments() #%d error = %v", i+1, err)
+		}
+		if segments == nil || len(segments) != 0 {
+			t.Fatalf("FetchSegments() #%d = %v, want an empty slice", i+1, segments)
+		}
+	}
+}
+
+func TestSponsorBlockClient_Categories(t *testing.T) {
+	var gotCategories string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotCategories = r.URL.Query().Get("categories")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewSponsorBlockClient(ythttp.New(ythttp.DefaultConfig()))
+	client.endpoint = server.URL
+	client.Categories = []SegmentCategory{SegmentSelfPromo}
+
+	if _, err := client.FetchSegments(context.Background(), "abc"); err != nil {
+		t.Fatalf("FetchSegments() error = %v", err)
+	}
+	if want := `["selfpromo"]`; gotCategories != want {
+		t.Errorf("request categories = %q, want %q", gotCategories, want)
+	}
+}
+
+func TestSponsorBlockClient_EmptyVideoID(t *testing.T) {
+	client := NewSponsorBlockClient(nil)
+	if _, err := client.FetchSegments(context.Background(), ""); err == nil {
+		t.Error("FetchSegments(\"\") error = nil, want error")
+	}
+}
+
+func TestStripSegments(t *testing.T) {
+	transcript := &Transcript{
+		VideoID:  "abc",
+		Language: "en",
+		Entries: []TranscriptEntry{
+			{Start: 0, Duration: 5, Text: "welcome back"},
+			{Start: 10, Duration: 4, Text: "this video is sponsored by"},
+			{Start: 16, Duration: 4, Text: "use code ytsync"},
+			{Start: 19, Duration: 4, Text: "anyway, back to it"},
+			{Start: 30, Duration: 5, Text: "thanks for watching"},
+		},
+	}
+	segments := []SkipSegment{
+		{Category: SegmentSponsor, Start: 9, End: 20},
+		{Category: SegmentOutro, Start: 30, End: 40},
+	}
+
+	got := StripSegments(transcript, segments)
+	want := []string{"welcome back", "anyway, back to it"}
+	if len(got.Entries) != len(want) {
+		t.Fatalf("StripSegments() kept %d entries, want %d: %+v", len(got.Entries), len(want), got.Entries)
+	}
+	for i, text := range want {
+		if got.Entries[i].Text != text {
+			t.Errorf("entry %d = %q, want %q", i, got.Entries[i].Text, text)
+		}
+	}
+	if got.VideoID != "abc" || got.Language != "en" {
+		t.Errorf("StripSegments() lost transcript fields: %+v", got)
+	}
+	if len(transcript.Entries) != 5 {
+		t.Error("StripSegments() modified the input transcript")
+	}
+
+	if StripSegments(nil, segments) != nil {
+		t.Error("StripSegments(nil) != nil")
+	}
+}
+
+func TestSegmentsToStorage(t *testing.T) {
+	out := SegmentsToStorage([]SkipSegment{{UUID: "a", Category: SegmentIntro, Start: 0, End: 12.5}})
+	if len(out) != 1 || out[0].UUID != "a" || out[0].Category != "intro" || out[0].End != 12.5 {
+		t.Errorf("SegmentsToStorage() = %+v", out)
+	}
+}
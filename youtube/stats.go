@@ -0,0 +1,199 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"ytsync/errcode"
+	ythttp "ytsync/http"
+	"ytsync/storage"
+)
+
+// ErrNoStatsStore is returned by CaptureStats when the store does not
+// implement storage.VideoStatsStore.
+var ErrNoStatsStore = errcode.New(errcode.InvalidInput, "youtube: store does not record video stats")
+
+// StatsOptions configures CaptureStats and TrackStats.
+type StatsOptions struct {
+	// Store lists the videos to sample and receives the samples. It must
+	// also implement storage.VideoStatsStore.
+	Store storage.Store
+	// Metadata fetches the current counters of a video.
+	Metadata MetadataFetcher
+	// ChannelIDs limits sampling to the stored channels with these YouTube
+	// IDs. Empty samples every stored channel.
+	ChannelIDs []string
+	// MaxAge, if non-zero, skips videos published longer ago, since their
+	// counters change little once the first weeks have passed.
+	MaxAge time.Duration
+	// Concurrency is the number of videos sampled in parallel.
+	// Defaults to DefaultEnrichConcurrency.
+	Concurrency int
+	// RateLimiter, if set, is waited on before every metadata fetch.
+	RateLimiter *ythttp.RateLimiter
+}
+
+// StatsResult is the outcome of one CaptureStats run.
+type StatsResult struct {
+	// Sampled is the number of videos whose counters were recorded.
+	Sampled int
+	// Errors maps the YouTube ID of each video that could not be sampled
+	// to its error.
+	Errors map[string]error
+}
+
+// CaptureStats records one sample of the view, like, and comment counts
+// of every matching stored video. Per-video failures are reported in the
+// result; the error is non-nil only if sampling could not start.
+func CaptureStats(ctx context.Context, opts *StatsOptions) (*StatsResult, error) {
+	if opts == nil || opts.Store == nil || opts.Metadata == nil {
+		return nil, fmt.Errorf("capture stats: store and metadata fetcher are required")
+	}
+	statsStore, ok := opts.Store.(storage.VideoStatsStore)
+	if !ok {
+		return nil, ErrNoStatsStore
+	}
+
+	videos, err := statsVideos(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultEnrichConcurrency
+	}
+
+	result := &StatsResult{Errors: make(map[string]error)}
+	var mu sync.Mutex
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, video := range videos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := sampleVideo(ctx, opts, statsStore, video, sem)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Errors[video.YouTubeID] = err
+			} else {
+				result.Sampled++
+			}
+		}()
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// TrackStats runs CaptureStats immediately and then every interval until
+// ctx is done, building a time series per video. Failed runs are logged
+// and retried at the next interval. It returns ctx's error.
+func TrackStats(ctx context.Context, interval time.Duration, opts *StatsOptions) error {
+	if interval <= 0 {
+		return fmt.Errorf("track stats: interval must be positive")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := CaptureStats(ctx, opts)
+		switch {
+		case err != nil:
+			log.Printf("youtube: capture stats: %v", err)
+		case len(result.Errors) > 0:
+			log.Printf("youtube: captured stats for %d videos, %d failed", result.Sampled, len(result.Errors))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// statsVideos returns the stored videos opts selects.
+func statsVideos(ctx context.Context, opts *StatsOptions) ([]*storage.Video, error) {
+	var videos []*storage.Video
+	err := eachStoredVideo(ctx, opts.Store, opts.ChannelIDs, func(video *storage.Video) {
+		if opts.MaxAge > 0 && !video.PublishedAt.IsZero() && time.Since(video.PublishedAt) > opts.MaxAge {
+			return
+		}
+		videos = append(videos, video)
+	})
+	return videos, err
+}
+
+// eachStoredVideo calls fn for every video of the stored channels with the
+// given YouTube IDs, or of every stored channel if channelIDs is empty.
+func eachStoredVideo(ctx context.Context, store storage.Store, channelIDs []string, fn func(*storage.Video)) error {
+	var channels []*storage.Channel
+	if len(channelIDs) == 0 {
+		all, err := store.ListChannels(ctx)
+		if err != nil {
+			return fmt.Errorf("list channels: %w", err)
+		}
+		channels = all
+	} else {
+		for _, id := range channelIDs {
+			channel, err := store.GetChannelByYouTubeID(ctx, id)
+			if err != nil {
+				return fmt.Errorf("look up channel %s: %w", id, err)
+			}
+			channels = append(channels, channel)
+		}
+	}
+
+	for _, channel := range channels {
+		list, err := store.ListVideosByChannel(ctx, channel.ID)
+		if err != nil {
+			return fmt.Errorf("list videos of %s: %w", channel.YouTubeID, err)
+		}
+		for _, video := range list {
+			fn(video)
+		}
+	}
+	return nil
+}
+
+// sampleVideo fetches video's counters and records them.
+func sampleVideo(ctx context.Context, opts *StatsOptions, store storage.VideoStatsStore, video *storage.Video, sem chan struct{}) error {
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-sem }()
+
+	if opts.RateLimiter != nil {
+		if err := opts.RateLimiter.Wait(ctx, "https://www.youtube.com/watch?v="+video.YouTubeID); err != nil {
+			return err
+		}
+	}
+	md, err := opts.Metadata(ctx, video.YouTubeID)
+	if err != nil {
+		return err
+	}
+	return store.RecordVideoStats(ctx, StatsFromMetadata(video.ID, md))
+}
+
+// StatsFromMetadata converts the counters in md to a stats sample for the
+// stored video with internal ID videoID.
+func StatsFromMetadata(videoID string, md *VideoMetadata) *storage.VideoStats {
+	sampledAt := md.FetchedAt
+	if sampledAt.IsZero() {
+		sampledAt = time.Now().UTC()
+	}
+	return &storage.VideoStats{
+		VideoID:      videoID,
+		SampledAt:    sampledAt,
+		ViewCount:    md.ViewCount,
+		LikeCount:    md.LikeCount,
+		CommentCount: md.CommentCount,
+	}
+}
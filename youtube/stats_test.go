@@ -0,0 +1,85 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCaptureStats(t *testing.T) {
+	store := newEnrichTestStore(t)
+	ctx := context.Background()
+
+	videos := []VideoInfo{
+		{ID: "new", Title: "New", Published: time.Now().Add(-time.Hour)},
+		{ID: "old", Title: "Old", Published: time.Now().AddDate(-1, 0, 0)},
+		{ID: "gone", Title: "Gone", Published: time.Now()},
+	}
+	if _, err := Enrich(ctx, "UCtest", videos, &EnrichOptions{Store: store}); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	views := int64(0)
+	opts := &StatsOptions{
+		Store: store,
+		Metadata: func(ctx context.Context, videoID string) (*VideoMetadata, error) {
+			if videoID == "gone" {
+				return nil, ErrNetworkTimeout
+			}
+			views += 10
+			return &VideoMetadata{ID: videoID, ViewCount: views, LikeCount: 2, CommentCount: 1}, nil
+		},
+		MaxAge:      30 * 24 * time.Hour,
+		Concurrency: 1,
+	}
+
+	for i := 0; i < 2; i++ {
+		result, err := CaptureStats(ctx, opts)
+		if err != nil {
+			t.Fatalf("CaptureStats() error = %v", err)
+		}
+		if result.Sampled != 1 || !errors.Is(result.Errors["gone"], ErrNetworkTimeout) {
+			t.Errorf("CaptureStats() = %+v, want the recent video sampled and the unavailable one failed", result)
+		}
+	}
+
+	video, err := store.GetVideoByYouTubeID(ctx, "new")
+	if err != nil {
+		t.Fatalf("GetVideoByYouTubeID() error = %v", err)
+	}
+	history, err := store.GetStatsHistory(ctx, video.ID, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("GetStatsHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[0].ViewCount != 10 || history[1].ViewCount != 20 || history[1].LikeCount != 2 {
+		t.Errorf("history = %+v, want two growing samples", history)
+	}
+
+	old, _ := store.GetVideoByYouTubeID(ctx, "old")
+	if history, _ := store.GetStatsHistory(ctx, old.ID, time.Time{}, time.Time{}); len(history) != 0 {
+		t.Errorf("video older than MaxAge sampled: %+v", history)
+	}
+}
+
+func TestEnrich_RecordStats(t *testing.T) {
+	store := newEnrichTestStore(t)
+	ctx := context.Background()
+
+	opts := &EnrichOptions{
+		Metadata: func(ctx context.Context, videoID string) (*VideoMetadata, error) {
+			return &VideoMetadata{ID: videoID, Title: "Title", ViewCount: 42}, nil
+		},
+		Store:       store,
+		RecordStats: true,
+	}
+	if _, err := Enrich(ctx, "UCtest", []VideoInfo{{ID: "vid1"}}, opts); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	video, _ := store.GetVideoByYouTubeID(ctx, "vid1")
+	history, _ := store.GetStatsHistory(ctx, video.ID, time.Time{}, time.Time{})
+	if len(history) != 1 || history[0].ViewCount != 42 {
+		t.Errorf("history = %+v, want one sample from the metadata stage", history)
+	}
+}
@@ -0,0 +1,77 @@
+package youtube
+
+import (
+	"strings"
+	"ytsync/errcode"
+)
+
+var (
+	// ErrNoStreams is returned when YouTube does not offer playable streams
+	// for a video, for example because it is private, removed, or region
+	// blocked.
+	ErrNoStreams = errcode.New(errcode.NotFound, "youtube: no playable streams")
+	// ErrCipherNotFound is returned when the signature routine cannot be
+	// located in a player script, usually because YouTube changed its layout.
+	ErrCipherNotFound = errcode.New(errcode.ParseFailure, "youtube: signature cipher not found in player script")
+	// ErrNTransformRequired is returned when a stream URL has a scrambled n
+	// parameter, no JavaScript evaluator is set to decode it, and throttled
+	// URLs are not accepted.
+	ErrNTransformRequired = errcode.New(errcode.Unavailable, "youtube: stream URL needs the n transform")
+)
+
+// StreamFormat is one media stream offered for a video, with a direct URL
+// that can be fetched without yt-dlp. The innertube package's
+// StreamResolver produces it.
+type StreamFormat struct {
+	// Itag is YouTube's identifier for the format.
+	Itag int
+	// URL is the direct media URL. It expires after a few hours.
+	URL string
+	// Throttled reports whether URL's n parameter was left undecoded, so
+	// YouTube serves the stream at roughly real-time speed.
+	Throttled bool
+	// MimeType is the container type, e.g. "video/mp4".
+	MimeType string
+	// Codecs lists the codecs in the stream, e.g. "avc1.640028" or "opus".
+	Codecs string
+	// Quality is YouTube's quality name, e.g. "hd720" or "tiny".
+	Quality string
+	// QualityLabel is the display label of video streams, e.g. "720p60".
+	QualityLabel string
+	// Width and Height are the video dimensions; zero for audio streams.
+	Width, Height int
+	// FPS is the video frame rate; zero for audio streams.
+	FPS int
+	// Bitrate is the peak bitrate in bits per second.
+	Bitrate int
+	// ContentLength is the stream size in bytes, if known.
+	ContentLength int64
+	// AudioSampleRate is the audio sample rate in Hz, if the stream has audio.
+	AudioSampleRate int
+	// AudioChannels is the number of audio channels, if the stream has audio.
+	AudioChannels int
+	// Adaptive reports whether the stream carries only audio or only video.
+	// Non-adaptive streams are muxed and carry both.
+	Adaptive bool
+	// AudioLanguage is the language of the audio track in videos with
+	// several, e.g. "en-US".
+	AudioLanguage string
+	// AudioTrackName is YouTube's name for the audio track, e.g.
+	// "English (United States) original".
+	AudioTrackName string
+	// DefaultAudio reports whether the audio track is played by default.
+	DefaultAudio bool
+	// OriginalAudio reports whether the audio track is in the video's
+	// original language rather than dubbed.
+	OriginalAudio bool
+}
+
+// HasVideo reports whether the stream contains video.
+func (f *StreamFormat) HasVideo() bool {
+	return strings.HasPrefix(f.MimeType, "video/")
+}
+
+// HasAudio reports whether the stream contains audio.
+func (f *StreamFormat) HasAudio() bool {
+	return strings.HasPrefix(f.MimeType, "audio/") || !f.Adaptive
+}
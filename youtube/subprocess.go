@@ -0,0 +1,228 @@
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"ytsync/errcode"
+	"ytsync/proc"
+	"ytsync/tags"
+)
+
+// YtdlpErrorClass is a category of yt-dlp failure recognized from its
+// stderr output.
+type YtdlpErrorClass string
+
+const (
+	// YtdlpErrorUnknown is a failure that matched no known category.
+	YtdlpErrorUnknown YtdlpErrorClass = "unknown"
+	// YtdlpErrorUnavailable means the video was removed, made private to
+	// its uploader, or is otherwise unavailable.
+	YtdlpErrorUnavailable YtdlpErrorClass = "video_unavailable"
+	// YtdlpErrorNotFound means the channel, playlist, or page does not
+	// exist.
+	YtdlpErrorNotFound YtdlpErrorClass = "not_found"
+	// YtdlpErrorAgeRestricted means the video needs an account that has
+	// confirmed its age.
+	YtdlpErrorAgeRestricted YtdlpErrorClass = "age_restricted"
+	// YtdlpErrorSignInRequired means the video is private or members-only
+	// and needs cookies of an account with access.
+	YtdlpErrorSignInRequired YtdlpErrorClass = "sign_in_required"
+	// YtdlpErrorBotDetected means YouTube asked to confirm the client is
+	// not a bot.
+	YtdlpErrorBotDetected YtdlpErrorClass = "bot_detected"
+	// YtdlpErrorRateLimited means YouTube answered with HTTP 429.
+	YtdlpErrorRateLimited YtdlpErrorClass = "rate_limited"
+	// YtdlpErrorGeoBlocked means the video is not available in the
+	// country the request came from.
+	YtdlpErrorGeoBlocked YtdlpErrorClass = "geo_blocked"
+	// YtdlpErrorFragment means a download gave up on a media fragment.
+	YtdlpErrorFragment YtdlpErrorClass = "fragment"
+)
+
+// ytdlpErrorPatterns maps stderr substrings, matched case-insensitively, to
+// error classes. The first match wins, so more specific patterns come
+// first: YouTube's bot check and age gate both ask to "sign in".
+var ytdlpErrorPatterns = []struct {
+	class    YtdlpErrorClass
+	patterns []string
+}{
+	{YtdlpErrorBotDetected, []string{"confirm you're not a bot", "confirm you’re not a bot"}},
+	{YtdlpErrorAgeRestricted, []string{"confirm your age", "age-restricted", "age restricted", "inappropriate for some users"}},
+	{YtdlpErrorRateLimited, []string{"http error 429", "too many requests", "rate-limited", "rate limit"}},
+	{YtdlpErrorGeoBlocked, []string{"available in your country", "geo restriction", "geo-restricted", "geo restricted"}},
+	{YtdlpErrorSignInRequired, []string{"sign in", "members-only", "join this channel", "private video", "use --cookies"}},
+	{YtdlpErrorFragment, []string{"fragment"}},
+	{YtdlpErrorUnavailable, []string{"video unavailable", "this video is unavailable", "has been removed", "no longer available", "is not available"}},
+	{YtdlpErrorNotFound, []string{"http error 404", "not found", "does not exist"}},
+}
+
+// ClassifyYtdlpStderr returns the class of the failure described by
+// yt-dlp's stderr output, or YtdlpErrorUnknown.
+func ClassifyYtdlpStderr(stderr string) YtdlpErrorClass {
+	msg := strings.ToLower(stderr)
+	for _, p := range ytdlpErrorPatterns {
+		for _, pattern := range p.patterns {
+			if strings.Contains(msg, pattern) {
+				return p.class
+			}
+		}
+	}
+	return YtdlpErrorUnknown
+}
+
+// Retryable reports whether a failure of this class may succeed if the
+// command is run again later.
+func (c YtdlpErrorClass) Retryable() bool {
+	switch c {
+	case YtdlpErrorUnavailable, YtdlpErrorNotFound, YtdlpErrorAgeRestricted,
+		YtdlpErrorSignInRequired, YtdlpErrorGeoBlocked:
+		return false
+	default:
+		return true
+	}
+}
+
+// code returns the errcode for failures of this class.
+func (c YtdlpErrorClass) code() errcode.Code {
+	switch c {
+	case YtdlpErrorUnavailable, YtdlpErrorNotFound:
+		return errcode.NotFound
+	case YtdlpErrorBotDetected:
+		return errcode.BotDetected
+	case YtdlpErrorRateLimited:
+		return errcode.RateLimited
+	case YtdlpErrorFragment:
+		return errcode.Unavailable
+	default:
+		return errcode.SubprocessFailure
+	}
+}
+
+// sentinel returns the package error a failure of this class also matches
+// with errors.Is, or nil.
+func (c YtdlpErrorClass) sentinel() error {
+	switch c {
+	case YtdlpErrorNotFound:
+		return ErrChannelNotFound
+	case YtdlpErrorBotDetected:
+		return ErrBotDetected
+	case YtdlpErrorRateLimited:
+		return ErrRateLimited
+	default:
+		return nil
+	}
+}
+
+// maxSubprocessStderr bounds the stderr kept in a SubprocessError. The end
+// of the output is kept, since yt-dlp reports the fatal error last.
+const maxSubprocessStderr = 8 << 10
+
+// SubprocessError is returned when yt-dlp exits with an error. It keeps
+// what is needed to diagnose the failure and classifies it from stderr.
+// Use errors.As to inspect it; errors.Is matches ErrChannelNotFound,
+// ErrBotDetected, and ErrRateLimited for the corresponding classes.
+type SubprocessError struct {
+	// Path is the executable that was run.
+	Path string
+	// Args are the arguments it was run with.
+	Args []string
+	// ExitCode is the exit status, or -1 if the process did not exit
+	// normally or could not be started.
+	ExitCode int
+	// Stderr is the end of the process's error output.
+	Stderr string
+	// Class is the failure category matched in Stderr.
+	Class YtdlpErrorClass
+	// Err is the error from running the process.
+	Err error
+	// Tags are the tags of the context the process was run with.
+	Tags tags.Tags
+}
+
+// Error returns the class and the last error line yt-dlp printed, followed
+// by the tags, if any.
+func (e *SubprocessError) Error() string {
+	msg := fmt.Sprintf("yt-dlp failed (%s, exit status %d)", e.Class, e.ExitCode)
+	if line := lastErrorLine(e.Stderr); line != "" {
+		msg += ": " + line
+	} else {
+		msg += ": " + e.Err.Error()
+	}
+	if len(e.Tags) > 0 {
+		msg += " [" + e.Tags.String() + "]"
+	}
+	return msg
+}
+
+// Unwrap returns the process error and the package sentinel for the class,
+// if any.
+func (e *SubprocessError) Unwrap() []error {
+	if sentinel := e.Class.sentinel(); sentinel != nil {
+		return []error{sentinel, e.Err}
+	}
+	return []error{e.Err}
+}
+
+// ErrorCode returns the errcode for the failure class.
+func (e *SubprocessError) ErrorCode() errcode.Code {
+	return e.Class.code()
+}
+
+// Retryable reports whether running the command again may succeed.
+func (e *SubprocessError) Retryable() bool {
+	return e.Class.Retryable()
+}
+
+// newSubprocessError builds a SubprocessError for a failed run of path.
+func newSubprocessError(path string, args []string, stderr string, err error) *SubprocessError {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+	if len(stderr) > maxSubprocessStderr {
+		stderr = stderr[len(stderr)-maxSubprocessStderr:]
+	}
+	return &SubprocessError{
+		Path:     path,
+		Args:     append([]string(nil), args...),
+		ExitCode: exitCode,
+		Stderr:   stderr,
+		Class:    ClassifyYtdlpStderr(stderr),
+		Err:      err,
+	}
+}
+
+// runYtdlp runs yt-dlp at path and returns its standard output. A failed
+// run returns the output so far and a *SubprocessError carrying the tags of
+// ctx; callers check ctx themselves to tell timeouts and cancellation apart.
+func runYtdlp(ctx context.Context, path string, args ...string) ([]byte, error) {
+	cmd := proc.Command(ctx, path, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		e := newSubprocessError(path, args, stderr.String(), err)
+		e.Tags = tags.From(ctx)
+		return stdout.Bytes(), e
+	}
+	return stdout.Bytes(), nil
+}
+
+// lastErrorLine returns the last "ERROR:" line of yt-dlp output, or its
+// last non-empty line.
+func lastErrorLine(stderr string) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); strings.HasPrefix(line, "ERROR:") {
+			return line
+		}
+	}
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,81 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"ytsync/errcode"
+	"ytsync/tags"
+)
+
+func TestClassifyYtdlpStderr(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   YtdlpErrorClass
+	}{
+		{"ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader", YtdlpErrorUnavailable},
+		{"ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users.", YtdlpErrorAgeRestricted},
+		{"ERROR: [youtube] abc: Sign in to confirm you're not a bot. Use --cookies-from-browser", YtdlpErrorBotDetected},
+		{"ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video", YtdlpErrorSignInRequired},
+		{"ERROR: [youtube] abc: Join this channel to get access to members-only content", YtdlpErrorSignInRequired},
+		{"ERROR: [youtube] abc: Unable to download API page: HTTP Error 429: Too Many Requests", YtdlpErrorRateLimited},
+		{"ERROR: [youtube] abc: The uploader has not made this video available in your country", YtdlpErrorGeoBlocked},
+		{"ERROR: fragment 3 not found, unable to continue", YtdlpErrorFragment},
+		{"ERROR: [youtube:tab] @missing: This channel does not exist.", YtdlpErrorNotFound},
+		{"ERROR: something else entirely", YtdlpErrorUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyYtdlpStderr(tt.stderr); got != tt.want {
+			t.Errorf("ClassifyYtdlpStderr(%q) = %s, want %s", tt.stderr, got, tt.want)
+		}
+	}
+}
+
+func TestRunYtdlp_SubprocessError(t *testing.T) {
+	path := writeMockScript(t, t.TempDir(), "yt-dlp", `
+echo "[youtube] abc: Downloading webpage" >&2
+echo "ERROR: [youtube] abc: Sign in to confirm you're not a bot" >&2
+exit 1
+`)
+
+	_, err := runYtdlp(context.Background(), path, "-J", "abc")
+	var subErr *SubprocessError
+	if !errors.As(err, &subErr) {
+		t.Fatalf("runYtdlp() error = %v, want *SubprocessError", err)
+	}
+	if subErr.ExitCode != 1 || subErr.Class != YtdlpErrorBotDetected || strings.Join(subErr.Args, " ") != "-J abc" {
+		t.Errorf("SubprocessError = %+v", subErr)
+	}
+	if !errors.Is(err, ErrBotDetected) || errcode.Of(err) != errcode.BotDetected || !subErr.Retryable() {
+		t.Errorf("error %v does not match ErrBotDetected", err)
+	}
+	want := "yt-dlp failed (bot_detected, exit status 1): ERROR: [youtube] abc: Sign in to confirm you're not a bot"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	// The error names the channel the run was for
+	_, err = runYtdlp(tags.WithChannel(context.Background(), "UCa"), path, "-J", "abc")
+	if want += " [channel=UCa]"; err == nil || err.Error() != want {
+		t.Errorf("Error() with tags = %v, want %q", err, want)
+	}
+}
+
+func TestYtdlpLister_PermanentFailure(t *testing.T) {
+	path := writeMockScript(t, t.TempDir(), "yt-dlp", `
+if [ "$1" = "--version" ]; then echo "2024.01.01"; exit 0; fi
+echo "ERROR: [youtube:tab] @missing: This channel does not exist." >&2
+exit 1
+`)
+
+	lister := &YtdlpLister{Path: path}
+	_, err := lister.ListVideos(context.Background(), "https://www.youtube.com/@missing", &ListOptions{})
+	if !errors.Is(err, ErrChannelNotFound) || errcode.Of(err) != errcode.NotFound {
+		t.Errorf("ListVideos() error = %v, want ErrChannelNotFound", err)
+	}
+	var subErr *SubprocessError
+	if !errors.As(err, &subErr) || subErr.Retryable() {
+		t.Errorf("ListVideos() error = %v, want a permanent SubprocessError", err)
+	}
+}
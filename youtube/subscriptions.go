@@ -0,0 +1,301 @@
+package youtube
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"ytsync/storage"
+)
+
+// SubscriptionFormat is the file format of a subscription export.
+type SubscriptionFormat string
+
+const (
+	// SubscriptionsOPML is an OPML feed list, as exported by YouTube's old
+	// subscription manager, NewPipe, and RSS readers.
+	SubscriptionsOPML SubscriptionFormat = "opml"
+	// SubscriptionsCSV is a CSV file, such as Google Takeout's
+	// subscriptions.csv. Columns are found by header name (id, url, title);
+	// without a header the first column is the channel and the second, if
+	// any, its name.
+	SubscriptionsCSV SubscriptionFormat = "csv"
+	// SubscriptionsTakeout is the subscriptions.json of older Google Takeout
+	// exports, a list of Data API subscription resources.
+	SubscriptionsTakeout SubscriptionFormat = "takeout"
+)
+
+// Subscription is one channel listed in a subscription export.
+type Subscription struct {
+	// Row is the 1-based position of the entry in the file, not counting a
+	// CSV header.
+	Row int
+	// Channel is the channel ID, URL, or handle as given in the file.
+	Channel string
+	// Title is the channel name given in the file, if any.
+	Title string
+}
+
+// ParseSubscriptions reads the channels listed in a subscription export.
+// Entries without a channel reference are skipped.
+func ParseSubscriptions(r io.Reader, format SubscriptionFormat) ([]Subscription, error) {
+	switch format {
+	case SubscriptionsOPML:
+		return parseOPMLSubscriptions(r)
+	case SubscriptionsCSV:
+		return parseCSVSubscriptions(r)
+	case SubscriptionsTakeout:
+		return parseTakeoutSubscriptions(r)
+	default:
+		return nil, fmt.Errorf("%w: unknown subscription format %q", ErrInvalidURL, format)
+	}
+}
+
+// opmlOutline is an OPML outline element. Feed lists nest channel
+// outlines inside a folder outline.
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	HTMLURL  string        `xml:"htmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+func parseOPMLSubscriptions(r io.Reader) ([]Subscription, error) {
+	var doc struct {
+		Body struct {
+			Outlines []opmlOutline `xml:"outline"`
+		} `xml:"body"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse OPML: %w", err)
+	}
+
+	var subs []Subscription
+	var walk func([]opmlOutline)
+	walk = func(outlines []opmlOutline) {
+		for _, o := range outlines {
+			walk(o.Outlines)
+			channel := o.XMLURL
+			if channel == "" {
+				channel = o.HTMLURL
+			}
+			if channel == "" {
+				continue
+			}
+			title := o.Title
+			if title == "" {
+				title = o.Text
+			}
+			subs = append(subs, Subscription{Row: len(subs) + 1, Channel: channel, Title: title})
+		}
+	}
+	walk(doc.Body.Outlines)
+	return subs, nil
+}
+
+func parseCSVSubscriptions(r io.Reader) ([]Subscription, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	// The first row is a header unless it already names a channel
+	header := true
+	for _, cell := range records[0] {
+		if isChannelReference(cell) {
+			header = false
+		}
+	}
+
+	// Prefer the ID column, then the URL column, for the channel reference
+	idCol, urlCol, titleCol := -1, -1, -1
+	for i, name := range records[0] {
+		if !header {
+			break
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case strings.Contains(name, "id"):
+			idCol = i
+		case strings.Contains(name, "url") || strings.Contains(name, "link"):
+			urlCol = i
+		case strings.Contains(name, "title") || strings.Contains(name, "name"):
+			titleCol = i
+		}
+	}
+	channelCol := idCol
+	if channelCol < 0 {
+		channelCol = urlCol
+	}
+	if channelCol >= 0 {
+		records = records[1:]
+	} else {
+		channelCol, titleCol = 0, 1
+	}
+
+	field := func(record []string, col int) string {
+		if col < 0 || col >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[col])
+	}
+	var subs []Subscription
+	for i, record := range records {
+		channel := field(record, channelCol)
+		if channel == "" {
+			channel = field(record, urlCol)
+		}
+		if channel == "" {
+			continue
+		}
+		subs = append(subs, Subscription{Row: i + 1, Channel: channel, Title: field(record, titleCol)})
+	}
+	return subs, nil
+}
+
+// isChannelReference reports whether s looks like a channel ID, URL, or handle.
+func isChannelReference(s string) bool {
+	s = strings.TrimSpace(s)
+	return extractChannelIDDirect(s) != "" || strings.HasPrefix(s, "@") || strings.Contains(s, "youtube.com/")
+}
+
+func parseTakeoutSubscriptions(r io.Reader) ([]Subscription, error) {
+	var entries []struct {
+		Snippet struct {
+			Title      string `json:"title"`
+			ResourceID struct {
+				ChannelID string `json:"channelId"`
+			} `json:"resourceId"`
+		} `json:"snippet"`
+	}
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("parse takeout subscriptions: %w", err)
+	}
+
+	var subs []Subscription
+	for i, e := range entries {
+		if e.Snippet.ResourceID.ChannelID == "" {
+			continue
+		}
+		subs = append(subs, Subscription{Row: i + 1, Channel: e.Snippet.ResourceID.ChannelID, Title: e.Snippet.Title})
+	}
+	return subs, nil
+}
+
+// ImportStatus is the outcome of importing one subscription.
+type ImportStatus string
+
+const (
+	// ImportCreated means the channel was added to the store.
+	ImportCreated ImportStatus = "created"
+	// ImportExisting means the channel was already in the store.
+	ImportExisting ImportStatus = "existing"
+	// ImportDuplicate means the channel appeared earlier in the same file.
+	ImportDuplicate ImportStatus = "duplicate"
+	// ImportFailed means the channel could not be resolved or saved.
+	ImportFailed ImportStatus = "failed"
+)
+
+// ImportResult is the outcome of importing one subscription.
+type ImportResult struct {
+	Subscription
+	// ChannelID is the resolved YouTube channel ID, if resolution succeeded.
+	ChannelID string
+	Status    ImportStatus
+	// Err is set when Status is ImportFailed.
+	Err error
+}
+
+// SubscriptionImporter creates stored channels from subscription exports.
+type SubscriptionImporter struct {
+	// Store receives the new channels.
+	Store storage.ChannelStore
+	// Resolver resolves handles and custom URLs to channel IDs. Entries that
+	// already carry a channel ID are not looked up. If nil, a default
+	// resolver is used.
+	Resolver *ChannelResolver
+	// Policy, if set, is copied to every created channel.
+	Policy *storage.SyncPolicy
+}
+
+// Import parses r and adds each listed channel to the store, returning one
+// result per entry in file order. A channel that cannot be resolved or
+// saved is reported in its result and does not stop the import; only a
+// parse failure or cancelled context does, in which case the results so
+// far are returned with the error.
+func (im *SubscriptionImporter) Import(ctx context.Context, r io.Reader, format SubscriptionFormat) ([]ImportResult, error) {
+	subs, err := ParseSubscriptions(r, format)
+	if err != nil {
+		return nil, err
+	}
+	resolver := im.Resolver
+	if resolver == nil {
+		resolver = NewChannelResolver()
+	}
+
+	seen := make(map[string]bool)
+	results := make([]ImportResult, 0, len(subs))
+	for _, sub := range subs {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := ImportResult{Subscription: sub}
+		result.ChannelID, result.Status, result.Err = im.importOne(ctx, resolver, sub, seen)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// importOne resolves and stores one subscription. seen holds the channel
+// IDs of earlier entries.
+func (im *SubscriptionImporter) importOne(ctx context.Context, resolver *ChannelResolver, sub Subscription, seen map[string]bool) (string, ImportStatus, error) {
+	channel := &storage.Channel{Name: sub.Title}
+	if id := extractChannelIDDirect(sub.Channel); id != "" {
+		channel.YouTubeID = id
+		channel.URL = "https://www.youtube.com/channel/" + id
+	} else {
+		info, err := resolver.FetchChannelInfo(ctx, sub.Channel)
+		if err != nil {
+			return "", ImportFailed, err
+		}
+		channel.YouTubeID = info.ID
+		channel.URL = info.URL
+		channel.Handle = info.Handle
+		channel.Description = info.Description
+		if channel.Name == "" {
+			channel.Name = info.Name
+		}
+	}
+
+	id := channel.YouTubeID
+	if seen[id] {
+		return id, ImportDuplicate, nil
+	}
+	seen[id] = true
+
+	if _, err := im.Store.GetChannelByYouTubeID(ctx, id); err == nil {
+		return id, ImportExisting, nil
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return id, ImportFailed, err
+	}
+
+	if im.Policy != nil {
+		policy := *im.Policy
+		channel.Policy = &policy
+	}
+	if err := im.Store.CreateChannel(ctx, channel); err != nil {
+		return id, ImportFailed, err
+	}
+	return id, ImportCreated, nil
+}
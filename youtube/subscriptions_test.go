@@ -0,0 +1,191 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"ytsync/storage"
+)
+
+const (
+	subTestID1 = "UCsBjURrPoezykLs9EqgamOA"
+	subTestID2 = "UC_x5XG1OV2P6uZZ5FSM9Ttw"
+)
+
+func TestParseSubscriptions_OPML(t *testing.T) {
+	opml := `<?xml version="1.0"?>
+<opml version="1.1">
+  <body>
+    <outline text="YouTube Subscriptions" title="YouTube Subscriptions">
+      <outline text="Fireship" title="Fireship" type="rss" xmlUrl="https://www.youtube.com/feeds/videos.xml?channel_id=` + subTestID1 + `"/>
+      <outline text="Google for Developers" htmlUrl="https://www.youtube.com/channel/` + subTestID2 + `"/>
+    </outline>
+  </body>
+</opml>`
+
+	subs, err := ParseSubscriptions(strings.NewReader(opml), SubscriptionsOPML)
+	if err != nil {
+		t.Fatalf("ParseSubscriptions() error = %v", err)
+	}
+	if len(subs) != 2 {
+		t.Fatalf("got %d subscriptions, want 2: %+v", len(subs), subs)
+	}
+	if subs[0].Title != "Fireship" || !strings.Contains(subs[0].Channel, subTestID1) || subs[0].Row != 1 {
+		t.Errorf("subs[0] = %+v", subs[0])
+	}
+	if subs[1].Title != "Google for Developers" || !strings.Contains(subs[1].Channel, subTestID2) {
+		t.Errorf("subs[1] = %+v", subs[1])
+	}
+}
+
+func TestParseSubscriptions_CSV(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Subscription
+	}{
+		{
+			name:  "takeout header",
+			input: "Channel Id,Channel Url,Channel Title\n" + subTestID1 + ",http://www.youtube.com/channel/" + subTestID1 + ",Fireship\n\n" + subTestID2 + ",,Google\n",
+			want: []Subscription{
+				{Row: 1, Channel: subTestID1, Title: "Fireship"},
+				{Row: 2, Channel: subTestID2, Title: "Google"},
+			},
+		},
+		{
+			name:  "no header",
+			input: "@Fireship,Fireship\nhttps://www.youtube.com/channel/" + subTestID2 + "\n",
+			want: []Subscription{
+				{Row: 1, Channel: "@Fireship", Title: "Fireship"},
+				{Row: 2, Channel: "https://www.youtube.com/channel/" + subTestID2},
+			},
+		},
+		{
+			name:  "url column only",
+			input: "name,link\nFireship,https://www.youtube.com/@Fireship\n",
+			want: []Subscription{
+				{Row: 1, Channel: "https://www.youtube.com/@Fireship", Title: "Fireship"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs, err := ParseSubscriptions(strings.NewReader(tt.input), SubscriptionsCSV)
+			if err != nil {
+				t.Fatalf("ParseSubscriptions() error = %v", err)
+			}
+			if len(subs) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", subs, tt.want)
+			}
+			for i := range subs {
+				if subs[i] != tt.want[i] {
+					t.Errorf("subs[%d] = %+v, want %+v", i, subs[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseSubscriptions_Takeout(t *testing.T) {
+	input := `[
+  {"snippet": {"title": "Fireship", "resourceId": {"kind": "youtube#channel", "channelId": "` + subTestID1 + `"}}},
+  {"snippet": {"title": "Broken"}},
+  {"snippet": {"title": "Google", "resourceId": {"kind": "youtube#channel", "channelId": "` + subTestID2 + `"}}}
+]`
+
+	subs, err := ParseSubscriptions(strings.NewReader(input), SubscriptionsTakeout)
+	if err != nil {
+		t.Fatalf("ParseSubscriptions() error = %v", err)
+	}
+	want := []Subscription{
+		{Row: 1, Channel: subTestID1, Title: "Fireship"},
+		{Row: 3, Channel: subTestID2, Title: "Google"},
+	}
+	if len(subs) != len(want) || subs[0] != want[0] || subs[1] != want[1] {
+		t.Errorf("got %+v, want %+v", subs, want)
+	}
+}
+
+func TestParseSubscriptions_Errors(t *testing.T) {
+	if _, err := ParseSubscriptions(strings.NewReader(""), "yaml"); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("unknown format error = %v, want ErrInvalidURL", err)
+	}
+	if _, err := ParseSubscriptions(strings.NewReader("{"), SubscriptionsTakeout); err == nil {
+		t.Error("expected error for malformed takeout JSON")
+	}
+}
+
+func TestSubscriptionImporter_Import(t *testing.T) {
+	store := newEnrichTestStore(t)
+	ctx := context.Background()
+
+	if err := store.CreateChannel(ctx, &storage.Channel{YouTubeID: subTestID2, Name: "Google"}); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+
+	resolver := NewChannelResolver()
+	resolver.HTTPClient = newMockHTTPClient(http.StatusNotFound, "")
+	importer := &SubscriptionImporter{
+		Store:    store,
+		Resolver: resolver,
+		Policy:   &storage.SyncPolicy{ContentType: "videos", Transcripts: true},
+	}
+
+	input := "Channel Id,Channel Title\n" +
+		subTestID1 + ",Fireship\n" +
+		subTestID2 + ",Google\n" +
+		"https://www.youtube.com/channel/" + subTestID1 + ",Fireship again\n" +
+		"@missing,Missing\n"
+	results, err := importer.Import(ctx, strings.NewReader(input), SubscriptionsCSV)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	want := []ImportStatus{ImportCreated, ImportExisting, ImportDuplicate, ImportFailed}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("results[%d].Status = %s, want %s (err %v)", i, r.Status, want[i], r.Err)
+		}
+	}
+	if results[3].Err == nil {
+		t.Error("failed result has no error")
+	}
+	if results[2].ChannelID != subTestID1 {
+		t.Errorf("duplicate ChannelID = %q, want %q", results[2].ChannelID, subTestID1)
+	}
+
+	created, err := store.GetChannelByYouTubeID(ctx, subTestID1)
+	if err != nil {
+		t.Fatalf("GetChannelByYouTubeID() error = %v", err)
+	}
+	if created.Name != "Fireship" {
+		t.Errorf("Name = %q, want Fireship", created.Name)
+	}
+	if created.Policy == nil || !created.Policy.Transcripts {
+		t.Errorf("Policy = %+v, want copy of importer policy", created.Policy)
+	}
+	if created.Policy == importer.Policy {
+		t.Error("Policy is shared with the importer, want a copy")
+	}
+}
+
+func TestSubscriptionImporter_Cancelled(t *testing.T) {
+	store := newEnrichTestStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	importer := &SubscriptionImporter{Store: store}
+	results, err := importer.Import(ctx, strings.NewReader(subTestID1+"\n"), SubscriptionsCSV)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Import() error = %v, want context.Canceled", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("got %d results, want 0", len(results))
+	}
+}
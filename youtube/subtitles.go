@@ -0,0 +1,117 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"ytsync/media"
+)
+
+// SubtitleMode controls how downloaded subtitles are added to a video.
+type SubtitleMode string
+
+const (
+	// SubtitlesEmbed adds subtitles as selectable soft-subtitle streams.
+	// Audio and video are copied, so this is fast and lossless.
+	SubtitlesEmbed SubtitleMode = "embed"
+	// SubtitlesBurn draws subtitles onto the video frames. The video is
+	// re-encoded, so this is slow, and the subtitles cannot be turned off.
+	SubtitlesBurn SubtitleMode = "burn"
+)
+
+// SubtitleOptions configures subtitles included in a downloaded video.
+type SubtitleOptions struct {
+	// Languages lists the subtitle languages to include, e.g. ["en", "de"].
+	// SubtitlesBurn uses the first language that is available.
+	Languages []string `json:"languages"`
+	// Mode is how subtitles are added. Defaults to SubtitlesEmbed.
+	Mode SubtitleMode `json:"mode,omitempty"`
+	// Auto falls back to auto-generated captions for languages without
+	// uploaded subtitles.
+	Auto bool `json:"auto,omitempty"`
+	// Container, if set, is the extension of the final file, e.g. "mkv".
+	// Matroska holds every subtitle format; MP4 converts them to mov_text.
+	Container string `json:"container,omitempty"`
+	// KeepFiles keeps the downloaded subtitle files next to the video.
+	KeepFiles bool `json:"keep_files,omitempty"`
+}
+
+// ytdlpArgs returns the yt-dlp arguments that write the subtitle files
+// next to the video.
+func (o *SubtitleOptions) ytdlpArgs() []string {
+	args := []string{"--write-subs", "--sub-langs", strings.Join(o.Languages, ","), "--sub-format", "vtt/srt/best"}
+	if o.Auto {
+		args = append(args, "--write-auto-subs")
+	}
+	return args
+}
+
+// subtitleExts are the subtitle file extensions yt-dlp may write.
+var subtitleExts = []string{".vtt", ".srt", ".ass"}
+
+// findSubtitleFiles returns the subtitle tracks yt-dlp wrote next to
+// videoPath, named <base>.<lang>.<ext>, in the order of langs.
+func findSubtitleFiles(videoPath string, langs []string) []media.SubtitleTrack {
+	base := strings.TrimSuffix(videoPath, filepath.Ext(videoPath))
+	var tracks []media.SubtitleTrack
+	for _, lang := range langs {
+		for _, ext := range subtitleExts {
+			path := base + "." + lang + ext
+			if fileExists(path) {
+				tracks = append(tracks, media.SubtitleTrack{Path: path, Language: lang})
+				break
+			}
+		}
+	}
+	return tracks
+}
+
+// addSubtitles muxes or burns the subtitle files downloaded for videoPath
+// into it and returns the path of the resulting video, which differs from
+// videoPath if opts.Container changed the extension. Returns ErrNoTranscript
+// if none of the requested languages were downloaded.
+func (d *Downloader) addSubtitles(ctx context.Context, videoPath string, opts *SubtitleOptions) (string, error) {
+	tracks := findSubtitleFiles(videoPath, opts.Languages)
+	if len(tracks) == 0 {
+		return videoPath, fmt.Errorf("add subtitles: %w in %s", ErrNoTranscript, strings.Join(opts.Languages, ", "))
+	}
+	if !opts.KeepFiles {
+		defer func() {
+			for _, t := range tracks {
+				os.Remove(t.Path)
+			}
+		}()
+	}
+
+	out := videoPath
+	if opts.Container != "" {
+		out = strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + "." + strings.TrimPrefix(opts.Container, ".")
+	}
+	// ffmpeg cannot write over its input, so write beside it and rename
+	tmp := strings.TrimSuffix(out, filepath.Ext(out)) + ".subs" + filepath.Ext(out)
+
+	var err error
+	switch opts.Mode {
+	case SubtitlesEmbed, "":
+		err = d.mediaTool().MuxSubtitles(ctx, videoPath, tmp, tracks)
+	case SubtitlesBurn:
+		err = d.mediaTool().BurnSubtitles(ctx, videoPath, tmp, tracks[0])
+	default:
+		err = fmt.Errorf("unknown subtitle mode %q", opts.Mode)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return videoPath, fmt.Errorf("add subtitles: %w", err)
+	}
+
+	if err := os.Rename(tmp, out); err != nil {
+		os.Remove(tmp)
+		return videoPath, fmt.Errorf("add subtitles: %w", err)
+	}
+	if out != videoPath {
+		os.Remove(videoPath)
+	}
+	return out, nil
+}
@@ -0,0 +1,129 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"ytsync/media"
+)
+
+// fakeSubtitler records subtitle operations and writes the output file.
+// Other media operations are not implemented.
+type fakeSubtitler struct {
+	media.Tool
+	muxed  []media.SubtitleTrack
+	burned *media.SubtitleTrack
+	in     string
+}
+
+func (f *fakeSubtitler) MuxSubtitles(ctx context.Context, in, out string, tracks []media.SubtitleTrack) error {
+	f.in, f.muxed = in, tracks
+	return os.WriteFile(out, []byte("muxed"), 0644)
+}
+
+func (f *fakeSubtitler) BurnSubtitles(ctx context.Context, in, out string, track media.SubtitleTrack) error {
+	f.in, f.burned = in, &track
+	return os.WriteFile(out, []byte("burned"), 0644)
+}
+
+func writeSubtitleFixture(t *testing.T, dir string, names ...string) string {
+	t.Helper()
+	video := filepath.Join(dir, "Test Video.mp4")
+	for _, name := range append([]string{"Test Video.mp4"}, names...) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return video
+}
+
+func TestDownloader_AddSubtitles_Embed(t *testing.T) {
+	dir := t.TempDir()
+	video := writeSubtitleFixture(t, dir, "Test Video.en.vtt", "Test Video.de.srt")
+	fake := &fakeSubtitler{}
+	d := &Downloader{Media: fake}
+
+	out, err := d.addSubtitles(context.Background(), video, &SubtitleOptions{
+		Languages: []string{"de", "fr", "en"},
+		Container: "mkv",
+	})
+	if err != nil {
+		t.Fatalf("addSubtitles() error = %v", err)
+	}
+
+	if want := filepath.Join(dir, "Test Video.mkv"); out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "muxed" {
+		t.Errorf("output content = %q, want muxed file", data)
+	}
+	want := []media.SubtitleTrack{
+		{Path: filepath.Join(dir, "Test Video.de.srt"), Language: "de"},
+		{Path: filepath.Join(dir, "Test Video.en.vtt"), Language: "en"},
+	}
+	if !reflect.DeepEqual(fake.muxed, want) {
+		t.Errorf("tracks = %+v, want %+v", fake.muxed, want)
+	}
+	for _, path := range []string{video, want[0].Path, want[1].Path} {
+		if fileExists(path) {
+			t.Errorf("%s should have been removed", filepath.Base(path))
+		}
+	}
+}
+
+func TestDownloader_AddSubtitles_Burn(t *testing.T) {
+	dir := t.TempDir()
+	video := writeSubtitleFixture(t, dir, "Test Video.en.vtt", "Test Video.es.vtt")
+	fake := &fakeSubtitler{}
+	d := &Downloader{Media: fake}
+
+	out, err := d.addSubtitles(context.Background(), video, &SubtitleOptions{
+		Languages: []string{"es", "en"},
+		Mode:      SubtitlesBurn,
+		KeepFiles: true,
+	})
+	if err != nil {
+		t.Fatalf("addSubtitles() error = %v", err)
+	}
+	if out != video || fake.in != video {
+		t.Errorf("output = %q from %q, want the video replaced in place", out, fake.in)
+	}
+	if data, _ := os.ReadFile(video); string(data) != "burned" {
+		t.Errorf("video content = %q, want burned file", data)
+	}
+	if fake.burned == nil || fake.burned.Language != "es" {
+		t.Errorf("burned track = %+v, want es", fake.burned)
+	}
+	if !fileExists(filepath.Join(dir, "Test Video.en.vtt")) {
+		t.Error("subtitle files removed despite KeepFiles")
+	}
+	if fileExists(filepath.Join(dir, "Test Video.subs.mp4")) {
+		t.Error("temporary file left behind")
+	}
+}
+
+func TestDownloader_AddSubtitles_Missing(t *testing.T) {
+	dir := t.TempDir()
+	video := writeSubtitleFixture(t, dir)
+	d := &Downloader{Media: &fakeSubtitler{}}
+
+	out, err := d.addSubtitles(context.Background(), video, &SubtitleOptions{Languages: []string{"en"}})
+	if !errors.Is(err, ErrNoTranscript) {
+		t.Errorf("addSubtitles() error = %v, want ErrNoTranscript", err)
+	}
+	if out != video {
+		t.Errorf("output = %q, want original video", out)
+	}
+}
+
+func TestSubtitleOptions_YtdlpArgs(t *testing.T) {
+	got := strings.Join((&SubtitleOptions{Languages: []string{"en", "de"}, Auto: true}).ytdlpArgs(), " ")
+	want := "--write-subs --sub-langs en,de --sub-format vtt/srt/best --write-auto-subs"
+	if got != want {
+		t.Errorf("ytdlpArgs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package youtube
+
+import (
+	"context"
+	"time"
+	"ytsync/retry"
+	"ytsync/tags"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before
+// it misses events.
+const subscriberBuffer = 256
+
+// SyncEventKind says what a SyncEvent reports.
+type SyncEventKind string
+
+const (
+	// EventSyncStarted starts the sync of a channel.
+	EventSyncStarted SyncEventKind = "sync_started"
+	// EventPhase starts a phase: "rss", "count", "full", or "enrich".
+	EventPhase SyncEventKind = "phase"
+	// EventPage reports a listed page.
+	EventPage SyncEventKind = "page"
+	// EventTranscriptFetched reports a transcript fetched for VideoID.
+	EventTranscriptFetched SyncEventKind = "transcript_fetched"
+	// EventVideoStored reports VideoID written to the enrichment's store.
+	EventVideoStored SyncEventKind = "video_stored"
+	// EventRetry reports a failed request that will be retried after
+	// Backoff.
+	EventRetry SyncEventKind = "retry"
+	// EventError reports an error the sync recovered from or failed with.
+	EventError SyncEventKind = "error"
+	// EventSyncFinished ends the sync of a channel, with Err set if it
+	// failed or was interrupted.
+	EventSyncFinished SyncEventKind = "sync_finished"
+)
+
+// SyncEvent is one step of a SyncManager's work, delivered to subscribers
+// as it happens. Page and Videos are the counts of the current phase, as
+// in SyncProgress, when the event was sent.
+type SyncEvent struct {
+	// Kind says what happened.
+	Kind SyncEventKind
+	// Time is when it happened.
+	Time time.Time
+	// Channel is the YouTube ID of the channel being synced.
+	Channel string
+	// Phase is the current phase, or empty before the first.
+	Phase string
+	// Page is the number of pages listed in the phase.
+	Page int
+	// Videos is the number of videos listed, or enriched, in the phase.
+	Videos int
+	// VideoID is the video a transcript was fetched or stored for, or a
+	// retried request was made for.
+	VideoID string
+	// Attempt is the number of the failed attempt, for EventRetry.
+	Attempt int
+	// Backoff is the wait before the next attempt, for EventRetry.
+	Backoff time.Duration
+	// Err is the error of EventRetry, EventError, and a failed
+	// EventSyncFinished.
+	Err error
+}
+
+// Subscribe returns a channel receiving an event for each step of the
+// manager's syncs until ctx is done, when it is closed. Events are sent
+// without waiting: a subscriber more than 256 events behind misses events
+// rather than slowing the sync, and Progress still gives the totals.
+func (sm *SyncManager) Subscribe(ctx context.Context) <-chan SyncEvent {
+	return sm.progress.subscribe(ctx)
+}
+
+// subscribe registers a subscriber until ctx is done.
+func (t *progressTracker) subscribe(ctx context.Context) <-chan SyncEvent {
+	ch := make(chan SyncEvent, subscriberBuffer)
+	t.mu.Lock()
+	if t.subscribers == nil {
+		t.subscribers = make(map[chan SyncEvent]struct{})
+	}
+	t.subscribers[ch] = struct{}{}
+	t.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.subscribers, ch)
+		close(ch)
+	})
+	return ch
+}
+
+// emit sends e, filled in from the progress, to every subscriber that has
+// room for it. The caller must hold t.mu.
+func (t *progressTracker) emit(e SyncEvent) {
+	if len(t.subscribers) == 0 {
+		return
+	}
+	e.Time = t.progress.UpdatedAt
+	e.Channel = t.progress.Channel
+	e.Phase = t.progress.Phase
+	e.Page = t.progress.Page
+	e.Videos = t.progress.VideosProcessed
+	for ch := range t.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// retried records a failed attempt that will be retried.
+func (t *progressTracker) retried(ctx context.Context, attempt retry.Attempt) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.UpdatedAt = time.Now()
+	t.emit(SyncEvent{
+		Kind:    EventRetry,
+		VideoID: tags.From(ctx).Get(tags.Video),
+		Attempt: attempt.Number,
+		Backoff: attempt.Backoff,
+		Err:     attempt.Err,
+	})
+}
+
+// enriched records a video done with enrichment. stored tells whether the
+// enrichment persists videos.
+func (t *progressTracker) enriched(r *EnrichResult, stored bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.VideosProcessed++
+	t.progress.UpdatedAt = time.Now()
+	if r.Transcript != nil {
+		t.emit(SyncEvent{Kind: EventTranscriptFetched, VideoID: r.VideoID})
+	}
+	if stored && r.Errors[StagePersist] == nil {
+		t.emit(SyncEvent{Kind: EventVideoStored, VideoID: r.VideoID})
+	}
+}
+
+// enrichOptions returns a copy of opts whose OnResult callback also records
+// each enriched video. The caller's callback, if any, still runs.
+func (t *progressTracker) enrichOptions(opts *EnrichOptions) *EnrichOptions {
+	tracked := *opts
+	next := tracked.OnResult
+	tracked.OnResult = func(r *EnrichResult) {
+		t.enriched(r, opts.Store != nil)
+		if next != nil {
+			next(r)
+		}
+	}
+	return &tracked
+}
@@ -2,10 +2,14 @@ package youtube
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"time"
+	ythttp "ytsync/http"
+	"ytsync/lease"
+	"ytsync/retry"
 	"ytsync/storage"
+	"ytsync/tags"
 )
 
 // SyncManager orchestrates incremental video synchronization for YouTube channels.
@@ -15,7 +19,13 @@ type SyncManager struct {
 	rssLister    *RSSLister
 	fallbackList VideoLister
 	store        storage.SyncStateStore
+	reports      storage.SyncReportStore
+	enrichment   *EnrichOptions
+	counter      VideoCounter
+	locker       *lease.Locker
 	maxRetries   int
+	limiter      *ythttp.RateLimiter
+	progress     progressTracker
 }
 
 // NewSyncManager creates a new sync manager with default listers.
@@ -38,6 +48,41 @@ func NewSyncManagerWithListers(rssLister *RSSLister, fallback VideoLister, store
 	}
 }
 
+// SetReportStore enables saving a SyncReport to store after every sync.
+// Pass nil to stop saving reports.
+func (sm *SyncManager) SetReportStore(store storage.SyncReportStore) {
+	sm.reports = store
+}
+
+// SetEnrichment enables fetching metadata and transcripts for newly
+// discovered videos after each successful sync. When opts.TranscriptRetry
+// has a PollSchedule, each sync also asks again for the transcripts of the
+// channel's earlier videos that are still polling and due. Pass nil to
+// disable.
+func (sm *SyncManager) SetEnrichment(opts *EnrichOptions) {
+	sm.enrichment = opts
+}
+
+// SetVideoCounter enables reading the channel's video count before a full
+// sync. The count is saved in SyncState.TotalVideos after each full sync
+// and kept up to date by incremental syncs. When the RSS feed shows a gap
+// but the count grew by exactly the number of new videos in the feed, or
+// did not change while the feed shows nothing new, no video was missed and
+// the full listing is skipped. Pass nil to disable.
+func (sm *SyncManager) SetVideoCounter(counter VideoCounter) {
+	sm.counter = counter
+}
+
+// SetLocker makes every sync take the lease "channel/<channel ID>" from
+// locker first, so that instances on several hosts sharing a channel list
+// do not sync a channel at once. A sync of a channel whose lease another
+// instance holds fails at once with an error matching lease.ErrHeld. A sync
+// that loses its lease stops as if ctx were canceled, saving its
+// checkpoint. Pass nil to disable.
+func (sm *SyncManager) SetLocker(locker *lease.Locker) {
+	sm.locker = locker
+}
+
 // SyncResult contains the outcome of a sync operation.
 type SyncResult struct {
 	// Videos is the list of videos discovered during this sync.
@@ -52,6 +97,15 @@ type SyncResult struct {
 	GapDetected bool
 	// TimeSynced is the timestamp of the newest video in this sync.
 	TimeSynced time.Time
+	// Report summarizes the sync run for auditing.
+	Report *storage.SyncReport
+	// Enriched holds the enrichment outcome for each new video, if
+	// enrichment is enabled.
+	Enriched []*EnrichResult
+	// PolledTranscripts holds the outcome for each earlier video whose
+	// transcript was asked for again under the enrichment's
+	// TranscriptRetry.PollSchedule.
+	PolledTranscripts []*EnrichResult
 }
 
 // SyncChannelVideos performs an efficient sync of channel videos.
@@ -59,16 +113,46 @@ type SyncResult struct {
 // 1. This is the first sync (no prior sync state)
 // 2. A gap is detected in the RSS feed
 // 3. The fallback lister supports full history and no recent videos were found
-func (sm *SyncManager) SyncChannelVideos(ctx context.Context, channelURL string, opts *ListOptions) (*SyncResult, error) {
+//
+// Requests, logs, and errors of the sync are tagged with the channel ID, the
+// phase, and a run ID, which is generated unless ctx already has one and is
+// saved in the report. Its steps, including retried requests, are sent to
+// subscribers (see Subscribe).
+//
+// A sync that fails or is interrupted once it has started still returns a
+// result with the report of the failed run, along with the error; its
+// other fields are empty.
+func (sm *SyncManager) SyncChannelVideos(ctx context.Context, channelURL string, opts *ListOptions) (_ *SyncResult, err error) {
 	// Extract channel ID for state tracking
 	channelID, err := extractChannelID(channelURL)
 	if err != nil {
 		return nil, fmt.Errorf("extract channel ID: %w", err)
 	}
+	ctx = tags.WithChannel(tags.Default(ctx, tags.Run, tags.NewRunID()), channelID)
+
+	if sm.locker != nil {
+		held, err := sm.locker.Acquire(ctx, "channel/"+channelID)
+		if err != nil {
+			return nil, fmt.Errorf("lease channel %s: %w", channelID, err)
+		}
+		defer held.Release(context.WithoutCancel(ctx))
+		var cancel context.CancelFunc
+		ctx, cancel = held.Context(ctx)
+		defer cancel()
+	}
+
+	sm.progress.begin(channelID)
+	defer func() { sm.progress.end(err) }()
+	ctx = retry.WithObserver(ctx, sm.progress.retried)
+
+	report := storage.NewSyncReport(channelID, channelURL)
+	report.RunID = tags.From(ctx).Get(tags.Run)
+	opts = trackListOptions(opts, report)
+	opts = sm.progress.listOptions(opts)
 
 	// Get or create sync state
 	syncState, err := sm.store.GetSyncState(ctx, channelID)
-	if err != nil && err != storage.ErrNotFound {
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
 		return nil, fmt.Errorf("get sync state: %w", err)
 	}
 	if syncState == nil {
@@ -76,57 +160,167 @@ func (sm *SyncManager) SyncChannelVideos(ctx context.Context, channelURL string,
 		syncState = storage.NewSyncState(channelID)
 	}
 
-	// Check if we should resume from a token
+	// The state as loaded, restored if the sync is interrupted before it
+	// records any progress of its own
+	saved := *syncState
+
+	// Resume an interrupted full sync from its checkpoint if the fallback
+	// lister pages the same way
 	if syncState.CanResume() {
-		log.Printf("ytsync: resuming sync for channel %s from token", channelID)
-		return sm.resumeSync(ctx, syncState, opts)
+		if listerStrategy(sm.fallbackList) == syncState.Strategy {
+			tags.Logf(ctx, "ytsync: resuming %s sync for channel %s after %d videos", syncState.Strategy, channelID, syncState.VideosProcessed)
+			return sm.fullSync(ctx, channelURL, syncState, &saved, report, opts, true, 0)
+		}
+		tags.Logf(ctx, "ytsync: discarding %s checkpoint for channel %s, fallback lister cannot resume it", syncState.Strategy, channelID)
 	}
 
 	// Attempt incremental RSS sync first
-	rssResult, err := sm.attemptIncrementalSync(ctx, channelURL, syncState, opts)
+	phaseStart := time.Now()
+	sm.progress.phase("rss")
+	rssResult, newInFeed, err := sm.attemptIncrementalSync(tags.WithOperation(ctx, "rss"), channelURL, syncState, opts)
+	report.Requests++
+	report.AddPhase("rss", phaseStart)
+	if interrupted(ctx, err) {
+		return sm.interrupt(ctx, syncState, &saved, report, nil)
+	}
 	if err != nil {
 		// Log error but continue to full sync fallback
-		log.Printf("ytsync: incremental sync failed for %s: %v", channelID, err)
+		tags.Logf(ctx, "ytsync: incremental sync failed for %s: %v", channelID, err)
+		sm.progress.fail(err)
 	} else if rssResult != nil && !rssResult.GapDetected {
 		// Incremental sync succeeded and no gap - persist state and return
 		syncState.UpdateRSSState(rssResult.TimeSynced, false)
 		syncState.CompleteSync()
-		if err := sm.store.UpdateSyncState(ctx, syncState); err != nil {
-			log.Printf("ytsync: failed to persist sync state: %v", err)
+		if syncState.TotalVideos > 0 {
+			// Keep the saved count in step with the videos synced since
+			syncState.TotalVideos += newInFeed
 		}
-		return rssResult, nil
+		sm.persistState(ctx, syncState, report)
+		return sm.finishReport(ctx, report, syncState, &saved, rssResult, nil), nil
 	}
-	
+
 	// If we get here, either incremental failed or gap was detected
 	if rssResult != nil && rssResult.GapDetected {
-		log.Printf("ytsync: gap detected in RSS feed for %s, performing full sync", channelID)
+		tags.Logf(ctx, "ytsync: gap detected in RSS feed for %s, performing full sync", channelID)
+		report.GapDetected = true
+	}
+
+	// Check the video count before paging through the whole channel
+	count, err := sm.videoCount(ctx, channelURL, report)
+	if interrupted(ctx, err) {
+		return sm.interrupt(ctx, syncState, &saved, report, nil)
+	}
+	if err != nil {
+		tags.Logf(ctx, "ytsync: video count unavailable for %s: %v", channelID, err)
+	} else if rssResult != nil && saved.TotalVideos > 0 && count == saved.TotalVideos+newInFeed {
+		tags.Logf(ctx, "ytsync: video count of %s accounts for every new video in the RSS feed, skipping full sync", channelID)
+		report.ListingSkipped = true
+		syncState.UpdateRSSState(rssResult.TimeSynced, false)
+		syncState.CompleteSync()
+		syncState.TotalVideos = count
+		sm.persistState(ctx, syncState, report)
+		return sm.finishReport(ctx, report, syncState, &saved, rssResult, nil), nil
 	}
 
 	// Perform full sync as fallback or when gap detected
-	fullResult, err := sm.performFullSync(ctx, channelURL, syncState, opts)
+	return sm.fullSync(ctx, channelURL, syncState, &saved, report, opts, false, count)
+}
+
+// videoCount reads the channel's video count with the configured
+// VideoCounter. It returns 0 and ErrVideoCountUnavailable if there is none.
+func (sm *SyncManager) videoCount(ctx context.Context, channelURL string, report *storage.SyncReport) (int, error) {
+	if sm.counter == nil {
+		return 0, ErrVideoCountUnavailable
+	}
+	phaseStart := time.Now()
+	sm.progress.phase("count")
+	count, err := sm.counter.VideoCount(tags.WithOperation(ctx, "count"), channelURL)
+	report.Requests++
+	report.AddPhase("count", phaseStart)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// fullSync runs the full sync phase, from the start or from the checkpoint
+// in syncState, and persists its outcome. count is the channel's video count
+// read before the sync, or 0 if it is unknown, and is saved as the baseline
+// for the next sync's count check once the sync succeeds.
+func (sm *SyncManager) fullSync(ctx context.Context, channelURL string, syncState, saved *storage.SyncState, report *storage.SyncReport, opts *ListOptions, resume bool, count int) (*SyncResult, error) {
+	phaseStart := time.Now()
+	requestsBefore := report.Requests
+	sm.progress.phase("full")
+	fullResult, err := sm.performFullSync(tags.WithOperation(ctx, "full"), channelURL, syncState, opts, resume)
+	if report.Requests == requestsBefore && sm.fallbackList != nil {
+		// Listers that do not report pages still made a request
+		report.Requests++
+	}
+	report.AddPhase("full", phaseStart)
+	if interrupted(ctx, err) {
+		var partial []VideoInfo
+		if fullResult != nil {
+			partial = fullResult.Videos
+		}
+		return sm.interrupt(ctx, syncState, saved, report, partial)
+	}
 	if err != nil {
 		// Fail sync but preserve state for potential resume
+		sm.progress.fail(err)
 		syncState.FailSync(fmt.Sprintf("full sync failed: %v", err))
-		if err := sm.store.UpdateSyncState(ctx, syncState); err != nil {
-			log.Printf("ytsync: failed to persist error state: %v", err)
-		}
-		return nil, fmt.Errorf("full sync failed: %w", err)
+		// The videos since the saved count are not all synced
+		syncState.TotalVideos = 0
+		sm.persistState(ctx, syncState, report)
+		err = fmt.Errorf("full sync failed: %w", err)
+		return sm.finishReport(ctx, report, syncState, saved, nil, err), err
 	}
 
 	// Update state after successful full sync
 	syncState.CompleteSync()
 	syncState.NewestVideoTimestamp = fullResult.TimeSynced
+	if resume && saved.NewestVideoTimestamp.After(fullResult.TimeSynced) {
+		// A resumed sync lists only the older pages, so keep the watermark
+		// from before the interrupted run
+		syncState.NewestVideoTimestamp = saved.NewestVideoTimestamp
+	}
 	syncState.RSSRequiresFullSync = false
+	syncState.TotalVideos = count
 
-	if err := sm.store.UpdateSyncState(ctx, syncState); err != nil {
-		log.Printf("ytsync: failed to persist sync state: %v", err)
+	sm.persistState(ctx, syncState, report)
+	return sm.finishReport(ctx, report, syncState, saved, fullResult, nil), nil
+}
+
+// interrupt persists a checkpoint for a sync stopped by ctx being cancelled
+// and returns the error wrapping a ResumableError. A sync with a page token
+// stays in the syncing status so the next sync resumes it; one that got no
+// further than its first page leaves the state as it was loaded. Either way
+// the RSS watermark from before the sync is kept, so the next incremental
+// sync does not miss videos. The returned result holds only the report.
+func (sm *SyncManager) interrupt(ctx context.Context, syncState, saved *storage.SyncState, report *storage.SyncReport, partial []VideoInfo) (*SyncResult, error) {
+	cause := context.Cause(ctx)
+	checkpoint := checkpointOf(syncState)
+	if checkpoint.Resumable() {
+		syncState.NewestVideoTimestamp = saved.NewestVideoTimestamp
+		syncState.RSSRequiresFullSync = saved.RSSRequiresFullSync
+		syncState.LastError = fmt.Sprintf("interrupted: %v", cause)
+	} else {
+		*syncState = *saved
 	}
 
-	return fullResult, nil
+	// The caller's context is done, but the checkpoint must still be saved
+	sm.persistState(context.WithoutCancel(ctx), syncState, report)
+	err := fmt.Errorf("sync interrupted: %w", &ResumableError{
+		Checkpoint: checkpoint,
+		Videos:     partial,
+		Err:        cause,
+	})
+	return sm.finishReport(context.WithoutCancel(ctx), report, syncState, saved, nil, err), err
 }
 
-// attemptIncrementalSync performs an incremental RSS sync.
-func (sm *SyncManager) attemptIncrementalSync(ctx context.Context, channelURL string, syncState *storage.SyncState, opts *ListOptions) (*SyncResult, error) {
+// attemptIncrementalSync performs an incremental RSS sync. It also returns
+// the number of videos in the feed newer than the last sync before opts'
+// filters are applied.
+func (sm *SyncManager) attemptIncrementalSync(ctx context.Context, channelURL string, syncState *storage.SyncState, opts *ListOptions) (*SyncResult, int, error) {
 	// Determine last sync time BEFORE clearing state (StartSync clears NewestVideoTimestamp)
 	var lastSyncTime time.Time
 	if !syncState.NewestVideoTimestamp.IsZero() {
@@ -138,8 +332,9 @@ func (sm *SyncManager) attemptIncrementalSync(ctx context.Context, channelURL st
 	// Perform incremental RSS fetch
 	rssResult, err := sm.rssLister.ListVideosIncremental(ctx, channelURL, lastSyncTime, opts)
 	if err != nil {
-		return nil, fmt.Errorf("rss incremental fetch failed: %w", err)
+		return nil, 0, fmt.Errorf("rss incremental fetch failed: %w", err)
 	}
+	sm.progress.videos(len(rssResult.Videos))
 
 	// Update sync state with RSS progress
 	syncState.UpdateRSSState(rssResult.NewestTimestamp, rssResult.GapDetected)
@@ -150,22 +345,37 @@ func (sm *SyncManager) attemptIncrementalSync(ctx context.Context, channelURL st
 		IsIncremental:  true,
 		GapDetected:    rssResult.GapDetected,
 		TimeSynced:     rssResult.NewestTimestamp,
-	}, nil
+	}, rssResult.NewInFeed, nil
 }
 
 // performFullSync performs a complete channel sync using the fallback lister.
-func (sm *SyncManager) performFullSync(ctx context.Context, channelURL string, syncState *storage.SyncState, opts *ListOptions) (*SyncResult, error) {
+// With resume set, listing continues from the checkpoint in syncState
+// instead of starting over. Each page's token is recorded in syncState as it
+// is fetched. On error the result, if non-nil, holds the videos the lister
+// returned before failing.
+func (sm *SyncManager) performFullSync(ctx context.Context, channelURL string, syncState *storage.SyncState, opts *ListOptions, resume bool) (*SyncResult, error) {
 	if sm.fallbackList == nil {
 		return nil, fmt.Errorf("no fallback lister configured for full sync")
 	}
 
-	syncState.StartSync(storage.StrategyYtdlp)
+	if resume {
+		syncState.LastError = ""
+	} else {
+		syncState.StartSync(listerStrategy(sm.fallbackList))
+	}
+	opts = checkpointOptions(opts, syncState)
+	if resume {
+		checkpoint := checkpointOf(syncState)
+		opts.ResumeToken = checkpoint.Token
+		opts.ResumePlaylistID = checkpoint.PlaylistID
+	}
 
 	// Perform full listing
 	videos, err := sm.fallbackList.ListVideos(ctx, channelURL, opts)
 	if err != nil {
-		return nil, fmt.Errorf("fallback full sync failed: %w", err)
+		return &SyncResult{Videos: videos, IsFullSync: true}, fmt.Errorf("fallback full sync failed: %w", err)
 	}
+	sm.progress.videos(len(videos))
 
 	// Find newest video timestamp
 	var newestTime time.Time
@@ -186,17 +396,6 @@ func (sm *SyncManager) performFullSync(ctx context.Context, channelURL string, s
 	}, nil
 }
 
-// resumeSync continues a previously interrupted sync operation.
-func (sm *SyncManager) resumeSync(ctx context.Context, syncState *storage.SyncState, opts *ListOptions) (*SyncResult, error) {
-	// This would resume based on the strategy used (Innertube or API continuation tokens)
-	// For now, fall back to a fresh sync if resuming fails
-	log.Printf("ytsync: resume capability not yet implemented, starting fresh sync")
-
-	// Clear expired token and start fresh
-	syncState.ClearPaginationState()
-	return nil, nil
-}
-
 // ChannelSyncStatus returns the current sync status for a channel.
 func (sm *SyncManager) ChannelSyncStatus(ctx context.Context, channelID string) (*storage.SyncState, error) {
 	return sm.store.GetSyncState(ctx, channelID)
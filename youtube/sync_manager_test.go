@@ -2,11 +2,19 @@ package youtube
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+	ythttp "ytsync/http"
+	"ytsync/lease"
+	"ytsync/retry"
 	"ytsync/storage"
+	"ytsync/tags"
 )
 
 // MockSyncStateStore implements storage.SyncStateStore for testing.
@@ -86,6 +94,7 @@ func TestSyncManagerIncrementalSyncNoGap(t *testing.T) {
 	prevState.NewestVideoTimestamp = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 	prevState.Status = storage.SyncStatusIdle
 	prevState.LastSyncAt = time.Now().Add(-1 * time.Hour)
+	prevState.TotalVideos = 50
 	store.states["UCuAXFkgsw1L7xaCfnd5JJOw"] = prevState
 
 	sm := NewSyncManagerWithListers(rssLister, nil, store)
@@ -107,6 +116,10 @@ func TestSyncManagerIncrementalSyncNoGap(t *testing.T) {
 	if result.GapDetected {
 		t.Error("gap should not be detected")
 	}
+	// The saved video count follows the one new video in the feed
+	if got := store.states["UCuAXFkgsw1L7xaCfnd5JJOw"].TotalVideos; got != 51 {
+		t.Errorf("TotalVideos = %d, want 51", got)
+	}
 }
 
 // TestSyncManagerGapDetectionFallback tests that full sync is performed when a gap is detected.
@@ -160,6 +173,73 @@ func TestSyncManagerGapDetectionFallback(t *testing.T) {
 	}
 }
 
+// videoCounterFunc adapts a function to VideoCounter.
+type videoCounterFunc func(ctx context.Context, channelURL string) (int, error)
+
+func (f videoCounterFunc) VideoCount(ctx context.Context, channelURL string) (int, error) {
+	return f(ctx, channelURL)
+}
+
+// TestSyncManagerVideoCount tests that the full sync after an RSS gap is
+// skipped when the video count shows the feed held every new video.
+func TestSyncManagerVideoCount(t *testing.T) {
+	const channelID = "UCuAXFkgsw1L7xaCfnd5JJOw"
+	// SampleAtomFeed holds two videos, both newer than the saved state
+	tests := []struct {
+		name      string
+		saved     int
+		count     int
+		countErr  error
+		wantSkip  bool
+		wantTotal int
+	}{
+		{name: "feed holds every new video", saved: 100, count: 102, wantSkip: true, wantTotal: 102},
+		{name: "videos missing from feed", saved: 100, count: 105, wantTotal: 105},
+		{name: "no saved count", count: 102, wantTotal: 102},
+		{name: "count unavailable", saved: 100, countErr: ErrVideoCountUnavailable, wantTotal: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockSyncStateStore()
+			prevState := storage.NewSyncState(channelID)
+			prevState.NewestVideoTimestamp = time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+			prevState.Status = storage.SyncStatusIdle
+			prevState.TotalVideos = tt.saved
+			store.states[channelID] = prevState
+
+			fallback := &mockVideoLister{videos: []VideoInfo{
+				{ID: "fallback1", Published: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)},
+			}}
+			sm := NewSyncManagerWithListers(NewRSSListerWithClient(newMockHTTPClient(http.StatusOK, SampleAtomFeed)), fallback, store)
+			sm.SetVideoCounter(videoCounterFunc(func(ctx context.Context, channelURL string) (int, error) {
+				return tt.count, tt.countErr
+			}))
+
+			result, err := sm.SyncChannelVideos(context.Background(), channelID, nil)
+			if err != nil {
+				t.Fatalf("SyncChannelVideos() error = %v", err)
+			}
+			if result.IsFullSync == tt.wantSkip {
+				t.Errorf("IsFullSync = %v, want %v", result.IsFullSync, !tt.wantSkip)
+			}
+			if result.Report.ListingSkipped != tt.wantSkip {
+				t.Errorf("Report.ListingSkipped = %v, want %v", result.Report.ListingSkipped, tt.wantSkip)
+			}
+			if tt.wantSkip && len(result.Videos) != 2 {
+				t.Errorf("len(Videos) = %d, want the 2 feed videos", len(result.Videos))
+			}
+
+			state := store.states[channelID]
+			if state.TotalVideos != tt.wantTotal {
+				t.Errorf("TotalVideos = %d, want %d", state.TotalVideos, tt.wantTotal)
+			}
+			if state.Status != storage.SyncStatusIdle || state.RSSRequiresFullSync {
+				t.Errorf("state = %s, RSSRequiresFullSync %v; want idle without a pending full sync", state.Status, state.RSSRequiresFullSync)
+			}
+		})
+	}
+}
+
 // TestSyncManagerStateUpdated tests that sync state is properly updated.
 func TestSyncManagerStateUpdated(t *testing.T) {
 	client := newMockHTTPClient(http.StatusOK, SampleAtomFeed)
@@ -244,3 +324,508 @@ func TestSyncManagerChannelStatusFound(t *testing.T) {
 		t.Errorf("ChannelID = %s, want UCexists", retrieved.ChannelID)
 	}
 }
+
+// mockSyncReportStore implements storage.SyncReportStore for testing.
+type mockSyncReportStore struct {
+	reports []*storage.SyncReport
+}
+
+func (m *mockSyncReportStore) SaveSyncReport(ctx context.Context, report *storage.SyncReport) error {
+	m.reports = append(m.reports, report)
+	return nil
+}
+
+func (m *mockSyncReportStore) ListSyncReports(ctx context.Context, channelID string) ([]*storage.SyncReport, error) {
+	return m.reports, nil
+}
+
+// TestSyncManagerReport tests that a sync produces and saves a report.
+func TestSyncManagerReport(t *testing.T) {
+	client := newMockHTTPClient(http.StatusOK, SampleAtomFeed)
+	rssLister := NewRSSListerWithClient(client)
+	store := newMockSyncStateStore()
+	reports := &mockSyncReportStore{}
+
+	sm := NewSyncManagerWithListers(rssLister, nil, store)
+	sm.SetReportStore(reports)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := sm.SyncChannelVideos(ctx, "UCuAXFkgsw1L7xaCfnd5JJOw", nil)
+	if err != nil {
+		t.Fatalf("SyncChannelVideos() error = %v", err)
+	}
+
+	report := result.Report
+	if report == nil {
+		t.Fatal("SyncResult.Report = nil")
+	}
+	if report.ChannelID != "UCuAXFkgsw1L7xaCfnd5JJOw" || !report.Incremental || report.Strategy != storage.StrategyRSS {
+		t.Errorf("report = %+v, want incremental RSS sync of the channel", report)
+	}
+	if report.VideosSeen != len(result.Videos) || len(report.NewVideos) != len(result.Videos) {
+		t.Errorf("VideosSeen = %d, NewVideos = %d, want %d", report.VideosSeen, len(report.NewVideos), len(result.Videos))
+	}
+	if report.Requests != 1 {
+		t.Errorf("Requests = %d, want 1", report.Requests)
+	}
+	if len(report.Phases) != 2 || report.Phases[0].Phase != "rss" || report.Phases[1].Phase != "persist" {
+		t.Errorf("Phases = %+v, want rss, persist", report.Phases)
+	}
+	if report.FinishedAt.IsZero() {
+		t.Error("FinishedAt should be set")
+	}
+	if len(report.RunID) != 16 {
+		t.Errorf("RunID = %q, want a generated run ID", report.RunID)
+	}
+	if len(reports.reports) != 1 || reports.reports[0] != report {
+		t.Errorf("saved reports = %v, want the returned report", reports.reports)
+	}
+
+	if _, err := json.Marshal(report); err != nil {
+		t.Errorf("json.Marshal(report) error = %v", err)
+	}
+}
+
+// TestSyncManagerTags tests that a sync tags its requests with the channel,
+// phase, and the caller's run ID.
+func TestSyncManagerTags(t *testing.T) {
+	transport := &tagRecordingTransport{mockTransport: mockTransport{statusCode: http.StatusOK, body: SampleAtomFeed}}
+	sm := NewSyncManagerWithListers(NewRSSListerWithClient(&http.Client{Transport: transport}), nil, newMockSyncStateStore())
+
+	ctx := tags.WithRun(context.Background(), "run-1")
+	result, err := sm.SyncChannelVideos(ctx, "UCuAXFkgsw1L7xaCfnd5JJOw", nil)
+	if err != nil {
+		t.Fatalf("SyncChannelVideos() error = %v", err)
+	}
+	if result.Report.RunID != "run-1" {
+		t.Errorf("RunID = %q, want the caller's run-1", result.Report.RunID)
+	}
+	if got := transport.tags; len(got) != 1 || got[0].String() != "run=run-1 channel=UCuAXFkgsw1L7xaCfnd5JJOw op=rss" {
+		t.Errorf("request tags = %v", got)
+	}
+}
+
+// tagRecordingTransport records the tags of each request's context.
+type tagRecordingTransport struct {
+	mockTransport
+	tags []tags.Tags
+}
+
+func (m *tagRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	m.tags = append(m.tags, tags.From(req.Context()))
+	return m.mockTransport.RoundTrip(req)
+}
+
+// TestSyncManagerLease tests that a channel leased by another instance is
+// not synced and that the lease is released after a sync.
+func TestSyncManagerLease(t *testing.T) {
+	backend := &lease.FileBackend{Dir: t.TempDir()}
+	other := lease.NewLocker(backend)
+	sm := NewSyncManagerWithListers(NewRSSListerWithClient(newMockHTTPClient(http.StatusOK, SampleAtomFeed)), nil, newMockSyncStateStore())
+	sm.SetLocker(lease.NewLocker(backend))
+
+	ctx := context.Background()
+	held, err := other.Acquire(ctx, "channel/UCuAXFkgsw1L7xaCfnd5JJOw")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := sm.SyncChannelVideos(ctx, "UCuAXFkgsw1L7xaCfnd5JJOw", nil); !errors.Is(err, lease.ErrHeld) {
+		t.Fatalf("SyncChannelVideos() of a leased channel error = %v, want lease.ErrHeld", err)
+	}
+	held.Release(ctx)
+
+	if _, err := sm.SyncChannelVideos(ctx, "UCuAXFkgsw1L7xaCfnd5JJOw", nil); err != nil {
+		t.Fatalf("SyncChannelVideos() error = %v", err)
+	}
+	// The sync released its lease
+	held, err = other.Acquire(ctx, "channel/UCuAXFkgsw1L7xaCfnd5JJOw")
+	if err != nil {
+		t.Fatalf("Acquire() after the sync error = %v", err)
+	}
+	held.Release(ctx)
+}
+
+// TestSyncManagerReportFailure tests that failed syncs still save a report.
+func TestSyncManagerReportFailure(t *testing.T) {
+	client := newMockHTTPClient(http.StatusNotFound, "")
+	rssLister := NewRSSListerWithClient(client)
+	store := newMockSyncStateStore()
+	reports := &mockSyncReportStore{}
+
+	fallback := &mockVideoLister{err: errors.New("listing failed")}
+	sm := NewSyncManagerWithListers(rssLister, fallback, store)
+	sm.SetReportStore(reports)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := sm.SyncChannelVideos(ctx, "UCuAXFkgsw1L7xaCfnd5JJOw", nil)
+	if err == nil {
+		t.Fatal("SyncChannelVideos() error = nil, want error")
+	}
+	if len(reports.reports) != 1 {
+		t.Fatalf("saved %d reports, want 1", len(reports.reports))
+	}
+	report := reports.reports[0]
+	if result == nil || result.Report != report {
+		t.Errorf("SyncChannelVideos() result = %+v, want the saved report with the error", result)
+	}
+	if report.Error == "" || report.Strategy != storage.StrategyYtdlp {
+		t.Errorf("report = %+v, want ytdlp failure recorded", report)
+	}
+	if report.Requests != 2 {
+		t.Errorf("Requests = %d, want 2", report.Requests)
+	}
+}
+
+// TestSyncManagerReportClassification tests that without enrichment
+// storing videos, the report classifies them against the last sync's
+// newest video.
+func TestSyncManagerReportClassification(t *testing.T) {
+	const channelID = "UCuAXFkgsw1L7xaCfnd5JJOw"
+	store := newMockSyncStateStore()
+	prevState := storage.NewSyncState(channelID)
+	prevState.NewestVideoTimestamp = time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	prevState.Status = storage.SyncStatusIdle
+	store.states[channelID] = prevState
+
+	fallback := &mockVideoLister{videos: []VideoInfo{
+		{ID: "newer", Published: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{ID: "older", Published: time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "undated"},
+	}}
+	// The feed fails, so the channel is listed in full
+	sm := NewSyncManagerWithListers(NewRSSListerWithClient(newMockHTTPClient(http.StatusNotFound, "")), fallback, store)
+
+	result, err := sm.SyncChannelVideos(context.Background(), channelID, nil)
+	if err != nil {
+		t.Fatalf("SyncChannelVideos() error = %v", err)
+	}
+	report := result.Report
+	if got := strings.Join(report.NewVideos, ","); got != "newer,undated" {
+		t.Errorf("NewVideos = %s, want newer,undated", got)
+	}
+	if got := strings.Join(report.UpdatedVideos, ","); got != "older" {
+		t.Errorf("UpdatedVideos = %s, want older", got)
+	}
+}
+
+// TestSyncManagerEnrichment tests that new videos are enriched and the
+// outcomes recorded in the report.
+func TestSyncManagerEnrichment(t *testing.T) {
+	client := newMockHTTPClient(http.StatusOK, SampleAtomFeed)
+	rssLister := NewRSSListerWithClient(client)
+	store := newMockSyncStateStore()
+
+	sm := NewSyncManagerWithListers(rssLister, nil, store)
+	sm.SetEnrichment(&EnrichOptions{
+		Metadata: func(ctx context.Context, videoID string) (*VideoMetadata, error) {
+			return nil, ErrNetworkTimeout
+		},
+		Transcripts: func(ctx context.Context, videoID string) (*Transcript, error) {
+			return &Transcript{VideoID: videoID}, nil
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := sm.SyncChannelVideos(ctx, "UCuAXFkgsw1L7xaCfnd5JJOw", nil)
+	if err != nil {
+		t.Fatalf("SyncChannelVideos() error = %v", err)
+	}
+
+	n := len(result.Videos)
+	if len(result.Enriched) != n {
+		t.Errorf("Enriched = %d results, want %d", len(result.Enriched), n)
+	}
+	report := result.Report
+	if report.TranscriptsFetched != n || len(report.TranscriptFailures) != 0 {
+		t.Errorf("TranscriptsFetched = %d, failures = %v, want %d fetched", report.TranscriptsFetched, report.TranscriptFailures, n)
+	}
+	if len(report.StageFailures) != n || report.StageFailures[0].Stage != StageMetadata || report.StageFailures[0].Code != "timeout" {
+		t.Errorf("StageFailures = %+v, want %d metadata timeouts", report.StageFailures, n)
+	}
+	var sawEnrich bool
+	for _, p := range report.Phases {
+		sawEnrich = sawEnrich || p.Phase == "enrich"
+	}
+	if !sawEnrich {
+		t.Errorf("Phases = %+v, want an enrich phase", report.Phases)
+	}
+}
+
+// pagingLister pages through pages of videos like the Data API lister,
+// reporting each page to OnProgress. It cancels the sync after cancelAfter
+// pages, as a Ctrl-C would.
+type pagingLister struct {
+	pages       [][]VideoInfo
+	cancelAfter int
+	cancel      context.CancelFunc
+	resumedFrom []string
+}
+
+func (m *pagingLister) ListVideos(ctx context.Context, channelURL string, opts *ListOptions) ([]VideoInfo, error) {
+	m.resumedFrom = append(m.resumedFrom, opts.ResumeToken)
+	start := 0
+	if opts.ResumeToken != "" {
+		fmt.Sscanf(opts.ResumeToken, "page%d", &start)
+	}
+	var videos []VideoInfo
+	for i := start; i < len(m.pages); i++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		videos = append(videos, m.pages[i]...)
+		token := ""
+		if i+1 < len(m.pages) {
+			token = fmt.Sprintf("page%d", i+1)
+		}
+		opts.OnProgress(&PaginationProgress{
+			Token:           token,
+			PlaylistID:      "UUuAXFkgsw1L7xaCfnd5JJOw",
+			VideosRetrieved: len(videos),
+			LastVideoID:     m.pages[i][len(m.pages[i])-1].ID,
+			QuotaUsed:       i - start + 1,
+			Complete:        token == "",
+		})
+		if m.cancel != nil && i+1 == m.cancelAfter {
+			m.cancel()
+		}
+	}
+	return videos, nil
+}
+
+func (m *pagingLister) SupportsFullHistory() bool {
+	return true
+}
+
+func (m *pagingLister) PaginationStrategy() storage.PaginationStrategy {
+	return storage.StrategyAPI
+}
+
+// TestSyncManagerCheckpointOnCancel tests that cancelling a full sync
+// persists its page token and counts, and that the next sync resumes there.
+func TestSyncManagerCheckpointOnCancel(t *testing.T) {
+	const channelID = "UCuAXFkgsw1L7xaCfnd5JJOw"
+	client := newMockHTTPClient(http.StatusNotFound, "")
+	rssLister := NewRSSListerWithClient(client)
+	store := newMockSyncStateStore()
+	watermark := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	state := storage.NewSyncState(channelID)
+	state.NewestVideoTimestamp = watermark
+	store.states[channelID] = state
+
+	now := time.Now()
+	lister := &pagingLister{
+		pages: [][]VideoInfo{
+			{{ID: "v1", Published: now}, {ID: "v2", Published: now.Add(-time.Hour)}},
+			{{ID: "v3", Published: now.Add(-2 * time.Hour)}},
+			{{ID: "v4", Published: now.Add(-3 * time.Hour)}},
+		},
+		cancelAfter: 2,
+	}
+	sm := NewSyncManagerWithListers(rssLister, lister, store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lister.cancel = cancel
+	_, err := sm.SyncChannelVideos(ctx, channelID, nil)
+
+	var resumable *ResumableError
+	if !errors.As(err, &resumable) {
+		t.Fatalf("SyncChannelVideos() error = %v, want ResumableError", err)
+	}
+	if !errors.Is(err, context.Canceled) || !IsResumable(err) {
+		t.Errorf("error = %v, want resumable context.Canceled", err)
+	}
+	cp := resumable.Checkpoint
+	if cp.Strategy != storage.StrategyAPI || cp.Token != "page2" || cp.VideosProcessed != 3 || cp.LastVideoID != "v3" {
+		t.Errorf("checkpoint = %+v", cp)
+	}
+
+	saved, _ := store.GetSyncState(context.Background(), channelID)
+	if saved.Status != storage.SyncStatusSyncing || saved.APIPageToken != "page2" || saved.APIPlaylistID == "" || saved.APIQuotaUsed != 2 {
+		t.Errorf("persisted state = %+v, want resumable API checkpoint", saved)
+	}
+	if !saved.NewestVideoTimestamp.Equal(watermark) {
+		t.Errorf("NewestVideoTimestamp = %v, want %v preserved", saved.NewestVideoTimestamp, watermark)
+	}
+	if !saved.CanResume() {
+		t.Error("CanResume() = false after interrupted sync")
+	}
+
+	// The next sync picks up from the checkpoint
+	lister.cancel = nil
+	result, err := sm.SyncChannelVideos(context.Background(), channelID, nil)
+	if err != nil {
+		t.Fatalf("resumed SyncChannelVideos() error = %v", err)
+	}
+	if got := lister.resumedFrom; len(got) != 2 || got[1] != "page2" {
+		t.Errorf("resume tokens = %q, want second call from page2", got)
+	}
+	if len(result.Videos) != 1 || result.Videos[0].ID != "v4" {
+		t.Errorf("resumed videos = %v, want v4", result.Videos)
+	}
+	saved, _ = store.GetSyncState(context.Background(), channelID)
+	if saved.Status != storage.SyncStatusIdle || saved.APIPageToken != "" {
+		t.Errorf("state after resume = %+v, want idle with no token", saved)
+	}
+	if want := lister.pages[2][0].Published; !saved.NewestVideoTimestamp.Equal(want) {
+		t.Errorf("NewestVideoTimestamp = %v, want %v", saved.NewestVideoTimestamp, want)
+	}
+}
+
+// TestSyncManagerCancelBeforeFirstPage tests that a sync cancelled before
+// any progress leaves the stored state as it was.
+func TestSyncManagerCancelBeforeFirstPage(t *testing.T) {
+	const channelID = "UCuAXFkgsw1L7xaCfnd5JJOw"
+	client := newMockHTTPClient(http.StatusNotFound, "")
+	store := newMockSyncStateStore()
+	watermark := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	state := storage.NewSyncState(channelID)
+	state.NewestVideoTimestamp = watermark
+	state.LastSyncAt = watermark
+	store.states[channelID] = state
+
+	sm := NewSyncManagerWithListers(NewRSSListerWithClient(client), &pagingLister{}, store)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := sm.SyncChannelVideos(ctx, channelID, nil)
+	if !errors.Is(err, context.Canceled) || IsResumable(err) {
+		t.Fatalf("SyncChannelVideos() error = %v, want non-resumable cancellation", err)
+	}
+	saved, _ := store.GetSyncState(context.Background(), channelID)
+	if saved.Status != storage.SyncStatusIdle || !saved.NewestVideoTimestamp.Equal(watermark) || saved.LastError != "" {
+		t.Errorf("state = %+v, want unchanged", saved)
+	}
+}
+
+// TestSyncManagerProgress tests that Progress reports the channel, phase,
+// and page of a running sync, and errors and rate limits after it.
+func TestSyncManagerProgress(t *testing.T) {
+	const channelID = "UCuAXFkgsw1L7xaCfnd5JJOw"
+	client := newMockHTTPClient(http.StatusNotFound, "")
+	rssLister := NewRSSListerWithClient(client)
+	lister := &pagingLister{pages: [][]VideoInfo{
+		{{ID: "v1"}, {ID: "v2"}},
+		{{ID: "v3"}},
+	}}
+	sm := NewSyncManagerWithListers(rssLister, lister, newMockSyncStateStore())
+	cfg := ythttp.DefaultRateLimiterConfig()
+	cfg.EnableDynamicBackoff = true
+	limiter := ythttp.NewRateLimiter(cfg)
+	if err := limiter.Wait(context.Background(), "https://www.youtube.com/browse"); err != nil {
+		t.Fatal(err)
+	}
+	limiter.RecordRateLimitError("https://www.youtube.com/browse", 0)
+	sm.SetRateLimiter(limiter)
+
+	var during []SyncProgress
+	opts := &ListOptions{OnProgress: func(p *PaginationProgress) error {
+		during = append(during, sm.Progress())
+		return nil
+	}}
+	if _, err := sm.SyncChannelVideos(context.Background(), channelID, opts); err != nil {
+		t.Fatalf("SyncChannelVideos() error = %v", err)
+	}
+
+	if len(during) != 2 {
+		t.Fatalf("got %d snapshots during the sync, want 2", len(during))
+	}
+	last := during[1]
+	if last.Channel != channelID || last.Phase != "full" || last.Page != 2 || last.VideosProcessed != 3 {
+		t.Errorf("snapshot on page 2 = %+v", last)
+	}
+	if last.StartedAt.IsZero() || len(last.RecentErrors) != 1 || last.RecentErrors[0].Channel != channelID {
+		t.Errorf("snapshot on page 2 = %+v, want the RSS failure recorded", last)
+	}
+
+	after := sm.Progress()
+	if after.Channel != "" || after.Phase != "" || after.ChannelsSynced != 1 {
+		t.Errorf("snapshot after sync = %+v, want idle with 1 channel synced", after)
+	}
+	if len(after.RateLimits) == 0 || after.Backoffs["www.youtube.com"].ConsecutiveErrors != 1 {
+		t.Errorf("rate limits = %v, backoffs = %v", after.RateLimits, after.Backoffs)
+	}
+}
+
+func TestSyncManagerSubscribe(t *testing.T) {
+	const channelID = "UCuAXFkgsw1L7xaCfnd5JJOw"
+	// The JSON store wraps ErrNotFound for a channel never synced before
+	store := newEnrichTestStore(t)
+	sm := NewSyncManagerWithListers(NewRSSListerWithClient(newMockHTTPClient(http.StatusOK, SampleAtomFeed)), nil, store)
+	var mu sync.Mutex
+	failed := make(map[string]bool)
+	sm.SetEnrichment(&EnrichOptions{
+		Transcripts: func(ctx context.Context, videoID string) (*Transcript, error) {
+			cfg := retry.Config{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 1}
+			var transcript *Transcript
+			err := retry.Do(ctx, cfg, nil, func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if !failed[videoID] {
+					failed[videoID] = true
+					return ErrNetworkTimeout
+				}
+				transcript = &Transcript{VideoID: videoID, Language: "en"}
+				return nil
+			})
+			return transcript, err
+		},
+		Store: store,
+	})
+
+	subCtx, unsubscribe := context.WithCancel(context.Background())
+	events := sm.Subscribe(subCtx)
+	result, err := sm.SyncChannelVideos(context.Background(), channelID, nil)
+	if err != nil {
+		t.Fatalf("SyncChannelVideos() error = %v", err)
+	}
+	unsubscribe()
+
+	counts := make(map[SyncEventKind]int)
+	var kinds []SyncEventKind
+	for e := range events {
+		if e.Channel != channelID || e.Time.IsZero() {
+			t.Errorf("event %+v, want channel %s and a time", e, channelID)
+		}
+		switch e.Kind {
+		case EventRetry:
+			if e.VideoID == "" || e.Attempt != 1 || e.Backoff != time.Millisecond || !errors.Is(e.Err, ErrNetworkTimeout) {
+				t.Errorf("retry event = %+v", e)
+			}
+		case EventTranscriptFetched, EventVideoStored:
+			if e.VideoID == "" || e.Phase != "enrich" {
+				t.Errorf("%s event = %+v", e.Kind, e)
+			}
+		}
+		counts[e.Kind]++
+		kinds = append(kinds, e.Kind)
+	}
+
+	n := len(result.Videos)
+	if n == 0 || counts[EventRetry] != n || counts[EventTranscriptFetched] != n || counts[EventVideoStored] != n {
+		t.Errorf("event counts = %v, want %d retries, transcripts, and stored videos", counts, n)
+	}
+	if len(kinds) < 2 || kinds[0] != EventSyncStarted || kinds[len(kinds)-1] != EventSyncFinished {
+		t.Errorf("events = %v, want the sync's start first and its finish last", kinds)
+	}
+	if counts[EventPhase] != 2 {
+		t.Errorf("got %d phase events, want rss and enrich", counts[EventPhase])
+	}
+}
+
+func TestProgressTrackerRecentErrors(t *testing.T) {
+	var tracker progressTracker
+	tracker.begin("UCx")
+	for i := 0; i < maxRecentErrors+5; i++ {
+		tracker.fail(fmt.Errorf("error %d", i))
+	}
+	errs := tracker.snapshot().RecentErrors
+	if len(errs) != maxRecentErrors || errs[0].Err != "error 5" {
+		t.Errorf("RecentErrors = %+v, want the last %d", errs, maxRecentErrors)
+	}
+}
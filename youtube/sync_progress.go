@@ -0,0 +1,176 @@
+package youtube
+
+import (
+	"sync"
+	"time"
+	ythttp "ytsync/http"
+)
+
+// maxRecentErrors bounds the errors kept for SyncProgress.RecentErrors.
+const maxRecentErrors = 10
+
+// SyncProgress is a point-in-time snapshot of a SyncManager's work, for
+// operators watching long syncs. It is safe to take from another goroutine
+// while a sync runs.
+type SyncProgress struct {
+	// Channel is the YouTube ID of the channel being synced, or empty when
+	// the manager is idle.
+	Channel string
+	// Phase is "rss", "count", "full", or "enrich", or empty when idle.
+	Phase string
+	// Page is the number of pages listed in the current phase.
+	Page int
+	// VideosProcessed is the number of videos listed in the current phase.
+	VideosProcessed int
+	// ChannelsSynced counts the channel syncs finished by the manager,
+	// successful or not.
+	ChannelsSynced int
+	// StartedAt is when the current channel sync started.
+	StartedAt time.Time
+	// UpdatedAt is when progress was last recorded.
+	UpdatedAt time.Time
+	// RecentErrors are the latest sync errors, oldest first.
+	RecentErrors []ProgressError
+	// RateLimits are the current request rates by domain and path class,
+	// if a rate limiter is set.
+	RateLimits map[string]float64
+	// Backoffs are the backoff states of rate limited domains, if a rate
+	// limiter is set.
+	Backoffs map[string]ythttp.BackoffState
+}
+
+// ProgressError is an error recorded during a sync.
+type ProgressError struct {
+	// Time is when the error occurred.
+	Time time.Time
+	// Channel is the YouTube ID of the channel being synced.
+	Channel string
+	// Err is the error message.
+	Err string
+}
+
+// progressTracker records SyncProgress as a SyncManager works and sends
+// SyncEvents to subscribers.
+type progressTracker struct {
+	mu          sync.Mutex
+	progress    SyncProgress
+	subscribers map[chan SyncEvent]struct{}
+}
+
+// begin starts tracking a sync of channelID.
+func (t *progressTracker) begin(channelID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.progress.Channel = channelID
+	t.progress.Phase = ""
+	t.progress.Page = 0
+	t.progress.VideosProcessed = 0
+	t.progress.StartedAt = now
+	t.progress.UpdatedAt = now
+	t.emit(SyncEvent{Kind: EventSyncStarted})
+}
+
+// end marks the current sync finished, with err if it failed.
+func (t *progressTracker) end(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.UpdatedAt = time.Now()
+	t.emit(SyncEvent{Kind: EventSyncFinished, Err: err})
+	t.progress.Channel = ""
+	t.progress.Phase = ""
+	t.progress.ChannelsSynced++
+}
+
+// phase starts a new phase, resetting the page and video counts.
+func (t *progressTracker) phase(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.Phase = name
+	t.progress.Page = 0
+	t.progress.VideosProcessed = 0
+	t.progress.UpdatedAt = time.Now()
+	t.emit(SyncEvent{Kind: EventPhase})
+}
+
+// page records a listed page with videos retrieved so far in the phase.
+func (t *progressTracker) page(videos int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.Page++
+	if videos > t.progress.VideosProcessed {
+		t.progress.VideosProcessed = videos
+	}
+	t.progress.UpdatedAt = time.Now()
+	t.emit(SyncEvent{Kind: EventPage})
+}
+
+// videos raises the video count of the phase to n, for listers that do not
+// report pages.
+func (t *progressTracker) videos(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n > t.progress.VideosProcessed {
+		t.progress.VideosProcessed = n
+	}
+	t.progress.UpdatedAt = time.Now()
+}
+
+// fail records err against the current channel, dropping the oldest error
+// past maxRecentErrors.
+func (t *progressTracker) fail(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.progress.RecentErrors = append(t.progress.RecentErrors, ProgressError{Time: now, Channel: t.progress.Channel, Err: err.Error()})
+	if n := len(t.progress.RecentErrors); n > maxRecentErrors {
+		t.progress.RecentErrors = append([]ProgressError(nil), t.progress.RecentErrors[n-maxRecentErrors:]...)
+	}
+	t.progress.UpdatedAt = now
+	t.emit(SyncEvent{Kind: EventError, Err: err})
+}
+
+// snapshot returns a copy of the progress.
+func (t *progressTracker) snapshot() SyncProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := t.progress
+	p.RecentErrors = append([]ProgressError(nil), t.progress.RecentErrors...)
+	return p
+}
+
+// listOptions returns opts with OnProgress wrapped to record pages.
+func (t *progressTracker) listOptions(opts *ListOptions) *ListOptions {
+	tracked := ListOptions{}
+	if opts != nil {
+		tracked = *opts
+	}
+	next := tracked.OnProgress
+	tracked.OnProgress = func(p *PaginationProgress) error {
+		t.page(p.VideosRetrieved)
+		if next != nil {
+			return next(p)
+		}
+		return nil
+	}
+	return &tracked
+}
+
+// SetRateLimiter includes the rates and backoff states of rl in Progress
+// snapshots. It does not make the manager wait on rl; pass the limiter the
+// listers and enrichment use. Pass nil to leave them out.
+func (sm *SyncManager) SetRateLimiter(rl *ythttp.RateLimiter) {
+	sm.limiter = rl
+}
+
+// Progress returns a snapshot of the sync in progress, or of the idle
+// manager between syncs. It may be called from any goroutine, such as a
+// signal handler reporting on a long run.
+func (sm *SyncManager) Progress() SyncProgress {
+	p := sm.progress.snapshot()
+	if sm.limiter != nil {
+		p.RateLimits = sm.limiter.Stats()
+		p.Backoffs = sm.limiter.BackoffStates()
+	}
+	return p
+}
@@ -0,0 +1,142 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"time"
+	"ytsync/storage"
+	"ytsync/tags"
+)
+
+// trackListOptions returns a copy of opts whose OnProgress callback also
+// records page requests and API quota in report. The caller's callback,
+// if any, still runs.
+func trackListOptions(opts *ListOptions, report *storage.SyncReport) *ListOptions {
+	tracked := ListOptions{}
+	if opts != nil {
+		tracked = *opts
+	}
+	next := tracked.OnProgress
+	tracked.OnProgress = func(p *PaginationProgress) error {
+		report.Requests++
+		if p.QuotaUsed > report.QuotaUsed {
+			report.QuotaUsed = p.QuotaUsed
+		}
+		if next != nil {
+			return next(p)
+		}
+		return nil
+	}
+	return &tracked
+}
+
+// persistState saves the sync state, timing the write as the "persist" phase.
+// Failures are logged rather than returned so a completed listing is not lost.
+func (sm *SyncManager) persistState(ctx context.Context, syncState *storage.SyncState, report *storage.SyncReport) {
+	start := time.Now()
+	if err := sm.store.UpdateSyncState(ctx, syncState); err != nil {
+		tags.Logf(ctx, "ytsync: failed to persist sync state: %v", err)
+	}
+	report.AddPhase("persist", start)
+}
+
+// finishReport fills in the report from the sync outcome, saves it if a
+// report store is configured, and attaches it to result. saved is the sync
+// state as loaded before the sync. It returns result, or, for a sync that
+// failed before producing one, a result holding only the report, so the
+// caller gets the report with the error.
+func (sm *SyncManager) finishReport(ctx context.Context, report *storage.SyncReport, syncState, saved *storage.SyncState, result *SyncResult, syncErr error) *SyncResult {
+	report.Strategy = syncState.Strategy
+	if result != nil {
+		report.Incremental = result.IsIncremental
+		report.VideosSeen = len(result.Videos)
+		sm.classifyVideos(ctx, report, saved, result.Videos)
+		sm.enrichNewVideos(ctx, report, result)
+		sm.pollTranscripts(ctx, report, result)
+	} else {
+		result = &SyncResult{}
+	}
+	report.Finish(syncErr)
+	result.Report = report
+
+	if sm.reports != nil {
+		if err := sm.reports.SaveSyncReport(ctx, report); err != nil {
+			tags.Logf(ctx, "ytsync: failed to save sync report: %v", err)
+		}
+	}
+	return result
+}
+
+// classifyVideos splits videos into new and already-seen ones. When
+// enrichment stores the videos it finds, the video table says which were
+// seen. Otherwise nothing stores them, so a video is new if it was
+// published after the newest video of the last sync in saved, as every
+// video of a channel's first sync is. Videos without a publish time are
+// new unless the store has them.
+func (sm *SyncManager) classifyVideos(ctx context.Context, report *storage.SyncReport, saved *storage.SyncState, videos []VideoInfo) {
+	videoStore, _ := sm.store.(storage.VideoStore)
+	persisted := sm.enrichment != nil && sm.enrichment.Store != nil
+	for _, v := range videos {
+		if !persisted && !saved.NewestVideoTimestamp.IsZero() && !v.Published.IsZero() {
+			if v.Published.After(saved.NewestVideoTimestamp) {
+				report.NewVideos = append(report.NewVideos, v.ID)
+			} else {
+				report.UpdatedVideos = append(report.UpdatedVideos, v.ID)
+			}
+			continue
+		}
+		if videoStore != nil {
+			_, err := videoStore.GetVideoByYouTubeID(ctx, v.ID)
+			if err == nil {
+				report.UpdatedVideos = append(report.UpdatedVideos, v.ID)
+				continue
+			}
+			if !errors.Is(err, storage.ErrNotFound) {
+				tags.Logf(ctx, "ytsync: failed to look up video %s: %v", v.ID, err)
+			}
+		}
+		report.NewVideos = append(report.NewVideos, v.ID)
+	}
+}
+
+// enrichNewVideos runs the configured enrichment stages on the videos the
+// report classified as new and records each stage outcome in the report.
+func (sm *SyncManager) enrichNewVideos(ctx context.Context, report *storage.SyncReport, result *SyncResult) {
+	if sm.enrichment == nil || len(report.NewVideos) == 0 {
+		return
+	}
+
+	isNew := make(map[string]bool, len(report.NewVideos))
+	for _, id := range report.NewVideos {
+		isNew[id] = true
+	}
+	var videos []VideoInfo
+	for _, v := range result.Videos {
+		if isNew[v.ID] {
+			videos = append(videos, v)
+		}
+	}
+
+	start := time.Now()
+	sm.progress.phase("enrich")
+	enriched, err := Enrich(tags.WithOperation(ctx, "enrich"), report.ChannelID, videos, sm.progress.enrichOptions(sm.enrichment))
+	report.AddPhase("enrich", start)
+	if err != nil {
+		tags.Logf(ctx, "ytsync: enrichment failed for %s: %v", report.ChannelID, err)
+		sm.progress.fail(err)
+		return
+	}
+
+	sm.progress.videos(len(enriched))
+	for _, r := range enriched {
+		if sm.enrichment.Transcripts != nil {
+			report.RecordTranscript(r.VideoID, r.Errors[StageTranscript])
+		}
+		for _, name := range []string{StageMetadata, StagePersist} {
+			if err := r.Errors[name]; err != nil {
+				report.RecordStageFailure(r.VideoID, name, err)
+			}
+		}
+	}
+	result.Enriched = enriched
+}
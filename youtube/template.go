@@ -0,0 +1,241 @@
+package youtube
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"ytsync/errcode"
+)
+
+// ErrOutputExists indicates the rendered output path already exists and the
+// collision policy is CollisionSkip.
+var ErrOutputExists = errcode.New(errcode.AlreadyExists, "youtube: output file already exists")
+
+// maxPathComponent is the maximum length in bytes of a rendered path component.
+// Most filesystems limit names to 255 bytes; leave room for extensions and suffixes.
+const maxPathComponent = 200
+
+// CollisionPolicy controls what happens when a rendered output path already exists.
+type CollisionPolicy string
+
+const (
+	// CollisionOverwrite replaces the existing file.
+	CollisionOverwrite CollisionPolicy = "overwrite"
+	// CollisionSkip leaves the existing file untouched and skips the write.
+	CollisionSkip CollisionPolicy = "skip"
+	// CollisionSuffix writes to a new path with a numeric suffix ("name-1.ext").
+	CollisionSuffix CollisionPolicy = "suffix"
+)
+
+// TemplateData holds the fields available to an OutputTemplate.
+// String fields are sanitized before rendering, so they can never
+// introduce path separators; only literal slashes in the template do.
+type TemplateData struct {
+	// ID is the YouTube video ID.
+	ID string
+	// Title is the video title.
+	Title string
+	// TitleSlug is a lowercase, hyphen-separated form of the title.
+	TitleSlug string
+	// ChannelName is the channel display name.
+	ChannelName string
+	// ChannelID is the YouTube channel ID.
+	ChannelID string
+	// Published is when the video was published.
+	Published time.Time
+	// Ext is the file extension without the leading dot (e.g., "mp4", "json").
+	Ext string
+	// Language is the language code for subtitle or transcript outputs.
+	Language string
+}
+
+// TemplateDataFromMetadata builds template data from video metadata.
+func TemplateDataFromMetadata(m *VideoMetadata, ext string) TemplateData {
+	published, _ := time.Parse("20060102", m.UploadDate)
+	return TemplateData{
+		ID:          m.ID,
+		Title:       m.Title,
+		TitleSlug:   Slugify(m.Title),
+		ChannelName: m.Uploader,
+		ChannelID:   m.UploaderID,
+		Published:   published,
+		Ext:         ext,
+	}
+}
+
+// TemplateDataFromVideoInfo builds template data from a listed video.
+func TemplateDataFromVideoInfo(v *VideoInfo, ext string) TemplateData {
+	return TemplateData{
+		ID:          v.ID,
+		Title:       v.Title,
+		TitleSlug:   Slugify(v.Title),
+		ChannelName: v.ChannelName,
+		ChannelID:   v.ChannelID,
+		Published:   v.Published,
+		Ext:         ext,
+	}
+}
+
+// OutputTemplate renders output file paths from video fields using Go
+// text/template syntax, for example:
+//
+//	{{.ChannelName}}/{{.Published.Year}}/{{.ID}}-{{.TitleSlug}}.{{.Ext}}
+//
+// The same template can be shared by the video, thumbnail, subtitle, and
+// metadata writers; each supplies its own Ext.
+type OutputTemplate struct {
+	raw  string
+	tmpl *template.Template
+}
+
+// DefaultOutputTemplate matches the historical "<title>.<ext>" naming.
+const DefaultOutputTemplate = "{{.Title}}.{{.Ext}}"
+
+// ParseOutputTemplate parses a path template.
+func ParseOutputTemplate(text string) (*OutputTemplate, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("parse output template: empty template")
+	}
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse output template: %w", err)
+	}
+	return &OutputTemplate{raw: text, tmpl: tmpl}, nil
+}
+
+// MustParseOutputTemplate is like ParseOutputTemplate but panics on error.
+func MustParseOutputTemplate(text string) *OutputTemplate {
+	t, err := ParseOutputTemplate(text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String returns the template source.
+func (t *OutputTemplate) String() string {
+	return t.raw
+}
+
+// Render executes the template and returns a cleaned relative path.
+// Field values are sanitized, and each resulting path component is trimmed
+// and truncated so it is valid on common filesystems.
+func (t *OutputTemplate) Render(data TemplateData) (string, error) {
+	data.ID = sanitizePathComponent(data.ID)
+	data.Title = sanitizePathComponent(data.Title)
+	data.TitleSlug = sanitizePathComponent(data.TitleSlug)
+	data.ChannelName = sanitizePathComponent(data.ChannelName)
+	data.ChannelID = sanitizePathComponent(data.ChannelID)
+	data.Ext = sanitizePathComponent(data.Ext)
+	data.Language = sanitizePathComponent(data.Language)
+
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render output template: %w", err)
+	}
+
+	parts := strings.Split(filepath.ToSlash(buf.String()), "/")
+	cleaned := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = truncateComponent(strings.TrimSpace(part))
+		if part == "" || part == "." || part == ".." {
+			continue
+		}
+		cleaned = append(cleaned, part)
+	}
+	if len(cleaned) == 0 {
+		return "", fmt.Errorf("render output template: empty path")
+	}
+	return filepath.Join(cleaned...), nil
+}
+
+// ResolveOutputPath applies a collision policy to path. It returns the path
+// to write to, or ErrOutputExists if the policy is CollisionSkip and the
+// path is taken. An empty policy behaves like CollisionOverwrite.
+func ResolveOutputPath(path string, policy CollisionPolicy) (string, error) {
+	if !fileExists(path) {
+		return path, nil
+	}
+
+	switch policy {
+	case CollisionSkip:
+		return path, ErrOutputExists
+	case CollisionSuffix:
+		ext := filepath.Ext(path)
+		base := strings.TrimSuffix(path, ext)
+		for i := 1; ; i++ {
+			candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+			if !fileExists(candidate) {
+				return candidate, nil
+			}
+		}
+	case CollisionOverwrite, "":
+		return path, nil
+	default:
+		return "", fmt.Errorf("unknown collision policy %q", policy)
+	}
+}
+
+// Slugify converts s to a lowercase, hyphen-separated slug containing only
+// letters and digits.
+func Slugify(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			pendingHyphen = false
+		} else {
+			pendingHyphen = true
+		}
+	}
+	return b.String()
+}
+
+// sanitizePathComponent makes a field value safe to embed in a single path
+// component by replacing separators, reserved characters, and control characters.
+func sanitizePathComponent(s string) string {
+	s = sanitizeFilename(s)
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Trim(s, " .")
+}
+
+// truncateComponent shortens a path component to maxPathComponent bytes,
+// preserving its extension and never splitting a UTF-8 sequence.
+func truncateComponent(s string) string {
+	if len(s) <= maxPathComponent {
+		return s
+	}
+	ext := filepath.Ext(s)
+	if len(ext) > 16 {
+		ext = ""
+	}
+	base := s[:len(s)-len(ext)]
+	limit := maxPathComponent - len(ext)
+	for limit > 0 && !isRuneStart(base[limit]) {
+		limit--
+	}
+	return strings.TrimSpace(base[:limit]) + ext
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
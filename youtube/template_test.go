@@ -0,0 +1,164 @@
+package youtube
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutputTemplate_Render(t *testing.T) {
+	data := TemplateData{
+		ID:          "dQw4w9WgXcQ",
+		Title:       "Never Gonna Give You Up: Remastered?",
+		TitleSlug:   Slugify("Never Gonna Give You Up: Remastered?"),
+		ChannelName: "Rick/Astley",
+		Published:   time.Date(2009, 10, 25, 0, 0, 0, 0, time.UTC),
+		Ext:         "mp4",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "nested path",
+			template: "{{.ChannelName}}/{{.Published.Year}}/{{.ID}}-{{.TitleSlug}}.{{.Ext}}",
+			want:     filepath.Join("Rick_Astley", "2009", "dQw4w9WgXcQ-never-gonna-give-you-up-remastered.mp4"),
+		},
+		{
+			name:     "default template sanitizes title",
+			template: DefaultOutputTemplate,
+			want:     "Never Gonna Give You Up_ Remastered_.mp4",
+		},
+		{
+			name:     "parent directory components dropped",
+			template: "../{{.ID}}.{{.Ext}}",
+			want:     "dQw4w9WgXcQ.mp4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseOutputTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("ParseOutputTemplate() error = %v", err)
+			}
+			got, err := tmpl.Render(data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputTemplate_Invalid(t *testing.T) {
+	for _, text := range []string{"", "{{.ID", "{{.Missing}}.mp4"} {
+		tmpl, err := ParseOutputTemplate(text)
+		if err == nil {
+			_, err = tmpl.Render(TemplateData{ID: "abc"})
+		}
+		if err == nil {
+			t.Errorf("template %q: error = nil, want error", text)
+		}
+	}
+}
+
+func TestOutputTemplate_TruncatesLongComponents(t *testing.T) {
+	tmpl := MustParseOutputTemplate("{{.Title}}.{{.Ext}}")
+	got, err := tmpl.Render(TemplateData{Title: strings.Repeat("é", 300), Ext: "mp4"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if len(got) > maxPathComponent {
+		t.Errorf("Render() len = %d, want <= %d", len(got), maxPathComponent)
+	}
+	if !strings.HasSuffix(got, ".mp4") {
+		t.Errorf("Render() = %q, want .mp4 suffix", got)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"  Leading and trailing  ", "leading-and-trailing"},
+		{"Go 1.24 Release", "go-1-24-release"},
+		{"Ünïcödé Tïtle", "ünïcödé-tïtle"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.input); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestResolveOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "video.mp4")
+
+	got, err := ResolveOutputPath(path, CollisionSkip)
+	if err != nil || got != path {
+		t.Errorf("ResolveOutputPath() on free path = %q, %v, want %q, nil", got, err, path)
+	}
+
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ResolveOutputPath(path, CollisionSkip); !errors.Is(err, ErrOutputExists) {
+		t.Errorf("ResolveOutputPath(skip) error = %v, want ErrOutputExists", err)
+	}
+	if got, _ := ResolveOutputPath(path, CollisionOverwrite); got != path {
+		t.Errorf("ResolveOutputPath(overwrite) = %q, want %q", got, path)
+	}
+	if got, _ := ResolveOutputPath(path, CollisionSuffix); got != filepath.Join(dir, "video-1.mp4") {
+		t.Errorf("ResolveOutputPath(suffix) = %q, want video-1.mp4", got)
+	}
+}
+
+func TestResolveTemplatedOutput(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := MustParseOutputTemplate("{{.ID}}.{{.Ext}}")
+	metadata := &VideoMetadata{ID: "abc", Title: "Test"}
+
+	// A leftover metadata sidecar is not a collision
+	if err := os.WriteFile(filepath.Join(dir, "abc.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	base, err := resolveTemplatedOutput(dir, tmpl, metadata, CollisionSkip)
+	if err != nil {
+		t.Fatalf("resolveTemplatedOutput() error = %v", err)
+	}
+	if want := filepath.Join(dir, "abc."+extPlaceholder); base != want {
+		t.Errorf("resolveTemplatedOutput() = %q, want %q", base, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "abc.webm"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	existing, err := resolveTemplatedOutput(dir, tmpl, metadata, CollisionSkip)
+	if !errors.Is(err, ErrOutputExists) {
+		t.Fatalf("resolveTemplatedOutput(skip) error = %v, want ErrOutputExists", err)
+	}
+	if existing != filepath.Join(dir, "abc.webm") {
+		t.Errorf("resolveTemplatedOutput(skip) = %q, want existing file", existing)
+	}
+
+	suffixed, err := resolveTemplatedOutput(dir, tmpl, metadata, CollisionSuffix)
+	if err != nil {
+		t.Fatalf("resolveTemplatedOutput(suffix) error = %v", err)
+	}
+	if want := filepath.Join(dir, "abc-1."+extPlaceholder); suffixed != want {
+		t.Errorf("resolveTemplatedOutput(suffix) = %q, want %q", suffixed, want)
+	}
+}
@@ -29,6 +29,19 @@ func NewTimedtextClient() *TimedtextClient {
 	}
 }
 
+// NewTimedtextClientWithClient creates a timedtext client that sends requests
+// through client, e.g. one from SessionManager.GetClient carrying imported
+// browser cookies.
+func NewTimedtextClientWithClient(client *httpclient.Client) *TimedtextClient {
+	if client == nil {
+		return NewTimedtextClient()
+	}
+	return &TimedtextClient{
+		httpClient: client,
+		baseURL:    "https://www.youtube.com/api/timedtext",
+	}
+}
+
 // TimedtextResponse represents the raw timedtext API response.
 type TimedtextResponse struct {
 	Events []TimedtextEvent `json:"events"`
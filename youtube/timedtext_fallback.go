@@ -0,0 +1,157 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"ytsync/errcode"
+	httpclient "ytsync/http"
+)
+
+// DefaultCaptionFormats is the order FetchTranscript requests caption
+// formats in. srv3 carries the most detail; json3 and WebVTT are served
+// for some tracks that return an empty srv3 document.
+var DefaultCaptionFormats = []Format{FormatSRT3, FormatJSON3, FormatVTT}
+
+// CaptionTrack identifies a caption track of a video.
+type CaptionTrack struct {
+	// Language is the track's language code.
+	Language string
+	// IsAutoGenerated selects the automatic speech recognition (ASR) track.
+	IsAutoGenerated bool
+}
+
+// CaptionAttempt records a caption track and format that was tried but
+// yielded no transcript.
+type CaptionAttempt struct {
+	Language        string `json:"language"`
+	IsAutoGenerated bool   `json:"is_auto_generated"`
+	Format          Format `json:"format,omitempty"`
+	// Error describes why the attempt failed.
+	Error string `json:"error"`
+}
+
+// errEmptyCaptions is recorded for a track that exists but has no events,
+// which is common for videos published minutes ago.
+var errEmptyCaptions = errors.New("no caption events")
+
+// captionCandidates returns the tracks to try for pref, in order: each
+// preferred language's manual and ASR tracks, ordered by
+// PreferManualCaptions, then English if AllowEnglishFallback is set.
+func captionCandidates(pref LanguagePreference) []CaptionTrack {
+	languages := append([]string(nil), pref.PreferredLanguages...)
+	if pref.AllowEnglishFallback {
+		languages = append(languages, "en")
+	}
+
+	kinds := []bool{false}
+	if pref.IncludeAutoGenerated {
+		kinds = []bool{false, true}
+		if !pref.PreferManualCaptions {
+			kinds = []bool{true, false}
+		}
+	}
+
+	seen := make(map[CaptionTrack]bool)
+	var tracks []CaptionTrack
+	for _, lang := range languages {
+		for _, auto := range kinds {
+			track := CaptionTrack{Language: lang, IsAutoGenerated: auto}
+			if lang != "" && !seen[track] {
+				seen[track] = true
+				tracks = append(tracks, track)
+			}
+		}
+	}
+	return tracks
+}
+
+// isFallbackError reports whether a failed caption request should move on
+// to the next format or track. Rate limiting, bot detection, and
+// cancellation stop the fallback, since further requests would fail too.
+func isFallbackError(err error) bool {
+	switch errcode.Of(err) {
+	case errcode.RateLimited, errcode.BotDetected, errcode.Canceled, errcode.Timeout:
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// isTrackMissing reports whether err means the requested track does not
+// exist, so other formats of it need not be tried.
+func isTrackMissing(err error) bool {
+	var httpErr *httpclient.HTTPError
+	return errors.As(err, &httpErr) && httpErr.ErrorCode() == errcode.NotFound
+}
+
+// FetchTranscript fetches the best available captions for videoID. Tracks
+// are tried in pref order: each preferred language's manual track, then
+// its ASR track, then English. For each track the formats in
+// DefaultCaptionFormats are tried in turn. A track that is missing or has
+// no caption events moves on to the next one.
+//
+// The returned Transcript's Language, IsAutoGenerated, and Format say
+// which track succeeded; its Fallback lists the attempts that failed
+// before it. Returns ErrNoTranscript if every track failed, or the error
+// that stopped the fallback if YouTube rate limited or blocked a request.
+func (tc *TimedtextClient) FetchTranscript(ctx context.Context, videoID string, pref LanguagePreference) (*Transcript, error) {
+	if videoID == "" {
+		return nil, fmt.Errorf("video ID is required")
+	}
+
+	var attempts []CaptionAttempt
+	for _, track := range captionCandidates(pref) {
+		for _, format := range DefaultCaptionFormats {
+			entries, err := tc.fetchTrack(ctx, videoID, track, format)
+			if err == nil && len(entries) == 0 {
+				err = errEmptyCaptions
+			}
+			if err == nil {
+				return &Transcript{
+					VideoID:         videoID,
+					Language:        track.Language,
+					LanguageName:    getLanguageName(track.Language),
+					IsAutoGenerated: track.IsAutoGenerated,
+					Entries:         entries,
+					Format:          format,
+					Fallback:        attempts,
+				}, nil
+			}
+			if !isFallbackError(err) {
+				return nil, &TranscriptError{VideoID: videoID, Err: err}
+			}
+
+			attempts = append(attempts, CaptionAttempt{
+				Language:        track.Language,
+				IsAutoGenerated: track.IsAutoGenerated,
+				Format:          format,
+				Error:           err.Error(),
+			})
+			if isTrackMissing(err) {
+				break
+			}
+		}
+	}
+	return nil, &TranscriptError{VideoID: videoID, Err: ErrNoTranscript}
+}
+
+// fetchTrack requests one caption track in one format.
+func (tc *TimedtextClient) fetchTrack(ctx context.Context, videoID string, track CaptionTrack, format Format) ([]TranscriptEntry, error) {
+	params := url.Values{}
+	params.Set("v", videoID)
+	params.Set("lang", track.Language)
+	params.Set("fmt", string(format))
+	if track.IsAutoGenerated {
+		params.Set("kind", "asr")
+	}
+
+	response, err := tc.httpClient.Get(ctx, tc.baseURL+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Body) == 0 {
+		return nil, nil
+	}
+	return ParseFormat(string(response.Body), format)
+}
@@ -0,0 +1,192 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	ythttp "ytsync/http"
+)
+
+const sampleSRV3 = `<?xml version="1.0" encoding="utf-8" ?><timedtext format="3">
+<body>
+<p t="0" d="2500" w="1"><s ac="0">Hello</s><s t="400" ac="0"> world</s></p>
+<p t="1200" d="1300" a="1">
+</p>
+<p t="2500" d="1800">caf&#233; &amp; more</p>
+</body>
+</timedtext>`
+
+const sampleTimedtextJSON3 = `{"events":[{"tStartMs":0,"dDurationMs":1500,"segs":[{"utf8":"from json3"}]}]}`
+
+// newTimedtextTestClient serves timedtext responses from handle, keyed by
+// the request's lang, kind, and fmt parameters, and records each request.
+func newTimedtextTestClient(t *testing.T, handle func(w http.ResponseWriter, key string)) (*TimedtextClient, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		key := q.Get("lang") + "/" + q.Get("kind") + "/" + q.Get("fmt")
+		mu.Lock()
+		requests = append(requests, key)
+		mu.Unlock()
+		handle(w, key)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := ythttp.DefaultConfig()
+	cfg.Retry.MaxRetries = 0
+	cfg.RateLimiter.EnableDynamicBackoff = false
+	tc := NewTimedtextClientWithClient(ythttp.New(cfg))
+	tc.baseURL = server.URL
+	return tc, &requests
+}
+
+func TestParseSRV3(t *testing.T) {
+	entries, err := ParseFormat(sampleSRV3, FormatSRT3)
+	if err != nil {
+		t.Fatalf("ParseFormat(srv3) error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2 (empty line-break paragraph skipped): %+v", len(entries), entries)
+	}
+	if entries[0].Text != "Hello world" || entries[0].Duration != 2.5 {
+		t.Errorf("entries[0] = %+v", entries[0])
+	}
+	if entries[1].Text != "café & more" || entries[1].Start != 2.5 {
+		t.Errorf("entries[1] = %+v", entries[1])
+	}
+}
+
+func TestParseYouTubeCaptionFormats(t *testing.T) {
+	// YouTube sends JSON3 times as numbers and WebVTT cues with settings
+	// and inline word timings
+	entries, err := ParseFormat(sampleTimedtextJSON3, FormatJSON3)
+	if err != nil || len(entries) != 1 || entries[0].Duration != 1.5 {
+		t.Errorf("ParseFormat(json3) = %+v, %v", entries, err)
+	}
+
+	vtt := "WEBVTT\nKind: captions\n\n00:00:01.000 --> 00:00:03.000 align:start position:0%\nhello<00:00:01.500><c> there</c>\n"
+	entries, err = ParseFormat(vtt, FormatVTT)
+	if err != nil || len(entries) != 1 || entries[0].Text != "hello there" || entries[0].Duration != 2 {
+		t.Errorf("ParseFormat(vtt) = %+v, %v", entries, err)
+	}
+}
+
+func TestCaptionCandidates(t *testing.T) {
+	pref := PreferenceForLanguages([]string{"de"})
+	got := captionCandidates(pref)
+	want := []CaptionTrack{{"de", false}, {"de", true}, {"en", false}, {"en", true}}
+	if len(got) != len(want) {
+		t.Fatalf("captionCandidates() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("captionCandidates()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	pref = LanguagePreference{PreferredLanguages: []string{"en"}, IncludeAutoGenerated: true}
+	if got := captionCandidates(pref); len(got) != 2 || !got[0].IsAutoGenerated {
+		t.Errorf("without PreferManualCaptions, got %v, want ASR first", got)
+	}
+}
+
+func TestFetchTranscript_Fallback(t *testing.T) {
+	tc, requests := newTimedtextTestClient(t, func(w http.ResponseWriter, key string) {
+		switch key {
+		case "en/asr/srv3":
+			// Newly published: the track exists but has no events yet
+			w.Write([]byte(`<timedtext format="3"><body></body></timedtext>`))
+		case "en/asr/json3":
+			w.Write([]byte(sampleTimedtextJSON3))
+		default:
+			http.NotFound(w, nil)
+		}
+	})
+
+	transcript, err := tc.FetchTranscript(context.Background(), "vid", DefaultLanguagePreference())
+	if err != nil {
+		t.Fatalf("FetchTranscript() error = %v", err)
+	}
+	if transcript.Language != "en" || !transcript.IsAutoGenerated || transcript.Format != FormatJSON3 {
+		t.Errorf("succeeded with %s auto=%v %s, want en ASR json3", transcript.Language, transcript.IsAutoGenerated, transcript.Format)
+	}
+	if len(transcript.Entries) != 1 || transcript.Entries[0].Text != "from json3" {
+		t.Errorf("Entries = %+v", transcript.Entries)
+	}
+
+	// The missing manual track is tried once, not in every format
+	wantRequests := []string{"en//srv3", "en/asr/srv3", "en/asr/json3"}
+	if len(*requests) != len(wantRequests) {
+		t.Fatalf("requests = %v, want %v", *requests, wantRequests)
+	}
+	if len(transcript.Fallback) != 2 {
+		t.Fatalf("Fallback = %+v, want 2 failed attempts", transcript.Fallback)
+	}
+	if transcript.Fallback[0].IsAutoGenerated || transcript.Fallback[1].Format != FormatSRT3 || transcript.Fallback[1].Error != errEmptyCaptions.Error() {
+		t.Errorf("Fallback = %+v", transcript.Fallback)
+	}
+}
+
+func TestFetchTranscript_NoCaptions(t *testing.T) {
+	tc, _ := newTimedtextTestClient(t, func(w http.ResponseWriter, key string) {
+		http.NotFound(w, nil)
+	})
+	_, err := tc.FetchTranscript(context.Background(), "vid", PreferenceForLanguages([]string{"de"}))
+	if !errors.Is(err, ErrNoTranscript) {
+		t.Errorf("FetchTranscript() error = %v, want ErrNoTranscript", err)
+	}
+}
+
+func TestFetchTranscript_StopsWhenRateLimited(t *testing.T) {
+	tc, requests := newTimedtextTestClient(t, func(w http.ResponseWriter, key string) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	_, err := tc.FetchTranscript(context.Background(), "vid", DefaultLanguagePreference())
+	if err == nil || errors.Is(err, ErrNoTranscript) {
+		t.Fatalf("FetchTranscript() error = %v, want the rate limit error", err)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("made %d requests, want 1: fallback must stop when rate limited", len(*requests))
+	}
+}
+
+func TestExtractTranscript_DownloadFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/en-empty":
+			w.Write([]byte(`{"events":[]}`))
+		case "/en-asr":
+			w.Write([]byte(sampleTimedtextJSON3))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	info := &ytdlpVideoInfo{
+		ID:                "abc",
+		Subtitles:         map[string][]subtitleFormat{"en": {{URL: server.URL + "/en-empty", Ext: "json3"}}},
+		AutomaticCaptions: map[string][]subtitleFormat{"en": {{URL: server.URL + "/en-asr", Ext: "json3"}}},
+	}
+	pref := DefaultLanguagePreference()
+	te := NewTranscriptExtractor()
+
+	got, err := te.extractTranscript(info, "abc", &ExtractOptions{Preference: &pref})
+	if err != nil {
+		t.Fatalf("extractTranscript() error = %v", err)
+	}
+	if !got.IsAutoGenerated || len(got.Entries) != 1 || len(got.Fallback) != 1 || got.Fallback[0].IsAutoGenerated {
+		t.Errorf("got %+v, want ASR track after the empty manual track", got)
+	}
+
+	// With auto-generated captions skipped there is nothing to fall back to
+	_, err = te.extractTranscript(info, "abc", &ExtractOptions{Preference: &pref, SkipAutoGenerated: true})
+	if !errors.Is(err, ErrNoTranscript) {
+		t.Errorf("extractTranscript() error = %v, want ErrNoTranscript", err)
+	}
+}
@@ -2,16 +2,16 @@
 package youtube
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os/exec"
+	"sort"
 	"strings"
 	"time"
+	"ytsync/errcode"
 	"ytsync/retry"
 )
 
@@ -59,6 +59,12 @@ type Transcript struct {
 	Entries []TranscriptEntry `json:"entries"`
 	// DownloadURL is the URL where the transcript can be downloaded (e.g., JSON3 format).
 	DownloadURL string `json:"download_url,omitempty"`
+	// Format is the caption format the entries were parsed from, if they
+	// were downloaded.
+	Format Format `json:"format,omitempty"`
+	// Fallback lists the tracks and formats tried before this one that
+	// were missing or empty.
+	Fallback []CaptionAttempt `json:"fallback,omitempty"`
 }
 
 // ExtractOptions configures transcript extraction.
@@ -70,6 +76,14 @@ type ExtractOptions struct {
 	Format string
 	// SkipAutoGenerated skips auto-generated captions if set.
 	SkipAutoGenerated bool
+	// Preference, if set, chooses the track with LanguageAvailability.SelectLanguage
+	// (preferred order, manual before auto-generated, English fallback, then
+	// any track) instead of the first match in Languages. Languages should be
+	// empty so every track is considered.
+	Preference *LanguagePreference
+	// Normalize cleans the entries with Normalize after extraction,
+	// merging the overlapping windows of auto-generated captions.
+	Normalize bool
 }
 
 // Extract fetches and parses the transcript for a video.
@@ -81,14 +95,145 @@ func (te *TranscriptExtractor) Extract(ctx context.Context, videoID string, opts
 		opts.Format = "json3"
 	}
 
+	var transcript *Transcript
+	err := te.withInfo(ctx, videoID, opts, func(info *ytdlpVideoInfo) error {
+		// Extract first available subtitle in requested format
+		t, err := te.extractTranscript(info, videoID, opts)
+		if err != nil {
+			return err
+		}
+		transcript = t
+		return nil
+	})
+	if err == nil && opts.Normalize {
+		transcript.Entries = Normalize(transcript.Entries)
+	}
+
+	return transcript, err
+}
+
+// ExtractAll fetches the transcript of a video in every language it has
+// captions in: each manual track, and the auto-generated track of the
+// spoken language where there is no manual one. Auto-generated machine
+// translations are left out unless opts.Languages asks for them. If
+// opts.Languages is set, only those languages are returned. Tracks that
+// fail to download are skipped; if none can be downloaded the error
+// matches ErrNoTranscript. opts.Preference is ignored.
+func (te *TranscriptExtractor) ExtractAll(ctx context.Context, videoID string, opts *ExtractOptions) ([]*Transcript, error) {
+	if opts == nil {
+		opts = &ExtractOptions{}
+	}
+
+	var transcripts []*Transcript
+	err := te.withInfo(ctx, videoID, opts, func(info *ytdlpVideoInfo) error {
+		transcripts = nil
+		for _, track := range allTracks(info, opts) {
+			downloadURL := json3URL(info, track)
+			entries, err := te.downloadTranscript(downloadURL)
+			if err != nil || len(entries) == 0 {
+				continue
+			}
+			language := strings.TrimSuffix(track.Language, origSuffix)
+			transcripts = append(transcripts, &Transcript{
+				VideoID:         videoID,
+				Language:        language,
+				LanguageName:    getLanguageName(language),
+				IsAutoGenerated: track.IsAutoGenerated,
+				Entries:         entries,
+				DownloadURL:     downloadURL,
+				Format:          FormatJSON3,
+			})
+		}
+		if len(transcripts) > 0 {
+			return nil
+		}
+		if len(opts.Languages) > 0 {
+			return &TranscriptError{VideoID: videoID, Err: ErrNoTranscript}
+		}
+		// Nothing matched the rules above, such as a video whose spoken
+		// language yt-dlp does not mark; fall back to the best single track
+		t, err := te.extractTranscript(info, videoID, &ExtractOptions{SkipAutoGenerated: opts.SkipAutoGenerated})
+		if err != nil {
+			return err
+		}
+		if len(t.Entries) == 0 {
+			return &TranscriptError{VideoID: videoID, Err: ErrNoTranscript}
+		}
+		transcripts = []*Transcript{t}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.Normalize {
+		for _, t := range transcripts {
+			t.Entries = Normalize(t.Entries)
+		}
+	}
+	return transcripts, nil
+}
+
+// origSuffix marks yt-dlp's key for the auto-generated track in a video's
+// spoken language, as opposed to its machine translations.
+const origSuffix = "-orig"
+
+// allTracks returns the tracks ExtractAll downloads, manual tracks first,
+// each group sorted by language. Tracks without JSON3 captions, such as
+// live chat replays, are skipped.
+func allTracks(info *ytdlpVideoInfo, opts *ExtractOptions) []CaptionTrack {
+	wanted := func(lang string) bool {
+		if len(opts.Languages) == 0 {
+			return true
+		}
+		for _, l := range opts.Languages {
+			if l == lang {
+				return true
+			}
+		}
+		return false
+	}
+
+	var tracks []CaptionTrack
+	manual := make(map[string]bool)
+	all := availabilityFromInfo(info, info.ID, opts.SkipAutoGenerated)
+	for _, lang := range all.ManualLanguages {
+		track := CaptionTrack{Language: lang.Code}
+		if wanted(lang.Code) && json3URL(info, track) != "" {
+			tracks = append(tracks, track)
+			manual[lang.Code] = true
+		}
+	}
+	for _, lang := range all.AutoLanguages {
+		code := lang.Code
+		if len(opts.Languages) == 0 {
+			// Only the spoken language, not the machine translations
+			if !strings.HasSuffix(code, origSuffix) {
+				continue
+			}
+			code = strings.TrimSuffix(code, origSuffix)
+		} else if strings.HasSuffix(code, origSuffix) {
+			continue
+		}
+		track := CaptionTrack{Language: lang.Code, IsAutoGenerated: true}
+		if wanted(code) && !manual[code] && json3URL(info, track) != "" {
+			tracks = append(tracks, track)
+			manual[code] = true
+		}
+	}
+	return tracks
+}
+
+// withInfo runs yt-dlp for the caption tracks of a video and calls use
+// with them, retrying transient failures of either step with the
+// extractor's retry policy.
+func (te *TranscriptExtractor) withInfo(ctx context.Context, videoID string, opts *ExtractOptions, use func(info *ytdlpVideoInfo) error) error {
 	cfg := te.RetryConfig
 	if cfg == nil {
 		defaultCfg := retry.DefaultConfig()
 		cfg = &defaultCfg
 	}
 
-	var transcript *Transcript
-	err := retry.Do(ctx, *cfg, transcriptErrorClassifier, func(ctx context.Context) error {
+	return retry.Do(ctx, *cfg, transcriptErrorClassifier, func(ctx context.Context) error {
 		// Build yt-dlp arguments to get subtitle info
 		args := []string{
 			"--skip-download",
@@ -117,13 +262,7 @@ func (te *TranscriptExtractor) Extract(ctx context.Context, videoID string, opts
 		cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
 
-		cmd := exec.CommandContext(cmdCtx, te.path(), args...)
-
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
-
-		err := cmd.Run()
+		stdout, err := runYtdlp(cmdCtx, te.path(), args...)
 		if err != nil {
 			if cmdCtx.Err() == context.DeadlineExceeded {
 				return &TranscriptError{VideoID: videoID, Err: ErrNetworkTimeout}
@@ -132,35 +271,24 @@ func (te *TranscriptExtractor) Extract(ctx context.Context, videoID string, opts
 				return &TranscriptError{VideoID: videoID, Err: context.Canceled}
 			}
 
-			// Check for common error patterns
-			errMsg := stderr.String()
-			if strings.Contains(errMsg, "not found") || strings.Contains(errMsg, "does not exist") {
-				return &TranscriptError{VideoID: videoID, Err: ErrChannelNotFound}
-			}
-			if strings.Contains(errMsg, "no subtitles") || strings.Contains(errMsg, "no captions") {
-				return &TranscriptError{VideoID: videoID, Err: ErrNoTranscript}
+			var subErr *SubprocessError
+			if errors.As(err, &subErr) {
+				errMsg := subErr.Stderr
+				if strings.Contains(errMsg, "no subtitles") || strings.Contains(errMsg, "no captions") {
+					return &TranscriptError{VideoID: videoID, Err: ErrNoTranscript}
+				}
 			}
-
-			return &TranscriptError{VideoID: videoID,
-				Err: fmt.Errorf("yt-dlp failed: %w: %s", err, errMsg)}
+			return &TranscriptError{VideoID: videoID, Err: err}
 		}
 
 		// Parse yt-dlp JSON output for video metadata including subtitles
 		var info ytdlpVideoInfo
-		if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		if err := json.Unmarshal(stdout, &info); err != nil {
 			return &TranscriptError{VideoID: videoID, Err: fmt.Errorf("parse yt-dlp output: %w", err)}
 		}
 
-		// Extract first available subtitle in requested format
-		t, err := te.extractTranscript(&info, videoID, opts)
-		if err != nil {
-			return err
-		}
-		transcript = t
-		return nil
+		return use(&info)
 	})
-
-	return transcript, err
 }
 
 // extractTranscript extracts transcript from yt-dlp video info.
@@ -174,7 +302,9 @@ func (te *TranscriptExtractor) extractTranscript(info *ytdlpVideoInfo, videoID s
 	var isAutoGenerated bool
 
 	// Prefer requested language
-	if len(opts.Languages) > 0 {
+	if opts.Preference != nil {
+		langKey, isAutoGenerated = availabilityFromInfo(info, videoID, opts.SkipAutoGenerated).SelectLanguage(*opts.Preference)
+	} else if len(opts.Languages) > 0 {
 		for _, lang := range opts.Languages {
 			if _, ok := info.Subtitles[lang]; ok {
 				langKey = lang
@@ -209,47 +339,138 @@ func (te *TranscriptExtractor) extractTranscript(info *ytdlpVideoInfo, videoID s
 		return nil, &TranscriptError{VideoID: videoID, Err: ErrNoTranscript}
 	}
 
-	// Get the subtitle data
-	var subtitleData []subtitleFormat
-	if isAutoGenerated {
-		subtitleData = info.AutomaticCaptions[langKey]
-	} else {
-		subtitleData = info.Subtitles[langKey]
+	// Download the selected track's JSON3 captions. If that fails or the
+	// track is empty, fall back to the other tracks in preference order.
+	selected := CaptionTrack{Language: langKey, IsAutoGenerated: isAutoGenerated}
+	var attempts []CaptionAttempt
+	var lastErr error
+	for i, track := range fallbackTracks(info, selected, opts) {
+		downloadURL := json3URL(info, track)
+		if downloadURL == "" {
+			if i == 0 {
+				// Nothing to download; report the track without entries
+				return &Transcript{
+					VideoID:         videoID,
+					Language:        langKey,
+					LanguageName:    getLanguageName(langKey),
+					IsAutoGenerated: isAutoGenerated,
+				}, nil
+			}
+			continue
+		}
+
+		entries, err := te.downloadTranscript(downloadURL)
+		if err == nil && len(entries) == 0 {
+			err = errEmptyCaptions
+		}
+		if err == nil {
+			return &Transcript{
+				VideoID:         videoID,
+				Language:        track.Language,
+				LanguageName:    getLanguageName(track.Language),
+				IsAutoGenerated: track.IsAutoGenerated,
+				Entries:         entries,
+				DownloadURL:     downloadURL,
+				Format:          FormatJSON3,
+				Fallback:        attempts,
+			}, nil
+		}
+		attempts = append(attempts, CaptionAttempt{
+			Language:        track.Language,
+			IsAutoGenerated: track.IsAutoGenerated,
+			Format:          FormatJSON3,
+			Error:           err.Error(),
+		})
+		lastErr = err
 	}
 
-	// Find JSON format if available and download it
-	var entries []TranscriptEntry
-	var downloadURL string
-	var downloadErr error
-	for _, sub := range subtitleData {
-		if sub.Ext == "json3" {
-			downloadURL = sub.URL
-			// Try to download and parse the transcript
-			parsedEntries, err := te.downloadTranscript(sub.URL)
-			if err == nil {
-				entries = parsedEntries
-			} else {
-				// Store the error to return to caller
-				downloadErr = err
-			}
-			// If download fails, we'll still return metadata with the error
-			break
+	if errors.Is(lastErr, errEmptyCaptions) {
+		return nil, &TranscriptError{VideoID: videoID, Err: ErrNoTranscript}
+	}
+	return nil, &TranscriptError{VideoID: videoID, Err: lastErr}
+}
+
+// fallbackTracks returns the tracks of info to try, starting with selected:
+// the tracks in the preferred languages in preference order, then every
+// other track, manual before auto-generated.
+func fallbackTracks(info *ytdlpVideoInfo, selected CaptionTrack, opts *ExtractOptions) []CaptionTrack {
+	pref := LanguagePreference{
+		PreferredLanguages:   opts.Languages,
+		IncludeAutoGenerated: true,
+		PreferManualCaptions: true,
+	}
+	if opts.Preference != nil {
+		pref = *opts.Preference
+	}
+	candidates := captionCandidates(pref)
+	all := availabilityFromInfo(info, info.ID, opts.SkipAutoGenerated)
+	for _, lang := range all.ManualLanguages {
+		candidates = append(candidates, CaptionTrack{Language: lang.Code})
+	}
+	for _, lang := range all.AutoLanguages {
+		candidates = append(candidates, CaptionTrack{Language: lang.Code, IsAutoGenerated: true})
+	}
+
+	tracks := []CaptionTrack{selected}
+	seen := map[CaptionTrack]bool{selected: true}
+	for _, track := range candidates {
+		if seen[track] || (track.IsAutoGenerated && opts.SkipAutoGenerated) || !hasTrack(info, track) {
+			continue
 		}
+		seen[track] = true
+		tracks = append(tracks, track)
 	}
+	return tracks
+}
 
-	// If we couldn't download, return an error
-	if downloadURL != "" && len(entries) == 0 && downloadErr != nil {
-		return nil, &TranscriptError{VideoID: videoID, Err: downloadErr}
+// hasTrack reports whether info lists track.
+func hasTrack(info *ytdlpVideoInfo, track CaptionTrack) bool {
+	if track.IsAutoGenerated {
+		_, ok := info.AutomaticCaptions[track.Language]
+		return ok
 	}
+	_, ok := info.Subtitles[track.Language]
+	return ok
+}
 
-	return &Transcript{
-		VideoID:         videoID,
-		Language:        langKey,
-		LanguageName:    getLanguageName(langKey),
-		IsAutoGenerated: isAutoGenerated,
-		Entries:         entries,
-		DownloadURL:     downloadURL,
-	}, nil
+// json3URL returns the URL of track's JSON3 captions, or "" if yt-dlp
+// listed none.
+func json3URL(info *ytdlpVideoInfo, track CaptionTrack) string {
+	formats := info.Subtitles[track.Language]
+	if track.IsAutoGenerated {
+		formats = info.AutomaticCaptions[track.Language]
+	}
+	for _, f := range formats {
+		if f.Ext == "json3" {
+			return f.URL
+		}
+	}
+	return ""
+}
+
+// availabilityFromInfo lists the caption tracks in yt-dlp video info, sorted
+// by code so fallback selection is deterministic.
+func availabilityFromInfo(info *ytdlpVideoInfo, videoID string, skipAuto bool) *LanguageAvailability {
+	tracks := func(m map[string][]subtitleFormat, auto bool) []LanguageInfo {
+		codes := make([]string, 0, len(m))
+		for code := range m {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		out := make([]LanguageInfo, 0, len(codes))
+		for _, code := range codes {
+			out = append(out, LanguageInfo{Code: code, Name: getLanguageName(code), IsAutoGenerated: auto})
+		}
+		return out
+	}
+
+	la := NewLanguageAvailability(videoID)
+	var auto []LanguageInfo
+	if !skipAuto {
+		auto = tracks(info.AutomaticCaptions, true)
+	}
+	la.Update(tracks(info.Subtitles, false), auto)
+	return la
 }
 
 // downloadTranscript downloads and parses a transcript from the YouTube API.
@@ -388,7 +609,7 @@ func (e *TranscriptError) Unwrap() error {
 }
 
 // ErrNoTranscript indicates the video has no available transcripts.
-var ErrNoTranscript = fmt.Errorf("youtube: no transcript available")
+var ErrNoTranscript = errcode.New(errcode.NotFound, "youtube: no transcript available")
 
 // transcriptErrorClassifier determines if a transcript error is retryable.
 func transcriptErrorClassifier(err error) bool {
@@ -397,6 +618,10 @@ func transcriptErrorClassifier(err error) bool {
 	}
 
 	// Check for permanent errors
+	var subErr *SubprocessError
+	if errors.As(err, &subErr) {
+		return subErr.Retryable()
+	}
 	var transcriptErr *TranscriptError
 	if errors.As(err, &transcriptErr) {
 		switch transcriptErr.Err {
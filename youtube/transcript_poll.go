@@ -0,0 +1,102 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"time"
+	"ytsync/storage"
+	"ytsync/tags"
+)
+
+// pollTranscripts asks again for the transcripts of the channel's earlier
+// videos that are still within the enrichment's TranscriptRetry.PollWindow,
+// whose last attempt failed, and whose next attempt is due. Videos enriched
+// in this run are left out. Only the transcript stage runs; outcomes are
+// recorded in the report and result.
+func (sm *SyncManager) pollTranscripts(ctx context.Context, report *storage.SyncReport, result *SyncResult) {
+	opts := sm.enrichment
+	if opts == nil || opts.Transcripts == nil || opts.Store == nil || opts.TranscriptRetry == nil ||
+		len(opts.TranscriptRetry.PollSchedule) == 0 {
+		return
+	}
+
+	start := time.Now()
+	videos, err := pendingTranscripts(ctx, opts, report.ChannelID, report.NewVideos, start)
+	if err != nil {
+		tags.Logf(ctx, "ytsync: failed to list pending transcripts for %s: %v", report.ChannelID, err)
+		return
+	}
+	if len(videos) == 0 {
+		return
+	}
+
+	poll := sm.progress.enrichOptions(opts)
+	poll.Metadata = nil
+	poll.RecordStats = false
+	sm.progress.phase("enrich")
+	polled, err := Enrich(tags.WithOperation(ctx, "transcript-poll"), report.ChannelID, videos, poll)
+	report.AddPhase("transcript-poll", start)
+	if err != nil {
+		tags.Logf(ctx, "ytsync: transcript polling failed for %s: %v", report.ChannelID, err)
+		return
+	}
+
+	report.TranscriptsPolled += len(polled)
+	for _, r := range polled {
+		report.RecordTranscript(r.VideoID, r.Errors[StageTranscript])
+		if err := r.Errors[StagePersist]; err != nil {
+			report.RecordStageFailure(r.VideoID, StagePersist, err)
+		}
+	}
+	result.PolledTranscripts = polled
+}
+
+// pendingTranscripts returns the stored videos of the channel with YouTube
+// ID channelID, other than those in skip, whose transcript is due another
+// attempt at now under opts.TranscriptRetry's poll schedule.
+func pendingTranscripts(ctx context.Context, opts *EnrichOptions, channelID string, skip []string, now time.Time) ([]VideoInfo, error) {
+	attempts, ok := opts.Store.(storage.TranscriptAttemptStore)
+	if !ok {
+		return nil, nil
+	}
+	channel, err := opts.Store.GetChannelByYouTubeID(ctx, channelID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	stored, err := opts.Store.ListVideosByChannel(ctx, channel.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := make(map[string]bool, len(skip))
+	for _, id := range skip {
+		skipped[id] = true
+	}
+	policy := opts.TranscriptRetry
+	var videos []VideoInfo
+	for _, v := range stored {
+		if skipped[v.YouTubeID] || v.TranscriptFailure == nil || !policy.Polling(v.PublishedAt, now) {
+			continue
+		}
+		history, err := attempts.ListTranscriptAttempts(ctx, v.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !policy.DueFor(v.PublishedAt, history, now) {
+			continue
+		}
+		videos = append(videos, VideoInfo{
+			ID:          v.YouTubeID,
+			Title:       v.Title,
+			ChannelID:   channelID,
+			ChannelName: channel.Name,
+			Published:   v.PublishedAt,
+			Duration:    time.Duration(v.Duration) * time.Second,
+			Description: v.Description,
+		})
+	}
+	return videos, nil
+}
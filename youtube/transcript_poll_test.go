@@ -0,0 +1,88 @@
+package youtube
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+	"ytsync/storage"
+)
+
+func TestSyncManagerPollTranscripts(t *testing.T) {
+	store := newEnrichTestStore(t)
+	ctx := context.Background()
+
+	channel := &storage.Channel{YouTubeID: "UCtest", Name: "Test"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	failure := &storage.TranscriptFailure{Reason: "no captions yet", Code: "not_found"}
+	for _, v := range []struct {
+		id          string
+		published   time.Duration // before now
+		failed      bool
+		lastAttempt time.Duration // before now
+	}{
+		{id: "due", published: 2 * time.Hour, failed: true, lastAttempt: 30 * time.Minute},
+		{id: "waiting", published: 2 * time.Hour, failed: true, lastAttempt: 5 * time.Minute},
+		{id: "old", published: 30 * 24 * time.Hour, failed: true, lastAttempt: 10 * 24 * time.Hour},
+		{id: "captioned", published: time.Hour},
+		{id: "new", published: time.Hour, failed: true, lastAttempt: time.Hour},
+	} {
+		video := &storage.Video{YouTubeID: v.id, ChannelID: channel.ID, Title: v.id, PublishedAt: now.Add(-v.published)}
+		if v.failed {
+			video.TranscriptFailure = failure
+		}
+		if err := store.CreateVideo(ctx, video); err != nil {
+			t.Fatal(err)
+		}
+		if v.failed {
+			attempt := &storage.TranscriptAttempt{VideoID: video.ID, At: now.Add(-v.lastAttempt), Outcome: storage.TranscriptFailed}
+			if err := store.RecordTranscriptAttempt(ctx, attempt); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var fetched []string
+	sm := NewSyncManager(store)
+	sm.SetEnrichment(&EnrichOptions{
+		Metadata: func(ctx context.Context, videoID string) (*VideoMetadata, error) {
+			t.Errorf("metadata fetched for %s while polling transcripts", videoID)
+			return nil, ErrNetworkTimeout
+		},
+		Transcripts: func(ctx context.Context, videoID string) (*Transcript, error) {
+			fetched = append(fetched, videoID)
+			return &Transcript{VideoID: videoID, Language: "en"}, nil
+		},
+		Store: store,
+		TranscriptRetry: &storage.TranscriptRetryPolicy{
+			RetryAfter:   24 * time.Hour,
+			MaxAttempts:  1,
+			PollSchedule: []time.Duration{15 * time.Minute, time.Hour},
+			PollWindow:   7 * 24 * time.Hour,
+		},
+	})
+
+	report := &storage.SyncReport{ChannelID: "UCtest", NewVideos: []string{"new"}}
+	result := &SyncResult{}
+	sm.pollTranscripts(ctx, report, result)
+
+	if !reflect.DeepEqual(fetched, []string{"due"}) {
+		t.Errorf("fetched transcripts of %q, want only the due fresh video", fetched)
+	}
+	if report.TranscriptsPolled != 1 || report.TranscriptsFetched != 1 || len(result.PolledTranscripts) != 1 {
+		t.Errorf("report = %+v, %d polled results; want one polled and fetched", report, len(result.PolledTranscripts))
+	}
+	video, err := store.GetVideoByYouTubeID(ctx, "due")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if video.TranscriptFailure != nil || video.Title != "due" {
+		t.Errorf("video after polling = %+v, want the failure cleared and the title kept", video)
+	}
+	if _, err := store.GetTranscript(ctx, video.ID); err != nil {
+		t.Errorf("GetTranscript() error = %v, want the polled transcript saved", err)
+	}
+}
@@ -0,0 +1,55 @@
+package youtube
+
+import (
+	"strings"
+	"ytsync/storage"
+)
+
+// TranscriptSourceYouTube is the storage source for transcripts fetched from YouTube captions.
+const TranscriptSourceYouTube = "youtube"
+
+// Text returns the transcript entries joined into plain text.
+func (t *Transcript) Text() string {
+	return joinEntryText(t.Entries)
+}
+
+// ToStorage converts the transcript to its storage representation, keeping
+// each entry as a timed segment. videoID is the internal storage Video.ID,
+// which differs from the YouTube video ID.
+func (t *Transcript) ToStorage(videoID string) *storage.Transcript {
+	return &storage.Transcript{
+		VideoID:  videoID,
+		Language: t.Language,
+		Content:  t.Text(),
+		Segments: entriesToSegments(t.Entries),
+		Source:   TranscriptSourceYouTube,
+		// Tracks listed in YouTube's caption data carry the flag; others,
+		// such as fallbacks, are treated as uploaded
+		AutoGenerated: t.IsAutoGenerated,
+	}
+}
+
+// entriesToSegments converts transcript entries to storage segments,
+// translating start/duration into start/end.
+func entriesToSegments(entries []TranscriptEntry) []storage.Segment {
+	segments := make([]storage.Segment, 0, len(entries))
+	for _, e := range entries {
+		segments = append(segments, storage.Segment{
+			Start: e.Start,
+			End:   e.Start + e.Duration,
+			Text:  e.Text,
+		})
+	}
+	return segments
+}
+
+// joinEntryText joins non-empty entry text with single spaces.
+func joinEntryText(entries []TranscriptEntry) string {
+	parts := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if text := strings.TrimSpace(e.Text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,35 @@
+package youtube
+
+import "testing"
+
+func TestTranscript_ToStorage(t *testing.T) {
+	transcript := &Transcript{
+		VideoID:  "dQw4w9WgXcQ",
+		Language: "en",
+		Entries: []TranscriptEntry{
+			{Start: 0, Duration: 1.5, Text: " Never gonna "},
+			{Start: 1.5, Duration: 2, Text: "give you up"},
+			{Start: 3.5, Duration: 1, Text: ""},
+		},
+	}
+
+	got := transcript.ToStorage("internal-id")
+	if got.VideoID != "internal-id" {
+		t.Errorf("VideoID = %q, want %q", got.VideoID, "internal-id")
+	}
+	if got.Content != "Never gonna give you up" {
+		t.Errorf("Content = %q, want %q", got.Content, "Never gonna give you up")
+	}
+	if got.Source != TranscriptSourceYouTube {
+		t.Errorf("Source = %q, want %q", got.Source, TranscriptSourceYouTube)
+	}
+	if len(got.Segments) != 3 {
+		t.Fatalf("len(Segments) = %d, want 3", len(got.Segments))
+	}
+	if seg := got.Segments[1]; seg.Start != 1.5 || seg.End != 3.5 {
+		t.Errorf("Segments[1] = [%v, %v], want [1.5, 3.5]", seg.Start, seg.End)
+	}
+	if text := got.TextAt(2); text != "give you up" {
+		t.Errorf("TextAt(2) = %q, want %q", text, "give you up")
+	}
+}
@@ -0,0 +1,108 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestAllTracks(t *testing.T) {
+	json3 := func(key string) []subtitleFormat {
+		return []subtitleFormat{{URL: "https://example.com/" + key, Ext: "json3"}}
+	}
+	info := &ytdlpVideoInfo{
+		ID: "abc",
+		Subtitles: map[string][]subtitleFormat{
+			"fr":        json3("fr"),
+			"en":        json3("en"),
+			"live_chat": {{URL: "https://example.com/chat", Ext: "json"}},
+		},
+		AutomaticCaptions: map[string][]subtitleFormat{
+			"de-orig": json3("de-orig"),
+			"de":      json3("de"),
+			"en":      json3("en-auto"),
+			"ja":      json3("ja"),
+		},
+	}
+	tests := []struct {
+		name string
+		opts ExtractOptions
+		want []CaptionTrack
+	}{
+		{
+			name: "every language",
+			want: []CaptionTrack{{Language: "en"}, {Language: "fr"}, {Language: "de-orig", IsAutoGenerated: true}},
+		},
+		{
+			name: "skip auto",
+			opts: ExtractOptions{SkipAutoGenerated: true},
+			want: []CaptionTrack{{Language: "en"}, {Language: "fr"}},
+		},
+		{
+			name: "requested translations",
+			opts: ExtractOptions{Languages: []string{"ja", "en"}},
+			want: []CaptionTrack{{Language: "en"}, {Language: "ja", IsAutoGenerated: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allTracks(info, &tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allTracks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscriptExtractorExtractAll(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script stub")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := strings.TrimPrefix(r.URL.Path, "/")
+		if lang == "empty" {
+			fmt.Fprint(w, `{"events": []}`)
+			return
+		}
+		fmt.Fprintf(w, `{"events": [{"tStartMs": 0, "dDurationMs": 1000, "segs": [{"utf8": "hello %s"}]}]}`, lang)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "yt-dlp")
+	body := fmt.Sprintf(`#!/bin/sh
+cat <<'EOF'
+{"id": "abc", "subtitles": {"en": [{"url": "%[1]s/en", "ext": "json3"}], "es": [{"url": "%[1]s/empty", "ext": "json3"}]},
+ "automatic_captions": {"de-orig": [{"url": "%[1]s/de", "ext": "json3"}], "fr": [{"url": "%[1]s/fr", "ext": "json3"}]}}
+EOF
+`, server.URL)
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	te := NewTranscriptExtractor()
+	te.YtdlpPath = script
+
+	transcripts, err := te.ExtractAll(context.Background(), "abc", nil)
+	if err != nil {
+		t.Fatalf("ExtractAll() error = %v", err)
+	}
+	var got []string
+	for _, tr := range transcripts {
+		got = append(got, fmt.Sprintf("%s/%v/%s", tr.Language, tr.IsAutoGenerated, tr.Entries[0].Text))
+	}
+	// The empty es track is skipped and the fr translation left out
+	if want := []string{"en/false/hello en", "de/true/hello de"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractAll() = %q, want %q", got, want)
+	}
+
+	if _, err := te.ExtractAll(context.Background(), "abc", &ExtractOptions{Languages: []string{"es"}}); !errors.Is(err, ErrNoTranscript) {
+		t.Errorf("ExtractAll(es) error = %v, want ErrNoTranscript", err)
+	}
+}
@@ -0,0 +1,216 @@
+package youtube
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+	"ytsync/media"
+)
+
+// AudioChunkFormat is the container/codec used for transcription chunks.
+type AudioChunkFormat string
+
+const (
+	// AudioChunkWAV writes 16-bit PCM WAV files.
+	AudioChunkWAV AudioChunkFormat = "wav"
+	// AudioChunkFLAC writes lossless FLAC files, about half the size of WAV.
+	AudioChunkFLAC AudioChunkFormat = "flac"
+)
+
+// Defaults for DownloadAudioForTranscription.
+const (
+	// DefaultTranscriptionChunkLength keeps 16kHz mono WAV chunks under the
+	// 25 MB upload limit of common speech-to-text APIs.
+	DefaultTranscriptionChunkLength = 10 * time.Minute
+	// DefaultTranscriptionSampleRate is the sample rate Whisper-style models expect.
+	DefaultTranscriptionSampleRate = 16000
+)
+
+// TranscriptionAudioOptions configures DownloadAudioForTranscription.
+type TranscriptionAudioOptions struct {
+	// OutputDir is the directory for the chunk files. Defaults to the current directory.
+	OutputDir string
+	// Format is the chunk file format. Defaults to AudioChunkWAV.
+	Format AudioChunkFormat
+	// ChunkLength is the length of each chunk. Defaults to
+	// DefaultTranscriptionChunkLength. Zero or negative values use the default.
+	ChunkLength time.Duration
+	// Overlap is how much each chunk repeats the end of the previous one, so
+	// words cut at a boundary appear whole in one chunk. Must be shorter
+	// than ChunkLength.
+	Overlap time.Duration
+	// SampleRate is the output sample rate in Hz. Defaults to DefaultTranscriptionSampleRate.
+	SampleRate int
+	// KeepSource keeps the downloaded source audio instead of deleting it
+	// after the chunks are written.
+	KeepSource bool
+}
+
+// AudioChunk is one piece of a video's audio prepared for transcription.
+type AudioChunk struct {
+	// Index is the zero-based chunk position.
+	Index int `json:"index"`
+	// Path is the chunk file path.
+	Path string `json:"path"`
+	// Start is the chunk start offset in the video, in seconds.
+	Start float64 `json:"start"`
+	// End is the chunk end offset in the video, in seconds.
+	End float64 `json:"end"`
+}
+
+// TranscriptionAudio is the result of DownloadAudioForTranscription.
+type TranscriptionAudio struct {
+	// VideoID is the YouTube video ID.
+	VideoID string `json:"video_id"`
+	// SourcePath is the downloaded source audio (empty unless KeepSource was set).
+	SourcePath string `json:"source_path,omitempty"`
+	// Duration is the audio duration in seconds.
+	Duration float64 `json:"duration"`
+	// SampleRate is the chunk sample rate in Hz.
+	SampleRate int `json:"sample_rate"`
+	// Format is the chunk file format.
+	Format AudioChunkFormat `json:"format"`
+	// Chunks are the chunk files in order.
+	Chunks []AudioChunk `json:"chunks"`
+}
+
+// TranscriptionChunkPath returns the deterministic path of a chunk file:
+// <dir>/<videoID>_<index>.<format>, with the index zero-padded to 4 digits.
+func TranscriptionChunkPath(dir, videoID string, index int, format AudioChunkFormat) string {
+	return filepath.Join(dir, fmt.Sprintf("%s_%04d.%s", sanitizeFilename(videoID), index, format))
+}
+
+// DownloadAudioForTranscription downloads a video's best audio stream and
+// converts it to mono chunks at the configured sample rate, ready for
+// Whisper-style speech-to-text pipelines. Chunk files are named with
+// TranscriptionChunkPath, so re-running for the same video overwrites the
+// same files; chunks of the format past the last one written, left by an
+// earlier run with shorter chunks, are removed. Requires ffmpeg; ffprobe
+// is used for probing when available.
+func (d *Downloader) DownloadAudioForTranscription(ctx context.Context, videoID string, opts *TranscriptionAudioOptions) (*TranscriptionAudio, error) {
+	if opts == nil {
+		opts = &TranscriptionAudioOptions{}
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = AudioChunkWAV
+	}
+	if format != AudioChunkWAV && format != AudioChunkFLAC {
+		return nil, fmt.Errorf("unsupported chunk format %q", format)
+	}
+	chunkLength := opts.ChunkLength
+	if chunkLength <= 0 {
+		chunkLength = DefaultTranscriptionChunkLength
+	}
+	if opts.Overlap < 0 || opts.Overlap >= chunkLength {
+		return nil, fmt.Errorf("overlap %v must be non-negative and shorter than chunk length %v", opts.Overlap, chunkLength)
+	}
+	sampleRate := opts.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = DefaultTranscriptionSampleRate
+	}
+	// yt-dlp reports the downloaded file by path, so use an absolute directory
+	outputDir, err := filepath.Abs(opts.OutputDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve output directory: %w", err)
+	}
+
+	// Download the original audio stream without re-encoding
+	download, err := d.Download(ctx, videoID, &DownloadOptions{
+		OutputDir: outputDir,
+		Format:    "bestaudio/best",
+		Filename:  videoID + ".source",
+	})
+	if err != nil {
+		return nil, err
+	}
+	source := download.VideoPath
+	if source == "" || source == outputDir {
+		return nil, fmt.Errorf("download audio: yt-dlp did not report an output file")
+	}
+	if !opts.KeepSource {
+		defer os.Remove(source)
+	}
+
+	duration, err := d.mediaTool().Duration(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("probe audio duration: %w", err)
+	}
+
+	result := &TranscriptionAudio{
+		VideoID:    videoID,
+		Duration:   duration,
+		SampleRate: sampleRate,
+		Format:     format,
+	}
+	if opts.KeepSource {
+		result.SourcePath = source
+	}
+
+	for i, span := range chunkSpans(duration, chunkLength.Seconds(), opts.Overlap.Seconds()) {
+		chunk := AudioChunk{
+			Index: i,
+			Path:  TranscriptionChunkPath(outputDir, videoID, i, format),
+			Start: span[0],
+			End:   span[1],
+		}
+		if err := d.extractAudioChunk(ctx, source, chunk, sampleRate, format); err != nil {
+			return nil, fmt.Errorf("extract chunk %d: %w", i, err)
+		}
+		result.Chunks = append(result.Chunks, chunk)
+	}
+	removeChunksFrom(outputDir, videoID, len(result.Chunks), format)
+
+	return result, nil
+}
+
+// removeChunksFrom deletes the chunk files of videoID numbered index and
+// up. Chunks are numbered without gaps, so it stops at the first missing
+// one.
+func removeChunksFrom(dir, videoID string, index int, format AudioChunkFormat) {
+	for ; ; index++ {
+		if err := os.Remove(TranscriptionChunkPath(dir, videoID, index, format)); err != nil {
+			return
+		}
+	}
+}
+
+// chunkSpans splits [0, duration) into windows of length seconds, each
+// starting overlap seconds before the previous one ends.
+func chunkSpans(duration, length, overlap float64) [][2]float64 {
+	var spans [][2]float64
+	step := length - overlap
+	for start := 0.0; start < duration; start += step {
+		end := math.Min(start+length, duration)
+		spans = append(spans, [2]float64{start, end})
+		if end >= duration {
+			break
+		}
+	}
+	return spans
+}
+
+// extractAudioChunk converts one span of source into a mono chunk file.
+func (d *Downloader) extractAudioChunk(ctx context.Context, source string, chunk AudioChunk, sampleRate int, format AudioChunkFormat) error {
+	codec := "pcm_s16le"
+	if format == AudioChunkFLAC {
+		codec = "flac"
+	}
+
+	return d.mediaTool().ExtractAudio(ctx, source, chunk.Path, media.AudioOptions{
+		Start:      secondsToDuration(chunk.Start),
+		Duration:   secondsToDuration(chunk.End - chunk.Start),
+		Channels:   1,
+		SampleRate: sampleRate,
+		Codec:      codec,
+	})
+}
+
+// secondsToDuration converts fractional seconds to a time.Duration.
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds * float64(time.Second)))
+}
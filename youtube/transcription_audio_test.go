@@ -0,0 +1,141 @@
+package youtube
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChunkSpans(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration float64
+		length   float64
+		overlap  float64
+		want     [][2]float64
+	}{
+		{"shorter than one chunk", 45, 60, 0, [][2]float64{{0, 45}}},
+		{"exact multiple", 120, 60, 0, [][2]float64{{0, 60}, {60, 120}}},
+		{"with overlap", 130, 60, 5, [][2]float64{{0, 60}, {55, 115}, {110, 130}}},
+		{"empty", 0, 60, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chunkSpans(tt.duration, tt.length, tt.overlap)
+			if len(got) != len(tt.want) {
+				t.Fatalf("chunkSpans() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("span %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTranscriptionChunkPath(t *testing.T) {
+	got := TranscriptionChunkPath("/out", "dQw4w9WgXcQ", 3, AudioChunkFLAC)
+	if want := filepath.Join("/out", "dQw4w9WgXcQ_0003.flac"); got != want {
+		t.Errorf("TranscriptionChunkPath() = %q, want %q", got, want)
+	}
+}
+
+// writeMockScript creates an executable shell script in dir.
+func writeMockScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("mock tools require a POSIX shell")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatalf("failed to create mock %s: %v", name, err)
+	}
+	return path
+}
+
+func TestDownloader_DownloadAudioForTranscription(t *testing.T) {
+	tools := t.TempDir()
+	outDir := t.TempDir()
+	argsLog := filepath.Join(tools, "ffmpeg.log")
+
+	// yt-dlp: write the source file named by the -o template and print its path
+	ytdlp := writeMockScript(t, tools, "yt-dlp", `
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-o" ]; then out="$2"; fi
+  shift
+done
+path=$(echo "$out" | sed 's/%(ext)s/webm/')
+echo audio > "$path"
+echo "$path"
+`)
+	ffprobe := writeMockFFprobe(t, tools, "130.0")
+	// ffmpeg: log arguments and create the output file (last argument)
+	ffmpeg := writeMockScript(t, tools, "ffmpeg", `
+echo "$@" >> "`+argsLog+`"
+for last; do :; done
+echo chunk > "$last"
+`)
+
+	// Chunks left by an earlier run that made more of them
+	for i := 3; i < 5; i++ {
+		if err := os.WriteFile(TranscriptionChunkPath(outDir, "abc123", i, AudioChunkFLAC), []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := &Downloader{YtdlpPath: ytdlp, FFprobePath: ffprobe, FFmpegPath: ffmpeg}
+	result, err := d.DownloadAudioForTranscription(context.Background(), "abc123", &TranscriptionAudioOptions{
+		OutputDir:   outDir,
+		Format:      AudioChunkFLAC,
+		ChunkLength: time.Minute,
+		Overlap:     5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("DownloadAudioForTranscription() error = %v", err)
+	}
+
+	if len(result.Chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(result.Chunks))
+	}
+	for i, chunk := range result.Chunks {
+		if want := TranscriptionChunkPath(outDir, "abc123", i, AudioChunkFLAC); chunk.Path != want {
+			t.Errorf("chunk %d path = %q, want %q", i, chunk.Path, want)
+		}
+		if _, err := os.Stat(chunk.Path); err != nil {
+			t.Errorf("chunk %d not written: %v", i, err)
+		}
+	}
+	if result.Chunks[1].Start != 55 || result.Chunks[2].End != 130 {
+		t.Errorf("unexpected chunk spans: %+v", result.Chunks)
+	}
+	for i := 3; i < 5; i++ {
+		if _, err := os.Stat(TranscriptionChunkPath(outDir, "abc123", i, AudioChunkFLAC)); !os.IsNotExist(err) {
+			t.Errorf("stale chunk %d should be removed", i)
+		}
+	}
+
+	logData, _ := os.ReadFile(argsLog)
+	if !strings.Contains(string(logData), "-ac 1 -ar 16000 -c:a flac") {
+		t.Errorf("ffmpeg args missing mono/16kHz/flac: %s", logData)
+	}
+
+	if _, err := os.Stat(filepath.Join(outDir, "abc123.source.webm")); !os.IsNotExist(err) {
+		t.Error("source audio should be removed when KeepSource is false")
+	}
+}
+
+func TestDownloader_DownloadAudioForTranscription_InvalidOverlap(t *testing.T) {
+	d := NewDownloader()
+	_, err := d.DownloadAudioForTranscription(context.Background(), "abc123", &TranscriptionAudioOptions{
+		ChunkLength: time.Minute,
+		Overlap:     time.Minute,
+	})
+	if err == nil {
+		t.Fatal("expected error for overlap >= chunk length")
+	}
+}
@@ -0,0 +1,112 @@
+package youtube
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"ytsync/errcode"
+)
+
+// ErrInvalidVideoID is matched by the *VideoIDError returned by
+// ParseVideoID for input that does not name a YouTube video.
+var ErrInvalidVideoID = errcode.New(errcode.InvalidInput, "youtube: invalid video ID")
+
+// VideoIDError is returned by ParseVideoID for input that does not name a
+// YouTube video. It matches ErrInvalidVideoID.
+type VideoIDError struct {
+	// Input is the string that was parsed.
+	Input string
+	// Reason says what is wrong with it.
+	Reason string
+}
+
+// Error returns the input and the reason it was rejected.
+func (e *VideoIDError) Error() string {
+	return fmt.Sprintf("youtube: invalid video ID %q: %s", e.Input, e.Reason)
+}
+
+// Unwrap returns ErrInvalidVideoID.
+func (e *VideoIDError) Unwrap() error {
+	return ErrInvalidVideoID
+}
+
+// videoPathPrefixes are the youtube.com paths followed by a video ID.
+var videoPathPrefixes = []string{"/shorts/", "/embed/", "/live/", "/v/", "/e/"}
+
+// ParseVideoID returns the 11-character video ID named by input, which may
+// be a bare ID or a URL of the video: a watch URL, a youtu.be short link,
+// or a shorts, live, or embed URL, with or without scheme, "www." or "m.",
+// and with any further query parameters or fragment. It returns a
+// *VideoIDError if input names no video or the ID is malformed.
+func ParseVideoID(input string) (string, error) {
+	s := strings.TrimSpace(input)
+	if IsValidVideoID(s) {
+		return s, nil
+	}
+	fail := func(reason string) (string, error) {
+		return "", &VideoIDError{Input: input, Reason: reason}
+	}
+	if s == "" {
+		return fail("empty")
+	}
+	if !strings.Contains(s, "/") {
+		return fail("not an 11-character ID or a URL")
+	}
+
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return fail("not a URL")
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m.", "music."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+
+	var id string
+	switch host {
+	case "youtu.be":
+		id, _, _ = strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	case "youtube.com", "youtube-nocookie.com":
+		if u.Path == "/watch" {
+			id = u.Query().Get("v")
+			break
+		}
+		for _, prefix := range videoPathPrefixes {
+			if rest, ok := strings.CutPrefix(u.Path, prefix); ok {
+				id, _, _ = strings.Cut(rest, "/")
+				break
+			}
+		}
+	default:
+		return fail("not a YouTube URL")
+	}
+	if id == "" {
+		return fail("URL does not name a video")
+	}
+	if !IsValidVideoID(id) {
+		return fail(fmt.Sprintf("malformed video ID %q", id))
+	}
+	return id, nil
+}
+
+// IsValidVideoID reports whether id has the form of a YouTube video ID: 11
+// characters from A-Z, a-z, 0-9, "-", and "_".
+func IsValidVideoID(id string) bool {
+	if len(id) != 11 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// WatchURL returns the canonical watch URL of the video id.
+func WatchURL(id string) string {
+	return "https://www.youtube.com/watch?v=" + id
+}
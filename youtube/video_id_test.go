@@ -0,0 +1,56 @@
+package youtube
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVideoID(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"  dQw4w9WgXcQ\n", "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/watch?feature=share&v=dQw4w9WgXcQ&t=42s#comments", "dQw4w9WgXcQ"},
+		{"http://m.youtube.com/watch?v=dQw4w9WgXcQ&list=PL123", "dQw4w9WgXcQ"},
+		{"youtube.com/watch?v=dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://music.youtube.com/watch?v=dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://youtu.be/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"youtu.be/dQw4w9WgXcQ?si=abc&t=10", "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ?feature=share", "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/embed/dQw4w9WgXcQ?start=30", "dQw4w9WgXcQ"},
+		{"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://www.youtube.com/live/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+		{"https://WWW.YOUTUBE.COM/v/dQw4w9WgXcQ", "dQw4w9WgXcQ"},
+	}
+	for _, tt := range tests {
+		got, err := ParseVideoID(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseVideoID(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseVideoIDInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"dQw4w9WgXc",
+		"dQw4w9WgXcQQ",
+		"dQw4w9WgX!Q",
+		"--version",
+		"https://vimeo.com/watch?v=dQw4w9WgXcQ",
+		"https://www.youtube.com/watch?v=dQw4w9",
+		"https://www.youtube.com/watch?list=PL123",
+		"https://www.youtube.com/@Fireship",
+		"https://www.youtube.com/channel/UCsBjURrPoezykLs9EqgamOA",
+		"https://youtu.be/",
+	} {
+		id, err := ParseVideoID(input)
+		var idErr *VideoIDError
+		if !errors.As(err, &idErr) || !errors.Is(err, ErrInvalidVideoID) || idErr.Input != input {
+			t.Errorf("ParseVideoID(%q) = %q, %v, want a *VideoIDError", input, id, err)
+		}
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"ytsync/proc"
 	"ytsync/retry"
+	"ytsync/tags"
 )
 
 const (
@@ -29,6 +32,14 @@ type YtdlpLister struct {
 	// ExtraArgs are additional arguments to pass to yt-dlp.
 	ExtraArgs []string
 
+	// Incremental makes listings with a date-sorted ListOptions stop early
+	// instead of walking the whole channel: at the first video older than
+	// PublishedAfter, at the first of KnownVideoIDs, or after MaxResults
+	// entries when no content filter can drop any. It needs yt-dlp
+	// 2023.06.21 or later. Do not combine it with a --match-filter in
+	// ExtraArgs, which would also end the listing.
+	Incremental bool
+
 	// RetryConfig holds retry behavior configuration.
 	RetryConfig *retry.Config
 }
@@ -45,6 +56,10 @@ func NewYtdlpLister() *YtdlpLister {
 
 // ListVideos fetches all videos from the specified channel using yt-dlp.
 func (y *YtdlpLister) ListVideos(ctx context.Context, channelURL string, opts *ListOptions) ([]VideoInfo, error) {
+	if id := extractChannelIDDirect(channelURL); id != "" {
+		ctx = tags.Default(ctx, tags.Channel, id)
+	}
+
 	// Check if yt-dlp is installed
 	if err := y.checkInstalled(ctx); err != nil {
 		return nil, err
@@ -57,29 +72,14 @@ func (y *YtdlpLister) ListVideos(ctx context.Context, channelURL string, opts *L
 		cfg = &defaultCfg
 	}
 
-	contentType := opts.ContentType
-	if contentType == 0 {
-		contentType = ContentTypeVideos
+	contentType := ContentTypeVideos
+	if opts != nil {
+		contentType = opts.ContentType
 	}
 
 	// If ContentTypeBoth, fetch both videos and streams
 	if contentType == ContentTypeBoth {
-		videosOpts := *opts
-		videosOpts.ContentType = ContentTypeVideos
-		videosList, err := y.ListVideos(ctx, channelURL, &videosOpts)
-		if err != nil {
-			return nil, err
-		}
-
-		streamsOpts := *opts
-		streamsOpts.ContentType = ContentTypeStreams
-		streamsList, err := y.ListVideos(ctx, channelURL, &streamsOpts)
-		if err != nil {
-			return nil, err
-		}
-
-		videos = append(videosList, streamsList...)
-		return videos, nil
+		return y.listBoth(ctx, channelURL, opts)
 	}
 
 	err := retry.Do(ctx, *cfg, ytdlpErrorClassifier, func(ctx context.Context) error {
@@ -90,16 +90,26 @@ func (y *YtdlpLister) ListVideos(ctx context.Context, channelURL string, opts *L
 		// We use --flat-playlist for speed (lists entries without fetching full metadata).
 		// Trade-off: Some fields like dates may be missing for certain content types.
 		// To get full metadata for a specific video, use --skip-playlist.
+		output := "-J" // JSON output
+		incremental := y.Incremental && opts != nil && opts.SortOrder == SortByDate
+		if incremental {
+			// One JSON line per entry, printed as it is listed, so the
+			// entries before an early stop are not lost
+			output = "-j"
+		}
 		args := []string{
 			"--flat-playlist",
-			"-J", // JSON output
+			output,
 			"--no-warnings",
 		}
+		if incremental {
+			args = append(args, incrementalArgs(opts)...)
+		}
 
 		// Add sorting if specified
-		if opts != nil && opts.SortOrder == SortByPopularity {
+		if opts != nil && opts.SortOrder == SortByPopularity && strings.HasSuffix(url, "/"+tabName(contentType)) {
 			args = append(args, "--playlist-items", "1-")
-			url = strings.TrimSuffix(url, "/videos") + "/videos?view=0&sort=p"
+			url += "?view=0&sort=p"
 		}
 
 		// Add extra args
@@ -114,13 +124,18 @@ func (y *YtdlpLister) ListVideos(ctx context.Context, channelURL string, opts *L
 		cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
 
-		cmd := exec.CommandContext(cmdCtx, y.path(), args...)
-
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
-
-		err := cmd.Run()
+		stdout, err := runYtdlp(cmdCtx, y.path(), args...)
+		var subErr *SubprocessError
+		if incremental && errors.As(err, &subErr) && subErr.ExitCode == ytdlpExitCancelled {
+			// yt-dlp stopped at a break condition
+			err = nil
+		}
+		if isMissingTab(err) {
+			// A channel that never streamed has no Live tab, one with
+			// only Shorts or streams no Videos tab
+			videos = nil
+			return nil
+		}
 		if err != nil {
 			if cmdCtx.Err() == context.DeadlineExceeded {
 				return &ListerError{Source: "ytdlp", Channel: channelURL, Err: ErrNetworkTimeout}
@@ -128,22 +143,15 @@ func (y *YtdlpLister) ListVideos(ctx context.Context, channelURL string, opts *L
 			if cmdCtx.Err() == context.Canceled {
 				return &ListerError{Source: "ytdlp", Channel: channelURL, Err: context.Canceled}
 			}
-
-			// Check for common error patterns in stderr
-			errMsg := stderr.String()
-			if strings.Contains(errMsg, "not found") || strings.Contains(errMsg, "does not exist") {
-				return &ListerError{Source: "ytdlp", Channel: channelURL, Err: ErrChannelNotFound}
-			}
-			if strings.Contains(errMsg, "rate") || strings.Contains(errMsg, "429") {
-				return &ListerError{Source: "ytdlp", Channel: channelURL, Err: ErrRateLimited}
-			}
-
-			return &ListerError{Source: "ytdlp", Channel: channelURL,
-				Err: fmt.Errorf("yt-dlp failed: %w: %s", err, errMsg)}
+			return &ListerError{Source: "ytdlp", Channel: channelURL, Err: err}
 		}
 
 		// Parse JSON output
-		parsedVideos, parseErr := parseYtdlpOutput(stdout.Bytes(), contentType)
+		parse := parseYtdlpOutput
+		if incremental {
+			parse = parseYtdlpLines
+		}
+		parsedVideos, parseErr := parse(stdout, contentType)
 		if parseErr != nil {
 			return parseErr
 		}
@@ -163,6 +171,136 @@ func (y *YtdlpLister) ListVideos(ctx context.Context, channelURL string, opts *L
 	return videos, nil
 }
 
+// FetchVideo returns the listing entry of a single video, as ListVideos
+// would return it, with its channel, duration, and publish time. videoID
+// may be an ID or any URL ParseVideoID accepts.
+func (y *YtdlpLister) FetchVideo(ctx context.Context, videoID string) (VideoInfo, error) {
+	id, err := ParseVideoID(videoID)
+	if err != nil {
+		return VideoInfo{}, err
+	}
+	timeout := y.Timeout
+	if timeout == 0 {
+		timeout = defaultYtdlpTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := append([]string{"-J", "--no-warnings"}, y.ExtraArgs...)
+	stdout, err := runYtdlp(ctx, y.path(), append(args, "--", id)...)
+	if err != nil {
+		return VideoInfo{}, fmt.Errorf("fetch video %s: %w", id, err)
+	}
+	var entry ytdlpEntry
+	if err := json.Unmarshal(stdout, &entry); err != nil {
+		return VideoInfo{}, fmt.Errorf("parse yt-dlp output: %w", err)
+	}
+	contentType := ContentTypeVideos
+	if entry.LiveStatus == "is_live" || entry.LiveStatus == "was_live" || entry.LiveStatus == "post_live" {
+		contentType = ContentTypeStreams
+	}
+	return ytdlpVideo(entry, "", "", contentType), nil
+}
+
+// ytdlpExitCancelled is yt-dlp's exit status when a --break-* option or
+// --max-downloads ended the run early.
+const ytdlpExitCancelled = 101
+
+// maxKnownVideoIDs bounds how many known videos the break filter names.
+// Only the newest matters unless it was deleted.
+const maxKnownVideoIDs = 10
+
+// ytdlpDateAfterMargin widens the --dateafter cut-off, because dates in flat
+// listings are derived from rounded relative times such as "2 weeks ago".
+// Videos in the margin are removed again by the exact date filter.
+const ytdlpDateAfterMargin = 7 * 24 * time.Hour
+
+// incrementalArgs returns the yt-dlp arguments that end a newest-first
+// listing once it has passed what opts asks for.
+func incrementalArgs(opts *ListOptions) []string {
+	var args []string
+	if !opts.PublishedAfter.IsZero() {
+		cutoff := opts.PublishedAfter.Add(-ytdlpDateAfterMargin)
+		args = append(args,
+			"--extractor-args", "youtubetab:approximate_date",
+			"--dateafter", cutoff.UTC().Format("20060102"),
+			"--break-on-reject")
+	}
+	if known := opts.KnownVideoIDs; len(known) > 0 {
+		if len(known) > maxKnownVideoIDs {
+			known = known[:maxKnownVideoIDs]
+		}
+		filters := make([]string, len(known))
+		for i, id := range known {
+			filters[i] = "id!=" + id
+		}
+		args = append(args, "--break-match-filters", strings.Join(filters, " & "))
+	}
+	if opts.MaxResults > 0 && !opts.hasContentFilters() {
+		args = append(args, "--playlist-end", strconv.Itoa(opts.MaxResults))
+	}
+	return args
+}
+
+// listBoth lists the Videos and Live tabs of a channel with one yt-dlp run
+// each and merges the results: every video once, newest first (or most
+// viewed first for SortByPopularity), cut to MaxResults.
+func (y *YtdlpLister) listBoth(ctx context.Context, channelURL string, opts *ListOptions) ([]VideoInfo, error) {
+	var merged []VideoInfo
+	for _, contentType := range []ContentType{ContentTypeVideos, ContentTypeStreams} {
+		tabOpts := *opts
+		tabOpts.ContentType = contentType
+		videos, err := y.ListVideos(ctx, channelURL, &tabOpts)
+		if err != nil {
+			return nil, err
+		}
+		merged = append(merged, videos...)
+		// Playlists have no tabs, so the second run would list them again
+		if strings.Contains(channelURL, "list=") {
+			break
+		}
+	}
+	return mergeTabs(merged, opts), nil
+}
+
+// mergeTabs de-duplicates videos listed from several tabs, preferring the
+// Live tab's entry for a video on both, and orders them by opts.SortOrder.
+func mergeTabs(videos []VideoInfo, opts *ListOptions) []VideoInfo {
+	index := make(map[string]int, len(videos))
+	merged := make([]VideoInfo, 0, len(videos))
+	for _, v := range videos {
+		if i, ok := index[v.ID]; ok {
+			if v.Type == VideoTypeStream {
+				merged[i] = v
+			}
+			continue
+		}
+		index[v.ID] = len(merged)
+		merged = append(merged, v)
+	}
+
+	if opts.SortOrder == SortByPopularity {
+		sort.SliceStable(merged, func(i, j int) bool { return merged[i].ViewCount > merged[j].ViewCount })
+	} else {
+		sort.SliceStable(merged, func(i, j int) bool { return merged[i].Published.After(merged[j].Published) })
+	}
+	if opts.MaxResults > 0 && len(merged) > opts.MaxResults {
+		merged = merged[:opts.MaxResults]
+	}
+	return merged
+}
+
+// isMissingTab reports whether yt-dlp failed because the channel has no
+// tab of the requested kind.
+func isMissingTab(err error) bool {
+	var subErr *SubprocessError
+	if !errors.As(err, &subErr) {
+		return false
+	}
+	msg := strings.ToLower(subErr.Stderr)
+	return strings.Contains(msg, "does not have a") && strings.Contains(msg, " tab")
+}
+
 // SupportsFullHistory returns true - yt-dlp can retrieve all videos.
 func (y *YtdlpLister) SupportsFullHistory() bool {
 	return true
@@ -170,7 +308,7 @@ func (y *YtdlpLister) SupportsFullHistory() bool {
 
 // checkInstalled verifies that yt-dlp is available.
 func (y *YtdlpLister) checkInstalled(ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, y.path(), "--version")
+	cmd := proc.Command(ctx, y.path(), "--version")
 	if err := cmd.Run(); err != nil {
 		return &ListerError{Source: "ytdlp", Channel: "", Err: ErrYtdlpNotInstalled}
 	}
@@ -186,9 +324,11 @@ func (y *YtdlpLister) path() string {
 
 // normalizeChannelURL ensures the URL points to the correct tab (videos or streams).
 func normalizeChannelURL(url string, contentType ContentType) string {
-	tab := "videos"
-	if contentType == ContentTypeStreams {
-		tab = "streams"
+	tab := tabName(contentType)
+
+	// Playlists have no tabs
+	if strings.Contains(url, "list=") {
+		return url
 	}
 
 	// If it's just a channel ID, construct full URL
@@ -210,6 +350,14 @@ func normalizeChannelURL(url string, contentType ContentType) string {
 	return url
 }
 
+// tabName returns the path of the channel tab listing contentType.
+func tabName(contentType ContentType) string {
+	if contentType == ContentTypeStreams {
+		return "streams"
+	}
+	return "videos"
+}
+
 // ytdlpPlaylist represents yt-dlp's JSON output for a playlist/channel.
 type ytdlpPlaylist struct {
 	ID          string       `json:"id"`
@@ -238,6 +386,8 @@ type ytdlpEntry struct {
 	Timestamp        int64            `json:"timestamp"`         // Unix timestamp
 	ReleaseTimestamp int64            `json:"release_timestamp"` // Unix timestamp (for premieres/streams)
 	CreatedAt        int64            `json:"created_at"`        // Unix timestamp (creation time)
+	LiveStatus       string           `json:"live_status"`       // is_upcoming, is_live, was_live, not_live
+	Availability     string           `json:"availability"`      // public, unlisted, subscriber_only, ...
 	Thumbnail        string           `json:"thumbnail"`
 	Thumbnails       []ytdlpThumbnail `json:"thumbnails"`
 }
@@ -255,31 +405,75 @@ func parseYtdlpOutput(data []byte, contentType ContentType) ([]VideoInfo, error)
 		return nil, fmt.Errorf("parse yt-dlp output: %w", err)
 	}
 
-	videoType := "video"
-	if contentType == ContentTypeStreams {
-		videoType = "stream"
-	}
-
 	videos := make([]VideoInfo, 0, len(playlist.Entries))
 	for _, entry := range playlist.Entries {
-		video := VideoInfo{
-			ID:          entry.ID,
-			Title:       entry.Title,
-			ChannelID:   coalesce(entry.ChannelID, playlist.ChannelID),
-			ChannelName: coalesce(entry.Uploader, playlist.Uploader),
-			Duration:    time.Duration(entry.Duration) * time.Second,
-			Description: entry.Description,
-			ViewCount:   entry.ViewCount,
-			Thumbnail:   bestThumbnail(entry),
-			Published:   parseYtdlpDate(entry),
-			Type:        videoType,
-		}
-		videos = append(videos, video)
+		videos = append(videos, ytdlpVideo(entry, playlist.ChannelID, playlist.Uploader, contentType))
 	}
 
 	return videos, nil
 }
 
+// ytdlpLine is one line of yt-dlp's -j output for a flat playlist: an
+// entry with the playlist's fields added.
+type ytdlpLine struct {
+	ytdlpEntry
+	PlaylistChannelID string `json:"playlist_channel_id"`
+	PlaylistUploader  string `json:"playlist_uploader"`
+}
+
+// parseYtdlpLines parses yt-dlp's line-delimited JSON output (-j) into a
+// VideoInfo slice.
+func parseYtdlpLines(data []byte, contentType ContentType) ([]VideoInfo, error) {
+	var videos []VideoInfo
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var entry ytdlpLine
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("parse yt-dlp output: %w", err)
+		}
+		videos = append(videos, ytdlpVideo(entry.ytdlpEntry, entry.PlaylistChannelID, entry.PlaylistUploader, contentType))
+	}
+	return videos, nil
+}
+
+// ytdlpVideo converts a yt-dlp entry, falling back to the playlist's channel
+// for fields the entry lacks.
+func ytdlpVideo(entry ytdlpEntry, channelID, channelName string, contentType ContentType) VideoInfo {
+	videoType := VideoTypeVideo
+	if contentType == ContentTypeStreams {
+		videoType = VideoTypeStream
+	}
+	video := VideoInfo{
+		ID:          entry.ID,
+		Title:       entry.Title,
+		ChannelID:   coalesce(entry.ChannelID, channelID),
+		ChannelName: coalesce(entry.Uploader, channelName),
+		Duration:    time.Duration(entry.Duration) * time.Second,
+		Description: entry.Description,
+		ViewCount:   entry.ViewCount,
+		Thumbnail:   bestThumbnail(entry),
+		Published:   parseYtdlpDate(entry),
+		Type:        videoType,
+	}
+	video.LiveStatus = ytdlpLiveStatus(entry.LiveStatus)
+	if contentType == ContentTypeStreams && video.LiveStatus == LiveStatusNone {
+		// Everything on the Live tab is or was a stream. Flat listings
+		// leave out live_status for most past broadcasts.
+		video.LiveStatus = LiveStatusEnded
+	}
+	video.IsMembersOnly = ytdlpMembersOnly(entry.Availability)
+	video.ScheduledStartTime = ytdlpScheduledStart(video.LiveStatus, entry.ReleaseTimestamp)
+	// Premieres are listed on the Videos tab, scheduled streams on the
+	// Live tab
+	if contentType == ContentTypeVideos && video.LiveStatus != LiveStatusNone && video.LiveStatus != LiveStatusEnded {
+		video.IsPremiere = true
+	}
+	return video
+}
+
 // parseYtdlpDate extracts the published time from a yt-dlp entry.
 // Tries multiple date fields in order of preference, since different content types
 // (regular videos, premieres, live streams) use different fields.
@@ -342,6 +536,10 @@ func ytdlpErrorClassifier(err error) bool {
 	}
 
 	// Permanent errors - don't retry
+	var subErr *SubprocessError
+	if errors.As(err, &subErr) {
+		return subErr.Retryable()
+	}
 	var listerErr *ListerError
 	if errors.As(err, &listerErr) {
 		switch listerErr.Err {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -47,6 +48,11 @@ func TestNormalizeChannelURL(t *testing.T) {
 			input: "https://www.youtube.com/@testchannel/",
 			want:  "https://www.youtube.com/@testchannel/videos",
 		},
+		{
+			name:  "playlist URL",
+			input: "https://www.youtube.com/playlist?list=PLtest",
+			want:  "https://www.youtube.com/playlist?list=PLtest",
+		},
 	}
 
 	for _, tt := range tests {
@@ -298,3 +304,106 @@ const sampleYtdlpOutput = `{
     }
   ]
 }`
+
+func TestIncrementalArgs(t *testing.T) {
+	opts := &ListOptions{
+		PublishedAfter: time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+		KnownVideoIDs:  []string{"vid1", "vid2"},
+		MaxResults:     50,
+	}
+	got := strings.Join(incrementalArgs(opts), " ")
+	want := "--extractor-args youtubetab:approximate_date --dateafter 20240303 --break-on-reject " +
+		"--break-match-filters id!=vid1 & id!=vid2 --playlist-end 50"
+	if got != want {
+		t.Errorf("incrementalArgs() = %q, want %q", got, want)
+	}
+
+	// Content filters may drop entries, so the listing cannot end after
+	// MaxResults
+	opts = &ListOptions{MaxResults: 50, MinViews: 100}
+	if got := incrementalArgs(opts); len(got) != 0 {
+		t.Errorf("incrementalArgs(filtered) = %q, want none", got)
+	}
+}
+
+func TestParseYtdlpLines(t *testing.T) {
+	data := []byte(`{"id": "vid1", "title": "First", "upload_date": "20240301", "playlist_channel_id": "UCtest", "playlist_uploader": "Test"}
+{"id": "vid2", "title": "Second", "channel_id": "UCother", "uploader": "Other"}
+`)
+	videos, err := parseYtdlpLines(data, ContentTypeStreams)
+	if err != nil {
+		t.Fatalf("parseYtdlpLines() error = %v", err)
+	}
+	if len(videos) != 2 {
+		t.Fatalf("parseYtdlpLines() len = %d, want 2", len(videos))
+	}
+	if v := videos[0]; v.ChannelID != "UCtest" || v.ChannelName != "Test" || v.Type != VideoTypeStream || v.Published.IsZero() {
+		t.Errorf("videos[0] = %+v, want the playlist's channel and a date", v)
+	}
+	if v := videos[1]; v.ChannelID != "UCother" || v.ChannelName != "Other" {
+		t.Errorf("videos[1] = %+v, want the entry's own channel", v)
+	}
+	if _, err := parseYtdlpLines([]byte("{bad"), ContentTypeVideos); err == nil {
+		t.Error("parseYtdlpLines(invalid) succeeded")
+	}
+}
+
+func TestYtdlpLister_ListBoth(t *testing.T) {
+	dir := t.TempDir()
+	mockPath := filepath.Join(dir, "yt-dlp")
+
+	// The Videos tab has a premiere that also shows up on the Live tab
+	script := `#!/bin/sh
+if [ "$1" = "--version" ]; then
+    echo "2024.01.01"
+    exit 0
+fi
+for url; do :; done
+case "$url" in
+*@empty*)
+    echo "ERROR: [youtube:tab] UCempty: This channel does not have a streams tab" >&2
+    exit 1
+    ;;
+*/videos)
+    echo '{"channel_id": "UCtest", "entries": [
+      {"id": "vid1", "title": "Upload", "timestamp": 1736505600},
+      {"id": "prem1", "title": "Premiere", "timestamp": 1736000000, "live_status": "was_live"}]}'
+    ;;
+*/streams)
+    echo '{"channel_id": "UCtest", "entries": [
+      {"id": "next1", "title": "Next stream", "live_status": "is_upcoming", "release_timestamp": 1900000000},
+      {"id": "live1", "title": "Stream", "timestamp": 1736419200},
+      {"id": "prem1", "title": "Premiere", "timestamp": 1736000000, "live_status": "was_live"}]}'
+    ;;
+esac
+`
+	if err := os.WriteFile(mockPath, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to create mock yt-dlp: %v", err)
+	}
+	lister := &YtdlpLister{Path: mockPath, Timeout: 30 * time.Second}
+	ctx := context.Background()
+
+	videos, err := lister.ListVideos(ctx, "https://www.youtube.com/@test", &ListOptions{ContentType: ContentTypeBoth})
+	if err != nil {
+		t.Fatalf("ListVideos(both) error = %v", err)
+	}
+	var got []string
+	for _, v := range videos {
+		got = append(got, v.ID+"/"+v.Type+"/"+string(v.LiveStatus))
+	}
+	want := []string{"next1/stream/upcoming", "vid1/video/", "live1/stream/ended", "prem1/stream/ended"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("ListVideos(both) = %q, want %q", got, want)
+	}
+
+	videos, err = lister.ListVideos(ctx, "https://www.youtube.com/@test", &ListOptions{ContentType: ContentTypeBoth, MaxResults: 2})
+	if err != nil || len(videos) != 2 || videos[0].ID != "next1" || videos[1].ID != "vid1" {
+		t.Errorf("ListVideos(both, max 2) = %v, %v; want the 2 newest", videos, err)
+	}
+
+	// A channel without a Live tab has no streams
+	videos, err = lister.ListVideos(ctx, "https://www.youtube.com/@empty", &ListOptions{ContentType: ContentTypeStreams})
+	if err != nil || len(videos) != 0 {
+		t.Errorf("ListVideos(no Live tab) = %v, %v; want no videos", videos, err)
+	}
+}
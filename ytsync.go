@@ -17,10 +17,25 @@ package ytsync
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"maps"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+	"ytsync/cache"
 	"ytsync/config"
+	ythttp "ytsync/http"
+	"ytsync/lease"
+	"ytsync/retry"
 	"ytsync/storage"
 	"ytsync/youtube"
+	"ytsync/youtube/innertube"
+
+	"github.com/google/uuid"
 )
 
 // ListVideos retrieves videos from a YouTube channel using default configuration.
@@ -46,6 +61,35 @@ type ListOptions struct {
 	UseYouTubeAPI bool
 	// ContentType specifies what to list: videos, streams, or both (default: videos)
 	ContentType youtube.ContentType
+	// PublishedAfter and PublishedBefore restrict results to a date range
+	// (zero = unbounded). Listers that paginate stop once they pass the range.
+	PublishedAfter  time.Time
+	PublishedBefore time.Time
+	// MinDuration and MaxDuration exclude videos outside this length range
+	// (0 = no bound). Videos with unknown duration are kept.
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	// TitleMatch keeps only videos whose title matches, if set
+	TitleMatch *regexp.Regexp
+	// MinViews excludes videos with fewer views (0 = no minimum)
+	MinViews int64
+	// ExcludeLive excludes live streams and stream recordings
+	ExcludeLive bool
+	// ExcludeMembersOnly excludes videos restricted to channel members
+	ExcludeMembersOnly bool
+	// ExcludeUpcoming excludes scheduled streams and premieres that have not
+	// aired yet
+	ExcludeUpcoming bool
+	// Rules skips the videos an include/exclude rule set denies, if set.
+	// Load one with youtube.LoadRules.
+	Rules *youtube.RuleSet
+	// QuotaStorePath, if set, persists Data API quota usage to the JSON store
+	// at this path, so the daily budget is tracked across runs. Resolved
+	// handles are cached there too.
+	QuotaStorePath string
+	// Config overrides the configuration loaded from ytsync.json and the
+	// environment. Build one with config.New.
+	Config *config.Config
 }
 
 // ListVideosWithOptions retrieves videos with custom options.
@@ -55,20 +99,30 @@ func ListVideosWithOptions(ctx context.Context, channelURL string, opts *ListOpt
 	}
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := loadConfig(opts.Config)
 	if err != nil {
-		return nil, fmt.Errorf("load config: %w", err)
+		return nil, err
 	}
 
 	// Create lister
 	var lister youtube.VideoLister
 	if opts.UseYouTubeAPI && cfg.YouTubeAPIEnabled {
-		if cfg.YouTubeAPIKey == "" {
-			return nil, fmt.Errorf("YouTube API requested but no API key configured")
+		var store *storage.JSONStore
+		if opts.QuotaStorePath != "" {
+			store, err = openStore(opts.QuotaStorePath, cfg)
+			if err != nil {
+				return nil, fmt.Errorf("initialize quota store: %w", err)
+			}
+			defer store.Close()
 		}
-		apiLister, err := youtube.NewAPILister(cfg.YouTubeAPIKey, cfg.YouTubeAPIQuotaReserve)
+		apiLister, err := newAPILister(cfg, store)
 		if err != nil {
-			return nil, fmt.Errorf("create api lister: %w", err)
+			return nil, err
+		}
+		if store != nil {
+			apiLister.Aliases = store
+		} else if c := sharedCache(cfg); c != nil {
+			apiLister.Aliases = c.AliasStore(cache.DefaultAliasTTL)
 		}
 		// Set up fallback to yt-dlp when quota exhausted
 		ytdlp := youtube.NewYtdlpLister()
@@ -77,7 +131,12 @@ func ListVideosWithOptions(ctx context.Context, channelURL string, opts *ListOpt
 		apiLister.SetFallbackLister(ytdlp)
 		lister = apiLister
 	} else if opts.UseRSS {
-		lister = youtube.NewRSSLister()
+		rssLister := youtube.NewRSSLister()
+		rssLister.Client = NewHTTPClient(cfg)
+		if c := sharedCache(cfg); c != nil {
+			rssLister.Aliases = c.AliasStore(cache.DefaultAliasTTL)
+		}
+		lister = rssLister
 	} else {
 		ytdlp := youtube.NewYtdlpLister()
 		ytdlp.Path = cfg.YtdlpPath
@@ -87,8 +146,18 @@ func ListVideosWithOptions(ctx context.Context, channelURL string, opts *ListOpt
 
 	// Build list options
 	listOpts := &youtube.ListOptions{
-		MaxResults:  opts.MaxResults,
-		ContentType: opts.ContentType,
+		MaxResults:         opts.MaxResults,
+		PublishedAfter:     opts.PublishedAfter,
+		PublishedBefore:    opts.PublishedBefore,
+		ContentType:        opts.ContentType,
+		MinDuration:        opts.MinDuration,
+		MaxDuration:        opts.MaxDuration,
+		TitleMatch:         opts.TitleMatch,
+		MinViews:           opts.MinViews,
+		ExcludeLive:        opts.ExcludeLive,
+		ExcludeMembersOnly: opts.ExcludeMembersOnly,
+		ExcludeUpcoming:    opts.ExcludeUpcoming,
+		Rules:              opts.Rules,
 	}
 
 	// List videos
@@ -100,17 +169,62 @@ func ListVideosWithOptions(ctx context.Context, channelURL string, opts *ListOpt
 	return videos, nil
 }
 
+// newAPILister creates a Data API lister with the keys in cfg. If store is
+// not nil, quota usage is tracked in it so the daily budget holds across
+// runs.
+func newAPILister(cfg *config.Config, store *storage.JSONStore) (*youtube.APILister, error) {
+	keys := cfg.APIKeys()
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("YouTube API requested but no API key configured")
+	}
+	var apiLister *youtube.APILister
+	var err error
+	if len(keys) > 1 {
+		apiLister, err = youtube.NewAPIListerWithKeys(keys, cfg.YouTubeAPIQuotaReserve)
+	} else {
+		apiLister, err = youtube.NewAPILister(keys[0], cfg.YouTubeAPIQuotaReserve)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create api lister: %w", err)
+	}
+	if store != nil {
+		if pool := apiLister.Keys(); pool != nil {
+			pool.SetQuotaStore(store)
+		} else {
+			apiLister.SetQuotaTracker(youtube.NewQuotaTracker(store, youtube.QuotaKey(keys[0]),
+				youtube.DefaultDailyQuota, cfg.YouTubeAPIQuotaReserve))
+		}
+	}
+	return apiLister, nil
+}
+
 // TranscriptOptions configures transcript extraction.
 type TranscriptOptions struct {
 	// Languages specifies preferred language codes (e.g., ["en", "es"]).
-	// Empty means try all available languages.
+	// Empty uses the configured transcript_languages preference
+	// (YTSYNC_TRANSCRIPT_LANGUAGES), which defaults to English first.
 	Languages []string
-	// SkipAutoGenerated skips auto-generated captions if true.
+	// SkipAutoGenerated skips auto-generated captions if true. The
+	// transcript_skip_auto_generated setting also enables this.
 	SkipAutoGenerated bool
+	// StripSponsorSegments removes entries that fall inside SponsorBlock
+	// sponsor, intro, and outro segments. Useful for cleaning text used for
+	// training or retrieval.
+	StripSponsorSegments bool
+	// Normalize merges the repeated, overlapping windows of auto-generated
+	// captions and strips noise tags like [Music]. See youtube.Normalize.
+	Normalize bool
+	// Config overrides the configuration loaded from ytsync.json and the
+	// environment. Build one with config.New.
+	Config *config.Config
 }
 
 // ExtractTranscript retrieves and parses the transcript for a video.
 // It returns the first available transcript matching the options.
+//
+// The videoID may be a bare ID or any URL youtube.ParseVideoID accepts.
+// Other input fails with an error matching youtube.ErrInvalidVideoID
+// before yt-dlp is run.
 func ExtractTranscript(ctx context.Context, videoID string) (*youtube.Transcript, error) {
 	return ExtractTranscriptWithOptions(ctx, videoID, &TranscriptOptions{})
 }
@@ -120,11 +234,15 @@ func ExtractTranscriptWithOptions(ctx context.Context, videoID string, opts *Tra
 	if opts == nil {
 		opts = &TranscriptOptions{}
 	}
+	videoID, err := youtube.ParseVideoID(videoID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := loadConfig(opts.Config)
 	if err != nil {
-		return nil, fmt.Errorf("load config: %w", err)
+		return nil, err
 	}
 
 	// Create extractor
@@ -136,7 +254,12 @@ func ExtractTranscriptWithOptions(ctx context.Context, videoID string, opts *Tra
 	extractOpts := &youtube.ExtractOptions{
 		Languages:         opts.Languages,
 		Format:            "json3",
-		SkipAutoGenerated: opts.SkipAutoGenerated,
+		SkipAutoGenerated: opts.SkipAutoGenerated || cfg.TranscriptSkipAutoGenerated,
+		Normalize:         opts.Normalize,
+	}
+	if len(opts.Languages) == 0 {
+		pref := youtube.PreferenceForLanguages(cfg.TranscriptLanguages)
+		extractOpts.Preference = &pref
 	}
 
 	// Extract transcript
@@ -145,20 +268,42 @@ func ExtractTranscriptWithOptions(ctx context.Context, videoID string, opts *Tra
 		return nil, fmt.Errorf("extract transcript: %w", err)
 	}
 
+	if opts.StripSponsorSegments {
+		segments, err := youtube.NewSponsorBlockClient(NewHTTPClient(cfg)).FetchSegments(ctx, videoID)
+		if err != nil {
+			return nil, fmt.Errorf("fetch sponsor segments: %w", err)
+		}
+		transcript = youtube.StripSegments(transcript, segments)
+	}
+
 	return transcript, nil
 }
 
 // FetchVideoMetadata retrieves comprehensive metadata for a video using yt-dlp.
 // This includes title, description, duration, view count, and other details.
+// Transient yt-dlp failures are retried with the configured retry policy,
+// and fetches share one rate limit. The videoID may be a bare ID or any URL
+// youtube.ParseVideoID accepts.
 func FetchVideoMetadata(ctx context.Context, videoID string) (*youtube.VideoMetadata, error) {
+	return FetchVideoMetadataWithConfig(ctx, videoID, nil)
+}
+
+// FetchVideoMetadataWithConfig is like FetchVideoMetadata but uses cfg
+// instead of loading configuration from file and environment. A nil cfg
+// falls back to config.Load.
+func FetchVideoMetadataWithConfig(ctx context.Context, videoID string, cfg *config.Config) (*youtube.VideoMetadata, error) {
+	videoID, err := youtube.ParseVideoID(videoID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err = loadConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("load config: %w", err)
+		return nil, err
 	}
 
-	// Fetch metadata
-	metadata, err := youtube.FetchMetadata(ctx, videoID, cfg.YtdlpPath)
+	metadata, err := metadataSource(cfg)(ctx, videoID)
 	if err != nil {
 		return nil, fmt.Errorf("fetch metadata: %w", err)
 	}
@@ -166,6 +311,320 @@ func FetchVideoMetadata(ctx context.Context, videoID string) (*youtube.VideoMeta
 	return metadata, nil
 }
 
+// metadataSource returns the fetcher for video metadata under cfg: the
+// shared metadata cache when caching is enabled, else metadataFetcher.
+func metadataSource(cfg *config.Config) youtube.MetadataFetcher {
+	if cfg.MetadataCacheTTL > 0 {
+		return sharedMetadataCache(cfg).Get
+	}
+	return metadataFetcher(cfg)
+}
+
+// metadataLimiter paces the yt-dlp metadata fetches of this package, so a
+// batch of fetches does not trip YouTube's rate limits.
+var metadataLimiter = ythttp.NewRateLimiter(ythttp.DefaultRateLimiterConfig())
+
+// metadataFetcher returns a fetcher that runs yt-dlp with cfg's retry
+// policy, waiting on metadataLimiter before each attempt.
+func metadataFetcher(cfg *config.Config) youtube.MetadataFetcher {
+	policy := retryConfig(cfg)
+	return func(ctx context.Context, videoID string) (*youtube.VideoMetadata, error) {
+		var metadata *youtube.VideoMetadata
+		err := retry.Do(ctx, policy, isRetryableMetadataError, func(ctx context.Context) error {
+			if err := metadataLimiter.Wait(ctx, "https://www.youtube.com/watch?v="+videoID); err != nil {
+				return err
+			}
+			var err error
+			metadata, err = youtube.FetchMetadata(ctx, videoID, cfg.YtdlpPath)
+			return err
+		})
+		return metadata, err
+	}
+}
+
+// isRetryableMetadataError reports whether a metadata fetch failed in a way
+// running yt-dlp again may fix, such as a rate limit or network error.
+func isRetryableMetadataError(err error) bool {
+	var subErr *youtube.SubprocessError
+	return errors.As(err, &subErr) && subErr.Retryable()
+}
+
+// retryConfig returns the retry policy configured in cfg.
+func retryConfig(cfg *config.Config) retry.Config {
+	policy := retry.DefaultConfig()
+	policy.MaxRetries = cfg.MaxRetries
+	policy.InitialBackoff = cfg.InitialBackoff
+	policy.MaxBackoff = cfg.MaxBackoff
+	policy.Multiplier = cfg.BackoffMultiplier
+	return policy
+}
+
+// NewHTTPClient returns an HTTP client with the user agent, timeout, rate
+// limits, circuit breaker, and retry policy configured in cfg, which the
+// functions of this package use for their YouTube requests. Settings cfg
+// leaves at zero keep the ythttp.DefaultConfig values. A nil cfg gives a
+// client with the defaults.
+func NewHTTPClient(cfg *config.Config) *ythttp.Client {
+	return ythttp.New(httpConfig(cfg))
+}
+
+// httpConfig applies the HTTP settings of cfg to ythttp.DefaultConfig.
+func httpConfig(cfg *config.Config) *ythttp.Config {
+	hc := ythttp.DefaultConfig()
+	if cfg == nil {
+		return hc
+	}
+	hc.Retry = retryConfig(cfg)
+	if cfg.HTTPUserAgent != "" {
+		hc.UserAgent = cfg.HTTPUserAgent
+	}
+	if cfg.HTTPTimeout > 0 {
+		hc.Timeout = cfg.HTTPTimeout
+	}
+	if cfg.HTTPMaxConcurrent > 0 {
+		hc.MaxConcurrent = cfg.HTTPMaxConcurrent
+	}
+	if cfg.HTTPInnertubeRPS > 0 {
+		hc.RateLimiter.InnertubeRPS = cfg.HTTPInnertubeRPS
+	}
+	if cfg.HTTPDataAPIRPS > 0 {
+		hc.RateLimiter.DataAPIRPS = cfg.HTTPDataAPIRPS
+	}
+	if cfg.HTTPRSSRPS > 0 {
+		hc.RateLimiter.RSSRPS = cfg.HTTPRSSRPS
+	}
+	if cfg.HTTPGlobalRPS > 0 {
+		hc.RateLimiter.GlobalRPS = cfg.HTTPGlobalRPS
+	}
+	if cfg.HTTPBurst > 0 {
+		hc.RateLimiter.Burst = cfg.HTTPBurst
+	}
+	if cfg.HTTPCircuitFailureThreshold > 0 {
+		hc.CircuitBreaker.FailureThreshold = cfg.HTTPCircuitFailureThreshold
+	}
+	if cfg.HTTPCircuitRecoveryTimeout > 0 {
+		hc.CircuitBreaker.RecoveryTimeout = cfg.HTTPCircuitRecoveryTimeout
+	}
+	return hc
+}
+
+// loadConfig validates and returns cfg, or loads the configuration from
+// ytsync.json and the environment when cfg is nil.
+func loadConfig(cfg *config.Config) (*config.Config, error) {
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		return cfg, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// storeEncryptor returns the encryptor for cfg's store encryption key, or
+// nil if stores are not encrypted.
+func storeEncryptor(cfg *config.Config) (storage.Encryptor, error) {
+	if cfg.StoreEncryptionKey == "" {
+		return nil, nil
+	}
+	enc, err := storage.ParseAESGCMKeys(cfg.StoreEncryptionKey, cfg.StorePreviousKeys...)
+	if err != nil {
+		return nil, fmt.Errorf("store encryption key: %w", err)
+	}
+	return enc, nil
+}
+
+// storeIDs returns how new channels and videos get their IDs under cfg.
+func storeIDs(cfg *config.Config) (storage.IDGenerator, error) {
+	if !cfg.StoreDeterministicIDs {
+		return storage.RandomIDs, nil
+	}
+	namespace := uuid.Nil
+	if cfg.StoreIDNamespace != "" {
+		var err error
+		if namespace, err = uuid.Parse(cfg.StoreIDNamespace); err != nil {
+			return nil, fmt.Errorf("store ID namespace: %w", err)
+		}
+	}
+	return storage.DeterministicIDs(namespace), nil
+}
+
+// openStore opens the JSON store at path, encrypted at rest if cfg sets a
+// store encryption key, with deterministic IDs if cfg asks for them.
+func openStore(path string, cfg *config.Config) (*storage.JSONStore, error) {
+	enc, err := storeEncryptor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := storeIDs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	store, err := storage.NewJSONStoreWithOptions(path, &storage.JSONStoreOptions{
+		Encryptor: enc,
+		Lock:      &storage.LockPolicy{Timeout: cfg.StoreLockTimeout, StaleAfter: cfg.StoreLockStaleAfter},
+		IDs:       ids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("initialize store: %w", err)
+	}
+	return store, nil
+}
+
+// openBlobStore opens the gzip blob store in dir, encrypting new blobs if
+// cfg sets a store encryption key.
+func openBlobStore(dir string, cfg *config.Config) (*storage.BlobStore, error) {
+	enc, err := storeEncryptor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := storage.NewBlobStore(dir, storage.CompressionGzip)
+	if err != nil {
+		return nil, fmt.Errorf("initialize blob store: %w", err)
+	}
+	if enc != nil {
+		blobs.SetEncryptor(enc)
+	}
+	return blobs, nil
+}
+
+// RotateStoreKey re-encrypts the JSON store at storePath, and the blob
+// store in blobDir if not empty, with newKey (hex or base64). The store is
+// opened with the key from cfg (nil loads ytsync.json and the
+// environment). An empty newKey decrypts the store. Afterwards set
+// store_encryption_key to newKey.
+func RotateStoreKey(ctx context.Context, storePath, blobDir, newKey string, cfg *config.Config) error {
+	cfg, err := loadConfig(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(storePath, cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if blobDir != "" {
+		blobs, err := openBlobStore(blobDir, cfg)
+		if err != nil {
+			return err
+		}
+		store.SetBlobStore(blobs)
+	}
+
+	var enc storage.Encryptor
+	if newKey != "" {
+		// Keep the old keys so an interrupted rotation can be resumed
+		previous := cfg.StorePreviousKeys
+		if cfg.StoreEncryptionKey != "" {
+			previous = append([]string{cfg.StoreEncryptionKey}, previous...)
+		}
+		aesgcm, err := storage.ParseAESGCMKeys(newKey, previous...)
+		if err != nil {
+			return fmt.Errorf("new store key: %w", err)
+		}
+		enc = aesgcm
+	}
+	return store.RotateKey(ctx, enc)
+}
+
+// metadataCacheKey is the settings of cfg a metadata cache is built from.
+// Configs that agree on them share a cache.
+type metadataCacheKey struct {
+	ytdlpPath string
+	ttl       time.Duration
+	staleTTL  time.Duration
+	cachePath string
+	retry     retry.Config
+}
+
+var (
+	metadataCacheMu sync.Mutex
+	metadataCaches  = make(map[metadataCacheKey]*youtube.MetadataCache)
+	// metadataMemory holds the entries of caches without a cache file, so
+	// caches built from different Configs still share what they fetched.
+	metadataMemory = youtube.NewMemoryMetadataStore()
+)
+
+// sharedMetadataCache returns the metadata cache for the settings of cfg,
+// creating it on first use.
+func sharedMetadataCache(cfg *config.Config) *youtube.MetadataCache {
+	key := metadataCacheKey{
+		ytdlpPath: cfg.YtdlpPath,
+		ttl:       cfg.MetadataCacheTTL,
+		staleTTL:  cfg.MetadataCacheStaleTTL,
+		cachePath: cfg.CachePath,
+		retry:     retryConfig(cfg),
+	}
+	metadataCacheMu.Lock()
+	defer metadataCacheMu.Unlock()
+
+	if mc, ok := metadataCaches[key]; ok {
+		return mc
+	}
+	mc := youtube.NewMetadataCache(cfg.YtdlpPath)
+	mc.TTL = cfg.MetadataCacheTTL
+	mc.StaleTTL = cfg.MetadataCacheStaleTTL
+	mc.Fetch = metadataFetcher(cfg)
+	mc.Store = metadataMemory
+	if c := sharedCache(cfg); c != nil {
+		mc.Store = c.MetadataStore(cfg.MetadataCacheTTL + cfg.MetadataCacheStaleTTL)
+	} else if cfg.CachePath != "" {
+		// The cache file failed to open; try it again next time
+		return mc
+	}
+	metadataCaches[key] = mc
+	return mc
+}
+
+var (
+	fileCacheMu sync.Mutex
+	fileCaches  = make(map[string]*cache.Cache)
+)
+
+// sharedCache returns the file cache at cfg.CachePath, opening it on first
+// use, or nil if none is configured or it cannot be opened. The cache only
+// saves work, so callers carry on without it; a failed open is logged and
+// tried again on the next call.
+func sharedCache(cfg *config.Config) *cache.Cache {
+	if cfg.CachePath == "" {
+		return nil
+	}
+	fileCacheMu.Lock()
+	defer fileCacheMu.Unlock()
+
+	if c, ok := fileCaches[cfg.CachePath]; ok {
+		return c
+	}
+	c, err := cache.Open(cfg.CachePath)
+	if err != nil {
+		log.Printf("ytsync: open cache %s: %v; continuing without it", cfg.CachePath, err)
+		return nil
+	}
+	fileCaches[cfg.CachePath] = c
+	return c
+}
+
+// InvalidateVideoMetadata removes a video from the metadata caches so the next
+// FetchVideoMetadata call fetches fresh data. It is a no-op when caching is disabled.
+func InvalidateVideoMetadata(videoID string) error {
+	metadataCacheMu.Lock()
+	caches := slices.Collect(maps.Values(metadataCaches))
+	metadataCacheMu.Unlock()
+
+	if err := metadataMemory.Delete(videoID); err != nil {
+		return err
+	}
+	for _, mc := range caches {
+		if err := mc.Invalidate(videoID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SyncOptions configures video synchronization behavior.
 type SyncOptions struct {
 	// MaxResults limits the number of videos to retrieve (0 = all available)
@@ -175,14 +634,87 @@ type SyncOptions struct {
 	// StorePath is the path to the JSON store for persisting sync state
 	// Required for incremental sync functionality
 	StorePath string
+	// Rules decides which videos the sync keeps. If nil, the store's rules
+	// file is used when it has one: ytsync.rules.json or ytsync.rules.yaml
+	// next to ytsync.json (see youtube.StoreRulesPath).
+	Rules *youtube.RuleSet
+	// BlobDir keeps transcript text in gzip-compressed, content-addressed
+	// files under this directory instead of in the store file, which then
+	// holds only references. Existing inline transcripts are read as before.
+	BlobDir string
+	// SaveReport appends the run's SyncReport to the store's sync history.
+	SaveReport bool
+	// Enrich fetches metadata and transcripts for newly discovered videos in
+	// parallel and saves them to the store. A failure in one does not
+	// prevent the other from being saved.
+	Enrich bool
+	// EnrichConcurrency is the number of videos enriched in parallel.
+	// Defaults to youtube.DefaultEnrichConcurrency.
+	EnrichConcurrency int
+	// RecordStats adds the view, like, and comment counts fetched during
+	// enrichment to each video's stats history. Requires Enrich.
+	RecordStats bool
+	// SkipSegments fetches the SponsorBlock sponsor, intro, and outro
+	// segments of each new video during enrichment and stores them with
+	// the video. Requires Enrich.
+	SkipSegments bool
+	// ChannelArt downloads the channel's avatar and banner after the sync
+	// when the channel is tracked in the store and they are missing, their
+	// URLs changed, or they are older than a week. They are kept in the
+	// blob store when BlobDir is set. A failure is reported in
+	// SyncResult.ChannelArtErr and does not fail the sync.
+	ChannelArt bool
+	// CountVideos reads the channel's video count from the Innertube API
+	// before falling back to a full sync, and skips the full sync when the
+	// count grew by exactly the new videos in the RSS feed. Only exact
+	// counts are used, so it has no effect on channels whose header shows
+	// an abbreviated count such as "1.2K videos".
+	CountVideos bool
+	// Locker, if set, leases the channel before syncing it, so that
+	// instances on several hosts can share a channel list without syncing a
+	// channel twice. A sync of a channel leased by another instance fails
+	// with an error matching lease.ErrHeld.
+	Locker *lease.Locker
+	// OnProgress, if set, receives a snapshot of the sync every
+	// ProgressInterval while it runs, for watching long full syncs.
+	OnProgress func(youtube.SyncProgress)
+	// ProgressInterval is how often OnProgress is called. Defaults to
+	// DefaultProgressInterval.
+	ProgressInterval time.Duration
+	// OnEvent, if set, is called with the steps of the sync as they happen:
+	// phase changes, listed pages, fetched transcripts, stored videos,
+	// retried requests, and errors. Calls are made in order from one
+	// goroutine, and all of them before SyncChannelVideos returns. Events
+	// are buffered so a slow OnEvent does not hold up the sync; once it
+	// falls 256 events behind, further events are dropped until it catches
+	// up. Counts taken from events can therefore fall short; OnProgress and
+	// the result give the totals.
+	OnEvent func(youtube.SyncEvent)
+	// HTTPClient, if set, makes the sync's requests in place of a client
+	// built by NewHTTPClient, so that several syncs share its rate limiter
+	// and circuit breaker and callers can watch their state.
+	HTTPClient *ythttp.Client
+	// Config overrides the configuration loaded from ytsync.json and the
+	// environment. Build one with config.New.
+	Config *config.Config
 }
 
+// DefaultProgressInterval is how often SyncOptions.OnProgress is called
+// when ProgressInterval is zero.
+const DefaultProgressInterval = 30 * time.Second
+
+// channelArtMaxAge is how old channel art synced by SyncChannelVideos may
+// get before it is downloaded again to check for a changed image.
+const channelArtMaxAge = 7 * 24 * time.Hour
+
 // SyncChannelVideos performs an efficient incremental sync of channel videos.
 // It uses the sync manager to coordinate between RSS (fast, incremental) and
 // full sync strategies. Sync state is persisted to enable gap detection and
 // resumable pagination.
 //
 // Returns a SyncResult containing the videos discovered and metadata about the sync.
+// A sync that fails once it has started returns a SyncResult holding only
+// the Report of the failed run, along with the error.
 func SyncChannelVideos(ctx context.Context, channelURL string, opts *SyncOptions) (*SyncResult, error) {
 	if opts == nil {
 		opts = &SyncOptions{}
@@ -192,17 +724,24 @@ func SyncChannelVideos(ctx context.Context, channelURL string, opts *SyncOptions
 		return nil, fmt.Errorf("StorePath is required for sync operations")
 	}
 
-	// Initialize storage
-	store, err := storage.NewJSONStore(opts.StorePath)
+	// Load configuration
+	cfg, err := loadConfig(opts.Config)
 	if err != nil {
-		return nil, fmt.Errorf("initialize store: %w", err)
+		return nil, err
 	}
-	defer store.Close()
 
-	// Load configuration
-	cfg, err := config.Load()
+	// Initialize storage
+	store, err := openStore(opts.StorePath, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("load config: %w", err)
+		return nil, err
+	}
+	defer store.Close()
+	if opts.BlobDir != "" {
+		blobs, err := openBlobStore(opts.BlobDir, cfg)
+		if err != nil {
+			return nil, err
+		}
+		store.SetBlobStore(blobs)
 	}
 
 	// Create fallback lister (for full syncs when RSS has gaps)
@@ -211,29 +750,370 @@ func SyncChannelVideos(ctx context.Context, channelURL string, opts *SyncOptions
 	fallback.Timeout = cfg.YtdlpTimeout
 
 	// Create sync manager
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = NewHTTPClient(cfg)
+	}
 	rssLister := youtube.NewRSSLister()
+	rssLister.Client = httpClient
+	rssLister.Aliases = store
 	syncMgr := youtube.NewSyncManagerWithListers(rssLister, fallback, store)
+	if opts.SaveReport {
+		syncMgr.SetReportStore(store)
+	}
+	if opts.CountVideos {
+		syncMgr.SetVideoCounter(innertube.NewLister(httpClient))
+	}
+	if opts.Locker != nil {
+		syncMgr.SetLocker(opts.Locker)
+	}
+	if opts.Enrich {
+		enrich := enrichOptions(cfg, store, opts.EnrichConcurrency)
+		enrich.RecordStats = opts.RecordStats
+		if opts.SkipSegments {
+			enrich.Segments = youtube.NewSponsorBlockClient(httpClient).FetchSegments
+		}
+		syncMgr.SetEnrichment(enrich)
+		syncMgr.SetRateLimiter(enrich.RateLimiter)
+	}
+	if opts.OnProgress != nil {
+		stop := reportProgress(syncMgr, opts.OnProgress, opts.ProgressInterval)
+		defer stop()
+	}
+	if opts.OnEvent != nil {
+		stop := forwardEvents(syncMgr, opts.OnEvent)
+		defer stop()
+	}
+
+	rules := opts.Rules
+	if rules == nil {
+		if rules, err = youtube.LoadStoreRules(opts.StorePath); err != nil {
+			return nil, err
+		}
+	}
 
 	// Build list options
 	listOpts := &youtube.ListOptions{
 		MaxResults:  opts.MaxResults,
 		ContentType: opts.ContentType,
+		Rules:       rules,
 	}
 
 	// Perform sync
 	result, err := syncMgr.SyncChannelVideos(ctx, channelURL, listOpts)
 	if err != nil {
+		if result != nil {
+			return &SyncResult{Report: result.Report}, fmt.Errorf("sync channel videos: %w", err)
+		}
 		return nil, fmt.Errorf("sync channel videos: %w", err)
 	}
 
 	// Convert to public result type
-	return &SyncResult{
-		Videos:         result.Videos,
-		NewVideosCount: result.NewVideosCount,
-		IsIncremental:  result.IsIncremental,
-		IsFullSync:     result.IsFullSync,
-		GapDetected:    result.GapDetected,
-	}, nil
+	synced := &SyncResult{
+		Videos:            result.Videos,
+		NewVideosCount:    result.NewVideosCount,
+		IsIncremental:     result.IsIncremental,
+		IsFullSync:        result.IsFullSync,
+		GapDetected:       result.GapDetected,
+		Report:            result.Report,
+		Enriched:          result.Enriched,
+		PolledTranscripts: result.PolledTranscripts,
+	}
+	if opts.ChannelArt {
+		synced.ChannelArt, synced.ChannelArtErr = syncChannelArt(ctx, store, httpClient, channelURL)
+	}
+	return synced, nil
+}
+
+// syncChannelArt refreshes the avatar and banner of the tracked channel at
+// channelURL. Channels that are not tracked are skipped.
+func syncChannelArt(ctx context.Context, store *storage.JSONStore, client *ythttp.Client, channelURL string) ([]storage.ChannelArtKind, error) {
+	resolver := youtube.NewChannelResolver()
+	resolver.Client = client
+	resolver.Aliases = store
+	info, err := resolver.FetchChannelInfo(ctx, channelURL)
+	if err != nil {
+		return nil, fmt.Errorf("sync channel art: %w", err)
+	}
+	ch, err := store.GetChannelByYouTubeID(ctx, info.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sync channel art: %w", err)
+	}
+	fetcher := youtube.NewChannelArtFetcher()
+	fetcher.MaxAge = channelArtMaxAge
+	changed, err := fetcher.Sync(ctx, store, ch, info)
+	if err != nil {
+		return changed, fmt.Errorf("sync channel art: %w", err)
+	}
+	return changed, nil
+}
+
+// reportProgress calls fn with a snapshot of sm every interval until the
+// returned function is called.
+func reportProgress(sm *youtube.SyncManager, fn func(youtube.SyncProgress), interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				fn(sm.Progress())
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// forwardEvents calls fn with each event of sm until the returned function
+// is called, which waits for the events sent before it to be handled.
+func forwardEvents(sm *youtube.SyncManager, fn func(youtube.SyncEvent)) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := sm.Subscribe(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range events {
+			fn(e)
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// ImportOptions configures ImportSubscriptions.
+type ImportOptions struct {
+	// StorePath is the path to the JSON store the channels are added to.
+	// Required.
+	StorePath string
+	// Policy, if set, is the sync policy given to every imported channel.
+	Policy *storage.SyncPolicy
+	// Config overrides the configuration loaded from ytsync.json and the
+	// environment. Build one with config.New.
+	Config *config.Config
+}
+
+// ImportSubscriptions adds the channels listed in a subscription export
+// (OPML, CSV, or Google Takeout subscriptions.json) to the store. Channel
+// IDs are taken from the file where present; handles and URLs are resolved
+// and remembered as aliases. Channels already in the store or repeated in
+// the file are skipped.
+//
+// Returns one result per entry. Entries that fail to resolve are reported
+// in their result rather than as an error.
+func ImportSubscriptions(ctx context.Context, r io.Reader, format youtube.SubscriptionFormat, opts *ImportOptions) ([]youtube.ImportResult, error) {
+	if opts == nil || opts.StorePath == "" {
+		return nil, fmt.Errorf("StorePath is required to import subscriptions")
+	}
+
+	cfg, err := loadConfig(opts.Config)
+	if err != nil {
+		return nil, err
+	}
+	store, err := openStore(opts.StorePath, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	resolver := youtube.NewChannelResolver()
+	resolver.Client = NewHTTPClient(cfg)
+	resolver.Aliases = store
+	importer := &youtube.SubscriptionImporter{Store: store, Resolver: resolver, Policy: opts.Policy}
+	return importer.Import(ctx, r, format)
+}
+
+// StatsOptions configures CaptureVideoStats.
+type StatsOptions struct {
+	// StorePath is the path to the JSON store holding the videos and their
+	// stats history. Required.
+	StorePath string
+	// ChannelIDs limits sampling to these YouTube channel IDs. Empty samples
+	// every stored channel.
+	ChannelIDs []string
+	// MaxAge, if non-zero, skips videos published longer ago.
+	MaxAge time.Duration
+	// Concurrency is the number of videos sampled in parallel.
+	// Defaults to youtube.DefaultEnrichConcurrency.
+	Concurrency int
+	// Config overrides the configuration loaded from ytsync.json and the
+	// environment. Build one with config.New.
+	Config *config.Config
+}
+
+// CaptureVideoStats records the current view, like, and comment counts of
+// the stored videos as a new sample in each video's stats history. Run it
+// periodically (or use youtube.TrackStats) to build growth curves.
+// Metadata is always fetched fresh, bypassing the metadata cache.
+func CaptureVideoStats(ctx context.Context, opts *StatsOptions) (*youtube.StatsResult, error) {
+	if opts == nil || opts.StorePath == "" {
+		return nil, fmt.Errorf("StorePath is required to capture video stats")
+	}
+
+	cfg, err := loadConfig(opts.Config)
+	if err != nil {
+		return nil, err
+	}
+	store, err := openStore(opts.StorePath, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	return youtube.CaptureStats(ctx, &youtube.StatsOptions{
+		Store: store,
+		Metadata: func(ctx context.Context, videoID string) (*youtube.VideoMetadata, error) {
+			return youtube.FetchMetadata(ctx, videoID, cfg.YtdlpPath)
+		},
+		ChannelIDs:  opts.ChannelIDs,
+		MaxAge:      opts.MaxAge,
+		Concurrency: opts.Concurrency,
+		RateLimiter: ythttp.NewRateLimiter(ythttp.DefaultRateLimiterConfig()),
+	})
+}
+
+// GetStatsHistory returns the stats samples of the video with YouTube ID
+// videoID taken in [since, until], oldest first. A zero since or until
+// leaves that end of the range open.
+func GetStatsHistory(ctx context.Context, storePath, videoID string, since, until time.Time) ([]*storage.VideoStats, error) {
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		return nil, err
+	}
+	store, err := openStore(storePath, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	video, err := store.GetVideoByYouTubeID(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetStatsHistory(ctx, video.ID, since, until)
+}
+
+// BackfillOptions configures BackfillPublished.
+type BackfillOptions struct {
+	// StorePath is the path to the JSON store holding the videos. Required.
+	StorePath string
+	// ChannelIDs limits the backfill to these YouTube channel IDs. Empty
+	// checks every stored channel.
+	ChannelIDs []string
+	// BatchSize is the number of videos looked up at a time.
+	// Defaults to youtube.DefaultBackfillBatchSize.
+	BatchSize int
+	// OnProgress, if set, is called after each batch.
+	OnProgress func(youtube.BackfillProgress)
+	// Config overrides the configuration loaded from ytsync.json and the
+	// environment. Build one with config.New.
+	Config *config.Config
+}
+
+// BackfillPublished looks up the publish dates of stored videos that have
+// none, such as those synced while yt-dlp listings lacked upload_date, and
+// saves them. Dates come from the Data API's videos.list when the API is
+// enabled, at one quota unit per 50 videos, and from player requests
+// otherwise or once the quota runs out. Quota usage is tracked in the
+// store.
+func BackfillPublished(ctx context.Context, opts *BackfillOptions) (*youtube.BackfillResult, error) {
+	if opts == nil || opts.StorePath == "" {
+		return nil, fmt.Errorf("StorePath is required to backfill publish dates")
+	}
+
+	cfg, err := loadConfig(opts.Config)
+	if err != nil {
+		return nil, err
+	}
+	store, err := openStore(opts.StorePath, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	player := innertube.NewClient(NewHTTPClient(cfg))
+	fetch := player.PublishDates
+	if cfg.YouTubeAPIEnabled && len(cfg.APIKeys()) > 0 {
+		api, err := newAPILister(cfg, store)
+		if err != nil {
+			return nil, err
+		}
+		fetch = func(ctx context.Context, videoIDs []string) (map[string]time.Time, error) {
+			dates, err := api.PublishDates(ctx, videoIDs)
+			if !errors.Is(err, youtube.ErrQuotaInsufficient) {
+				return dates, err
+			}
+			// Out of quota: look up the rest with player requests
+			var rest []string
+			for _, id := range videoIDs {
+				if _, ok := dates[id]; !ok {
+					rest = append(rest, id)
+				}
+			}
+			more, err := player.PublishDates(ctx, rest)
+			maps.Copy(dates, more)
+			return dates, err
+		}
+	}
+
+	return youtube.BackfillPublished(ctx, &youtube.BackfillOptions{
+		Store:      store,
+		Fetch:      fetch,
+		ChannelIDs: opts.ChannelIDs,
+		BatchSize:  opts.BatchSize,
+		OnProgress: opts.OnProgress,
+	})
+}
+
+// enrichOptions builds sync enrichment that fetches metadata and transcripts
+// with the settings from cfg and persists them to store. Both fetchers share
+// one rate limiter so parallel workers stay within YouTube's request budget.
+func enrichOptions(cfg *config.Config, store storage.Store, concurrency int) *youtube.EnrichOptions {
+	extractor := youtube.NewTranscriptExtractor()
+	extractor.YtdlpPath = cfg.YtdlpPath
+	extractor.Timeout = cfg.YtdlpTimeout
+	pref := youtube.PreferenceForLanguages(cfg.TranscriptLanguages)
+	extractOpts := &youtube.ExtractOptions{
+		Format:            "json3",
+		SkipAutoGenerated: cfg.TranscriptSkipAutoGenerated,
+		Preference:        &pref,
+	}
+
+	metadata := func(ctx context.Context, videoID string) (*youtube.VideoMetadata, error) {
+		return youtube.FetchMetadata(ctx, videoID, cfg.YtdlpPath)
+	}
+	if cfg.MetadataCacheTTL > 0 {
+		metadata = sharedMetadataCache(cfg).Get
+	}
+
+	return &youtube.EnrichOptions{
+		Metadata: metadata,
+		Transcripts: func(ctx context.Context, videoID string) (*youtube.Transcript, error) {
+			return extractor.Extract(ctx, videoID, extractOpts)
+		},
+		Store:       store,
+		Concurrency: concurrency,
+		RateLimiter: ythttp.NewRateLimiter(ythttp.DefaultRateLimiterConfig()),
+		TranscriptRetry: &storage.TranscriptRetryPolicy{
+			RetryAfter:   cfg.TranscriptRetryAfter,
+			MaxAttempts:  cfg.TranscriptMaxAttempts,
+			PollSchedule: cfg.TranscriptPollSchedule,
+			PollWindow:   cfg.TranscriptPollWindow,
+		},
+		TranscriptLanguages: cfg.TranscriptLanguages,
+	}
 }
 
 // SyncResult contains the outcome of a sync operation.
@@ -248,6 +1128,21 @@ type SyncResult struct {
 	IsFullSync bool
 	// GapDetected is true if RSS sync detected a gap in the feed.
 	GapDetected bool
+	// Report is the structured summary of the run, serializable to JSON.
+	Report *storage.SyncReport
+	// Enriched holds the metadata and transcript outcome for each new
+	// video when SyncOptions.Enrich is set.
+	Enriched []*youtube.EnrichResult
+	// PolledTranscripts holds the outcome for each earlier video whose
+	// transcript was asked for again because it is a fresh upload that had
+	// none (see config.Config.TranscriptPollSchedule).
+	PolledTranscripts []*youtube.EnrichResult
+	// ChannelArt lists the channel images replaced when
+	// SyncOptions.ChannelArt is set.
+	ChannelArt []storage.ChannelArtKind
+	// ChannelArtErr is the error that kept channel art from being synced,
+	// if any.
+	ChannelArtErr error
 }
 
 // DownloadOptions configures video download behavior.
@@ -263,6 +1158,10 @@ type DownloadOptions struct {
 	// AudioQuality specifies the audio quality in kbps when AudioOnly is true.
 	// Defaults to 192 if not specified.
 	AudioQuality int
+	// AudioLanguage picks the audio track of videos with dubbed versions:
+	// a language code like "en", or youtube.OriginalAudio for the original
+	// language. Videos without such a track get the default one.
+	AudioLanguage string
 	// IncludeMetadata saves video metadata to a JSON file alongside the video.
 	IncludeMetadata bool
 	// Filename specifies a custom output filename (without extension).
@@ -270,6 +1169,34 @@ type DownloadOptions struct {
 	// When provided, this takes precedence over title-based naming.
 	// Useful for ensuring unique filenames based on video IDs (e.g., "dQw4w9WgXcQ").
 	Filename string
+	// OutputTemplate is a Go text/template for the output path relative to
+	// OutputDir, e.g. "{{.ChannelName}}/{{.Published.Year}}/{{.ID}}-{{.TitleSlug}}.{{.Ext}}".
+	// If set, it overrides Filename.
+	OutputTemplate string
+	// Collision controls what happens when the templated path already exists:
+	// youtube.CollisionOverwrite (default), youtube.CollisionSkip, or youtube.CollisionSuffix.
+	Collision youtube.CollisionPolicy
+	// Resume continues an interrupted download from its partial file, even
+	// if the yt-dlp configuration says otherwise. yt-dlp resumes by default.
+	Resume bool
+	// Restart discards any partial file and starts the download over.
+	// Ignored if Resume is set.
+	Restart bool
+	// Verify checks the completed file against the expected size and duration.
+	// A file that fails verification returns an error matching
+	// ErrCorruptDownload together with the result, whose Verification lists
+	// the problems.
+	Verify bool
+	// Subtitles, if set, embeds or burns downloaded subtitles into the video
+	// with ffmpeg.
+	Subtitles *youtube.SubtitleOptions
+	// PostProcessors are run in order on the completed download, for
+	// example a youtube.ChecksumProcessor, a youtube.CommandProcessor, or a
+	// library.Mover. An error from one is returned with the result.
+	PostProcessors []youtube.PostProcessor
+	// Config overrides the configuration loaded from ytsync.json and the
+	// environment. Build one with config.New.
+	Config *config.Config
 }
 
 // DownloadResult contains information about a completed download.
@@ -278,8 +1205,15 @@ type DownloadResult struct {
 	VideoPath string
 	// MetadataPath is the path to the metadata JSON file (if IncludeMetadata was true).
 	MetadataPath string
-	// Metadata contains the parsed video metadata (if IncludeMetadata was true).
+	// Metadata contains the parsed video metadata (if IncludeMetadata or Verify was true).
 	Metadata *youtube.VideoMetadata
+	// Verification contains the integrity check result (if Verify was true).
+	Verification *youtube.VerifyResult
+	// Skipped is true if the output already existed and Collision was youtube.CollisionSkip.
+	Skipped bool
+	// Checksums maps algorithm names to the checksums computed by a
+	// youtube.ChecksumProcessor.
+	Checksums map[string]string
 }
 
 // DownloadVideo downloads a YouTube video using default configuration.
@@ -288,6 +1222,7 @@ type DownloadResult struct {
 // The videoID can be:
 // - A video ID: dQw4w9WgXcQ
 // - A full URL: https://www.youtube.com/watch?v=dQw4w9WgXcQ
+// - Any other URL youtube.ParseVideoID accepts, such as https://youtu.be/dQw4w9WgXcQ
 //
 // The video will be saved to the current directory with the video's title as filename.
 func DownloadVideo(ctx context.Context, videoID string) (*DownloadResult, error) {
@@ -299,16 +1234,22 @@ func DownloadVideoWithOptions(ctx context.Context, videoID string, opts *Downloa
 	if opts == nil {
 		opts = &DownloadOptions{}
 	}
+	videoID, err := youtube.ParseVideoID(videoID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := loadConfig(opts.Config)
 	if err != nil {
-		return nil, fmt.Errorf("load config: %w", err)
+		return nil, err
 	}
 
 	// Create downloader
 	downloader := youtube.NewDownloader()
 	downloader.YtdlpPath = cfg.YtdlpPath
+	downloader.PostProcessors = opts.PostProcessors
+	downloader.Metadata = metadataSource(cfg)
 
 	// Convert public options to internal options
 	downloadOpts := &youtube.DownloadOptions{
@@ -316,21 +1257,202 @@ func DownloadVideoWithOptions(ctx context.Context, videoID string, opts *Downloa
 		Format:          opts.Format,
 		AudioOnly:       opts.AudioOnly,
 		AudioQuality:    opts.AudioQuality,
+		AudioLanguage:   opts.AudioLanguage,
 		IncludeMetadata: opts.IncludeMetadata,
 		Filename:        opts.Filename,
 		YtdlpPath:       cfg.YtdlpPath,
+		Resume:          opts.Resume,
+		Restart:         opts.Restart,
+		Verify:          opts.Verify,
+		Collision:       opts.Collision,
+		Subtitles:       opts.Subtitles,
+	}
+	if opts.OutputTemplate != "" {
+		tmpl, err := youtube.ParseOutputTemplate(opts.OutputTemplate)
+		if err != nil {
+			return nil, err
+		}
+		downloadOpts.OutputTemplate = tmpl
 	}
 
 	// Download video
 	result, err := downloader.Download(ctx, videoID, downloadOpts)
-	if err != nil {
+	if err != nil && result == nil {
 		return nil, fmt.Errorf("download video: %w", err)
 	}
 
 	// Convert result to public type
-	return &DownloadResult{
+	public := &DownloadResult{
 		VideoPath:    result.VideoPath,
 		MetadataPath: result.MetadataPath,
 		Metadata:     result.Metadata,
-	}, nil
+		Verification: result.Verification,
+		Skipped:      result.Skipped,
+		Checksums:    result.Checksums,
+	}
+	if err != nil {
+		return public, fmt.Errorf("download video: %w", err)
+	}
+	return public, nil
+}
+
+// VerifyDownload checks a previously downloaded file against the video's
+// metadata and reports whether it appears truncated or corrupted.
+func VerifyDownload(ctx context.Context, path string, videoID string) (*youtube.VerifyResult, error) {
+	return VerifyDownloadWithConfig(ctx, path, videoID, nil)
+}
+
+// VerifyDownloadWithConfig is like VerifyDownload but uses cfg instead of
+// loading configuration. A nil cfg falls back to config.Load.
+func VerifyDownloadWithConfig(ctx context.Context, path string, videoID string, cfg *config.Config) (*youtube.VerifyResult, error) {
+	cfg, err := loadConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	downloader := youtube.NewDownloader()
+	downloader.YtdlpPath = cfg.YtdlpPath
+	downloader.Metadata = metadataSource(cfg)
+
+	result, err := downloader.Verify(ctx, path, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("verify download: %w", err)
+	}
+	return result, nil
+}
+
+// CheckAvailability probes whether a video is public, unlisted, private,
+// deleted, age-restricted, members-only, region-blocked, live, or upcoming.
+// It uses a single lightweight player request, which is much cheaper than
+// FetchVideoMetadata when reconciling large numbers of videos. Each call
+// loads the configuration and builds its own HTTP client; to check many
+// videos, use CheckAvailabilityWithOptions with a shared HTTPClient so the
+// requests are paced together.
+func CheckAvailability(ctx context.Context, videoID string) (*youtube.VideoAvailability, error) {
+	return CheckAvailabilityWithOptions(ctx, videoID, nil)
+}
+
+// AvailabilityOptions configures CheckAvailabilityWithOptions.
+type AvailabilityOptions struct {
+	// HTTPClient, if set, makes the request in place of a client built by
+	// NewHTTPClient, so that many checks share its rate limiter and
+	// circuit breaker.
+	HTTPClient *ythttp.Client
+	// Config overrides the configuration loaded from ytsync.json and the
+	// environment. Build one with config.New.
+	Config *config.Config
+}
+
+// CheckAvailabilityWithOptions is like CheckAvailability, with an HTTP
+// client and Config override.
+func CheckAvailabilityWithOptions(ctx context.Context, videoID string, opts *AvailabilityOptions) (*youtube.VideoAvailability, error) {
+	if opts == nil {
+		opts = &AvailabilityOptions{}
+	}
+	videoID, err := youtube.ParseVideoID(videoID)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		cfg, err := loadConfig(opts.Config)
+		if err != nil {
+			return nil, err
+		}
+		httpClient = NewHTTPClient(cfg)
+	}
+	result, err := innertube.NewClient(httpClient).Availability(ctx, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("check availability: %w", err)
+	}
+	return result, nil
+}
+
+// FetchSegments returns the SponsorBlock sponsor, intro, and outro segments
+// for a video, sorted by start time. Videos without submitted segments return
+// an empty slice. Use youtube.StripSegments to remove them from a transcript.
+func FetchSegments(ctx context.Context, videoID string) ([]youtube.SkipSegment, error) {
+	videoID, err := youtube.ParseVideoID(videoID)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := youtube.NewSponsorBlockClient(NewHTTPClient(cfg)).FetchSegments(ctx, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch segments: %w", err)
+	}
+	return segments, nil
+}
+
+// FetchChannelInfo returns the metadata shown on a channel's page,
+// including its about tab: links, country, join date, total view count,
+// and whether it has a business email. channelURL may be any URL or handle
+// youtube.ChannelResolver accepts.
+func FetchChannelInfo(ctx context.Context, channelURL string) (*youtube.ChannelInfo, error) {
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		return nil, err
+	}
+	client := NewHTTPClient(cfg)
+	resolver := youtube.NewChannelResolver()
+	resolver.Client = client
+	info, err := resolver.FetchChannelInfo(ctx, channelURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetch channel info: %w", err)
+	}
+	about, err := innertube.NewClient(client).About(ctx, info.ID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch channel about: %w", err)
+	}
+	info.About = about
+	return info, nil
+}
+
+// TranscriptionAudioOptions configures DownloadAudioForTranscriptionWithOptions.
+type TranscriptionAudioOptions struct {
+	youtube.TranscriptionAudioOptions
+	// Config overrides the configuration loaded from ytsync.json and the
+	// environment. Build one with config.New.
+	Config *config.Config
+}
+
+// DownloadAudioForTranscription downloads a video's audio and splits it into
+// mono chunks (16kHz WAV by default) ready for speech-to-text models. Chunks
+// are named <videoID>_<index>.<format> in opts.OutputDir. Requires ffmpeg
+// and ffprobe on PATH.
+func DownloadAudioForTranscription(ctx context.Context, videoID string, opts *youtube.TranscriptionAudioOptions) (*youtube.TranscriptionAudio, error) {
+	if opts == nil {
+		opts = &youtube.TranscriptionAudioOptions{}
+	}
+	return DownloadAudioForTranscriptionWithOptions(ctx, videoID, &TranscriptionAudioOptions{
+		TranscriptionAudioOptions: *opts,
+	})
+}
+
+// DownloadAudioForTranscriptionWithOptions is like
+// DownloadAudioForTranscription, with a Config override.
+func DownloadAudioForTranscriptionWithOptions(ctx context.Context, videoID string, opts *TranscriptionAudioOptions) (*youtube.TranscriptionAudio, error) {
+	if opts == nil {
+		opts = &TranscriptionAudioOptions{}
+	}
+	videoID, err := youtube.ParseVideoID(videoID)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig(opts.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	downloader := youtube.NewDownloader()
+	downloader.YtdlpPath = cfg.YtdlpPath
+
+	result, err := downloader.DownloadAudioForTranscription(ctx, videoID, &opts.TranscriptionAudioOptions)
+	if err != nil {
+		return nil, fmt.Errorf("download audio for transcription: %w", err)
+	}
+	return result, nil
 }
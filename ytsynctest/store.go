@@ -0,0 +1,12 @@
+package ytsynctest
+
+import "ytsync/storage"
+
+// MemoryStore is an in-memory storage.Store. It is storage.MemoryStore,
+// kept here so tests can take all their fakes from this package.
+type MemoryStore = storage.MemoryStore
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return storage.NewMemoryStore()
+}
@@ -0,0 +1,363 @@
+package ytsynctest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	ythttp "ytsync/http"
+	"ytsync/youtube"
+	"ytsync/youtube/innertube"
+)
+
+const (
+	innertubeBrowseURL = "https://www.youtube.com/youtubei/v1/browse"
+	rssFeedURL         = "https://www.youtube.com/feeds/videos.xml?channel_id=%s"
+)
+
+// Response is a canned HTTP response served by Transport.
+type Response struct {
+	Status int // defaults to 200
+	Header http.Header
+	Body   []byte
+}
+
+// Request is a request received by Transport.
+type Request struct {
+	Method string
+	URL    string
+	Body   []byte
+}
+
+type route struct {
+	method    string
+	prefix    string
+	responses []Response
+	served    int
+}
+
+// Transport is an http.RoundTripper that answers requests from scripted
+// responses instead of the network. Requests matching no script fail, so
+// a test notices any request it did not expect. It is safe for concurrent
+// use.
+type Transport struct {
+	mu       sync.Mutex
+	routes   []*route
+	requests []Request
+}
+
+// NewTransport creates a Transport with nothing scripted.
+func NewTransport() *Transport {
+	return &Transport{}
+}
+
+// Handle scripts the responses to requests with the given method whose URL
+// starts with urlPrefix. Responses are served in order and the last one is
+// repeated. When several scripts match, the one added first wins.
+func (t *Transport) Handle(method, urlPrefix string, responses ...Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes = append(t.routes, &route{method: method, prefix: urlPrefix, responses: responses})
+}
+
+// ScriptInnertube scripts the Innertube browse endpoint to return pages in
+// order, such as an InnertubeBrowsePage followed by
+// InnertubeContinuationPages.
+func (t *Transport) ScriptInnertube(pages ...[]byte) {
+	responses := make([]Response, len(pages))
+	for i, page := range pages {
+		responses[i] = Response{Header: http.Header{"Content-Type": {"application/json"}}, Body: page}
+	}
+	t.Handle(http.MethodPost, innertubeBrowseURL, responses...)
+}
+
+// ScriptRSS scripts the RSS feed of channelID to return feed.
+func (t *Transport) ScriptRSS(channelID string, feed []byte) {
+	t.Handle(http.MethodGet, fmt.Sprintf(rssFeedURL, channelID),
+		Response{Header: http.Header{"Content-Type": {"application/atom+xml"}}, Body: feed})
+}
+
+// Requests returns the requests received so far, in order.
+func (t *Transport) Requests() []Request {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Request(nil), t.requests...)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	url := req.URL.String()
+
+	t.mu.Lock()
+	t.requests = append(t.requests, Request{Method: req.Method, URL: url, Body: body})
+	var resp *Response
+	for _, r := range t.routes {
+		if r.method == req.Method && strings.HasPrefix(url, r.prefix) && len(r.responses) > 0 {
+			resp = &r.responses[min(r.served, len(r.responses)-1)]
+			r.served++
+			break
+		}
+	}
+	t.mu.Unlock()
+
+	if resp == nil {
+		return nil, fmt.Errorf("ytsynctest: no response scripted for %s %s", req.Method, url)
+	}
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := resp.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Client returns a ythttp.Client that sends its requests through t, with
+// retries disabled and rate limits high enough not to slow tests down.
+func (t *Transport) Client() *ythttp.Client {
+	cfg := ythttp.DefaultConfig()
+	cfg.RoundTripper = t
+	cfg.Retry.MaxRetries = 0
+	cfg.RateLimiter.InnertubeRPS = 1000
+	cfg.RateLimiter.DataAPIRPS = 1000
+	cfg.RateLimiter.RSSRPS = 1000
+	cfg.RateLimiter.Burst = 1000
+	cfg.RateLimiter.EnableDynamicBackoff = false
+	return ythttp.New(cfg)
+}
+
+// HTTPClient returns an http.Client that sends its requests through t.
+func (t *Transport) HTTPClient() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// InnertubeBrowsePage returns the first page of a channel's Videos tab as
+// the Innertube browse endpoint serves it, listing videos in the order
+// given. A non-empty continuation is included as the token for the next
+// page, which should be a valid base64 string.
+func InnertubeBrowsePage(channelID, channelName string, videos []youtube.VideoInfo, continuation string) []byte {
+	grid := make([]innertube.RichGridContent, 0, len(videos)+1)
+	for _, v := range videos {
+		grid = append(grid, innertube.RichGridContent{
+			RichItemRenderer: &innertube.RichItemRenderer{
+				Content: &innertube.RichItemContent{VideoRenderer: videoRenderer(v)},
+			},
+		})
+	}
+	if continuation != "" {
+		grid = append(grid, innertube.RichGridContent{ContinuationItemRenderer: continuationRenderer(continuation)})
+	}
+
+	resp := innertube.BrowseResponse{
+		Contents: &innertube.Contents{
+			TwoColumnBrowseResultsRenderer: &innertube.TwoColumnBrowseResultsRenderer{
+				Tabs: []innertube.Tab{{TabRenderer: &innertube.TabRenderer{
+					Title:    "Videos",
+					Selected: true,
+					Content: &innertube.TabContent{
+						RichGridRenderer: &innertube.RichGridRenderer{Contents: grid},
+					},
+				}}},
+			},
+		},
+		Metadata: &innertube.ChannelMetadata{
+			ChannelMetadataRenderer: &innertube.ChannelMetadataRenderer{Title: channelName, ExternalID: channelID},
+		},
+	}
+	return mustMarshal(resp)
+}
+
+// InnertubeContinuationPage returns a later page of a channel listing, as
+// served for a continuation token. A non-empty continuation is included as
+// the token for the page after it.
+func InnertubeContinuationPage(videos []youtube.VideoInfo, continuation string) []byte {
+	items := make([]innertube.ContinuationItem, 0, len(videos)+1)
+	for _, v := range videos {
+		items = append(items, innertube.ContinuationItem{
+			RichItemRenderer: &innertube.RichItemRenderer{
+				Content: &innertube.RichItemContent{VideoRenderer: videoRenderer(v)},
+			},
+		})
+	}
+	if continuation != "" {
+		items = append(items, innertube.ContinuationItem{ContinuationItemRenderer: continuationRenderer(continuation)})
+	}
+
+	resp := innertube.BrowseResponse{
+		OnResponseReceived: []innertube.OnResponseAction{{
+			AppendContinuationItemsAction: &innertube.AppendContinuationItemsAction{ContinuationItems: items},
+		}},
+	}
+	return mustMarshal(resp)
+}
+
+// videoRenderer renders v the way the Videos tab does. Innertube only
+// shows relative publish times and rounded view counts, so listers see
+// those, not v's exact values.
+func videoRenderer(v youtube.VideoInfo) *innertube.VideoRenderer {
+	r := &innertube.VideoRenderer{
+		VideoID: v.ID,
+		Title:   &innertube.TextRuns{Runs: []innertube.TextRun{{Text: v.Title}}},
+	}
+	if v.Description != "" {
+		r.DescriptionSnippet = &innertube.TextRuns{Runs: []innertube.TextRun{{Text: v.Description}}}
+	}
+	if v.Thumbnail != "" {
+		r.Thumbnail = &innertube.ThumbnailList{Thumbnails: []innertube.Thumbnail{{URL: v.Thumbnail}}}
+	}
+	if !v.Published.IsZero() {
+		r.PublishedTimeText = &innertube.SimpleText{SimpleText: relativeTime(v.Published)}
+	}
+	if v.Duration > 0 {
+		r.LengthText = &innertube.SimpleText{SimpleText: lengthText(v.Duration)}
+	}
+	r.ViewCountText = &innertube.SimpleText{SimpleText: fmt.Sprintf("%d views", v.ViewCount)}
+	return r
+}
+
+func continuationRenderer(token string) *innertube.ContinuationItemRenderer {
+	return &innertube.ContinuationItemRenderer{
+		ContinuationEndpoint: &innertube.ContinuationEndpoint{
+			ContinuationCommand: &innertube.ContinuationCommand{Token: token},
+		},
+	}
+}
+
+// relativeTime formats t as Innertube's "N units ago".
+func relativeTime(t time.Time) string {
+	age := time.Since(t)
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, u := range units {
+		if n := int(age / u.size); n >= 1 {
+			if n == 1 {
+				return fmt.Sprintf("1 %s ago", u.name)
+			}
+			return fmt.Sprintf("%d %ss ago", n, u.name)
+		}
+	}
+	return fmt.Sprintf("%d seconds ago", int(age/time.Second))
+}
+
+// lengthText formats d as Innertube's "M:SS" or "H:MM:SS".
+func lengthText(d time.Duration) string {
+	s := int(d / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// RSSFeed returns the Atom feed YouTube serves for a channel, listing
+// videos in the order given.
+func RSSFeed(channelID, channelName string, videos []youtube.VideoInfo) []byte {
+	type stats struct {
+		Views int64 `xml:"views,attr"`
+	}
+	type thumbnail struct {
+		URL string `xml:"url,attr"`
+	}
+	type group struct {
+		Title       string    `xml:"media:title"`
+		Description string    `xml:"media:description"`
+		Thumbnail   thumbnail `xml:"media:thumbnail"`
+		Statistics  stats     `xml:"media:community>media:statistics"`
+	}
+	type entry struct {
+		ID        string    `xml:"id"`
+		VideoID   string    `xml:"yt:videoId"`
+		ChannelID string    `xml:"yt:channelId"`
+		Title     string    `xml:"title"`
+		Published time.Time `xml:"published"`
+		Updated   time.Time `xml:"updated"`
+		Group     group     `xml:"media:group"`
+	}
+	type feed struct {
+		XMLName   xml.Name `xml:"feed"`
+		XMLNS     string   `xml:"xmlns,attr"`
+		XMLNSYT   string   `xml:"xmlns:yt,attr"`
+		XMLNSMed  string   `xml:"xmlns:media,attr"`
+		ChannelID string   `xml:"yt:channelId"`
+		Title     string   `xml:"title"`
+		Author    struct {
+			Name string `xml:"name"`
+			URI  string `xml:"uri"`
+		} `xml:"author"`
+		Entries []entry `xml:"entry"`
+	}
+
+	f := feed{
+		XMLNS:     "http://www.w3.org/2005/Atom",
+		XMLNSYT:   "http://www.youtube.com/xml/schemas/2015",
+		XMLNSMed:  "http://search.yahoo.com/mrss/",
+		ChannelID: channelID,
+		Title:     channelName,
+	}
+	f.Author.Name = channelName
+	f.Author.URI = "https://www.youtube.com/channel/" + channelID
+	for _, v := range videos {
+		f.Entries = append(f.Entries, entry{
+			ID:        "yt:video:" + v.ID,
+			VideoID:   v.ID,
+			ChannelID: channelID,
+			Title:     v.Title,
+			Published: v.Published,
+			Updated:   v.Published,
+			Group: group{
+				Title:       v.Title,
+				Description: v.Description,
+				Thumbnail:   thumbnail{URL: v.Thumbnail},
+				Statistics:  stats{Views: v.ViewCount},
+			},
+		})
+	}
+
+	out, err := xml.MarshalIndent(f, "", "  ")
+	if err != nil {
+		panic("ytsynctest: encode RSS feed: " + err.Error())
+	}
+	return append([]byte(xml.Header), out...)
+}
+
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic("ytsynctest: encode Innertube page: " + err.Error())
+	}
+	return data
+}
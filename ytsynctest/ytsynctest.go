@@ -0,0 +1,164 @@
+// Package ytsynctest provides in-memory fakes of ytsync's dependencies for
+// testing code built on it without yt-dlp, network access, or files.
+//
+// FakeVideoLister implements youtube.VideoLister and FakeTranscriptExtractor
+// stands in for youtube.TranscriptExtractor. MemoryStore implements
+// storage.Store. Transport is a scripted http.RoundTripper that serves
+// canned Innertube and RSS responses built with InnertubeBrowsePage and
+// RSSFeed, for tests that exercise the real listers:
+//
+//	transport := ytsynctest.NewTransport()
+//	transport.ScriptInnertube(ytsynctest.InnertubeBrowsePage("UC...", "Channel", videos, ""))
+//	lister := innertube.NewLister(transport.Client())
+package ytsynctest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"ytsync/youtube"
+)
+
+// FakeVideoLister is a youtube.VideoLister serving videos added with
+// AddVideos. ListOptions filters, MaxResults, and cancellation are applied
+// as the real listers do. It is safe for concurrent use.
+type FakeVideoLister struct {
+	// Err, if set, is returned by every ListVideos call.
+	Err error
+	// FullHistory is returned by SupportsFullHistory.
+	FullHistory bool
+
+	mu     sync.Mutex
+	videos map[string][]youtube.VideoInfo
+	calls  []string
+}
+
+// AddVideos adds videos to the listing of channelURL, in the order given,
+// which should be newest first.
+func (f *FakeVideoLister) AddVideos(channelURL string, videos ...youtube.VideoInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.videos == nil {
+		f.videos = make(map[string][]youtube.VideoInfo)
+	}
+	f.videos[channelURL] = append(f.videos[channelURL], videos...)
+}
+
+// ListVideos returns the videos added for channelURL that match opts. A
+// channel without videos fails with youtube.ErrChannelNotFound.
+func (f *FakeVideoLister) ListVideos(ctx context.Context, channelURL string, opts *youtube.ListOptions) ([]youtube.VideoInfo, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, channelURL)
+	videos, ok := f.videos[channelURL]
+	f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if !ok {
+		return nil, &youtube.ListerError{Source: "fake", Channel: channelURL, Err: youtube.ErrChannelNotFound}
+	}
+
+	var out []youtube.VideoInfo
+	for _, v := range videos {
+		if opts.BeforeRange(v) {
+			break
+		}
+		if !opts.Matches(v) {
+			continue
+		}
+		out = append(out, v)
+		if opts != nil && opts.MaxResults > 0 && len(out) == opts.MaxResults {
+			break
+		}
+	}
+	return out, nil
+}
+
+// SupportsFullHistory reports FullHistory.
+func (f *FakeVideoLister) SupportsFullHistory() bool {
+	return f.FullHistory
+}
+
+// Calls returns the channel URLs ListVideos was called with, in order.
+func (f *FakeVideoLister) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// FakeTranscriptExtractor serves transcripts added with AddTranscript. Its
+// Extract method has the signature of youtube.TranscriptExtractor.Extract,
+// and Fetcher adapts it to a youtube.TranscriptFetcher for enrichment. It
+// is safe for concurrent use.
+type FakeTranscriptExtractor struct {
+	mu          sync.Mutex
+	transcripts map[string]*youtube.Transcript
+	errs        map[string]error
+	calls       []string
+}
+
+// AddTranscript makes Extract return t for t.VideoID.
+func (f *FakeTranscriptExtractor) AddTranscript(t *youtube.Transcript) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.transcripts == nil {
+		f.transcripts = make(map[string]*youtube.Transcript)
+	}
+	f.transcripts[t.VideoID] = t
+}
+
+// FailWith makes Extract return err for videoID.
+func (f *FakeTranscriptExtractor) FailWith(videoID string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.errs == nil {
+		f.errs = make(map[string]error)
+	}
+	f.errs[videoID] = err
+}
+
+// Extract returns a copy of the transcript added for videoID, with its
+// entries normalized if opts.Normalize is set. Videos with neither a
+// transcript nor an error fail with youtube.ErrNoTranscript.
+func (f *FakeTranscriptExtractor) Extract(ctx context.Context, videoID string, opts *youtube.ExtractOptions) (*youtube.Transcript, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, videoID)
+	t, ok := f.transcripts[videoID]
+	err := f.errs[videoID]
+	f.mu.Unlock()
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", youtube.ErrNoTranscript, videoID)
+	}
+
+	out := *t
+	out.Entries = append([]youtube.TranscriptEntry(nil), t.Entries...)
+	if opts != nil && opts.Normalize {
+		out.Entries = youtube.Normalize(out.Entries)
+	}
+	return &out, nil
+}
+
+// Fetcher returns Extract with default options as a TranscriptFetcher.
+func (f *FakeTranscriptExtractor) Fetcher() youtube.TranscriptFetcher {
+	return func(ctx context.Context, videoID string) (*youtube.Transcript, error) {
+		return f.Extract(ctx, videoID, nil)
+	}
+}
+
+// Calls returns the video IDs Extract was called with, in order.
+func (f *FakeTranscriptExtractor) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
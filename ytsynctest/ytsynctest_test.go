@@ -0,0 +1,196 @@
+package ytsynctest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"ytsync/storage"
+	"ytsync/youtube"
+	"ytsync/youtube/innertube"
+)
+
+const testChannelID = "UCabcdefghijklmnopqrstuv"
+
+func testVideos() []youtube.VideoInfo {
+	now := time.Now()
+	return []youtube.VideoInfo{
+		{ID: "vid00000003", Title: "Third", Published: now.Add(-26 * time.Hour), Duration: 10 * time.Minute, ViewCount: 300},
+		{ID: "vid00000002", Title: "Second", Published: now.Add(-8 * 24 * time.Hour), Duration: 75 * time.Minute, ViewCount: 200},
+		{ID: "vid00000001", Title: "First", Published: now.Add(-40 * 24 * time.Hour), Duration: 90 * time.Second, ViewCount: 100},
+	}
+}
+
+func videoIDs(videos []youtube.VideoInfo) string {
+	ids := make([]string, len(videos))
+	for i, v := range videos {
+		ids[i] = v.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestFakeVideoLister(t *testing.T) {
+	ctx := context.Background()
+	lister := &FakeVideoLister{}
+	lister.AddVideos("chan", testVideos()...)
+
+	videos, err := lister.ListVideos(ctx, "chan", &youtube.ListOptions{MaxResults: 2})
+	if err != nil {
+		t.Fatalf("ListVideos() error = %v", err)
+	}
+	if got := videoIDs(videos); got != "vid00000003,vid00000002" {
+		t.Errorf("ListVideos(MaxResults: 2) = %s", got)
+	}
+
+	videos, _ = lister.ListVideos(ctx, "chan", &youtube.ListOptions{PublishedAfter: time.Now().Add(-10 * 24 * time.Hour)})
+	if got := videoIDs(videos); got != "vid00000003,vid00000002" {
+		t.Errorf("ListVideos(PublishedAfter) = %s", got)
+	}
+
+	if _, err := lister.ListVideos(ctx, "other", nil); !errors.Is(err, youtube.ErrChannelNotFound) {
+		t.Errorf("ListVideos(unknown) error = %v, want ErrChannelNotFound", err)
+	}
+	if calls := lister.Calls(); len(calls) != 3 {
+		t.Errorf("Calls() = %v, want 3 calls", calls)
+	}
+}
+
+func TestFakeTranscriptExtractor(t *testing.T) {
+	ctx := context.Background()
+	extractor := &FakeTranscriptExtractor{}
+	extractor.AddTranscript(&youtube.Transcript{VideoID: "vid00000001", Entries: []youtube.TranscriptEntry{{Start: 0, Text: "hello"}}})
+	failure := errors.New("boom")
+	extractor.FailWith("vid00000002", failure)
+
+	transcript, err := extractor.Fetcher()(ctx, "vid00000001")
+	if err != nil || len(transcript.Entries) != 1 {
+		t.Fatalf("Fetcher() = %+v, %v", transcript, err)
+	}
+	if _, err := extractor.Extract(ctx, "vid00000002", nil); !errors.Is(err, failure) {
+		t.Errorf("Extract(failing) error = %v", err)
+	}
+	if _, err := extractor.Extract(ctx, "vid00000003", nil); !errors.Is(err, youtube.ErrNoTranscript) {
+		t.Errorf("Extract(missing) error = %v, want ErrNoTranscript", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	channel := &storage.Channel{YouTubeID: testChannelID, Name: "Test"}
+	if err := store.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	if channel.ID == "" {
+		t.Error("CreateChannel() did not assign an ID")
+	}
+	if err := store.CreateChannel(ctx, &storage.Channel{YouTubeID: testChannelID}); !errors.Is(err, storage.ErrAlreadyExists) {
+		t.Errorf("CreateChannel(duplicate) error = %v, want ErrAlreadyExists", err)
+	}
+
+	video := &storage.Video{ID: "v1", YouTubeID: "vid00000001", ChannelID: channel.ID}
+	if err := store.CreateVideo(ctx, video); err != nil {
+		t.Fatalf("CreateVideo() error = %v", err)
+	}
+	if err := store.CreateTranscript(ctx, &storage.Transcript{VideoID: "v1", Segments: []storage.Segment{{Start: 1, Text: "b"}, {Start: 0, Text: "a"}}}); err != nil {
+		t.Fatalf("CreateTranscript() error = %v", err)
+	}
+	if got, _ := store.GetVideo(ctx, "v1"); !got.HasTranscript {
+		t.Error("CreateTranscript() did not mark the video as transcribed")
+	}
+	if got, _ := store.GetTranscript(ctx, "v1"); got.Content != "a b" {
+		t.Errorf("transcript content = %q, want segments in start order", got.Content)
+	}
+	if pending, _ := store.ListVideosNeedingTranscript(ctx); len(pending) != 0 {
+		t.Errorf("ListVideosNeedingTranscript() = %d videos, want 0", len(pending))
+	}
+	if err := store.CreateTranscript(ctx, &storage.Transcript{VideoID: "v1", Language: "es", Content: "hola"}); err != nil {
+		t.Fatalf("CreateTranscript(es) error = %v", err)
+	}
+	if langs, _ := store.ListTranscriptLanguages(ctx, "v1"); len(langs) != 2 || langs[0] != "" || langs[1] != "es" {
+		t.Errorf("ListTranscriptLanguages() = %q, want the first transcript's language, then es", langs)
+	}
+	if got, err := store.GetTranscriptByLanguage(ctx, "v1", "es"); err != nil || got.Content != "hola" {
+		t.Errorf("GetTranscriptByLanguage(es) = %+v, %v", got, err)
+	}
+
+	if err := store.SaveChannelAlias(ctx, &storage.ChannelAlias{Alias: "@Test", YouTubeID: testChannelID}); err != nil {
+		t.Fatalf("SaveChannelAlias() error = %v", err)
+	}
+	if got, err := store.GetChannelByHandle(ctx, "@test"); err != nil || got.ID != channel.ID {
+		t.Errorf("GetChannelByHandle() = %+v, %v", got, err)
+	}
+
+	if err := store.DeleteVideo(ctx, "v1"); err != nil {
+		t.Fatalf("DeleteVideo() error = %v", err)
+	}
+	if _, err := store.GetTranscript(ctx, "v1"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("GetTranscript() after DeleteVideo error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.GetLastSync(ctx, channel.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("GetLastSync() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestTransport_InnertubeLister(t *testing.T) {
+	videos := testVideos()
+	transport := NewTransport()
+	transport.ScriptInnertube(
+		InnertubeBrowsePage(testChannelID, "Test Channel", videos[:2], "Y29udGludWF0aW9u"),
+		InnertubeContinuationPage(videos[2:], ""),
+	)
+
+	lister := innertube.NewLister(transport.Client())
+	got, err := lister.ListVideos(context.Background(), testChannelID, &youtube.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListVideos() error = %v", err)
+	}
+	if ids := videoIDs(got); ids != "vid00000003,vid00000002,vid00000001" {
+		t.Fatalf("ListVideos() = %s", ids)
+	}
+	if got[0].ChannelName != "Test Channel" || got[0].Title != "Third" || got[1].Duration != 75*time.Minute || got[0].ViewCount != 300 {
+		t.Errorf("ListVideos()[0] = %+v", got[0])
+	}
+	// Innertube rounds publish times, to a month here
+	if d := time.Since(got[2].Published); d < 29*24*time.Hour || d > 31*24*time.Hour {
+		t.Errorf("ListVideos()[2].Published = %v, want about a month ago", got[2].Published)
+	}
+	if requests := transport.Requests(); len(requests) != 2 || !strings.Contains(string(requests[1].Body), "Y29udGludWF0aW9u") {
+		t.Errorf("Requests() = %+v, want a browse request and a continuation", requests)
+	}
+}
+
+func TestTransport_RSSLister(t *testing.T) {
+	videos := testVideos()
+	transport := NewTransport()
+	transport.ScriptRSS(testChannelID, RSSFeed(testChannelID, "Test Channel", videos))
+
+	lister := youtube.NewResilientRSSLister(transport.Client())
+	got, err := lister.ListVideos(context.Background(), testChannelID, nil)
+	if err != nil {
+		t.Fatalf("ListVideos() error = %v", err)
+	}
+	if ids := videoIDs(got); ids != "vid00000003,vid00000002,vid00000001" {
+		t.Fatalf("ListVideos() = %s", ids)
+	}
+	if got[0].ChannelName != "Test Channel" || got[0].ViewCount != 300 || !got[0].Published.Equal(videos[0].Published) {
+		t.Errorf("ListVideos()[0] = %+v", got[0])
+	}
+}
+
+func TestTransport_Unscripted(t *testing.T) {
+	transport := NewTransport()
+	transport.Handle(http.MethodGet, "https://example.com/", Response{Status: http.StatusTeapot})
+
+	resp, err := transport.HTTPClient().Get("https://example.com/a")
+	if err != nil || resp.StatusCode != http.StatusTeapot {
+		t.Fatalf("Get(scripted) = %v, %v", resp, err)
+	}
+	resp.Body.Close()
+	if _, err := transport.HTTPClient().Get("https://example.org/"); err == nil || !strings.Contains(err.Error(), "no response scripted") {
+		t.Errorf("Get(unscripted) error = %v", err)
+	}
+}